@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/urfave/cli/v3"
+)
+
+// configureCompletionCommand lists the built-in completion command (bash, zsh, fish, pwsh)
+// in the help output
+func configureCompletionCommand(cmd *cli.Command) {
+	cmd.Hidden = false
+	cmd.Usage = "Output the shell completion script for bash, zsh, fish or pwsh (PowerShell)"
+}
+
+// completeConfigNames completes the values of --target and --env with the names defined in
+// the configuration, and flags and subcommands otherwise
+func completeConfigNames(ctx context.Context, cmd *cli.Command) {
+	// The completion scripts append --generate-shell-completion to the words before the cursor
+	previous := ""
+	if len(os.Args) >= 2 {
+		previous = os.Args[len(os.Args)-2]
+	}
+
+	var names []string
+	switch previous {
+	case "--target":
+		config, err := configuration.LoadRawConfiguration(configPath(cmd))
+		if err != nil {
+			return
+		}
+		for _, target := range config.Targets {
+			names = append(names, target.Name)
+		}
+	case "--env":
+		config, err := configuration.LoadRawConfiguration(configPath(cmd))
+		if err != nil {
+			return
+		}
+		for name := range config.Environments {
+			names = append(names, name)
+		}
+	default:
+		cli.DefaultCompleteWithFlags(ctx, cmd)
+		return
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(cmd.Root().Writer, name)
+	}
+}
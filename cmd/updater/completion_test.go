@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestCompleteConfigNames(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "updater.yml")
+	config := `targets:
+  - name: web
+    type: yaml-field
+    file: values.yaml
+    items: []
+  - name: api
+    type: yaml-field
+    file: values.yaml
+    items: []
+environments:
+  staging: {}
+  production: {}
+`
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		previous string
+		expected []string
+	}{
+		{name: "target names", previous: "--target", expected: []string{"api", "web"}},
+		{name: "environment names", previous: "--env", expected: []string{"production", "staging"}},
+		{name: "flag names otherwise", previous: "--co", expected: []string{"--config"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalArgs := os.Args
+			os.Args = []string{"updater", "compare", tt.previous, "--generate-shell-completion"}
+			defer func() { os.Args = originalArgs }()
+
+			var output bytes.Buffer
+			command := &cli.Command{
+				Name:   "updater",
+				Writer: &output,
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "config", Value: configFile},
+					&cli.BoolFlag{Name: "recursive"},
+					&cli.StringSliceFlag{Name: "target"},
+					&cli.StringFlag{Name: "env"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					completeConfigNames(ctx, cmd)
+					return nil
+				},
+			}
+			if err := command.Run(context.Background(), []string{"updater"}); err != nil {
+				t.Fatalf("failed to run command: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("expected completion %q, got:\n%s", expected, output.String())
+				}
+			}
+			if tt.previous == "--target" && strings.Contains(output.String(), "staging") {
+				t.Errorf("expected only target names, got:\n%s", output.String())
+			}
+		})
+	}
+}
+
+func TestCompleteConfigNames_InvalidConfiguration(t *testing.T) {
+	originalArgs := os.Args
+	os.Args = []string{"updater", "compare", "--target", "--generate-shell-completion"}
+	defer func() { os.Args = originalArgs }()
+
+	var output bytes.Buffer
+	command := &cli.Command{
+		Name:   "updater",
+		Writer: &output,
+		Flags:  []cli.Flag{&cli.StringFlag{Name: "config", Value: filepath.Join(t.TempDir(), "missing.yml")}},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			completeConfigNames(ctx, cmd)
+			return nil
+		},
+	}
+	if err := command.Run(context.Background(), []string{"updater"}); err != nil {
+		t.Fatalf("failed to run command: %v", err)
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no completions without configuration, got:\n%s", output.String())
+	}
+}
+
+func TestConfigureCompletionCommand(t *testing.T) {
+	command := &cli.Command{Name: "completion", Hidden: true}
+	configureCompletionCommand(command)
+	if command.Hidden || !strings.Contains(command.Usage, "pwsh") {
+		t.Errorf("expected a visible completion command with usage, got hidden=%v usage=%q", command.Hidden, command.Usage)
+	}
+}
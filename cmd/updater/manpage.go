@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+func docsManCommand(ctx context.Context, cmd *cli.Command) error {
+	var writer io.Writer = os.Stdout
+	if outputFile := cmd.String("output-file"); outputFile != "" && outputFile != "-" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("failed to create output file: %v", err), 1)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	if err := writeManPage(writer, cmd.Root()); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	return nil
+}
+
+// writeManPage renders the command tree as a roff man page
+func writeManPage(w io.Writer, root *cli.Command) error {
+	var page strings.Builder
+	name := root.Name
+
+	fmt.Fprintf(&page, ".TH %s 1 \"\" \"%s %s\" \"User Commands\"\n", strings.ToUpper(name), name, roffEscape(root.Version))
+	fmt.Fprintf(&page, ".SH NAME\n%s \\- %s\n", name, roffEscape(root.Usage))
+	fmt.Fprintf(&page, ".SH SYNOPSIS\n.B %s\n[\\fIGLOBAL OPTIONS\\fR] \\fICOMMAND\\fR [\\fIOPTIONS\\fR]\n", name)
+
+	if flags := root.VisibleFlags(); len(flags) > 0 {
+		page.WriteString(".SH GLOBAL OPTIONS\n")
+		writeManFlags(&page, flags)
+	}
+
+	page.WriteString(".SH COMMANDS\n")
+	var writeCommands func(commands []*cli.Command, prefix string)
+	writeCommands = func(commands []*cli.Command, prefix string) {
+		for _, command := range commands {
+			if command.Name == "help" {
+				continue
+			}
+			fullName := strings.TrimSpace(prefix + " " + command.Name)
+			fmt.Fprintf(&page, ".SS %s\n%s\n", roffEscape(fullName), roffEscape(command.Usage))
+			writeManFlags(&page, command.VisibleFlags())
+			writeCommands(command.VisibleCommands(), fullName)
+		}
+	}
+	writeCommands(root.VisibleCommands(), "")
+
+	_, err := io.WriteString(w, page.String())
+	return err
+}
+
+func writeManFlags(page *strings.Builder, flags []cli.Flag) {
+	for _, flag := range flags {
+		names := make([]string, 0, len(flag.Names()))
+		for _, flagName := range flag.Names() {
+			if flagName == "help" || flagName == "h" {
+				continue
+			}
+			prefix := "--"
+			if len(flagName) == 1 {
+				prefix = "-"
+			}
+			names = append(names, fmt.Sprintf("\\fB%s\\fR", roffEscape(prefix+flagName)))
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(page, ".TP\n%s", strings.Join(names, ", "))
+		docFlag, ok := flag.(cli.DocGenerationFlag)
+		if !ok {
+			page.WriteString("\n")
+			continue
+		}
+		if docFlag.TakesValue() {
+			page.WriteString(" \\fIvalue\\fR")
+		}
+		page.WriteString("\n")
+
+		usage := docFlag.GetUsage()
+		if value := docFlag.GetValue(); value != "" && value != `""` && docFlag.TypeName() != "bool" {
+			usage += fmt.Sprintf(" (default: %s)", value)
+		}
+		if envVars := docFlag.GetEnvVars(); len(envVars) > 0 {
+			usage += fmt.Sprintf(" [$%s]", strings.Join(envVars, ", $"))
+		}
+		fmt.Fprintf(page, "%s\n", roffEscape(usage))
+	}
+}
+
+// roffEscape escapes text for roff: backslashes and dashes, and control characters at
+// the start of a line
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestRoffEscape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain text", expected: "plain text"},
+		{input: "--dry-run", expected: `\-\-dry\-run`},
+		{input: `C:\path`, expected: `C:\epath`},
+		{input: ".updater directory", expected: `\&.updater directory`},
+		{input: "'quoted", expected: `\&'quoted`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := roffEscape(tt.input); got != tt.expected {
+				t.Errorf("roffEscape(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteManPage(t *testing.T) {
+	root := &cli.Command{
+		Name:    "updater",
+		Usage:   "Update dependencies",
+		Version: "1.2.3",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "verbose", Usage: "Enable debug logging"},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "compare",
+				Usage: "Compare versions",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "Path to configuration", Value: ".updater", Sources: cli.EnvVars("UPDATER_CONFIG")},
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "Export the configuration",
+				Commands: []*cli.Command{
+					{Name: "flux", Usage: "Export Flux manifests"},
+				},
+			},
+			{Name: "hidden", Usage: "Not documented", Hidden: true},
+		},
+	}
+	// Running the root sets up the help command and flags like the CLI does
+	root.Action = func(ctx context.Context, cmd *cli.Command) error { return nil }
+	if err := root.Run(context.Background(), []string{"updater"}); err != nil {
+		t.Fatalf("failed to set up command tree: %v", err)
+	}
+
+	var page bytes.Buffer
+	if err := writeManPage(&page, root); err != nil {
+		t.Fatalf("writeManPage() failed: %v", err)
+	}
+	output := page.String()
+
+	for _, expected := range []string{
+		`.TH UPDATER 1 "" "updater 1.2.3" "User Commands"`,
+		"updater \\- Update dependencies",
+		".SH GLOBAL OPTIONS",
+		`\fB\-\-verbose\fR`,
+		".SS compare",
+		`\fB\-\-config\fR, \fB\-c\fR \fIvalue\fR`,
+		`Path to configuration (default: ".updater") [$UPDATER_CONFIG]`,
+		".SS export flux",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected man page to contain %q, got:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{".SS hidden", ".SS help", `\-\-help`} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("expected man page not to contain %q", unexpected)
+		}
+	}
+}
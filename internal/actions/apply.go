@@ -2,19 +2,61 @@ package actions
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 )
 
-func Apply(options *ApplyOptions) error {
+func Apply(options *ApplyOptions) (err error) {
 	log.Debug().Str("config", options.ConfigPath).Msg("Starting apply process...")
 
-	// Load configuration
-	config, err := configuration.LoadConfiguration(options.ConfigPath)
+	summary := newRunSummary("apply")
+	if options.Profile {
+		summary.enableProfile()
+	}
+	var patchGroups []*PatchGroup
+	defer func() {
+		summary.addPatchGroups(patchGroups)
+		summary.addError(err)
+		summary.printProfile()
+		summary.write(options.SummaryFile)
+		summary.recordHistory(options.History, options.HistoryScope)
+		if options.onFinish != nil {
+			options.onFinish(summary)
+		}
+		if options.GitHubAnnotations {
+			writeGitHubOutputs(map[string]string{
+				"updates_count": fmt.Sprintf("%d", summary.Updates.Total),
+				"prs_created":   fmt.Sprintf("%d", countCreatedPullRequests(patchGroups)),
+			}, []string{"updates_count", "prs_created"})
+		}
+	}()
+
+	options.audit, err = openAuditLog(options.AuditLog, summary.RunID, "apply")
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to load configuration")
-		return fmt.Errorf("configuration load error: %w", err)
+		return err
+	}
+	defer func() {
+		options.audit.close(err)
+	}()
+
+	log.Debug().Str("runId", summary.RunID).Msg("Starting apply run")
+
+	if err := validateConflictMode(options.OnConflict); err != nil {
+		return err
+	}
+
+	// Load configuration
+	endParse := summary.startPhase(phaseParse)
+	config := options.config
+	if config == nil {
+		if config, err = loadConfiguration(options.ConfigPath, options.Recursive, options.Environment); err != nil {
+			log.Error().Err(err).Msg("Failed to load configuration")
+			return fmt.Errorf("configuration load error: %w", err)
+		}
 	}
 
 	log.Debug().Msg("Configuration loaded successfully")
@@ -31,44 +73,91 @@ func Apply(options *ApplyOptions) error {
 
 	log.Debug().Msg("Configuration is valid")
 
+	// Restrict to the requested targets
+	if err := configuration.FilterTargets(config, options.Targets); err != nil {
+		return err
+	}
+	if err := restrictToSBOM(config, options.SBOMs); err != nil {
+		return err
+	}
+	endParse()
+
 	// Get comparison results without outputting them
-	compareResult, err := compareInternal(config, options.Limit, options.Only, options.OutputFormat)
+	compareResult, err := compareInternal(config, options.Scraper, options.Limit, options.Only, options.OutputFormat, options.Snoozes, options.SkippedVersions, summary)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to compare versions")
 		return fmt.Errorf("comparison error: %w", err)
 	}
+	summary.addScrapeResult(compareResult.ScrapeResult)
+	summary.addComparisonResults(compareResult.Results)
+	options.audit.recordDecisions(compareResult.Results)
+
+	if options.GitHubAnnotations {
+		emitGitHubAnnotations(os.Stderr, compareResult.Results)
+	}
 
 	if !compareResult.HasUpdates {
 		log.Info().Msg("No updates available")
-		fmt.Println("✅ All targets are up to date")
+		fmt.Fprintln(util.StatusOutput(), "✅ All targets are up to date")
 		return nil
 	}
 
 	// Build update items with patch groups and labels
 	updateItems := buildUpdateItems(config, compareResult.Results)
 
+	// Drop the updates denied by the policy
+	updateItems, err = applyPolicy(options.Policy, updateItems, options.audit)
+	if err != nil {
+		return err
+	}
+	if len(updateItems) == 0 {
+		fmt.Fprintln(util.StatusOutput(), "✅ No updates allowed by policy")
+		return nil
+	}
+
+	// Drop the updates whose new tag cannot be pulled from the deployment registry yet
+	registryClient := util.NewHTTPClient(30 * time.Second)
+	updateItems = checkImages(config, updateItems, registryClient, options.audit)
+	if len(updateItems) == 0 {
+		fmt.Fprintln(util.StatusOutput(), "✅ No updates with pullable images")
+		return nil
+	}
+
+	// Resolve the digests written to digest-pinned targets
+	updateItems = resolveDigests(config, updateItems, registryClient)
+	if len(updateItems) == 0 {
+		fmt.Fprintln(util.StatusOutput(), "✅ No updates with resolvable digests")
+		return nil
+	}
+
 	// Group updates by patch group
-	patchGroups := groupUpdatesByPatchGroup(updateItems)
+	patchGroups = groupUpdatesByPatchGroup(updateItems)
+	if err := assignBranchNames(config.TargetActor, patchGroups, time.Now()); err != nil {
+		return fmt.Errorf("failed to name update branches: %w", err)
+	}
 
 	// Output the apply plan
+	endApply := summary.startPhase(phaseApply)
+	defer endApply()
 	if options.DryRun {
-		outputDryRunPlan(patchGroups)
+		outputDryRunPlan(config, patchGroups, options)
 	} else if options.Local {
 		outputLocalPlan(updateItems)
 
 		// Apply all updates directly to local files — no git operations
 		for _, update := range updateItems {
-			if err := applyUpdate(config, update); err != nil {
+			if err := applyUpdate(config, update, update.TargetFile); err != nil {
 				return fmt.Errorf("failed to apply update for %s in %s: %w", update.ItemName, update.TargetFile, err)
 			}
-			fmt.Printf("  ✓ Updated %s in %s: %s → %s\n",
+			options.audit.recordFileWritten(update)
+			fmt.Fprintf(util.StatusOutput(), "  ✓ Updated %s in %s: %s → %s\n",
 				update.ItemName,
 				update.TargetFile,
 				update.CurrentVersion,
 				update.LatestVersion)
 		}
 
-		fmt.Println("\n✅ Successfully applied all updates locally")
+		fmt.Fprintln(util.StatusOutput(), "\n✅ Successfully applied all updates locally")
 	} else {
 		outputApplyPlan(patchGroups)
 
@@ -77,13 +166,20 @@ func Apply(options *ApplyOptions) error {
 			return fmt.Errorf("targetActor is required for applying changes")
 		}
 
+		// Do not sweep stray changes into update commits when checking out branches in place
+		if options.InPlace && !options.AllowDirty {
+			if err := checkDirtyWorkingTrees(patchGroups); err != nil {
+				return err
+			}
+		}
+
 		// Apply changes for each patch group
-		if err := applyPatchGroups(config, patchGroups); err != nil {
+		if err := applyPatchGroups(config, patchGroups, options); err != nil {
 			log.Error().Err(err).Msg("Failed to apply patch groups")
 			return fmt.Errorf("apply error: %w", err)
 		}
 
-		fmt.Println("\n✅ Successfully applied all updates")
+		fmt.Fprintln(util.StatusOutput(), "\n✅ Successfully applied all updates")
 	}
 
 	return nil
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/rs/zerolog/log"
+)
+
+// appendChangelogEntry appends an entry for the updates of a commit to the changelog file of
+// the repository and returns the file path relative to the repository root. The file is
+// created if it does not exist; an identical entry left by an interrupted run is not repeated.
+func appendChangelogEntry(repo *git.Repository, changelog *configuration.Changelog, group *PatchGroup, updates []*UpdateItem, now time.Time) (string, error) {
+	entry := &configuration.ChangelogEntry{
+		PatchGroup: group.Name,
+		Date:       now.Format("2006-01-02"),
+		Updates:    make([]*configuration.ChangelogUpdate, 0, len(updates)),
+	}
+	for _, update := range updates {
+		entry.Updates = append(entry.Updates, &configuration.ChangelogUpdate{
+			Item:   update.ItemName,
+			File:   repo.RelativePath(update.TargetFile),
+			Source: update.SourceName,
+			From:   update.CurrentVersion,
+			To:     update.LatestVersion,
+			Type:   string(update.UpdateType),
+		})
+	}
+
+	rendered, err := changelog.RenderChangelogEntry(entry)
+	if err != nil {
+		return "", err
+	}
+	rendered = strings.TrimRight(rendered, "\n") + "\n"
+
+	changelogPath := filepath.Join(repo.WorkingDirectory, changelog.File)
+	existing, err := os.ReadFile(changelogPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read changelog: %w", err)
+	}
+
+	content := string(existing)
+	if strings.Contains(content, rendered) {
+		log.Debug().Str("file", changelog.File).Msg("Changelog already contains the entry")
+		return filepath.ToSlash(changelog.File), nil
+	}
+	if content != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	content += rendered
+
+	if err := os.MkdirAll(filepath.Dir(changelogPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create changelog directory: %w", err)
+	}
+	if err := os.WriteFile(changelogPath, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write changelog: %w", err)
+	}
+
+	return filepath.ToSlash(changelog.File), nil
+}
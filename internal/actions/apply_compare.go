@@ -2,17 +2,21 @@ package actions
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mxcd/updater/internal/compare"
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/history"
 	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 )
 
-// compareInternal performs comparison without outputting results
-func compareInternal(config *configuration.Config, limit int, only string, outputFormat string) (*CompareResult, error) {
+// compareInternal performs comparison without outputting results, timing its phases in the
+// profile of the summary
+func compareInternal(config *configuration.Config, scraperOptions *scraper.OrchestratorOptions, limit int, only string, outputFormat string, snoozes []*history.Snooze, skippedVersions bool, summary *RunSummary) (*CompareResult, error) {
 	// Create orchestrator and scrape sources
-	orchestrator, err := scraper.NewOrchestrator(config)
+	orchestrator, err := scraper.NewOrchestrator(config, scraperOptions)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create scraper orchestrator")
 		return nil, fmt.Errorf("orchestrator creation error: %w", err)
@@ -20,12 +24,16 @@ func compareInternal(config *configuration.Config, limit int, only string, outpu
 
 	log.Debug().Msg("Scraper orchestrator created successfully")
 
-	// Scrape all sources
+	// Scrape the sources referenced by the targets
 	scrapeOptions := &scraper.ScrapeOptions{
-		Limit: limit,
+		Limit:          limit,
+		Sources:        configuration.ReferencedSources(config),
+		StopAtVersions: compare.NewCompareEngine(config).OldestCurrentVersions(),
 	}
 
+	endScrape := summary.startPhase(phaseScrape)
 	scrapeResult := orchestrator.ScrapeAllSources(scrapeOptions)
+	endScrape()
 
 	log.Debug().
 		Int("succeeded", scrapeResult.Succeeded).
@@ -36,27 +44,42 @@ func compareInternal(config *configuration.Config, limit int, only string, outpu
 	compareEngine := compare.NewCompareEngine(orchestrator.GetConfig())
 
 	// Perform comparison
+	endCompare := summary.startPhase(phaseCompare)
 	results, err := compareEngine.CompareAll()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to compare targets")
 		return nil, fmt.Errorf("comparison error: %w", err)
 	}
 
+	// Hold back updates of later rollout stages until the previous stage is ready
+	applyRollout(orchestrator.GetConfig(), results, time.Now())
+	applySnoozes(results, snoozes, time.Now())
+
+	// Keep the items of version sets at one version after holding back single items
+	compare.AlignVersionSets(results)
+
+	// Score the risk of the updates left after holding back
+	compare.AssessRisks(results, time.Now())
+	if skippedVersions {
+		compare.ListSkippedVersions(results)
+	}
+	endCompare()
+
 	// Filter results based on 'only' flag
 	filteredResults := filterComparisonResults(results, only)
 
-	if err := outputComparisonResults(filteredResults, outputFormat); err != nil {
+	if err := outputComparisonResults(filteredResults, outputFormat, &tableLayout{}); err != nil {
 		log.Error().Err(err).Msg("Failed to output comparison results")
 		return nil, fmt.Errorf("output error: %w", err)
 	}
 
 	// Show scraping errors at the end
 	if scrapeResult.HasErrors() {
-		fmt.Printf("\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
+		fmt.Fprintf(util.StatusOutput(), "\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
 		for _, scrapeErr := range scrapeResult.Errors {
-			fmt.Printf("  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
+			fmt.Fprintf(util.StatusOutput(), "  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
 		}
-		fmt.Println()
+		fmt.Fprintln(util.StatusOutput())
 	}
 
 	// Check if there are pending updates
@@ -75,7 +98,8 @@ func compareInternal(config *configuration.Config, limit int, only string, outpu
 	}
 
 	return &CompareResult{
-		Results:    filteredResults,
-		HasUpdates: hasUpdates,
+		Results:      filteredResults,
+		HasUpdates:   hasUpdates,
+		ScrapeResult: scrapeResult,
 	}, nil
 }
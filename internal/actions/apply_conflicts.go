@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// Conflict handling modes of apply for open pull requests touching the same lines
+const (
+	conflictModeIgnore = "ignore"
+	conflictModeWarn   = "warn"
+	conflictModeSkip   = "skip"
+)
+
+// PullRequestConflict is an open human-authored pull request that changes the same lines of
+// a file as an update branch
+type PullRequestConflict struct {
+	Number int
+	URL    string
+	Author string
+	File   string
+}
+
+func (c *PullRequestConflict) String() string {
+	return fmt.Sprintf("#%d by %s changes the same lines of %s (%s)", c.Number, c.Author, c.File, c.URL)
+}
+
+// validateConflictMode checks the --on-conflict value
+func validateConflictMode(mode string) error {
+	switch mode {
+	case "", conflictModeIgnore, conflictModeWarn, conflictModeSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid --on-conflict: %s (expected ignore, warn or skip)", mode)
+}
+
+// holdBackConflictingBranch checks the open pull requests of others for changes to the same
+// lines before the update branch of a group is pushed. Conflicts are reported; in skip mode
+// the branch is kept local, the group is marked as skipped and true is returned. Failing to
+// check never holds back the branch.
+func holdBackConflictingBranch(repo *git.Repository, config *configuration.Config, group *PatchGroup, mode string) bool {
+	if mode == "" || mode == conflictModeIgnore {
+		return false
+	}
+
+	seen := make(map[string]bool)
+	relPaths := make([]string, 0, len(group.Updates))
+	for _, update := range group.Updates {
+		relPath := repo.RelativePath(update.TargetFile)
+		if !seen[relPath] {
+			seen[relPath] = true
+			relPaths = append(relPaths, relPath)
+		}
+	}
+
+	conflicts, err := findConflictingPullRequests(repo, config.TargetActor, relPaths)
+	if err != nil {
+		log.Warn().Err(err).Str("patchGroup", group.Name).Msg("Failed to check open pull requests for conflicts")
+		return false
+	}
+	if len(conflicts) == 0 {
+		return false
+	}
+
+	for _, conflict := range conflicts {
+		fmt.Fprintf(util.StatusOutput(), "  ⚠️  Conflicts with open pull request %s\n", conflict)
+	}
+	if mode != conflictModeSkip {
+		return false
+	}
+
+	reasons := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		reasons[i] = conflict.String()
+	}
+	group.SkippedReason = "conflicts with open pull request " + strings.Join(reasons, "; ")
+	fmt.Fprintf(util.StatusOutput(), "  ⏭️  Not pushing %s until the conflicting pull requests are merged or closed\n", repo.BranchName)
+	return true
+}
+
+// findConflictingPullRequests returns the open pull requests against the base branch that
+// were not opened by updater or a bot and change lines of the given files within one line
+// of the changes on the current branch
+func findConflictingPullRequests(repo *git.Repository, targetActor *configuration.TargetActor, relPaths []string) ([]*PullRequestConflict, error) {
+	ownLines := make(map[string][]int, len(relPaths))
+	for _, relPath := range relPaths {
+		diff, err := repo.DiffAgainstBase(relPath)
+		if err != nil {
+			return nil, err
+		}
+		if lines := git.ChangedLines(diff); len(lines) > 0 {
+			ownLines[relPath] = lines
+		}
+	}
+	if len(ownLines) == 0 {
+		return nil, nil
+	}
+
+	githubClient, err := git.NewGitHubClient(repo.RepoURL, targetActor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	prs, err := githubClient.ListOpenPullRequests(repo.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Branches of other patch groups share the static prefix of the branch template
+	ownPrefix := configuration.BranchPrefix(targetActor.BranchTemplate)
+	if ownPrefix == "" {
+		ownPrefix = repo.BranchName
+	}
+
+	conflicts := make([]*PullRequestConflict, 0)
+	for _, pr := range prs {
+		if pr.Head.Ref == repo.BranchName || strings.HasPrefix(pr.Head.Ref, ownPrefix) || pr.User.Type == "Bot" {
+			continue
+		}
+
+		files, err := githubClient.ListPullRequestFiles(pr.Number)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			lines, ok := ownLines[file.Filename]
+			if !ok || !git.LinesConflict(lines, git.ChangedLines(file.Patch)) {
+				continue
+			}
+			log.Debug().Int("pr", pr.Number).Str("file", file.Filename).Msg("Open pull request changes the same lines")
+			conflicts = append(conflicts, &PullRequestConflict{
+				Number: pr.Number,
+				URL:    pr.HTMLURL,
+				Author: pr.User.Login,
+				File:   file.Filename,
+			})
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].Number < conflicts[j].Number })
+	return conflicts, nil
+}
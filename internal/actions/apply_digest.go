@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// resolveDigests looks up the digest of the new tag of every digest-pinned update. Digests
+// are content addresses, so the source image is queried regardless of any registry mirror.
+// Updates whose digest cannot be resolved are dropped with the other updates of their version
+// set and proposed again by the next run.
+func resolveDigests(config *configuration.Config, items []*UpdateItem, client *http.Client) []*UpdateItem {
+	resolved := make(map[string]string)
+	available := make([]*UpdateItem, 0, len(items))
+	for _, item := range items {
+		if item.DigestPin == nil {
+			available = append(available, item)
+			continue
+		}
+
+		tag := imageCheckTag(item.LatestVersion)
+		image, provider, err := sourceImage(config, item.SourceName)
+		if err == nil {
+			key := image + ":" + tag
+			digest, done := resolved[key]
+			if !done {
+				if digest, err = docker.ManifestDigest(client, provider, image, tag); err == nil {
+					resolved[key] = digest
+				}
+			}
+			item.Digest = digest
+		}
+
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("item", item.ItemName).
+				Str("file", item.TargetFile).
+				Msg("Failed to resolve digest of new image tag, skipping update")
+			fmt.Fprintf(util.StatusOutput(), "🚫 Digest not resolved, skipping %s in %s: %s → %s (%v)\n",
+				item.ItemName, item.TargetFile, item.CurrentVersion, item.LatestVersion, err)
+			continue
+		}
+		available = append(available, item)
+	}
+	return keepWholeVersionSets(items, available)
+}
+
+// sourceImage returns the full image name of a docker-image source and its provider
+func sourceImage(config *configuration.Config, sourceName string) (string, *configuration.PackageSourceProvider, error) {
+	source := findPackageSource(config, sourceName)
+	if source == nil || source.Type != configuration.PackageSourceTypeDockerImage {
+		return "", nil, fmt.Errorf("source '%s' is not a docker-image source", sourceName)
+	}
+	provider := findProvider(config, source.Provider)
+	baseURL := ""
+	if provider != nil {
+		baseURL = provider.BaseUrl
+	}
+	image, err := docker.BuildImageName(baseURL, source.URI)
+	if err != nil {
+		return "", nil, err
+	}
+	return image, provider, nil
+}
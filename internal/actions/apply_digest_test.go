@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestResolveDigests(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v2/team/app/manifests/1.1.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:abc")
+	}))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	config := &configuration.Config{
+		PackageSourceProviders: []*configuration.PackageSourceProvider{
+			{Name: "registry", Type: configuration.PackageSourceProviderTypeDocker},
+		},
+		PackageSources: []*configuration.PackageSource{
+			{Name: "app", Provider: "registry", Type: configuration.PackageSourceTypeDockerImage, URI: registry + "/team/app"},
+			{Name: "release", Provider: "registry", Type: configuration.PackageSourceTypeGitRelease, URI: "team/app"},
+		},
+		// Digests are resolved from the source image, not from a mirror
+		RegistryMap: map[string]string{registry: "mirror.example.com"},
+	}
+	items := []*UpdateItem{
+		{ItemName: "tag", SourceName: "app", LatestVersion: "9.9.9"},
+		{ItemName: "pinned", SourceName: "app", LatestVersion: "1.1.0", DigestPin: &configuration.DigestPin{}},
+		{ItemName: "pinned again", SourceName: "app", LatestVersion: "1.1.0", DigestPin: &configuration.DigestPin{TagField: "tag"}},
+		{ItemName: "missing tag", SourceName: "app", LatestVersion: "1.2.0", DigestPin: &configuration.DigestPin{}},
+		{ItemName: "no image source", SourceName: "release", LatestVersion: "1.1.0", DigestPin: &configuration.DigestPin{}},
+	}
+
+	available := resolveDigests(config, items, server.Client())
+
+	names := make([]string, 0, len(available))
+	for _, item := range available {
+		names = append(names, item.ItemName+"="+item.Digest)
+	}
+	expected := []string{"tag=", "pinned=sha256:abc", "pinned again=sha256:abc"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v to be available, got %v", expected, names)
+	}
+	// Each tag is resolved once
+	if requests != 2 {
+		t.Errorf("expected 2 registry requests, got %d", requests)
+	}
+}
@@ -1,37 +1,159 @@
 package actions
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/git"
 	"github.com/mxcd/updater/internal/target"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 )
 
-// applyPatchGroups applies all patch groups
-func applyPatchGroups(config *configuration.Config, patchGroups []*PatchGroup) error {
-	log.Debug().Int("groups", len(patchGroups)).Msg("Applying patch groups")
+// applyPatchGroups applies all patch groups. With options.Parallel above one, groups are
+// applied concurrently, but groups sharing a git working directory still run one at a time
+// as they check out their branches in the same working tree.
+func applyPatchGroups(config *configuration.Config, patchGroups []*PatchGroup, options *ApplyOptions) error {
+	log.Debug().Int("groups", len(patchGroups)).Int("parallel", options.Parallel).Msg("Applying patch groups")
+
+	return runPatchGroups(patchGroups, options.Parallel, patchGroupRoots, func(i int, group *PatchGroup) error {
+		start := time.Now()
+		defer func() {
+			group.Duration = time.Since(start)
+		}()
+		return applyScheduledPatchGroup(config, group, i, len(patchGroups), options)
+	})
+}
+
+// runPatchGroups runs apply for every patch group, at most parallel at a time. Groups sharing
+// a working directory returned by roots run one at a time; a group first waits for the locks
+// of its working directories and only then takes one of the parallel slots, so groups waiting
+// for a busy repository do not hold back groups of other repositories. Once a group failed,
+// no further groups are started.
+func runPatchGroups(patchGroups []*PatchGroup, parallel int, roots func(*PatchGroup) []string, apply func(int, *PatchGroup) error) error {
+	if parallel <= 1 {
+		for i, group := range patchGroups {
+			if err := apply(i, group); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
+	locks := &repoLocks{locks: make(map[string]*sync.Mutex)}
+	slots := make(chan struct{}, parallel)
+	errs := make([]error, len(patchGroups))
+	var failed atomic.Bool
+	var wg sync.WaitGroup
 	for i, group := range patchGroups {
-		fmt.Printf("\n📦 Processing Patch Group %d/%d: %s\n", i+1, len(patchGroups), group.Name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(roots(group))
+			defer unlock()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// Do not start further groups once one failed, as in sequential mode
+			if failed.Load() {
+				return
+			}
+			if errs[i] = apply(i, group); errs[i] != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// applyScheduledPatchGroup applies the i-th patch group unless it is outside its maintenance window
+func applyScheduledPatchGroup(config *configuration.Config, group *PatchGroup, i int, total int, options *ApplyOptions) error {
+	fmt.Fprintf(util.StatusOutput(), "\n📦 Processing Patch Group %d/%d: %s\n", i+1, total, group.Name)
 
-		if err := applyPatchGroup(config, group); err != nil {
-			return fmt.Errorf("failed to apply patch group %s: %w", group.Name, err)
+	// Defer groups outside their maintenance window to a later run
+	if !options.IgnoreSchedule {
+		if reason := scheduleDeferral(config, group, time.Now()); reason != "" {
+			group.SkippedReason = reason
+			fmt.Fprintf(util.StatusOutput(), "⏸️  Deferred patch group %s: %s\n", group.Name, reason)
+			return nil
 		}
+	}
 
-		fmt.Printf("✅ Completed patch group: %s\n", group.Name)
+	if err := applyPatchGroup(config, group, options); err != nil {
+		return fmt.Errorf("failed to apply patch group %s: %w", group.Name, err)
 	}
 
+	fmt.Fprintf(util.StatusOutput(), "✅ Completed patch group: %s\n", group.Name)
 	return nil
 }
 
+// repoLocks holds a lock per git working directory
+type repoLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock acquires the locks of the given working directories in sorted order, so groups
+// spanning several repositories cannot deadlock, and returns a function releasing them
+func (l *repoLocks) lock(roots []string) func() {
+	held := make([]*sync.Mutex, 0, len(roots))
+	for _, root := range roots {
+		l.mu.Lock()
+		repoLock, ok := l.locks[root]
+		if !ok {
+			repoLock = &sync.Mutex{}
+			l.locks[root] = repoLock
+		}
+		l.mu.Unlock()
+
+		repoLock.Lock()
+		held = append(held, repoLock)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+	}
+}
+
+// patchGroupRoots returns the sorted git working directories of the files a patch group updates.
+// Files outside a repository are left out; applying them fails with the detection error.
+func patchGroupRoots(group *PatchGroup) []string {
+	seen := make(map[string]bool)
+	roots := make([]string, 0)
+	for _, update := range group.Updates {
+		root, err := git.FindRoot(update.TargetFile)
+		if err != nil || seen[root] {
+			continue
+		}
+		seen[root] = true
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
 // applyPatchGroup applies a single patch group
-func applyPatchGroup(config *configuration.Config, group *PatchGroup) error {
-	// Group updates by file
-	fileGroups := groupUpdatesByFile(group.Updates)
+func applyPatchGroup(config *configuration.Config, group *PatchGroup, options *ApplyOptions) error {
+	// Group updates into commits according to the commit strategy, version sets share a commit
+	commitUnits := groupUpdatesIntoCommits(group.Updates, commitStrategy(config))
+
+	// Apply in worktrees unless updating in place, removed once the pull request is up to date
+	var worktrees map[string]*git.Repository
+	if !options.InPlace {
+		worktrees = make(map[string]*git.Repository)
+		defer removeWorktrees(worktrees)
+	}
 
 	// Track repository and branch info (should be same for all files in group)
 	var repo *git.Repository
@@ -39,34 +161,25 @@ func applyPatchGroup(config *configuration.Config, group *PatchGroup) error {
 	var branchPushed bool
 	var prURL string
 
-	// Sort file paths for deterministic processing order
-	filePaths := make([]string, 0, len(fileGroups))
-	for filePath := range fileGroups {
-		filePaths = append(filePaths, filePath)
-	}
-	sort.Strings(filePaths)
-
-	// Process each file separately
-	fileIndex := 0
-	totalFiles := len(fileGroups)
-	for _, filePath := range filePaths {
-		updates := fileGroups[filePath]
-		fileIndex++
-		isLastFile := fileIndex == totalFiles
+	// Process each commit unit separately
+	for i, unit := range commitUnits {
+		isLastUnit := i == len(commitUnits)-1
 
-		// Pass whether this is the last file so PR is only created once
-		fileRepo, fileBranchExists, fileBranchPushed, err := applyFileUpdates(config, filePath, updates, group, isLastFile)
+		// Pass whether this is the last unit so PR is only created once, and reset the branch
+		// before the first unit only
+		resetBranch := options.ResetBranches && i == 0
+		unitRepo, unitBranchExists, unitBranchPushed, err := applyFileUpdates(config, unit, group, isLastUnit, resetBranch, options, worktrees)
 		if err != nil {
-			return fmt.Errorf("failed to apply updates to file %s: %w", filePath, err)
+			return fmt.Errorf("failed to apply updates to %s: %w", strings.Join(unit.Files, ", "), err)
 		}
 
-		// Store repo and branch info from first file
+		// Store repo and branch info from first unit
 		if repo == nil {
-			repo = fileRepo
-			branchExists = fileBranchExists
+			repo = unitRepo
+			branchExists = unitBranchExists
 		}
-		// Track if branch was pushed in any file processing
-		if fileBranchPushed {
+		// Track if branch was pushed in any unit processing
+		if unitBranchPushed {
 			branchPushed = true
 		}
 	}
@@ -80,55 +193,101 @@ func applyPatchGroup(config *configuration.Config, group *PatchGroup) error {
 			return fmt.Errorf("failed to create or update pull request: %w", err)
 		}
 
+		group.PullRequestURL = prURL
+		group.PullRequestCreated = !branchExists
+
+		prEvent := auditEventPullRequestCreated
+		if branchExists {
+			prEvent = auditEventPullRequestUpdated
+		}
+		options.audit.record(&AuditEvent{
+			Event:       prEvent,
+			PatchGroup:  group.Name,
+			Branch:      repo.BranchName,
+			PullRequest: prURL,
+		})
+
 		if branchExists {
-			fmt.Printf("  🔄 Updated pull request: %s\n", prURL)
+			fmt.Fprintf(util.StatusOutput(), "  🔄 Updated pull request: %s\n", prURL)
 		} else {
-			fmt.Printf("  🔀 Created pull request: %s\n", prURL)
+			fmt.Fprintf(util.StatusOutput(), "  🔀 Created pull request: %s\n", prURL)
+		}
+
+		if options.AttestationDir != "" {
+			if err := attestPatchGroup(config, options, repo, group); err != nil {
+				return fmt.Errorf("failed to attest patch group: %w", err)
+			}
 		}
 	} else if repo != nil && !branchPushed {
-		fmt.Printf("  ℹ️  No changes to push, skipping PR creation\n")
+		fmt.Fprintf(util.StatusOutput(), "  ℹ️  No changes to push, skipping PR creation\n")
 	}
 
 	return nil
 }
 
-// applyFileUpdates applies updates to a single file and returns the repository, branch status, and whether branch was pushed
-func applyFileUpdates(config *configuration.Config, filePath string, updates []*UpdateItem, group *PatchGroup, isLastFile bool) (repo *git.Repository, branchExists bool, branchPushed bool, err error) {
+// applyFileUpdates applies the updates of a commit unit and returns the repository, branch status, and whether branch was pushed.
+// Updates are applied in the worktree of the repository if worktrees is not nil.
+func applyFileUpdates(config *configuration.Config, unit *CommitUnit, group *PatchGroup, isLastFile bool, resetBranch bool, options *ApplyOptions, worktrees map[string]*git.Repository) (repo *git.Repository, branchExists bool, branchPushed bool, err error) {
+	audit := options.audit
+	updates := unit.Updates
 	log.Debug().
-		Str("file", filePath).
+		Strs("files", unit.Files).
 		Int("updates", len(updates)).
-		Msg("Applying updates to file")
+		Msg("Applying updates to files")
 
 	// Create repository instance
 	repo = git.NewRepository("", config.TargetActor)
 
 	// Detect git repository from file path
-	if err = repo.DetectRepository(filePath); err != nil {
+	if err = repo.DetectRepository(unit.Files[0]); err != nil {
 		return nil, false, false, fmt.Errorf("failed to detect git repository: %w", err)
 	}
 
-	// Ensure we always checkout back to the base branch on error
+	// Work in the worktree of the repository, shared by the commit units of the group
+	if worktrees != nil {
+		if repo, err = openWorktree(worktrees, repo); err != nil {
+			return nil, false, false, err
+		}
+	}
+
+	// Ensure we always checkout back to the base branch on error, worktrees are removed instead
+	checkoutRepo := repo
 	defer func() {
+		if checkoutRepo.MainWorkingDirectory != "" {
+			return
+		}
 		if err != nil {
-			if checkoutErr := repo.CheckoutBranch(repo.BaseBranch); checkoutErr != nil {
-				log.Error().Err(checkoutErr).Str("branch", repo.BaseBranch).Msg("Failed to checkout base branch after error")
-				fmt.Printf("  ❌ Error: Could not checkout back to %s: %v\n", repo.BaseBranch, checkoutErr)
+			if checkoutErr := checkoutRepo.CheckoutBranch(checkoutRepo.BaseBranch); checkoutErr != nil {
+				log.Error().Err(checkoutErr).Str("branch", checkoutRepo.BaseBranch).Msg("Failed to checkout base branch after error")
+				fmt.Fprintf(util.StatusOutput(), "  ❌ Error: Could not checkout back to %s: %v\n", checkoutRepo.BaseBranch, checkoutErr)
 			} else {
-				fmt.Printf("  ↩️  Reverted to %s due to error\n", repo.BaseBranch)
+				fmt.Fprintf(util.StatusOutput(), "  ↩️  Reverted to %s due to error\n", checkoutRepo.BaseBranch)
 			}
 		} else if isLastFile {
 			// Only checkout back to base branch after the last file (and after PR creation)
-			if checkoutErr := repo.CheckoutBranch(repo.BaseBranch); checkoutErr != nil {
-				log.Warn().Err(checkoutErr).Str("branch", repo.BaseBranch).Msg("Failed to checkout base branch")
-				fmt.Printf("  ⚠️  Warning: Could not checkout back to %s: %v\n", repo.BaseBranch, checkoutErr)
+			if checkoutErr := checkoutRepo.CheckoutBranch(checkoutRepo.BaseBranch); checkoutErr != nil {
+				log.Warn().Err(checkoutErr).Str("branch", checkoutRepo.BaseBranch).Msg("Failed to checkout base branch")
+				fmt.Fprintf(util.StatusOutput(), "  ⚠️  Warning: Could not checkout back to %s: %v\n", checkoutRepo.BaseBranch, checkoutErr)
 			} else {
-				fmt.Printf("  ✓ Checked out back to %s\n", repo.BaseBranch)
+				fmt.Fprintf(util.StatusOutput(), "  ✓ Checked out back to %s\n", checkoutRepo.BaseBranch)
 			}
 		}
 	}()
 
-	// Create branch name using format: chore/update/<patchGroup>
-	branchName := fmt.Sprintf("chore/update/%s", group.Name)
+	branchName := group.BranchName
+
+	// Recover from partial changes of an interrupted run by starting over from the base branch
+	if resetBranch {
+		if err = repo.ResetBranch(branchName); err != nil {
+			return nil, false, false, fmt.Errorf("failed to reset branch: %w", err)
+		}
+		fmt.Fprintf(util.StatusOutput(), "  🧹 Reset branch %s, recreating it from %s\n", branchName, repo.BaseBranch)
+		audit.record(&AuditEvent{
+			Event:      auditEventBranchReset,
+			PatchGroup: group.Name,
+			Branch:     branchName,
+		})
+	}
 
 	// Check if branch already exists (reuse existing PR)
 	branchExists, err = repo.CheckoutOrCreateBranch(branchName)
@@ -137,9 +296,9 @@ func applyFileUpdates(config *configuration.Config, filePath string, updates []*
 	}
 
 	if branchExists {
-		fmt.Printf("  🔄 Reusing existing branch: %s\n", branchName)
+		fmt.Fprintf(util.StatusOutput(), "  🔄 Reusing existing branch: %s\n", branchName)
 	} else {
-		fmt.Printf("  📝 Created new branch: %s\n", branchName)
+		fmt.Fprintf(util.StatusOutput(), "  📝 Created new branch: %s\n", branchName)
 	}
 
 	// Check for uncommitted changes from a previous run
@@ -149,29 +308,52 @@ func applyFileUpdates(config *configuration.Config, filePath string, updates []*
 	}
 
 	if hasUncommitted && branchExists {
-		fmt.Printf("  ⚠️  Found uncommitted changes from previous run, will include them\n")
+		fmt.Fprintf(util.StatusOutput(), "  ⚠️  Found uncommitted changes from previous run, will include them\n")
 	}
 
 	// Apply each update to the file
 	for _, update := range updates {
-		if err = applyUpdate(config, update); err != nil {
+		if err = applyUpdate(config, update, repo.WorktreePath(update.TargetFile)); err != nil {
 			return nil, false, false, fmt.Errorf("failed to apply update for %s: %w", update.ItemName, err)
 		}
 
-		fmt.Printf("  ✓ Updated %s: %s → %s\n",
+		fmt.Fprintf(util.StatusOutput(), "  ✓ Updated %s: %s → %s\n",
 			update.ItemName,
 			update.CurrentVersion,
 			update.LatestVersion)
+		audit.recordFileWritten(update)
 	}
 
-	// Get relative path for commit
-	relPath, err := filepath.Rel(repo.WorkingDirectory, filePath)
-	if err != nil {
-		relPath = filePath
+	// Run the postUpdate hooks of the updated targets, and those of the patch group once after
+	// its last updates, a failing hook aborts the group
+	var groupHooks []string
+	if settings := config.PatchGroups[group.Name]; settings != nil && isLastFile {
+		groupHooks = settings.PostUpdate
+	}
+	var hookFiles []string
+	if hookFiles, err = runPostUpdateHooks(repo, group, updates, groupHooks); err != nil {
+		return nil, false, false, err
+	}
+
+	// Get relative paths for commit, including files changed by hooks
+	relPaths := make([]string, 0, len(unit.Files)+len(hookFiles))
+	for _, filePath := range unit.Files {
+		relPaths = append(relPaths, repo.RelativePath(filePath))
+	}
+	relPaths = append(relPaths, hookFiles...)
+
+	// Record the updates in the changelog of the repository as part of the same commit
+	if config.TargetActor != nil && config.TargetActor.Changelog != nil {
+		var changelogPath string
+		if changelogPath, err = appendChangelogEntry(repo, config.TargetActor.Changelog, group, updates, time.Now()); err != nil {
+			return nil, false, false, fmt.Errorf("failed to update changelog: %w", err)
+		}
+		relPaths = append(relPaths, changelogPath)
+		fmt.Fprintf(util.StatusOutput(), "  ✓ Added changelog entry to %s\n", changelogPath)
 	}
 
 	// Create commit message
-	commitMessage := buildCommitMessage(updates, group)
+	commitMessage := buildCommitMessage(config.TargetActor, updates, group)
 
 	// Check if there are changes to commit
 	hasChanges, err := repo.HasUncommittedChanges()
@@ -184,17 +366,29 @@ func applyFileUpdates(config *configuration.Config, filePath string, updates []*
 		// Commit changes
 		commitOptions := &git.CommitOptions{
 			Message: commitMessage,
-			Files:   []string{relPath},
+			Files:   relPaths,
 		}
 
 		if err = repo.Commit(commitOptions); err != nil {
 			return nil, false, false, fmt.Errorf("failed to commit changes: %w", err)
 		}
 
-		fmt.Printf("  📝 Created commit: %s\n", commitMessage)
+		fmt.Fprintf(util.StatusOutput(), "  📝 Created commit: %s\n", commitMessage)
 		needsPush = true
+
+		commit, shaErr := repo.GetBranchCommit("HEAD")
+		if shaErr != nil {
+			log.Warn().Err(shaErr).Msg("Failed to resolve commit SHA for audit log")
+		}
+		audit.record(&AuditEvent{
+			Event:      auditEventCommitCreated,
+			PatchGroup: group.Name,
+			Branch:     repo.BranchName,
+			File:       strings.Join(relPaths, ","),
+			Commit:     commit,
+		})
 	} else {
-		fmt.Printf("  ℹ️  No new changes to commit\n")
+		fmt.Fprintf(util.StatusOutput(), "  ℹ️  No new changes to commit\n")
 
 		// Check if there are unpushed commits from a previous run
 		hasUnpushed, err := repo.HasUnpushedCommits()
@@ -203,10 +397,10 @@ func applyFileUpdates(config *configuration.Config, filePath string, updates []*
 		}
 
 		if hasUnpushed {
-			fmt.Printf("  📦 Found unpushed commits from previous run\n")
+			fmt.Fprintf(util.StatusOutput(), "  📦 Found unpushed commits from previous run\n")
 			lastCommit, _ := repo.GetLastCommitMessage()
 			if lastCommit != "" {
-				fmt.Printf("  📝 Last commit: %s\n", lastCommit)
+				fmt.Fprintf(util.StatusOutput(), "  📝 Last commit: %s\n", lastCommit)
 			}
 			needsPush = true
 		}
@@ -215,27 +409,67 @@ func applyFileUpdates(config *configuration.Config, filePath string, updates []*
 	// Track whether branch was pushed
 	branchPushed = false
 
+	// Keep the branch local while it would conflict with open pull requests of others
+	if isLastFile && needsPush && holdBackConflictingBranch(repo, config, group, options.OnConflict) {
+		return repo, branchExists, false, nil
+	}
+
 	// Push branch only if this is the last file (after all commits are made)
 	if isLastFile && needsPush {
 		if err = repo.Push(); err != nil {
 			return nil, false, false, fmt.Errorf("failed to push branch: %w", err)
 		}
-		fmt.Printf("  📤 Pushed branch to remote\n")
+		fmt.Fprintf(util.StatusOutput(), "  📤 Pushed branch to remote\n")
 		branchPushed = true
+		audit.record(&AuditEvent{
+			Event:      auditEventBranchPushed,
+			PatchGroup: group.Name,
+			Branch:     repo.BranchName,
+		})
 	} else if isLastFile && !needsPush {
-		fmt.Printf("  ℹ️  No changes to push\n")
+		fmt.Fprintf(util.StatusOutput(), "  ℹ️  No changes to push\n")
 	}
 
 	return repo, branchExists, branchPushed, nil
 }
 
-// applyUpdate applies a single update to a target
-func applyUpdate(config *configuration.Config, update *UpdateItem) error {
+// openWorktree returns the worktree of a repository for the patch group, adding it on first use
+func openWorktree(worktrees map[string]*git.Repository, repo *git.Repository) (*git.Repository, error) {
+	if worktree, ok := worktrees[repo.WorkingDirectory]; ok {
+		return worktree, nil
+	}
+
+	worktree, err := repo.AddWorktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to add worktree: %w", err)
+	}
+	worktrees[repo.WorkingDirectory] = worktree
+	fmt.Fprintf(util.StatusOutput(), "  🌿 Working in worktree %s\n", worktree.WorkingDirectory)
+	return worktree, nil
+}
+
+// removeWorktrees removes the worktrees of a patch group
+func removeWorktrees(worktrees map[string]*git.Repository) {
+	for _, worktree := range worktrees {
+		if err := worktree.RemoveWorktree(); err != nil {
+			log.Warn().Err(err).Str("worktree", worktree.WorkingDirectory).Msg("Failed to remove worktree")
+		}
+	}
+}
+
+// applyUpdate applies a single update to a target, writing the target file at filePath,
+// e.g. its copy in a worktree
+func applyUpdate(config *configuration.Config, update *UpdateItem, filePath string) error {
 	// Find the target and item configuration
-	targetConfig, updateItemConfig := findTargetAndItemByFile(config, update.TargetFile, update.SourceName)
+	targetConfig, updateItemConfig := findTargetAndItemByFile(config, update)
 	if targetConfig == nil || updateItemConfig == nil {
 		return fmt.Errorf("could not find target configuration for %s", update.TargetFile)
 	}
+	if filePath != targetConfig.File {
+		relocated := *targetConfig
+		relocated.File = filePath
+		targetConfig = &relocated
+	}
 
 	// Create target factory
 	targetFactory := target.NewTargetFactory(config)
@@ -246,6 +480,18 @@ func applyUpdate(config *configuration.Config, update *UpdateItem) error {
 		return fmt.Errorf("failed to create target client: %w", err)
 	}
 
+	// Pin the digest of the new version next to its tag
+	if update.Digest != "" {
+		digestWriter, ok := targetClient.(target.DigestWriter)
+		if !ok {
+			return fmt.Errorf("target %s does not support digest pinning", update.TargetFile)
+		}
+		if err := digestWriter.WriteDigest(update.LatestVersion, update.Digest); err != nil {
+			return fmt.Errorf("failed to write digest: %w", err)
+		}
+		return nil
+	}
+
 	// Write new version
 	if err := targetClient.WriteVersion(update.LatestVersion); err != nil {
 		return fmt.Errorf("failed to write version: %w", err)
@@ -254,15 +500,16 @@ func applyUpdate(config *configuration.Config, update *UpdateItem) error {
 	return nil
 }
 
-// findTargetAndItemByFile finds target and item configuration by file path and source
-func findTargetAndItemByFile(config *configuration.Config, filePath string, sourceName string) (*configuration.Target, *configuration.TargetItem) {
+// findTargetAndItemByFile finds target and item configuration of an update by file path,
+// source and item name, so several items of one source in the same file stay apart
+func findTargetAndItemByFile(config *configuration.Config, update *UpdateItem) (*configuration.Target, *configuration.TargetItem) {
 	for _, target := range config.Targets {
-		if target.File != filePath {
+		if target.File != update.TargetFile {
 			continue
 		}
 
 		for _, item := range target.Items {
-			if item.Source == sourceName {
+			if item.Source == update.SourceName && updateItemName(config, &item, item.Source) == update.ItemName {
 				return target, &item
 			}
 		}
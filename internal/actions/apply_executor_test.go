@@ -0,0 +1,214 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestRepoLocks(t *testing.T) {
+	locks := &repoLocks{locks: make(map[string]*sync.Mutex)}
+	active := make(map[string]int)
+	var mu sync.Mutex
+	var overlaps atomic.Int32
+
+	// Groups spanning both repositories in either order must neither overlap nor deadlock
+	rootSets := [][]string{{"/repo/a"}, {"/repo/a", "/repo/b"}, {"/repo/b"}, {"/repo/a", "/repo/b"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		roots := rootSets[i%len(rootSets)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(roots)
+			defer unlock()
+
+			mu.Lock()
+			for _, root := range roots {
+				if active[root]++; active[root] > 1 {
+					overlaps.Add(1)
+				}
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			for _, root := range roots {
+				active[root]--
+			}
+			mu.Unlock()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("repository locks deadlocked")
+	}
+	if overlaps.Load() != 0 {
+		t.Errorf("expected groups of the same repository never to overlap, got %d overlaps", overlaps.Load())
+	}
+	if len(locks.locks) != 2 {
+		t.Errorf("expected one lock per repository, got %d", len(locks.locks))
+	}
+}
+
+// newPatchGroups returns patch groups named after the repositories they update
+func newPatchGroups(repos ...string) ([]*PatchGroup, func(*PatchGroup) []string) {
+	groups := make([]*PatchGroup, 0, len(repos))
+	roots := make(map[*PatchGroup][]string)
+	for i, repo := range repos {
+		group := &PatchGroup{Name: fmt.Sprintf("group-%d", i)}
+		groups = append(groups, group)
+		roots[group] = []string{repo}
+	}
+	return groups, func(group *PatchGroup) []string { return roots[group] }
+}
+
+func TestRunPatchGroups_Sequential(t *testing.T) {
+	groups, roots := newPatchGroups("/repo/a", "/repo/b", "/repo/c")
+
+	var applied []string
+	err := runPatchGroups(groups, 1, roots, func(i int, group *PatchGroup) error {
+		applied = append(applied, group.Name)
+		if i == 1 {
+			return errors.New("push rejected")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "push rejected" {
+		t.Fatalf("expected the error of the failed group, got %v", err)
+	}
+	if len(applied) != 2 || applied[1] != "group-1" {
+		t.Errorf("expected groups after the failed group not to run, got %v", applied)
+	}
+}
+
+func TestRunPatchGroups_Parallel(t *testing.T) {
+	groups, roots := newPatchGroups("/repo/a", "/repo/a", "/repo/b", "/repo/c", "/repo/c")
+
+	var mu sync.Mutex
+	running := make(map[string]int)
+	var maxRunning, overlaps int
+	var total int
+	var applied atomic.Int32
+	err := runPatchGroups(groups, 2, roots, func(i int, group *PatchGroup) error {
+		repo := roots(group)[0]
+		mu.Lock()
+		running[repo]++
+		total++
+		if running[repo] > 1 {
+			overlaps++
+		}
+		maxRunning = max(maxRunning, total)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		applied.Add(1)
+
+		mu.Lock()
+		running[repo]--
+		total--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied.Load() != int32(len(groups)) {
+		t.Errorf("expected all %d groups to be applied, got %d", len(groups), applied.Load())
+	}
+	if overlaps != 0 {
+		t.Errorf("expected groups of the same repository never to overlap, got %d overlaps", overlaps)
+	}
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 groups at a time, got %d", maxRunning)
+	}
+}
+
+func TestRunPatchGroups_WaitingGroupsDoNotHoldSlots(t *testing.T) {
+	// Whichever group of repository a runs first waits for the group of repository b. If
+	// the other group of repository a held the second slot while waiting for the repository
+	// lock, the group of repository b could never start.
+	groups, roots := newPatchGroups("/repo/a", "/repo/a", "/repo/b")
+	started := make(chan struct{})
+
+	done := make(chan error)
+	go func() {
+		done <- runPatchGroups(groups, 2, roots, func(i int, group *PatchGroup) error {
+			if roots(group)[0] == "/repo/b" {
+				close(started)
+				return nil
+			}
+			select {
+			case <-started:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("group of another repository did not start")
+			}
+		})
+	}()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunPatchGroups_ParallelFailure(t *testing.T) {
+	groups, roots := newPatchGroups("/repo/a", "/repo/a", "/repo/a")
+
+	var applied atomic.Int32
+	err := runPatchGroups(groups, 2, roots, func(i int, group *PatchGroup) error {
+		applied.Add(1)
+		return fmt.Errorf("failed to apply %s", group.Name)
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	// The groups share a repository, so the first one to run fails and the others are skipped
+	if applied.Load() != 1 {
+		t.Errorf("expected no group to start after a failure, got %d applied", applied.Load())
+	}
+}
+
+func TestFindTargetAndItemByFile(t *testing.T) {
+	config := &configuration.Config{
+		PackageSources: []*configuration.PackageSource{{Name: "app"}},
+		Targets: []*configuration.Target{
+			{
+				Name: "app",
+				Type: configuration.TargetTypeYamlField,
+				File: "values.yaml",
+				Items: []configuration.TargetItem{
+					{YamlPath: "server.image.tag", Source: "app"},
+					{YamlPath: "worker.image.tag", Source: "app"},
+				},
+			},
+		},
+	}
+
+	for _, path := range []string{"server.image.tag", "worker.image.tag"} {
+		target, item := findTargetAndItemByFile(config, &UpdateItem{TargetFile: "values.yaml", SourceName: "app", ItemName: path})
+		if target == nil || item == nil {
+			t.Fatalf("%s: expected to find the item", path)
+		}
+		if item.YamlPath != path {
+			t.Errorf("expected item %s, got %s", path, item.YamlPath)
+		}
+	}
+
+	if target, _ := findTargetAndItemByFile(config, &UpdateItem{TargetFile: "values.yaml", SourceName: "app", ItemName: "missing"}); target != nil {
+		t.Error("expected no match for an unknown item")
+	}
+}
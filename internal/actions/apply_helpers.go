@@ -1,7 +1,9 @@
 package actions
 
 import (
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/mxcd/updater/internal/compare"
 	"github.com/mxcd/updater/internal/configuration"
@@ -36,30 +38,14 @@ func buildUpdateItems(config *configuration.Config, results []*compare.Compariso
 			patchGroup = "default"
 		}
 
-		// Merge labels (target labels + item labels)
+		// Merge labels (target labels + item labels), flagging risky updates
 		labels := mergeLabels(targetConfig.Labels, updateItemConfig.Labels)
-
-		// Determine item name to display (priority: type-specific field > Name > SourceName)
-		itemName := updateItemConfig.TerraformVariableName
-		if itemName == "" {
-			itemName = updateItemConfig.SubchartName
-		}
-		if itemName == "" {
-			itemName = updateItemConfig.YamlPath
-		}
-		if itemName == "" {
-			itemName = updateItemConfig.Name
-		}
-		if itemName == "" {
-			// Find the source to get its name as fallback
-			for _, source := range config.PackageSources {
-				if source.Name == result.SourceName {
-					itemName = source.Name
-					break
-				}
-			}
+		if label := riskLabel(result.Risk); label != "" {
+			labels = mergeLabels(labels, []string{label})
 		}
 
+		itemName := updateItemName(config, updateItemConfig, result.SourceName)
+
 		item := &UpdateItem{
 			TargetName:      result.TargetName,
 			TargetFile:      result.TargetFile,
@@ -68,10 +54,24 @@ func buildUpdateItems(config *configuration.Config, results []*compare.Compariso
 			CurrentVersion:  result.CurrentVersion,
 			LatestVersion:   result.LatestVersion,
 			UpdateType:      result.UpdateType,
+			Risk:            result.Risk,
+			RiskReasons:     result.RiskReasons,
+			SkippedVersions: result.SkippedVersions,
+			SkippedPartial:  result.SkippedVersionsIncomplete,
+			CVEs:            result.CVEs(),
 			PatchGroup:      patchGroup,
 			Labels:          labels,
+			Reviewers:       targetConfig.Reviewers,
+			Assignees:       targetConfig.Assignees,
+			Milestone:       targetConfig.Milestone,
+			Projects:        targetConfig.Projects,
+			ClosesIssues:    targetConfig.ClosesIssues,
+			PostUpdate:      targetConfig.PostUpdate,
 			WildcardPattern: targetConfig.WildcardPattern,
 			IsWildcardMatch: targetConfig.IsWildcardMatch,
+			VersionSet:      result.VersionSet,
+			ImageCheck:      configuration.ResolveImageCheck(targetConfig, updateItemConfig),
+			DigestPin:       updateItemConfig.DigestPin,
 		}
 
 		items = append(items, item)
@@ -93,14 +93,82 @@ func findTargetAndItem(config *configuration.Config, result *compare.ComparisonR
 				itemName = target.Name
 			}
 
-			if itemName == result.TargetName && item.Source == result.SourceName {
-				return target, &item
+			if itemName != result.TargetName || item.Source != result.SourceName {
+				continue
 			}
+			if result.TargetItemName != "" && updateItemName(config, &item, item.Source) != result.TargetItemName {
+				continue
+			}
+			return target, &item
 		}
 	}
 	return nil, nil
 }
 
+// updateItemName returns the name an item is displayed and identified by within its target
+// file (priority: type-specific field > Name > SourceName)
+func updateItemName(config *configuration.Config, item *configuration.TargetItem, sourceName string) string {
+	itemName := item.TerraformVariableName
+	if itemName == "" {
+		itemName = item.SubchartName
+	}
+	if itemName == "" {
+		itemName = item.YamlPath
+	}
+	if itemName == "" {
+		itemName = item.JsonnetPath
+	}
+	if itemName == "" && item.ReleaseName != "" {
+		itemName = item.ReleaseName
+		if item.ValuePath != "" {
+			itemName += " " + item.ValuePath
+		}
+	}
+	if itemName == "" {
+		itemName = item.CrateName
+	}
+	if itemName == "" {
+		itemName = item.Artifact
+	}
+	if itemName == "" {
+		itemName = item.Property
+	}
+	if itemName == "" {
+		itemName = item.CatalogEntry
+	}
+	if itemName == "" {
+		itemName = item.VariableName
+	}
+	if itemName == "" {
+		itemName = item.ToolName
+	}
+	if itemName == "" {
+		itemName = item.Image
+	}
+	if itemName == "" {
+		itemName = item.Name
+	}
+	if itemName == "" {
+		// Find the source to get its name as fallback
+		for _, source := range config.PackageSources {
+			if source.Name == sourceName {
+				itemName = source.Name
+				break
+			}
+		}
+	}
+	return itemName
+}
+
+// riskLabel returns the pull request label of a medium or high risk update, e.g. risk/high,
+// or an empty string for low risk
+func riskLabel(risk compare.RiskLevel) string {
+	if risk != compare.RiskLevelMedium && risk != compare.RiskLevelHigh {
+		return ""
+	}
+	return "risk/" + string(risk)
+}
+
 // mergeLabels merges two label slices, removing duplicates
 func mergeLabels(targetLabels, itemLabels []string) []string {
 	labelMap := make(map[string]bool)
@@ -142,8 +210,21 @@ func groupUpdatesByPatchGroup(items []*UpdateItem) []*PatchGroup {
 
 		group.Updates = append(group.Updates, item)
 
-		// Merge labels from all items in the group
+		// Merge labels, reviewers and assignees from all items in the group
 		group.Labels = mergeLabels(group.Labels, item.Labels)
+		group.Reviewers = mergeLabels(group.Reviewers, item.Reviewers)
+		group.Assignees = mergeLabels(group.Assignees, item.Assignees)
+		group.Projects = mergeLabels(group.Projects, item.Projects)
+		group.ClosesIssues = mergeLabels(group.ClosesIssues, item.ClosesIssues)
+		if group.Milestone == "" {
+			group.Milestone = item.Milestone
+		} else if item.Milestone != "" && item.Milestone != group.Milestone {
+			log.Warn().
+				Str("patchGroup", group.Name).
+				Str("milestone", group.Milestone).
+				Str("ignored", item.Milestone).
+				Msg("Targets of the patch group set different milestones, using the first")
+		}
 	}
 
 	// Convert map to sorted slice for deterministic ordering
@@ -158,6 +239,76 @@ func groupUpdatesByPatchGroup(items []*UpdateItem) []*PatchGroup {
 	return groups
 }
 
+// commitStrategy returns the commit strategy of the target actor, per-file if not set
+func commitStrategy(config *configuration.Config) configuration.CommitStrategy {
+	if config.TargetActor == nil || config.TargetActor.CommitStrategy == "" {
+		return configuration.CommitStrategyPerFile
+	}
+	return config.TargetActor.CommitStrategy
+}
+
+// scheduleDeferral returns why a patch group is deferred if its schedule does not allow
+// updates at the given time, or an empty string if it may be applied
+func scheduleDeferral(config *configuration.Config, group *PatchGroup, now time.Time) string {
+	settings := config.PatchGroups[group.Name]
+	if settings == nil || settings.Schedule == "" {
+		return ""
+	}
+
+	schedule, err := configuration.ParseSchedule(settings.Schedule)
+	if err != nil {
+		log.Warn().Err(err).Str("patchGroup", group.Name).Msg("Ignoring invalid schedule")
+		return ""
+	}
+	if schedule.Allows(now) {
+		return ""
+	}
+
+	if next, ok := schedule.Next(now); ok {
+		return fmt.Sprintf("outside schedule %q, next window opens %s", settings.Schedule, next.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	return fmt.Sprintf("outside schedule %q", settings.Schedule)
+}
+
+// assignBranchNames renders the update branch name of every patch group from the branch
+// template of the target actor
+func assignBranchNames(targetActor *configuration.TargetActor, groups []*PatchGroup, now time.Time) error {
+	branchTemplate := ""
+	maxLength := 0
+	if targetActor != nil {
+		branchTemplate = targetActor.BranchTemplate
+		maxLength = targetActor.BranchMaxLength
+	}
+
+	for _, group := range groups {
+		data := &configuration.BranchNameData{
+			PatchGroup: group.Name,
+			Date:       now.Format("2006-01-02"),
+		}
+		for i, update := range group.Updates {
+			if i == 0 {
+				data.Source = update.SourceName
+				data.Version = update.LatestVersion
+				continue
+			}
+			if update.SourceName != data.Source {
+				data.Source = ""
+			}
+			if update.LatestVersion != data.Version {
+				data.Version = ""
+			}
+		}
+
+		branchName, err := configuration.RenderBranchName(branchTemplate, data, maxLength)
+		if err != nil {
+			return fmt.Errorf("patch group %s: %w", group.Name, err)
+		}
+		group.BranchName = branchName
+	}
+
+	return nil
+}
+
 // groupUpdatesByFile groups updates by target file
 func groupUpdatesByFile(updates []*UpdateItem) map[string][]*UpdateItem {
 	fileMap := make(map[string][]*UpdateItem)
@@ -168,3 +319,117 @@ func groupUpdatesByFile(updates []*UpdateItem) map[string][]*UpdateItem {
 
 	return fileMap
 }
+
+// groupUpdatesIntoCommits groups updates into commit units according to the commit strategy.
+// Units are sorted for deterministic processing.
+func groupUpdatesIntoCommits(updates []*UpdateItem, strategy configuration.CommitStrategy) []*CommitUnit {
+	switch strategy {
+	case configuration.CommitStrategySingle:
+		if len(updates) == 0 {
+			return []*CommitUnit{}
+		}
+		return []*CommitUnit{newCommitUnit(updates)}
+	case configuration.CommitStrategyPerItem:
+		return groupUpdatesByItem(updates)
+	default:
+		return groupUpdatesByCommitFile(updates)
+	}
+}
+
+// groupUpdatesByItem creates one commit unit per update, except that updates of the same
+// version set are committed together
+func groupUpdatesByItem(updates []*UpdateItem) []*CommitUnit {
+	grouped := make([][]*UpdateItem, 0, len(updates))
+	setIndex := make(map[string]int)
+	for _, update := range updates {
+		if update.VersionSet != "" {
+			if i, exists := setIndex[update.VersionSet]; exists {
+				grouped[i] = append(grouped[i], update)
+				continue
+			}
+			setIndex[update.VersionSet] = len(grouped)
+		}
+		grouped = append(grouped, []*UpdateItem{update})
+	}
+
+	units := make([]*CommitUnit, 0, len(grouped))
+	for _, unitUpdates := range grouped {
+		units = append(units, newCommitUnit(unitUpdates))
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].Files[0] < units[j].Files[0]
+	})
+
+	return units
+}
+
+// newCommitUnit creates a commit unit of the given updates, ordered by file
+func newCommitUnit(updates []*UpdateItem) *CommitUnit {
+	fileGroups := groupUpdatesByFile(updates)
+	unit := &CommitUnit{Files: make([]string, 0, len(fileGroups))}
+	for filePath := range fileGroups {
+		unit.Files = append(unit.Files, filePath)
+	}
+	sort.Strings(unit.Files)
+	for _, filePath := range unit.Files {
+		unit.Updates = append(unit.Updates, fileGroups[filePath]...)
+	}
+	return unit
+}
+
+// groupUpdatesByCommitFile creates one commit unit per file, except that files linked by a
+// version set are committed together
+func groupUpdatesByCommitFile(updates []*UpdateItem) []*CommitUnit {
+	fileGroups := groupUpdatesByFile(updates)
+
+	// Union files sharing a version set
+	parent := make(map[string]string)
+	var find func(file string) string
+	find = func(file string) string {
+		if parent[file] == "" || parent[file] == file {
+			return file
+		}
+		root := find(parent[file])
+		parent[file] = root
+		return root
+	}
+
+	setFiles := make(map[string]string)
+	for _, update := range updates {
+		if update.VersionSet == "" {
+			continue
+		}
+		if first, exists := setFiles[update.VersionSet]; exists {
+			if a, b := find(first), find(update.TargetFile); a != b {
+				parent[b] = a
+			}
+		} else {
+			setFiles[update.VersionSet] = update.TargetFile
+		}
+	}
+
+	unitMap := make(map[string]*CommitUnit)
+	for filePath := range fileGroups {
+		root := find(filePath)
+		unit, exists := unitMap[root]
+		if !exists {
+			unit = &CommitUnit{}
+			unitMap[root] = unit
+		}
+		unit.Files = append(unit.Files, filePath)
+	}
+
+	units := make([]*CommitUnit, 0, len(unitMap))
+	for _, unit := range unitMap {
+		sort.Strings(unit.Files)
+		for _, filePath := range unit.Files {
+			unit.Updates = append(unit.Updates, fileGroups[filePath]...)
+		}
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Files[0] < units[j].Files[0]
+	})
+
+	return units
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// hookTimeout bounds the runtime of a single postUpdate hook
+const hookTimeout = 10 * time.Minute
+
+// maxHookOutput is the number of trailing output bytes of a hook kept for the PR body
+const maxHookOutput = 4000
+
+// runPostUpdateHooks runs the postUpdate hooks of the targets updated in a commit unit in the
+// repository root, once per target file and command, followed by the given hooks of the patch
+// group, and returns the files the hooks changed in addition to the updated files. The first
+// failing hook aborts with its output.
+func runPostUpdateHooks(repo *git.Repository, group *PatchGroup, updates []*UpdateItem, groupHooks []string) ([]string, error) {
+	type hook struct {
+		command    string
+		targetFile string
+	}
+	hooks := make([]hook, 0)
+	seen := make(map[hook]bool)
+	for _, update := range updates {
+		for _, command := range update.PostUpdate {
+			h := hook{command: command, targetFile: repo.RelativePath(update.TargetFile)}
+			if !seen[h] {
+				seen[h] = true
+				hooks = append(hooks, h)
+			}
+		}
+	}
+	for _, command := range groupHooks {
+		hooks = append(hooks, hook{command: command})
+	}
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+
+	changedBefore, err := repo.ChangedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hooks {
+		log.Debug().Str("command", h.command).Str("target", h.targetFile).Msg("Running postUpdate hook")
+
+		// Hooks of the patch group run once for all targets and have no target file
+		env := []string{"UPDATER_PATCH_GROUP=" + group.Name}
+		subject := "patch group " + group.Name
+		if h.targetFile != "" {
+			env = append(env, "UPDATER_TARGET_FILE="+h.targetFile, "UPDATER_TARGET_DIR="+path.Dir(h.targetFile))
+			subject = h.targetFile
+		}
+
+		output, err := runHook(repo.WorkingDirectory, h.command, env)
+		if err != nil {
+			return nil, fmt.Errorf("postUpdate hook %q for %s failed: %w, output: %s", h.command, subject, err, tailOutput(output))
+		}
+
+		group.HookResults = append(group.HookResults, &HookResult{
+			Command:    h.command,
+			TargetFile: h.targetFile,
+			Output:     tailOutput(output),
+		})
+		fmt.Fprintf(util.StatusOutput(), "  🪝 Ran postUpdate hook for %s: %s\n", subject, h.command)
+	}
+
+	changedAfter, err := repo.ChangedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	wasChanged := make(map[string]bool, len(changedBefore))
+	for _, file := range changedBefore {
+		wasChanged[file] = true
+	}
+	hookFiles := make([]string, 0)
+	for _, file := range changedAfter {
+		if !wasChanged[file] {
+			hookFiles = append(hookFiles, file)
+		}
+	}
+	return hookFiles, nil
+}
+
+// runHook runs a shell command in a directory with additional environment variables and
+// returns its combined output
+func runHook(dir string, command string, env []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(output), fmt.Errorf("timed out after %s", hookTimeout)
+	}
+	return string(output), err
+}
+
+// tailOutput trims hook output to its last maxHookOutput bytes
+func tailOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxHookOutput {
+		start := len(output) - maxHookOutput
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		return "…" + output[start:]
+	}
+	return output
+}
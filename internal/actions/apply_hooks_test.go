@@ -0,0 +1,64 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/git"
+)
+
+func TestRunPostUpdateHooks_PatchGroup(t *testing.T) {
+	dir, run := newTestRepository(t)
+	writeTestFile(t, filepath.Join(dir, "apps", "server", "values.yaml"), "tag: 1.0.0\n")
+	writeTestFile(t, filepath.Join(dir, "apps", "worker", "values.yaml"), "tag: 1.0.0\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	targetHook := `echo "target $UPDATER_TARGET_FILE" >> hooks.log`
+	groupHook := `echo "group $UPDATER_PATCH_GROUP ${UPDATER_TARGET_FILE:-none}" >> hooks.log`
+	updates := []*UpdateItem{
+		{TargetFile: filepath.Join(dir, "apps", "server", "values.yaml"), PostUpdate: []string{targetHook}},
+		{TargetFile: filepath.Join(dir, "apps", "worker", "values.yaml"), PostUpdate: []string{targetHook}},
+	}
+	group := &PatchGroup{Name: "production", Updates: updates}
+
+	hookFiles, err := runPostUpdateHooks(git.NewRepository(dir, nil), group, updates, []string{groupHook})
+	if err != nil {
+		t.Fatalf("runPostUpdateHooks() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "hooks.log"))
+	if err != nil {
+		t.Fatalf("failed to read hook log: %v", err)
+	}
+	expected := "target apps/server/values.yaml\ntarget apps/worker/values.yaml\ngroup production none\n"
+	if string(data) != expected {
+		t.Errorf("expected the group hook to run once after the target hooks, got:\n%s", data)
+	}
+	if len(hookFiles) != 1 || hookFiles[0] != "hooks.log" {
+		t.Errorf("expected the file changed by the hooks, got %v", hookFiles)
+	}
+	if len(group.HookResults) != 3 || group.HookResults[2].TargetFile != "" || group.HookResults[2].Command != groupHook {
+		t.Errorf("expected the group hook to be recorded last without target file, got %+v", group.HookResults)
+	}
+
+	body := buildPRBody(updates, group)
+	if !strings.Contains(body, "(patch group)") {
+		t.Errorf("expected the group hook in the pull request body, got:\n%s", body)
+	}
+}
+
+func TestRunPostUpdateHooks_PatchGroupFailure(t *testing.T) {
+	dir, run := newTestRepository(t)
+	writeTestFile(t, filepath.Join(dir, "values.yaml"), "tag: 1.0.0\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	group := &PatchGroup{Name: "production"}
+	_, err := runPostUpdateHooks(git.NewRepository(dir, nil), group, nil, []string{"exit 3"})
+	if err == nil || !strings.Contains(err.Error(), "for patch group production failed") {
+		t.Errorf("expected the failing group hook to abort, got %v", err)
+	}
+}
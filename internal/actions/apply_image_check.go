@@ -0,0 +1,150 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mxcd/updater/internal/cluster"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// Image check decisions recorded in the audit log
+const (
+	imageCheckPullable = "pullable"
+	imageCheckMissing  = "missing"
+)
+
+// checkImages drops the updates whose new tag cannot be pulled from the registry the target
+// deploys from, e.g. because a mirror has not synced it yet. Registry errors drop the update
+// as well, so an unreachable registry never lets an update through. Dropped updates and the
+// other updates of their version set are proposed again by the next run.
+func checkImages(config *configuration.Config, items []*UpdateItem, client *http.Client, audit *auditLog) []*UpdateItem {
+	checked := make(map[string]error)
+	available := make([]*UpdateItem, 0, len(items))
+	for _, item := range items {
+		if item.ImageCheck == nil {
+			available = append(available, item)
+			continue
+		}
+
+		image, provider, err := resolveCheckedImage(config, item)
+		tag := imageCheckTag(item.LatestVersion)
+		if err == nil {
+			key := image + ":" + tag
+			var done bool
+			if err, done = checked[key]; !done {
+				err = checkImage(client, provider, image, tag, item.ImageCheck.Platforms)
+				checked[key] = err
+			}
+		}
+
+		event := &AuditEvent{
+			Event:      auditEventImageCheck,
+			Target:     item.TargetName,
+			File:       item.TargetFile,
+			Item:       item.ItemName,
+			Source:     item.SourceName,
+			From:       item.CurrentVersion,
+			To:         item.LatestVersion,
+			Decision:   imageCheckPullable,
+			PatchGroup: item.PatchGroup,
+		}
+		if err != nil {
+			event.Decision = imageCheckMissing
+			event.Reason = err.Error()
+			log.Warn().
+				Err(err).
+				Str("item", item.ItemName).
+				Str("file", item.TargetFile).
+				Msg("New image tag is not pullable, skipping update")
+			fmt.Fprintf(util.StatusOutput(), "🚫 Image not pullable, skipping %s in %s: %s → %s (%v)\n",
+				item.ItemName, item.TargetFile, item.CurrentVersion, item.LatestVersion, err)
+		} else {
+			available = append(available, item)
+		}
+		audit.record(event)
+	}
+	return keepWholeVersionSets(items, available)
+}
+
+// resolveCheckedImage returns the image an update is checked against and the provider
+// holding the registry credentials. Without an image, the source image is checked, at the
+// mirror the registry map assigns to it or with the source's provider.
+func resolveCheckedImage(config *configuration.Config, item *UpdateItem) (string, *configuration.PackageSourceProvider, error) {
+	check := item.ImageCheck
+	image := check.Image
+	providerName := check.Provider
+	if image == "" {
+		source := findPackageSource(config, item.SourceName)
+		if source == nil || source.Type != configuration.PackageSourceTypeDockerImage {
+			return "", nil, fmt.Errorf("imageCheck requires an image for source '%s'", item.SourceName)
+		}
+		baseURL := ""
+		if provider := findProvider(config, source.Provider); provider != nil {
+			baseURL = provider.BaseUrl
+		}
+		name, err := docker.BuildImageName(baseURL, source.URI)
+		if err != nil {
+			return "", nil, err
+		}
+		// A mirror is not queried with the credentials of the upstream registry
+		image = configuration.MapImage(config.RegistryMap, name)
+		if providerName == "" && image == name {
+			providerName = source.Provider
+		}
+	}
+
+	var provider *configuration.PackageSourceProvider
+	if providerName != "" {
+		if provider = findProvider(config, providerName); provider == nil {
+			return "", nil, fmt.Errorf("provider '%s' not found", providerName)
+		}
+	}
+	return image, provider, nil
+}
+
+// checkImage checks that a tag of an image is published for all required platforms
+func checkImage(client *http.Client, provider *configuration.PackageSourceProvider, image string, tag string, requiredPlatforms []string) error {
+	platforms := make([]configuration.Platform, 0, len(requiredPlatforms))
+	for _, required := range requiredPlatforms {
+		platform, err := configuration.ParsePlatform(required)
+		if err != nil {
+			return err
+		}
+		platforms = append(platforms, platform)
+	}
+	return docker.CheckManifest(client, provider, image, tag, platforms)
+}
+
+// imageCheckTag returns the tag of the value written to a target, which is either the tag
+// itself or, with an extract pattern, a whole image reference
+func imageCheckTag(value string) string {
+	if strings.ContainsAny(value, ":/@") {
+		return cluster.ImageTag(value)
+	}
+	return value
+}
+
+// findPackageSource returns the package source with the given name, nil if not found
+func findPackageSource(config *configuration.Config, name string) *configuration.PackageSource {
+	for _, source := range config.PackageSources {
+		if source.Name == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// findProvider returns the provider with the given name, nil if not found
+func findProvider(config *configuration.Config, name string) *configuration.PackageSourceProvider {
+	for _, provider := range config.PackageSourceProviders {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestCheckImages(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/v2/team/app/manifests/1.1.0", "/v2/mirror/app/manifests/1.1.0":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	config := &configuration.Config{
+		PackageSourceProviders: []*configuration.PackageSourceProvider{
+			{Name: "registry", Type: configuration.PackageSourceProviderTypeDocker},
+		},
+		PackageSources: []*configuration.PackageSource{
+			{Name: "app", Provider: "registry", Type: configuration.PackageSourceTypeDockerImage, URI: registry + "/team/app"},
+		},
+	}
+	items := []*UpdateItem{
+		{ItemName: "unchecked", SourceName: "app", LatestVersion: "9.9.9"},
+		{ItemName: "source image", SourceName: "app", LatestVersion: "1.1.0", ImageCheck: &configuration.ImageCheck{}},
+		{ItemName: "source image again", SourceName: "app", LatestVersion: "1.1.0", ImageCheck: &configuration.ImageCheck{}},
+		{ItemName: "prefixed tag", SourceName: "app", LatestVersion: "v1.1.0", ImageCheck: &configuration.ImageCheck{Image: registry + "/mirror/app"}},
+		{ItemName: "image reference", SourceName: "app", LatestVersion: registry + "/mirror/app:1.1.0", ImageCheck: &configuration.ImageCheck{Image: registry + "/mirror/app"}},
+		{ItemName: "not synced", SourceName: "app", LatestVersion: "1.2.0", ImageCheck: &configuration.ImageCheck{Image: registry + "/mirror/app"}},
+		{ItemName: "unknown provider", SourceName: "app", LatestVersion: "1.1.0", ImageCheck: &configuration.ImageCheck{Provider: "missing"}},
+	}
+
+	available := checkImages(config, items, server.Client(), nil)
+
+	names := make([]string, 0, len(available))
+	for _, item := range available {
+		names = append(names, item.ItemName)
+	}
+	expected := []string{"unchecked", "source image", "source image again", "image reference"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v to be available, got %v", expected, names)
+	}
+	// Each image and tag is checked once, the unknown provider fails without a request
+	if requests != 4 {
+		t.Errorf("expected 4 registry requests, got %d", requests)
+	}
+}
+
+func TestCheckImages_RegistryMap(t *testing.T) {
+	var paths []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Errorf("upstream credentials sent to the mirror")
+		}
+		if r.URL.Path != "/v2/dockerhub/library/nginx/manifests/1.27.0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := &configuration.Config{
+		PackageSourceProviders: []*configuration.PackageSourceProvider{
+			{Name: "dockerhub", Type: configuration.PackageSourceProviderTypeDocker, AuthType: configuration.PackageSourceProviderAuthTypeBasic, Username: "user", Password: "secret"},
+		},
+		PackageSources: []*configuration.PackageSource{
+			{Name: "nginx", Provider: "dockerhub", Type: configuration.PackageSourceTypeDockerImage, URI: "nginx"},
+		},
+		RegistryMap: map[string]string{"docker.io": strings.TrimPrefix(server.URL, "https://") + "/dockerhub"},
+	}
+	items := []*UpdateItem{
+		{ItemName: "synced", SourceName: "nginx", LatestVersion: "1.27.0", ImageCheck: &configuration.ImageCheck{}},
+		{ItemName: "not synced", SourceName: "nginx", LatestVersion: "1.28.0", ImageCheck: &configuration.ImageCheck{}},
+	}
+
+	available := checkImages(config, items, server.Client(), nil)
+	if len(available) != 1 || available[0].ItemName != "synced" {
+		t.Errorf("expected only the synced tag to be available, got %d updates", len(available))
+	}
+	if len(paths) != 2 {
+		t.Errorf("expected both tags to be checked at the mirror, got %v", paths)
+	}
+}
+
+func TestImageCheckTag(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "1.2.3", expected: "1.2.3"},
+		{value: "v1.2.3-alpine", expected: "v1.2.3-alpine"},
+		{value: "example/app:1.2.3", expected: "1.2.3"},
+		{value: "registry.example.com:5000/app:1.2.3", expected: "1.2.3"},
+		{value: "example/app:1.2.3@sha256:abc", expected: "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if tag := imageCheckTag(tt.value); tag != tt.expected {
+				t.Errorf("imageCheckTag(%q) = %q, expected %q", tt.value, tag, tt.expected)
+			}
+		})
+	}
+}
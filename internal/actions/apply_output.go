@@ -2,12 +2,14 @@ package actions
 
 import (
 	"fmt"
-	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 )
 
 // splitByWildcard separates updates into sorted wildcard groups and non-wildcard updates.
@@ -47,6 +49,21 @@ func formatUpdateType(ut compare.UpdateType) string {
 	}
 }
 
+// formatRisk formats the risk level of an update, empty if there is no update
+func formatRisk(risk compare.RiskLevel) string {
+	s := string(risk)
+	switch risk {
+	case compare.RiskLevelHigh:
+		return "🔴 " + s
+	case compare.RiskLevelMedium:
+		return "🟡 " + s
+	case compare.RiskLevelLow:
+		return "🟢 " + s
+	default:
+		return s
+	}
+}
+
 // displayName returns the best display name for an update item.
 func displayName(update *UpdateItem) string {
 	if update.ItemName != "" {
@@ -56,25 +73,32 @@ func displayName(update *UpdateItem) string {
 }
 
 // outputDryRunPlan outputs the plan in dry-run mode
-func outputDryRunPlan(groups []*PatchGroup) {
-	fmt.Println("\n🔍 DRY RUN - Apply Plan")
-	fmt.Println("========================")
+func outputDryRunPlan(config *configuration.Config, groups []*PatchGroup, options *ApplyOptions) {
+	strategy := commitStrategy(config)
+	fmt.Fprintln(util.ResultOutput(), "\n🔍 DRY RUN - Apply Plan")
+	fmt.Fprintln(util.ResultOutput(), "========================")
 
 	totalCommits := 0
 	totalPRs := len(groups)
 
 	for i, group := range groups {
-		fmt.Printf("📦 Patch Group %d/%d: %s\n", i+1, len(groups), group.Name)
+		fmt.Fprintf(util.ResultOutput(), "📦 Patch Group %d/%d: %s\n", i+1, len(groups), group.Name)
+		fmt.Fprintf(util.ResultOutput(), "   Branch: %s\n", group.BranchName)
+		if !options.IgnoreSchedule {
+			if reason := scheduleDeferral(config, group, time.Now()); reason != "" {
+				fmt.Fprintf(util.ResultOutput(), "   ⏸️  Deferred: %s\n", reason)
+			}
+		}
 		if len(group.Labels) > 0 {
-			fmt.Printf("   Labels: %s\n", strings.Join(group.Labels, ", "))
+			fmt.Fprintf(util.ResultOutput(), "   Labels: %s\n", strings.Join(group.Labels, ", "))
 		}
-		fmt.Printf("   Updates: %d\n\n", len(group.Updates))
+		fmt.Fprintf(util.ResultOutput(), "   Updates: %d\n\n", len(group.Updates))
 
 		fileGroups := groupUpdatesByFile(group.Updates)
-		totalCommits += len(fileGroups)
+		commitUnits := groupUpdatesIntoCommits(group.Updates, strategy)
+		totalCommits += len(commitUnits)
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
+		t := util.NewTable()
 		t.AppendHeader(table.Row{"Target", "File", "Source", "Current", "→", "Latest", "Type"})
 
 		patterns, wildcardGroups, nonWildcardUpdates := splitByWildcard(group.Updates)
@@ -112,35 +136,52 @@ func outputDryRunPlan(groups []*PatchGroup) {
 			})
 		}
 
-		t.SetStyle(table.StyleRounded)
 		t.Render()
-		fmt.Println()
+		fmt.Fprintln(util.ResultOutput())
 
-		fmt.Printf("   📝 Would create: %d commit(s) in %d file(s)\n", len(fileGroups), len(fileGroups))
-		fmt.Printf("   🔀 Would create: 1 pull request\n")
+		fmt.Fprintf(util.ResultOutput(), "   📝 Would create: %d commit(s) in %d file(s)\n", len(commitUnits), len(fileGroups))
+		hooks := make([]string, 0)
+		for _, update := range group.Updates {
+			hooks = mergeLabels(hooks, update.PostUpdate)
+		}
+		if len(hooks) > 0 {
+			fmt.Fprintf(util.ResultOutput(), "   🪝 Would run hooks: %s\n", strings.Join(hooks, ", "))
+		}
+		fmt.Fprintf(util.ResultOutput(), "   🔀 Would create: 1 pull request\n")
 		if len(group.Labels) > 0 {
-			fmt.Printf("   🏷️  PR labels: %s\n", strings.Join(group.Labels, ", "))
+			fmt.Fprintf(util.ResultOutput(), "   🏷️  PR labels: %s\n", strings.Join(group.Labels, ", "))
+		}
+		if len(group.Reviewers) > 0 {
+			fmt.Fprintf(util.ResultOutput(), "   👀 PR reviewers: %s\n", strings.Join(group.Reviewers, ", "))
+		}
+		if len(group.Assignees) > 0 {
+			fmt.Fprintf(util.ResultOutput(), "   👤 PR assignees: %s\n", strings.Join(group.Assignees, ", "))
+		}
+		if group.Milestone != "" {
+			fmt.Fprintf(util.ResultOutput(), "   🎯 PR milestone: %s\n", group.Milestone)
+		}
+		if len(group.ClosesIssues) > 0 {
+			fmt.Fprintf(util.ResultOutput(), "   🔗 PR closes: %s\n", strings.Join(group.ClosesIssues, ", "))
 		}
-		fmt.Println()
+		fmt.Fprintln(util.ResultOutput())
 	}
 
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("📊 Summary:\n")
-	fmt.Printf("   • Total patch groups: %d\n", totalPRs)
-	fmt.Printf("   • Total commits: %d\n", totalCommits)
-	fmt.Printf("   • Total pull requests: %d\n", totalPRs)
-	fmt.Println()
-	fmt.Println("💡 This is a dry run. Use 'apply' without --dry-run to execute.")
+	fmt.Fprintln(util.ResultOutput(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(util.ResultOutput(), "📊 Summary:\n")
+	fmt.Fprintf(util.ResultOutput(), "   • Total patch groups: %d\n", totalPRs)
+	fmt.Fprintf(util.ResultOutput(), "   • Total commits: %d\n", totalCommits)
+	fmt.Fprintf(util.ResultOutput(), "   • Total pull requests: %d\n", totalPRs)
+	fmt.Fprintln(util.ResultOutput())
+	fmt.Fprintln(util.ResultOutput(), "💡 This is a dry run. Use 'apply' without --dry-run to execute.")
 }
 
 // outputLocalPlan outputs the plan for local-only mode (no git operations)
 func outputLocalPlan(updates []*UpdateItem) {
-	fmt.Println("\n📂 Local Apply Plan")
-	fmt.Println("====================")
-	fmt.Printf("   Updates: %d\n\n", len(updates))
+	fmt.Fprintln(util.ResultOutput(), "\n📂 Local Apply Plan")
+	fmt.Fprintln(util.ResultOutput(), "====================")
+	fmt.Fprintf(util.ResultOutput(), "   Updates: %d\n\n", len(updates))
 
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
+	t := util.NewTable()
 	t.AppendHeader(table.Row{"Target", "File", "Current", "→", "Latest", "Type"})
 
 	patterns, wildcardGroups, nonWildcardUpdates := splitByWildcard(updates)
@@ -176,24 +217,22 @@ func outputLocalPlan(updates []*UpdateItem) {
 		})
 	}
 
-	t.SetStyle(table.StyleRounded)
 	t.Render()
-	fmt.Println()
+	fmt.Fprintln(util.ResultOutput())
 }
 
 // outputApplyPlan outputs the plan for actual execution
 func outputApplyPlan(groups []*PatchGroup) {
-	fmt.Println("\n🚀 Apply Plan")
-	fmt.Println("=============")
+	fmt.Fprintln(util.ResultOutput(), "\n🚀 Apply Plan")
+	fmt.Fprintln(util.ResultOutput(), "=============")
 
 	for i, group := range groups {
-		fmt.Printf("📦 Patch Group %d/%d: %s\n", i+1, len(groups), group.Name)
+		fmt.Fprintf(util.ResultOutput(), "📦 Patch Group %d/%d: %s\n", i+1, len(groups), group.Name)
 		if len(group.Labels) > 0 {
-			fmt.Printf("   Labels: %s\n", strings.Join(group.Labels, ", "))
+			fmt.Fprintf(util.ResultOutput(), "   Labels: %s\n", strings.Join(group.Labels, ", "))
 		}
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
+		t := util.NewTable()
 		t.AppendHeader(table.Row{"Target", "File", "Current", "→", "Latest", "Type"})
 
 		patterns, wildcardGroups, nonWildcardUpdates := splitByWildcard(group.Updates)
@@ -229,8 +268,7 @@ func outputApplyPlan(groups []*PatchGroup) {
 			})
 		}
 
-		t.SetStyle(table.StyleRounded)
 		t.Render()
-		fmt.Println()
+		fmt.Fprintln(util.ResultOutput())
 	}
 }
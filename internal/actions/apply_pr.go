@@ -2,8 +2,13 @@ package actions
 
 import (
 	"fmt"
+	"html"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/mxcd/updater/internal/compare"
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/git"
 	"github.com/rs/zerolog/log"
@@ -18,9 +23,15 @@ func createOrUpdatePullRequest(repo *git.Repository, targetActor *configuration.
 	}
 
 	// Build PR title and body
-	prTitle := buildPRTitle(updates, group)
+	prTitle := buildPRTitle(targetActor, updates, group)
 	prBody := buildPRBody(updates, group)
 
+	// Milestone and projects of the targets win over those of the target actor
+	milestone := group.Milestone
+	if milestone == "" {
+		milestone = targetActor.Milestone
+	}
+
 	// Create PR options
 	prOptions := &git.PullRequestOptions{
 		Title:      prTitle,
@@ -28,6 +39,10 @@ func createOrUpdatePullRequest(repo *git.Repository, targetActor *configuration.
 		BaseBranch: repo.BaseBranch,
 		HeadBranch: repo.BranchName,
 		Labels:     group.Labels,
+		Reviewers:  pullRequestReviewers(repo, targetActor, group),
+		Assignees:  group.Assignees,
+		Milestone:  milestone,
+		Projects:   mergeLabels(targetActor.Projects, group.Projects),
 		PatchGroup: group.Name,
 	}
 
@@ -54,11 +69,51 @@ func createOrUpdatePullRequest(repo *git.Repository, targetActor *configuration.
 	return prURL, nil
 }
 
+// pullRequestReviewers returns the reviewers configured on the targets of a group, plus the
+// CODEOWNERS of the changed files if enabled. The actor itself cannot review its own PR.
+func pullRequestReviewers(repo *git.Repository, targetActor *configuration.TargetActor, group *PatchGroup) []string {
+	reviewers := group.Reviewers
+
+	if targetActor.CodeOwnerReviews {
+		codeOwners, err := git.LoadCodeOwners(repo.WorkingDirectory)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to read CODEOWNERS, not requesting code owner reviews")
+		}
+		for _, update := range group.Updates {
+			reviewers = mergeLabels(reviewers, codeOwners.Owners(repo.RelativePath(update.TargetFile)))
+		}
+	}
+
+	filtered := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if !strings.EqualFold(strings.TrimPrefix(reviewer, "@"), targetActor.Username) {
+			filtered = append(filtered, reviewer)
+		}
+	}
+	return filtered
+}
+
+// commitPrefix returns the type of commit messages and PR titles, with a Conventional Commits
+// type and scope inferred from the update types if configured
+func commitPrefix(targetActor *configuration.TargetActor, updates []*UpdateItem) string {
+	if targetActor == nil || targetActor.ConventionalCommits == nil {
+		return "chore"
+	}
+
+	updateTypes := make([]string, len(updates))
+	for i, update := range updates {
+		updateTypes[i] = string(update.UpdateType)
+	}
+	return targetActor.ConventionalCommits.Prefix(updateTypes)
+}
+
 // buildCommitMessage builds a commit message for the updates
-func buildCommitMessage(updates []*UpdateItem, group *PatchGroup) string {
+func buildCommitMessage(targetActor *configuration.TargetActor, updates []*UpdateItem, group *PatchGroup) string {
+	prefix := commitPrefix(targetActor, updates)
 	if len(updates) == 1 {
 		update := updates[0]
-		return fmt.Sprintf("chore: update %s from %s to %s",
+		return fmt.Sprintf("%s: update %s from %s to %s",
+			prefix,
 			update.ItemName,
 			update.CurrentVersion,
 			update.LatestVersion)
@@ -66,7 +121,7 @@ func buildCommitMessage(updates []*UpdateItem, group *PatchGroup) string {
 
 	// Multiple updates
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("chore: update %d dependencies in %s\n\n", len(updates), group.Name))
+	sb.WriteString(fmt.Sprintf("%s: update %d dependencies in %s\n\n", prefix, len(updates), group.Name))
 
 	for _, update := range updates {
 		sb.WriteString(fmt.Sprintf("- %s: %s → %s\n",
@@ -79,13 +134,65 @@ func buildCommitMessage(updates []*UpdateItem, group *PatchGroup) string {
 }
 
 // buildPRTitle builds a pull request title
-func buildPRTitle(updates []*UpdateItem, group *PatchGroup) string {
+func buildPRTitle(targetActor *configuration.TargetActor, updates []*UpdateItem, group *PatchGroup) string {
+	prefix := commitPrefix(targetActor, updates)
 	if len(updates) == 1 {
 		update := updates[0]
-		return fmt.Sprintf("chore: update %s to %s", update.ItemName, update.LatestVersion)
+		return fmt.Sprintf("%s: update %s to %s", prefix, update.ItemName, update.LatestVersion)
 	}
 
-	return fmt.Sprintf("chore: update %d dependencies in %s", len(updates), group.Name)
+	return fmt.Sprintf("%s: update %d dependencies in %s", prefix, len(updates), group.Name)
+}
+
+// wildcardChecklistFiles is the number of files from which the updates of a wildcard group
+// are listed as collapsible checklists per directory instead of table rows
+const wildcardChecklistFiles = 10
+
+// writeWildcardChecklist lists the updates of a wildcard group as a checklist per directory,
+// each collapsed, so PRs touching dozens of environment files stay reviewable
+func writeWildcardChecklist(sb *strings.Builder, pattern string, updates []*UpdateItem) {
+	directories := make(map[string][]*UpdateItem)
+	for _, update := range updates {
+		directory := wildcardDirectory(pattern, update.TargetFile)
+		directories[directory] = append(directories[directory], update)
+	}
+	names := make([]string, 0, len(directories))
+	for name := range directories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb.WriteString(fmt.Sprintf("\n### Wildcard Group `%s` (%d files)\n\n", pattern, len(updates)))
+	for _, name := range names {
+		directoryUpdates := directories[name]
+		sb.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code> (%d files)</summary>\n\n", html.EscapeString(name), len(directoryUpdates)))
+		for _, update := range directoryUpdates {
+			sb.WriteString(fmt.Sprintf("- [ ] `%s`: %s `%s` → `%s` %s\n",
+				update.TargetFile,
+				displayName(update),
+				update.CurrentVersion,
+				update.LatestVersion,
+				formatUpdateType(update.UpdateType)))
+		}
+		sb.WriteString("\n</details>\n")
+	}
+}
+
+// wildcardDirectory returns the directory a file matched by a wildcard pattern is grouped
+// under: its path up to the first wildcard segment of the pattern, e.g. envs/prod for
+// envs/prod/eu/values.yaml matched by envs/*/**/values.yaml
+func wildcardDirectory(pattern string, file string) string {
+	patternParts := strings.Split(filepath.ToSlash(pattern), "/")
+	fileParts := strings.Split(filepath.ToSlash(file), "/")
+	for i, part := range patternParts {
+		if strings.ContainsAny(part, "*?[") {
+			if i < len(fileParts)-1 {
+				return strings.Join(fileParts[:i+1], "/")
+			}
+			break
+		}
+	}
+	return path.Dir(filepath.ToSlash(file))
 }
 
 // buildPRBody builds a pull request body
@@ -140,11 +247,24 @@ func buildPRBody(updates []*UpdateItem, group *PatchGroup) string {
 
 	patterns, wildcardGroups, nonWildcardUpdates := splitByWildcard(updates)
 
-	sb.WriteString("| Item | File | Current | Latest | Type |\n")
-	sb.WriteString("|------------|------|---------|--------|------|\n")
+	// Large wildcard groups are listed as checklists per directory below the table
+	tablePatterns := make([]string, 0, len(patterns))
+	checklistPatterns := make([]string, 0)
+	for _, pattern := range patterns {
+		if len(wildcardGroups[pattern]) >= wildcardChecklistFiles {
+			checklistPatterns = append(checklistPatterns, pattern)
+		} else {
+			tablePatterns = append(tablePatterns, pattern)
+		}
+	}
+
+	if len(tablePatterns) > 0 || len(nonWildcardUpdates) > 0 {
+		sb.WriteString("| Item | File | Current | Latest | Type |\n")
+		sb.WriteString("|------------|------|---------|--------|------|\n")
+	}
 
 	// Display wildcard groups first
-	for _, pattern := range patterns {
+	for _, pattern := range tablePatterns {
 		groupUpdates := wildcardGroups[pattern]
 		sb.WriteString(fmt.Sprintf("| **%s** | `%s` (%d files) | | | |\n",
 			"Wildcard Group",
@@ -171,8 +291,111 @@ func buildPRBody(updates []*UpdateItem, group *PatchGroup) string {
 			formatUpdateType(update.UpdateType)))
 	}
 
+	for _, pattern := range checklistPatterns {
+		writeWildcardChecklist(&sb, pattern, wildcardGroups[pattern])
+	}
+
+	// List the versions each update skips, once per distinct update of wildcard groups
+	skippedLines := make([]string, 0)
+	seenSkipped := make(map[string]bool)
+	for _, update := range updates {
+		if update.SkippedVersions == nil {
+			continue
+		}
+		line := fmt.Sprintf("- %s `%s` → `%s`: %s\n", displayName(update), update.CurrentVersion, update.LatestVersion, describeSkippedVersions(update))
+		if !seenSkipped[line] {
+			seenSkipped[line] = true
+			skippedLines = append(skippedLines, line)
+		}
+	}
+	if len(skippedLines) > 0 {
+		sb.WriteString("\n## Skipped Versions\n\n")
+		for _, line := range skippedLines {
+			sb.WriteString(line)
+		}
+	}
+
+	// Explain what makes updates risky
+	risky := make([]*UpdateItem, 0)
+	for _, update := range updates {
+		if update.Risk == compare.RiskLevelMedium || update.Risk == compare.RiskLevelHigh {
+			risky = append(risky, update)
+		}
+	}
+	if len(risky) > 0 {
+		sb.WriteString("\n## Risk\n\n")
+		for _, update := range risky {
+			sb.WriteString(fmt.Sprintf("- %s `%s` → `%s`: %s (%s)\n", displayName(update), update.CurrentVersion, update.LatestVersion, formatRisk(update.Risk), strings.Join(update.RiskReasons, ", ")))
+		}
+	}
+
+	// Explain why the policy requires approval before merging
+	needsApproval := make([]*UpdateItem, 0)
+	for _, update := range updates {
+		if update.ApprovalReason != "" {
+			needsApproval = append(needsApproval, update)
+		}
+	}
+	if len(needsApproval) > 0 {
+		sb.WriteString("\n## Approval Required\n\n")
+		sb.WriteString("The update policy requires approval of:\n\n")
+		for _, update := range needsApproval {
+			sb.WriteString(fmt.Sprintf("- %s `%s` → `%s`: %s\n", displayName(update), update.CurrentVersion, update.LatestVersion, update.ApprovalReason))
+		}
+	}
+
+	// Show the output of the postUpdate hooks that changed the branch
+	if len(group.HookResults) > 0 {
+		sb.WriteString("\n## Post-Update Hooks\n\n")
+		for _, result := range group.HookResults {
+			subject := result.TargetFile
+			if subject == "" {
+				subject = "patch group"
+			}
+			sb.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code> (%s)</summary>\n\n", html.EscapeString(result.Command), subject))
+			if result.Output == "" {
+				sb.WriteString("No output\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("```\n%s\n```\n", result.Output))
+			}
+			sb.WriteString("\n</details>\n")
+		}
+	}
+
+	// Link the issues closed by merging, GitHub only recognizes one keyword per issue
+	if len(group.ClosesIssues) > 0 {
+		sb.WriteString("\n")
+		for _, issue := range group.ClosesIssues {
+			if ref, err := configuration.FormatIssueReference(issue); err == nil {
+				sb.WriteString(fmt.Sprintf("Closes %s\n", ref))
+			}
+		}
+	}
+
 	sb.WriteString("\n---\n")
 	sb.WriteString(fmt.Sprintf("🤖 This PR was automatically generated by updater (patch group: %s)\n", group.Name))
 
 	return sb.String()
 }
+
+// describeSkippedVersions describes the versions an update skips, e.g. "2 skipped
+// version(s): `1.3.0`, `1.2.0`"
+func describeSkippedVersions(update *UpdateItem) string {
+	count := len(update.SkippedVersions)
+	if count == 0 && !update.SkippedPartial {
+		return "no skipped versions"
+	}
+
+	quoted := make([]string, 0, count)
+	for _, version := range update.SkippedVersions {
+		quoted = append(quoted, fmt.Sprintf("`%s`", version))
+	}
+	description := fmt.Sprintf("%d skipped version(s)", count)
+	if update.SkippedPartial {
+		description = fmt.Sprintf("at least %d skipped version(s), older ones are beyond the version limit", count)
+	}
+	if count > 0 {
+		description += ": " + strings.Join(quoted, ", ")
+	}
+	return description
+}
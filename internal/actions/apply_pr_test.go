@@ -0,0 +1,149 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+func TestWildcardDirectory(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		file     string
+		expected string
+	}{
+		{"envs/*/values.yaml", "envs/prod/values.yaml", "envs/prod"},
+		{"envs/**/values.yaml", "envs/prod/eu/values.yaml", "envs/prod"},
+		{"clusters/*/apps/*/values.yaml", "clusters/prod/apps/web/values.yaml", "clusters/prod"},
+		{"envs/*.yaml", "envs/prod.yaml", "envs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if directory := wildcardDirectory(tt.pattern, tt.file); directory != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, directory)
+			}
+		})
+	}
+}
+
+func TestBuildPRBody_WildcardChecklist(t *testing.T) {
+	newUpdate := func(file string, pattern string) *UpdateItem {
+		return &UpdateItem{
+			TargetFile:      file,
+			ItemName:        "image.tag",
+			CurrentVersion:  "1.2.3",
+			LatestVersion:   "1.3.0",
+			UpdateType:      compare.UpdateTypeMinor,
+			WildcardPattern: pattern,
+			IsWildcardMatch: pattern != "",
+		}
+	}
+
+	var updates []*UpdateItem
+	for i := 0; i < 12; i++ {
+		env := "prod"
+		if i%3 == 0 {
+			env = "staging"
+		}
+		updates = append(updates, newUpdate(fmt.Sprintf("envs/%s/region-%d/values.yaml", env, i), "envs/**/values.yaml"))
+	}
+	updates = append(updates,
+		newUpdate("apps/a/Chart.yaml", "apps/*/Chart.yaml"),
+		newUpdate("apps/b/Chart.yaml", "apps/*/Chart.yaml"),
+		newUpdate("variables.tf", ""),
+	)
+
+	body := buildPRBody(updates, &PatchGroup{Name: "default"})
+
+	if !strings.Contains(body, "| **Wildcard Group** | `apps/*/Chart.yaml` (2 files) | | | |") {
+		t.Errorf("expected the small wildcard group in the table:\n%s", body)
+	}
+	if strings.Contains(body, "| ↳ image.tag | `envs/") {
+		t.Errorf("expected the large wildcard group outside the table:\n%s", body)
+	}
+	for _, expected := range []string{
+		"### Wildcard Group `envs/**/values.yaml` (12 files)",
+		"<summary><code>envs/prod</code> (8 files)</summary>",
+		"<summary><code>envs/staging</code> (4 files)</summary>",
+		"- [ ] `envs/prod/region-1/values.yaml`: image.tag `1.2.3` → `1.3.0` 🟡 minor",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in body:\n%s", expected, body)
+		}
+	}
+	if strings.Index(body, "envs/prod</code>") > strings.Index(body, "envs/staging</code>") {
+		t.Errorf("expected directories in alphabetical order:\n%s", body)
+	}
+}
+
+func TestBuildPRBody_OnlyChecklist(t *testing.T) {
+	var updates []*UpdateItem
+	for i := 0; i < wildcardChecklistFiles; i++ {
+		updates = append(updates, &UpdateItem{
+			TargetFile:      fmt.Sprintf("envs/env-%d/values.yaml", i),
+			ItemName:        "image.tag",
+			UpdateType:      compare.UpdateTypePatch,
+			WildcardPattern: "envs/*/values.yaml",
+			IsWildcardMatch: true,
+		})
+	}
+
+	body := buildPRBody(updates, &PatchGroup{Name: "default"})
+	if strings.Contains(body, "| Item | File |") {
+		t.Errorf("expected no empty table:\n%s", body)
+	}
+	if strings.Count(body, "<details>") != wildcardChecklistFiles {
+		t.Errorf("expected one section per environment:\n%s", body)
+	}
+}
+
+func TestBuildPRBody_Risk(t *testing.T) {
+	updates := []*UpdateItem{
+		{ItemName: "api", CurrentVersion: "1.4.0", LatestVersion: "2.0.0", UpdateType: compare.UpdateTypeMajor, Risk: compare.RiskLevelHigh, RiskReasons: []string{"major update", "release notes of 2.0.0 announce breaking changes"}},
+		{ItemName: "web", CurrentVersion: "3.1.0", LatestVersion: "3.1.1", UpdateType: compare.UpdateTypePatch, Risk: compare.RiskLevelLow},
+	}
+
+	body := buildPRBody(updates, &PatchGroup{Name: "default"})
+	if !strings.Contains(body, "- api `1.4.0` → `2.0.0`: 🔴 high (major update, release notes of 2.0.0 announce breaking changes)") {
+		t.Errorf("expected the high risk update to be explained:\n%s", body)
+	}
+	if strings.Contains(body, "- web `3.1.0`") {
+		t.Errorf("expected low risk updates not to be listed:\n%s", body)
+	}
+}
+
+func TestRiskLabel(t *testing.T) {
+	if label := riskLabel(compare.RiskLevelHigh); label != "risk/high" {
+		t.Errorf("expected risk/high, got %q", label)
+	}
+	if label := riskLabel(compare.RiskLevelLow); label != "" {
+		t.Errorf("expected no label for low risk, got %q", label)
+	}
+}
+
+func TestBuildPRBody_SkippedVersions(t *testing.T) {
+	updates := []*UpdateItem{
+		{ItemName: "api", CurrentVersion: "1.4.0", LatestVersion: "1.7.0", UpdateType: compare.UpdateTypeMinor, SkippedVersions: []string{"1.6.0", "1.5.0"}},
+		{ItemName: "web", CurrentVersion: "3.1.0", LatestVersion: "3.1.1", UpdateType: compare.UpdateTypePatch, SkippedVersions: []string{}},
+		{ItemName: "worker", CurrentVersion: "0.9.0", LatestVersion: "1.2.0", UpdateType: compare.UpdateTypeMajor, SkippedVersions: []string{"1.1.0"}, SkippedPartial: true},
+		{ItemName: "db", CurrentVersion: "15.1", LatestVersion: "15.2", UpdateType: compare.UpdateTypeMinor},
+	}
+
+	body := buildPRBody(updates, &PatchGroup{Name: "default"})
+	for _, expected := range []string{
+		"## Skipped Versions",
+		"- api `1.4.0` → `1.7.0`: 2 skipped version(s): `1.6.0`, `1.5.0`",
+		"- web `3.1.0` → `3.1.1`: no skipped versions",
+		"- worker `0.9.0` → `1.2.0`: at least 1 skipped version(s), older ones are beyond the version limit: `1.1.0`",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in the body:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "- db `15.1`") {
+		t.Errorf("expected updates without listed versions to be left out:\n%s", body)
+	}
+}
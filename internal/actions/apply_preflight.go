@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/git"
+)
+
+// checkDirtyWorkingTrees refuses to apply in place while a git working directory of the
+// updated files has uncommitted changes outside the target files, as checking out the update
+// branches carries them along and postUpdate hooks may commit them. Changes to the target files
+// themselves are left over from an interrupted run and reapplied anyway.
+func checkDirtyWorkingTrees(patchGroups []*PatchGroup) error {
+	targetFiles := make(map[string]map[string]bool)
+	for _, group := range patchGroups {
+		for _, update := range group.Updates {
+			root, err := git.FindRoot(update.TargetFile)
+			if err != nil {
+				// Applying fails with the detection error
+				continue
+			}
+			if targetFiles[root] == nil {
+				targetFiles[root] = make(map[string]bool)
+			}
+			targetFiles[root][git.NewRepository(root, nil).RelativePath(update.TargetFile)] = true
+		}
+	}
+
+	roots := make([]string, 0, len(targetFiles))
+	for root := range targetFiles {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		changed, err := git.NewRepository(root, nil).ChangedFiles()
+		if err != nil {
+			return fmt.Errorf("failed to check %s for uncommitted changes: %w", root, err)
+		}
+
+		stray := make([]string, 0)
+		for _, file := range changed {
+			if !targetFiles[root][file] {
+				stray = append(stray, file)
+			}
+		}
+		if len(stray) > 0 {
+			sort.Strings(stray)
+			return fmt.Errorf("%s has uncommitted changes outside the target files: %s (commit or stash them, or use --allow-dirty)", root, strings.Join(stray, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDirtyWorkingTrees(t *testing.T) {
+	tests := []struct {
+		name          string
+		change        func(t *testing.T, dir string)
+		errorContains string
+	}{
+		{
+			name:   "clean working tree",
+			change: func(t *testing.T, dir string) {},
+		},
+		{
+			name: "dirty target file is allowed",
+			change: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "envs", "values.yaml"), "tag: 1.1.0\n")
+			},
+		},
+		{
+			name: "dirty unrelated file is refused",
+			change: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "README.md"), "# changed\n")
+			},
+			errorContains: "uncommitted changes outside the target files: README.md",
+		},
+		{
+			name: "untracked file is refused",
+			change: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "envs", "notes.txt"), "todo\n")
+			},
+			errorContains: "envs/notes.txt",
+		},
+		{
+			name: "deleted unrelated file is refused",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "README.md")); err != nil {
+					t.Fatalf("failed to remove test file: %v", err)
+				}
+			},
+			errorContains: "README.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, run := newTestRepository(t)
+			target := filepath.Join(dir, "envs", "values.yaml")
+			writeTestFile(t, target, "tag: 1.0.0\n")
+			writeTestFile(t, filepath.Join(dir, "README.md"), "# repo\n")
+			run("add", ".")
+			run("commit", "-q", "-m", "initial")
+
+			tt.change(t, dir)
+
+			err := checkDirtyWorkingTrees([]*PatchGroup{{Name: "default", Updates: []*UpdateItem{{TargetFile: target}}}})
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
+func TestCheckDirtyWorkingTrees_UntrackedTargetFile(t *testing.T) {
+	dir, run := newTestRepository(t)
+	run("commit", "-q", "--allow-empty", "-m", "initial")
+	target := filepath.Join(dir, "values.yaml")
+	writeTestFile(t, target, "tag: 1.0.0\n")
+
+	if err := checkDirtyWorkingTrees([]*PatchGroup{{Updates: []*UpdateItem{{TargetFile: target}}}}); err != nil {
+		t.Errorf("expected a new target file to be allowed, got %v", err)
+	}
+}
+
+func TestCheckDirtyWorkingTrees_OutsideRepository(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "values.yaml")
+	writeTestFile(t, target, "tag: 1.0.0\n")
+
+	if err := checkDirtyWorkingTrees([]*PatchGroup{{Updates: []*UpdateItem{{TargetFile: target}}}}); err != nil {
+		t.Errorf("expected files outside a repository to be left to apply, got %v", err)
+	}
+}
+
+// writeTestFile writes content to a file, creating its directory
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+}
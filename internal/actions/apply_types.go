@@ -1,22 +1,75 @@
 package actions
 
-import "github.com/mxcd/updater/internal/compare"
+import (
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/history"
+	"github.com/mxcd/updater/internal/scraper"
+)
 
 // ApplyOptions represents options for the apply command
 type ApplyOptions struct {
-	ConfigPath   string
-	OutputFormat string
-	DryRun       bool
-	Local        bool
-	Limit        int
-	Only         string
+	ConfigPath        string
+	Recursive         bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment       string // Environment whose overrides are applied
+	OutputFormat      string
+	DryRun            bool
+	Local             bool
+	Limit             int
+	Only              string
+	SummaryFile       string
+	GitHubAnnotations bool
+	Targets           []string          // Only apply updates to targets with these names
+	SBOMs             []string          // Only apply updates of items whose source has a component in these CycloneDX or SPDX SBOMs
+	AttestationDir    string            // Write and attach signed in-toto attestations when set
+	AttestationKey    string            // cosign key for signing attestations, keyless if empty
+	UpdaterVersion    string            // Version recorded in attestations
+	AuditLog          string            // Append audit events to this file, "-" for stderr
+	ResetBranches     bool              // Delete and recreate update branches from the base branch before applying
+	OnConflict        string            // Handling of open pull requests of others changing the same lines: ignore, warn, skip
+	Policy            string            // Rego policy file or directory deciding on every update, none if empty
+	IgnoreSchedule    bool              // Apply patch groups outside their maintenance window
+	Parallel          int               // Number of patch groups applied concurrently, one at a time per git working directory
+	InPlace           bool              // Check out update branches in the working directory instead of a temporary worktree
+	AllowDirty        bool              // Apply in place despite uncommitted changes outside the target files
+	Profile           bool              // Report the time spent per phase, source and patch group
+	SkippedVersions   bool              // List the versions skipped by each update in the output and pull request bodies
+	History           *history.Store    // Record the run in this history database, disabled if nil
+	HistoryScope      string            // Scope the run is recorded under, e.g. the UpdaterConfig "namespace/name"
+	Snoozes           []*history.Snooze // Updates held back until their snooze ends
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+
+	audit    *auditLog                 // Audit log of the current run, nil if disabled
+	onFinish func(summary *RunSummary) // Called with the summary of the finished run, e.g. by serve
+	config   *configuration.Config     // Configuration to run with instead of loading ConfigPath, e.g. by the operator
 }
 
 // PatchGroup represents a group of updates that should be applied together
 type PatchGroup struct {
-	Name    string
-	Updates []*UpdateItem
-	Labels  []string
+	Name               string
+	BranchName         string // Update branch rendered from the branch template of the target actor
+	Updates            []*UpdateItem
+	Labels             []string
+	Reviewers          []string
+	Assignees          []string
+	Milestone          string // First milestone set on a target of the group
+	Projects           []string
+	ClosesIssues       []string
+	PullRequestURL     string        // Set after the pull request was created or updated
+	PullRequestCreated bool          // True if the pull request was newly created
+	SkippedReason      string        // Set if the group was not applied or its branch not pushed, e.g. due to conflicting pull requests
+	HookResults        []*HookResult // Output of the postUpdate hooks run for the group
+	Duration           time.Duration // Time spent applying the group
+}
+
+// HookResult is the captured output of a postUpdate hook
+type HookResult struct {
+	Command    string
+	TargetFile string // Target file the hook ran for, relative to the repository root, empty for hooks of the patch group
+	Output     string
 }
 
 // UpdateItem represents a single update to be applied
@@ -28,8 +81,30 @@ type UpdateItem struct {
 	CurrentVersion  string
 	LatestVersion   string
 	UpdateType      compare.UpdateType
+	Risk            compare.RiskLevel // Heuristic risk of the update
+	RiskReasons     []string          // What the risk is based on
+	SkippedVersions []string          // Versions between the current and the latest version, nil unless listed
+	SkippedPartial  bool              // Older skipped versions are beyond the version limit and not listed
+	CVEs            []string          // CVE IDs mentioned in the release notes of the update
 	PatchGroup      string
 	Labels          []string
-	WildcardPattern string // Original wildcard pattern if this target was expanded
-	IsWildcardMatch bool   // Flag indicating if this came from a wildcard expansion
+	Reviewers       []string
+	Assignees       []string
+	Milestone       string
+	Projects        []string
+	ClosesIssues    []string
+	PostUpdate      []string                  // Hooks of the target run after the update, before commit
+	WildcardPattern string                    // Original wildcard pattern if this target was expanded
+	IsWildcardMatch bool                      // Flag indicating if this came from a wildcard expansion
+	VersionSet      string                    // Version set whose files are committed together
+	ApprovalReason  string                    // Set if the policy requires approval of the update
+	ImageCheck      *configuration.ImageCheck // Registry check of the new tag before it is written, none if nil
+	DigestPin       *configuration.DigestPin  // Set if the target pins the digest of the new tag
+	Digest          string                    // Digest of the new tag written to a digest-pinned target
+}
+
+// CommitUnit represents files that are updated and committed together
+type CommitUnit struct {
+	Files   []string
+	Updates []*UpdateItem
 }
@@ -0,0 +1,210 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// updateAttestationPredicateType identifies the predicate of updater attestations
+const updateAttestationPredicateType = "https://github.com/mxcd/updater/attestation/update/v1"
+
+// inTotoStatement is an in-toto v1 attestation statement
+type inTotoStatement struct {
+	Type          string           `json:"_type"`
+	Subject       []*inTotoSubject `json:"subject"`
+	PredicateType string           `json:"predicateType"`
+	Predicate     *updatePredicate `json:"predicate"`
+}
+
+// inTotoSubject identifies an attested artifact by name and digest
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// updatePredicate records what was bumped, by which updater version, from which scrape data
+type updatePredicate struct {
+	UpdaterVersion string            `json:"updaterVersion"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	PatchGroup     string            `json:"patchGroup"`
+	Branch         string            `json:"branch"`
+	PullRequest    string            `json:"pullRequest"`
+	Updates        []*attestedUpdate `json:"updates"`
+	Sources        []*attestedSource `json:"sources"`
+}
+
+// attestedUpdate records a single version bump
+type attestedUpdate struct {
+	Target     string `json:"target"`
+	File       string `json:"file"`
+	Item       string `json:"item"`
+	Source     string `json:"source"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	UpdateType string `json:"updateType"`
+}
+
+// attestedSource records the scrape data an update was derived from
+type attestedSource struct {
+	Name     string   `json:"name"`
+	Provider string   `json:"provider"`
+	Type     string   `json:"type"`
+	URI      string   `json:"uri,omitempty"`
+	Versions []string `json:"versions"`
+}
+
+// attestPatchGroup builds an in-toto statement for the updates of a patch group, signs it
+// with cosign, writes statement and signature bundle to the attestation directory, and
+// attaches both to the pull request as a comment
+func attestPatchGroup(config *configuration.Config, options *ApplyOptions, repo *git.Repository, group *PatchGroup) error {
+	commit, err := repo.GetBranchCommit(repo.BranchName)
+	if err != nil {
+		return err
+	}
+
+	statement := buildUpdateStatement(config, options.UpdaterVersion, repo, commit, group)
+	statementJSON, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	if err := os.MkdirAll(options.AttestationDir, 0755); err != nil {
+		return fmt.Errorf("failed to create attestation directory: %w", err)
+	}
+	statementPath := filepath.Join(options.AttestationDir, fmt.Sprintf("%s.intoto.json", group.Name))
+	if err := os.WriteFile(statementPath, append(statementJSON, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+
+	bundlePath := statementPath + ".bundle"
+	if err := signBlob(statementPath, bundlePath, options.AttestationKey); err != nil {
+		return err
+	}
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature bundle: %w", err)
+	}
+
+	fmt.Fprintf(util.StatusOutput(), "  🔏 Signed attestation: %s\n", statementPath)
+
+	prNumber, err := git.ParsePullRequestNumber(group.PullRequestURL)
+	if err != nil {
+		return err
+	}
+	githubClient, err := git.NewGitHubClient(repo.RepoURL, config.TargetActor)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	if err := githubClient.AddPullRequestComment(prNumber, buildAttestationComment(commit, statementJSON, bundle)); err != nil {
+		return fmt.Errorf("failed to attach attestation to pull request: %w", err)
+	}
+
+	log.Debug().
+		Str("patchGroup", group.Name).
+		Str("commit", commit).
+		Msg("Attached attestation to pull request")
+
+	return nil
+}
+
+// buildUpdateStatement builds the in-toto statement for a patch group. The subject is the
+// head commit of the update branch.
+func buildUpdateStatement(config *configuration.Config, updaterVersion string, repo *git.Repository, commit string, group *PatchGroup) *inTotoStatement {
+	predicate := &updatePredicate{
+		UpdaterVersion: updaterVersion,
+		CreatedAt:      time.Now().UTC(),
+		PatchGroup:     group.Name,
+		Branch:         repo.BranchName,
+		PullRequest:    group.PullRequestURL,
+		Updates:        make([]*attestedUpdate, 0, len(group.Updates)),
+		Sources:        make([]*attestedSource, 0),
+	}
+
+	seenSources := make(map[string]bool)
+	for _, update := range group.Updates {
+		predicate.Updates = append(predicate.Updates, &attestedUpdate{
+			Target:     update.TargetName,
+			File:       repo.RelativePath(update.TargetFile),
+			Item:       update.ItemName,
+			Source:     update.SourceName,
+			From:       update.CurrentVersion,
+			To:         update.LatestVersion,
+			UpdateType: string(update.UpdateType),
+		})
+
+		if seenSources[update.SourceName] {
+			continue
+		}
+		seenSources[update.SourceName] = true
+		for _, source := range config.PackageSources {
+			if source.Name != update.SourceName {
+				continue
+			}
+			versions := make([]string, 0, len(source.Versions))
+			for _, v := range source.Versions {
+				versions = append(versions, v.Version)
+			}
+			predicate.Sources = append(predicate.Sources, &attestedSource{
+				Name:     source.Name,
+				Provider: source.Provider,
+				Type:     string(source.Type),
+				URI:      source.URI,
+				Versions: versions,
+			})
+			break
+		}
+	}
+
+	return &inTotoStatement{
+		Type: "https://in-toto.io/Statement/v1",
+		Subject: []*inTotoSubject{
+			{
+				Name:   fmt.Sprintf("%s@%s", repo.RepoURL, repo.BranchName),
+				Digest: map[string]string{"gitCommit": commit},
+			},
+		},
+		PredicateType: updateAttestationPredicateType,
+		Predicate:     predicate,
+	}
+}
+
+// signBlob signs a file with cosign sign-blob and writes the signature bundle. Without a key,
+// cosign signs keyless using the ambient OIDC identity (e.g. GitHub Actions).
+func signBlob(path string, bundlePath string, key string) error {
+	args := []string{"sign-blob", "--yes", "--bundle", bundlePath}
+	if key != "" {
+		args = append(args, "--key", key)
+	}
+	args = append(args, path)
+
+	cmd := exec.Command("cosign", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cosign sign-blob failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// buildAttestationComment renders the pull request comment carrying the signed attestation
+func buildAttestationComment(commit string, statement []byte, bundle []byte) string {
+	var sb strings.Builder
+	sb.WriteString("### 🔏 Updater Attestation\n\n")
+	sb.WriteString(fmt.Sprintf("Signed in-toto statement for commit `%s`.\n\n", commit))
+	sb.WriteString("<details>\n<summary>in-toto statement</summary>\n\n```json\n")
+	sb.Write(statement)
+	sb.WriteString("\n```\n\n</details>\n\n")
+	sb.WriteString("<details>\n<summary>cosign bundle</summary>\n\n```json\n")
+	sb.WriteString(strings.TrimSpace(string(bundle)))
+	sb.WriteString("\n```\n\n</details>\n")
+	return sb.String()
+}
@@ -0,0 +1,182 @@
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// Audit event types
+const (
+	auditEventRunStarted         = "run.started"
+	auditEventRunFinished        = "run.finished"
+	auditEventVersionDecision    = "version.decision"
+	auditEventPolicyDecision     = "policy.decision"
+	auditEventImageCheck         = "image.check"
+	auditEventFileWritten        = "file.written"
+	auditEventCommitCreated      = "commit.created"
+	auditEventBranchReset        = "branch.reset"
+	auditEventBranchPushed       = "branch.pushed"
+	auditEventPullRequestCreated = "pullRequest.created"
+	auditEventPullRequestUpdated = "pullRequest.updated"
+)
+
+// AuditEvent is a single entry of the audit log
+type AuditEvent struct {
+	Time        time.Time `json:"time"`
+	RunID       string    `json:"runId"`
+	Command     string    `json:"command"`
+	Event       string    `json:"event"`
+	Target      string    `json:"target,omitempty"`
+	File        string    `json:"file,omitempty"`
+	Item        string    `json:"item,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	From        string    `json:"from,omitempty"`
+	To          string    `json:"to,omitempty"`
+	Decision    string    `json:"decision,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	PatchGroup  string    `json:"patchGroup,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	PullRequest string    `json:"pullRequest,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// auditLog appends one JSON event per line to a file or stderr. A nil audit log discards events.
+type auditLog struct {
+	mu      sync.Mutex // Serializes events of patch groups applied in parallel
+	runID   string
+	command string
+	out     io.Writer
+	file    *os.File
+}
+
+// newRunID returns a unique identifier for an updater invocation
+func newRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().UTC().Format("20060102T150405Z")
+	}
+	return fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix))
+}
+
+// openAuditLog opens the audit log at path in append mode. "-" writes to stderr, keeping
+// stdout free for structured command output, and an empty path disables the audit log.
+func openAuditLog(path string, runID string, command string) (*auditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	audit := &auditLog{runID: runID, command: command}
+	if path == "-" {
+		audit.out = os.Stderr
+	} else {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		audit.file = file
+		audit.out = file
+	}
+
+	audit.record(&AuditEvent{Event: auditEventRunStarted})
+	return audit, nil
+}
+
+// record appends an event to the audit log
+func (a *auditLog) record(event *AuditEvent) {
+	if a == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.RunID = a.runID
+	event.Command = a.command
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to marshal audit event")
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := io.WriteString(a.out, util.Redact(string(data))+"\n"); err != nil {
+		log.Warn().Err(err).Msg("Failed to write audit event")
+	}
+}
+
+// recordDecisions records the version decision taken for each comparison result
+func (a *auditLog) recordDecisions(results []*compare.ComparisonResult) {
+	if a == nil {
+		return
+	}
+	for _, result := range results {
+		event := &AuditEvent{
+			Event:      auditEventVersionDecision,
+			Target:     result.TargetName,
+			File:       result.TargetFile,
+			Item:       result.TargetItemName,
+			Source:     result.SourceName,
+			From:       result.CurrentVersion,
+			To:         result.LatestVersion,
+			PatchGroup: result.PatchGroup,
+		}
+		switch {
+		case result.Error != nil:
+			event.Decision = "error"
+			event.Error = result.Error.Error()
+		case result.NeedsUpdate:
+			event.Decision = "update"
+			event.Reason = fmt.Sprintf("%s update", result.UpdateType)
+		case result.HeldBackVersion != "":
+			event.Decision = "held-back"
+			event.Reason = fmt.Sprintf("%s %s exceeds maxUpdateType %s", result.HeldBackType, result.HeldBackVersion, result.MaxUpdateType)
+			if result.HeldBackReason != "" {
+				event.Reason = fmt.Sprintf("%s %s held back: %s", result.HeldBackType, result.HeldBackVersion, result.HeldBackReason)
+			}
+		default:
+			event.Decision = "up-to-date"
+		}
+		a.record(event)
+	}
+}
+
+// recordFileWritten records a version written to a target file
+func (a *auditLog) recordFileWritten(update *UpdateItem) {
+	a.record(&AuditEvent{
+		Event:      auditEventFileWritten,
+		Target:     update.TargetName,
+		File:       update.TargetFile,
+		Item:       update.ItemName,
+		Source:     update.SourceName,
+		From:       update.CurrentVersion,
+		To:         update.LatestVersion,
+		PatchGroup: update.PatchGroup,
+	})
+}
+
+// close records the end of the run and closes the audit log file
+func (a *auditLog) close(runErr error) {
+	if a == nil {
+		return
+	}
+	event := &AuditEvent{Event: auditEventRunFinished}
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+	a.record(event)
+
+	if a.file != nil {
+		if err := a.file.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close audit log")
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+// readAuditEvents parses the JSON lines of an audit log file
+func readAuditEvents(t *testing.T, path string) []*AuditEvent {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var events []*AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := &AuditEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			t.Fatalf("audit log line is not valid JSON: %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestOpenAuditLog(t *testing.T) {
+	t.Run("empty path disables the audit log", func(t *testing.T) {
+		audit, err := openAuditLog("", "run", "compare")
+		if err != nil || audit != nil {
+			t.Fatalf("expected no audit log, got %v, %v", audit, err)
+		}
+		// A nil audit log discards events
+		audit.record(&AuditEvent{Event: auditEventFileWritten})
+		audit.recordDecisions([]*compare.ComparisonResult{{TargetName: "a"}})
+		audit.close(nil)
+	})
+
+	t.Run("dash writes to stderr", func(t *testing.T) {
+		audit, err := openAuditLog("-", "run", "compare")
+		if err != nil {
+			t.Fatalf("openAuditLog() failed: %v", err)
+		}
+		if audit.out != os.Stderr || audit.file != nil {
+			t.Errorf("expected the audit log to write to stderr")
+		}
+	})
+
+	t.Run("unwritable path fails", func(t *testing.T) {
+		if _, err := openAuditLog(filepath.Join(t.TempDir(), "missing", "audit.log"), "run", "compare"); err == nil {
+			t.Errorf("expected an error for a missing directory")
+		}
+	})
+
+	t.Run("file is appended to", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		for _, runID := range []string{"run-1", "run-2"} {
+			audit, err := openAuditLog(path, runID, "apply")
+			if err != nil {
+				t.Fatalf("openAuditLog() failed: %v", err)
+			}
+			audit.close(nil)
+		}
+
+		events := readAuditEvents(t, path)
+		expected := []struct{ runID, event string }{
+			{"run-1", auditEventRunStarted},
+			{"run-1", auditEventRunFinished},
+			{"run-2", auditEventRunStarted},
+			{"run-2", auditEventRunFinished},
+		}
+		if len(events) != len(expected) {
+			t.Fatalf("expected %d events, got %d", len(expected), len(events))
+		}
+		for i, event := range events {
+			if event.RunID != expected[i].runID || event.Event != expected[i].event || event.Command != "apply" {
+				t.Errorf("event %d: expected %s %s, got %+v", i, expected[i].runID, expected[i].event, event)
+			}
+		}
+	})
+}
+
+func TestAuditLog_RecordFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := openAuditLog(path, "20261016T060000Z-3f9a1c2e", "compare")
+	if err != nil {
+		t.Fatalf("openAuditLog() failed: %v", err)
+	}
+	audit.recordFileWritten(&UpdateItem{
+		TargetName:     "app",
+		TargetFile:     "values.yaml",
+		ItemName:       "image.tag",
+		SourceName:     "nginx",
+		CurrentVersion: "1.0.0",
+		LatestVersion:  "1.1.0",
+		PatchGroup:     "prod",
+	})
+	audit.close(errors.New("push failed"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	var raw []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("audit log line is not valid JSON: %q: %v", line, err)
+		}
+		raw = append(raw, record)
+	}
+	if len(raw) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(raw))
+	}
+
+	written := raw[1]
+	for key, expected := range map[string]string{
+		"runId":      "20261016T060000Z-3f9a1c2e",
+		"command":    "compare",
+		"event":      auditEventFileWritten,
+		"target":     "app",
+		"file":       "values.yaml",
+		"item":       "image.tag",
+		"source":     "nginx",
+		"from":       "1.0.0",
+		"to":         "1.1.0",
+		"patchGroup": "prod",
+	} {
+		if written[key] != expected {
+			t.Errorf("expected %s %q, got %v", key, expected, written[key])
+		}
+	}
+	if _, ok := written["time"]; !ok {
+		t.Errorf("expected every event to have a time")
+	}
+	// Empty fields are omitted
+	if _, ok := written["error"]; ok {
+		t.Errorf("expected no error field, got %v", written["error"])
+	}
+	if raw[2]["event"] != auditEventRunFinished || raw[2]["error"] != "push failed" {
+		t.Errorf("unexpected run finished event: %v", raw[2])
+	}
+}
+
+func TestAuditLog_RecordDecisions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := openAuditLog(path, "run", "compare")
+	if err != nil {
+		t.Fatalf("openAuditLog() failed: %v", err)
+	}
+	audit.recordDecisions([]*compare.ComparisonResult{
+		{TargetName: "update", NeedsUpdate: true, UpdateType: compare.UpdateTypeMinor},
+		{TargetName: "held", HeldBackVersion: "3.0.0", HeldBackType: compare.UpdateTypeMajor, MaxUpdateType: compare.UpdateTypeMinor},
+		{TargetName: "rollout", HeldBackVersion: "3.0.0", HeldBackType: compare.UpdateTypeMajor, HeldBackReason: "waiting for stage dev"},
+		{TargetName: "current"},
+		{TargetName: "failed", Error: errors.New("source not found")},
+	})
+	audit.close(nil)
+
+	events := readAuditEvents(t, path)[1:]
+	tests := []struct {
+		decision string
+		reason   string
+		err      string
+	}{
+		{decision: "update", reason: "minor update"},
+		{decision: "held-back", reason: "major 3.0.0 exceeds maxUpdateType minor"},
+		{decision: "held-back", reason: "major 3.0.0 held back: waiting for stage dev"},
+		{decision: "up-to-date"},
+		{decision: "error", err: "source not found"},
+	}
+	for i, tt := range tests {
+		event := events[i]
+		if event.Event != auditEventVersionDecision || event.Decision != tt.decision || event.Reason != tt.reason || event.Error != tt.err {
+			t.Errorf("%s: expected %s %q %q, got %+v", event.Target, tt.decision, tt.reason, tt.err, event)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// badgeLabel is the left-hand text of the freshness badge
+const badgeLabel = "dependencies"
+
+// shieldsEndpoint is the shields.io endpoint badge schema, see https://shields.io/badges/endpoint-badge
+type shieldsEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// badgeMessage summarizes the pending updates, e.g. "12 updates: 2 major"
+func badgeMessage(counts *UpdateCounts) string {
+	if counts.Total == 0 {
+		return "up to date"
+	}
+	message := fmt.Sprintf("%d updates", counts.Total)
+	if counts.Total == 1 {
+		message = "1 update"
+	}
+	if counts.Major > 0 {
+		message += fmt.Sprintf(": %d major", counts.Major)
+	}
+	return message
+}
+
+// badgeColor is green when up to date, yellow with pending updates and red with major updates
+func badgeColor(counts *UpdateCounts) string {
+	switch {
+	case counts.Total == 0:
+		return "brightgreen"
+	case counts.Major > 0:
+		return "red"
+	default:
+		return "yellow"
+	}
+}
+
+// badgeColorHex maps the badge colors to the hex values shields.io renders them with
+var badgeColorHex = map[string]string{
+	"brightgreen": "#4c1",
+	"yellow":      "#dfb317",
+	"red":         "#e05d44",
+}
+
+// writeBadge writes a badge of the pending updates to the badge file, a shields.io endpoint
+// JSON for .json files and an SVG otherwise. Failures are logged, not returned, so a badge
+// never fails a run.
+func writeBadge(path string, counts *UpdateCounts) {
+	if path == "" {
+		return
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		data, err = json.MarshalIndent(&shieldsEndpoint{
+			SchemaVersion: 1,
+			Label:         badgeLabel,
+			Message:       badgeMessage(counts),
+			Color:         badgeColor(counts),
+		}, "", "  ")
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to marshal badge")
+			return
+		}
+		data = append(data, '\n')
+	} else {
+		data = []byte(renderBadgeSVG(badgeLabel, badgeMessage(counts), badgeColorHex[badgeColor(counts)]))
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn().Err(err).Str("file", path).Msg("Failed to write badge")
+		return
+	}
+	log.Debug().Str("file", path).Msg("Wrote badge")
+}
+
+// renderBadgeSVG renders a flat badge. Text widths are estimated from the character count,
+// which is close enough for the short ASCII texts of the badge.
+func renderBadgeSVG(label string, message string, color string) string {
+	const charWidth, padding = 7, 10
+	labelWidth := len(label)*charWidth + padding
+	messageWidth := len(message)*charWidth + padding
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+"\n", width, label, message)
+	fmt.Fprintf(&sb, `  <title>%s: %s</title>`+"\n", label, message)
+	sb.WriteString(`  <linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` + "\n")
+	fmt.Fprintf(&sb, `  <clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+"\n", width)
+	sb.WriteString(`  <g clip-path="url(#r)">` + "\n")
+	fmt.Fprintf(&sb, `    <rect width="%d" height="20" fill="#555"/>`+"\n", labelWidth)
+	fmt.Fprintf(&sb, `    <rect x="%d" width="%d" height="20" fill="%s"/>`+"\n", labelWidth, messageWidth, color)
+	fmt.Fprintf(&sb, `    <rect width="%d" height="20" fill="url(#s)"/>`+"\n", width)
+	sb.WriteString("  </g>\n")
+	sb.WriteString(`  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` + "\n")
+	fmt.Fprintf(&sb, `    <text x="%d" y="14">%s</text>`+"\n", labelWidth/2, label)
+	fmt.Fprintf(&sb, `    <text x="%d" y="14">%s</text>`+"\n", labelWidth+messageWidth/2, message)
+	sb.WriteString("  </g>\n")
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBadgeMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		counts        UpdateCounts
+		expected      string
+		expectedColor string
+	}{
+		{"up to date", UpdateCounts{}, "up to date", "brightgreen"},
+		{"single update", UpdateCounts{Total: 1, Patch: 1}, "1 update", "yellow"},
+		{"minor and patch", UpdateCounts{Total: 5, Minor: 2, Patch: 3}, "5 updates", "yellow"},
+		{"major", UpdateCounts{Total: 12, Major: 2, Minor: 4, Patch: 6}, "12 updates: 2 major", "red"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := badgeMessage(&tt.counts); message != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, message)
+			}
+			if color := badgeColor(&tt.counts); color != tt.expectedColor {
+				t.Errorf("expected color %q, got %q", tt.expectedColor, color)
+			}
+		})
+	}
+}
+
+func TestWriteBadge(t *testing.T) {
+	counts := &UpdateCounts{Total: 12, Major: 2, Minor: 4, Patch: 6}
+	dir := t.TempDir()
+
+	jsonFile := filepath.Join(dir, "badge.json")
+	writeBadge(jsonFile, counts)
+	data, err := os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("failed to read badge: %v", err)
+	}
+	var endpoint shieldsEndpoint
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		t.Fatalf("invalid badge JSON: %v", err)
+	}
+	expected := shieldsEndpoint{SchemaVersion: 1, Label: "dependencies", Message: "12 updates: 2 major", Color: "red"}
+	if endpoint != expected {
+		t.Errorf("expected %+v, got %+v", expected, endpoint)
+	}
+
+	svgFile := filepath.Join(dir, "badge.svg")
+	writeBadge(svgFile, counts)
+	data, err = os.ReadFile(svgFile)
+	if err != nil {
+		t.Fatalf("failed to read badge: %v", err)
+	}
+	svg := string(data)
+	if !strings.HasPrefix(svg, "<svg ") || !strings.Contains(svg, "<title>dependencies: 12 updates: 2 major</title>") || !strings.Contains(svg, `fill="#e05d44"`) {
+		t.Errorf("unexpected badge SVG:\n%s", svg)
+	}
+}
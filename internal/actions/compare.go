@@ -6,35 +6,113 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/mxcd/updater/internal/compare"
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/history"
 	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
 type CompareOptions struct {
-	ConfigPath   string
-	OutputFormat string
-	Limit        int
-	Only         string
+	ConfigPath        string
+	Recursive         bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment       string // Environment whose overrides are applied
+	OutputFormat      string
+	Limit             int
+	Only              string
+	SummaryFile       string
+	GitHubAnnotations bool
+	Targets           []string          // Only compare targets with these names
+	SBOMs             []string          // Only compare items whose source has a component in these CycloneDX or SPDX SBOMs
+	AuditLog          string            // Append audit events to this file, "-" for stderr
+	Since             string            // Only report updates whose current version is older than this (e.g. "30d")
+	GroupBy           string            // Table grouping: patch-group (default), target-file, source
+	SortBy            string            // Table row order: update-type, name, age; configuration order if empty
+	HealthFile        string            // Source health state file, per configuration in the user cache directory if empty
+	StaleAfter        string            // Report sources without a new version for this long (e.g. "180d")
+	BadgeFile         string            // Write a badge of the pending updates to this file, SVG or shields.io JSON by extension
+	Profile           bool              // Report the time spent per phase and source
+	SkippedVersions   bool              // List the versions skipped by each update in JSON and YAML output
+	History           *history.Store    // Record the run in this history database, disabled if nil
+	HistoryScope      string            // Scope the run is recorded under, e.g. the UpdaterConfig "namespace/name"
+	Snoozes           []*history.Snooze // Updates held back until their snooze ends
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+
+	onFinish func(summary *RunSummary) // Called with the summary of the finished run, e.g. by serve
+	config   *configuration.Config     // Configuration to run with instead of loading ConfigPath, e.g. by the operator
 }
 
 type CompareResult struct {
-	Results    []*compare.ComparisonResult
-	HasUpdates bool
+	Results      []*compare.ComparisonResult
+	HasUpdates   bool
+	ScrapeResult *scraper.ScrapeResult
 }
 
-func Compare(options *CompareOptions) (*CompareResult, error) {
+func Compare(options *CompareOptions) (compareResult *CompareResult, err error) {
 	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
 
-	// Load configuration
-	config, err := configuration.LoadConfiguration(options.ConfigPath)
+	summary := newRunSummary("compare")
+	if options.Profile {
+		summary.enableProfile()
+	}
+	defer func() {
+		if compareResult != nil {
+			summary.addScrapeResult(compareResult.ScrapeResult)
+			summary.addComparisonResults(compareResult.Results)
+		}
+		summary.addError(err)
+		summary.printProfile()
+		summary.write(options.SummaryFile)
+		summary.recordHistory(options.History, options.HistoryScope)
+		if options.onFinish != nil {
+			options.onFinish(summary)
+		}
+		if err == nil {
+			writeBadge(options.BadgeFile, summary.Updates)
+		}
+	}()
+
+	audit, err := openAuditLog(options.AuditLog, summary.RunID, "compare")
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to load configuration")
-		return nil, fmt.Errorf("configuration load error: %w", err)
+		return nil, err
+	}
+	defer func() {
+		audit.close(err)
+	}()
+
+	log.Debug().Str("runId", summary.RunID).Msg("Starting compare run")
+
+	layout := &tableLayout{GroupBy: options.GroupBy, SortBy: options.SortBy}
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+
+	var since time.Duration
+	if options.Since != "" {
+		if since, err = util.ParseDuration(options.Since); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+
+	healthFile, staleAfter, err := resolveHealthOptions(options.HealthFile, options.StaleAfter, options.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load configuration
+	endParse := summary.startPhase(phaseParse)
+	config := options.config
+	if config == nil {
+		if config, err = loadConfiguration(options.ConfigPath, options.Recursive, options.Environment); err != nil {
+			log.Error().Err(err).Msg("Failed to load configuration")
+			return nil, fmt.Errorf("configuration load error: %w", err)
+		}
 	}
 
 	log.Debug().Msg("Configuration loaded successfully")
@@ -51,8 +129,17 @@ func Compare(options *CompareOptions) (*CompareResult, error) {
 
 	log.Debug().Msg("Configuration is valid")
 
+	// Restrict to the requested targets
+	if err := configuration.FilterTargets(config, options.Targets); err != nil {
+		return nil, err
+	}
+	if err := restrictToSBOM(config, options.SBOMs); err != nil {
+		return nil, err
+	}
+	endParse()
+
 	// Create orchestrator and scrape sources
-	orchestrator, err := scraper.NewOrchestrator(config)
+	orchestrator, err := scraper.NewOrchestrator(config, options.Scraper)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create scraper orchestrator")
 		return nil, fmt.Errorf("orchestrator creation error: %w", err)
@@ -60,46 +147,83 @@ func Compare(options *CompareOptions) (*CompareResult, error) {
 
 	log.Debug().Msg("Scraper orchestrator created successfully")
 
-	// Scrape all sources
+	// Scrape the sources referenced by the targets
 	scrapeOptions := &scraper.ScrapeOptions{
-		Limit: options.Limit,
+		Limit:          options.Limit,
+		Sources:        configuration.ReferencedSources(config),
+		StopAtVersions: compare.NewCompareEngine(config).OldestCurrentVersions(),
 	}
 
+	endScrape := summary.startPhase(phaseScrape)
 	scrapeResult := orchestrator.ScrapeAllSources(scrapeOptions)
+	endScrape()
 
 	log.Debug().
 		Int("succeeded", scrapeResult.Succeeded).
 		Int("failed", scrapeResult.Failed).
 		Msg("Scraping complete")
 
+	healthIssues := recordSourceHealth(config, scrapeResult, healthFile, staleAfter)
+
 	// Create comparison engine (works with partial results from successful sources)
 	compareEngine := compare.NewCompareEngine(orchestrator.GetConfig())
 
 	// Perform comparison
+	endCompare := summary.startPhase(phaseCompare)
 	results, err := compareEngine.CompareAll()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to compare targets")
 		return nil, fmt.Errorf("comparison error: %w", err)
 	}
 
+	// Hold back updates of later rollout stages until the previous stage is ready
+	applyRollout(orchestrator.GetConfig(), results, time.Now())
+	applySnoozes(results, options.Snoozes, time.Now())
+
+	// Keep the items of version sets at one version after holding back single items
+	compare.AlignVersionSets(results)
+
+	// Score the risk of the updates left after holding back
+	compare.AssessRisks(results, time.Now())
+	if options.SkippedVersions {
+		compare.ListSkippedVersions(results)
+	}
+	endCompare()
+
 	// Filter results based on 'only' flag
 	filteredResults := filterComparisonResults(results, options.Only)
-
-	// Output results
-	if err := outputComparisonResults(filteredResults, options.OutputFormat); err != nil {
+	audit.recordDecisions(filteredResults)
+
+	// Report update debt instead of all results when --since is set
+	var debts []*UpdateDebt
+	if options.Since != "" {
+		debts = collectUpdateDebt(filteredResults, since)
+		if err := outputUpdateDebt(debts, options.Since, options.OutputFormat); err != nil {
+			log.Error().Err(err).Msg("Failed to output update debt")
+			return nil, fmt.Errorf("output error: %w", err)
+		}
+	} else if err := outputComparisonResults(filteredResults, options.OutputFormat, layout); err != nil {
 		log.Error().Err(err).Msg("Failed to output comparison results")
 		return nil, fmt.Errorf("output error: %w", err)
 	}
 
+	// Emit workflow annotations when running in GitHub Actions
+	if options.GitHubAnnotations {
+		emitGitHubAnnotations(os.Stderr, filteredResults)
+	}
+
 	// Show scraping errors at the end
 	if scrapeResult.HasErrors() {
-		fmt.Printf("\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
+		fmt.Fprintf(util.StatusOutput(), "\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
 		for _, scrapeErr := range scrapeResult.Errors {
-			fmt.Printf("  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
+			fmt.Fprintf(util.StatusOutput(), "  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
 		}
-		fmt.Println()
+		fmt.Fprintln(util.StatusOutput())
 	}
 
+	// Warn about sources that look like a moved repository or renamed image
+	printSourceHealthIssues(healthIssues)
+
 	// Check if there are pending updates
 	hasUpdates := false
 	for _, result := range filteredResults {
@@ -109,6 +233,16 @@ func Compare(options *CompareOptions) (*CompareResult, error) {
 		}
 	}
 
+	if options.Since != "" {
+		hasUpdates = len(debts) > 0
+	}
+
+	if options.GitHubAnnotations {
+		writeGitHubOutputs(map[string]string{
+			"updates_count": fmt.Sprintf("%d", countPendingUpdates(filteredResults)),
+		}, []string{"updates_count"})
+	}
+
 	if hasUpdates {
 		log.Info().Msg("Updates are available")
 	} else {
@@ -116,8 +250,9 @@ func Compare(options *CompareOptions) (*CompareResult, error) {
 	}
 
 	return &CompareResult{
-		Results:    filteredResults,
-		HasUpdates: hasUpdates,
+		Results:      filteredResults,
+		HasUpdates:   hasUpdates,
+		ScrapeResult: scrapeResult,
 	}, nil
 }
 
@@ -141,15 +276,19 @@ func filterComparisonResults(results []*compare.ComparisonResult, only string) [
 			if result.UpdateType == compare.UpdateTypePatch {
 				filtered = append(filtered, result)
 			}
+		case "downgrade":
+			if result.UpdateType == compare.UpdateTypeDowngrade {
+				filtered = append(filtered, result)
+			}
 		}
 	}
-	return filtered
+	return keepWholeVersionSetResults(results, filtered)
 }
 
-func outputComparisonResults(results []*compare.ComparisonResult, format string) error {
+func outputComparisonResults(results []*compare.ComparisonResult, format string, layout *tableLayout) error {
 	switch format {
 	case "table":
-		return outputComparisonTable(results)
+		return outputComparisonTable(results, layout)
 	case "json":
 		return outputComparisonJSON(results)
 	case "yaml":
@@ -159,13 +298,13 @@ func outputComparisonResults(results []*compare.ComparisonResult, format string)
 	}
 }
 
-func outputComparisonTable(results []*compare.ComparisonResult) error {
+func outputComparisonTable(results []*compare.ComparisonResult, layout *tableLayout) error {
 	// Filter out dependency not found errors from wildcard matches
 	// These are expected when some files don't have the dependency
 	filteredResults := filterWildcardDependencyErrors(results)
 
-	// Group results by patch group
-	groupedResults := groupResultsByPatchGroup(filteredResults)
+	// Group results by patch group, target file or source
+	groupedResults := groupComparisonResults(filteredResults, layout)
 
 	// Get sorted group names
 	groupNames := make([]string, 0, len(groupedResults))
@@ -175,27 +314,31 @@ func outputComparisonTable(results []*compare.ComparisonResult) error {
 	// Sort groups: empty group first, then alphabetically
 	sortPatchGroups(groupNames)
 
-	totalUpdates := 0
+	totalUpdates := &UpdateCounts{}
 	totalErrors := 0
+	totalHeldBack := 0
+	totalDowngrades := 0
 
 	// Render each group
 	for i, groupName := range groupNames {
 		groupResults := groupedResults[groupName]
+		sortComparisonResults(groupResults, layout.SortBy)
 
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
+		t := util.NewTable()
 
 		// Set title based on whether this is a named group or not
 		if groupName == "" {
 			t.SetTitle("🔍 Version Comparison")
 		} else {
-			t.SetTitle(fmt.Sprintf("🔍 Version Comparison - Patch Group: %s", groupName))
+			t.SetTitle(fmt.Sprintf("🔍 Version Comparison - %s", layout.groupTitle(groupName)))
 		}
 
-		t.AppendHeader(table.Row{"File / Variable", "Source", "Current", "Latest", "Update Type", "Status"})
+		t.AppendHeader(table.Row{"File / Variable", "Source", "Current", "Latest", "Update Type", "Risk", "Status"})
 
 		groupUpdates := 0
 		groupErrors := 0
+		groupHeldBack := 0
+		groupDowngrades := 0
 
 		for _, result := range groupResults {
 			// Build the first column based on target type
@@ -203,6 +346,9 @@ func outputComparisonTable(results []*compare.ComparisonResult) error {
 			if result.TargetItemName != "" {
 				// Show file path and item name (variable/subchart)
 				firstColumn = fmt.Sprintf("%s\n  → %s", result.TargetFile, result.TargetItemName)
+				if result.Track != "" {
+					firstColumn = fmt.Sprintf("%s (track: %s)", firstColumn, result.Track)
+				}
 			} else {
 				// Fallback to target name if no item name
 				firstColumn = result.TargetName
@@ -216,14 +362,37 @@ func outputComparisonTable(results []*compare.ComparisonResult) error {
 					"-",
 					"-",
 					"-",
+					"-",
 					fmt.Sprintf("❌ Error: %v", result.Error),
 				})
 			} else {
 				status := "✅ Up to date"
+				totalUpdates.add(result)
 				if result.NeedsUpdate {
 					groupUpdates++
 					status = fmt.Sprintf("🔄 Update available (%s)", result.UpdateType)
 				}
+				if result.UpdateType == compare.UpdateTypeDowngrade {
+					groupDowngrades++
+					status = "⬇️  Current is newer than the latest available version"
+				}
+				if result.Drifted {
+					status = fmt.Sprintf("%s\n🚢 Cluster runs %s, file has %s", status, result.CurrentVersion, result.FileVersion)
+				}
+				if result.UnknownVersion != "" {
+					status = fmt.Sprintf("%s\n🔍 %s", status, result.UnknownVersion)
+				}
+				if result.VersionSetInconsistent {
+					status = fmt.Sprintf("%s\n⚠️  Version set '%s' is inconsistent", status, result.VersionSet)
+				}
+				if result.HeldBackVersion != "" {
+					groupHeldBack++
+					cause := fmt.Sprintf("max: %s", result.MaxUpdateType)
+					if result.HeldBackReason != "" {
+						cause = result.HeldBackReason
+					}
+					status = fmt.Sprintf("%s\n⛔ %s %s held back (%s)", status, result.HeldBackType, result.HeldBackVersion, cause)
+				}
 
 				t.AppendRow(table.Row{
 					firstColumn,
@@ -231,60 +400,67 @@ func outputComparisonTable(results []*compare.ComparisonResult) error {
 					result.CurrentVersion,
 					result.LatestVersion,
 					result.UpdateType,
+					formatRisk(result.Risk),
 					status,
 				})
 			}
 		}
 
-		t.SetStyle(table.StyleRounded)
 		t.Render()
 
 		// Group summary
-		if groupErrors > 0 || groupUpdates > 0 {
-			fmt.Print("  ")
+		if groupErrors > 0 || groupUpdates > 0 || groupHeldBack > 0 || groupDowngrades > 0 {
+			fmt.Fprint(util.ResultOutput(), "  ")
 			if groupErrors > 0 {
-				fmt.Printf("⚠️  %d error(s)  ", groupErrors)
+				fmt.Fprintf(util.ResultOutput(), "⚠️  %d error(s)  ", groupErrors)
 			}
 			if groupUpdates > 0 {
-				fmt.Printf("🔄 %d update(s)", groupUpdates)
+				fmt.Fprintf(util.ResultOutput(), "🔄 %d update(s)  ", groupUpdates)
 			}
-			fmt.Println()
+			if groupHeldBack > 0 {
+				fmt.Fprintf(util.ResultOutput(), "⛔ %d held back  ", groupHeldBack)
+			}
+			if groupDowngrades > 0 {
+				fmt.Fprintf(util.ResultOutput(), "⬇️  %d ahead of source", groupDowngrades)
+			}
+			fmt.Fprintln(util.ResultOutput())
 		}
 
 		// Add spacing between groups
 		if i < len(groupNames)-1 {
-			fmt.Println()
+			fmt.Fprintln(util.ResultOutput())
 		}
 
-		totalUpdates += groupUpdates
 		totalErrors += groupErrors
+		totalHeldBack += groupHeldBack
+		totalDowngrades += groupDowngrades
 	}
 
-	fmt.Println()
+	fmt.Fprintln(util.StatusOutput())
 
 	// Overall summary
 	if totalErrors > 0 {
-		fmt.Printf("⚠️  Total: %d target(s) with errors\n", totalErrors)
+		fmt.Fprintf(util.StatusOutput(), "⚠️  Total: %d target(s) with errors\n", totalErrors)
 	}
-	if totalUpdates > 0 {
-		fmt.Printf("🔄 Total: %d target(s) need updating\n", totalUpdates)
+	if totalUpdates.Total > 0 {
+		types := ""
+		if description := totalUpdates.describeTypes(); description != "" {
+			types = fmt.Sprintf(" (%s)", description)
+		}
+		fmt.Fprintf(util.StatusOutput(), "🔄 Total: %d target(s) need updating%s\n", totalUpdates.Total, types)
 	} else {
-		fmt.Println("✅ All targets are up to date")
+		fmt.Fprintln(util.StatusOutput(), "✅ All targets are up to date")
+	}
+	if totalHeldBack > 0 {
+		fmt.Fprintf(util.StatusOutput(), "⛔ Total: %d update(s) held back by maxUpdateType or rollout\n", totalHeldBack)
+	}
+	if totalDowngrades > 0 {
+		fmt.Fprintf(util.StatusOutput(), "⬇️  Total: %d target(s) newer than their source's latest version, check tagPattern and deleted releases\n", totalDowngrades)
 	}
 
 	return nil
 }
 
-// groupResultsByPatchGroup groups comparison results by their patch group
-func groupResultsByPatchGroup(results []*compare.ComparisonResult) map[string][]*compare.ComparisonResult {
-	grouped := make(map[string][]*compare.ComparisonResult)
-	for _, result := range results {
-		groupName := result.PatchGroup
-		grouped[groupName] = append(grouped[groupName], result)
-	}
-	return grouped
-}
-
 // sortPatchGroups sorts patch group names with empty string first, then alphabetically
 func sortPatchGroups(groups []string) {
 	sort.Slice(groups, func(i, j int) bool {
@@ -357,7 +533,9 @@ func outputComparisonJSON(results []*compare.ComparisonResult) error {
 	output := map[string]interface{}{
 		"results": results,
 	}
-	encoder := json.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
@@ -366,7 +544,9 @@ func outputComparisonYAML(results []*compare.ComparisonResult) error {
 	output := map[string]interface{}{
 		"results": results,
 	}
-	encoder := yaml.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := yaml.NewEncoder(writer)
 	encoder.SetIndent(2)
 	return encoder.Encode(output)
 }
@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/rs/zerolog/log"
+)
+
+// Groupings and orderings of the compare table
+const (
+	GroupByPatchGroup = "patch-group"
+	GroupByTargetFile = "target-file"
+	GroupBySource     = "source"
+
+	SortByName       = "name"
+	SortByUpdateType = "update-type"
+	SortByAge        = "age"
+)
+
+// tableLayout controls how the comparison table is split into tables and ordered
+type tableLayout struct {
+	GroupBy string // One table per patch group (default), target file or source
+	SortBy  string // Row order within a table, configuration order if empty
+}
+
+func (l *tableLayout) validate() error {
+	switch l.GroupBy {
+	case "", GroupByPatchGroup, GroupByTargetFile, GroupBySource:
+	default:
+		return fmt.Errorf("invalid --group-by: %s (must be %s, %s or %s)", l.GroupBy, GroupByTargetFile, GroupBySource, GroupByPatchGroup)
+	}
+	switch l.SortBy {
+	case "", SortByName, SortByUpdateType, SortByAge:
+	default:
+		return fmt.Errorf("invalid --sort-by: %s (must be %s, %s or %s)", l.SortBy, SortByUpdateType, SortByName, SortByAge)
+	}
+	return nil
+}
+
+// groupTitle returns the table title suffix of a named group
+func (l *tableLayout) groupTitle(name string) string {
+	switch l.GroupBy {
+	case GroupByTargetFile:
+		return "File: " + name
+	case GroupBySource:
+		return "Source: " + name
+	default:
+		return "Patch Group: " + name
+	}
+}
+
+// groupComparisonResults groups comparison results by the layout's grouping
+func groupComparisonResults(results []*compare.ComparisonResult, layout *tableLayout) map[string][]*compare.ComparisonResult {
+	grouped := make(map[string][]*compare.ComparisonResult)
+	for _, result := range results {
+		var groupName string
+		switch layout.GroupBy {
+		case GroupByTargetFile:
+			groupName = result.TargetFile
+		case GroupBySource:
+			groupName = result.SourceName
+		default:
+			groupName = result.PatchGroup
+		}
+		grouped[groupName] = append(grouped[groupName], result)
+	}
+	return grouped
+}
+
+// updateTypeRank orders results by urgency: major updates first, errors last
+func updateTypeRank(result *compare.ComparisonResult) int {
+	if result.Error != nil {
+		return 5
+	}
+	if !result.NeedsUpdate {
+		return 4
+	}
+	switch result.UpdateType {
+	case compare.UpdateTypeMajor:
+		return 0
+	case compare.UpdateTypeMinor:
+		return 1
+	case compare.UpdateTypePatch:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// sortComparisonResults orders the rows of a table. Sorting by age looks up in git how long
+// each current version has been pinned, oldest first; rows without history come last.
+func sortComparisonResults(results []*compare.ComparisonResult, sortBy string) {
+	switch sortBy {
+	case SortByName:
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].TargetName != results[j].TargetName {
+				return results[i].TargetName < results[j].TargetName
+			}
+			if results[i].TargetFile != results[j].TargetFile {
+				return results[i].TargetFile < results[j].TargetFile
+			}
+			return results[i].TargetItemName < results[j].TargetItemName
+		})
+	case SortByUpdateType:
+		sort.SliceStable(results, func(i, j int) bool {
+			return updateTypeRank(results[i]) < updateTypeRank(results[j])
+		})
+	case SortByAge:
+		pinned := make(map[*compare.ComparisonResult]time.Time, len(results))
+		for _, result := range results {
+			if result.Error != nil {
+				continue
+			}
+			pinnedSince, err := currentVersionPinnedSince(result)
+			if err != nil {
+				log.Debug().Err(err).Str("file", result.TargetFile).Msg("Unknown age of current version")
+				continue
+			}
+			pinned[result] = pinnedSince
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			a, aKnown := pinned[results[i]]
+			b, bKnown := pinned[results[j]]
+			if aKnown != bKnown {
+				return aKnown
+			}
+			return a.Before(b)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+func TestTableLayout_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		layout        tableLayout
+		errorContains string
+	}{
+		{name: "defaults", layout: tableLayout{}},
+		{name: "valid grouping and ordering", layout: tableLayout{GroupBy: GroupBySource, SortBy: SortByAge}},
+		{name: "invalid grouping", layout: tableLayout{GroupBy: "owner"}, errorContains: "invalid --group-by: owner"},
+		{name: "invalid ordering", layout: tableLayout{SortBy: "size"}, errorContains: "invalid --sort-by: size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.layout.validate()
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
+func TestGroupComparisonResults(t *testing.T) {
+	results := []*compare.ComparisonResult{
+		{TargetName: "a", PatchGroup: "prod", TargetFile: "prod.yaml", SourceName: "nginx"},
+		{TargetName: "b", PatchGroup: "prod", TargetFile: "shared.yaml", SourceName: "redis"},
+		{TargetName: "c", PatchGroup: "dev", TargetFile: "shared.yaml", SourceName: "nginx"},
+	}
+
+	tests := []struct {
+		groupBy  string
+		expected map[string][]string
+		title    string
+	}{
+		{groupBy: "", expected: map[string][]string{"prod": {"a", "b"}, "dev": {"c"}}, title: "Patch Group: prod"},
+		{groupBy: GroupByPatchGroup, expected: map[string][]string{"prod": {"a", "b"}, "dev": {"c"}}, title: "Patch Group: prod"},
+		{groupBy: GroupByTargetFile, expected: map[string][]string{"prod.yaml": {"a"}, "shared.yaml": {"b", "c"}}, title: "File: prod"},
+		{groupBy: GroupBySource, expected: map[string][]string{"nginx": {"a", "c"}, "redis": {"b"}}, title: "Source: prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run("group by "+tt.groupBy, func(t *testing.T) {
+			layout := &tableLayout{GroupBy: tt.groupBy}
+			grouped := groupComparisonResults(results, layout)
+			if len(grouped) != len(tt.expected) {
+				t.Fatalf("expected %d groups, got %d", len(tt.expected), len(grouped))
+			}
+			for group, names := range tt.expected {
+				if got := targetNames(grouped[group]); strings.Join(got, ",") != strings.Join(names, ",") {
+					t.Errorf("group %s: expected %v, got %v", group, names, got)
+				}
+			}
+			if title := layout.groupTitle("prod"); title != tt.title {
+				t.Errorf("expected title %q, got %q", tt.title, title)
+			}
+		})
+	}
+}
+
+func TestSortComparisonResults(t *testing.T) {
+	newResults := func() []*compare.ComparisonResult {
+		return []*compare.ComparisonResult{
+			{TargetName: "patch", NeedsUpdate: true, UpdateType: compare.UpdateTypePatch},
+			{TargetName: "failed", Error: errors.New("source not found")},
+			{TargetName: "current"},
+			{TargetName: "major", NeedsUpdate: true, UpdateType: compare.UpdateTypeMajor},
+			{TargetName: "minor", NeedsUpdate: true, UpdateType: compare.UpdateTypeMinor},
+			{TargetName: "digest", NeedsUpdate: true, UpdateType: compare.UpdateTypeNone},
+		}
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected []string
+	}{
+		{sortBy: "", expected: []string{"patch", "failed", "current", "major", "minor", "digest"}},
+		{sortBy: SortByName, expected: []string{"current", "digest", "failed", "major", "minor", "patch"}},
+		{sortBy: SortByUpdateType, expected: []string{"major", "minor", "patch", "digest", "current", "failed"}},
+		// Rows without git history keep their order
+		{sortBy: SortByAge, expected: []string{"patch", "failed", "current", "major", "minor", "digest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("sort by "+tt.sortBy, func(t *testing.T) {
+			results := newResults()
+			sortComparisonResults(results, tt.sortBy)
+			if got := targetNames(results); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSortComparisonResults_NameTieBreak(t *testing.T) {
+	results := []*compare.ComparisonResult{
+		{TargetName: "app", TargetFile: "b.yaml", TargetItemName: "image"},
+		{TargetName: "app", TargetFile: "a.yaml", TargetItemName: "sidecar"},
+		{TargetName: "app", TargetFile: "a.yaml", TargetItemName: "image"},
+	}
+	sortComparisonResults(results, SortByName)
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.TargetFile+"/"+result.TargetItemName)
+	}
+	expected := "a.yaml/image,a.yaml/sidecar,b.yaml/image"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(got, ","))
+	}
+}
+
+// targetNames returns the target names of the results in order
+func targetNames(results []*compare.ComparisonResult) []string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.TargetName)
+	}
+	return names
+}
@@ -0,0 +1,112 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/util"
+)
+
+// captureOutput runs fn with stdout and stderr redirected to files and returns what was
+// written to each
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("failed to create stdout: %v", err)
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatalf("failed to create stderr: %v", err)
+	}
+	defer stderr.Close()
+
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = originalStdout, originalStderr
+	}()
+	fn()
+
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("failed to read stdout: %v", err)
+	}
+	errOut, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatalf("failed to read stderr: %v", err)
+	}
+	return string(out), string(errOut)
+}
+
+func TestCompare_MachineOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "main.tf"), "variable \"app_version\" {\n  default = \"1.0.0\"\n}\n")
+	writeTestFile(t, filepath.Join(dir, ".updater.yml"), fmt.Sprintf(`packageSources:
+  - name: app
+    type: static
+    staticVersions:
+      - 1.1.0
+      - 1.0.0
+targets:
+  - name: app
+    type: terraform-variable
+    file: %s
+    items:
+      - terraformVariableName: app_version
+        source: app
+`, filepath.Join(dir, "main.tf")))
+
+	tests := []struct {
+		name   string
+		quiet  bool
+		status string // Expected on stderr, nothing if empty
+	}{
+		{name: "status text on stderr", status: "Scraping package sources"},
+		{name: "quiet", quiet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util.SetQuiet(tt.quiet)
+			defer util.SetQuiet(false)
+
+			var err error
+			stdout, stderr := captureOutput(t, func() {
+				_, err = Compare(&CompareOptions{
+					ConfigPath:   filepath.Join(dir, ".updater.yml"),
+					OutputFormat: "json",
+					Only:         "all",
+					HealthFile:   filepath.Join(t.TempDir(), "health.json"),
+				})
+			})
+			if err != nil {
+				t.Fatalf("Compare() failed: %v", err)
+			}
+
+			var output struct {
+				Results []map[string]any `json:"results"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+				t.Fatalf("expected stdout to be JSON only, got %v:\n%s", err, stdout)
+			}
+			if len(output.Results) != 1 {
+				t.Errorf("expected one result, got %d", len(output.Results))
+			}
+
+			if tt.status == "" {
+				if stderr != "" {
+					t.Errorf("expected no status text in quiet mode, got:\n%s", stderr)
+				}
+			} else if !strings.Contains(stderr, tt.status) {
+				t.Errorf("expected %q on stderr, got:\n%s", tt.status, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/target"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type DetectOptions struct {
+	Path         string // Root directory of the repository to scan
+	OutputFormat string
+}
+
+// detectResult is the machine-readable output of detect
+type detectResult struct {
+	Detections []target.Detection     `json:"detections" yaml:"detections"`
+	Targets    []configuration.Target `json:"targets" yaml:"targets"`
+}
+
+// Detect scans a repository for files and versions updater could manage and prints them
+// with suggested target stanzas, independent of any configuration
+func Detect(options *DetectOptions) error {
+	log.Debug().Str("path", options.Path).Msg("Detecting managed files...")
+
+	detections, err := target.Detect(options.Path)
+	if err != nil {
+		return err
+	}
+	result := &detectResult{
+		Detections: detections,
+		Targets:    target.SuggestTargets(detections),
+	}
+	if result.Detections == nil {
+		result.Detections = []target.Detection{}
+	}
+	if result.Targets == nil {
+		result.Targets = []configuration.Target{}
+	}
+
+	switch options.OutputFormat {
+	case "table":
+		return outputDetectTable(result)
+	case "json":
+		// Targets only have YAML field names, so they are converted through YAML
+		var targets interface{}
+		content, err := yaml.Marshal(result.Targets)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(content, &targets); err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"detections": result.Detections,
+			"targets":    targets,
+		})
+	case "yaml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		return encoder.Encode(result)
+	default:
+		return fmt.Errorf("unsupported output format: %s", options.OutputFormat)
+	}
+}
+
+func outputDetectTable(result *detectResult) error {
+	if len(result.Detections) == 0 {
+		fmt.Fprintln(util.ResultOutput(), "✓ No managed files detected")
+		return nil
+	}
+
+	t := util.NewTable()
+	t.AppendHeader(table.Row{"File", "Manager", "Dependency", "Version", "Target Type"})
+	for _, detection := range result.Detections {
+		targetType := string(detection.TargetType)
+		if targetType == "" {
+			targetType = "(not supported yet)"
+		}
+		t.AppendRow(table.Row{detection.File, detection.Manager, detection.Dependency, detection.Version, targetType})
+	}
+	t.Render()
+
+	if len(result.Targets) == 0 {
+		return nil
+	}
+	fmt.Fprintln(util.StatusOutput(), "\nSuggested targets, replace the sources with package sources of your configuration:")
+	fmt.Fprintln(util.StatusOutput())
+	encoder := yaml.NewEncoder(util.ResultOutput())
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]interface{}{"targets": result.Targets}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
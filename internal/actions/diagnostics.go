@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// startPprofServer serves the net/http/pprof endpoints under /debug/pprof/ on address until
+// the context ends. The endpoints are registered on their own mux, so nothing else is exposed.
+func startPprofServer(ctx context.Context, address string) (net.Addr, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on pprof address %s: %w", address, err)
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("pprof server failed")
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info().Str("address", listener.Addr().String()).Msg("Serving pprof endpoints under /debug/pprof/")
+	return listener.Addr(), nil
+}
+
+// logRuntimeStats logs memory and goroutine statistics every interval until the context
+// ends, to spot leaks of long-running processes
+func logRuntimeStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			log.Info().
+				Uint64("heapAllocBytes", stats.HeapAlloc).
+				Uint64("heapInuseBytes", stats.HeapInuse).
+				Uint64("heapObjects", stats.HeapObjects).
+				Uint64("sysBytes", stats.Sys).
+				Uint32("numGC", stats.NumGC).
+				Int("goroutines", runtime.NumGoroutine()).
+				Msg("Runtime stats")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestStartPprofServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := startPprofServer(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/pprof/heap", http.StatusOK},
+		{"/debug/pprof/goroutine?debug=1", http.StatusOK},
+		{"/", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get("http://" + addr.String() + tt.path)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	if _, err := startPprofServer(ctx, addr.String()); err == nil {
+		t.Error("Expected an error for an address in use")
+	}
+}
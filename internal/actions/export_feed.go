@@ -0,0 +1,144 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/scraper/offline"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type ExportFeedOptions struct {
+	ConfigPath  string
+	Recursive   bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment string // Environment whose overrides are applied
+	Output      string // Path of the feed bundle (.tar.gz)
+	Limit       int
+	SigningKey  string // cosign key signing the feed, keyless signing if empty
+	Unsigned    bool   // Bundle the feed without signature
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+}
+
+// ExportFeed scrapes all sources of the configuration and writes their versions as a version
+// feed for offline providers, signed with cosign, into a feed bundle
+func ExportFeed(options *ExportFeedOptions) error {
+	if options.Output == "" {
+		return fmt.Errorf("--output is required")
+	}
+	if !offline.IsBundle(options.Output) {
+		return fmt.Errorf("--output must be a .tar.gz or .tgz file, got %q", options.Output)
+	}
+
+	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
+
+	config, err := loadConfiguration(options.ConfigPath, options.Recursive, options.Environment)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load configuration")
+		return fmt.Errorf("configuration load error: %w", err)
+	}
+
+	validationResult := configuration.ValidateConfiguration(config)
+	if !validationResult.Valid {
+		log.Error().Msg("Configuration validation failed")
+		for _, validationErr := range validationResult.Errors {
+			log.Error().Str("field", validationErr.Field).Msg(validationErr.Message)
+		}
+		return fmt.Errorf("configuration validation failed")
+	}
+
+	orchestrator, err := scraper.NewOrchestrator(config, options.Scraper)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create scraper orchestrator")
+		return fmt.Errorf("orchestrator creation error: %w", err)
+	}
+	scrapeResult := orchestrator.ScrapeAllSources(&scraper.ScrapeOptions{Limit: options.Limit})
+
+	failed := make(map[string]bool)
+	for _, scrapeErr := range scrapeResult.Errors {
+		failed[scrapeErr.SourceName] = true
+	}
+	generatedAt := time.Now().UTC()
+	feed := buildFeed(orchestrator.GetConfig(), failed, generatedAt)
+
+	if err := writeFeedBundle(feed, options); err != nil {
+		return err
+	}
+	fmt.Fprintf(util.StatusOutput(), "📦 Exported the versions of %d source(s) to %s\n", len(feed.Sources), options.Output)
+
+	// Sources that failed to scrape are missing from the feed and fail offline as well
+	if scrapeResult.HasErrors() {
+		fmt.Fprintf(util.StatusOutput(), "\n⚠️  %d of %d source(s) failed to scrape and are not in the feed:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
+		for _, scrapeErr := range scrapeResult.Errors {
+			fmt.Fprintf(util.StatusOutput(), "  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
+		}
+		fmt.Fprintln(util.StatusOutput())
+		return fmt.Errorf("%d source(s) failed to scrape", scrapeResult.Failed)
+	}
+
+	log.Info().Int("sources", len(feed.Sources)).Str("output", options.Output).Msg("Exported version feed")
+	return nil
+}
+
+// buildFeed returns the feed of the scraped sources. Static sources need no feed, discovery
+// sources are represented by the sources discovered from them, and sources that failed to
+// scrape are left out.
+func buildFeed(config *configuration.Config, failed map[string]bool, generatedAt time.Time) *offline.Feed {
+	feed := &offline.Feed{
+		GeneratedAt: generatedAt,
+		Sources:     make([]*offline.FeedSource, 0, len(config.PackageSources)),
+	}
+	for _, source := range config.PackageSources {
+		if source.Type == configuration.PackageSourceTypeStatic || source.Discovers() || failed[source.Name] {
+			continue
+		}
+		versions := source.Versions
+		if versions == nil {
+			versions = make([]*configuration.PackageSourceVersion, 0)
+		}
+		feed.Sources = append(feed.Sources, &offline.FeedSource{
+			Name:     source.Name,
+			Type:     source.Type,
+			URI:      source.URI,
+			Versions: versions,
+		})
+	}
+	return feed
+}
+
+// writeFeedBundle writes the feed and its cosign signature bundle into the feed bundle
+func writeFeedBundle(feed *offline.Feed, options *ExportFeedOptions) error {
+	dir, err := os.MkdirTemp("", "updater-feed-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := yaml.Marshal(feed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	feedPath := filepath.Join(dir, offline.BundleFeedFile)
+	if err := os.WriteFile(feedPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+
+	files := []string{feedPath}
+	if options.Unsigned {
+		log.Warn().Msg("Exporting the version feed without signature")
+	} else {
+		signaturePath := filepath.Join(dir, offline.BundleSignatureFile)
+		if err := signBlob(feedPath, signaturePath, options.SigningKey); err != nil {
+			return err
+		}
+		files = append(files, signaturePath)
+	}
+
+	return offline.WriteBundle(options.Output, files, feed.GeneratedAt)
+}
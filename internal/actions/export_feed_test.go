@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/offline"
+)
+
+func TestBuildFeed(t *testing.T) {
+	config := &configuration.Config{
+		PackageSources: []*configuration.PackageSource{
+			{Name: "app", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/app", Versions: []*configuration.PackageSourceVersion{{Version: "2.0.0"}, {Version: "1.9.0"}}},
+			{Name: "pinned", Type: configuration.PackageSourceTypeStatic, StaticVersions: []string{"1.0.0"}},
+			{Name: "images", Type: configuration.PackageSourceTypeDockerNamespace, URI: "registry.example.com/images"},
+			{Name: "images/api", Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/images/api", DiscoveredBy: "images"},
+			{Name: "broken", Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/org/broken"},
+		},
+	}
+
+	feed := buildFeed(config, map[string]bool{"broken": true}, time.Now())
+	names := make([]string, 0, len(feed.Sources))
+	for _, source := range feed.Sources {
+		names = append(names, source.Name)
+	}
+	if len(names) != 2 || names[0] != "app" || names[1] != "images/api" {
+		t.Fatalf("expected the scraped sources app and images/api, got %v", names)
+	}
+	if feed.Sources[1].Versions == nil {
+		t.Error("expected sources without versions to list an empty version list")
+	}
+}
+
+func TestWriteFeedBundle(t *testing.T) {
+	generatedAt := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	feed := &offline.Feed{
+		GeneratedAt: generatedAt,
+		Sources: []*offline.FeedSource{
+			{Name: "app", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/app", Versions: []*configuration.PackageSourceVersion{{Version: "2.0.0"}, {Version: "1.9.0"}}},
+		},
+	}
+	output := filepath.Join(t.TempDir(), "feed.tar.gz")
+	if err := writeFeedBundle(feed, &ExportFeedOptions{Output: output, Unsigned: true}); err != nil {
+		t.Fatalf("writeFeedBundle() failed: %v", err)
+	}
+
+	// Offline providers read the bundle directly
+	loaded, err := offline.LoadFeed(output)
+	if err != nil {
+		t.Fatalf("LoadFeed() failed: %v", err)
+	}
+	if !loaded.GeneratedAt.Equal(generatedAt) || len(loaded.Sources) != 1 {
+		t.Fatalf("unexpected feed %+v", loaded)
+	}
+	versions, err := loaded.Versions(&configuration.PackageSource{Name: "app", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/app"})
+	if err != nil || len(versions) != 2 || versions[0].Version != "2.0.0" || versions[0].MajorVersion != 2 {
+		t.Errorf("unexpected versions %v, %v", versions, err)
+	}
+}
+
+func TestExportFeed_Output(t *testing.T) {
+	if err := ExportFeed(&ExportFeedOptions{Output: "feed.yaml"}); err == nil {
+		t.Error("expected an output other than a .tar.gz bundle to fail")
+	}
+}
@@ -0,0 +1,272 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const fluxImageAPIVersion = "image.toolkit.fluxcd.io/v1beta2"
+
+type ExportFluxOptions struct {
+	ConfigPath  string
+	Environment string // Environment whose overrides are applied
+	OutputFile  string
+	Namespace   string
+	Interval    string
+}
+
+// fluxObject is a Flux image automation manifest
+type fluxObject struct {
+	APIVersion string       `yaml:"apiVersion"`
+	Kind       string       `yaml:"kind"`
+	Metadata   fluxMetadata `yaml:"metadata"`
+	Spec       interface{}  `yaml:"spec"`
+}
+
+type fluxMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type fluxImageRepositorySpec struct {
+	Image     string         `yaml:"image"`
+	Interval  string         `yaml:"interval"`
+	SecretRef *fluxReference `yaml:"secretRef,omitempty"`
+}
+
+type fluxReference struct {
+	Name string `yaml:"name"`
+}
+
+type fluxImagePolicySpec struct {
+	ImageRepositoryRef fluxReference   `yaml:"imageRepositoryRef"`
+	FilterTags         *fluxFilterTags `yaml:"filterTags,omitempty"`
+	Policy             fluxPolicy      `yaml:"policy"`
+}
+
+type fluxFilterTags struct {
+	Pattern string `yaml:"pattern"`
+	Extract string `yaml:"extract,omitempty"`
+}
+
+type fluxPolicy struct {
+	SemVer       *fluxSemVerPolicy       `yaml:"semver,omitempty"`
+	Alphabetical *fluxAlphabeticalPolicy `yaml:"alphabetical,omitempty"`
+}
+
+type fluxSemVerPolicy struct {
+	Range string `yaml:"range"`
+}
+
+type fluxAlphabeticalPolicy struct {
+	Order string `yaml:"order"`
+}
+
+// ExportFlux converts the docker-image sources of the configuration into Flux
+// ImageRepository and ImagePolicy manifests
+func ExportFlux(options *ExportFluxOptions) error {
+	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
+
+	config, err := loadConfiguration(options.ConfigPath, false, options.Environment)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load configuration")
+		return fmt.Errorf("configuration load error: %w", err)
+	}
+
+	validationResult := configuration.ValidateConfiguration(config)
+	if !validationResult.Valid {
+		log.Error().Msg("Configuration validation failed")
+		for _, validationErr := range validationResult.Errors {
+			log.Error().Str("field", validationErr.Field).Msg(validationErr.Message)
+		}
+		return fmt.Errorf("configuration validation failed")
+	}
+
+	objects, err := buildFluxObjects(config, options)
+	if err != nil {
+		return err
+	}
+
+	stdout := util.NewRedactingWriter(os.Stdout)
+	defer stdout.Close()
+	var writer io.Writer = stdout
+	if options.OutputFile != "" && options.OutputFile != "-" {
+		file, err := os.Create(options.OutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	for _, object := range objects {
+		if err := encoder.Encode(object); err != nil {
+			return fmt.Errorf("failed to encode %s %s: %w", object.Kind, object.Metadata.Name, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to write manifests: %w", err)
+	}
+
+	log.Info().Int("manifests", len(objects)).Msg("Exported Flux image automation manifests")
+	return nil
+}
+
+// buildFluxObjects creates an ImageRepository per docker-image source and an ImagePolicy for
+// the source and each of its tracks. Settings Flux cannot express are reported as warnings.
+func buildFluxObjects(config *configuration.Config, options *ExportFluxOptions) ([]*fluxObject, error) {
+	providers := make(map[string]*configuration.PackageSourceProvider)
+	for _, provider := range config.PackageSourceProviders {
+		providers[provider.Name] = provider
+	}
+
+	objects := make([]*fluxObject, 0)
+	for _, source := range config.PackageSources {
+		logger := log.With().Str("source", source.Name).Logger()
+
+		if source.Type != configuration.PackageSourceTypeDockerImage {
+			logger.Debug().Str("type", string(source.Type)).Msg("Flux image automation only supports images, skipping source")
+			continue
+		}
+
+		if source.SortBy != "" && source.SortBy != "semantic" && source.SortBy != "alphabetical" {
+			logger.Warn().Str("sortBy", source.SortBy).Msg("Flux has no equivalent policy, skipping source")
+			continue
+		}
+		if source.ExcludePattern != "" {
+			logger.Warn().Msg("Flux tag filters cannot exclude tags, excludePattern is ignored")
+		}
+
+		provider, ok := providers[source.Provider]
+		if !ok {
+			return nil, fmt.Errorf("source %s references unknown provider %s", source.Name, source.Provider)
+		}
+		upstream, err := docker.BuildImageName(provider.BaseUrl, source.URI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve image of source %s: %w", source.Name, err)
+		}
+		// Flux scans the mirror the cluster pulls from, which the provider has no credentials for
+		image := configuration.MapImage(config.RegistryMap, upstream)
+
+		name := fluxResourceName(source.Name)
+		repositorySpec := &fluxImageRepositorySpec{
+			Image:    image,
+			Interval: options.Interval,
+		}
+		if image == upstream && provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic || provider.AuthType == configuration.PackageSourceProviderAuthTypeToken {
+			// Credentials are never exported, the docker-registry secret must be created separately
+			repositorySpec.SecretRef = &fluxReference{Name: fluxResourceName(provider.Name) + "-registry"}
+		}
+		objects = append(objects, &fluxObject{
+			APIVersion: fluxImageAPIVersion,
+			Kind:       "ImageRepository",
+			Metadata:   fluxMetadata{Name: name, Namespace: options.Namespace},
+			Spec:       repositorySpec,
+		})
+
+		policy, err := buildFluxImagePolicy(source, name, name, source.TagPattern, options.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, policy)
+		for _, track := range source.Tracks {
+			policyName := fluxResourceName(source.Name + "-" + track.Name)
+			policy, err := buildFluxImagePolicy(source, policyName, name, track.TagPattern, options.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("track %s: %w", track.Name, err)
+			}
+			objects = append(objects, policy)
+		}
+	}
+
+	return objects, nil
+}
+
+// buildFluxImagePolicy creates an ImagePolicy selecting the latest tag of the repository
+// that matches the tag pattern
+func buildFluxImagePolicy(source *configuration.PackageSource, name string, repository string, tagPattern string, namespace string) (*fluxObject, error) {
+	filterTags, err := fluxTagFilter(source, tagPattern)
+	if err != nil {
+		return nil, err
+	}
+	spec := &fluxImagePolicySpec{
+		ImageRepositoryRef: fluxReference{Name: repository},
+		FilterTags:         filterTags,
+	}
+
+	if source.SortBy == "alphabetical" {
+		spec.Policy.Alphabetical = &fluxAlphabeticalPolicy{Order: "asc"}
+	} else {
+		versionRange := source.VersionConstraint
+		if versionRange == "" {
+			versionRange = ">=0.0.0"
+		}
+		spec.Policy.SemVer = &fluxSemVerPolicy{Range: versionRange}
+	}
+
+	return &fluxObject{
+		APIVersion: fluxImageAPIVersion,
+		Kind:       "ImagePolicy",
+		Metadata:   fluxMetadata{Name: name, Namespace: namespace},
+		Spec:       spec,
+	}, nil
+}
+
+// fluxTagFilter translates the tag pattern and version format of a source into a Flux tag
+// filter. Flux supports a single pattern, and regular expressions cannot be intersected, so
+// a tag pattern cannot be combined with a version extraction.
+func fluxTagFilter(source *configuration.PackageSource, tagPattern string) (*fluxFilterTags, error) {
+	extraction := source.ExtractPattern
+	if template := configuration.ResolveVersionTemplate(source.VersionTemplate, source.VersionPrefix); extraction == "" && template != "" {
+		pattern := regexp.QuoteMeta(template)
+		pattern = strings.Replace(pattern, regexp.QuoteMeta(configuration.VersionPlaceholder), "(?P<version>.+)", 1)
+		extraction = "^" + pattern + "$"
+	}
+
+	if extraction == "" {
+		if tagPattern == "" {
+			return nil, nil
+		}
+		return &fluxFilterTags{Pattern: tagPattern}, nil
+	}
+
+	if tagPattern != "" {
+		return nil, fmt.Errorf("source %s: Flux supports a single tag filter, tagPattern %q cannot be combined with the version extraction %q; fold the pattern into extractPattern", source.Name, tagPattern, extraction)
+	}
+
+	compiled, err := regexp.Compile(extraction)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: invalid extractPattern: %w", source.Name, err)
+	}
+	extract := "$0"
+	switch {
+	case compiled.SubexpIndex("version") != -1:
+		extract = "$version"
+	case compiled.NumSubexp() > 0:
+		extract = "$1"
+	}
+	return &fluxFilterTags{Pattern: extraction, Extract: extract}, nil
+}
+
+var fluxInvalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// fluxResourceName converts a name into a valid Kubernetes resource name
+func fluxResourceName(name string) string {
+	name = fluxInvalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
@@ -0,0 +1,221 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestFluxTagFilter(t *testing.T) {
+	tests := []struct {
+		name            string
+		source          *configuration.PackageSource
+		tagPattern      string
+		expectedPattern string
+		expectedExtract string
+		errorContains   string
+	}{
+		{
+			name:   "no filter",
+			source: &configuration.PackageSource{Name: "app"},
+		},
+		{
+			name:            "tag pattern",
+			source:          &configuration.PackageSource{Name: "app"},
+			tagPattern:      `^\d+\.\d+\.\d+$`,
+			expectedPattern: `^\d+\.\d+\.\d+$`,
+		},
+		{
+			name:            "version prefix",
+			source:          &configuration.PackageSource{Name: "app", VersionPrefix: "v"},
+			expectedPattern: `^v(?P<version>.+)$`,
+			expectedExtract: "$version",
+		},
+		{
+			name:            "version template",
+			source:          &configuration.PackageSource{Name: "app", VersionTemplate: "release-{{version}}.final"},
+			expectedPattern: `^release-(?P<version>.+)\.final$`,
+			expectedExtract: "$version",
+		},
+		{
+			name:            "extract pattern with positional group",
+			source:          &configuration.PackageSource{Name: "app", ExtractPattern: `^app-(\d+\.\d+\.\d+)$`},
+			expectedPattern: `^app-(\d+\.\d+\.\d+)$`,
+			expectedExtract: "$1",
+		},
+		{
+			name:            "extract pattern without group",
+			source:          &configuration.PackageSource{Name: "app", ExtractPattern: `\d+\.\d+\.\d+`},
+			expectedPattern: `\d+\.\d+\.\d+`,
+			expectedExtract: "$0",
+		},
+		{
+			name:          "tag pattern with extraction fails",
+			source:        &configuration.PackageSource{Name: "app", VersionPrefix: "v"},
+			tagPattern:    `-lts$`,
+			errorContains: "cannot be combined with the version extraction",
+		},
+		{
+			name:          "invalid extract pattern",
+			source:        &configuration.PackageSource{Name: "app", ExtractPattern: `(`},
+			errorContains: "invalid extractPattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := fluxTagFilter(tt.source, tt.tagPattern)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedPattern == "" {
+				if filter != nil {
+					t.Errorf("expected no filter, got %+v", filter)
+				}
+				return
+			}
+			if filter == nil || filter.Pattern != tt.expectedPattern || filter.Extract != tt.expectedExtract {
+				t.Errorf("expected pattern %q and extract %q, got %+v", tt.expectedPattern, tt.expectedExtract, filter)
+			}
+		})
+	}
+}
+
+func TestBuildFluxObjects(t *testing.T) {
+	providers := []*configuration.PackageSourceProvider{
+		{Name: "dockerhub", Type: configuration.PackageSourceProviderTypeDocker},
+		{Name: "Private_Registry", Type: configuration.PackageSourceProviderTypeDocker, BaseUrl: "https://registry.example.com", AuthType: configuration.PackageSourceProviderAuthTypeBasic},
+	}
+	options := &ExportFluxOptions{Namespace: "flux-system", Interval: "5m"}
+
+	tests := []struct {
+		name          string
+		sources       []*configuration.PackageSource
+		expected      []string // kind/name of the objects
+		errorContains string
+	}{
+		{
+			name: "image with tracks",
+			sources: []*configuration.PackageSource{{
+				Name:              "Web_App",
+				Provider:          "dockerhub",
+				Type:              configuration.PackageSourceTypeDockerImage,
+				URI:               "library/nginx",
+				VersionConstraint: ">=1.0 <2.0",
+				Tracks:            []*configuration.PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}},
+			}},
+			expected: []string{"ImageRepository/web-app", "ImagePolicy/web-app", "ImagePolicy/web-app-lts"},
+		},
+		{
+			name: "non-image and date sorted sources are skipped",
+			sources: []*configuration.PackageSource{
+				{Name: "chart", Provider: "dockerhub", Type: configuration.PackageSourceTypeHelmRepository},
+				{Name: "nightly", Provider: "dockerhub", Type: configuration.PackageSourceTypeDockerImage, URI: "org/nightly", SortBy: "date"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "unknown provider fails",
+			sources: []*configuration.PackageSource{
+				{Name: "app", Provider: "missing", Type: configuration.PackageSourceTypeDockerImage, URI: "org/app"},
+			},
+			errorContains: "unknown provider missing",
+		},
+		{
+			name: "track pattern with version prefix fails",
+			sources: []*configuration.PackageSource{{
+				Name:          "app",
+				Provider:      "dockerhub",
+				Type:          configuration.PackageSourceTypeDockerImage,
+				URI:           "org/app",
+				VersionPrefix: "v",
+				Tracks:        []*configuration.PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}},
+			}},
+			errorContains: "track lts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configuration.Config{PackageSourceProviders: providers, PackageSources: tt.sources}
+			objects, err := buildFluxObjects(config, options)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			names := make([]string, 0, len(objects))
+			for _, object := range objects {
+				names = append(names, object.Kind+"/"+object.Metadata.Name)
+				if object.Metadata.Namespace != "flux-system" {
+					t.Errorf("%s: expected namespace flux-system, got %s", object.Metadata.Name, object.Metadata.Namespace)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected objects %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	t.Run("policy and credentials", func(t *testing.T) {
+		config := &configuration.Config{
+			PackageSourceProviders: providers,
+			PackageSources: []*configuration.PackageSource{
+				{Name: "app", Provider: "Private_Registry", Type: configuration.PackageSourceTypeDockerImage, URI: "org/app", VersionConstraint: "~1.2"},
+				{Name: "tools", Provider: "dockerhub", Type: configuration.PackageSourceTypeDockerImage, URI: "org/tools", SortBy: "alphabetical"},
+			},
+		}
+		objects, err := buildFluxObjects(config, options)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repository := objects[0].Spec.(*fluxImageRepositorySpec)
+		if !strings.HasPrefix(repository.Image, "registry.example.com/") || repository.Interval != "5m" {
+			t.Errorf("unexpected image repository: %+v", repository)
+		}
+		if repository.SecretRef == nil || repository.SecretRef.Name != "private-registry-registry" {
+			t.Errorf("expected a secret reference for basic auth, got %+v", repository.SecretRef)
+		}
+		if policy := objects[1].Spec.(*fluxImagePolicySpec); policy.Policy.SemVer == nil || policy.Policy.SemVer.Range != "~1.2" {
+			t.Errorf("expected semver range ~1.2, got %+v", policy.Policy)
+		}
+		if repository := objects[2].Spec.(*fluxImageRepositorySpec); repository.SecretRef != nil {
+			t.Errorf("expected no secret reference without auth, got %+v", repository.SecretRef)
+		}
+		if policy := objects[3].Spec.(*fluxImagePolicySpec); policy.Policy.Alphabetical == nil || policy.Policy.SemVer != nil {
+			t.Errorf("expected an alphabetical policy, got %+v", policy.Policy)
+		}
+	})
+
+	t.Run("registry map", func(t *testing.T) {
+		config := &configuration.Config{
+			PackageSourceProviders: providers,
+			PackageSources: []*configuration.PackageSource{
+				{Name: "app", Provider: "Private_Registry", Type: configuration.PackageSourceTypeDockerImage, URI: "org/app"},
+				{Name: "nginx", Provider: "dockerhub", Type: configuration.PackageSourceTypeDockerImage, URI: "library/nginx"},
+			},
+			RegistryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub"},
+		}
+		objects, err := buildFluxObjects(config, options)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repository := objects[0].Spec.(*fluxImageRepositorySpec); repository.Image != "registry.example.com/org/app" || repository.SecretRef == nil {
+			t.Errorf("expected the unmapped registry with credentials, got %+v", repository)
+		}
+		if repository := objects[2].Spec.(*fluxImageRepositorySpec); repository.Image != "mirror.example.com/dockerhub/library/nginx" || repository.SecretRef != nil {
+			t.Errorf("expected the mirror without provider credentials, got %+v", repository)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/rs/zerolog/log"
+)
+
+// emitGitHubAnnotations writes a ::warning workflow command for each outdated target
+// so drift shows up inline in GitHub pull request checks. The runner reads workflow
+// commands from stderr as well, which keeps stdout free for JSON and YAML reports.
+func emitGitHubAnnotations(out io.Writer, results []*compare.ComparisonResult) {
+	for _, result := range results {
+		if !result.NeedsUpdate || result.Error != nil {
+			continue
+		}
+
+		itemName := result.TargetItemName
+		if itemName == "" {
+			itemName = result.TargetName
+		}
+
+		properties := []string{
+			"file=" + escapeWorkflowProperty(annotationPath(result.TargetFile)),
+		}
+		if line := findVersionLine(result.TargetFile, itemName, result.TargetValue()); line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", line))
+		}
+		properties = append(properties, "title="+escapeWorkflowProperty(fmt.Sprintf("%s update available", result.UpdateType)))
+
+		message := fmt.Sprintf("%s can be updated from %s to %s (source: %s)",
+			itemName, result.CurrentVersion, result.LatestVersion, result.SourceName)
+
+		fmt.Fprintf(out, "::warning %s::%s\n", strings.Join(properties, ","), escapeWorkflowData(message))
+	}
+}
+
+// writeGitHubOutputs appends key=value pairs to $GITHUB_OUTPUT for downstream steps
+func writeGitHubOutputs(outputs map[string]string, keys []string) {
+	outputFile := os.Getenv("GITHUB_OUTPUT")
+	if outputFile == "" {
+		log.Debug().Msg("GITHUB_OUTPUT not set, skipping step outputs")
+		return
+	}
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", key, outputs[key]))
+	}
+
+	if err := appendToFile(outputFile, sb.String()); err != nil {
+		log.Warn().Err(err).Str("file", outputFile).Msg("Failed to write GitHub step outputs")
+	}
+}
+
+// countPendingUpdates counts results that need an update
+func countPendingUpdates(results []*compare.ComparisonResult) int {
+	count := 0
+	for _, result := range results {
+		if result.NeedsUpdate && result.Error == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// countCreatedPullRequests counts patch groups for which a new pull request was opened
+func countCreatedPullRequests(groups []*PatchGroup) int {
+	count := 0
+	for _, group := range groups {
+		if group.PullRequestCreated {
+			count++
+		}
+	}
+	return count
+}
+
+// findVersionLine returns the 1-based line number on which the current version of an item
+// appears in the file, or 0 if it cannot be located. The search starts at the first line
+// mentioning the item key so that identical versions of other items are not picked up.
+func findVersionLine(filePath string, itemName string, version string) int {
+	if version == "" {
+		return 0
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0
+	}
+	lines := strings.Split(string(content), "\n")
+
+	// Use the last path segment as key (e.g. "tag" for "image.tag")
+	key := itemName
+	if idx := strings.LastIndex(key, "."); idx != -1 {
+		key = key[idx+1:]
+	}
+
+	start := 0
+	if key != "" {
+		for i, line := range lines {
+			if strings.Contains(line, key) {
+				start = i
+				break
+			}
+		}
+	}
+
+	for i := start; i < len(lines); i++ {
+		if strings.Contains(lines[i], version) {
+			return i + 1
+		}
+	}
+	for i := 0; i < start; i++ {
+		if strings.Contains(lines[i], version) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// annotationPath converts a target file path to a path relative to the workspace root
+func annotationPath(filePath string) string {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" || !filepath.IsAbs(filePath) {
+		return filepath.ToSlash(filePath)
+	}
+	if rel, err := filepath.Rel(workspace, filePath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filePath)
+}
+
+// escapeWorkflowData escapes the message part of a workflow command
+func escapeWorkflowData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeWorkflowProperty escapes a property value of a workflow command
+func escapeWorkflowProperty(s string) string {
+	s = escapeWorkflowData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
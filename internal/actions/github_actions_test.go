@@ -0,0 +1,162 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+func TestEscapeWorkflowData(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain message is unchanged",
+			input:    "nginx can be updated from 1.0.0 to 1.1.0",
+			expected: "nginx can be updated from 1.0.0 to 1.1.0",
+		},
+		{
+			name:     "percent is escaped first",
+			input:    "100% done",
+			expected: "100%25 done",
+		},
+		{
+			name:     "line breaks are escaped",
+			input:    "line1\r\nline2",
+			expected: "line1%0D%0Aline2",
+		},
+		{
+			name:     "colons and commas are kept in data",
+			input:    "a: b, c",
+			expected: "a: b, c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeWorkflowData(tt.input); got != tt.expected {
+				t.Errorf("escapeWorkflowData() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapeWorkflowProperty(t *testing.T) {
+	input := "charts/app: 50%, major\n"
+	expected := "charts/app%3A 50%25%2C major%0A"
+	if got := escapeWorkflowProperty(input); got != expected {
+		t.Errorf("escapeWorkflowProperty() = %q, expected %q", got, expected)
+	}
+}
+
+func TestFindVersionLine(t *testing.T) {
+	content := `apiVersion: v2
+name: app
+version: 1.2.3
+dependencies:
+  - name: redis
+    version: 1.2.3
+  - name: postgres
+    version: 12.1.0
+image:
+  tag: 1.2.3
+`
+	file := filepath.Join(t.TempDir(), "Chart.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		itemName string
+		version  string
+		expected int
+	}{
+		{
+			name:     "search starts at the item key",
+			itemName: "redis",
+			version:  "1.2.3",
+			expected: 6,
+		},
+		{
+			name:     "last path segment is used as key",
+			itemName: "image.tag",
+			version:  "1.2.3",
+			expected: 10,
+		},
+		{
+			name:     "wraps around to lines before the key",
+			itemName: "image.tag",
+			version:  "12.1.0",
+			expected: 8,
+		},
+		{
+			name:     "version not in file",
+			itemName: "redis",
+			version:  "9.9.9",
+			expected: 0,
+		},
+		{
+			name:     "empty version",
+			itemName: "redis",
+			version:  "",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findVersionLine(file, tt.itemName, tt.version); got != tt.expected {
+				t.Errorf("findVersionLine() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+
+	if got := findVersionLine(filepath.Join(t.TempDir(), "missing.yaml"), "redis", "1.2.3"); got != 0 {
+		t.Errorf("findVersionLine() of a missing file = %d, expected 0", got)
+	}
+}
+
+func TestEmitGitHubAnnotations(t *testing.T) {
+	workspace := t.TempDir()
+	t.Setenv("GITHUB_WORKSPACE", workspace)
+	file := filepath.Join(workspace, "infra", "variables.tf")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("variable \"app_version\" {\n  default = \"1.0.0\"\n}\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var out bytes.Buffer
+	emitGitHubAnnotations(&out, []*compare.ComparisonResult{
+		{
+			TargetName:     "infra",
+			TargetFile:     file,
+			TargetItemName: "app_version",
+			SourceName:     "app",
+			CurrentVersion: "1.0.0",
+			LatestVersion:  "1.1.0",
+			UpdateType:     compare.UpdateTypeMinor,
+			NeedsUpdate:    true,
+		},
+		{TargetName: "up-to-date", TargetFile: file},
+		{TargetName: "failed", TargetFile: file, NeedsUpdate: true, Error: errors.New("scrape failed")},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 annotation, got %d:\n%s", len(lines), out.String())
+	}
+	expected := "::warning file=infra/variables.tf,line=2,title=minor update available::app_version can be updated from 1.0.0 to 1.1.0 (source: app)"
+	if lines[0] != expected {
+		t.Errorf("unexpected annotation:\n got: %s\nwant: %s", lines[0], expected)
+	}
+}
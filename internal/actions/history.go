@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/history"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// HistoryOptions represents options for the history command
+type HistoryOptions struct {
+	Database     string // Database URL of the recorded runs
+	Scope        string // Only runs of this scope, e.g. the UpdaterConfig "namespace/name"
+	Timeline     bool   // List the version timeline of the target items instead of the runs
+	Target       string // Only the timeline of items of this target
+	Source       string // Only the timeline of items following this source
+	Limit        int    // Newest runs to list, all if zero
+	OutputFormat string
+}
+
+// History lists the runs or the version timeline recorded in a history database
+func History(options *HistoryOptions) error {
+	if options.Database == "" {
+		return fmt.Errorf("no database given: set --database or UPDATER_DATABASE")
+	}
+	if (options.Target != "" || options.Source != "") && !options.Timeline {
+		return fmt.Errorf("--target and --source require --timeline")
+	}
+
+	store, err := history.Open(options.Database)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if options.Timeline {
+		timeline, err := store.Timeline(history.TimelineFilter{Scope: options.Scope, Target: options.Target, Source: options.Source})
+		if err != nil {
+			return err
+		}
+		if options.OutputFormat == "table" {
+			return outputTimelineTable(timeline)
+		}
+		return outputHistory(timeline, options.OutputFormat)
+	}
+
+	runs, err := store.Runs(history.RunFilter{Scope: options.Scope, Limit: options.Limit})
+	if err != nil {
+		return err
+	}
+	if options.OutputFormat == "table" {
+		return outputRunsTable(runs)
+	}
+	return outputHistory(runs, options.OutputFormat)
+}
+
+func outputHistory(value any, format string) error {
+	switch format {
+	case "json":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "yaml":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(2)
+		return encoder.Encode(value)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+func outputRunsTable(runs []*history.Run) error {
+	if len(runs) == 0 {
+		fmt.Fprintln(util.ResultOutput(), "No runs recorded")
+		return nil
+	}
+
+	t := util.NewTable()
+	t.SetTitle("🕑 Runs")
+	t.AppendHeader(table.Row{"Run", "Scope", "Command", "Started", "Duration", "Pending Updates", "Failed Sources", "Errors"})
+	for _, run := range runs {
+		t.AppendRow(table.Row{
+			run.ID,
+			run.Scope,
+			run.Command,
+			run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			(time.Duration(run.DurationMs) * time.Millisecond).Round(time.Second),
+			run.PendingUpdates,
+			run.FailedSources,
+			run.Errors,
+		})
+	}
+	t.Render()
+	return nil
+}
+
+func outputTimelineTable(timeline []*history.TimelineEntry) error {
+	if len(timeline) == 0 {
+		fmt.Fprintln(util.ResultOutput(), "No versions recorded")
+		return nil
+	}
+
+	t := util.NewTable()
+	t.SetTitle("🕑 Version Timeline")
+	t.AppendHeader(table.Row{"Seen", "Scope", "File / Variable", "Source", "Current", "Latest", "Update Type"})
+	for _, entry := range timeline {
+		firstColumn := entry.Target
+		if entry.Item != "" {
+			firstColumn = fmt.Sprintf("%s\n  → %s", entry.File, entry.Item)
+		}
+		t.AppendRow(table.Row{
+			entry.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			entry.Scope,
+			firstColumn,
+			entry.Source,
+			entry.CurrentVersion,
+			entry.LatestVersion,
+			entry.UpdateType,
+		})
+	}
+	t.Render()
+	return nil
+}
+
+// recordHistory records the run with the versions of its target items in the history database,
+// if one is set. Failures are logged, so a database outage does not fail the run.
+func (s *RunSummary) recordHistory(store *history.Store, scope string) {
+	if store == nil {
+		return
+	}
+	s.DurationMs = time.Since(s.StartedAt).Milliseconds()
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to marshal run summary")
+		return
+	}
+	run := &history.Run{
+		ID:             s.RunID,
+		Scope:          scope,
+		Command:        s.Command,
+		StartedAt:      s.StartedAt,
+		DurationMs:     s.DurationMs,
+		PendingUpdates: s.Updates.Total,
+		FailedSources:  s.Failed,
+		Errors:         len(s.Errors),
+		Summary:        json.RawMessage(util.Redact(string(data))),
+	}
+	for _, result := range s.comparisons {
+		comparison := &history.Comparison{
+			Target:         result.TargetName,
+			File:           result.TargetFile,
+			Item:           result.TargetItemName,
+			Source:         result.SourceName,
+			CurrentVersion: result.CurrentVersion,
+			LatestVersion:  result.LatestVersion,
+			UpdateType:     string(result.UpdateType),
+			NeedsUpdate:    result.NeedsUpdate,
+		}
+		if result.Error != nil {
+			comparison.Error = util.Redact(result.Error.Error())
+		}
+		run.Comparisons = append(run.Comparisons, comparison)
+	}
+
+	if err := store.RecordRun(run); err != nil {
+		log.Warn().Err(err).Str("runId", s.RunID).Msg("Failed to record run in history database")
+	}
+}
@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type LintOptions struct {
+	ConfigPath     string
+	OutputFormat   string
+	UpdaterVersion string
+	HealthFile     string // Source health state file written by compare, per configuration in the user cache directory if empty
+	StaleAfter     string // Report sources without a new version for this long (e.g. "180d")
+}
+
+// Lint checks the configuration against opinionated rules beyond structural validation.
+// It fails if any finding has error severity.
+func Lint(options *LintOptions) error {
+	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
+
+	// Lint the configuration as written, substitution would hide plain-text credentials
+	config, err := configuration.LoadRawConfiguration(options.ConfigPath)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load configuration")
+		return fmt.Errorf("configuration load error: %w", err)
+	}
+
+	result := configuration.LintConfiguration(config)
+
+	// Report stale and failing sources recorded by previous compare runs
+	healthFile, staleAfter, err := resolveHealthOptions(options.HealthFile, options.StaleAfter, options.ConfigPath)
+	if err != nil {
+		return err
+	}
+	healthState, err := configuration.LoadHealthState(healthFile)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load source health state")
+	} else {
+		configuration.LintSourceHealth(result, config, healthState, staleAfter, time.Now())
+	}
+
+	if err := outputLintResult(result, options); err != nil {
+		log.Error().Err(err).Msg("Failed to output lint results")
+		return fmt.Errorf("output error: %w", err)
+	}
+
+	if result.HasErrors() {
+		return fmt.Errorf("configuration lint failed")
+	}
+	return nil
+}
+
+func outputLintResult(result *configuration.LintResult, options *LintOptions) error {
+	switch options.OutputFormat {
+	case "table":
+		return outputLintTable(result)
+	case "json":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"findingCount": len(result.Findings),
+			"findings":     result.Findings,
+		})
+	case "yaml":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(2)
+		return encoder.Encode(map[string]interface{}{
+			"findingCount": len(result.Findings),
+			"findings":     result.Findings,
+		})
+	case "sarif":
+		return outputLintSARIF(result, options.UpdaterVersion)
+	default:
+		return fmt.Errorf("unsupported output format: %s", options.OutputFormat)
+	}
+}
+
+func outputLintTable(result *configuration.LintResult) error {
+	if len(result.Findings) == 0 {
+		fmt.Fprintln(util.ResultOutput(), "✓ No lint findings")
+		return nil
+	}
+
+	writer := util.NewRedactingWriter(util.ResultOutput())
+	defer writer.Close()
+	t := util.NewTable()
+	t.SetOutputMirror(writer)
+	t.AppendHeader(table.Row{"Severity", "Rule", "Field", "Message"})
+	for _, finding := range result.Findings {
+		t.AppendRow(table.Row{finding.Severity, finding.RuleID, finding.Field, finding.Message})
+	}
+	t.Render()
+
+	fmt.Fprintf(util.StatusOutput(), "\nTotal findings: %d\n", len(result.Findings))
+	return nil
+}
+
+func outputLintSARIF(result *configuration.LintResult, version string) error {
+	rules := make([]interface{}, len(configuration.LintRules))
+	for i, rule := range configuration.LintRules {
+		rules[i] = map[string]interface{}{
+			"id": rule.ID,
+			"shortDescription": map[string]interface{}{
+				"text": rule.Description,
+			},
+			"defaultConfiguration": map[string]interface{}{
+				"level": string(rule.Severity),
+			},
+		}
+	}
+
+	results := make([]interface{}, len(result.Findings))
+	for i, finding := range result.Findings {
+		results[i] = map[string]interface{}{
+			"ruleId": finding.RuleID,
+			"level":  string(finding.Severity),
+			"message": map[string]interface{}{
+				"text": finding.Message,
+			},
+			"locations": []interface{}{
+				map[string]interface{}{
+					"logicalLocations": []interface{}{
+						map[string]interface{}{
+							"fullyQualifiedName": finding.Field,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	sarif := map[string]interface{}{
+		"version": "2.1.0",
+		"$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "updater-lint",
+						"informationUri": "https://github.com/mxcd/updater",
+						"version":        version,
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarif)
+}
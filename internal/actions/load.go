@@ -8,21 +8,51 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
 type LoadOptions struct {
 	ConfigPath   string
+	Recursive    bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment  string // Environment whose overrides are applied
 	OutputFormat string
 	Limit        int
+	SummaryFile  string
+	Watch        string // Refresh interval of the live dashboard (e.g. 5m), empty to load once
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+}
+
+// loadConfiguration loads the configuration at configPath, or with recursive discovery all
+// .updaterconfig.yml files under it, with the overrides of the environment applied
+func loadConfiguration(configPath string, recursive bool, environment string) (*configuration.Config, error) {
+	if recursive {
+		return configuration.LoadRecursiveConfiguration(configPath, environment)
+	}
+	return configuration.LoadEnvironmentConfiguration(configPath, environment)
 }
 
-func Load(options *LoadOptions) error {
+func Load(options *LoadOptions) (err error) {
+	if options.Watch != "" {
+		interval, err := util.ParseDuration(options.Watch)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid --watch interval: %s", options.Watch)
+		}
+		return watchLoad(options, interval)
+	}
+
 	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
 
+	summary := newRunSummary("load")
+	defer func() {
+		summary.addError(err)
+		summary.write(options.SummaryFile)
+	}()
+
 	// Load configuration
-	config, err := configuration.LoadConfiguration(options.ConfigPath)
+	config, err := loadConfiguration(options.ConfigPath, options.Recursive, options.Environment)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to load configuration")
 		return fmt.Errorf("configuration load error: %w", err)
@@ -43,7 +73,7 @@ func Load(options *LoadOptions) error {
 	log.Debug().Msg("Configuration is valid")
 
 	// Create orchestrator
-	orchestrator, err := scraper.NewOrchestrator(config)
+	orchestrator, err := scraper.NewOrchestrator(config, options.Scraper)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create scraper orchestrator")
 		return fmt.Errorf("orchestrator creation error: %w", err)
@@ -57,6 +87,7 @@ func Load(options *LoadOptions) error {
 	}
 
 	scrapeResult := orchestrator.ScrapeAllSources(scrapeOptions)
+	summary.addScrapeResult(scrapeResult)
 
 	// Output results (including partial results from successful sources)
 	if err := outputLoadResults(orchestrator.GetConfig(), options.OutputFormat); err != nil {
@@ -66,11 +97,11 @@ func Load(options *LoadOptions) error {
 
 	// Show scraping errors at the end
 	if scrapeResult.HasErrors() {
-		fmt.Printf("\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
+		fmt.Fprintf(util.StatusOutput(), "\n⚠️  %d of %d source(s) failed to scrape:\n", scrapeResult.Failed, scrapeResult.Succeeded+scrapeResult.Failed)
 		for _, scrapeErr := range scrapeResult.Errors {
-			fmt.Printf("  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
+			fmt.Fprintf(util.StatusOutput(), "  ❌ %s (provider: %s): %v\n", scrapeErr.SourceName, scrapeErr.Provider, scrapeErr.Err)
 		}
-		fmt.Println()
+		fmt.Fprintln(util.StatusOutput())
 		return fmt.Errorf("%d source(s) failed to scrape", scrapeResult.Failed)
 	}
 
@@ -92,8 +123,7 @@ func outputLoadResults(config *configuration.Config, format string) error {
 }
 
 func outputLoadResultsTable(config *configuration.Config) error {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
+	t := util.NewTable()
 	t.SetTitle("📦 Package Sources")
 	t.AppendHeader(table.Row{"Name", "Provider", "Type", "Version", "Semantic Version", "Version Info"})
 
@@ -143,27 +173,32 @@ func outputLoadResultsTable(config *configuration.Config) error {
 		t.AppendSeparator()
 	}
 
-	t.SetStyle(table.StyleRounded)
 	t.Render()
-	fmt.Println()
+	fmt.Fprintln(util.ResultOutput())
 
 	return nil
 }
 
 func outputLoadResultsJSON(config *configuration.Config) error {
 	output := map[string]interface{}{
-		"packageSources": config.PackageSources,
+		"packageSourceProviders": configuration.RedactedProviders(config),
+		"packageSources":         config.PackageSources,
 	}
-	encoder := json.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
 
 func outputLoadResultsYAML(config *configuration.Config) error {
 	output := map[string]interface{}{
-		"packageSources": config.PackageSources,
+		"packageSourceProviders": configuration.RedactedProviders(config),
+		"packageSources":         config.PackageSources,
 	}
-	encoder := yaml.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := yaml.NewEncoder(writer)
 	encoder.SetIndent(2)
 	return encoder.Encode(output)
 }
@@ -0,0 +1,278 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/history"
+	"github.com/mxcd/updater/internal/operator"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// OperatorOptions represents options for the operator command
+type OperatorOptions struct {
+	Context        string // kubeconfig context, the current context if empty
+	Namespace      string // Watch UpdaterConfigs of this namespace, all namespaces if empty
+	Interval       string // Resync interval of schedules and of UpdaterConfigs without schedule (e.g. "5m")
+	WorkDir        string // Directory the repositories of UpdaterConfigs are cloned into
+	UpdaterVersion string // Version recorded in attestations
+	PprofAddress   string // Serve the net/http/pprof endpoints on this address, disabled if empty
+	StatsInterval  string // Log memory and goroutine statistics this often (e.g. "10m"), disabled if empty
+	Database       string // Record every run in this history database, disabled if empty
+	AllowHooks     bool   // Allow postUpdate hooks and includes in the inline configurations of UpdaterConfigs
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+
+	history *history.Store // History database opened from Database
+}
+
+// runUpdaterConfigFunc runs an UpdaterConfig and returns the outcome
+type runUpdaterConfigFunc func(config *operator.UpdaterConfig) *operator.RunResult
+
+// Operator runs the controller of UpdaterConfig custom resources until interrupted. It
+// reconciles on every change of an UpdaterConfig and every interval, runs compare or apply
+// for each UpdaterConfig that is due, and writes the outcome to its status.
+func Operator(options *OperatorOptions) error {
+	interval, err := time.ParseDuration(options.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid --interval %q: must be a positive duration like 5m", options.Interval)
+	}
+	var statsInterval time.Duration
+	if options.StatsInterval != "" {
+		if statsInterval, err = time.ParseDuration(options.StatsInterval); err != nil || statsInterval <= 0 {
+			return fmt.Errorf("invalid --stats-interval %q: must be a positive duration like 10m", options.StatsInterval)
+		}
+	}
+	workDir, err := filepath.Abs(options.WorkDir)
+	if err != nil {
+		return fmt.Errorf("invalid work directory: %w", err)
+	}
+	options.WorkDir = workDir
+	if options.Database != "" {
+		if options.history, err = history.Open(options.Database); err != nil {
+			return err
+		}
+		defer options.history.Close()
+	}
+	// Results are reported through the status of the UpdaterConfigs, only logs are written
+	util.SetQuiet(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if options.PprofAddress != "" {
+		if _, err := startPprofServer(ctx, options.PprofAddress); err != nil {
+			return err
+		}
+	}
+	if statsInterval > 0 {
+		go logRuntimeStats(ctx, statsInterval)
+	}
+
+	client := &operator.KubectlClient{Context: options.Context, Namespace: options.Namespace}
+	changed := make(chan struct{}, 1)
+	go watchUpdaterConfigs(ctx, client, changed, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Str("namespace", options.Namespace).Dur("interval", interval).Msg("Operator started")
+	run := func(config *operator.UpdaterConfig) *operator.RunResult {
+		return runUpdaterConfig(client, options, config)
+	}
+	for {
+		reconcileUpdaterConfigs(client, run, interval, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-changed:
+		}
+	}
+}
+
+// watchUpdaterConfigs keeps a kubectl watch of the UpdaterConfigs running, restarting it
+// after the interval when it ends
+func watchUpdaterConfigs(ctx context.Context, client *operator.KubectlClient, changed chan<- struct{}, interval time.Duration) {
+	for {
+		if err := client.Watch(ctx, changed); err != nil {
+			log.Warn().Err(err).Msg("Watch of UpdaterConfigs ended")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// reconcileUpdaterConfigs runs every due UpdaterConfig and updates its status. UpdaterConfigs
+// run one at a time.
+func reconcileUpdaterConfigs(client operator.Client, run runUpdaterConfigFunc, interval time.Duration, now time.Time) {
+	configs, err := client.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list UpdaterConfigs")
+		return
+	}
+
+	for _, config := range configs {
+		previous := config.Status
+		due, err := config.Due(now, interval)
+		switch {
+		case err != nil:
+			config.RecordError("InvalidSpec", err, now)
+		case !due:
+			continue
+		default:
+			log.Info().Str("updaterConfig", config.Key()).Bool("apply", config.Spec.Apply).Msg("Running UpdaterConfig")
+			result := run(config)
+			if result.Err != nil {
+				log.Error().Err(result.Err).Str("updaterConfig", config.Key()).Msg("UpdaterConfig run failed")
+			}
+			config.RecordRun(result, time.Now())
+		}
+
+		// Unchanged status is not written, so status updates do not trigger reconciles forever
+		if reflect.DeepEqual(previous, config.Status) {
+			continue
+		}
+		if err := client.UpdateStatus(config); err != nil {
+			log.Error().Err(err).Str("updaterConfig", config.Key()).Msg("Failed to update UpdaterConfig status")
+		}
+	}
+}
+
+// runUpdaterConfig synchronizes the clone of the repository of an UpdaterConfig and runs
+// compare or apply on it through the same code paths as the CLI
+func runUpdaterConfig(client operator.Client, options *OperatorOptions, config *operator.UpdaterConfig) *operator.RunResult {
+	dir := filepath.Join(options.WorkDir, config.Metadata.Namespace, config.Metadata.Name)
+
+	token := ""
+	if ref := config.Spec.Repository.CredentialsSecretRef; ref != nil {
+		var err error
+		if token, err = client.ReadSecret(config.Metadata.Namespace, ref); err != nil {
+			return &operator.RunResult{Err: fmt.Errorf("failed to read repository credentials: %w", err)}
+		}
+		util.RegisterSecret(token)
+	}
+	if err := git.SyncClone(config.Spec.Repository.URL, config.Spec.Repository.Branch, dir, token); err != nil {
+		return &operator.RunResult{Err: fmt.Errorf("failed to synchronize repository: %w", err)}
+	}
+
+	runConfig, configPath, err := loadUpdaterConfigConfiguration(options, config, dir)
+	if err != nil {
+		return &operator.RunResult{Err: err}
+	}
+
+	result := &operator.RunResult{}
+	onFinish := func(summary *RunSummary) {
+		result.RunID = summary.RunID
+		result.PendingUpdates = summary.Updates.Total
+		result.Errors = summary.Errors
+		for _, pullRequest := range summary.PullRequests {
+			result.PullRequests = append(result.PullRequests, operator.PullRequestStatus{PatchGroup: pullRequest.PatchGroup, URL: pullRequest.URL})
+		}
+	}
+	if config.Spec.Apply {
+		result.Err = Apply(&ApplyOptions{
+			ConfigPath:     configPath,
+			Environment:    config.Spec.Environment,
+			OutputFormat:   "table",
+			Only:           "all",
+			UpdaterVersion: options.UpdaterVersion,
+			OnConflict:     "warn",
+			Parallel:       1,
+			History:        options.history,
+			HistoryScope:   config.Key(),
+			Scraper:        options.Scraper,
+			onFinish:       onFinish,
+			config:         runConfig,
+		})
+	} else {
+		_, result.Err = Compare(&CompareOptions{
+			ConfigPath:   configPath,
+			Environment:  config.Spec.Environment,
+			OutputFormat: "table",
+			Only:         "all",
+			History:      options.history,
+			HistoryScope: config.Key(),
+			Scraper:      options.Scraper,
+			onFinish:     onFinish,
+			config:       runConfig,
+		})
+	}
+	return result
+}
+
+// loadUpdaterConfigConfiguration loads the configuration of an UpdaterConfig, inline or from
+// the clone of its repository in dir, with its file paths resolved against dir. It returns the
+// path the configuration is known by, e.g. to key its source health state.
+func loadUpdaterConfigConfiguration(options *OperatorOptions, config *operator.UpdaterConfig, dir string) (*configuration.Config, string, error) {
+	if config.Spec.Config == nil {
+		configPath := config.Spec.ConfigPath
+		if configPath == "" {
+			configPath = ".updater"
+		}
+		configPath = filepath.Join(dir, configPath)
+		raw, err := configuration.LoadRawConfiguration(configPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("configuration load error: %w", err)
+		}
+		rooted, err := configuration.RootConfiguration(raw, dir, config.Spec.Environment)
+		if err != nil {
+			return nil, "", fmt.Errorf("configuration load error: %w", err)
+		}
+		return rooted, configPath, nil
+	}
+
+	data, err := yaml.Marshal(config.Spec.Config)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal inline configuration: %w", err)
+	}
+	inline := &configuration.Config{}
+	if err := yaml.Unmarshal(data, inline); err != nil {
+		return nil, "", fmt.Errorf("failed to parse inline configuration: %w", err)
+	}
+	if len(inline.Include) > 0 && !options.AllowHooks {
+		return nil, "", fmt.Errorf("inline configuration must not include other configurations unless the operator runs with --allow-inline-hooks")
+	}
+	rooted, err := configuration.RootConfiguration(inline, dir, config.Spec.Environment)
+	if err != nil {
+		return nil, "", fmt.Errorf("configuration load error: %w", err)
+	}
+	if !options.AllowHooks {
+		if err := refuseHooks(rooted); err != nil {
+			return nil, "", err
+		}
+	}
+	// The inline configuration has no file, its source health is kept next to the clone
+	return rooted, dir + ".updater.yml", nil
+}
+
+// refuseHooks fails for a configuration defining postUpdate hooks. Anyone allowed to create
+// UpdaterConfigs could otherwise run commands in the operator with its credentials.
+func refuseHooks(config *configuration.Config) error {
+	for _, target := range config.Targets {
+		if len(target.PostUpdate) > 0 {
+			return fmt.Errorf("target %s: inline configuration must not define postUpdate hooks unless the operator runs with --allow-inline-hooks", target.Name)
+		}
+	}
+	for name, patchGroup := range config.PatchGroups {
+		if patchGroup != nil && len(patchGroup.PostUpdate) > 0 {
+			return fmt.Errorf("patch group %s: inline configuration must not define postUpdate hooks unless the operator runs with --allow-inline-hooks", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/operator"
+)
+
+// fakeOperatorClient serves UpdaterConfigs from memory and records status updates
+type fakeOperatorClient struct {
+	configs []*operator.UpdaterConfig
+	updated []string
+}
+
+func (c *fakeOperatorClient) List() ([]*operator.UpdaterConfig, error) {
+	return c.configs, nil
+}
+
+func (c *fakeOperatorClient) UpdateStatus(config *operator.UpdaterConfig) error {
+	c.updated = append(c.updated, config.Key())
+	return nil
+}
+
+func (c *fakeOperatorClient) ReadSecret(namespace string, ref *operator.SecretReference) (string, error) {
+	return "", nil
+}
+
+func TestReconcileUpdaterConfigs(t *testing.T) {
+	now := time.Date(2026, 10, 16, 6, 30, 0, 0, time.UTC)
+	lastRun := now.Add(-time.Minute)
+	client := &fakeOperatorClient{configs: []*operator.UpdaterConfig{
+		{Metadata: operator.ObjectMeta{Name: "new", Namespace: "team", Generation: 1}},
+		{
+			Metadata: operator.ObjectMeta{Name: "current", Namespace: "team", Generation: 1},
+			Status:   operator.UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: &lastRun},
+		},
+		{
+			Metadata: operator.ObjectMeta{Name: "invalid", Namespace: "team", Generation: 1},
+			Spec:     operator.UpdaterConfigSpec{Schedule: "sometimes"},
+		},
+	}}
+
+	var runs []string
+	run := func(config *operator.UpdaterConfig) *operator.RunResult {
+		runs = append(runs, config.Key())
+		return &operator.RunResult{RunID: "run", PendingUpdates: 1}
+	}
+
+	reconcileUpdaterConfigs(client, run, 5*time.Minute, now)
+	if len(runs) != 1 || runs[0] != "team/new" {
+		t.Errorf("expected only team/new to run, got %v", runs)
+	}
+	if len(client.updated) != 2 || client.updated[0] != "team/new" || client.updated[1] != "team/invalid" {
+		t.Errorf("expected status updates of team/new and team/invalid, got %v", client.updated)
+	}
+	if condition := client.configs[0].Condition(operator.ConditionUpdatesAvailable); condition == nil || condition.Status != "True" {
+		t.Errorf("expected UpdatesAvailable of team/new, got %+v", condition)
+	}
+	if condition := client.configs[2].Condition(operator.ConditionReady); condition == nil || condition.Reason != "InvalidSpec" {
+		t.Errorf("expected invalid schedule to be reported, got %+v", condition)
+	}
+
+	// Reconciling again neither runs nor rewrites unchanged status
+	runs, client.updated = nil, nil
+	reconcileUpdaterConfigs(client, run, 5*time.Minute, now)
+	if len(runs) != 0 || len(client.updated) != 0 {
+		t.Errorf("expected no runs and status updates, got %v and %v", runs, client.updated)
+	}
+}
+
+func TestLoadUpdaterConfigConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".updater.yml"), []byte("targets:\n  - name: app\n    type: terraform-variable\n    file: main.tf\n"), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+	inline := func(target map[string]any) *operator.UpdaterConfig {
+		return &operator.UpdaterConfig{Spec: operator.UpdaterConfigSpec{Config: map[string]any{"targets": []any{target}}}}
+	}
+	target := map[string]any{"name": "app", "type": "terraform-variable", "file": "main.tf"}
+	hooked := map[string]any{"name": "app", "type": "terraform-variable", "file": "main.tf", "postUpdate": []any{"make generate"}}
+
+	tests := []struct {
+		name          string
+		config        *operator.UpdaterConfig
+		allowHooks    bool
+		errorContains string
+	}{
+		{name: "repository configuration", config: &operator.UpdaterConfig{Spec: operator.UpdaterConfigSpec{ConfigPath: ".updater.yml"}}},
+		{name: "inline configuration", config: inline(target)},
+		{name: "inline hooks are refused", config: inline(hooked), errorContains: "must not define postUpdate hooks"},
+		{name: "inline hooks allowed by the operator", config: inline(hooked), allowHooks: true},
+		{
+			name:          "inline includes are refused",
+			config:        &operator.UpdaterConfig{Spec: operator.UpdaterConfigSpec{Config: map[string]any{"include": []any{"https://example.com/hooks.yml"}}}},
+			errorContains: "must not include other configurations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, _, err := loadUpdaterConfigConfiguration(&OperatorOptions{AllowHooks: tt.allowHooks}, tt.config, dir)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(config.Targets) != 1 || config.Targets[0].File != filepath.Join(dir, "main.tf") {
+				t.Errorf("expected the target file to be resolved against the clone, got %+v", config.Targets)
+			}
+		})
+	}
+}
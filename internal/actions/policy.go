@@ -0,0 +1,159 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// policyQuery is the Rego rule evaluated for every proposed update
+const policyQuery = "data.updater.decision"
+
+// needsApprovalLabel is added to pull requests containing updates the policy wants approved
+const needsApprovalLabel = "needs-approval"
+
+// Policy decisions
+const (
+	policyDecisionAllow         = "allow"
+	policyDecisionDeny          = "deny"
+	policyDecisionNeedsApproval = "needs-approval"
+)
+
+// PolicyInput is the input document of the policy for a proposed update
+type PolicyInput struct {
+	Target         string   `json:"target"`
+	File           string   `json:"file"`
+	Item           string   `json:"item"`
+	Source         string   `json:"source"`
+	CurrentVersion string   `json:"currentVersion"`
+	LatestVersion  string   `json:"latestVersion"`
+	UpdateType     string   `json:"updateType"`
+	PatchGroup     string   `json:"patchGroup"`
+	Labels         []string `json:"labels"`
+	CVEs           []string `json:"cves"` // CVE IDs in the release notes of the new and skipped versions, not a scan
+}
+
+// PolicyDecision is the result of the policy for a proposed update. The policy may return
+// the decision as a string or as an object with decision and reason.
+type PolicyDecision struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// applyPolicy evaluates the Rego policy for every update. Denied updates are dropped together
+// with the other updates of their version set, updates needing approval are labeled and
+// explained in the pull request. Failing to evaluate the policy fails the run, so an
+// unavailable policy never lets updates through.
+func applyPolicy(policyPath string, items []*UpdateItem, audit *auditLog) ([]*UpdateItem, error) {
+	if policyPath == "" {
+		return items, nil
+	}
+
+	allowed := make([]*UpdateItem, 0, len(items))
+	for _, item := range items {
+		decision, err := evaluatePolicy(policyPath, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate policy for %s in %s: %w", item.ItemName, item.TargetFile, err)
+		}
+
+		log.Debug().
+			Str("item", item.ItemName).
+			Str("decision", decision.Decision).
+			Str("reason", decision.Reason).
+			Msg("Evaluated policy")
+		audit.record(&AuditEvent{
+			Event:      auditEventPolicyDecision,
+			Target:     item.TargetName,
+			File:       item.TargetFile,
+			Item:       item.ItemName,
+			Source:     item.SourceName,
+			From:       item.CurrentVersion,
+			To:         item.LatestVersion,
+			Decision:   decision.Decision,
+			Reason:     decision.Reason,
+			PatchGroup: item.PatchGroup,
+		})
+
+		switch decision.Decision {
+		case policyDecisionAllow:
+			allowed = append(allowed, item)
+		case policyDecisionNeedsApproval:
+			item.Labels = mergeLabels(item.Labels, []string{needsApprovalLabel})
+			item.ApprovalReason = decision.Reason
+			if item.ApprovalReason == "" {
+				item.ApprovalReason = "required by policy"
+			}
+			allowed = append(allowed, item)
+		case policyDecisionDeny:
+			fmt.Fprintf(util.StatusOutput(), "🚫 Policy denied %s in %s: %s → %s (%s)\n",
+				item.ItemName, item.TargetFile, item.CurrentVersion, item.LatestVersion, decision.Reason)
+		default:
+			return nil, fmt.Errorf("policy returned unknown decision %q for %s (expected allow, deny or needs-approval)", decision.Decision, item.ItemName)
+		}
+	}
+
+	return keepWholeVersionSets(items, allowed), nil
+}
+
+// evaluatePolicy evaluates the policy for an update with opa eval. A policy without a
+// decision for the update denies it.
+func evaluatePolicy(policyPath string, item *UpdateItem) (*PolicyDecision, error) {
+	input, err := json.Marshal(&PolicyInput{
+		Target:         item.TargetName,
+		File:           item.TargetFile,
+		Item:           item.ItemName,
+		Source:         item.SourceName,
+		CurrentVersion: item.CurrentVersion,
+		LatestVersion:  item.LatestVersion,
+		UpdateType:     string(item.UpdateType),
+		PatchGroup:     item.PatchGroup,
+		Labels:         item.Labels,
+		CVEs:           item.CVEs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	cmd := exec.Command("opa", "eval", "--format", "json", "--data", policyPath, "--stdin-input", policyQuery)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("opa eval failed: %s", strings.TrimSpace(stderr.String()+string(output)))
+	}
+
+	return parsePolicyResult(output)
+}
+
+// parsePolicyResult reads the decision from the JSON output of opa eval
+func parsePolicyResult(output []byte) (*PolicyDecision, error) {
+	var result struct {
+		Result []struct {
+			Expressions []struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse opa output: %w", err)
+	}
+	if len(result.Result) == 0 || len(result.Result[0].Expressions) == 0 {
+		return &PolicyDecision{Decision: policyDecisionDeny, Reason: fmt.Sprintf("%s is undefined", policyQuery)}, nil
+	}
+
+	value := result.Result[0].Expressions[0].Value
+	decision := &PolicyDecision{}
+	if err := json.Unmarshal(value, &decision.Decision); err == nil {
+		return decision, nil
+	}
+	if err := json.Unmarshal(value, decision); err != nil {
+		return nil, fmt.Errorf("policy decision must be a string or an object with decision and reason, got %s", string(value))
+	}
+	return decision, nil
+}
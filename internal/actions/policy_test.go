@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePolicyResult(t *testing.T) {
+	tests := []struct {
+		name             string
+		output           string
+		expectedDecision string
+		expectedReason   string
+		errorContains    string
+	}{
+		{
+			name:             "allow as string",
+			output:           `{"result": [{"expressions": [{"value": "allow", "text": "data.updater.decision"}]}]}`,
+			expectedDecision: policyDecisionAllow,
+		},
+		{
+			name:             "deny with reason",
+			output:           `{"result": [{"expressions": [{"value": {"decision": "deny", "reason": "major updates are frozen"}}]}]}`,
+			expectedDecision: policyDecisionDeny,
+			expectedReason:   "major updates are frozen",
+		},
+		{
+			name:             "needs approval with reason",
+			output:           `{"result": [{"expressions": [{"value": {"decision": "needs-approval", "reason": "production"}}]}]}`,
+			expectedDecision: policyDecisionNeedsApproval,
+			expectedReason:   "production",
+		},
+		{
+			name:             "undefined decision denies",
+			output:           `{}`,
+			expectedDecision: policyDecisionDeny,
+			expectedReason:   "data.updater.decision is undefined",
+		},
+		{
+			name:             "result without expressions denies",
+			output:           `{"result": [{"expressions": []}]}`,
+			expectedDecision: policyDecisionDeny,
+			expectedReason:   "data.updater.decision is undefined",
+		},
+		{
+			name:          "malformed output",
+			output:        `opa: command not found`,
+			errorContains: "failed to parse opa output",
+		},
+		{
+			name:          "decision of the wrong type",
+			output:        `{"result": [{"expressions": [{"value": true}]}]}`,
+			errorContains: "policy decision must be a string or an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := parsePolicyResult([]byte(tt.output))
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decision.Decision != tt.expectedDecision || decision.Reason != tt.expectedReason {
+				t.Errorf("expected %s (%q), got %s (%q)", tt.expectedDecision, tt.expectedReason, decision.Decision, decision.Reason)
+			}
+		})
+	}
+}
+
+func TestApplyPolicy_WithoutPolicy(t *testing.T) {
+	items := []*UpdateItem{{ItemName: "app"}, {ItemName: "db"}}
+	allowed, err := applyPolicy("", items, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(allowed) != len(items) {
+		t.Errorf("expected all %d updates without a policy, got %d", len(items), len(allowed))
+	}
+}
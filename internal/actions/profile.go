@@ -0,0 +1,108 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/util"
+)
+
+// Phases of a run timed with --profile
+const (
+	phaseParse   = "parse"   // Loading, parsing and validating the configuration
+	phaseScrape  = "scrape"  // Scraping the package sources
+	phaseCompare = "compare" // Reading the current versions of the targets and comparing them
+	phaseApply   = "apply"   // Updating files, git operations and pull requests
+)
+
+// profileSourceCount is the number of slowest sources listed in the profile report
+const profileSourceCount = 10
+
+// RunProfile records the time spent per phase of a run, enabled with --profile. Scrape
+// durations per source are part of the sources of the summary.
+type RunProfile struct {
+	Phases      []*PhaseTiming `json:"phases"`
+	PatchGroups []*PhaseTiming `json:"patchGroups,omitempty"` // git and pull request time per patch group
+}
+
+// PhaseTiming is the duration of a phase or of a patch group
+type PhaseTiming struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// enableProfile starts recording phase timings
+func (s *RunSummary) enableProfile() {
+	s.Profile = &RunProfile{Phases: make([]*PhaseTiming, 0)}
+}
+
+// startPhase starts timing a phase and returns the function ending it. Without a profile,
+// nothing is recorded.
+func (s *RunSummary) startPhase(name string) func() {
+	if s.Profile == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		s.Profile.Phases = append(s.Profile.Phases, &PhaseTiming{Name: name, DurationMs: time.Since(start).Milliseconds()})
+	}
+}
+
+// printProfile reports the phase timings, the slowest sources and the time per patch group
+func (s *RunSummary) printProfile() {
+	if s.Profile == nil {
+		return
+	}
+
+	fmt.Fprintln(util.StatusOutput(), "\n⏱️  Profile")
+	t := util.NewTable()
+	t.SetOutputMirror(util.StatusOutput())
+	t.AppendHeader(table.Row{"Phase", "Duration"})
+	for _, phase := range s.Profile.Phases {
+		t.AppendRow(table.Row{phase.Name, formatProfileDuration(phase.DurationMs)})
+	}
+	t.AppendFooter(table.Row{"total", formatProfileDuration(time.Since(s.StartedAt).Milliseconds())})
+	t.Render()
+
+	if sources := slowestSources(s.Sources, profileSourceCount); len(sources) > 0 {
+		t := util.NewTable()
+		t.SetOutputMirror(util.StatusOutput())
+		t.AppendHeader(table.Row{"Source", "Provider", "Scrape Duration", "Versions"})
+		for _, source := range sources {
+			t.AppendRow(table.Row{source.Name, source.Provider, formatProfileDuration(source.DurationMs), source.Versions})
+		}
+		t.Render()
+	}
+
+	if len(s.Profile.PatchGroups) > 0 {
+		t := util.NewTable()
+		t.SetOutputMirror(util.StatusOutput())
+		t.AppendHeader(table.Row{"Patch Group", "Duration"})
+		for _, group := range s.Profile.PatchGroups {
+			t.AppendRow(table.Row{group.Name, formatProfileDuration(group.DurationMs)})
+		}
+		t.Render()
+	}
+}
+
+// slowestSources returns up to n sources with the longest scrape duration, slowest first
+func slowestSources(sources []*SourceSummary, n int) []*SourceSummary {
+	sorted := append([]*SourceSummary{}, sources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DurationMs > sorted[j].DurationMs
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// formatProfileDuration formats milliseconds, e.g. "850ms" or "12.4s"
+func formatProfileDuration(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
@@ -0,0 +1,142 @@
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// promotion tells whether the version a target of the previous stage runs may be promoted
+type promotion struct {
+	ready  bool
+	reason string // Why the version is not promoted yet
+}
+
+// applyRollout caps the updates of targets in later rollout stages at the versions the
+// targets of the previous stage run with the same source, once these soaked for the soak
+// time of the stage or their pull request carries its approval label. Targets of the first
+// stage and targets without a stage are offered the latest version as usual.
+func applyRollout(config *configuration.Config, results []*compare.ComparisonResult, now time.Time) {
+	if config.Rollout == nil {
+		return
+	}
+
+	promotions := make(map[*compare.ComparisonResult]*promotion)
+	for i := 1; i < len(config.Rollout.Stages); i++ {
+		stage := config.Rollout.Stages[i]
+		previous := config.Rollout.Stages[i-1]
+
+		for _, result := range results {
+			if result.Stage != stage.Name || !result.NeedsUpdate {
+				continue
+			}
+
+			priors := make([]*compare.ComparisonResult, 0)
+			for _, prior := range results {
+				if prior.Stage == previous.Name && prior.SourceName == result.SourceName && prior.Error == nil {
+					priors = append(priors, prior)
+				}
+			}
+			if len(priors) == 0 {
+				log.Debug().
+					Str("target", result.TargetName).
+					Str("source", result.SourceName).
+					Str("stage", previous.Name).
+					Msg("No target of the previous rollout stage uses the source, not restricting update")
+				continue
+			}
+
+			for _, prior := range priors {
+				p, ok := promotions[prior]
+				if !ok {
+					p = checkPromotion(config.TargetActor, stage, prior, now)
+					promotions[prior] = p
+				}
+
+				if p.ready {
+					result.LimitTo(prior.BareCurrentVersion(), fmt.Sprintf("stage %s runs %s", previous.Name, prior.CurrentVersion))
+				} else {
+					result.LimitTo(result.BareCurrentVersion(), p.reason)
+				}
+			}
+		}
+	}
+}
+
+// checkPromotion decides whether the current version of a target of the previous stage has
+// soaked long enough or was approved for promotion to the given stage
+func checkPromotion(targetActor *configuration.TargetActor, stage *configuration.RolloutStage, prior *compare.ComparisonResult, now time.Time) *promotion {
+	if stage.SoakTime == "" && stage.ApprovalLabel == "" {
+		return &promotion{ready: true}
+	}
+
+	itemName := prior.TargetItemName
+	if itemName == "" {
+		itemName = prior.TargetName
+	}
+	line := findVersionLine(prior.TargetFile, itemName, prior.TargetValue())
+	if line == 0 {
+		return &promotion{reason: fmt.Sprintf("version of %s in %s not found", itemName, prior.TargetFile)}
+	}
+	sha, committed, err := git.LineCommit(prior.TargetFile, line)
+	if err != nil {
+		log.Warn().Err(err).Str("file", prior.TargetFile).Msg("Failed to read rollout history")
+		return &promotion{reason: fmt.Sprintf("history of %s unavailable", prior.TargetFile)}
+	}
+	if git.IsUncommittedSHA(sha) {
+		return &promotion{reason: fmt.Sprintf("%s in %s is not committed", prior.CurrentVersion, prior.TargetFile)}
+	}
+
+	reason := fmt.Sprintf("awaiting approval of %s in %s", prior.CurrentVersion, prior.TargetFile)
+	if stage.SoakTime != "" {
+		soakTime, err := util.ParseDuration(stage.SoakTime)
+		if err != nil {
+			return &promotion{reason: fmt.Sprintf("invalid soak time %s", stage.SoakTime)}
+		}
+		soakedAt := committed.Add(soakTime)
+		if !now.Before(soakedAt) {
+			return &promotion{ready: true}
+		}
+		reason = fmt.Sprintf("%s soaks in %s until %s", prior.CurrentVersion, prior.TargetFile, soakedAt.UTC().Format(time.RFC3339))
+	}
+
+	if stage.ApprovalLabel != "" {
+		approved, err := isCommitApproved(targetActor, prior.TargetFile, sha, stage.ApprovalLabel)
+		if err != nil {
+			log.Warn().Err(err).Str("commit", sha).Msg("Failed to check rollout approval")
+		} else if approved {
+			return &promotion{ready: true}
+		}
+	}
+
+	return &promotion{reason: reason}
+}
+
+// isCommitApproved reports whether a pull request containing the commit carries the label
+func isCommitApproved(targetActor *configuration.TargetActor, filePath string, sha string, label string) (bool, error) {
+	repo := git.NewRepository("", targetActor)
+	if err := repo.DetectRepository(filePath); err != nil {
+		return false, err
+	}
+
+	githubClient, err := git.NewGitHubClient(repo.RepoURL, targetActor)
+	if err != nil {
+		return false, err
+	}
+
+	prs, err := githubClient.ListCommitPullRequests(sha)
+	if err != nil {
+		return false, err
+	}
+	for i := range prs {
+		if prs[i].HasLabel(label) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
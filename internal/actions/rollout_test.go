@@ -0,0 +1,188 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// newTestRepository initializes a git repository in a temporary directory and returns it
+// together with a function running git in it
+func newTestRepository(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	run("init", "-q", "--initial-branch=main")
+	return dir, run
+}
+
+// writeTerraformTarget writes a variables file with an app_version variable holding the
+// current version and returns a target of the given rollout stage reading it
+func writeTerraformTarget(t *testing.T, dir string, name string, current string, stage string) *configuration.Target {
+	t.Helper()
+	file := filepath.Join(dir, name+".tf")
+	content := fmt.Sprintf("variable \"app_version\" {\n  default = \"%s\"\n}\n", current)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write target file: %v", err)
+	}
+	return &configuration.Target{
+		Name:  name,
+		Type:  configuration.TargetTypeTerraformVariable,
+		File:  file,
+		Stage: stage,
+		Items: []configuration.TargetItem{{TerraformVariableName: "app_version", Source: "app"}},
+	}
+}
+
+func TestApplyRollout(t *testing.T) {
+	dir, run := newTestRepository(t)
+	staging := writeTerraformTarget(t, dir, "staging", "2.5.0", "staging")
+	production := writeTerraformTarget(t, dir, "production", "2.4.1", "production")
+	unstaged := writeTerraformTarget(t, dir, "tools", "2.4.1", "")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial versions")
+
+	source := &configuration.PackageSource{Name: "app"}
+	for _, version := range []string{"3.0.0", "2.5.0", "2.4.1"} {
+		major, minor, patch := configuration.ParseSemver(version)
+		source.Versions = append(source.Versions, &configuration.PackageSourceVersion{Version: version, MajorVersion: major, MinorVersion: minor, PatchVersion: patch})
+	}
+
+	tests := []struct {
+		name               string
+		rollout            *configuration.Rollout
+		now                time.Time
+		expectedProduction string
+		needsUpdate        bool
+		reasonContains     string
+	}{
+		{
+			name:               "no rollout offers the latest version",
+			expectedProduction: "3.0.0",
+			needsUpdate:        true,
+		},
+		{
+			name: "promotion without soak time caps at the previous stage",
+			rollout: &configuration.Rollout{Stages: []*configuration.RolloutStage{
+				{Name: "staging"}, {Name: "production"},
+			}},
+			expectedProduction: "2.5.0",
+			needsUpdate:        true,
+			reasonContains:     "stage staging runs 2.5.0",
+		},
+		{
+			name: "soaking version is not promoted",
+			rollout: &configuration.Rollout{Stages: []*configuration.RolloutStage{
+				{Name: "staging"}, {Name: "production", SoakTime: "2d"},
+			}},
+			now:                time.Now().Add(time.Hour),
+			expectedProduction: "2.4.1",
+			reasonContains:     "2.5.0 soaks in",
+		},
+		{
+			name: "soaked version is promoted",
+			rollout: &configuration.Rollout{Stages: []*configuration.RolloutStage{
+				{Name: "staging"}, {Name: "production", SoakTime: "2d"},
+			}},
+			now:                time.Now().Add(72 * time.Hour),
+			expectedProduction: "2.5.0",
+			needsUpdate:        true,
+			reasonContains:     "stage staging runs 2.5.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configuration.Config{
+				PackageSources: []*configuration.PackageSource{source},
+				Targets:        []*configuration.Target{staging, production, unstaged},
+				Rollout:        tt.rollout,
+			}
+			results, err := compare.NewCompareEngine(config).CompareAll()
+			if err != nil {
+				t.Fatalf("CompareAll() failed: %v", err)
+			}
+
+			applyRollout(config, results, tt.now)
+
+			byTarget := make(map[string]*compare.ComparisonResult)
+			for _, result := range results {
+				if result.Error != nil {
+					t.Fatalf("unexpected error for %s: %v", result.TargetName, result.Error)
+				}
+				byTarget[result.TargetName] = result
+			}
+
+			// The first stage and targets without a stage are not restricted
+			for _, name := range []string{"staging", "tools"} {
+				if byTarget[name].LatestVersion != "3.0.0" {
+					t.Errorf("expected %s to be offered 3.0.0, got %s", name, byTarget[name].LatestVersion)
+				}
+			}
+
+			result := byTarget["production"]
+			if result.LatestVersion != tt.expectedProduction || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected production to be offered %s (needsUpdate %v), got %s (needsUpdate %v)",
+					tt.expectedProduction, tt.needsUpdate, result.LatestVersion, result.NeedsUpdate)
+			}
+			if !strings.Contains(result.HeldBackReason, tt.reasonContains) {
+				t.Errorf("expected held back reason containing %q, got %q", tt.reasonContains, result.HeldBackReason)
+			}
+		})
+	}
+}
+
+func TestApplyRollout_UncommittedPreviousStage(t *testing.T) {
+	dir, run := newTestRepository(t)
+	writeTerraformTarget(t, dir, "staging", "2.4.1", "staging")
+	production := writeTerraformTarget(t, dir, "production", "2.4.1", "production")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial versions")
+	// Staging runs a version that is not committed yet
+	staging := writeTerraformTarget(t, dir, "staging", "2.5.0", "staging")
+
+	source := &configuration.PackageSource{Name: "app", Versions: []*configuration.PackageSourceVersion{
+		{Version: "2.5.0", MajorVersion: 2, MinorVersion: 5},
+		{Version: "2.4.1", MajorVersion: 2, MinorVersion: 4, PatchVersion: 1},
+	}}
+	config := &configuration.Config{
+		PackageSources: []*configuration.PackageSource{source},
+		Targets:        []*configuration.Target{staging, production},
+		Rollout: &configuration.Rollout{Stages: []*configuration.RolloutStage{
+			{Name: "staging"}, {Name: "production", SoakTime: "1h"},
+		}},
+	}
+	results, err := compare.NewCompareEngine(config).CompareAll()
+	if err != nil {
+		t.Fatalf("CompareAll() failed: %v", err)
+	}
+
+	applyRollout(config, results, time.Now().Add(24*time.Hour))
+	if results[1].NeedsUpdate || !strings.Contains(results[1].HeldBackReason, "is not committed") {
+		t.Errorf("expected an uncommitted version not to be promoted, got %s (%q)", results[1].LatestVersion, results[1].HeldBackReason)
+	}
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/sbom"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// restrictToSBOM restricts the targets to the items whose source has a component in one of
+// the SBOMs, so only updates of deployed packages are compared and applied. Without SBOMs,
+// all targets are kept.
+func restrictToSBOM(config *configuration.Config, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	inventory, err := sbom.Load(paths[0])
+	if err != nil {
+		return err
+	}
+	for _, path := range paths[1:] {
+		other, err := sbom.Load(path)
+		if err != nil {
+			return err
+		}
+		inventory.Merge(other)
+	}
+
+	referenced := configuration.ReferencedSources(config)
+	deployed := make(map[string]bool, len(referenced))
+	for name := range referenced {
+		source, err := resolveSource(config, name)
+		if err != nil {
+			return err
+		}
+		if source != nil && inventory.Contains(source) {
+			deployed[name] = true
+		} else {
+			log.Debug().Str("source", name).Msg("Source has no component in the SBOM, skipping its targets")
+		}
+	}
+	configuration.FilterSources(config, deployed)
+
+	fmt.Fprintf(util.StatusOutput(), "🧾 %d of %d source(s) found in the SBOM (%d components)\n", len(deployed), len(referenced), inventory.Components)
+	return nil
+}
+
+// resolveSource returns the source of a name, materializing the package of a discovery source
+// for names of the form <source>/<package>, or nil if there is none
+func resolveSource(config *configuration.Config, name string) (*configuration.PackageSource, error) {
+	for _, source := range config.PackageSources {
+		if source.Name == name {
+			return source, nil
+		}
+	}
+	discovery := configuration.DiscoverySource(config.PackageSources, name)
+	if discovery == nil {
+		return nil, nil
+	}
+	return scraper.MaterializeSource(discovery, name[len(discovery.Name)+1:])
+}
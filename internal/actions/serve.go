@@ -0,0 +1,238 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mxcd/updater/internal/history"
+	"github.com/mxcd/updater/internal/lock"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/server"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// serveLogBytes is the amount of log output of a run kept for the web UI
+const serveLogBytes = 256 * 1024
+
+// serveLockDuration is the lease of the lock of --lock, renewed every third of it
+const serveLockDuration = 30 * time.Second
+
+// ServeOptions represents options for the serve command
+type ServeOptions struct {
+	ConfigPath     string
+	Recursive      bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment    string // Environment whose overrides are applied
+	Address        string // Serve the web UI on this address (e.g. "127.0.0.1:8080")
+	Interval       string // Run compare or apply this often (e.g. "1h")
+	Apply          bool   // Run apply instead of compare
+	Database       string // Record every run and keep snoozes in this history database, in memory if empty
+	Scope          string // Scope runs and snoozes are recorded under, the name of the working directory if empty
+	APIToken       string // Bearer token of the API under /api/v1/, the API is disabled if empty
+	Lock           string // Lock held by the replica that runs: kubernetes or database, disabled if empty
+	LockName       string // Name of the lock, "namespace/name" of a Kubernetes Lease
+	UpdaterVersion string // Version recorded in attestations
+
+	Scraper *scraper.OrchestratorOptions // GitHub version cache and recording or replay of provider requests, the defaults if nil
+}
+
+// Serve runs compare or apply every interval until interrupted and serves a web UI showing
+// the outcome of the last run, with buttons to trigger a run or snooze an update
+func Serve(options *ServeOptions) error {
+	interval, err := time.ParseDuration(options.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid --interval %q: must be a positive duration like 1h", options.Interval)
+	}
+	if options.Scope == "" {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		options.Scope = filepath.Base(workDir)
+	}
+
+	if options.Lock == "database" && options.Database == "" {
+		return fmt.Errorf("--lock database requires --database")
+	}
+
+	var store *history.Store
+	var snoozes server.SnoozeStore = &server.MemorySnoozes{}
+	if options.Database != "" {
+		if store, err = history.Open(options.Database); err != nil {
+			return err
+		}
+		defer store.Close()
+		snoozes = store
+	}
+
+	var runLock lock.Lock
+	switch options.Lock {
+	case "":
+	case "kubernetes":
+		namespace, name, found := strings.Cut(options.LockName, "/")
+		if !found {
+			namespace, name = "", options.LockName
+		}
+		runLock = &lock.KubernetesLease{Namespace: namespace, Name: name}
+	case "database":
+		runLock = &lock.Database{Store: store, Name: options.LockName}
+	default:
+		return fmt.Errorf("invalid --lock %q: must be kubernetes or database", options.Lock)
+	}
+
+	// Results are shown in the web UI, only logs are written
+	util.SetQuiet(true)
+	logBuffer := server.NewLogBuffer(serveLogBytes)
+	util.AddLogOutput(logBuffer)
+
+	command := "compare"
+	if options.Apply {
+		command = "apply"
+	}
+	if options.APIToken != "" {
+		util.RegisterSecret(options.APIToken)
+	}
+	srv := server.New(options.Scope, command, interval, snoozes, options.APIToken)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var elector *lock.Elector
+	if runLock != nil {
+		elector = lock.NewElector(runLock, lock.Holder(), serveLockDuration)
+		released := elector.Start(ctx)
+		defer func() {
+			stop()
+			<-released
+		}()
+	}
+
+	listener, err := net.Listen("tcp", options.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", options.Address, err)
+	}
+	httpServer := &http.Server{Handler: srv.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Web UI server failed")
+			stop()
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Str("address", listener.Addr().String()).Str("command", command).Dur("interval", interval).Msg("Serving web UI")
+	for {
+		srv.StartRun()
+		run := serveRun(options, store, srv, elector == nil || elector.IsLeader())
+		run.Log = logBuffer.Reset()
+		srv.FinishRun(run, time.Now().Add(interval))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-srv.Triggered():
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// serveRun runs compare or apply once with the active snoozes and returns its outcome. A replica
+// that does not hold the lock only compares, without recording the run, so its web UI stays
+// current while the leader applies.
+func serveRun(options *ServeOptions, store *history.Store, srv *server.Server, leader bool) *server.Run {
+	run := &server.Run{StartedAt: time.Now()}
+	onFinish := func(summary *RunSummary) {
+		run = newServerRun(summary)
+		run.Standby = !leader
+	}
+	if !leader {
+		log.Info().Msg("Another replica holds the lock, comparing without recording the run")
+		store = nil
+	}
+
+	snoozes, err := srv.Snoozes(time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list snoozes, running without them")
+	}
+	if options.Apply && leader {
+		err = Apply(&ApplyOptions{
+			ConfigPath:     options.ConfigPath,
+			Recursive:      options.Recursive,
+			Environment:    options.Environment,
+			OutputFormat:   "table",
+			Only:           "all",
+			UpdaterVersion: options.UpdaterVersion,
+			OnConflict:     "warn",
+			Parallel:       1,
+			History:        store,
+			HistoryScope:   options.Scope,
+			Snoozes:        snoozes,
+			Scraper:        options.Scraper,
+			onFinish:       onFinish,
+		})
+	} else {
+		_, err = Compare(&CompareOptions{
+			ConfigPath:   options.ConfigPath,
+			Recursive:    options.Recursive,
+			Environment:  options.Environment,
+			OutputFormat: "table",
+			Only:         "all",
+			History:      store,
+			HistoryScope: options.Scope,
+			Snoozes:      snoozes,
+			Scraper:      options.Scraper,
+			onFinish:     onFinish,
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Run failed")
+	}
+	return run
+}
+
+// newServerRun converts the summary of a run into the run shown in the web UI
+func newServerRun(summary *RunSummary) *server.Run {
+	run := &server.Run{
+		ID:          summary.RunID,
+		Command:     summary.Command,
+		StartedAt:   summary.StartedAt,
+		Duration:    time.Since(summary.StartedAt).Round(time.Millisecond),
+		Comparisons: summary.comparisons,
+		Errors:      summary.Errors,
+	}
+	for _, source := range summary.Sources {
+		run.Sources = append(run.Sources, &server.Source{
+			Name:     source.Name,
+			Provider: source.Provider,
+			Type:     source.Type,
+			Latest:   source.Latest,
+			Versions: source.Versions,
+			Error:    source.Error,
+		})
+	}
+	for _, pullRequest := range summary.PullRequests {
+		run.PullRequests = append(run.PullRequests, &server.PullRequest{
+			PatchGroup: pullRequest.PatchGroup,
+			URL:        pullRequest.URL,
+			Created:    pullRequest.Created,
+		})
+	}
+	return run
+}
@@ -0,0 +1,32 @@
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/history"
+	"github.com/rs/zerolog/log"
+)
+
+// applySnoozes holds back the updates snoozed at the given time. A snooze only applies to the
+// version it was created for, so a newer version is proposed again.
+func applySnoozes(results []*compare.ComparisonResult, snoozes []*history.Snooze, now time.Time) {
+	for _, result := range results {
+		if !result.NeedsUpdate {
+			continue
+		}
+		for _, snooze := range snoozes {
+			if !snooze.Until.After(now) || !snooze.Matches(result.TargetName, result.TargetFile, result.TargetItemName, result.LatestVersion) {
+				continue
+			}
+			log.Debug().
+				Str("target", result.TargetName).
+				Str("version", result.LatestVersion).
+				Time("until", snooze.Until).
+				Msg("Update is snoozed")
+			result.LimitTo(result.BareCurrentVersion(), fmt.Sprintf("snoozed until %s", snooze.Until.Local().Format("2006-01-02 15:04")))
+			break
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/history"
+)
+
+func TestApplySnoozes(t *testing.T) {
+	dir := t.TempDir()
+	app := writeTerraformTarget(t, dir, "app", "2.4.1", "")
+	tools := writeTerraformTarget(t, dir, "tools", "2.4.1", "")
+
+	source := &configuration.PackageSource{Name: "app"}
+	for _, version := range []string{"3.0.0", "2.5.0", "2.4.1"} {
+		major, minor, patch := configuration.ParseSemver(version)
+		source.Versions = append(source.Versions, &configuration.PackageSourceVersion{Version: version, MajorVersion: major, MinorVersion: minor, PatchVersion: patch})
+	}
+	config := &configuration.Config{
+		PackageSources: []*configuration.PackageSource{source},
+		Targets:        []*configuration.Target{app, tools},
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name        string
+		snooze      *history.Snooze
+		needsUpdate bool
+	}{
+		{
+			name:   "active snooze holds back the update",
+			snooze: &history.Snooze{Target: "app", File: app.File, Item: "app_version", Version: "3.0.0", Until: now.Add(time.Hour)},
+		},
+		{
+			name:        "ended snooze",
+			snooze:      &history.Snooze{Target: "app", File: app.File, Item: "app_version", Version: "3.0.0", Until: now.Add(-time.Hour)},
+			needsUpdate: true,
+		},
+		{
+			name:        "snooze of an older version",
+			snooze:      &history.Snooze{Target: "app", File: app.File, Item: "app_version", Version: "2.5.0", Until: now.Add(time.Hour)},
+			needsUpdate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := compare.NewCompareEngine(config).CompareAll()
+			if err != nil {
+				t.Fatalf("CompareAll() failed: %v", err)
+			}
+			applySnoozes(results, []*history.Snooze{tt.snooze}, now)
+
+			for _, result := range results {
+				if result.TargetName == "tools" {
+					if !result.NeedsUpdate || result.LatestVersion != "3.0.0" {
+						t.Errorf("unsnoozed target changed: %+v", result)
+					}
+					continue
+				}
+				if result.NeedsUpdate != tt.needsUpdate {
+					t.Errorf("NeedsUpdate = %v, want %v", result.NeedsUpdate, tt.needsUpdate)
+				}
+				if !tt.needsUpdate && (result.HeldBackVersion != "3.0.0" || !strings.HasPrefix(result.HeldBackReason, "snoozed until")) {
+					t.Errorf("expected 3.0.0 held back by the snooze, got %q (%s)", result.HeldBackVersion, result.HeldBackReason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// resolveHealthOptions returns the health state file, defaulting to one per configuration
+// in the user cache directory, and the default staleness period
+func resolveHealthOptions(healthFile, staleAfter, configPath string) (string, time.Duration, error) {
+	period := configuration.DefaultStaleAfter
+	if staleAfter != "" {
+		var err error
+		if period, err = util.ParseDuration(staleAfter); err != nil {
+			return "", 0, fmt.Errorf("invalid --stale-after: %w", err)
+		}
+	}
+
+	if healthFile == "" {
+		var err error
+		if healthFile, err = configuration.DefaultHealthStatePath(configPath); err != nil {
+			return "", 0, fmt.Errorf("failed to determine health state file: %w", err)
+		}
+	}
+	return healthFile, period, nil
+}
+
+// recordSourceHealth updates the health state with the outcome of a scrape and returns the
+// sources that are stale or keep failing. Health tracking never fails a run.
+func recordSourceHealth(config *configuration.Config, scrapeResult *scraper.ScrapeResult, healthFile string, staleAfter time.Duration) []*configuration.SourceHealthIssue {
+	state, err := configuration.LoadHealthState(healthFile)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load source health state, starting over")
+		state = &configuration.HealthState{Sources: make(map[string]*configuration.SourceHealth)}
+	}
+
+	sources := make(map[string]*configuration.PackageSource, len(config.PackageSources))
+	for _, source := range config.PackageSources {
+		sources[source.Name] = source
+	}
+
+	now := time.Now()
+	for _, stat := range scrapeResult.Sources {
+		if stat.Err != nil {
+			state.RecordFailure(stat.SourceName, stat.Err, now)
+			continue
+		}
+		latestVersion := ""
+		if source := sources[stat.SourceName]; source != nil {
+			if latest := source.LatestVersion(); latest != nil {
+				latestVersion = latest.Version
+			}
+		}
+		state.RecordSuccess(stat.SourceName, latestVersion, now)
+	}
+
+	if err := state.Save(healthFile); err != nil {
+		log.Warn().Err(err).Msg("Failed to save source health state")
+	}
+
+	return state.Issues(config, staleAfter, now)
+}
+
+// printSourceHealthIssues warns about stale and failing sources
+func printSourceHealthIssues(issues []*configuration.SourceHealthIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintf(util.StatusOutput(), "\n⏳ %d source(s) look unhealthy:\n", len(issues))
+	for _, issue := range issues {
+		log.Warn().Str("source", issue.SourceName).Msg(issue.Message)
+		fmt.Fprintf(util.StatusOutput(), "  ⚠️  %s: %s\n", issue.SourceName, issue.Message)
+	}
+	fmt.Fprintln(util.StatusOutput())
+}
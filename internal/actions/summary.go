@@ -0,0 +1,283 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// RunSummary is the machine-readable summary of a single updater invocation
+type RunSummary struct {
+	RunID        string                 `json:"runId"`
+	Command      string                 `json:"command"`
+	StartedAt    time.Time              `json:"startedAt"`
+	DurationMs   int64                  `json:"durationMs"`
+	Sources      []*SourceSummary       `json:"sources"`
+	Succeeded    int                    `json:"sourcesSucceeded"`
+	Failed       int                    `json:"sourcesFailed"`
+	Updates      *UpdateCounts          `json:"updates"`
+	PullRequests []*PullRequestSummary  `json:"pullRequests,omitempty"`
+	Skipped      []*SkippedGroupSummary `json:"skippedPatchGroups,omitempty"`
+	Errors       []string               `json:"errors"`
+	Profile      *RunProfile            `json:"profile,omitempty"` // Phase timings, recorded with --profile
+
+	comparisons []*compare.ComparisonResult // Compared target items, recorded in the history database
+}
+
+// SourceSummary describes the scrape outcome of a single package source
+type SourceSummary struct {
+	Name       string `json:"name"`
+	Provider   string `json:"provider"`
+	Type       string `json:"type"`
+	DurationMs int64  `json:"durationMs"`
+	Versions   int    `json:"versions"`
+	Latest     string `json:"latestVersion,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// UpdateCounts counts pending updates by update type
+type UpdateCounts struct {
+	Total    int `json:"total"`
+	Major    int `json:"major"`
+	Minor    int `json:"minor"`
+	Patch    int `json:"patch"`
+	HeldBack int `json:"heldBack"`
+}
+
+// PullRequestSummary records a pull request created or updated during apply
+type PullRequestSummary struct {
+	PatchGroup string `json:"patchGroup"`
+	URL        string `json:"url"`
+	Created    bool   `json:"created"`
+}
+
+// SkippedGroupSummary records a patch group apply deferred or whose branch it did not push
+type SkippedGroupSummary struct {
+	PatchGroup string `json:"patchGroup"`
+	Reason     string `json:"reason"`
+}
+
+// newRunSummary starts a summary for the given command
+func newRunSummary(command string) *RunSummary {
+	return &RunSummary{
+		RunID:     newRunID(),
+		Command:   command,
+		StartedAt: time.Now(),
+		Sources:   make([]*SourceSummary, 0),
+		Updates:   &UpdateCounts{},
+		Errors:    make([]string, 0),
+	}
+}
+
+// addScrapeResult records per-source scrape statistics
+func (s *RunSummary) addScrapeResult(result *scraper.ScrapeResult) {
+	if result == nil {
+		return
+	}
+	s.Succeeded = result.Succeeded
+	s.Failed = result.Failed
+	for _, stat := range result.Sources {
+		source := &SourceSummary{
+			Name:       stat.SourceName,
+			Provider:   stat.Provider,
+			Type:       string(stat.Type),
+			DurationMs: stat.Duration.Milliseconds(),
+			Versions:   stat.Versions,
+			Latest:     stat.Latest,
+		}
+		if stat.Err != nil {
+			source.Error = stat.Err.Error()
+		}
+		s.Sources = append(s.Sources, source)
+	}
+	for _, scrapeErr := range result.Errors {
+		s.Errors = append(s.Errors, scrapeErr.Error())
+	}
+}
+
+// addComparisonResults counts pending updates and records comparison errors
+func (s *RunSummary) addComparisonResults(results []*compare.ComparisonResult) {
+	s.comparisons = append(s.comparisons, results...)
+	for _, result := range results {
+		if result.Error != nil {
+			s.Errors = append(s.Errors, fmt.Sprintf("%s (%s): %v", result.TargetName, result.TargetFile, result.Error))
+			continue
+		}
+		s.Updates.add(result)
+	}
+}
+
+// add counts a comparison result that needs an update or has one held back
+func (c *UpdateCounts) add(result *compare.ComparisonResult) {
+	if result.HeldBackVersion != "" {
+		c.HeldBack++
+	}
+	if !result.NeedsUpdate {
+		return
+	}
+	c.Total++
+	switch result.UpdateType {
+	case compare.UpdateTypeMajor:
+		c.Major++
+	case compare.UpdateTypeMinor:
+		c.Minor++
+	case compare.UpdateTypePatch:
+		c.Patch++
+	}
+}
+
+// describeTypes lists the non-zero counts by update type, e.g. "2 major, 6 patch"
+func (c *UpdateCounts) describeTypes() string {
+	var parts []string
+	for _, count := range []struct {
+		n          int
+		updateType compare.UpdateType
+	}{
+		{c.Major, compare.UpdateTypeMajor},
+		{c.Minor, compare.UpdateTypeMinor},
+		{c.Patch, compare.UpdateTypePatch},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.updateType))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// addPatchGroups records the pull requests produced by apply
+func (s *RunSummary) addPatchGroups(groups []*PatchGroup) {
+	for _, group := range groups {
+		if s.Profile != nil && group.Duration > 0 {
+			s.Profile.PatchGroups = append(s.Profile.PatchGroups, &PhaseTiming{
+				Name:       group.Name,
+				DurationMs: group.Duration.Milliseconds(),
+			})
+		}
+		if group.SkippedReason != "" {
+			s.Skipped = append(s.Skipped, &SkippedGroupSummary{
+				PatchGroup: group.Name,
+				Reason:     group.SkippedReason,
+			})
+		}
+		if group.PullRequestURL == "" {
+			continue
+		}
+		s.PullRequests = append(s.PullRequests, &PullRequestSummary{
+			PatchGroup: group.Name,
+			URL:        group.PullRequestURL,
+			Created:    group.PullRequestCreated,
+		})
+	}
+}
+
+// addError records a fatal error that terminated the run
+func (s *RunSummary) addError(err error) {
+	if err != nil {
+		s.Errors = append(s.Errors, err.Error())
+	}
+}
+
+// write finalizes the summary and writes it to the summary file and, when running
+// inside GitHub Actions, to the step summary
+func (s *RunSummary) write(path string) {
+	if path == "" {
+		return
+	}
+	s.DurationMs = time.Since(s.StartedAt).Milliseconds()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to marshal run summary")
+		return
+	}
+	data = []byte(util.Redact(string(data)))
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		log.Warn().Err(err).Str("file", path).Msg("Failed to write run summary")
+		return
+	}
+	log.Debug().Str("file", path).Msg("Wrote run summary")
+
+	if stepSummary := os.Getenv("GITHUB_STEP_SUMMARY"); stepSummary != "" {
+		if err := appendToFile(stepSummary, util.Redact(s.renderMarkdown())); err != nil {
+			log.Warn().Err(err).Str("file", stepSummary).Msg("Failed to write GitHub step summary")
+		}
+	}
+}
+
+// renderMarkdown renders the summary as GitHub-flavored markdown
+func (s *RunSummary) renderMarkdown() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("## updater %s\n\n", s.Command))
+	sb.WriteString(fmt.Sprintf("Run `%s`.\n\n", s.RunID))
+	sb.WriteString(fmt.Sprintf("Scraped %d source(s), %d failed, in %.1fs.\n\n",
+		s.Succeeded+s.Failed, s.Failed, float64(s.DurationMs)/1000))
+
+	sb.WriteString("| Updates | Major | Minor | Patch |\n")
+	sb.WriteString("|---------|-------|-------|-------|\n")
+	sb.WriteString(fmt.Sprintf("| %d | %d | %d | %d |\n\n", s.Updates.Total, s.Updates.Major, s.Updates.Minor, s.Updates.Patch))
+
+	if len(s.PullRequests) > 0 {
+		sb.WriteString("### Pull Requests\n\n")
+		for _, pr := range s.PullRequests {
+			action := "updated"
+			if pr.Created {
+				action = "created"
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`: %s (%s)\n", pr.PatchGroup, pr.URL, action))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(s.Skipped) > 0 {
+		sb.WriteString("### Skipped Patch Groups\n\n")
+		for _, skipped := range s.Skipped {
+			sb.WriteString(fmt.Sprintf("- `%s`: %s\n", skipped.PatchGroup, skipped.Reason))
+		}
+		sb.WriteString("\n")
+	}
+
+	if s.Profile != nil {
+		sb.WriteString("### Profile\n\n")
+		sb.WriteString("| Phase | Duration |\n")
+		sb.WriteString("|-------|----------|\n")
+		for _, phase := range s.Profile.Phases {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", phase.Name, formatProfileDuration(phase.DurationMs)))
+		}
+		for _, source := range slowestSources(s.Sources, profileSourceCount) {
+			sb.WriteString(fmt.Sprintf("| scrape `%s` | %s |\n", source.Name, formatProfileDuration(source.DurationMs)))
+		}
+		for _, group := range s.Profile.PatchGroups {
+			sb.WriteString(fmt.Sprintf("| apply `%s` | %s |\n", group.Name, formatProfileDuration(group.DurationMs)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(s.Errors) > 0 {
+		sb.WriteString("### Errors\n\n")
+		for _, e := range s.Errors {
+			sb.WriteString(fmt.Sprintf("- %s\n", e))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// appendToFile appends content to a file, creating it if needed
+func appendToFile(path string, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(content)
+	return err
+}
@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/scraper"
+)
+
+func TestRunSummary_AddScrapeResult(t *testing.T) {
+	summary := newRunSummary("compare")
+	summary.addScrapeResult(&scraper.ScrapeResult{
+		Succeeded: 1,
+		Failed:    1,
+		Sources: []*scraper.SourceScrapeStat{
+			{SourceName: "nginx", Provider: "dockerhub", Type: "docker-image", Versions: 12},
+			{SourceName: "app", Provider: "github", Type: "git-release", Err: errors.New("timeout")},
+		},
+		Errors: []*scraper.ScrapeError{
+			{SourceName: "app", Provider: "github", Err: errors.New("timeout")},
+		},
+	})
+
+	if summary.Succeeded != 1 || summary.Failed != 1 {
+		t.Errorf("expected 1 succeeded and 1 failed source, got %d and %d", summary.Succeeded, summary.Failed)
+	}
+	if len(summary.Sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(summary.Sources))
+	}
+	if summary.Sources[0].Versions != 12 || summary.Sources[0].Error != "" {
+		t.Errorf("unexpected summary of nginx: %+v", summary.Sources[0])
+	}
+	if summary.Sources[1].Error != "timeout" {
+		t.Errorf("expected error of app to be recorded, got %q", summary.Sources[1].Error)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0] != "source app (provider github): timeout" {
+		t.Errorf("unexpected errors: %v", summary.Errors)
+	}
+
+	// A nil result is ignored
+	summary.addScrapeResult(nil)
+	if len(summary.Sources) != 2 {
+		t.Errorf("nil scrape result changed the sources")
+	}
+}
+
+func TestRunSummary_AddComparisonResults(t *testing.T) {
+	summary := newRunSummary("compare")
+	summary.addComparisonResults([]*compare.ComparisonResult{
+		{TargetName: "a", NeedsUpdate: true, UpdateType: compare.UpdateTypeMajor},
+		{TargetName: "b", NeedsUpdate: true, UpdateType: compare.UpdateTypeMinor},
+		{TargetName: "c", NeedsUpdate: true, UpdateType: compare.UpdateTypePatch},
+		{TargetName: "d", NeedsUpdate: true, UpdateType: compare.UpdateTypePatch, HeldBackVersion: "2.0.0"},
+		{TargetName: "e", HeldBackVersion: "3.0.0"},
+		{TargetName: "f"},
+		{TargetName: "g", TargetFile: "g.tf", Error: errors.New("source not found")},
+	})
+
+	expected := UpdateCounts{Total: 4, Major: 1, Minor: 1, Patch: 2, HeldBack: 2}
+	if *summary.Updates != expected {
+		t.Errorf("expected counts %+v, got %+v", expected, *summary.Updates)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0] != "g (g.tf): source not found" {
+		t.Errorf("unexpected errors: %v", summary.Errors)
+	}
+}
+
+func TestRunSummary_AddPatchGroups(t *testing.T) {
+	summary := newRunSummary("apply")
+	summary.addPatchGroups([]*PatchGroup{
+		{Name: "prod", PullRequestURL: "https://github.com/o/r/pull/1", PullRequestCreated: true},
+		{Name: "dev", PullRequestURL: "https://github.com/o/r/pull/2"},
+		{Name: "infra", SkippedReason: "outside maintenance window"},
+		{Name: "empty"},
+	})
+
+	if len(summary.PullRequests) != 2 {
+		t.Fatalf("expected 2 pull requests, got %d", len(summary.PullRequests))
+	}
+	if !summary.PullRequests[0].Created || summary.PullRequests[1].Created {
+		t.Errorf("unexpected created flags: %+v, %+v", summary.PullRequests[0], summary.PullRequests[1])
+	}
+	if len(summary.Skipped) != 1 || summary.Skipped[0].PatchGroup != "infra" {
+		t.Errorf("unexpected skipped patch groups: %+v", summary.Skipped)
+	}
+}
+
+func TestRunSummary_RenderMarkdown(t *testing.T) {
+	summary := &RunSummary{
+		RunID:      "20261016T060000Z-3f9a1c2e",
+		Command:    "apply",
+		DurationMs: 1500,
+		Succeeded:  3,
+		Failed:     1,
+		Updates:    &UpdateCounts{Total: 2, Major: 1, Patch: 1},
+		PullRequests: []*PullRequestSummary{
+			{PatchGroup: "prod", URL: "https://github.com/o/r/pull/1", Created: true},
+		},
+		Skipped: []*SkippedGroupSummary{
+			{PatchGroup: "infra", Reason: "outside maintenance window"},
+		},
+		Errors: []string{"source app (provider github): timeout"},
+	}
+
+	markdown := summary.renderMarkdown()
+	for _, expected := range []string{
+		"## updater apply",
+		"Run `20261016T060000Z-3f9a1c2e`.",
+		"Scraped 4 source(s), 1 failed, in 1.5s.",
+		"| 2 | 1 | 0 | 1 |",
+		"- `prod`: https://github.com/o/r/pull/1 (created)",
+		"### Skipped Patch Groups",
+		"- `infra`: outside maintenance window",
+		"- source app (provider github): timeout",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+
+	empty := newRunSummary("compare").renderMarkdown()
+	for _, section := range []string{"### Pull Requests", "### Skipped Patch Groups", "### Errors"} {
+		if strings.Contains(empty, section) {
+			t.Errorf("expected no %q section without entries", section)
+		}
+	}
+}
+
+func TestRunSummary_Write(t *testing.T) {
+	dir := t.TempDir()
+	stepSummary := filepath.Join(dir, "step-summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", stepSummary)
+
+	summary := newRunSummary("compare")
+	summary.Updates.Total = 1
+	path := filepath.Join(dir, "summary.json")
+	summary.write(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read summary file: %v", err)
+	}
+	var written RunSummary
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("summary file is not valid JSON: %v", err)
+	}
+	if written.RunID != summary.RunID || written.Command != "compare" || written.Updates.Total != 1 {
+		t.Errorf("unexpected summary written: %+v", written)
+	}
+
+	markdown, err := os.ReadFile(stepSummary)
+	if err != nil {
+		t.Fatalf("failed to read step summary: %v", err)
+	}
+	if !strings.Contains(string(markdown), "## updater compare") {
+		t.Errorf("expected step summary to be rendered, got:\n%s", markdown)
+	}
+}
+
+func TestUpdateCounts_DescribeTypes(t *testing.T) {
+	counts := &UpdateCounts{Total: 8, Major: 2, Patch: 6}
+	if description := counts.describeTypes(); description != "2 major, 6 patch" {
+		t.Errorf("unexpected description %q", description)
+	}
+	if description := (&UpdateCounts{}).describeTypes(); description != "" {
+		t.Errorf("expected empty description, got %q", description)
+	}
+}
+
+func TestRunSummary_Profile(t *testing.T) {
+	summary := newRunSummary("apply")
+	summary.startPhase(phaseParse)()
+	summary.addPatchGroups([]*PatchGroup{{Name: "prod", Duration: 2 * time.Second}})
+	if summary.Profile != nil {
+		t.Fatalf("expected no profile without --profile, got %+v", summary.Profile)
+	}
+
+	summary = newRunSummary("apply")
+	summary.enableProfile()
+	summary.startPhase(phaseParse)()
+	summary.startPhase(phaseScrape)()
+	summary.Sources = []*SourceSummary{
+		{Name: "fast", DurationMs: 120},
+		{Name: "slow", DurationMs: 9500},
+	}
+	summary.addPatchGroups([]*PatchGroup{
+		{Name: "prod", Duration: 2 * time.Second},
+		{Name: "deferred", SkippedReason: "outside maintenance window"},
+	})
+
+	if len(summary.Profile.Phases) != 2 || summary.Profile.Phases[0].Name != phaseParse || summary.Profile.Phases[1].Name != phaseScrape {
+		t.Errorf("unexpected phases: %+v", summary.Profile.Phases)
+	}
+	if len(summary.Profile.PatchGroups) != 1 || summary.Profile.PatchGroups[0].DurationMs != 2000 {
+		t.Errorf("unexpected patch group timings: %+v", summary.Profile.PatchGroups)
+	}
+	if sources := slowestSources(summary.Sources, 1); len(sources) != 1 || sources[0].Name != "slow" {
+		t.Errorf("unexpected slowest sources: %+v", sources)
+	}
+
+	markdown := summary.renderMarkdown()
+	for _, expected := range []string{"### Profile", "| parse | ", "| scrape `slow` | 9.5s |", "| apply `prod` | 2.0s |"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+}
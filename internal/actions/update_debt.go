@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/git"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateDebt is an outdated target item whose current version has not changed for longer
+// than the --since threshold
+type UpdateDebt struct {
+	Target         string    `json:"target" yaml:"target"`
+	File           string    `json:"file" yaml:"file"`
+	Item           string    `json:"item,omitempty" yaml:"item,omitempty"`
+	Source         string    `json:"source" yaml:"source"`
+	CurrentVersion string    `json:"currentVersion" yaml:"currentVersion"`
+	LatestVersion  string    `json:"latestVersion" yaml:"latestVersion"`
+	UpdateType     string    `json:"updateType" yaml:"updateType"`
+	PinnedSince    time.Time `json:"pinnedSince" yaml:"pinnedSince"`
+	AgeDays        int       `json:"ageDays" yaml:"ageDays"`
+}
+
+// collectUpdateDebt returns the outdated results whose current version was last changed in
+// git longer than threshold ago, oldest first. Results whose version line cannot be
+// located or is not committed are skipped.
+func collectUpdateDebt(results []*compare.ComparisonResult, threshold time.Duration) []*UpdateDebt {
+	now := time.Now()
+	debts := make([]*UpdateDebt, 0)
+
+	for _, result := range results {
+		if !result.NeedsUpdate || result.Error != nil {
+			continue
+		}
+
+		pinnedSince, err := currentVersionPinnedSince(result)
+		if err != nil {
+			log.Debug().Err(err).Str("file", result.TargetFile).Msg("Skipping update debt")
+			continue
+		}
+
+		age := now.Sub(pinnedSince)
+		if age < threshold {
+			continue
+		}
+
+		debts = append(debts, &UpdateDebt{
+			Target:         result.TargetName,
+			File:           result.TargetFile,
+			Item:           result.TargetItemName,
+			Source:         result.SourceName,
+			CurrentVersion: result.CurrentVersion,
+			LatestVersion:  result.LatestVersion,
+			UpdateType:     string(result.UpdateType),
+			PinnedSince:    pinnedSince,
+			AgeDays:        int(age.Hours() / 24),
+		})
+	}
+
+	sort.SliceStable(debts, func(i, j int) bool {
+		return debts[i].PinnedSince.Before(debts[j].PinnedSince)
+	})
+	return debts
+}
+
+// currentVersionPinnedSince returns when the line holding the current version of a result
+// was last changed in git
+func currentVersionPinnedSince(result *compare.ComparisonResult) (time.Time, error) {
+	itemName := result.TargetItemName
+	if itemName == "" {
+		itemName = result.TargetName
+	}
+
+	line := findVersionLine(result.TargetFile, itemName, result.TargetValue())
+	if line == 0 {
+		return time.Time{}, fmt.Errorf("current version of %s not found in file", itemName)
+	}
+
+	pinnedSince, err := git.LineCommitTime(result.TargetFile, line)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read version history: %w", err)
+	}
+	return pinnedSince, nil
+}
+
+func outputUpdateDebt(debts []*UpdateDebt, since string, format string) error {
+	switch format {
+	case "table":
+		return outputUpdateDebtTable(debts, since)
+	case "json":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(debts)
+	case "yaml":
+		writer := util.NewRedactingWriter(os.Stdout)
+		defer writer.Close()
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(2)
+		return encoder.Encode(debts)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+func outputUpdateDebtTable(debts []*UpdateDebt, since string) error {
+	if len(debts) == 0 {
+		fmt.Fprintf(util.ResultOutput(), "✅ No target has had an update available for longer than %s\n", since)
+		return nil
+	}
+
+	t := util.NewTable()
+	t.SetTitle(fmt.Sprintf("⏳ Update Debt (outdated for longer than %s)", since))
+	t.AppendHeader(table.Row{"File / Variable", "Source", "Current", "Latest", "Update Type", "Pinned Since", "Age (days)"})
+
+	for _, debt := range debts {
+		firstColumn := debt.Target
+		if debt.Item != "" {
+			firstColumn = fmt.Sprintf("%s\n  → %s", debt.File, debt.Item)
+		}
+		t.AppendRow(table.Row{
+			firstColumn,
+			debt.Source,
+			debt.CurrentVersion,
+			debt.LatestVersion,
+			debt.UpdateType,
+			debt.PinnedSince.Format("2006-01-02"),
+			debt.AgeDays,
+		})
+	}
+
+	t.Render()
+
+	fmt.Fprintf(util.StatusOutput(), "\n⏳ %d target(s) with update debt\n", len(debts))
+	return nil
+}
@@ -6,12 +6,15 @@ import (
 	"os"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
 type ValidateOptions struct {
 	ConfigPath     string
+	Recursive      bool   // Discover .updaterconfig.yml files under ConfigPath
+	Environment    string // Environment whose overrides are applied
 	OutputFormat   string
 	ProbeProviders bool
 }
@@ -20,7 +23,7 @@ func Validate(options *ValidateOptions) error {
 	log.Debug().Str("config", options.ConfigPath).Msg("Loading configuration...")
 
 	// Load configuration
-	config, err := configuration.LoadConfiguration(options.ConfigPath)
+	config, err := loadConfiguration(options.ConfigPath, options.Recursive, options.Environment)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to load configuration")
 		return fmt.Errorf("configuration load error: %w", err)
@@ -62,19 +65,19 @@ func outputValidationResult(result *configuration.ValidationResult, format strin
 
 func outputValidationTable(result *configuration.ValidationResult, probeProviders bool) error {
 	if result.Valid {
-		fmt.Println("✓ Configuration is valid")
+		fmt.Fprintln(util.ResultOutput(), "✓ Configuration is valid")
 		if probeProviders {
-			fmt.Println("  Note: Provider probing not yet implemented")
+			fmt.Fprintln(util.ResultOutput(), "  Note: Provider probing not yet implemented")
 		}
 		return nil
 	}
 
-	fmt.Println("✗ Configuration validation failed:")
-	fmt.Println()
+	fmt.Fprintln(util.ResultOutput(), "✗ Configuration validation failed:")
+	fmt.Fprintln(util.ResultOutput())
 	for _, err := range result.Errors {
-		fmt.Printf("  • %s\n", err.Error())
+		fmt.Fprintf(util.ResultOutput(), "  • %s\n", err.Error())
 	}
-	fmt.Printf("\nTotal errors: %d\n", len(result.Errors))
+	fmt.Fprintf(util.ResultOutput(), "\nTotal errors: %d\n", len(result.Errors))
 	return nil
 }
 
@@ -85,7 +88,9 @@ func outputValidationJSON(result *configuration.ValidationResult, probeProviders
 		"errors":         result.Errors,
 		"probeProviders": probeProviders,
 	}
-	encoder := json.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
@@ -97,7 +102,9 @@ func outputValidationYAML(result *configuration.ValidationResult, probeProviders
 		"errors":         result.Errors,
 		"probeProviders": probeProviders,
 	}
-	encoder := yaml.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := yaml.NewEncoder(writer)
 	encoder.SetIndent(2)
 	return encoder.Encode(output)
 }
@@ -120,7 +127,9 @@ func outputValidationSARIF(result *configuration.ValidationResult, probeProvider
 			},
 		},
 	}
-	encoder := json.NewEncoder(os.Stdout)
+	writer := util.NewRedactingWriter(os.Stdout)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(sarif)
 }
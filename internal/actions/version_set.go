@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// keepWholeVersionSets drops the kept updates of every version set that lost another update to
+// a filter, e.g. a policy deny or a missing image, so the items of a set are only ever updated
+// together
+func keepWholeVersionSets(all []*UpdateItem, kept []*UpdateItem) []*UpdateItem {
+	keptItems := make(map[*UpdateItem]bool, len(kept))
+	for _, item := range kept {
+		keptItems[item] = true
+	}
+	incomplete := make(map[string]bool)
+	for _, item := range all {
+		if item.VersionSet != "" && !keptItems[item] {
+			incomplete[item.VersionSet] = true
+		}
+	}
+	if len(incomplete) == 0 {
+		return kept
+	}
+
+	whole := make([]*UpdateItem, 0, len(kept))
+	for _, item := range kept {
+		if !incomplete[item.VersionSet] {
+			whole = append(whole, item)
+			continue
+		}
+		log.Warn().
+			Str("versionSet", item.VersionSet).
+			Str("item", item.ItemName).
+			Str("file", item.TargetFile).
+			Msg("Another update of the version set was skipped, skipping update")
+		fmt.Fprintf(util.StatusOutput(), "🚫 Version set %s is incomplete, skipping %s in %s: %s → %s\n",
+			item.VersionSet, item.ItemName, item.TargetFile, item.CurrentVersion, item.LatestVersion)
+	}
+	return whole
+}
+
+// keepWholeVersionSetResults drops the kept results proposing an update of every version set
+// of which a filter dropped another proposed update, see keepWholeVersionSets
+func keepWholeVersionSetResults(all []*compare.ComparisonResult, kept []*compare.ComparisonResult) []*compare.ComparisonResult {
+	keptResults := make(map[*compare.ComparisonResult]bool, len(kept))
+	for _, result := range kept {
+		keptResults[result] = true
+	}
+	incomplete := make(map[string]bool)
+	for _, result := range all {
+		if result.VersionSet != "" && result.NeedsUpdate && !keptResults[result] {
+			incomplete[result.VersionSet] = true
+		}
+	}
+	if len(incomplete) == 0 {
+		return kept
+	}
+
+	whole := make([]*compare.ComparisonResult, 0, len(kept))
+	for _, result := range kept {
+		if result.NeedsUpdate && incomplete[result.VersionSet] {
+			log.Warn().
+				Str("versionSet", result.VersionSet).
+				Str("target", result.TargetName).
+				Str("file", result.TargetFile).
+				Msg("Another update of the version set was filtered out, hiding update")
+			continue
+		}
+		whole = append(whole, result)
+	}
+	return whole
+}
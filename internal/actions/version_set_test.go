@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+func TestKeepWholeVersionSets(t *testing.T) {
+	server := &UpdateItem{ItemName: "server", VersionSet: "app"}
+	worker := &UpdateItem{ItemName: "worker", VersionSet: "app"}
+	other := &UpdateItem{ItemName: "other"}
+	all := []*UpdateItem{server, worker, other}
+
+	kept := keepWholeVersionSets(all, []*UpdateItem{server, other})
+	if len(kept) != 1 || kept[0] != other {
+		t.Errorf("expected only the update outside the incomplete set, got %v", kept)
+	}
+
+	kept = keepWholeVersionSets(all, []*UpdateItem{server, worker})
+	if len(kept) != 2 {
+		t.Errorf("expected the complete set to be kept, got %d updates", len(kept))
+	}
+}
+
+func TestFilterComparisonResults_VersionSet(t *testing.T) {
+	results := []*compare.ComparisonResult{
+		{TargetName: "server", VersionSet: "app", UpdateType: compare.UpdateTypeMinor, NeedsUpdate: true},
+		{TargetName: "worker", VersionSet: "app", UpdateType: compare.UpdateTypeMajor, NeedsUpdate: true},
+		{TargetName: "other", UpdateType: compare.UpdateTypeMinor, NeedsUpdate: true},
+	}
+
+	filtered := filterComparisonResults(results, "minor")
+	if len(filtered) != 1 || filtered[0].TargetName != "other" {
+		t.Errorf("expected the set split by --only to be dropped, got %d results", len(filtered))
+	}
+
+	if filtered := filterComparisonResults(results, "all"); len(filtered) != 3 {
+		t.Errorf("expected all results, got %d", len(filtered))
+	}
+}
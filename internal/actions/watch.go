@@ -0,0 +1,143 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper"
+	"github.com/mxcd/updater/internal/util"
+)
+
+// watchLoad re-scrapes all sources every interval and redraws a dashboard of the newest
+// version of each source and the targets it would update, until interrupted. The
+// configuration is reloaded on every refresh so edits are picked up.
+func watchLoad(options *LoadOptions, interval time.Duration) error {
+	if options.OutputFormat != "table" {
+		return fmt.Errorf("--watch only supports the table output format")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		renderWatchDashboard(watchRefresh(options), interval)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchSnapshot is the state shown by one refresh of the dashboard
+type watchSnapshot struct {
+	Sources      []*configuration.PackageSource
+	Results      []*compare.ComparisonResult
+	ScrapeErrors map[string]error // Scrape error by source name
+	Err          error            // Error that prevented the refresh
+}
+
+// watchRefresh loads the configuration, scrapes all sources and compares the targets
+func watchRefresh(options *LoadOptions) *watchSnapshot {
+	snapshot := &watchSnapshot{ScrapeErrors: make(map[string]error)}
+
+	config, err := loadConfiguration(options.ConfigPath, options.Recursive, options.Environment)
+	if err != nil {
+		snapshot.Err = fmt.Errorf("configuration load error: %w", err)
+		return snapshot
+	}
+
+	validationResult := configuration.ValidateConfiguration(config)
+	if !validationResult.Valid {
+		snapshot.Err = fmt.Errorf("configuration validation failed: %s: %s", validationResult.Errors[0].Field, validationResult.Errors[0].Message)
+		return snapshot
+	}
+
+	orchestrator, err := scraper.NewOrchestrator(config, options.Scraper)
+	if err != nil {
+		snapshot.Err = fmt.Errorf("orchestrator creation error: %w", err)
+		return snapshot
+	}
+
+	scrapeResult := orchestrator.ScrapeAllSources(&scraper.ScrapeOptions{Limit: options.Limit})
+	for _, scrapeErr := range scrapeResult.Errors {
+		snapshot.ScrapeErrors[scrapeErr.SourceName] = scrapeErr.Err
+	}
+	snapshot.Sources = orchestrator.GetConfig().PackageSources
+
+	if snapshot.Results, err = compare.NewCompareEngine(orchestrator.GetConfig()).CompareAll(); err != nil {
+		snapshot.Err = fmt.Errorf("comparison error: %w", err)
+	}
+	return snapshot
+}
+
+func renderWatchDashboard(snapshot *watchSnapshot, interval time.Duration) {
+	// Clear the terminal and move the cursor home
+	fmt.Fprint(util.ResultOutput(), "\033[H\033[2J")
+
+	t := util.NewTable()
+	t.SetTitle(fmt.Sprintf("👀 Watching %d source(s), refreshed %s, next in %s (Ctrl+C to stop)",
+		len(snapshot.Sources), time.Now().Format("15:04:05"), interval))
+	t.AppendHeader(table.Row{"Source", "Provider", "Newest Version", "Status", "Outdated Targets"})
+
+	for _, row := range watchRows(snapshot) {
+		t.AppendRow(row)
+		t.AppendSeparator()
+	}
+
+	t.Render()
+
+	if snapshot.Err != nil {
+		fmt.Fprintf(util.ResultOutput(), "\n❌ %v\n", snapshot.Err)
+	}
+}
+
+// watchRows returns one dashboard row per source with its newest version, its status and
+// the targets it would update
+func watchRows(snapshot *watchSnapshot) []table.Row {
+	outdated := make(map[string][]string)
+	for _, result := range snapshot.Results {
+		if result.NeedsUpdate {
+			outdated[result.SourceName] = append(outdated[result.SourceName],
+				fmt.Sprintf("%s: %s → %s", result.TargetName, result.CurrentVersion, result.LatestVersion))
+		}
+	}
+
+	rows := make([]table.Row, 0, len(snapshot.Sources))
+	for _, source := range snapshot.Sources {
+		newest := "-"
+		if latest := source.LatestVersion(); latest != nil {
+			newest = latest.Version
+		}
+
+		status := "✅ Up to date"
+		switch {
+		case snapshot.ScrapeErrors[source.Name] != nil:
+			status = fmt.Sprintf("❌ %v", snapshot.ScrapeErrors[source.Name])
+		case len(source.Versions) == 0:
+			status = "❌ No versions"
+		case len(outdated[source.Name]) > 0:
+			status = fmt.Sprintf("⬆️  %d update(s)", len(outdated[source.Name]))
+		}
+
+		targets := "-"
+		if len(outdated[source.Name]) > 0 {
+			targets = strings.Join(outdated[source.Name], "\n")
+		}
+
+		rows = append(rows, table.Row{source.Name, source.Provider, newest, status, targets})
+	}
+	return rows
+}
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestWatchRows(t *testing.T) {
+	snapshot := &watchSnapshot{
+		Sources: []*configuration.PackageSource{
+			{Name: "nginx", Provider: "dockerhub", Versions: []*configuration.PackageSourceVersion{{Version: "1.27.0"}, {Version: "1.26.0"}}},
+			{Name: "app", Provider: "github", Versions: []*configuration.PackageSourceVersion{{Version: "2.0.0"}}},
+			{Name: "redis", Provider: "dockerhub"},
+			{Name: "broken", Provider: "github"},
+		},
+		Results: []*compare.ComparisonResult{
+			{SourceName: "app", TargetName: "web", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", NeedsUpdate: true},
+			{SourceName: "app", TargetName: "api", CurrentVersion: "1.5.0", LatestVersion: "2.0.0", NeedsUpdate: true},
+			{SourceName: "nginx", TargetName: "proxy", CurrentVersion: "1.27.0", LatestVersion: "1.27.0"},
+		},
+		ScrapeErrors: map[string]error{"broken": errors.New("rate limited")},
+	}
+
+	rows := watchRows(snapshot)
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+
+	tests := []struct {
+		name    string
+		row     int
+		newest  string
+		status  string
+		targets string
+	}{
+		{name: "up to date", row: 0, newest: "1.27.0", status: "✅ Up to date", targets: "-"},
+		{name: "outdated targets", row: 1, newest: "2.0.0", status: "⬆️  2 update(s)", targets: "web: 1.0.0 → 2.0.0\napi: 1.5.0 → 2.0.0"},
+		{name: "no versions", row: 2, newest: "-", status: "❌ No versions", targets: "-"},
+		{name: "scrape error", row: 3, newest: "-", status: "❌ rate limited", targets: "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := rows[tt.row]
+			if row[2] != tt.newest || row[3] != tt.status || row[4] != tt.targets {
+				t.Errorf("expected %q, %q, %q, got %q, %q, %q", tt.newest, tt.status, tt.targets, row[2], row[3], row[4])
+			}
+		})
+	}
+}
+
+func TestWatchRefresh_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yml")
+	if err := os.WriteFile(invalid, []byte("packageSources:\n  - name: app\n"), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		configPath    string
+		errorContains string
+	}{
+		{name: "missing configuration", configPath: filepath.Join(dir, "missing.yml"), errorContains: "configuration load error"},
+		{name: "invalid configuration", configPath: invalid, errorContains: "configuration validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := watchRefresh(&LoadOptions{ConfigPath: tt.configPath, OutputFormat: "table"})
+			if snapshot.Err == nil || !strings.Contains(snapshot.Err.Error(), tt.errorContains) {
+				t.Fatalf("expected error containing %q, got %v", tt.errorContains, snapshot.Err)
+			}
+			if len(snapshot.Sources) != 0 || len(snapshot.Results) != 0 {
+				t.Errorf("expected an empty snapshot on error, got %+v", snapshot)
+			}
+		})
+	}
+}
+
+func TestWatchLoad_RequiresTableFormat(t *testing.T) {
+	err := watchLoad(&LoadOptions{OutputFormat: "json"}, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "table output format") {
+		t.Errorf("expected table format error, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// Reader reads deployed versions from Kubernetes clusters with kubectl, which must be on the
+// PATH and configured through the kubeconfig. Each resource is read once per reader. A
+// reader is safe for concurrent use.
+type Reader struct {
+	mu        sync.Mutex
+	resources map[string]map[string]any
+}
+
+// NewReader creates a reader with an empty resource cache
+func NewReader() *Reader {
+	return &Reader{resources: make(map[string]map[string]any)}
+}
+
+// ReadVersion returns the version a cluster resource runs: the image of a workload container,
+// or the chart version of a Flux HelmRelease
+func (r *Reader) ReadVersion(ref *configuration.ClusterReference) (string, error) {
+	kind, _, err := configuration.ParseClusterResource(ref.Resource)
+	if err != nil {
+		return "", err
+	}
+
+	resource, err := r.get(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if kind == configuration.ClusterKindHelmRelease {
+		return helmReleaseVersion(resource)
+	}
+	return workloadImage(resource, kind, ref.Container)
+}
+
+// get fetches a resource with kubectl get -o json
+func (r *Reader) get(ref *configuration.ClusterReference) (map[string]any, error) {
+	key := strings.Join([]string{ref.Context, ref.Namespace, ref.Resource}, "/")
+	r.mu.Lock()
+	resource, ok := r.resources[key]
+	r.mu.Unlock()
+	if ok {
+		return resource, nil
+	}
+
+	args := []string{"get", ref.Resource, "-o", "json"}
+	if ref.Context != "" {
+		args = append(args, "--context", ref.Context)
+	}
+	if ref.Namespace != "" {
+		args = append(args, "--namespace", ref.Namespace)
+	}
+
+	log.Debug().Strs("args", args).Msg("Reading cluster resource")
+
+	cmd := exec.Command("kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kubectl get %s failed: %s", ref.Resource, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(output, &resource); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ref.Resource, err)
+	}
+	r.mu.Lock()
+	r.resources[key] = resource
+	r.mu.Unlock()
+	return resource, nil
+}
+
+// workloadImage returns the image of the named container of a workload, or of its first
+// container if no name is given
+func workloadImage(resource map[string]any, kind string, container string) (string, error) {
+	podSpecPath := []string{"spec", "template", "spec"}
+	if kind == configuration.ClusterKindCronJob {
+		podSpecPath = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	podSpec, ok := lookup(resource, podSpecPath...).(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("%s has no pod template", kind)
+	}
+
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, _ := podSpec[field].([]any)
+		for _, c := range containers {
+			spec, _ := c.(map[string]any)
+			name, _ := spec["name"].(string)
+			image, _ := spec["image"].(string)
+			if image != "" && (container == "" || name == container) {
+				return image, nil
+			}
+		}
+	}
+
+	if container != "" {
+		return "", fmt.Errorf("container %s not found in %s", container, kind)
+	}
+	return "", fmt.Errorf("%s has no containers", kind)
+}
+
+// helmReleaseVersion returns the chart version a Flux HelmRelease last applied
+func helmReleaseVersion(resource map[string]any) (string, error) {
+	// helm-controller v1 records the release history, newest first
+	if history, ok := lookup(resource, "status", "history").([]any); ok && len(history) > 0 {
+		if snapshot, ok := history[0].(map[string]any); ok {
+			if version, ok := snapshot["chartVersion"].(string); ok && version != "" {
+				return version, nil
+			}
+		}
+	}
+	if version, ok := lookup(resource, "status", "lastAppliedRevision").(string); ok && version != "" {
+		return version, nil
+	}
+	return "", fmt.Errorf("helmrelease has no applied chart version in its status")
+}
+
+// lookup returns the value at a path of nested objects, nil if absent
+func lookup(value any, path ...string) any {
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// ImageTag returns the tag of an image reference, ignoring a digest. References without a
+// tag run "latest".
+func ImageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	lastSlash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > lastSlash {
+		return image[colon+1:]
+	}
+	return "latest"
+}
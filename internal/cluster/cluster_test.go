@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestWorkloadImage(t *testing.T) {
+	deployment := `{
+		"spec": {"template": {"spec": {
+			"initContainers": [{"name": "migrate", "image": "example/migrate:2.0.0"}],
+			"containers": [
+				{"name": "app", "image": "ghcr.io/example/app:1.4.2"},
+				{"name": "sidecar", "image": "example/proxy:0.9.0@sha256:abc"}
+			]
+		}}}
+	}`
+	cronJob := `{
+		"spec": {"jobTemplate": {"spec": {"template": {"spec": {
+			"containers": [{"name": "backup", "image": "example/backup:3.1.0"}]
+		}}}}}
+	}`
+
+	tests := []struct {
+		name      string
+		resource  string
+		kind      string
+		container string
+		expected  string
+		expectErr bool
+	}{
+		{name: "first container", resource: deployment, kind: configuration.ClusterKindDeployment, expected: "ghcr.io/example/app:1.4.2"},
+		{name: "named container", resource: deployment, kind: configuration.ClusterKindDeployment, container: "sidecar", expected: "example/proxy:0.9.0@sha256:abc"},
+		{name: "init container", resource: deployment, kind: configuration.ClusterKindDeployment, container: "migrate", expected: "example/migrate:2.0.0"},
+		{name: "missing container", resource: deployment, kind: configuration.ClusterKindDeployment, container: "worker", expectErr: true},
+		{name: "cron job", resource: cronJob, kind: configuration.ClusterKindCronJob, expected: "example/backup:3.1.0"},
+		{name: "cron job read as deployment", resource: cronJob, kind: configuration.ClusterKindDeployment, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]any
+			if err := json.Unmarshal([]byte(tt.resource), &resource); err != nil {
+				t.Fatalf("Failed to parse test resource: %v", err)
+			}
+
+			image, err := workloadImage(resource, tt.kind, tt.container)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got image %s", image)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("workloadImage() error = %v", err)
+			}
+			if image != tt.expected {
+				t.Errorf("workloadImage() = %s, expected %s", image, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHelmReleaseVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		resource  string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "release history",
+			resource: `{"status": {"history": [{"chartVersion": "1.2.0"}, {"chartVersion": "1.1.0"}], "lastAppliedRevision": "1.0.0"}}`,
+			expected: "1.2.0",
+		},
+		{
+			name:     "last applied revision",
+			resource: `{"status": {"lastAppliedRevision": "1.0.0"}}`,
+			expected: "1.0.0",
+		},
+		{
+			name:      "not yet applied",
+			resource:  `{"status": {}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]any
+			if err := json.Unmarshal([]byte(tt.resource), &resource); err != nil {
+				t.Fatalf("Failed to parse test resource: %v", err)
+			}
+
+			version, err := helmReleaseVersion(resource)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got version %s", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("helmReleaseVersion() error = %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("helmReleaseVersion() = %s, expected %s", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "nginx:1.25.0", expected: "1.25.0"},
+		{image: "registry.example.com:5000/team/app:v2.1.0", expected: "v2.1.0"},
+		{image: "registry.example.com:5000/team/app", expected: "latest"},
+		{image: "example/app:1.0.0@sha256:abc", expected: "1.0.0"},
+		{image: "nginx", expected: "latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if tag := ImageTag(tt.image); tag != tt.expected {
+				t.Errorf("ImageTag(%q) = %s, expected %s", tt.image, tag, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeKubectl puts a kubectl on the PATH that prints output and records each invocation in
+// the returned file
+func fakeKubectl(t *testing.T, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\ncat <<'EOF'\n" + output + "\nEOF\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func TestReader_ConcurrentReads(t *testing.T) {
+	calls := fakeKubectl(t, `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "example/app:1.4.2"}]}}}}`)
+	reader := NewReader()
+	ref := &configuration.ClusterReference{Context: "prod", Namespace: "apps", Resource: "deployment/app"}
+
+	var wg sync.WaitGroup
+	versions := make([]string, 8)
+	errs := make([]error, 8)
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			versions[i], errs[i] = reader.ReadVersion(ref)
+		}()
+	}
+	wg.Wait()
+
+	for i := range versions {
+		if errs[i] != nil || versions[i] != "example/app:1.4.2" {
+			t.Errorf("read %d: expected example/app:1.4.2, got %q (%v)", i, versions[i], errs[i])
+		}
+	}
+
+	// Later reads are served from the cache
+	if _, err := reader.ReadVersion(ref); err != nil {
+		t.Fatalf("ReadVersion() error = %v", err)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("Failed to read kubectl calls: %v", err)
+	}
+	invocations := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(invocations) > len(versions) {
+		t.Errorf("expected at most %d kubectl calls, got %d", len(versions), len(invocations))
+	}
+	if !strings.Contains(invocations[0], "get deployment/app -o json --context prod --namespace apps") {
+		t.Errorf("unexpected kubectl arguments: %s", invocations[0])
+	}
+}
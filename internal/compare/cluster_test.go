@@ -0,0 +1,170 @@
+package compare
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// fakeKubectl puts a kubectl on the PATH that prints the given resource
+func fakeKubectl(t *testing.T, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat <<'EOF'\n" + output + "\nEOF\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// deploymentResource returns a deployment running the given image
+func deploymentResource(image string) string {
+	return `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "` + image + `"}]}}}}`
+}
+
+func TestCompareAll_ReadFromCluster(t *testing.T) {
+	source := &configuration.PackageSource{
+		Name:     "app",
+		Versions: newVersions("3.0.0", "2.5.0", "2.4.1", "2.4.0"),
+	}
+
+	tests := []struct {
+		name            string
+		fileVersion     string
+		resource        string
+		clusterResource string
+		maxUpdateType   string
+		versionTemplate string
+		current         string
+		expected        string
+		needsUpdate     bool
+		drifted         bool
+		errorContains   string
+	}{
+		{
+			name:            "cluster matches the file",
+			fileVersion:     "2.4.1",
+			resource:        deploymentResource("example/app:2.4.1"),
+			clusterResource: "deployment/app",
+			current:         "2.4.1",
+			expected:        "3.0.0",
+			needsUpdate:     true,
+		},
+		{
+			name:            "v prefix of the deployed tag is not drift",
+			fileVersion:     "2.4.1",
+			resource:        deploymentResource("example/app:v2.4.1"),
+			clusterResource: "deployment/app",
+			current:         "v2.4.1",
+			expected:        "3.0.0",
+			needsUpdate:     true,
+		},
+		{
+			name:            "update is selected from the deployed version",
+			fileVersion:     "2.4.1",
+			resource:        deploymentResource("example/app:2.4.0"),
+			clusterResource: "deployment/app",
+			maxUpdateType:   "minor",
+			current:         "2.4.0",
+			expected:        "2.5.0",
+			needsUpdate:     true,
+			drifted:         true,
+		},
+		{
+			name:            "update already in the file is not proposed again",
+			fileVersion:     "2.4.1",
+			resource:        deploymentResource("example/app:2.4.0"),
+			clusterResource: "deployment/app",
+			maxUpdateType:   "patch",
+			current:         "2.4.0",
+			expected:        "2.4.1",
+			drifted:         true,
+		},
+		{
+			name:            "cluster ahead of the file is up to date",
+			fileVersion:     "2.4.0",
+			resource:        deploymentResource("registry.example.com:5000/app:3.0.0"),
+			clusterResource: "deployment/app",
+			current:         "3.0.0",
+			expected:        "3.0.0",
+			drifted:         true,
+		},
+		{
+			name:            "helm release chart version",
+			fileVersion:     "2.4.1",
+			resource:        `{"status": {"history": [{"chartVersion": "2.4.0"}, {"chartVersion": "2.3.0"}]}}`,
+			clusterResource: "helmrelease/app",
+			current:         "2.4.0",
+			expected:        "3.0.0",
+			needsUpdate:     true,
+			drifted:         true,
+		},
+		{
+			name:            "deployed tag outside the version format",
+			fileVersion:     "v2.4.1",
+			resource:        deploymentResource("example/app:latest"),
+			clusterResource: "deployment/app",
+			versionTemplate: "v{{version}}",
+			errorContains:   "deployed version 'example/app:latest' does not match the target version format",
+		},
+		{
+			name:          "no cluster resource",
+			fileVersion:   "2.4.1",
+			errorContains: "no cluster resource configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubectl(t, tt.resource)
+			target := newTerraformTarget(t, "app", tt.fileVersion, "app")
+			target.ReadFrom = configuration.TargetReadFromCluster
+			target.MaxUpdateType = tt.maxUpdateType
+			target.VersionTemplate = tt.versionTemplate
+			if tt.clusterResource != "" {
+				target.Cluster = &configuration.ClusterReference{Namespace: "apps", Resource: tt.clusterResource}
+			}
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if tt.errorContains != "" {
+				if result.Error == nil || !strings.Contains(result.Error.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, result.Error)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.CurrentVersion != tt.current || result.FileVersion != tt.fileVersion || result.Drifted != tt.drifted {
+				t.Errorf("expected current %s (file %s, drifted %v), got %s (file %s, drifted %v)",
+					tt.current, tt.fileVersion, tt.drifted, result.CurrentVersion, result.FileVersion, result.Drifted)
+			}
+			if result.LatestVersion != tt.expected || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected %s (needsUpdate %v), got %s (needsUpdate %v)", tt.expected, tt.needsUpdate, result.LatestVersion, result.NeedsUpdate)
+			}
+		})
+	}
+}
+
+func TestCompareAll_ReadFromFileIgnoresCluster(t *testing.T) {
+	fakeKubectl(t, deploymentResource("example/app:2.4.0"))
+	source := &configuration.PackageSource{Name: "app", Versions: newVersions("2.4.1", "2.4.0")}
+	target := newTerraformTarget(t, "app", "2.4.1", "app")
+	target.Cluster = &configuration.ClusterReference{Resource: "deployment/app"}
+
+	result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.CurrentVersion != "2.4.1" || result.FileVersion != "" || result.Drifted || result.NeedsUpdate {
+		t.Errorf("expected the file version without drift, got %+v", result)
+	}
+}
@@ -2,9 +2,12 @@ package compare
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/mxcd/updater/internal/cluster"
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
 	"github.com/mxcd/updater/internal/target"
 	"github.com/rs/zerolog/log"
 )
@@ -21,9 +24,37 @@ type ComparisonResult struct {
 	UpdateType      UpdateType
 	NeedsUpdate     bool
 	Error           error
-	IsWildcardMatch bool   // True if this target was expanded from a wildcard pattern
-	WildcardPattern string // The original wildcard pattern if IsWildcardMatch is true
-	PatchGroup      string // Patch group for grouping updates together
+	IsWildcardMatch bool       // True if this target was expanded from a wildcard pattern
+	WildcardPattern string     // The original wildcard pattern if IsWildcardMatch is true
+	PatchGroup      string     // Patch group for grouping updates together
+	Track           string     // Source track the target follows, empty for the overall latest version
+	MaxUpdateType   UpdateType // Largest update type allowed by policy, empty if unrestricted
+	HeldBackVersion string     // Newer version withheld because it exceeds MaxUpdateType
+	HeldBackType    UpdateType // Update type of HeldBackVersion
+	HeldBackReason  string     // Why HeldBackVersion is withheld if not by MaxUpdateType, e.g. a pending rollout
+	Stage           string     // Rollout stage of the target, empty if not staged
+	FileVersion     string     // Version in the target file if CurrentVersion was read from the cluster
+	Drifted         bool       // True if the cluster runs a different version than the target file
+	VersionSet      string     // Version set the item belongs to, empty if none
+	Risk            RiskLevel  // Heuristic risk of the proposed update, empty if none is proposed
+	RiskReasons     []string   // What the risk is based on, e.g. "major update"
+	// SkippedVersions lists the versions between the current and the proposed version, newest
+	// first, nil unless listed by ListSkippedVersions
+	SkippedVersions           []string
+	SkippedVersionCount       int
+	SkippedVersionsIncomplete bool // Older skipped versions are beyond the version limit and missing
+	// UnknownVersion explains why the current version is not among the source versions and
+	// names the nearest ones, empty if it is known
+	UnknownVersion string
+	// VersionSetInconsistent is true if the items of the version set currently hold different versions
+	VersionSetInconsistent bool
+
+	bareCurrentVersion string                                // Current version with the target format stripped
+	bareLatestVersion  string                                // Proposed version with the source format stripped
+	versions           []*configuration.PackageSourceVersion // Bare source versions of the track, newest first
+	currentSemVer      *configuration.PackageSourceVersion
+	versionScheme      string // Version scheme of the source
+	targetFormat       *targetVersionFormat
 }
 
 // UpdateType represents the type of update (major, minor, patch, none)
@@ -34,12 +65,17 @@ const (
 	UpdateTypeMinor UpdateType = "minor"
 	UpdateTypePatch UpdateType = "patch"
 	UpdateTypeNone  UpdateType = "none"
+	// UpdateTypeDowngrade means the current version is newer than the latest available one,
+	// usually because of a mis-scoped tagPattern or a deleted upstream release
+	UpdateTypeDowngrade UpdateType = "downgrade"
 )
 
 // CompareEngine performs comparison between targets and sources
 type CompareEngine struct {
 	config        *configuration.Config
 	targetFactory *target.TargetFactory
+	clusterReader *cluster.Reader
+	trackPatterns map[*configuration.PackageSourceTrack]*regexp.Regexp // Compiled tagPattern of each track
 }
 
 // NewCompareEngine creates a new comparison engine
@@ -47,6 +83,8 @@ func NewCompareEngine(config *configuration.Config) *CompareEngine {
 	return &CompareEngine{
 		config:        config,
 		targetFactory: target.NewTargetFactory(config),
+		clusterReader: cluster.NewReader(),
+		trackPatterns: make(map[*configuration.PackageSourceTrack]*regexp.Regexp),
 	}
 }
 
@@ -64,6 +102,9 @@ func (e *CompareEngine) CompareAll() ([]*ComparisonResult, error) {
 		}
 	}
 
+	markInconsistentVersionSets(results)
+	AlignVersionSets(results)
+
 	log.Debug().
 		Int("total", len(results)).
 		Int("needsUpdate", countNeedingUpdate(results)).
@@ -89,6 +130,28 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 		itemName = updateItem.SubchartName
 	case configuration.TargetTypeYamlField:
 		itemName = updateItem.YamlPath
+	case configuration.TargetTypeJsonnetField:
+		itemName = updateItem.JsonnetPath
+	case configuration.TargetTypeHelmfile:
+		itemName = updateItem.ReleaseName
+		if updateItem.ValuePath != "" {
+			itemName += " " + updateItem.ValuePath
+		}
+	case configuration.TargetTypeCargoToml:
+		itemName = updateItem.CrateName
+	case configuration.TargetTypeMavenPom:
+		itemName = updateItem.Artifact
+		if itemName == "" {
+			itemName = updateItem.Property
+		}
+	case configuration.TargetTypeGradleCatalog:
+		itemName = updateItem.CatalogEntry
+	case configuration.TargetTypeMakeVariable:
+		itemName = updateItem.VariableName
+	case configuration.TargetTypeToolVersions:
+		itemName = updateItem.ToolName
+	case configuration.TargetTypeDevcontainer, configuration.TargetTypeCIImage:
+		itemName = updateItem.Image
 	}
 
 	// Determine patch group - use item's patch group if set, otherwise use target's patch group
@@ -97,6 +160,24 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 		patchGroup = targetConfig.PatchGroup
 	}
 
+	// Determine update policy - use item's maxUpdateType if set, otherwise use target's
+	maxUpdateType := UpdateType(updateItem.MaxUpdateType)
+	if maxUpdateType == "" {
+		maxUpdateType = UpdateType(targetConfig.MaxUpdateType)
+	}
+
+	// Determine track - use item's track if set, otherwise use target's track
+	track := updateItem.Track
+	if track == "" {
+		track = targetConfig.Track
+	}
+
+	// Determine version set - use item's version set if set, otherwise use target's
+	versionSet := updateItem.VersionSet
+	if versionSet == "" {
+		versionSet = targetConfig.VersionSet
+	}
+
 	result := &ComparisonResult{
 		TargetName:      targetName,
 		TargetFile:      targetConfig.File,
@@ -106,6 +187,10 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 		IsWildcardMatch: targetConfig.IsWildcardMatch,
 		WildcardPattern: targetConfig.WildcardPattern,
 		PatchGroup:      patchGroup,
+		Track:           track,
+		MaxUpdateType:   maxUpdateType,
+		VersionSet:      versionSet,
+		Stage:           targetConfig.Stage,
 	}
 
 	log.Debug().
@@ -134,9 +219,83 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 		return result
 	}
 
-	// Get latest version from source (first version is the latest)
-	latestVersion := source.Versions[0]
-	result.LatestVersion = latestVersion.Version
+	// Restrict versions to the subscribed track
+	versions, err := e.trackVersions(source, track)
+	if err != nil {
+		result.Error = err
+		log.Error().
+			Err(err).
+			Str("target", targetName).
+			Str("track", track).
+			Msg("Failed to resolve track versions")
+		return result
+	}
+	if len(versions) == 0 {
+		result.Error = fmt.Errorf("no versions available for track '%s' of source '%s'", track, updateItem.Source)
+		log.Warn().
+			Str("target", targetName).
+			Str("source", updateItem.Source).
+			Str("track", track).
+			Msg("No versions available for track")
+		return result
+	}
+
+	// Strip the source version format so versions can be compared as bare versions
+	sourceFormat, err := newSourceVersionFormat(source)
+	if err != nil {
+		result.Error = err
+		log.Error().
+			Err(err).
+			Str("target", targetName).
+			Str("source", updateItem.Source).
+			Msg("Invalid source version format")
+		return result
+	}
+	versions = sourceFormat.bareVersions(versions)
+	if len(versions) == 0 {
+		result.Error = fmt.Errorf("no versions of source '%s' match its version format", updateItem.Source)
+		log.Warn().
+			Str("target", targetName).
+			Str("source", updateItem.Source).
+			Msg("No versions match source version format")
+		return result
+	}
+
+	// Order the versions newest first instead of trusting the order of the source
+	versions, err = orderVersions(source, versions)
+	if err != nil {
+		result.Error = err
+		log.Error().
+			Err(err).
+			Str("target", targetName).
+			Str("source", updateItem.Source).
+			Msg("Invalid source version constraint")
+		return result
+	}
+	if len(versions) == 0 {
+		result.Error = fmt.Errorf("no versions of source '%s' satisfy its version constraint", updateItem.Source)
+		log.Warn().
+			Str("target", targetName).
+			Str("source", updateItem.Source).
+			Msg("No versions satisfy source version constraint")
+		return result
+	}
+
+	// Determine target version format - use item's format if set, otherwise use target's
+	targetFormat, err := newTargetVersionFormat(targetConfig, updateItem)
+	if err != nil {
+		result.Error = err
+		log.Error().
+			Err(err).
+			Str("target", targetName).
+			Msg("Invalid target version format")
+		return result
+	}
+	targetFormat.image = e.sourceImage(source)
+
+	// Get latest version from source (first version is the latest after ordering)
+	latestVersion := versions[0]
+	result.LatestVersion = targetFormat.format("", latestVersion.Version)
 
 	// Create target client
 	targetClient, err := e.targetFactory.CreateTargetForUpdateItem(targetConfig, updateItem)
@@ -173,8 +332,38 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 	}
 	result.CurrentVersion = currentVersion
 
+	// Strip the target version format from the current version
+	bareCurrent, ok := targetFormat.extract(currentVersion)
+	if !ok {
+		result.Error = fmt.Errorf("current version '%s' does not match the target version format", currentVersion)
+		log.Error().
+			Str("target", targetName).
+			Str("current", currentVersion).
+			Msg("Current version does not match target version format")
+		return result
+	}
+	result.LatestVersion = targetFormat.format(currentVersion, latestVersion.Version)
+
+	// Compare the version running in the cluster instead, keeping the file version to report drift
+	if targetConfig.ReadFrom == configuration.TargetReadFromCluster {
+		deployed, bareDeployed, err := e.readDeployedVersion(targetConfig, updateItem, targetFormat)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to read deployed version: %w", err)
+			log.Error().
+				Err(err).
+				Str("target", targetName).
+				Msg("Failed to read deployed version")
+			return result
+		}
+		result.FileVersion = currentVersion
+		result.CurrentVersion = deployed
+		result.Drifted = normalizeVersion(bareDeployed) != normalizeVersion(bareCurrent)
+		bareCurrent = bareDeployed
+	}
+	result.bareCurrentVersion = bareCurrent
+
 	// Normalize versions for comparison (remove v prefix)
-	normalizedCurrent := normalizeVersion(currentVersion)
+	normalizedCurrent := normalizeVersion(bareCurrent)
 	normalizedLatest := normalizeVersion(latestVersion.Version)
 
 	// Determine if update is needed and what type
@@ -188,33 +377,76 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 	} else {
 		// Try to find current version in source versions to get semantic version info
 		var currentSemVer *configuration.PackageSourceVersion
-		for _, v := range source.Versions {
+		for _, v := range versions {
 			if normalizeVersion(v.Version) == normalizedCurrent {
 				currentSemVer = v
 				break
 			}
 		}
 
-		// If current version not found in source, try to parse it
+		// If current version not found in source, try to parse it and find out why it is missing
 		if currentSemVer == nil {
-			currentSemVer = parseVersionString(currentVersion)
+			currentSemVer = parseVersionString(bareCurrent)
+			result.UnknownVersion = e.diagnoseUnknownVersion(source, track, sourceFormat, currentSemVer, versions)
+			log.Debug().
+				Str("target", targetName).
+				Str("current", currentVersion).
+				Str("diagnostic", result.UnknownVersion).
+				Msg("Current version not found in source versions")
 		}
 
-		result.UpdateType = determineUpdateType(currentSemVer, latestVersion)
-		// Only mark as needing update if it's actually an upgrade, not a downgrade
-		result.NeedsUpdate = result.UpdateType != UpdateTypeNone
-		if result.NeedsUpdate {
+		result.UpdateType = determineUpdateType(source.VersionScheme, currentSemVer, latestVersion)
+
+		// Hold back updates exceeding the policy and fall back to the newest allowed version
+		if maxUpdateType != "" && updateTypeRank(result.UpdateType) > updateTypeRank(maxUpdateType) {
+			result.HeldBackVersion = result.LatestVersion
+			result.HeldBackType = result.UpdateType
+			result.LatestVersion = currentVersion
+			result.UpdateType = UpdateTypeNone
+			for _, v := range versions {
+				updateType := determineUpdateType(source.VersionScheme, currentSemVer, v)
+				if isUpdate(updateType) && updateTypeRank(updateType) <= updateTypeRank(maxUpdateType) {
+					latestVersion = v
+					result.LatestVersion = targetFormat.format(currentVersion, v.Version)
+					result.UpdateType = updateType
+					break
+				}
+			}
 			log.Debug().
 				Str("target", targetConfig.Name).
+				Str("heldBack", result.HeldBackVersion).
+				Str("maxUpdateType", string(maxUpdateType)).
+				Msg("Update exceeds maxUpdateType, holding back")
+		}
+
+		// Keep what is needed to cap the update later, e.g. by a rollout stage
+		result.bareLatestVersion = latestVersion.Version
+		result.versions = versions
+		result.currentSemVer = currentSemVer
+		result.versionScheme = source.VersionScheme
+		result.targetFormat = targetFormat
+
+		// Only mark as needing update if it's actually an upgrade, not a downgrade. An update
+		// already in the file but not deployed yet has nothing left to write.
+		result.NeedsUpdate = isUpdate(result.UpdateType) && result.LatestVersion != result.FileVersion
+		if result.UpdateType == UpdateTypeDowngrade {
+			log.Warn().
+				Str("target", targetName).
 				Str("current", currentVersion).
-				Str("latest", latestVersion.Version).
+				Str("latest", result.LatestVersion).
+				Msg("Current version is newer than the latest available version")
+		} else if result.NeedsUpdate {
+			log.Debug().
+				Str("target", targetConfig.Name).
+				Str("current", currentVersion).
+				Str("latest", result.LatestVersion).
 				Str("updateType", string(result.UpdateType)).
 				Msg("Update available")
 		} else {
 			log.Debug().
 				Str("target", targetConfig.Name).
 				Str("current", currentVersion).
-				Str("latest", latestVersion.Version).
+				Str("latest", result.LatestVersion).
 				Msg("Latest version is not newer than current, skipping")
 		}
 	}
@@ -222,6 +454,52 @@ func (e *CompareEngine) compareTargetUpdateItem(targetConfig *configuration.Targ
 	return result
 }
 
+// readDeployedVersion reads the version of a target item running in the cluster and strips
+// the target version format. Chart versions and, with an extract pattern, whole image
+// references are matched against the format, other images by their tag.
+func (e *CompareEngine) readDeployedVersion(targetConfig *configuration.Target, updateItem *configuration.TargetItem, targetFormat *targetVersionFormat) (string, string, error) {
+	ref := configuration.ResolveClusterReference(targetConfig, updateItem)
+	if ref == nil {
+		return "", "", fmt.Errorf("no cluster resource configured")
+	}
+
+	deployed, err := e.clusterReader.ReadVersion(ref)
+	if err != nil {
+		return "", "", err
+	}
+	if kind, _, _ := configuration.ParseClusterResource(ref.Resource); kind == configuration.ClusterKindHelmRelease || targetFormat.extractPattern != nil {
+		if bare, ok := targetFormat.extract(deployed); ok {
+			return deployed, bare, nil
+		}
+	}
+	tag := cluster.ImageTag(deployed)
+	if bare, ok := targetFormat.extract(tag); ok {
+		return tag, bare, nil
+	}
+	return "", "", fmt.Errorf("deployed version '%s' does not match the target version format", deployed)
+}
+
+// sourceImage returns the image name deployments reference for a docker source, mapped
+// through the registry map, or an empty string for other sources
+func (e *CompareEngine) sourceImage(source *configuration.PackageSource) string {
+	if source.Type != configuration.PackageSourceTypeDockerImage {
+		return ""
+	}
+	baseURL := ""
+	for _, provider := range e.config.PackageSourceProviders {
+		if provider.Name == source.Provider {
+			baseURL = provider.BaseUrl
+			break
+		}
+	}
+	image, err := docker.BuildImageName(baseURL, source.URI)
+	if err != nil {
+		log.Debug().Err(err).Str("source", source.Name).Msg("Failed to resolve image of source")
+		return ""
+	}
+	return configuration.MapImage(e.config.RegistryMap, image)
+}
+
 // findSource finds a source by name
 func (e *CompareEngine) findSource(name string) *configuration.PackageSource {
 	for _, source := range e.config.PackageSources {
@@ -232,6 +510,59 @@ func (e *CompareEngine) findSource(name string) *configuration.PackageSource {
 	return nil
 }
 
+// trackVersions returns the source versions that belong to the given track, preserving order.
+// An empty track returns all versions. The tagPattern of each track is compiled once.
+func (e *CompareEngine) trackVersions(source *configuration.PackageSource, trackName string) ([]*configuration.PackageSourceVersion, error) {
+	if trackName == "" {
+		return source.Versions, nil
+	}
+
+	track := source.FindTrack(trackName)
+	if track == nil {
+		return nil, fmt.Errorf("track '%s' not defined on source '%s'", trackName, source.Name)
+	}
+
+	pattern, ok := e.trackPatterns[track]
+	if !ok {
+		var err error
+		pattern, err = configuration.CompilePattern(track.TagPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tagPattern for track '%s': %w", trackName, err)
+		}
+		e.trackPatterns[track] = pattern
+	}
+
+	versions := make([]*configuration.PackageSourceVersion, 0)
+	for _, v := range source.Versions {
+		if pattern.MatchString(v.Version) {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+// orderVersions sorts bare source versions newest first by the sort order of the source and
+// drops versions outside its version constraint. Scrapers already do so, but sources loaded
+// from a file or listing tags alphabetically must not yield a wrong latest version.
+func orderVersions(source *configuration.PackageSource, versions []*configuration.PackageSourceVersion) ([]*configuration.PackageSourceVersion, error) {
+	sorted := configuration.SortVersions(versions, source)
+	if source.VersionConstraint == "" {
+		return sorted, nil
+	}
+
+	constraint, err := configuration.ParseVersionConstraint(source.VersionConstraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid versionConstraint of source '%s': %w", source.Name, err)
+	}
+	allowed := make([]*configuration.PackageSourceVersion, 0, len(sorted))
+	for _, version := range sorted {
+		if constraint.Allows(version) {
+			allowed = append(allowed, version)
+		}
+	}
+	return allowed, nil
+}
+
 // normalizeVersion removes the "v" or "V" prefix from a version string for comparison
 func normalizeVersion(version string) string {
 	normalized := strings.TrimPrefix(version, "v")
@@ -245,16 +576,32 @@ func parseVersionString(version string) *configuration.PackageSourceVersion {
 		Version: version,
 	}
 	v.MajorVersion, v.MinorVersion, v.PatchVersion = configuration.ParseSemver(version)
+	v.BuildVersion, v.Revision = configuration.ParseBuild(version)
 	return v
 }
 
-// determineUpdateType determines the type of update (major, minor, patch)
-func determineUpdateType(current, latest *configuration.PackageSourceVersion) UpdateType {
+// determineUpdateType determines the type of update (major, minor, patch). With the loose
+// version scheme the loose comparison decides whether latest is newer, and the semver
+// components only how big the update is, defaulting to patch.
+func determineUpdateType(versionScheme string, current, latest *configuration.PackageSourceVersion) UpdateType {
 	if current == nil || latest == nil {
 		// If we can't parse versions, we can't determine type
 		return UpdateTypePatch
 	}
 
+	if versionScheme == configuration.VersionSchemeLoose {
+		switch comparison := configuration.CompareLooseVersions(latest.Version, current.Version); {
+		case comparison < 0:
+			return UpdateTypeDowngrade
+		case comparison == 0:
+			return UpdateTypeNone
+		}
+		if updateType := determineUpdateType("", current, latest); isUpdate(updateType) {
+			return updateType
+		}
+		return UpdateTypePatch
+	}
+
 	// If both versions have all-zero semver fields but different version strings,
 	// they are non-semver versions. Treat as patch update so filters don't skip them.
 	if current.MajorVersion == 0 && current.MinorVersion == 0 && current.PatchVersion == 0 &&
@@ -265,25 +612,87 @@ func determineUpdateType(current, latest *configuration.PackageSourceVersion) Up
 		return UpdateTypeNone
 	}
 
+	// A latest version without semver fields cannot be ordered against a semver current version
+	if latest.MajorVersion == 0 && latest.MinorVersion == 0 && latest.PatchVersion == 0 {
+		return UpdateTypeNone
+	}
+
 	if latest.MajorVersion > current.MajorVersion {
 		return UpdateTypeMajor
 	}
 	if latest.MajorVersion < current.MajorVersion {
-		return UpdateTypeNone // Downgrade
+		return UpdateTypeDowngrade
 	}
 
 	if latest.MinorVersion > current.MinorVersion {
 		return UpdateTypeMinor
 	}
 	if latest.MinorVersion < current.MinorVersion {
-		return UpdateTypeNone // Downgrade
+		return UpdateTypeDowngrade
 	}
 
-	if latest.PatchVersion > current.PatchVersion {
+	// A newer build number or revision of the same version, e.g. 1.2.3.5 or 7.4.0-2023-12-01,
+	// is a patch update
+	switch comparison := configuration.CompareVersions(latest, current); {
+	case comparison > 0:
 		return UpdateTypePatch
+	case comparison < 0:
+		return UpdateTypeDowngrade
+	default:
+		return UpdateTypeNone
+	}
+}
+
+// isUpdate reports whether the update type moves to a newer version
+func isUpdate(updateType UpdateType) bool {
+	return updateTypeRank(updateType) > 0
+}
+
+// updateTypeRank orders update types by impact so policies can be compared
+func updateTypeRank(updateType UpdateType) int {
+	switch updateType {
+	case UpdateTypePatch:
+		return 1
+	case UpdateTypeMinor:
+		return 2
+	case UpdateTypeMajor:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// markInconsistentVersionSets flags all results of a version set whose items currently
+// hold different versions, so drift between linked files becomes visible
+func markInconsistentVersionSets(results []*ComparisonResult) {
+	sets := make(map[string][]*ComparisonResult)
+	for _, result := range results {
+		if result.VersionSet == "" || result.Error != nil {
+			continue
+		}
+		sets[result.VersionSet] = append(sets[result.VersionSet], result)
 	}
 
-	return UpdateTypeNone
+	for name, members := range sets {
+		inconsistent := false
+		for _, member := range members[1:] {
+			if normalizeVersion(member.bareCurrentVersion) != normalizeVersion(members[0].bareCurrentVersion) {
+				inconsistent = true
+				break
+			}
+		}
+		if !inconsistent {
+			continue
+		}
+
+		log.Warn().
+			Str("versionSet", name).
+			Int("items", len(members)).
+			Msg("Version set items hold different versions")
+		for _, member := range members {
+			member.VersionSetInconsistent = true
+		}
+	}
 }
 
 // countNeedingUpdate counts how many results need an update
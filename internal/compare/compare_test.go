@@ -0,0 +1,662 @@
+package compare
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// newVersions returns source versions parsed from version strings, newest first
+func newVersions(versions ...string) []*configuration.PackageSourceVersion {
+	parsed := make([]*configuration.PackageSourceVersion, 0, len(versions))
+	for _, version := range versions {
+		parsed = append(parsed, parseVersionString(version))
+	}
+	return parsed
+}
+
+// newTerraformTarget writes a variables file with an app_version variable holding the
+// current version and returns a target reading it from the given source
+func newTerraformTarget(t *testing.T, name string, current string, source string) *configuration.Target {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "variables.tf")
+	content := fmt.Sprintf("variable \"app_version\" {\n  default = \"%s\"\n}\n", current)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write target file: %v", err)
+	}
+	return &configuration.Target{
+		Name: name,
+		Type: configuration.TargetTypeTerraformVariable,
+		File: file,
+		Items: []configuration.TargetItem{
+			{TerraformVariableName: "app_version", Source: source},
+		},
+	}
+}
+
+// compareTargets compares the targets against the sources and fails on comparison errors
+func compareTargets(t *testing.T, sources []*configuration.PackageSource, targets ...*configuration.Target) []*ComparisonResult {
+	t.Helper()
+	engine := NewCompareEngine(&configuration.Config{PackageSources: sources, Targets: targets})
+	results, err := engine.CompareAll()
+	if err != nil {
+		t.Fatalf("CompareAll() failed: %v", err)
+	}
+	return results
+}
+
+func TestCompareAll_Track(t *testing.T) {
+	source := &configuration.PackageSource{
+		Name:     "app",
+		Versions: newVersions("3.0.0", "2.4.1-lts", "2.4.0-lts", "1.9.0-lts"),
+		Tracks: []*configuration.PackageSourceTrack{
+			{Name: "lts", TagPattern: `-lts$`},
+			{Name: "invalid", TagPattern: `(`},
+			{Name: "none", TagPattern: `-beta$`},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		current       string
+		targetTrack   string
+		itemTrack     string
+		expected      string
+		needsUpdate   bool
+		errorContains string
+	}{
+		{
+			name:        "no track follows the overall latest version",
+			current:     "2.4.0-lts",
+			expected:    "3.0.0",
+			needsUpdate: true,
+		},
+		{
+			name:        "target track restricts versions",
+			current:     "2.4.0-lts",
+			targetTrack: "lts",
+			expected:    "2.4.1-lts",
+			needsUpdate: true,
+		},
+		{
+			name:        "item track overrides target track",
+			current:     "2.4.0-lts",
+			targetTrack: "none",
+			itemTrack:   "lts",
+			expected:    "2.4.1-lts",
+			needsUpdate: true,
+		},
+		{
+			name:        "up to date on track",
+			current:     "2.4.1-lts",
+			targetTrack: "lts",
+			expected:    "2.4.1-lts",
+		},
+		{
+			name:          "undefined track",
+			current:       "2.4.0-lts",
+			targetTrack:   "stable",
+			errorContains: "track 'stable' not defined",
+		},
+		{
+			name:          "invalid track pattern",
+			current:       "2.4.0-lts",
+			targetTrack:   "invalid",
+			errorContains: "invalid tagPattern",
+		},
+		{
+			name:          "no versions on track",
+			current:       "2.4.0-lts",
+			targetTrack:   "none",
+			errorContains: "no versions available for track 'none'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newTerraformTarget(t, "app", tt.current, "app")
+			target.Track = tt.targetTrack
+			target.Items[0].Track = tt.itemTrack
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if tt.errorContains != "" {
+				if result.Error == nil || !strings.Contains(result.Error.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, result.Error)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.LatestVersion != tt.expected || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected %s (needsUpdate %v), got %s (needsUpdate %v)", tt.expected, tt.needsUpdate, result.LatestVersion, result.NeedsUpdate)
+			}
+		})
+	}
+}
+
+func TestTrackVersions_CompilesPatternOnce(t *testing.T) {
+	source := &configuration.PackageSource{
+		Name:     "app",
+		Versions: newVersions("2.0.0", "1.1.0-lts", "1.0.0-lts"),
+		Tracks:   []*configuration.PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}},
+	}
+	engine := NewCompareEngine(&configuration.Config{PackageSources: []*configuration.PackageSource{source}})
+
+	for i := 0; i < 3; i++ {
+		versions, err := engine.trackVersions(source, "lts")
+		if err != nil {
+			t.Fatalf("trackVersions() failed: %v", err)
+		}
+		if len(versions) != 2 || versions[0].Version != "1.1.0-lts" {
+			t.Errorf("unexpected track versions: %v", versions)
+		}
+	}
+	if len(engine.trackPatterns) != 1 {
+		t.Errorf("expected 1 compiled track pattern, got %d", len(engine.trackPatterns))
+	}
+}
+
+func TestCompareAll_MaxUpdateType(t *testing.T) {
+	source := &configuration.PackageSource{
+		Name:     "app",
+		Versions: newVersions("3.0.0", "2.5.0", "2.4.3", "2.4.2", "2.4.1"),
+	}
+
+	tests := []struct {
+		name            string
+		current         string
+		targetMax       string
+		itemMax         string
+		expected        string
+		expectedType    UpdateType
+		needsUpdate     bool
+		heldBackVersion string
+		heldBackType    UpdateType
+	}{
+		{
+			name:         "unrestricted proposes the latest version",
+			current:      "2.4.1",
+			expected:     "3.0.0",
+			expectedType: UpdateTypeMajor,
+			needsUpdate:  true,
+		},
+		{
+			name:            "minor falls back to the newest minor update",
+			current:         "2.4.1",
+			targetMax:       "minor",
+			expected:        "2.5.0",
+			expectedType:    UpdateTypeMinor,
+			needsUpdate:     true,
+			heldBackVersion: "3.0.0",
+			heldBackType:    UpdateTypeMajor,
+		},
+		{
+			name:            "patch falls back to the newest patch update",
+			current:         "2.4.1",
+			targetMax:       "patch",
+			expected:        "2.4.3",
+			expectedType:    UpdateTypePatch,
+			needsUpdate:     true,
+			heldBackVersion: "3.0.0",
+			heldBackType:    UpdateTypeMajor,
+		},
+		{
+			name:            "item overrides target policy",
+			current:         "2.4.1",
+			targetMax:       "major",
+			itemMax:         "patch",
+			expected:        "2.4.3",
+			expectedType:    UpdateTypePatch,
+			needsUpdate:     true,
+			heldBackVersion: "3.0.0",
+			heldBackType:    UpdateTypeMajor,
+		},
+		{
+			name:            "no allowed update keeps the current version",
+			current:         "2.4.3",
+			targetMax:       "patch",
+			expected:        "2.4.3",
+			expectedType:    UpdateTypeNone,
+			heldBackVersion: "3.0.0",
+			heldBackType:    UpdateTypeMajor,
+		},
+		{
+			name:         "update within the policy is not held back",
+			current:      "2.5.0",
+			targetMax:    "major",
+			expected:     "3.0.0",
+			expectedType: UpdateTypeMajor,
+			needsUpdate:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newTerraformTarget(t, "app", tt.current, "app")
+			target.MaxUpdateType = tt.targetMax
+			target.Items[0].MaxUpdateType = tt.itemMax
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.LatestVersion != tt.expected || result.UpdateType != tt.expectedType || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected %s (%s, needsUpdate %v), got %s (%s, needsUpdate %v)",
+					tt.expected, tt.expectedType, tt.needsUpdate, result.LatestVersion, result.UpdateType, result.NeedsUpdate)
+			}
+			if result.HeldBackVersion != tt.heldBackVersion || result.HeldBackType != tt.heldBackType {
+				t.Errorf("expected held back %q (%s), got %q (%s)", tt.heldBackVersion, tt.heldBackType, result.HeldBackVersion, result.HeldBackType)
+			}
+		})
+	}
+}
+
+func TestCompareAll_VersionOrdering(t *testing.T) {
+	tests := []struct {
+		name         string
+		versions     []string
+		sortBy       string
+		constraint   string
+		current      string
+		maxUpdate    string
+		expected     string
+		expectedType UpdateType
+	}{
+		{
+			name:         "unsorted versions",
+			versions:     []string{"1.9.0", "2.1.0", "1.10.0", "2.0.0"},
+			current:      "1.9.0",
+			expected:     "2.1.0",
+			expectedType: UpdateTypeMajor,
+		},
+		{
+			name:         "alphabetically sorted versions",
+			versions:     []string{"1.9.0", "1.10.0", "1.1.0"},
+			current:      "1.1.0",
+			expected:     "1.10.0",
+			expectedType: UpdateTypeMinor,
+		},
+		{
+			name:         "alphabetical sort order is honoured",
+			versions:     []string{"build-a", "build-c", "build-b"},
+			sortBy:       "alphabetical",
+			current:      "build-a",
+			expected:     "build-c",
+			expectedType: UpdateTypePatch,
+		},
+		{
+			name:         "date sort order is kept",
+			versions:     []string{"1.0.1", "2.0.0"},
+			sortBy:       "date",
+			current:      "1.0.0",
+			expected:     "1.0.1",
+			expectedType: UpdateTypePatch,
+		},
+		{
+			name:         "version constraint is applied",
+			versions:     []string{"2.0.0", "1.2.0", "1.3.0"},
+			constraint:   "<2.0.0",
+			current:      "1.2.0",
+			expected:     "1.3.0",
+			expectedType: UpdateTypeMinor,
+		},
+		{
+			name:         "four-part versions",
+			versions:     []string{"1.2.3.10", "1.2.3.9", "1.2.3.4"},
+			current:      "1.2.3.4",
+			expected:     "1.2.3.10",
+			expectedType: UpdateTypePatch,
+		},
+		{
+			name:         "date revisions",
+			versions:     []string{"7.4.0-2023-11-01", "7.4.0-2023-12-01"},
+			current:      "7.4.0-2023-11-01",
+			expected:     "7.4.0-2023-12-01",
+			expectedType: UpdateTypePatch,
+		},
+		{
+			name:         "held back update falls back to the newest allowed version",
+			versions:     []string{"1.2.1", "2.0.0", "1.2.3", "1.2.2"},
+			current:      "1.2.1",
+			maxUpdate:    "patch",
+			expected:     "1.2.3",
+			expectedType: UpdateTypePatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &configuration.PackageSource{
+				Name:              "app",
+				SortBy:            tt.sortBy,
+				VersionConstraint: tt.constraint,
+				Versions:          newVersions(tt.versions...),
+			}
+			target := newTerraformTarget(t, "app", tt.current, "app")
+			target.MaxUpdateType = tt.maxUpdate
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.LatestVersion != tt.expected || result.UpdateType != tt.expectedType || !result.NeedsUpdate {
+				t.Errorf("expected %s (%s), got %s (%s, needsUpdate %v)", tt.expected, tt.expectedType, result.LatestVersion, result.UpdateType, result.NeedsUpdate)
+			}
+		})
+	}
+}
+
+func TestCompareAll_LooseVersionScheme(t *testing.T) {
+	tests := []struct {
+		name         string
+		versions     []string
+		current      string
+		expected     string
+		expectedType UpdateType
+		needsUpdate  bool
+	}{
+		{
+			name:         "build numbers",
+			versions:     []string{"r987", "r1095", "r1001"},
+			current:      "r987",
+			expected:     "r1095",
+			expectedType: UpdateTypePatch,
+			needsUpdate:  true,
+		},
+		{
+			name:         "timestamps",
+			versions:     []string{"RELEASE.2023-10-25T06-33-25Z", "RELEASE.2023-11-01T01-57-10Z"},
+			current:      "RELEASE.2023-10-25T06-33-25Z",
+			expected:     "RELEASE.2023-11-01T01-57-10Z",
+			expectedType: UpdateTypePatch,
+			needsUpdate:  true,
+		},
+		{
+			name:         "epoch",
+			versions:     []string{"2.0.0", "1:1.0.0"},
+			current:      "2.0.0",
+			expected:     "1:1.0.0",
+			expectedType: UpdateTypePatch,
+			needsUpdate:  true,
+		},
+		{
+			name:         "semver components determine the update type",
+			versions:     []string{"2.1.0", "2.0.0"},
+			current:      "2.0.0",
+			expected:     "2.1.0",
+			expectedType: UpdateTypeMinor,
+			needsUpdate:  true,
+		},
+		{
+			name:         "up to date",
+			versions:     []string{"r1095", "r987"},
+			current:      "r1095",
+			expected:     "r1095",
+			expectedType: UpdateTypeNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &configuration.PackageSource{
+				Name:          "app",
+				VersionScheme: configuration.VersionSchemeLoose,
+				Versions:      newVersions(tt.versions...),
+			}
+			target := newTerraformTarget(t, "app", tt.current, "app")
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.LatestVersion != tt.expected || result.UpdateType != tt.expectedType || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected %s (%s, needsUpdate %v), got %s (%s, needsUpdate %v)",
+					tt.expected, tt.expectedType, tt.needsUpdate, result.LatestVersion, result.UpdateType, result.NeedsUpdate)
+			}
+		})
+	}
+}
+
+func TestCompareAll_Downgrade(t *testing.T) {
+	tests := []struct {
+		name         string
+		versions     []string
+		scheme       string
+		current      string
+		expectedType UpdateType
+	}{
+		{name: "major", versions: []string{"1.9.0"}, current: "2.0.0", expectedType: UpdateTypeDowngrade},
+		{name: "minor", versions: []string{"1.9.0"}, current: "1.10.0", expectedType: UpdateTypeDowngrade},
+		{name: "patch", versions: []string{"1.9.0"}, current: "1.9.1", expectedType: UpdateTypeDowngrade},
+		{name: "build number", versions: []string{"1.9.0.1"}, current: "1.9.0.2", expectedType: UpdateTypeDowngrade},
+		{name: "loose", versions: []string{"r987"}, scheme: configuration.VersionSchemeLoose, current: "r1095", expectedType: UpdateTypeDowngrade},
+		{name: "non-semver latest", versions: []string{"stable"}, current: "1.9.0", expectedType: UpdateTypeNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &configuration.PackageSource{Name: "app", VersionScheme: tt.scheme, Versions: newVersions(tt.versions...)}
+			target := newTerraformTarget(t, "app", tt.current, "app")
+
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.UpdateType != tt.expectedType || result.NeedsUpdate {
+				t.Errorf("expected %s without update, got %s (needsUpdate %v)", tt.expectedType, result.UpdateType, result.NeedsUpdate)
+			}
+			if result.LatestVersion != tt.versions[0] {
+				t.Errorf("expected latest version %s, got %s", tt.versions[0], result.LatestVersion)
+			}
+		})
+	}
+}
+
+func TestCompareAll_UnknownVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   *configuration.PackageSource
+		track    string
+		current  string
+		expected string
+	}{
+		{
+			name:     "known version",
+			source:   &configuration.PackageSource{Versions: newVersions("1.3.0", "1.2.0")},
+			current:  "1.2.0",
+			expected: "",
+		},
+		{
+			name:     "tag pattern",
+			source:   &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+$`, Versions: newVersions("1.3.0", "1.2.0")},
+			current:  "1.2.1-rc.1",
+			expected: "current version 1.2.1-rc.1 does not match the tagPattern of source 'app'; nearest versions: 1.3.0, 1.2.0",
+		},
+		{
+			name:     "exclude pattern with version prefix",
+			source:   &configuration.PackageSource{VersionPrefix: "v", ExcludePattern: `-rc`, Versions: newVersions("v1.3.0", "v1.2.0")},
+			current:  "1.2.1-rc.1",
+			expected: "current version v1.2.1-rc.1 is excluded by the excludePattern of source 'app'; nearest versions: 1.3.0, 1.2.0",
+		},
+		{
+			name: "track",
+			source: &configuration.PackageSource{
+				Versions: newVersions("2.0.0", "1.3.0-lts"),
+				Tracks:   []*configuration.PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}},
+			},
+			track:    "lts",
+			current:  "1.2.0",
+			expected: "current version 1.2.0 is not on track 'lts'; nearest versions: 1.3.0-lts",
+		},
+		{
+			name:     "version constraint",
+			source:   &configuration.PackageSource{VersionConstraint: ">=1.2.0", Versions: newVersions("1.3.0", "1.2.0")},
+			current:  "1.1.0",
+			expected: "current version is outside the versionConstraint '>=1.2.0' of source 'app'; nearest versions: 1.2.0",
+		},
+		{
+			name:     "limit",
+			source:   &configuration.PackageSource{Versions: newVersions("1.3.0", "1.2.0")},
+			current:  "1.1.0",
+			expected: "current version is older than the oldest of the 2 versions kept of source 'app' (1.2.0), the version limit may be too low; nearest versions: 1.2.0",
+		},
+		{
+			name:     "deleted upstream",
+			source:   &configuration.PackageSource{Versions: newVersions("1.3.0", "1.2.0")},
+			current:  "1.2.5",
+			expected: "current version was not found upstream in source 'app', it may have been deleted; nearest versions: 1.3.0, 1.2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.source.Name = "app"
+			target := newTerraformTarget(t, "app", tt.current, "app")
+			target.Track = tt.track
+
+			result := compareTargets(t, []*configuration.PackageSource{tt.source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.UnknownVersion != tt.expected {
+				t.Errorf("expected diagnostic %q, got %q", tt.expected, result.UnknownVersion)
+			}
+		})
+	}
+}
+
+func TestCompareAll_VersionSetConsistency(t *testing.T) {
+	source := &configuration.PackageSource{Name: "app", Versions: newVersions("2.0.0", "1.1.0", "1.0.0")}
+
+	tests := []struct {
+		name                 string
+		frontend             string
+		backend              string
+		frontendSet          string
+		backendSet           string
+		expectedInconsistent bool
+	}{
+		{
+			name:        "same versions are consistent",
+			frontend:    "1.0.0",
+			backend:     "1.0.0",
+			frontendSet: "app",
+			backendSet:  "app",
+		},
+		{
+			name:                 "different versions are inconsistent",
+			frontend:             "1.0.0",
+			backend:              "1.1.0",
+			frontendSet:          "app",
+			backendSet:           "app",
+			expectedInconsistent: true,
+		},
+		{
+			name:        "v prefix is ignored",
+			frontend:    "v1.0.0",
+			backend:     "1.0.0",
+			frontendSet: "app",
+			backendSet:  "app",
+		},
+		{
+			name:        "different sets are not compared",
+			frontend:    "1.0.0",
+			backend:     "1.1.0",
+			frontendSet: "frontend",
+			backendSet:  "backend",
+		},
+		{
+			name:     "items without set are not compared",
+			frontend: "1.0.0",
+			backend:  "1.1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontend := newTerraformTarget(t, "frontend", tt.frontend, "app")
+			frontend.VersionSet = tt.frontendSet
+			backend := newTerraformTarget(t, "backend", tt.backend, "app")
+			backend.Items[0].VersionSet = tt.backendSet
+
+			results := compareTargets(t, []*configuration.PackageSource{source}, frontend, backend)
+			for _, result := range results {
+				if result.Error != nil {
+					t.Fatalf("unexpected error: %v", result.Error)
+				}
+				if result.VersionSetInconsistent != tt.expectedInconsistent {
+					t.Errorf("%s: expected VersionSetInconsistent %v, got %v", result.TargetName, tt.expectedInconsistent, result.VersionSetInconsistent)
+				}
+			}
+			if results[1].VersionSet != tt.backendSet {
+				t.Errorf("expected item version set %q, got %q", tt.backendSet, results[1].VersionSet)
+			}
+		})
+	}
+}
+
+func TestMarkInconsistentVersionSets_SkipsErrors(t *testing.T) {
+	results := []*ComparisonResult{
+		{TargetName: "a", VersionSet: "app", bareCurrentVersion: "1.0.0"},
+		{TargetName: "b", VersionSet: "app", Error: fmt.Errorf("failed to read current version")},
+		{TargetName: "c", VersionSet: "app", bareCurrentVersion: "1.0.0"},
+	}
+	markInconsistentVersionSets(results)
+	for _, result := range results {
+		if result.VersionSetInconsistent {
+			t.Errorf("%s: failed items must not make a version set inconsistent", result.TargetName)
+		}
+	}
+}
+
+func TestCompareAll_WriteTemplateImage(t *testing.T) {
+	sources := []*configuration.PackageSource{
+		{Name: "nginx", Provider: "dockerhub", Type: configuration.PackageSourceTypeDockerImage, URI: "nginx", Versions: newVersions("1.27.0", "1.26.0")},
+	}
+
+	tests := []struct {
+		name        string
+		registryMap map[string]string
+		expected    string
+	}{
+		{
+			name:     "upstream image",
+			expected: "docker.io/library/nginx:1.27.0",
+		},
+		{
+			name:        "mirror image",
+			registryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub"},
+			expected:    "mirror.example.com/dockerhub/library/nginx:1.27.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newTerraformTarget(t, "nginx", "nginx:1.26.0", "nginx")
+			target.ExtractPattern = `:(?P<version>[^:]+)$`
+			target.WriteTemplate = "{{image}}:{{version}}"
+
+			engine := NewCompareEngine(&configuration.Config{
+				PackageSourceProviders: []*configuration.PackageSourceProvider{{Name: "dockerhub", Type: configuration.PackageSourceProviderTypeDocker}},
+				PackageSources:         sources,
+				Targets:                []*configuration.Target{target},
+				RegistryMap:            tt.registryMap,
+			})
+			results, err := engine.CompareAll()
+			if err != nil {
+				t.Fatalf("CompareAll() failed: %v", err)
+			}
+			if results[0].Error != nil {
+				t.Fatalf("unexpected error: %v", results[0].Error)
+			}
+			if results[0].LatestVersion != tt.expected || !results[0].NeedsUpdate {
+				t.Errorf("expected %s (needsUpdate true), got %s (needsUpdate %v)", tt.expected, results[0].LatestVersion, results[0].NeedsUpdate)
+			}
+		})
+	}
+}
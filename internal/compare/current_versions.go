@@ -0,0 +1,63 @@
+package compare
+
+import (
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// OldestCurrentVersions reads the current versions of all target items referencing an
+// incremental source and returns the oldest bare version per source. Scrapers of sorted
+// feeds can stop paginating once this version has been passed. Sources for which any
+// current version cannot be read are omitted so they are scraped in full.
+func (e *CompareEngine) OldestCurrentVersions() map[string]string {
+	oldest := make(map[string]string)
+	unknown := make(map[string]bool)
+
+	for _, targetConfig := range e.config.Targets {
+		for i := range targetConfig.Items {
+			updateItem := &targetConfig.Items[i]
+			source := e.findSource(updateItem.Source)
+			if source == nil || !source.Incremental || unknown[source.Name] {
+				continue
+			}
+
+			current, ok := e.readBareCurrentVersion(targetConfig, updateItem)
+			if !ok {
+				unknown[source.Name] = true
+				delete(oldest, source.Name)
+				continue
+			}
+
+			if existing, found := oldest[source.Name]; !found || configuration.CompareVersions(parseVersionString(current), parseVersionString(existing)) < 0 {
+				oldest[source.Name] = current
+			}
+		}
+	}
+
+	log.Debug().
+		Int("sources", len(oldest)).
+		Msg("Resolved current versions for incremental scraping")
+
+	return oldest
+}
+
+// readBareCurrentVersion reads the current version of a target item and strips the target
+// version format from it
+func (e *CompareEngine) readBareCurrentVersion(targetConfig *configuration.Target, updateItem *configuration.TargetItem) (string, bool) {
+	targetFormat, err := newTargetVersionFormat(targetConfig, updateItem)
+	if err != nil {
+		return "", false
+	}
+
+	targetClient, err := e.targetFactory.CreateTargetForUpdateItem(targetConfig, updateItem)
+	if err != nil {
+		return "", false
+	}
+
+	currentVersion, err := targetClient.ReadCurrentVersion()
+	if err != nil {
+		return "", false
+	}
+
+	return targetFormat.extract(currentVersion)
+}
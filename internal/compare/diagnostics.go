@@ -0,0 +1,83 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// diagnoseUnknownVersion explains why the current version of a target is not among the
+// versions of its source, e.g. because a pattern filtered it out or the version limit is too
+// low, and names the nearest known versions. versions are the bare versions of the track,
+// newest first.
+func (e *CompareEngine) diagnoseUnknownVersion(source *configuration.PackageSource, trackName string, format *sourceVersionFormat, current *configuration.PackageSourceVersion, versions []*configuration.PackageSourceVersion) string {
+	reason := e.unknownVersionReason(source, trackName, format, current, versions)
+
+	newer, older := nearestVersions(source, current, versions)
+	var nearest []string
+	if newer != nil {
+		nearest = append(nearest, newer.Version)
+	}
+	if older != nil {
+		nearest = append(nearest, older.Version)
+	}
+	if len(nearest) == 0 {
+		return reason
+	}
+	return fmt.Sprintf("%s; nearest versions: %s", reason, strings.Join(nearest, ", "))
+}
+
+// unknownVersionReason returns the most likely reason a current version is not among the
+// versions of its source
+func (e *CompareEngine) unknownVersionReason(source *configuration.PackageSource, trackName string, format *sourceVersionFormat, current *configuration.PackageSourceVersion, versions []*configuration.PackageSourceVersion) string {
+	// Patterns apply to source tags, which can only be rebuilt from a version template
+	if tag, ok := format.tag(current.Version); ok {
+		if source.TagPattern != "" && !matchesPattern(source.TagPattern, tag) {
+			return fmt.Sprintf("current version %s does not match the tagPattern of source '%s'", tag, source.Name)
+		}
+		if source.ExcludePattern != "" && matchesPattern(source.ExcludePattern, tag) {
+			return fmt.Sprintf("current version %s is excluded by the excludePattern of source '%s'", tag, source.Name)
+		}
+		if track := source.FindTrack(trackName); track != nil && !matchesPattern(track.TagPattern, tag) {
+			return fmt.Sprintf("current version %s is not on track '%s'", tag, trackName)
+		}
+	}
+
+	if source.VersionConstraint != "" {
+		if constraint, err := configuration.ParseVersionConstraint(source.VersionConstraint); err == nil && !constraint.Allows(current) {
+			return fmt.Sprintf("current version is outside the versionConstraint '%s' of source '%s'", source.VersionConstraint, source.Name)
+		}
+	}
+
+	if oldest := versions[len(versions)-1]; source.SortBy != "date" && source.CompareVersions(current, oldest) < 0 {
+		return fmt.Sprintf("current version is older than the oldest of the %d versions kept of source '%s' (%s), the version limit may be too low", len(versions), source.Name, oldest.Version)
+	}
+
+	return fmt.Sprintf("current version was not found upstream in source '%s', it may have been deleted", source.Name)
+}
+
+// nearestVersions returns the oldest version newer than current and the newest version older
+// than current, or nil if there is none
+func nearestVersions(source *configuration.PackageSource, current *configuration.PackageSourceVersion, versions []*configuration.PackageSourceVersion) (*configuration.PackageSourceVersion, *configuration.PackageSourceVersion) {
+	var newer, older *configuration.PackageSourceVersion
+	for _, v := range versions {
+		switch comparison := source.CompareVersions(v, current); {
+		case comparison > 0 && (newer == nil || source.CompareVersions(v, newer) < 0):
+			newer = v
+		case comparison < 0 && (older == nil || source.CompareVersions(v, older) > 0):
+			older = v
+		}
+	}
+	return newer, older
+}
+
+// matchesPattern reports whether the value matches the regex, treating invalid patterns,
+// which validation reports, as matching
+func matchesPattern(pattern string, value string) bool {
+	compiled, err := configuration.CompilePattern(pattern)
+	if err != nil {
+		return true
+	}
+	return compiled.MatchString(value)
+}
@@ -0,0 +1,108 @@
+package compare
+
+import "fmt"
+
+// BareCurrentVersion returns the current version with the target version format stripped
+func (r *ComparisonResult) BareCurrentVersion() string {
+	return r.bareCurrentVersion
+}
+
+// TargetValue returns the value of the target file updates are written over, which differs
+// from the current version if that was read from the cluster
+func (r *ComparisonResult) TargetValue() string {
+	if r.FileVersion != "" {
+		return r.FileVersion
+	}
+	return r.CurrentVersion
+}
+
+// LimitTo caps the proposed update at the given bare source version, e.g. the version an
+// earlier rollout stage runs. If the cap is older than the proposed version, the proposal is
+// lowered to the cap, or dropped if the cap is not newer than the current version or not a
+// known version of the source; the withheld version is recorded with the reason.
+func (r *ComparisonResult) LimitTo(bareVersion string, reason string) {
+	if !r.NeedsUpdate {
+		return
+	}
+
+	capIndex := r.versionIndex(bareVersion)
+	if capIndex >= 0 && capIndex <= r.versionIndex(r.bareLatestVersion) {
+		return
+	}
+
+	heldBackVersion := r.LatestVersion
+	heldBackType := r.UpdateType
+	r.LatestVersion = r.TargetValue()
+	r.UpdateType = UpdateTypeNone
+	if capIndex >= 0 {
+		capped := r.versions[capIndex]
+		if updateType := determineUpdateType(r.versionScheme, r.currentSemVer, capped); isUpdate(updateType) {
+			r.LatestVersion = r.targetFormat.format(r.TargetValue(), capped.Version)
+			r.UpdateType = updateType
+			r.bareLatestVersion = capped.Version
+		}
+	}
+	r.NeedsUpdate = isUpdate(r.UpdateType) && r.LatestVersion != r.FileVersion
+	r.HeldBackVersion = heldBackVersion
+	r.HeldBackType = heldBackType
+	r.HeldBackReason = reason
+}
+
+// versionIndex returns the position of a bare version in the source versions, newest first,
+// or -1 if unknown
+func (r *ComparisonResult) versionIndex(bareVersion string) int {
+	normalized := normalizeVersion(bareVersion)
+	for i, v := range r.versions {
+		if normalizeVersion(v.Version) == normalized {
+			return i
+		}
+	}
+	return -1
+}
+
+// AlignVersionSets moves all items of a version set to one version. Every proposal of a set is
+// capped at the oldest version any of its items is moved to or stays at, and the updates of a
+// set are dropped if one of its items failed to compare or the items share no known version.
+// CompareAll aligns the sets; call it again after the proposal of single items changed, e.g.
+// by LimitTo.
+func AlignVersionSets(results []*ComparisonResult) {
+	sets := make(map[string][]*ComparisonResult)
+	for _, result := range results {
+		if result.VersionSet == "" {
+			continue
+		}
+		sets[result.VersionSet] = append(sets[result.VersionSet], result)
+	}
+
+	for name, members := range sets {
+		target, oldest, failed := "", -1, false
+		for _, member := range members {
+			if member.Error != nil {
+				// Wildcard files without the item are expected to fail
+				failed = failed || !member.IsWildcardMatch
+				continue
+			}
+			version := member.bareCurrentVersion
+			if member.NeedsUpdate {
+				version = member.bareLatestVersion
+			}
+			index := member.versionIndex(version)
+			if index < 0 {
+				failed = true
+				continue
+			}
+			if index > oldest {
+				target, oldest = version, index
+			}
+		}
+
+		reason := fmt.Sprintf("version set %s is held at %s", name, target)
+		if failed {
+			target = ""
+			reason = fmt.Sprintf("version set %s has an item without a comparable version", name)
+		}
+		for _, member := range members {
+			member.LimitTo(target, reason)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestComparisonResult_LimitTo(t *testing.T) {
+	source := &configuration.PackageSource{
+		Name:     "app",
+		Versions: newVersions("3.0.0", "2.5.0", "2.4.2", "2.4.1"),
+	}
+
+	tests := []struct {
+		name            string
+		cap             string
+		expected        string
+		expectedType    UpdateType
+		needsUpdate     bool
+		heldBackVersion string
+		heldBackReason  string
+	}{
+		{
+			name:         "cap at the proposed version keeps the update",
+			cap:          "3.0.0",
+			expected:     "3.0.0",
+			expectedType: UpdateTypeMajor,
+			needsUpdate:  true,
+		},
+		{
+			name:            "older cap lowers the update",
+			cap:             "2.5.0",
+			expected:        "2.5.0",
+			expectedType:    UpdateTypeMinor,
+			needsUpdate:     true,
+			heldBackVersion: "3.0.0",
+			heldBackReason:  "stage staging runs 2.5.0",
+		},
+		{
+			name:            "cap at the current version drops the update",
+			cap:             "2.4.1",
+			expected:        "2.4.1",
+			expectedType:    UpdateTypeNone,
+			heldBackVersion: "3.0.0",
+			heldBackReason:  "stage staging runs 2.5.0",
+		},
+		{
+			name:            "unknown cap drops the update",
+			cap:             "2.4.9",
+			expected:        "2.4.1",
+			expectedType:    UpdateTypeNone,
+			heldBackVersion: "3.0.0",
+			heldBackReason:  "stage staging runs 2.5.0",
+		},
+		{
+			name:            "v prefix of the cap is ignored",
+			cap:             "v2.4.2",
+			expected:        "2.4.2",
+			expectedType:    UpdateTypePatch,
+			needsUpdate:     true,
+			heldBackVersion: "3.0.0",
+			heldBackReason:  "stage staging runs 2.5.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newTerraformTarget(t, "app", "2.4.1", "app")
+			result := compareTargets(t, []*configuration.PackageSource{source}, target)[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+
+			result.LimitTo(tt.cap, "stage staging runs 2.5.0")
+			if result.LatestVersion != tt.expected || result.UpdateType != tt.expectedType || result.NeedsUpdate != tt.needsUpdate {
+				t.Errorf("expected %s (%s, needsUpdate %v), got %s (%s, needsUpdate %v)",
+					tt.expected, tt.expectedType, tt.needsUpdate, result.LatestVersion, result.UpdateType, result.NeedsUpdate)
+			}
+			if result.HeldBackVersion != tt.heldBackVersion || result.HeldBackReason != tt.heldBackReason {
+				t.Errorf("expected held back %q (%q), got %q (%q)", tt.heldBackVersion, tt.heldBackReason, result.HeldBackVersion, result.HeldBackReason)
+			}
+		})
+	}
+}
+
+func TestComparisonResult_LimitTo_UpToDate(t *testing.T) {
+	source := &configuration.PackageSource{Name: "app", Versions: newVersions("2.0.0", "1.0.0")}
+	result := compareTargets(t, []*configuration.PackageSource{source}, newTerraformTarget(t, "app", "2.0.0", "app"))[0]
+
+	result.LimitTo("1.0.0", "stage staging runs 1.0.0")
+	if result.NeedsUpdate || result.LatestVersion != "2.0.0" || result.HeldBackVersion != "" {
+		t.Errorf("expected an up-to-date result to stay unchanged, got %s (held back %q)", result.LatestVersion, result.HeldBackVersion)
+	}
+}
+
+func TestAlignVersionSets(t *testing.T) {
+	source := &configuration.PackageSource{Name: "app", Versions: newVersions("2.0.0", "1.1.0", "1.0.0")}
+
+	tests := []struct {
+		name             string
+		frontend         string
+		backend          string
+		prepare          func(frontend, backend *configuration.Target)
+		snoozeFrontend   bool
+		expectedFrontend string
+		expectedBackend  string
+		expectedReasons  [2]string
+	}{
+		{
+			name:             "items behind by different versions move to the same version",
+			frontend:         "1.0.0",
+			backend:          "1.1.0",
+			expectedFrontend: "2.0.0",
+			expectedBackend:  "2.0.0",
+		},
+		{
+			name:     "restricted item holds the set at its version",
+			frontend: "1.0.0",
+			backend:  "1.0.0",
+			prepare: func(frontend, backend *configuration.Target) {
+				frontend.MaxUpdateType = "minor"
+			},
+			expectedFrontend: "1.1.0",
+			expectedBackend:  "1.1.0",
+			expectedReasons:  [2]string{"", "version set app is held at 1.1.0"},
+		},
+		{
+			name:             "held back item holds the set at its current version",
+			frontend:         "1.0.0",
+			backend:          "1.0.0",
+			snoozeFrontend:   true,
+			expectedFrontend: "1.0.0",
+			expectedBackend:  "1.0.0",
+			expectedReasons:  [2]string{"snoozed", "version set app is held at 1.0.0"},
+		},
+		{
+			name:     "failing item drops the updates of the set",
+			frontend: "1.0.0",
+			backend:  "1.0.0",
+			prepare: func(frontend, backend *configuration.Target) {
+				backend.Items[0].Source = "missing"
+			},
+			expectedFrontend: "1.0.0",
+			expectedReasons:  [2]string{"version set app has an item without a comparable version", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontend := newTerraformTarget(t, "frontend", tt.frontend, "app")
+			backend := newTerraformTarget(t, "backend", tt.backend, "app")
+			frontend.VersionSet = "app"
+			backend.VersionSet = "app"
+			if tt.prepare != nil {
+				tt.prepare(frontend, backend)
+			}
+
+			results := compareTargets(t, []*configuration.PackageSource{source}, frontend, backend)
+			if tt.snoozeFrontend {
+				results[0].LimitTo(results[0].BareCurrentVersion(), "snoozed")
+				AlignVersionSets(results)
+			}
+
+			for i, expected := range []string{tt.expectedFrontend, tt.expectedBackend} {
+				result := results[i]
+				if result.Error != nil {
+					continue
+				}
+				if result.LatestVersion != expected {
+					t.Errorf("%s: expected %s, got %s", result.TargetName, expected, result.LatestVersion)
+				}
+				if result.HeldBackReason != tt.expectedReasons[i] {
+					t.Errorf("%s: expected held back reason %q, got %q", result.TargetName, tt.expectedReasons[i], result.HeldBackReason)
+				}
+			}
+		})
+	}
+}
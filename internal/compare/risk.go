@@ -0,0 +1,104 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// RiskLevel is the heuristic risk of an update breaking its target
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "low"
+	RiskLevelMedium RiskLevel = "medium"
+	RiskLevelHigh   RiskLevel = "high"
+)
+
+const (
+	// riskManySkippedVersions is the number of skipped versions from which an update is
+	// considered a large jump
+	riskManySkippedVersions = 10
+	// riskFreshRelease is the age below which a release had little time to receive fixes
+	riskFreshRelease = 7 * 24 * time.Hour
+)
+
+// AssessRisks scores the risk of the proposed update of every result, see AssessRisk
+func AssessRisks(results []*ComparisonResult, now time.Time) {
+	for _, result := range results {
+		result.AssessRisk(now)
+	}
+}
+
+// AssessRisk scores the risk of the proposed update with heuristics: a major update scores 3
+// and a minor update 1, skipping 10 or more versions 2, release notes of a skipped or the
+// proposed version announcing breaking changes 3, and a proposed version released less than
+// a week ago 1. A score of 4 or more is high risk, 2 or 3 medium risk. Results without a
+// proposed update have no risk. Call it again after the proposal changed, e.g. by LimitTo.
+func (r *ComparisonResult) AssessRisk(now time.Time) {
+	r.Risk = ""
+	r.RiskReasons = nil
+	if !r.NeedsUpdate {
+		return
+	}
+
+	score := 0
+	switch r.UpdateType {
+	case UpdateTypeMajor:
+		score += 3
+		r.RiskReasons = append(r.RiskReasons, "major update")
+	case UpdateTypeMinor:
+		score++
+		r.RiskReasons = append(r.RiskReasons, "minor update")
+	}
+
+	skipped := r.skippedVersions()
+	if len(skipped) >= riskManySkippedVersions {
+		score += 2
+		r.RiskReasons = append(r.RiskReasons, fmt.Sprintf("skips %d versions", len(skipped)))
+	}
+
+	var latest *configuration.PackageSourceVersion
+	if i := r.versionIndex(r.bareLatestVersion); i >= 0 {
+		latest = r.versions[i]
+	}
+	breaking := make([]string, 0)
+	if latest != nil && latest.Breaking {
+		breaking = append(breaking, latest.Version)
+	}
+	for _, version := range skipped {
+		if version.Breaking {
+			breaking = append(breaking, version.Version)
+		}
+	}
+	if len(breaking) > 0 {
+		score += 3
+		r.RiskReasons = append(r.RiskReasons, fmt.Sprintf("release notes of %s announce breaking changes", strings.Join(breaking, ", ")))
+	}
+
+	if latest != nil && !latest.ReleasedAt.IsZero() {
+		if age := now.Sub(latest.ReleasedAt); age < riskFreshRelease {
+			score++
+			r.RiskReasons = append(r.RiskReasons, fmt.Sprintf("released %s ago", formatAge(age)))
+		}
+	}
+
+	switch {
+	case score >= 4:
+		r.Risk = RiskLevelHigh
+	case score >= 2:
+		r.Risk = RiskLevelMedium
+	default:
+		r.Risk = RiskLevelLow
+	}
+}
+
+// formatAge formats the age of a release in hours or days
+func formatAge(age time.Duration) string {
+	if age < 24*time.Hour {
+		return fmt.Sprintf("%dh", max(int(age.Hours()), 0))
+	}
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
@@ -0,0 +1,106 @@
+package compare
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestAssessRisk(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	// 1.0.0 to 1.0.12 with eleven patch versions in between
+	manyPatches := make([]string, 0)
+	for patch := 12; patch >= 0; patch-- {
+		manyPatches = append(manyPatches, fmt.Sprintf("1.0.%d", patch))
+	}
+
+	tests := []struct {
+		name     string
+		versions []string
+		current  string
+		modify   func(versions []*configuration.PackageSourceVersion)
+		risk     RiskLevel
+		reasons  int
+	}{
+		{
+			name:     "patch update",
+			versions: []string{"1.0.1", "1.0.0"},
+			current:  "1.0.0",
+			risk:     RiskLevelLow,
+		},
+		{
+			name:     "major update",
+			versions: []string{"2.0.0", "1.0.0"},
+			current:  "1.0.0",
+			risk:     RiskLevelMedium,
+			reasons:  1,
+		},
+		{
+			name:     "major update announcing breaking changes",
+			versions: []string{"2.0.0", "1.0.0"},
+			current:  "1.0.0",
+			modify: func(versions []*configuration.PackageSourceVersion) {
+				versions[0].Breaking = true
+			},
+			risk:    RiskLevelHigh,
+			reasons: 2,
+		},
+		{
+			name:     "minor update skipping a version announcing breaking changes",
+			versions: []string{"1.2.0", "1.1.0", "1.0.0"},
+			current:  "1.0.0",
+			modify: func(versions []*configuration.PackageSourceVersion) {
+				versions[1].Breaking = true
+			},
+			risk:    RiskLevelHigh,
+			reasons: 2,
+		},
+		{
+			name:     "patch update skipping many versions",
+			versions: manyPatches,
+			current:  "1.0.0",
+			risk:     RiskLevelMedium,
+			reasons:  1,
+		},
+		{
+			name:     "minor update released yesterday",
+			versions: []string{"1.1.0", "1.0.0"},
+			current:  "1.0.0",
+			modify: func(versions []*configuration.PackageSourceVersion) {
+				versions[0].ReleasedAt = now.Add(-24 * time.Hour)
+			},
+			risk:    RiskLevelMedium,
+			reasons: 2,
+		},
+		{
+			name:     "up to date",
+			versions: []string{"1.0.0"},
+			current:  "1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions := newVersions(tt.versions...)
+			if tt.modify != nil {
+				tt.modify(versions)
+			}
+			source := &configuration.PackageSource{Name: "app", Versions: versions}
+			result := compareTargets(t, []*configuration.PackageSource{source}, newTerraformTarget(t, "app", tt.current, "app"))[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+
+			result.AssessRisk(now)
+			if result.Risk != tt.risk {
+				t.Errorf("expected risk %q, got %q (%v)", tt.risk, result.Risk, result.RiskReasons)
+			}
+			if len(result.RiskReasons) != tt.reasons {
+				t.Errorf("expected %d reason(s), got %v", tt.reasons, result.RiskReasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package compare
+
+import "github.com/mxcd/updater/internal/configuration"
+
+// ListSkippedVersions lists the versions skipped by the proposed update of every result, see
+// ListSkippedVersions of ComparisonResult
+func ListSkippedVersions(results []*ComparisonResult) {
+	for _, result := range results {
+		result.ListSkippedVersions()
+	}
+}
+
+// ListSkippedVersions lists the source versions between the current and the proposed version
+// in SkippedVersions, newest first and in the version format of the target, so reviewers see
+// whether an update is a single step or a long jump. Only the versions kept by the version
+// limit are known; if the current version is older than all of them, older skipped versions
+// are missing and SkippedVersionsIncomplete is set. Results without a proposed update list no
+// versions. Call it again after the proposal changed, e.g. by LimitTo.
+func (r *ComparisonResult) ListSkippedVersions() {
+	r.SkippedVersions = nil
+	r.SkippedVersionCount = 0
+	r.SkippedVersionsIncomplete = false
+	if !r.NeedsUpdate {
+		return
+	}
+
+	skipped := r.skippedVersions()
+	r.SkippedVersions = make([]string, 0, len(skipped))
+	for _, version := range skipped {
+		r.SkippedVersions = append(r.SkippedVersions, r.targetFormat.format(r.TargetValue(), version.Version))
+	}
+	r.SkippedVersionCount = len(r.SkippedVersions)
+	oldest := r.versions[len(r.versions)-1]
+	r.SkippedVersionsIncomplete = r.versionIndex(r.bareCurrentVersion) < 0 && isUpdate(determineUpdateType(r.versionScheme, r.currentSemVer, oldest))
+}
+
+// skippedVersions returns the source versions between the current and the proposed version,
+// newest first
+func (r *ComparisonResult) skippedVersions() []*configuration.PackageSourceVersion {
+	latestIndex := r.versionIndex(r.bareLatestVersion)
+	if latestIndex < 0 {
+		return nil
+	}
+	skipped := make([]*configuration.PackageSourceVersion, 0)
+	for _, version := range r.versions[latestIndex+1:] {
+		if isUpdate(determineUpdateType(r.versionScheme, r.currentSemVer, version)) {
+			skipped = append(skipped, version)
+		}
+	}
+	return skipped
+}
+
+// CVEs returns the CVE IDs mentioned in the release notes of the proposed version and of the
+// skipped versions, i.e. the vulnerabilities the update claims to fix. Only sources with
+// release notes such as git-release provide them; nil if none are mentioned or no update is
+// proposed.
+func (r *ComparisonResult) CVEs() []string {
+	if !r.NeedsUpdate {
+		return nil
+	}
+	versions := r.skippedVersions()
+	if i := r.versionIndex(r.bareLatestVersion); i >= 0 {
+		versions = append([]*configuration.PackageSourceVersion{r.versions[i]}, versions...)
+	}
+
+	var cves []string
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		for _, cve := range version.CVEs {
+			if !seen[cve] {
+				seen[cve] = true
+				cves = append(cves, cve)
+			}
+		}
+	}
+	return cves
+}
@@ -0,0 +1,99 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestListSkippedVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   []string
+		current    string
+		skipped    []string
+		incomplete bool
+	}{
+		{
+			name:     "single step",
+			versions: []string{"1.1.0", "1.0.0"},
+			current:  "1.0.0",
+			skipped:  []string{},
+		},
+		{
+			name:     "several steps",
+			versions: []string{"2.0.0", "1.2.0", "1.1.1", "1.1.0", "1.0.0"},
+			current:  "1.1.0",
+			skipped:  []string{"1.2.0", "1.1.1"},
+		},
+		{
+			name:       "current version beyond the version limit",
+			versions:   []string{"1.4.0", "1.3.0", "1.2.0"},
+			current:    "1.0.0",
+			skipped:    []string{"1.3.0", "1.2.0"},
+			incomplete: true,
+		},
+		{
+			name:     "up to date",
+			versions: []string{"1.0.0"},
+			current:  "1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &configuration.PackageSource{Name: "app", Versions: newVersions(tt.versions...)}
+			result := compareTargets(t, []*configuration.PackageSource{source}, newTerraformTarget(t, "app", tt.current, "app"))[0]
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+
+			result.ListSkippedVersions()
+			if (tt.skipped == nil) != (result.SkippedVersions == nil) {
+				t.Fatalf("expected skipped versions %v, got %v", tt.skipped, result.SkippedVersions)
+			}
+			if len(result.SkippedVersions) != len(tt.skipped) || result.SkippedVersionCount != len(tt.skipped) {
+				t.Fatalf("expected skipped versions %v, got %v (count %d)", tt.skipped, result.SkippedVersions, result.SkippedVersionCount)
+			}
+			for i, version := range tt.skipped {
+				if result.SkippedVersions[i] != version {
+					t.Errorf("expected skipped versions %v, got %v", tt.skipped, result.SkippedVersions)
+					break
+				}
+			}
+			if result.SkippedVersionsIncomplete != tt.incomplete {
+				t.Errorf("expected incomplete %v, got %v", tt.incomplete, result.SkippedVersionsIncomplete)
+			}
+		})
+	}
+}
+
+func TestListSkippedVersions_AfterLimitTo(t *testing.T) {
+	source := &configuration.PackageSource{Name: "app", Versions: newVersions("1.3.0", "1.2.0", "1.1.0", "1.0.0")}
+	result := compareTargets(t, []*configuration.PackageSource{source}, newTerraformTarget(t, "app", "1.0.0", "app"))[0]
+
+	result.LimitTo("1.2.0", "rollout")
+	result.ListSkippedVersions()
+	if len(result.SkippedVersions) != 1 || result.SkippedVersions[0] != "1.1.0" {
+		t.Errorf("expected the versions skipped up to the cap, got %v", result.SkippedVersions)
+	}
+}
+
+func TestCVEs(t *testing.T) {
+	versions := newVersions("1.3.0", "1.2.0", "1.1.0", "1.0.0")
+	versions[0].CVEs = []string{"CVE-2026-0003"}
+	versions[1].CVEs = []string{"CVE-2026-0002", "CVE-2026-0003"}
+	versions[3].CVEs = []string{"CVE-2026-0001"}
+	source := &configuration.PackageSource{Name: "app", Versions: versions}
+	result := compareTargets(t, []*configuration.PackageSource{source}, newTerraformTarget(t, "app", "1.0.0", "app"))[0]
+
+	cves := result.CVEs()
+	if len(cves) != 2 || cves[0] != "CVE-2026-0003" || cves[1] != "CVE-2026-0002" {
+		t.Errorf("expected the CVEs of the new and skipped versions once each, got %v", cves)
+	}
+
+	result.LimitTo("1.1.0", "rollout")
+	if cves := result.CVEs(); cves != nil {
+		t.Errorf("expected no CVEs for versions without release notes mentioning any, got %v", cves)
+	}
+}
@@ -0,0 +1,140 @@
+package compare
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// sourceVersionFormat strips the source-specific formatting from scraped versions
+type sourceVersionFormat struct {
+	template       string
+	extractPattern *regexp.Regexp
+}
+
+// newSourceVersionFormat builds the version format of a source from its
+// extractPattern, versionTemplate, or versionPrefix
+func newSourceVersionFormat(source *configuration.PackageSource) (*sourceVersionFormat, error) {
+	format := &sourceVersionFormat{
+		template: configuration.ResolveVersionTemplate(source.VersionTemplate, source.VersionPrefix),
+	}
+	if source.ExtractPattern != "" {
+		pattern, err := configuration.CompilePattern(source.ExtractPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extractPattern for source '%s': %w", source.Name, err)
+		}
+		format.extractPattern = pattern
+	}
+	return format, nil
+}
+
+// extract returns the bare version of a scraped version, or false if it does not match the format
+func (f *sourceVersionFormat) extract(version string) (string, bool) {
+	if f.extractPattern != nil {
+		bare, _, ok := configuration.ExtractVersionMatch(f.extractPattern, version)
+		return bare, ok
+	}
+	return configuration.ExtractVersion(f.template, version)
+}
+
+// tag renders a bare version in the format of the source, or returns false if the source
+// extracts versions with a pattern, which cannot be reversed
+func (f *sourceVersionFormat) tag(bare string) (string, bool) {
+	if f.extractPattern != nil {
+		return "", false
+	}
+	return configuration.FormatVersion(f.template, bare), true
+}
+
+// bareVersions strips the source format from each version and re-parses the semantic
+// version components, dropping versions that do not match the format.
+// Versions are returned unchanged if the source has no format configured.
+func (f *sourceVersionFormat) bareVersions(versions []*configuration.PackageSourceVersion) []*configuration.PackageSourceVersion {
+	if f.template == "" && f.extractPattern == nil {
+		return versions
+	}
+
+	bare := make([]*configuration.PackageSourceVersion, 0, len(versions))
+	for _, v := range versions {
+		version, ok := f.extract(v.Version)
+		if !ok {
+			continue
+		}
+		b := parseVersionString(version)
+		b.VersionInformation = v.VersionInformation
+		b.ReleasedAt = v.ReleasedAt
+		b.Breaking = v.Breaking
+		b.CVEs = v.CVEs
+		bare = append(bare, b)
+	}
+	return bare
+}
+
+// targetVersionFormat describes how versions are stored in a target value
+type targetVersionFormat struct {
+	template       string
+	extractPattern *regexp.Regexp
+	writeTemplate  string
+	image          string // Mapped image name of a docker source rendered for {{image}}
+}
+
+// newTargetVersionFormat builds the version format of a target item. The item's format
+// options take precedence over the target's when any of them is set.
+func newTargetVersionFormat(targetConfig *configuration.Target, updateItem *configuration.TargetItem) (*targetVersionFormat, error) {
+	template := configuration.ResolveVersionTemplate(updateItem.VersionTemplate, updateItem.VersionPrefix)
+	extractPattern := updateItem.ExtractPattern
+	writeTemplate := updateItem.WriteTemplate
+	if template == "" && extractPattern == "" {
+		template = configuration.ResolveVersionTemplate(targetConfig.VersionTemplate, targetConfig.VersionPrefix)
+		extractPattern = targetConfig.ExtractPattern
+		writeTemplate = targetConfig.WriteTemplate
+	}
+
+	format := &targetVersionFormat{
+		template:      template,
+		writeTemplate: writeTemplate,
+	}
+	if extractPattern != "" {
+		pattern, err := configuration.CompilePattern(extractPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extractPattern for target '%s': %w", targetConfig.Name, err)
+		}
+		format.extractPattern = pattern
+	}
+	return format, nil
+}
+
+// extract returns the bare version of the current target value, or false if it does not match the format
+func (f *targetVersionFormat) extract(current string) (string, bool) {
+	if f.extractPattern != nil {
+		bare, _, ok := configuration.ExtractVersionMatch(f.extractPattern, current)
+		return bare, ok
+	}
+	return configuration.ExtractVersion(f.template, current)
+}
+
+// format renders a source version as the value to write to the target. Compound values
+// are rebuilt from the current value; without a format the version is written as provided by the source.
+func (f *targetVersionFormat) format(current string, version string) string {
+	if f.extractPattern != nil {
+		if current == "" {
+			return normalizeVersion(version)
+		}
+		if f.writeTemplate != "" {
+			_, groups, _ := configuration.ExtractVersionMatch(f.extractPattern, current)
+			if f.image != "" {
+				if groups == nil {
+					groups = make(map[string]string)
+				}
+				groups["image"] = f.image
+			}
+			return configuration.RenderWriteTemplate(f.writeTemplate, normalizeVersion(version), groups)
+		}
+		return configuration.ReplaceVersionMatch(f.extractPattern, current, normalizeVersion(version))
+	}
+	if f.template == "" {
+		return version
+	}
+	return configuration.FormatVersion(f.template, normalizeVersion(version))
+}
@@ -0,0 +1,358 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const argoCDImageUpdaterAnnotationPrefix = "argocd-image-updater.argoproj.io/"
+
+// argoCDApplication is the subset of an Argo CD Application manifest needed to
+// synthesize sources and targets from its argocd-image-updater annotations
+type argoCDApplication struct {
+	document int    // Index of the YAML document of the application in its file
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Source  *argoCDApplicationSource   `yaml:"source"`
+		Sources []*argoCDApplicationSource `yaml:"sources"`
+	} `yaml:"spec"`
+}
+
+type argoCDApplicationSource struct {
+	Helm *struct {
+		Parameters []struct {
+			Name  string `yaml:"name"`
+			Value string `yaml:"value"`
+		} `yaml:"parameters"`
+	} `yaml:"helm"`
+	Kustomize *struct {
+		Images []string `yaml:"images"`
+	} `yaml:"kustomize"`
+}
+
+// argoCDImage is a single entry of the image-list annotation
+type argoCDImage struct {
+	Alias      string
+	Image      string
+	Constraint string
+}
+
+// ExpandArgoCDImageUpdater reads the Application manifests of all argocdImageUpdater entries
+// and appends a docker-image source per annotated image and a yaml-field target per
+// application. Images whose write-back location cannot be found in the manifest are skipped
+// with a warning.
+func ExpandArgoCDImageUpdater(config *Config) error {
+	sourceNames := make(map[string]bool)
+	for _, source := range config.PackageSources {
+		sourceNames[source.Name] = true
+	}
+
+	for _, ingest := range config.ArgoCDImageUpdater {
+		files, err := expandFilePatterns(ingest.Files)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			apps, err := readArgoCDApplications(file)
+			if err != nil {
+				return fmt.Errorf("failed to read Argo CD applications %s: %w", file, err)
+			}
+
+			for _, app := range apps {
+				sources, target := synthesizeArgoCDApplication(app, file, ingest)
+				if target == nil {
+					continue
+				}
+
+				// Applications of the same name in other files, e.g. per environment, get
+				// numbered source names
+				for i, source := range sources {
+					source.Name = uniqueSourceName(sourceNames, source.Name)
+					target.Items[i].Source = source.Name
+				}
+
+				log.Debug().
+					Str("application", app.Metadata.Name).
+					Str("file", file).
+					Int("document", app.document).
+					Int("images", len(sources)).
+					Msg("Ingested argocd-image-updater annotations")
+
+				config.PackageSources = append(config.PackageSources, sources...)
+				config.Targets = append(config.Targets, target)
+			}
+		}
+	}
+	return nil
+}
+
+// uniqueSourceName returns the name, or the name with the first free numeric suffix if it is
+// already taken, and marks it as taken
+func uniqueSourceName(taken map[string]bool, name string) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// expandFilePatterns resolves wildcard patterns to the matching files
+func expandFilePatterns(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			files = append(files, pattern)
+			continue
+		}
+
+		var matches []string
+		var err error
+		if strings.Contains(pattern, "**") {
+			matches, err = recursiveGlob(pattern, false)
+		} else {
+			matches, err = filepath.Glob(pattern)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			log.Warn().Str("pattern", pattern).Msg("Wildcard pattern matched no files")
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// readArgoCDApplications returns the Applications with an image-list annotation in all
+// documents of the file
+func readArgoCDApplications(file string) ([]*argoCDApplication, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []*argoCDApplication
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for document := 0; ; document++ {
+		app := &argoCDApplication{document: document}
+		if err := decoder.Decode(app); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if app.Kind != "Application" || app.annotation("image-list") == "" {
+			continue
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// annotation returns the argocd-image-updater annotation with the given key
+func (a *argoCDApplication) annotation(key string) string {
+	return strings.TrimSpace(a.Metadata.Annotations[argoCDImageUpdaterAnnotationPrefix+key])
+}
+
+// applicationSources returns the sources of the application with their YAML paths
+func (a *argoCDApplication) applicationSources() ([]*argoCDApplicationSource, []string) {
+	if a.Spec.Source != nil {
+		return []*argoCDApplicationSource{a.Spec.Source}, []string{"spec.source"}
+	}
+	paths := make([]string, len(a.Spec.Sources))
+	for i := range a.Spec.Sources {
+		paths[i] = fmt.Sprintf("spec.sources.%d", i)
+	}
+	return a.Spec.Sources, paths
+}
+
+// synthesizeArgoCDApplication builds the sources and the target for a single application
+func synthesizeArgoCDApplication(app *argoCDApplication, file string, ingest *ArgoCDImageUpdater) ([]*PackageSource, *Target) {
+	appName := app.Metadata.Name
+	target := &Target{
+		Name:       appName,
+		Type:       TargetTypeYamlField,
+		File:       file,
+		Items:      make([]TargetItem, 0),
+		PatchGroup: ingest.PatchGroup,
+		Labels:     ingest.Labels,
+	}
+
+	var sources []*PackageSource
+	for _, image := range parseArgoCDImageList(app.annotation("image-list")) {
+		logger := log.With().Str("application", appName).Str("image", image.Alias).Logger()
+
+		source, ok := argoCDImageSource(app, image, ingest.Provider)
+		if !ok {
+			logger.Warn().Str("strategy", app.annotation(image.Alias+".update-strategy")).Msg("Unsupported update strategy, skipping image")
+			continue
+		}
+
+		yamlPath := argoCDWriteBackPath(app, image)
+		if yamlPath == "" {
+			logger.Warn().Msg("No Helm parameter or Kustomize image to update found in the application, skipping image")
+			continue
+		}
+
+		if image.Constraint != "" {
+			if _, err := ParseVersionConstraint(image.Constraint); err != nil {
+				logger.Warn().Err(err).Msg("Unsupported constraint, skipping image")
+				continue
+			}
+			source.VersionConstraint = image.Constraint
+		}
+
+		// Paths of multi-document files resolve in the application's own document
+		document := app.document
+		item := TargetItem{
+			Name:     image.Alias,
+			YamlPath: yamlPath,
+			Document: &document,
+			Source:   source.Name,
+		}
+
+		sources = append(sources, source)
+		target.Items = append(target.Items, item)
+	}
+
+	if len(target.Items) == 0 {
+		return nil, nil
+	}
+	return sources, target
+}
+
+// parseArgoCDImageList parses the image-list annotation of the form
+// "[alias=]image[:constraint], ..."
+func parseArgoCDImageList(value string) []*argoCDImage {
+	var images []*argoCDImage
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		image := &argoCDImage{}
+		if idx := strings.Index(entry, "="); idx != -1 {
+			image.Alias = entry[:idx]
+			entry = entry[idx+1:]
+		}
+
+		// A colon after the last slash separates the constraint, earlier ones are registry ports
+		image.Image = entry
+		if idx := strings.LastIndex(entry, ":"); idx > strings.LastIndex(entry, "/") {
+			image.Image = entry[:idx]
+			image.Constraint = entry[idx+1:]
+		}
+		if image.Alias == "" {
+			image.Alias = image.Image
+		}
+		images = append(images, image)
+	}
+	return images
+}
+
+// argoCDImageSource maps the update strategy and tag filters of an image to a docker-image
+// source. It reports false for strategies updater cannot follow (digest).
+func argoCDImageSource(app *argoCDApplication, image *argoCDImage, provider string) (*PackageSource, bool) {
+	source := &PackageSource{
+		Name:     fmt.Sprintf("%s-%s", app.Metadata.Name, image.Alias),
+		Provider: provider,
+		Type:     PackageSourceTypeDockerImage,
+		URI:      image.Image,
+	}
+
+	switch app.annotation(image.Alias + ".update-strategy") {
+	case "", "semver":
+		source.SortBy = "semantic"
+	case "latest", "newest-build":
+		source.SortBy = "date"
+	case "name", "alphabetical":
+		source.SortBy = "alphabetical"
+	default:
+		return nil, false
+	}
+
+	if allowTags := app.annotation(image.Alias + ".allow-tags"); strings.HasPrefix(allowTags, "regexp:") {
+		source.TagPattern = strings.TrimPrefix(allowTags, "regexp:")
+	}
+
+	if ignoreTags := app.annotation(image.Alias + ".ignore-tags"); ignoreTags != "" {
+		var patterns []string
+		for _, glob := range strings.Split(ignoreTags, ",") {
+			if glob = strings.TrimSpace(glob); glob != "" {
+				patterns = append(patterns, globToRegex(glob))
+			}
+		}
+		source.ExcludePattern = strings.Join(patterns, "|")
+	}
+
+	return source, true
+}
+
+// argoCDWriteBackPath returns the YAML path of the Helm parameter or Kustomize image that
+// holds the version of the image, or "" if the application does not contain it
+func argoCDWriteBackPath(app *argoCDApplication, image *argoCDImage) string {
+	imageSpec := app.annotation(image.Alias + ".helm.image-spec")
+	imageTag := app.annotation(image.Alias + ".helm.image-tag")
+	if imageTag == "" {
+		imageTag = "image.tag"
+	}
+	kustomizeName := app.annotation(image.Alias + ".kustomize.image-name")
+	if kustomizeName == "" {
+		kustomizeName = image.Image
+	}
+
+	sources, paths := app.applicationSources()
+	for i, source := range sources {
+		if source.Helm != nil {
+			for j, parameter := range source.Helm.Parameters {
+				if (imageSpec != "" && parameter.Name == imageSpec) || (imageSpec == "" && parameter.Name == imageTag) {
+					return fmt.Sprintf("%s.helm.parameters.%d.value", paths[i], j)
+				}
+			}
+		}
+		if source.Kustomize != nil {
+			for j, entry := range source.Kustomize.Images {
+				if kustomizeImageName(entry) == kustomizeName {
+					return fmt.Sprintf("%s.kustomize.images.%d", paths[i], j)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// kustomizeImageName returns the image name of a Kustomize image override of the form
+// "name[=newName][:tag]"
+func kustomizeImageName(entry string) string {
+	if idx := strings.Index(entry, "="); idx != -1 {
+		return entry[:idx]
+	}
+	if idx := strings.LastIndex(entry, ":"); idx > strings.LastIndex(entry, "/") {
+		return entry[:idx]
+	}
+	return entry
+}
+
+// globToRegex converts a tag glob with * and ? wildcards to an anchored regex
+func globToRegex(glob string) string {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return "^" + pattern + "$"
+}
@@ -0,0 +1,171 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseArgoCDImageList(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		alias      string
+		image      string
+		constraint string
+	}{
+		{name: "plain image", value: "nginx", alias: "nginx", image: "nginx"},
+		{name: "alias and constraint", value: "web=nginx:~1.25", alias: "web", image: "nginx", constraint: "~1.25"},
+		{name: "registry with port", value: "registry.example.com:5000/org/app", alias: "registry.example.com:5000/org/app", image: "registry.example.com:5000/org/app"},
+		{name: "registry with port and constraint", value: "app=registry.example.com:5000/org/app:^2", alias: "app", image: "registry.example.com:5000/org/app", constraint: "^2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := parseArgoCDImageList(tt.value)
+			if len(images) != 1 {
+				t.Fatalf("expected 1 image, got %d", len(images))
+			}
+			if images[0].Alias != tt.alias || images[0].Image != tt.image || images[0].Constraint != tt.constraint {
+				t.Errorf("got %+v, want alias=%s image=%s constraint=%s", images[0], tt.alias, tt.image, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestExpandArgoCDImageUpdater(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	application := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: shop
+  annotations:
+    argocd-image-updater.argoproj.io/image-list: web=nginx:~1.25, api=ghcr.io/example/api, worker=example/worker
+    argocd-image-updater.argoproj.io/web.helm.image-tag: web.image.tag
+    argocd-image-updater.argoproj.io/web.ignore-tags: "*-alpine, latest"
+    argocd-image-updater.argoproj.io/api.update-strategy: latest
+    argocd-image-updater.argoproj.io/api.allow-tags: regexp:^v\d+
+    argocd-image-updater.argoproj.io/worker.update-strategy: digest
+spec:
+  sources:
+    - helm:
+        parameters:
+          - name: replicas
+            value: "2"
+          - name: web.image.tag
+            value: 1.25.3
+    - kustomize:
+        images:
+          - ghcr.io/example/api:v1.0.0
+          - example/worker:1.0.0
+`
+	file := filepath.Join(tmpDir, "shop.yaml")
+	if err := os.WriteFile(file, []byte(application), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	config := &Config{
+		ArgoCDImageUpdater: []*ArgoCDImageUpdater{
+			{Files: []string{filepath.Join(tmpDir, "*.yaml")}, Provider: "registry", PatchGroup: "apps"},
+		},
+	}
+	if err := ExpandArgoCDImageUpdater(config); err != nil {
+		t.Fatalf("ExpandArgoCDImageUpdater() error = %v", err)
+	}
+
+	// The digest-strategy worker image is skipped
+	if len(config.PackageSources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(config.PackageSources))
+	}
+	if len(config.Targets) != 1 {
+		t.Fatalf("expected 1 target, got %d", len(config.Targets))
+	}
+
+	web := config.PackageSources[0]
+	if web.Name != "shop-web" || web.URI != "nginx" || web.SortBy != "semantic" || web.ExcludePattern != "^.*-alpine$|^latest$" || web.VersionConstraint != "~1.25" {
+		t.Errorf("unexpected web source: %+v", web)
+	}
+	api := config.PackageSources[1]
+	if api.URI != "ghcr.io/example/api" || api.SortBy != "date" || api.TagPattern != `^v\d+` {
+		t.Errorf("unexpected api source: %+v", api)
+	}
+
+	target := config.Targets[0]
+	if target.Name != "shop" || target.Type != TargetTypeYamlField || target.File != file || target.PatchGroup != "apps" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	if len(target.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(target.Items))
+	}
+	if target.Items[0].YamlPath != "spec.sources.0.helm.parameters.1.value" || target.Items[0].Document == nil || *target.Items[0].Document != 0 {
+		t.Errorf("unexpected web item: %+v", target.Items[0])
+	}
+	if target.Items[1].YamlPath != "spec.sources.1.kustomize.images.0" || target.Items[1].Source != "shop-api" {
+		t.Errorf("unexpected api item: %+v", target.Items[1])
+	}
+}
+
+func TestExpandArgoCDImageUpdater_MultipleDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	application := func(name string, constraint string) string {
+		return `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: ` + name + `
+  annotations:
+    argocd-image-updater.argoproj.io/image-list: web=nginx` + constraint + `
+spec:
+  source:
+    helm:
+      parameters:
+        - name: image.tag
+          value: 1.25.3
+`
+	}
+	staging := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n---\n" +
+		application("shop", ":>=1.0 <2.0") + "---\n" + application("blog", "")
+	production := application("shop", ":1.x") + "---\n" + application("legacy", ":newest")
+	for name, content := range map[string]string{"a-staging.yaml": staging, "b-production.yaml": production} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	config := &Config{
+		PackageSources: []*PackageSource{{Name: "blog-web"}},
+		ArgoCDImageUpdater: []*ArgoCDImageUpdater{
+			{Files: []string{filepath.Join(tmpDir, "*.yaml")}, Provider: "registry"},
+		},
+	}
+	if err := ExpandArgoCDImageUpdater(config); err != nil {
+		t.Fatalf("ExpandArgoCDImageUpdater() error = %v", err)
+	}
+
+	// The invalid constraint of the legacy application skips its only image
+	if len(config.Targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(config.Targets))
+	}
+	expected := []struct {
+		target     string
+		document   int
+		source     string
+		constraint string
+	}{
+		{target: "shop", document: 1, source: "shop-web", constraint: ">=1.0 <2.0"},
+		{target: "blog", document: 2, source: "blog-web-2"},
+		{target: "shop", document: 0, source: "shop-web-2", constraint: "1.x"},
+	}
+	for i, tt := range expected {
+		target := config.Targets[i]
+		item := target.Items[0]
+		source := config.PackageSources[i+1]
+		if target.Name != tt.target || item.Document == nil || *item.Document != tt.document {
+			t.Errorf("target %d: expected %s in document %d, got %s in %v", i, tt.target, tt.document, target.Name, item.Document)
+		}
+		if item.Source != tt.source || source.Name != tt.source || source.VersionConstraint != tt.constraint {
+			t.Errorf("target %d: expected source %s with constraint %q, got item source %s and %+v", i, tt.source, tt.constraint, item.Source, source)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultBranchTemplate is the name of update branches if the target actor sets no template
+const DefaultBranchTemplate = "chore/update/{{ .PatchGroup }}"
+
+// BranchNameData holds the fields available to branch name templates. Source and Version are
+// only set if all updates of the patch group come from the same source and go to the same version.
+type BranchNameData struct {
+	PatchGroup string
+	Source     string
+	Version    string
+	Date       string // Date of the run as YYYY-MM-DD
+}
+
+// branchInvalidCharsRegex matches characters and sequences git does not allow in branch names
+var branchInvalidCharsRegex = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]+|\.\.+|@\{`)
+
+// ParseBranchTemplate parses a branch name template, the default template if empty
+func ParseBranchTemplate(branchTemplate string) (*template.Template, error) {
+	if branchTemplate == "" {
+		branchTemplate = DefaultBranchTemplate
+	}
+	tmpl, err := template.New("branch").Option("missingkey=error").Parse(branchTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderBranchName renders a branch name template and sanitizes the result. A maxLength of
+// zero or less does not limit the length.
+func RenderBranchName(branchTemplate string, data *BranchNameData, maxLength int) (string, error) {
+	tmpl, err := ParseBranchTemplate(branchTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("failed to render branch template: %w", err)
+	}
+
+	branchName := SanitizeBranchName(name.String(), maxLength)
+	if branchName == "" {
+		return "", fmt.Errorf("branch template %q renders to an empty branch name", branchTemplate)
+	}
+	return branchName, nil
+}
+
+// SanitizeBranchName replaces characters git does not allow in branch names with dashes,
+// drops empty path segments and truncates the name to maxLength if positive
+func SanitizeBranchName(name string, maxLength int) string {
+	name = branchInvalidCharsRegex.ReplaceAllString(name, "-")
+
+	if maxLength > 0 && len(name) > maxLength {
+		name = name[:maxLength]
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(name, "/") {
+		segment = strings.Trim(segment, ".-")
+		segment = strings.TrimSuffix(segment, ".lock")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// BranchPrefix returns the static text of a branch name template before its first action,
+// which all branches rendered from it share
+func BranchPrefix(branchTemplate string) string {
+	if branchTemplate == "" {
+		branchTemplate = DefaultBranchTemplate
+	}
+	if i := strings.Index(branchTemplate, "{{"); i >= 0 {
+		return branchTemplate[:i]
+	}
+	return branchTemplate
+}
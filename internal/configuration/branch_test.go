@@ -0,0 +1,86 @@
+package configuration
+
+import "testing"
+
+func TestRenderBranchName(t *testing.T) {
+	data := &BranchNameData{PatchGroup: "frontend", Source: "nginx", Version: "1.27.0", Date: "2026-03-01"}
+
+	tests := []struct {
+		name      string
+		template  string
+		maxLength int
+		expected  string
+		expectErr bool
+	}{
+		{name: "default template", template: "", expected: "chore/update/frontend"},
+		{name: "all fields", template: "deps/{{ .PatchGroup }}/{{ .Source }}-{{ .Version }}-{{ .Date }}", expected: "deps/frontend/nginx-1.27.0-2026-03-01"},
+		{name: "truncated", template: "renovate/{{ .PatchGroup }}-{{ .Version }}", maxLength: 18, expected: "renovate/frontend"},
+		{name: "empty field collapses segment", template: "deps/{{ .PatchGroup }}/{{ .Source }}", expected: "deps/frontend/nginx"},
+		{name: "unknown field", template: "deps/{{ .Group }}", expectErr: true},
+		{name: "invalid template", template: "deps/{{ .PatchGroup", expectErr: true},
+		{name: "single field", template: "{{ .Source }}", expected: "nginx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := RenderBranchName(tt.template, data, tt.maxLength)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("Expected error, got branch name %q", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if name != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, name)
+			}
+		})
+	}
+
+	if _, err := RenderBranchName("{{ .Source }}", &BranchNameData{}, 0); err == nil {
+		t.Error("Expected error for a template rendering to an empty branch name")
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := []struct {
+		input     string
+		maxLength int
+		expected  string
+	}{
+		{input: "chore/update/default", expected: "chore/update/default"},
+		{input: "chore/update/my group", expected: "chore/update/my-group"},
+		{input: "deps/app:v1~2^3?*[x]", expected: "deps/app-v1-2-3-x]"},
+		{input: "deps//a..b/@{x}", expected: "deps/a-b/x}"},
+		{input: "deps/.hidden/", expected: "deps/hidden"},
+		{input: "deps/app.lock", expected: "deps/app"},
+		{input: "deps/very-long-name", maxLength: 10, expected: "deps/very"},
+		{input: "deps/app", maxLength: 5, expected: "deps"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeBranchName(tt.input, tt.maxLength); got != tt.expected {
+			t.Errorf("SanitizeBranchName(%q, %d) = %q, expected %q", tt.input, tt.maxLength, got, tt.expected)
+		}
+	}
+}
+
+func TestBranchPrefix(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: "", expected: "chore/update/"},
+		{template: "deps/{{ .PatchGroup }}", expected: "deps/"},
+		{template: "{{ .Date }}/{{ .PatchGroup }}", expected: ""},
+		{template: "static", expected: "static"},
+	}
+
+	for _, tt := range tests {
+		if got := BranchPrefix(tt.template); got != tt.expected {
+			t.Errorf("BranchPrefix(%q) = %q, expected %q", tt.template, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package configuration
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultChangelogTemplate renders a changelog entry if the changelog sets no template
+const DefaultChangelogTemplate = `## {{ .Date }} ({{ .PatchGroup }})
+
+{{ range .Updates }}- {{ .Item }}: {{ .From }} → {{ .To }}{{ if .Type }} ({{ .Type }}){{ end }}
+{{ end }}`
+
+// Changelog configures the changelog file of the target repository apply appends an entry to
+// with every commit
+type Changelog struct {
+	File     string `yaml:"file"`               // Path relative to the repository root, e.g. CHANGELOG.md
+	Template string `yaml:"template,omitempty"` // Go template of an entry, see ChangelogEntry
+}
+
+// ChangelogEntry holds the fields available to changelog templates
+type ChangelogEntry struct {
+	PatchGroup string
+	Date       string // Date of the run as YYYY-MM-DD
+	Updates    []*ChangelogUpdate
+}
+
+// ChangelogUpdate is a single update of a changelog entry
+type ChangelogUpdate struct {
+	Item   string
+	File   string // Path relative to the repository root
+	Source string
+	From   string
+	To     string
+	Type   string // major, minor or patch
+}
+
+// RenderChangelogEntry renders a changelog entry with the template of the changelog, the
+// default template if none is set
+func (c *Changelog) RenderChangelogEntry(entry *ChangelogEntry) (string, error) {
+	changelogTemplate := c.Template
+	if changelogTemplate == "" {
+		changelogTemplate = DefaultChangelogTemplate
+	}
+	tmpl, err := template.New("changelog").Option("missingkey=error").Parse(changelogTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid changelog template: %w", err)
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, entry); err != nil {
+		return "", fmt.Errorf("failed to render changelog template: %w", err)
+	}
+	return rendered.String(), nil
+}
+
+// validateChangelog checks the changelog file and renders its template with sample values
+func validateChangelog(result *ValidationResult, fieldPrefix string, changelog *Changelog) {
+	if strings.TrimSpace(changelog.File) == "" {
+		result.AddError(fmt.Sprintf("%s.file", fieldPrefix), "changelog file cannot be empty")
+	} else if filepath.IsAbs(changelog.File) || strings.HasPrefix(filepath.Clean(changelog.File), "..") {
+		result.AddError(fmt.Sprintf("%s.file", fieldPrefix), "changelog file must be relative to the repository root")
+	}
+
+	sample := &ChangelogEntry{
+		PatchGroup: "default",
+		Date:       "2006-01-02",
+		Updates:    []*ChangelogUpdate{{Item: "item", File: "file", Source: "source", From: "1.0.0", To: "1.1.0", Type: "minor"}},
+	}
+	if _, err := changelog.RenderChangelogEntry(sample); err != nil {
+		result.AddError(fmt.Sprintf("%s.template", fieldPrefix), err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package configuration
+
+import "testing"
+
+func TestRenderChangelogEntry(t *testing.T) {
+	entry := &ChangelogEntry{
+		PatchGroup: "production",
+		Date:       "2026-03-01",
+		Updates: []*ChangelogUpdate{
+			{Item: "nginx", File: "apps/values.yaml", Source: "nginx", From: "1.26.0", To: "1.27.0", Type: "minor"},
+			{Item: "redis", File: "apps/values.yaml", Source: "redis", From: "7.2.4", To: "7.2.5", Type: "patch"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		template  string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "default template",
+			expected: "## 2026-03-01 (production)\n\n- nginx: 1.26.0 → 1.27.0 (minor)\n- redis: 7.2.4 → 7.2.5 (patch)\n",
+		},
+		{
+			name:     "custom template",
+			template: "{{ range .Updates }}* `{{ .File }}` {{ .Source }} {{ .To }}\n{{ end }}",
+			expected: "* `apps/values.yaml` nginx 1.27.0\n* `apps/values.yaml` redis 7.2.5\n",
+		},
+		{name: "unknown field", template: "{{ .Group }}", expectErr: true},
+		{name: "invalid template", template: "{{ range .Updates }}", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelog := &Changelog{File: "CHANGELOG.md", Template: tt.template}
+			rendered, err := changelog.RenderChangelogEntry(entry)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %q", rendered)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rendered != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, rendered)
+			}
+		})
+	}
+}
+
+func TestValidateChangelog(t *testing.T) {
+	tests := []struct {
+		name        string
+		changelog   *Changelog
+		expectValid bool
+	}{
+		{name: "default template", changelog: &Changelog{File: "CHANGELOG.md"}, expectValid: true},
+		{name: "nested file", changelog: &Changelog{File: "docs/UPDATES.md", Template: "- {{ .Date }}\n"}, expectValid: true},
+		{name: "missing file", changelog: &Changelog{}, expectValid: false},
+		{name: "absolute file", changelog: &Changelog{File: "/tmp/CHANGELOG.md"}, expectValid: false},
+		{name: "file outside repository", changelog: &Changelog{File: "../CHANGELOG.md"}, expectValid: false},
+		{name: "invalid template", changelog: &Changelog{File: "CHANGELOG.md", Template: "{{ .Missing }}"}, expectValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Valid: true}
+			validateChangelog(result, "targetActor.changelog", tt.changelog)
+			if result.Valid != tt.expectValid {
+				t.Errorf("Expected valid=%v, got errors: %v", tt.expectValid, result.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TargetReadFrom is where compare reads the current version of a target
+type TargetReadFrom string
+
+const (
+	TargetReadFromFile    TargetReadFrom = "file"    // The target file (default)
+	TargetReadFromCluster TargetReadFrom = "cluster" // The resource deployed in a Kubernetes cluster
+)
+
+// Kinds of cluster resources the deployed version can be read from
+const (
+	ClusterKindDeployment  = "deployment"
+	ClusterKindStatefulSet = "statefulset"
+	ClusterKindDaemonSet   = "daemonset"
+	ClusterKindCronJob     = "cronjob"
+	ClusterKindHelmRelease = "helmrelease"
+)
+
+// ClusterReference locates the deployed version of a target in a Kubernetes cluster
+type ClusterReference struct {
+	Context   string `yaml:"context,omitempty"`   // kubeconfig context, the current context if empty
+	Namespace string `yaml:"namespace,omitempty"` // Namespace of the resource, the namespace of the context if empty
+	Resource  string `yaml:"resource"`            // Resource as kind/name, e.g. deployment/app or helmrelease/app
+	Container string `yaml:"container,omitempty"` // Container whose image holds the version, the first container if empty
+}
+
+// ParseClusterResource splits a cluster resource into its lower case kind and name
+func ParseClusterResource(resource string) (string, string, error) {
+	kind, name, ok := strings.Cut(resource, "/")
+	if !ok || kind == "" || name == "" {
+		return "", "", fmt.Errorf("invalid cluster resource %q (expected kind/name)", resource)
+	}
+
+	kind = strings.ToLower(kind)
+	switch kind {
+	case ClusterKindDeployment, ClusterKindStatefulSet, ClusterKindDaemonSet, ClusterKindCronJob, ClusterKindHelmRelease:
+		return kind, name, nil
+	default:
+		return "", "", fmt.Errorf("unsupported cluster resource kind %s (expected deployment, statefulset, daemonset, cronjob or helmrelease)", kind)
+	}
+}
+
+// ResolveClusterReference returns the cluster reference of a target item, the item's
+// overriding the target's
+func ResolveClusterReference(target *Target, item *TargetItem) *ClusterReference {
+	if item.Cluster != nil {
+		return item.Cluster
+	}
+	return target.Cluster
+}
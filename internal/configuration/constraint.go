@@ -0,0 +1,158 @@
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// constraintTermPattern matches one comparison of a version constraint, e.g. ">=1.2", "~1.4.0"
+// or "1.x". Prerelease and build suffixes of the version are ignored.
+var constraintTermPattern = regexp.MustCompile(`(!=|>=|<=|=|>|<|~|\^)?\s*[vV]?([0-9]+|[xX*])(?:\.([0-9]+|[xX*]))?(?:\.([0-9]+|[xX*]))?(?:[-+][0-9A-Za-z.-]*)?`)
+
+// VersionConstraint is a parsed versionConstraint. Comparisons separated by spaces or commas
+// must all hold, and "||" separates alternatives. Besides =, !=, >, >=, < and <=, "~1.2"
+// allows patch updates, "^1.2" allows updates below the next major version and "1.2.x"
+// matches a release line.
+type VersionConstraint struct {
+	alternatives [][]versionBound
+}
+
+// versionBound compares a version against a bound with one of =, !=, >, >=, < or <=
+type versionBound struct {
+	operator string
+	version  [3]int
+}
+
+// ParseVersionConstraint parses a semver constraint like ">=1.0 <2.0" or "~1.2 || ^2.0"
+func ParseVersionConstraint(constraint string) (*VersionConstraint, error) {
+	parsed := &VersionConstraint{}
+	for _, alternative := range strings.Split(constraint, "||") {
+		var bounds []versionBound
+		rest := alternative
+		for _, match := range constraintTermPattern.FindAllStringSubmatchIndex(alternative, -1) {
+			rest = strings.Replace(rest, alternative[match[0]:match[1]], "", 1)
+			term := make([]string, 5)
+			for i := 1; i < 5; i++ {
+				if match[2*i] >= 0 {
+					term[i] = alternative[match[2*i]:match[2*i+1]]
+				}
+			}
+			bounds = append(bounds, constraintBounds(term[1], term[2:])...)
+		}
+		if strings.Trim(rest, " ,\t") != "" {
+			return nil, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+		if len(bounds) == 0 && strings.TrimSpace(alternative) == "" {
+			return nil, fmt.Errorf("invalid version constraint %q: empty alternative", constraint)
+		}
+		parsed.alternatives = append(parsed.alternatives, bounds)
+	}
+	return parsed, nil
+}
+
+// constraintBounds converts a comparison with an optionally partial or wildcard version to
+// bounds on full versions
+func constraintBounds(operator string, parts []string) []versionBound {
+	var version [3]int
+	precision := 0
+	for i, part := range parts {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		version[i], _ = strconv.Atoi(part)
+		precision = i + 1
+	}
+
+	switch operator {
+	case "~":
+		if precision == 0 {
+			return nil
+		}
+		upper := version
+		if precision == 1 {
+			upper = [3]int{version[0] + 1, 0, 0}
+		} else {
+			upper = [3]int{version[0], version[1] + 1, 0}
+		}
+		return []versionBound{{">=", version}, {"<", upper}}
+	case "^":
+		if precision == 0 {
+			return nil
+		}
+		upper := [3]int{version[0] + 1, 0, 0}
+		switch {
+		case version[0] == 0 && precision >= 2 && version[1] > 0:
+			upper = [3]int{0, version[1] + 1, 0}
+		case version[0] == 0 && precision == 3 && version[1] == 0:
+			upper = [3]int{0, 0, version[2] + 1}
+		case version[0] == 0 && precision == 2:
+			upper = [3]int{0, 1, 0}
+		}
+		return []versionBound{{">=", version}, {"<", upper}}
+	case "", "=":
+		if precision == 3 {
+			return []versionBound{{"=", version}}
+		}
+		if precision == 0 {
+			return nil
+		}
+		// Partial versions match their whole release line
+		upper := [3]int{version[0] + 1, 0, 0}
+		if precision == 2 {
+			upper = [3]int{version[0], version[1] + 1, 0}
+		}
+		return []versionBound{{">=", version}, {"<", upper}}
+	}
+	return []versionBound{{operator, version}}
+}
+
+// Allows reports whether a version satisfies the constraint, comparing its major, minor and
+// patch numbers
+func (c *VersionConstraint) Allows(version *PackageSourceVersion) bool {
+	actual := [3]int{version.MajorVersion, version.MinorVersion, version.PatchVersion}
+	for _, bounds := range c.alternatives {
+		allowed := true
+		for _, bound := range bounds {
+			if !bound.allows(actual) {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether a version satisfies the bound
+func (b versionBound) allows(version [3]int) bool {
+	comparison := 0
+	for i := range version {
+		if version[i] != b.version[i] {
+			comparison = 1
+			if version[i] < b.version[i] {
+				comparison = -1
+			}
+			break
+		}
+	}
+
+	switch b.operator {
+	case "=":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	}
+	return false
+}
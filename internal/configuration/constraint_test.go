@@ -0,0 +1,59 @@
+package configuration
+
+import "testing"
+
+func TestVersionConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allowed    []string
+		denied     []string
+	}{
+		{constraint: ">=1.0 <2.0", allowed: []string{"1.0.0", "1.9.9"}, denied: []string{"0.9.0", "2.0.0"}},
+		{constraint: ">=1.0, <2.0", allowed: []string{"1.5.0"}, denied: []string{"2.1.0"}},
+		{constraint: ">= 4.0.0", allowed: []string{"4.0.0", "5.1.0"}, denied: []string{"3.9.9"}},
+		{constraint: "~1.2", allowed: []string{"1.2.0", "1.2.9"}, denied: []string{"1.3.0", "1.1.9"}},
+		{constraint: "~1", allowed: []string{"1.9.0"}, denied: []string{"2.0.0"}},
+		{constraint: "^1.2", allowed: []string{"1.2.0", "1.9.0"}, denied: []string{"2.0.0", "1.1.0"}},
+		{constraint: "^0.2.3", allowed: []string{"0.2.3", "0.2.9"}, denied: []string{"0.3.0"}},
+		{constraint: "^0.0.3", allowed: []string{"0.0.3"}, denied: []string{"0.0.4"}},
+		{constraint: "1.2.x", allowed: []string{"1.2.7"}, denied: []string{"1.3.0"}},
+		{constraint: "v1.x", allowed: []string{"1.7.0"}, denied: []string{"2.0.0"}},
+		{constraint: "1.2.3", allowed: []string{"v1.2.3", "1.2.3-rc1"}, denied: []string{"1.2.4"}},
+		{constraint: "!=1.2.3", allowed: []string{"1.2.4"}, denied: []string{"1.2.3"}},
+		{constraint: "~1.2 || ^3.0", allowed: []string{"1.2.5", "3.4.0"}, denied: []string{"2.0.0", "4.0.0"}},
+		{constraint: "*", allowed: []string{"0.0.1", "9.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := ParseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseVersionConstraint() failed: %v", err)
+			}
+			for _, version := range tt.allowed {
+				if !constraint.Allows(parseTestVersion(version)) {
+					t.Errorf("expected %s to allow %s", tt.constraint, version)
+				}
+			}
+			for _, version := range tt.denied {
+				if constraint.Allows(parseTestVersion(version)) {
+					t.Errorf("expected %s to deny %s", tt.constraint, version)
+				}
+			}
+		})
+	}
+}
+
+func TestParseVersionConstraint_Invalid(t *testing.T) {
+	for _, constraint := range []string{"", "latest", ">=1.0 ||", "1.0 and 2.0"} {
+		if _, err := ParseVersionConstraint(constraint); err == nil {
+			t.Errorf("expected %q to be invalid", constraint)
+		}
+	}
+}
+
+// parseTestVersion returns a source version with its semver components
+func parseTestVersion(version string) *PackageSourceVersion {
+	major, minor, patch := ParseSemver(version)
+	return &PackageSourceVersion{Version: version, MajorVersion: major, MinorVersion: minor, PatchVersion: patch}
+}
@@ -0,0 +1,95 @@
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// DefaultConventionalCommitScope is the scope of conventional commit messages if none is configured
+const DefaultConventionalCommitScope = "deps"
+
+// defaultConventionalCommitTypes maps update types to commit types if not configured. Patch
+// updates are fixes; minor and major updates do not trigger a feature release by default.
+var defaultConventionalCommitTypes = map[string]string{
+	"major": "chore",
+	"minor": "chore",
+	"patch": "fix",
+}
+
+// conventionalCommitTypeRegex matches valid commit types and scopes
+var conventionalCommitTypeRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// ConventionalCommits configures commit messages and pull request titles following the
+// Conventional Commits specification, e.g. "fix(deps): update nginx to 1.27.1"
+type ConventionalCommits struct {
+	Scope string            `yaml:"scope,omitempty"` // Commit scope, defaults to "deps"
+	Types map[string]string `yaml:"types,omitempty"` // Commit type per update type (major, minor, patch)
+}
+
+// Prefix returns the type and scope of a commit containing updates of the given update types,
+// e.g. "fix(deps)". Of several types, the one with the largest release impact wins
+// (feat before fix before any other type), so semantic release pipelines never miss a change.
+func (c *ConventionalCommits) Prefix(updateTypes []string) string {
+	commitType := ""
+	for _, updateType := range updateTypes {
+		candidate := c.Type(updateType)
+		if commitType == "" || conventionalCommitTypeRank(candidate) > conventionalCommitTypeRank(commitType) {
+			commitType = candidate
+		}
+	}
+	if commitType == "" {
+		commitType = "chore"
+	}
+
+	scope := c.Scope
+	if scope == "" {
+		scope = DefaultConventionalCommitScope
+	}
+	return fmt.Sprintf("%s(%s)", commitType, scope)
+}
+
+// Type returns the commit type of an update type
+func (c *ConventionalCommits) Type(updateType string) string {
+	if commitType, ok := c.Types[updateType]; ok && commitType != "" {
+		return commitType
+	}
+	if commitType, ok := defaultConventionalCommitTypes[updateType]; ok {
+		return commitType
+	}
+	return "chore"
+}
+
+// conventionalCommitTypeRank orders commit types by the release they trigger
+func conventionalCommitTypeRank(commitType string) int {
+	switch commitType {
+	case "feat":
+		return 2
+	case "fix":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// validateConventionalCommits checks the update types and the commit types and scope
+func validateConventionalCommits(result *ValidationResult, fieldPrefix string, conventionalCommits *ConventionalCommits) {
+	if conventionalCommits.Scope != "" && !conventionalCommitTypeRegex.MatchString(conventionalCommits.Scope) {
+		result.AddError(fmt.Sprintf("%s.scope", fieldPrefix), fmt.Sprintf("invalid commit scope: %s (lowercase letters, digits and dashes)", conventionalCommits.Scope))
+	}
+	updateTypes := make([]string, 0, len(conventionalCommits.Types))
+	for updateType := range conventionalCommits.Types {
+		updateTypes = append(updateTypes, updateType)
+	}
+	sort.Strings(updateTypes)
+
+	for _, updateType := range updateTypes {
+		commitType := conventionalCommits.Types[updateType]
+		if !isValidUpdateType(updateType) {
+			result.AddError(fmt.Sprintf("%s.types.%s", fieldPrefix, updateType), fmt.Sprintf("invalid update type: %s (must be major, minor, or patch)", updateType))
+		}
+		if !conventionalCommitTypeRegex.MatchString(commitType) {
+			result.AddError(fmt.Sprintf("%s.types.%s", fieldPrefix, updateType), fmt.Sprintf("invalid commit type: %s (lowercase letters, digits and dashes)", commitType))
+		}
+	}
+}
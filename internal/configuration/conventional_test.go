@@ -0,0 +1,58 @@
+package configuration
+
+import "testing"
+
+func TestConventionalCommitsPrefix(t *testing.T) {
+	tests := []struct {
+		name        string
+		convention  *ConventionalCommits
+		updateTypes []string
+		expected    string
+	}{
+		{name: "patch defaults to fix", convention: &ConventionalCommits{}, updateTypes: []string{"patch"}, expected: "fix(deps)"},
+		{name: "minor defaults to chore", convention: &ConventionalCommits{}, updateTypes: []string{"minor"}, expected: "chore(deps)"},
+		{name: "major defaults to chore", convention: &ConventionalCommits{}, updateTypes: []string{"major"}, expected: "chore(deps)"},
+		{name: "fix wins over chore", convention: &ConventionalCommits{}, updateTypes: []string{"minor", "patch"}, expected: "fix(deps)"},
+		{
+			name:        "feat wins over fix",
+			convention:  &ConventionalCommits{Types: map[string]string{"minor": "feat"}},
+			updateTypes: []string{"patch", "minor"},
+			expected:    "feat(deps)",
+		},
+		{name: "custom scope", convention: &ConventionalCommits{Scope: "images"}, updateTypes: []string{"patch"}, expected: "fix(images)"},
+		{name: "unknown update type", convention: &ConventionalCommits{}, updateTypes: []string{""}, expected: "chore(deps)"},
+		{name: "no updates", convention: &ConventionalCommits{}, updateTypes: nil, expected: "chore(deps)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.convention.Prefix(tt.updateTypes); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateConventionalCommits(t *testing.T) {
+	tests := []struct {
+		name        string
+		convention  *ConventionalCommits
+		expectValid bool
+	}{
+		{name: "defaults", convention: &ConventionalCommits{}, expectValid: true},
+		{name: "custom types", convention: &ConventionalCommits{Scope: "deps", Types: map[string]string{"major": "feat", "minor": "feat"}}, expectValid: true},
+		{name: "invalid update type", convention: &ConventionalCommits{Types: map[string]string{"breaking": "feat"}}, expectValid: false},
+		{name: "invalid commit type", convention: &ConventionalCommits{Types: map[string]string{"patch": "Fix!"}}, expectValid: false},
+		{name: "invalid scope", convention: &ConventionalCommits{Scope: "deps)"}, expectValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Valid: true}
+			validateConventionalCommits(result, "targetActor.conventionalCommits", tt.convention)
+			if result.Valid != tt.expectValid {
+				t.Errorf("Expected valid=%v, got errors: %v", tt.expectValid, result.Errors)
+			}
+		})
+	}
+}
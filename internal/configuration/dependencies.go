@@ -0,0 +1,94 @@
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReferencedSources returns the names of the package sources referenced by the items of
+// the configured targets. Only these sources need to be scraped to compare the targets.
+func ReferencedSources(config *Config) map[string]bool {
+	sources := make(map[string]bool)
+	for _, target := range config.Targets {
+		for _, item := range target.Items {
+			if item.Source != "" {
+				sources[item.Source] = true
+			}
+		}
+	}
+	return sources
+}
+
+// DiscoverySource returns the discovery source a name of the form <source>/<package> refers
+// to, e.g. the docker-namespace source acme for acme/api, or nil if the name refers to none
+func DiscoverySource(sources []*PackageSource, name string) *PackageSource {
+	var discovery *PackageSource
+	for _, source := range sources {
+		if !source.Discovers() || !strings.HasPrefix(name, source.Name+"/") || len(name) == len(source.Name)+1 {
+			continue
+		}
+		// Scoped names contain slashes as well, the longest matching source wins
+		if discovery == nil || len(source.Name) > len(discovery.Name) {
+			discovery = source
+		}
+	}
+	return discovery
+}
+
+// FilterTargets restricts the configured targets to the given target names. An empty
+// name list keeps all targets. Expanded wildcard targets share the name of their pattern
+// target and are kept together.
+func FilterTargets(config *Config, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	filtered := make([]*Target, 0, len(names))
+	found := make(map[string]bool, len(names))
+	for _, target := range config.Targets {
+		if wanted[target.Name] {
+			filtered = append(filtered, target)
+			found[target.Name] = true
+		}
+	}
+
+	unknown := make([]string, 0)
+	for name := range wanted {
+		if !found[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown target(s): %s", strings.Join(unknown, ", "))
+	}
+
+	config.Targets = filtered
+	return nil
+}
+
+// FilterSources restricts the items of the configured targets to those whose source is kept.
+// Targets without items left are dropped.
+func FilterSources(config *Config, keep map[string]bool) {
+	filtered := make([]*Target, 0, len(config.Targets))
+	for _, target := range config.Targets {
+		items := make([]TargetItem, 0, len(target.Items))
+		for _, item := range target.Items {
+			if keep[item.Source] {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		target.Items = items
+		filtered = append(filtered, target)
+	}
+	config.Targets = filtered
+}
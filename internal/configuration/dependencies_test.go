@@ -0,0 +1,126 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestReferencedSources(t *testing.T) {
+	config := &Config{
+		PackageSources: []*PackageSource{
+			{Name: "backend"},
+			{Name: "frontend"},
+			{Name: "unused"},
+		},
+		Targets: []*Target{
+			{
+				Name: "app",
+				Items: []TargetItem{
+					{Name: "backend", Source: "backend"},
+					{Name: "frontend", Source: "frontend"},
+				},
+			},
+			{
+				Name: "worker",
+				Items: []TargetItem{
+					{Name: "backend", Source: "backend"},
+				},
+			},
+		},
+	}
+
+	sources := ReferencedSources(config)
+	if len(sources) != 2 || !sources["backend"] || !sources["frontend"] {
+		t.Errorf("Expected backend and frontend, got %v", sources)
+	}
+	if sources["unused"] {
+		t.Errorf("Unreferenced source should not be included")
+	}
+}
+
+func TestFilterTargets(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			Targets: []*Target{
+				{Name: "app", File: "a/Chart.yaml"},
+				{Name: "app", File: "b/Chart.yaml"},
+				{Name: "worker", File: "worker.tf"},
+				{Name: "infra", File: "infra.tf"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		names         []string
+		expectTargets int
+		errorContains string
+	}{
+		{
+			name:          "no filter keeps all targets",
+			names:         nil,
+			expectTargets: 4,
+		},
+		{
+			name:          "filter keeps expanded wildcard targets together",
+			names:         []string{"app"},
+			expectTargets: 2,
+		},
+		{
+			name:          "multiple names",
+			names:         []string{"app", "infra"},
+			expectTargets: 3,
+		},
+		{
+			name:          "unknown target",
+			names:         []string{"app", "missing"},
+			errorContains: "unknown target(s): missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newConfig()
+			err := FilterTargets(config, tt.names)
+			if tt.errorContains != "" {
+				if err == nil || !contains(err.Error(), tt.errorContains) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(config.Targets) != tt.expectTargets {
+				t.Errorf("Expected %d targets, got %d", tt.expectTargets, len(config.Targets))
+			}
+		})
+	}
+}
+
+func TestFilterSources(t *testing.T) {
+	config := &Config{
+		Targets: []*Target{
+			{
+				Name: "app",
+				Items: []TargetItem{
+					{Name: "backend", Source: "backend"},
+					{Name: "frontend", Source: "frontend"},
+				},
+			},
+			{
+				Name: "worker",
+				Items: []TargetItem{
+					{Name: "frontend", Source: "frontend"},
+				},
+			},
+		},
+	}
+
+	FilterSources(config, map[string]bool{"backend": true})
+	if len(config.Targets) != 1 || config.Targets[0].Name != "app" {
+		t.Fatalf("Expected only target app, got %d targets", len(config.Targets))
+	}
+	if items := config.Targets[0].Items; len(items) != 1 || items[0].Source != "backend" {
+		t.Errorf("Expected only the backend item, got %v", items)
+	}
+}
@@ -0,0 +1,8 @@
+package configuration
+
+// DigestPin marks a yaml-field item whose yamlPath holds an image digest (sha256:... or
+// image@sha256:...) instead of a tag. The human-readable tag is kept next to the digest,
+// compared as the current version and updated together with the digest.
+type DigestPin struct {
+	TagField string `yaml:"tagField,omitempty"` // Sibling field holding the tag, a "# tag: <tag>" comment on the digest line if empty
+}
@@ -0,0 +1,79 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfiguration_DigestPin(t *testing.T) {
+	newConfig := func(targetType TargetType, sourceType PackageSourceType, digestPin *DigestPin) *Config {
+		item := TargetItem{Source: "app", DigestPin: digestPin}
+		if targetType == TargetTypeYamlField {
+			item.YamlPath = "image.digest"
+		} else {
+			item.Name = "app_version"
+		}
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "dockerhub", Type: PackageSourceProviderTypeDocker},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "dockerhub", Type: sourceType, URI: "example/app"},
+			},
+			Targets: []*Target{
+				{Name: "app", Type: targetType, File: "values.yaml", Items: []TargetItem{item}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "tag comment",
+			config:      newConfig(TargetTypeYamlField, PackageSourceTypeDockerImage, &DigestPin{}),
+			expectValid: true,
+		},
+		{
+			name:        "sibling tag field",
+			config:      newConfig(TargetTypeYamlField, PackageSourceTypeDockerImage, &DigestPin{TagField: "tag"}),
+			expectValid: true,
+		},
+		{
+			name:          "nested tag field",
+			config:        newConfig(TargetTypeYamlField, PackageSourceTypeDockerImage, &DigestPin{TagField: "image.tag"}),
+			errorContains: "tagField must be the key of a field next to the digest",
+		},
+		{
+			name:          "other source type",
+			config:        newConfig(TargetTypeYamlField, PackageSourceTypeGitRelease, &DigestPin{}),
+			errorContains: "digestPin requires a docker-image source",
+		},
+		{
+			name:          "other target type",
+			config:        newConfig(TargetTypeTerraformVariable, PackageSourceTypeDockerImage, &DigestPin{}),
+			errorContains: "digestPin is only supported for yaml-field targets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+			if result.Valid != tt.expectValid {
+				t.Fatalf("Expected valid=%v, got valid=%v, errors: %v", tt.expectValid, result.Valid, result.Errors)
+			}
+			if tt.errorContains == "" {
+				return
+			}
+			for _, err := range result.Errors {
+				if strings.Contains(err.Message, tt.errorContains) {
+					return
+				}
+			}
+			t.Errorf("Expected error containing %q, got: %v", tt.errorContains, result.Errors)
+		})
+	}
+}
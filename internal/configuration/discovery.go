@@ -0,0 +1,150 @@
+package configuration
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RecursiveConfigFileName is the file name of the configurations discovered by
+// LoadRecursiveConfiguration
+const RecursiveConfigFileName = ".updaterconfig.yml"
+
+// DiscoverConfigurations returns all .updaterconfig.yml files under root, sorted by path.
+// Hidden directories such as .git are not searched.
+func DiscoverConfigurations(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && len(d.Name()) > 1 && d.Name()[0] == '.' {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == RecursiveConfigFileName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover configurations: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadRecursiveConfiguration discovers all .updaterconfig.yml files under root and merges
+// them into a single configuration. Each configuration is scoped to its directory: names of
+// providers, sources, targets, patch groups and version sets are prefixed with the directory
+// relative to root, and relative file paths are resolved against the directory.
+//
+// With an environment, configurations defining environments get its overrides applied;
+// configurations without an environments section are used as they are.
+func LoadRecursiveConfiguration(root string, environment string) (*Config, error) {
+	files, err := DiscoverConfigurations(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files found under %s", RecursiveConfigFileName, root)
+	}
+
+	log.Debug().
+		Str("root", root).
+		Int("fileCount", len(files)).
+		Msg("Discovered configurations")
+
+	configs := make([]*Config, 0, len(files))
+	for _, file := range files {
+		config, err := loadSingleConfigurationFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		if config, err = resolveIncludes(config, nil); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		if err := ExpandTargetTemplates(config); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+
+		if len(config.Environments) > 0 || environment == "" {
+			if err := ApplyEnvironment(config, environment); err != nil {
+				return nil, fmt.Errorf("failed to load %s: %w", file, err)
+			}
+		}
+
+		dir := filepath.Dir(file)
+		scope, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve scope of %s: %w", file, err)
+		}
+		scopeConfiguration(config, filepath.ToSlash(scope), dir)
+		configs = append(configs, config)
+	}
+
+	config, err := mergeConfigurations(configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge configurations: %w", err)
+	}
+
+	return prepareConfiguration(config)
+}
+
+// scopeConfiguration isolates a discovered configuration from its siblings. The
+// configuration at the root itself (scope ".") keeps its names.
+func scopeConfiguration(config *Config, scope string, dir string) {
+	name := func(value string) string {
+		if scope == "." || value == "" {
+			return value
+		}
+		return scope + "/" + value
+	}
+	path := func(value string) string {
+		if value == "" || filepath.IsAbs(value) {
+			return value
+		}
+		return filepath.Join(dir, value)
+	}
+
+	for _, provider := range config.PackageSourceProviders {
+		provider.Name = name(provider.Name)
+	}
+	for _, source := range config.PackageSources {
+		source.Name = name(source.Name)
+		source.Provider = name(source.Provider)
+	}
+	for _, target := range config.Targets {
+		target.Name = name(target.Name)
+		target.File = path(target.File)
+		for i := range target.ExcludeFiles {
+			target.ExcludeFiles[i] = path(target.ExcludeFiles[i])
+		}
+		target.VersionSet = name(target.VersionSet)
+		if target.PatchGroup == "" && scope != "." {
+			// Keep the default patch group of each configuration in its own pull request
+			target.PatchGroup = scope
+		} else {
+			target.PatchGroup = name(target.PatchGroup)
+		}
+		for i := range target.Items {
+			item := &target.Items[i]
+			item.Source = name(item.Source)
+			item.PatchGroup = name(item.PatchGroup)
+			item.VersionSet = name(item.VersionSet)
+		}
+	}
+	for _, ingest := range config.ArgoCDImageUpdater {
+		ingest.Provider = name(ingest.Provider)
+		ingest.PatchGroup = name(ingest.PatchGroup)
+		for i := range ingest.Files {
+			ingest.Files[i] = path(ingest.Files[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRecursiveConfiguration(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		RecursiveConfigFileName: `packageSourceProviders:
+  - name: dockerhub
+    type: docker
+`,
+		filepath.Join("teams", "web", RecursiveConfigFileName): `packageSourceProviders:
+  - name: dockerhub
+    type: docker
+packageSources:
+  - name: nginx
+    provider: dockerhub
+    type: docker-image
+    uri: nginx
+targets:
+  - name: web
+    type: yaml-field
+    file: values.yaml
+    items:
+      - yamlPath: image.tag
+        source: nginx
+`,
+		filepath.Join("teams", "api", RecursiveConfigFileName): `packageSourceProviders:
+  - name: dockerhub
+    type: docker
+packageSources:
+  - name: nginx
+    provider: dockerhub
+    type: docker-image
+    uri: nginx
+targets:
+  - name: web
+    type: yaml-field
+    file: values.yaml
+    patchGroup: weekly
+    items:
+      - yamlPath: image.tag
+        source: nginx
+`,
+		filepath.Join(".git", RecursiveConfigFileName): `targets: [{name: ignored}]`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	discovered, err := DiscoverConfigurations(root)
+	if err != nil {
+		t.Fatalf("DiscoverConfigurations() error = %v", err)
+	}
+	if len(discovered) != 3 {
+		t.Fatalf("expected 3 configurations, got %d: %v", len(discovered), discovered)
+	}
+
+	config, err := LoadRecursiveConfiguration(root, "")
+	if err != nil {
+		t.Fatalf("LoadRecursiveConfiguration() error = %v", err)
+	}
+
+	if len(config.PackageSourceProviders) != 3 {
+		t.Errorf("expected 3 providers, got %d", len(config.PackageSourceProviders))
+	}
+	if config.PackageSourceProviders[0].Name != "dockerhub" {
+		t.Errorf("root provider should keep its name, got %s", config.PackageSourceProviders[0].Name)
+	}
+
+	targets := make(map[string]*Target)
+	for _, target := range config.Targets {
+		targets[target.Name] = target
+	}
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(config.Targets))
+	}
+
+	api := targets["teams/api/web"]
+	if api == nil {
+		t.Fatal("expected target teams/api/web")
+	}
+	if api.File != filepath.Join(root, "teams", "api", "values.yaml") {
+		t.Errorf("expected file resolved against config directory, got %s", api.File)
+	}
+	if api.PatchGroup != "teams/api/weekly" {
+		t.Errorf("expected scoped patch group, got %s", api.PatchGroup)
+	}
+	if api.Items[0].Source != "teams/api/nginx" {
+		t.Errorf("expected scoped source reference, got %s", api.Items[0].Source)
+	}
+
+	web := targets["teams/web/web"]
+	if web == nil {
+		t.Fatal("expected target teams/web/web")
+	}
+	if web.PatchGroup != "teams/web" {
+		t.Errorf("expected default patch group to be the scope, got %s", web.PatchGroup)
+	}
+
+	if result := ValidateConfiguration(config); !result.Valid {
+		t.Errorf("merged configuration should be valid: %v", result.Errors)
+	}
+}
+
+func TestLoadRecursiveConfiguration_NoFiles(t *testing.T) {
+	if _, err := LoadRecursiveConfiguration(t.TempDir(), ""); err == nil {
+		t.Error("expected error when no configuration is found")
+	}
+}
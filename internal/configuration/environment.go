@@ -0,0 +1,85 @@
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// ApplyEnvironment applies the overrides of the named environment to the sources and targets
+// of a configuration as written. An empty name keeps the base configuration.
+func ApplyEnvironment(config *Config, name string) error {
+	environments := config.Environments
+	config.Environments = nil
+	if name == "" {
+		return nil
+	}
+
+	environment, ok := environments[name]
+	if !ok {
+		available := make([]string, 0, len(environments))
+		for environmentName := range environments {
+			available = append(available, environmentName)
+		}
+		sort.Strings(available)
+		return fmt.Errorf("environment '%s' is not defined (available: %s)", name, strings.Join(available, ", "))
+	}
+
+	log.Debug().Str("environment", name).Msg("Applying environment overrides")
+
+	var err error
+	if config.PackageSources, err = applyOverrides(config.PackageSources, environment.PackageSources, func(s *PackageSource) string { return s.Name }); err != nil {
+		return fmt.Errorf("environment %s: invalid package source override: %w", name, err)
+	}
+	if config.Targets, err = applyOverrides(config.Targets, environment.Targets, func(t *Target) string { return t.Name }); err != nil {
+		return fmt.Errorf("environment %s: invalid target override: %w", name, err)
+	}
+	return nil
+}
+
+// applyOverrides decodes each override onto a copy of the base entry with the same name, so
+// that only the keys present in the override change. Overrides without a matching entry
+// are appended as new entries.
+func applyOverrides[T any](base []*T, overrides []yaml.Node, name func(*T) string) ([]*T, error) {
+	result := append([]*T{}, base...)
+	for i := range overrides {
+		node := &overrides[i]
+
+		var key struct {
+			Name string `yaml:"name"`
+		}
+		if err := node.Decode(&key); err != nil {
+			return nil, err
+		}
+		if key.Name == "" {
+			return nil, fmt.Errorf("override at line %d has no name", node.Line)
+		}
+
+		index := -1
+		for j, entry := range result {
+			if name(entry) == key.Name {
+				index = j
+				break
+			}
+		}
+
+		entry := new(T)
+		if index != -1 {
+			// Copy so shared definitions (e.g. from includes) are not modified
+			*entry = *result[index]
+		}
+		if err := node.Decode(entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", key.Name, err)
+		}
+
+		if index != -1 {
+			result[index] = entry
+		} else {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
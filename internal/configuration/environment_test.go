@@ -0,0 +1,128 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const environmentTestConfig = `
+packageSources:
+  - name: app
+    provider: harbor
+    type: docker-image
+    uri: team/app
+    tagPattern: "^\\d+\\.\\d+\\.\\d+$"
+targets:
+  - name: app
+    type: yaml-field
+    file: envs/dev/values.yaml
+    maxUpdateType: major
+    items:
+      - yamlPath: image.tag
+        source: app
+environments:
+  prod:
+    packageSources:
+      - name: app
+        tagPattern: "^1\\.\\d+\\.\\d+$"
+    targets:
+      - name: app
+        file: envs/prod/values.yaml
+        maxUpdateType: patch
+      - name: app-canary
+        type: yaml-field
+        file: envs/prod/canary.yaml
+        items:
+          - yamlPath: image.tag
+            source: app
+`
+
+func TestApplyEnvironment(t *testing.T) {
+	tests := []struct {
+		name          string
+		environment   string
+		wantErr       string
+		tagPattern    string
+		file          string
+		maxUpdateType string
+		targetCount   int
+	}{
+		{
+			name:          "base configuration",
+			environment:   "",
+			tagPattern:    `^\d+\.\d+\.\d+$`,
+			file:          "envs/dev/values.yaml",
+			maxUpdateType: "major",
+			targetCount:   1,
+		},
+		{
+			name:          "prod overrides",
+			environment:   "prod",
+			tagPattern:    `^1\.\d+\.\d+$`,
+			file:          "envs/prod/values.yaml",
+			maxUpdateType: "patch",
+			targetCount:   2,
+		},
+		{
+			name:        "unknown environment",
+			environment: "staging",
+			wantErr:     "environment 'staging' is not defined (available: prod)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			if err := yaml.Unmarshal([]byte(environmentTestConfig), &config); err != nil {
+				t.Fatalf("Failed to parse test config: %v", err)
+			}
+
+			err := ApplyEnvironment(&config, tt.environment)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyEnvironment() error = %v", err)
+			}
+
+			if config.Environments != nil {
+				t.Error("environments should be cleared after applying")
+			}
+			source := config.PackageSources[0]
+			if source.TagPattern != tt.tagPattern || source.URI != "team/app" {
+				t.Errorf("unexpected source after override: %+v", source)
+			}
+			if len(config.Targets) != tt.targetCount {
+				t.Fatalf("expected %d targets, got %d", tt.targetCount, len(config.Targets))
+			}
+			target := config.Targets[0]
+			if target.File != tt.file || target.MaxUpdateType != tt.maxUpdateType {
+				t.Errorf("unexpected target after override: %+v", target)
+			}
+			if len(target.Items) != 1 || target.Items[0].YamlPath != "image.tag" {
+				t.Errorf("items not set by the override should be kept, got %+v", target.Items)
+			}
+		})
+	}
+}
+
+func TestApplyEnvironment_OverrideWithoutName(t *testing.T) {
+	var config Config
+	if err := yaml.Unmarshal([]byte(`
+environments:
+  prod:
+    targets:
+      - file: values.yaml
+`), &config); err != nil {
+		t.Fatalf("Failed to parse test config: %v", err)
+	}
+
+	if err := ApplyEnvironment(&config, "prod"); err == nil {
+		t.Error("expected error for override without name")
+	}
+}
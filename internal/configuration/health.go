@@ -0,0 +1,166 @@
+package configuration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mxcd/updater/internal/util"
+)
+
+// DefaultStaleAfter is the period without a new version after which a source is reported
+// as stale unless it sets staleAfter
+const DefaultStaleAfter = 180 * 24 * time.Hour
+
+// UnhealthyFailureCount is the number of consecutive scrape failures after which a source
+// is reported as failing
+const UnhealthyFailureCount = 3
+
+// SourceHealth records the scrape history of a source across runs
+type SourceHealth struct {
+	LatestVersion       string    `json:"latestVersion,omitempty"`
+	NewVersionAt        time.Time `json:"newVersionAt,omitempty"` // When LatestVersion was first observed
+	LastSuccessAt       time.Time `json:"lastSuccessAt,omitempty"`
+	LastFailureAt       time.Time `json:"lastFailureAt,omitempty"`
+	ConsecutiveFailures int       `json:"consecutiveFailures,omitempty"`
+	LastError           string    `json:"lastError,omitempty"`
+}
+
+// HealthState holds the health of all sources of a configuration by source name
+type HealthState struct {
+	Sources map[string]*SourceHealth `json:"sources"`
+}
+
+// SourceHealthIssue describes a source that is stale or keeps failing, which usually means
+// the repository moved or the image was renamed
+type SourceHealthIssue struct {
+	SourceName string
+	Message    string
+}
+
+// DefaultHealthStatePath returns the health state file of a configuration in the user cache
+// directory, keyed by the absolute configuration path
+func DefaultHealthStatePath(configPath string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Join(cacheDir, "updater", "health", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadHealthState reads a health state file. A missing file yields an empty state.
+func LoadHealthState(path string) (*HealthState, error) {
+	state := &HealthState{Sources: make(map[string]*SourceHealth)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read health state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse health state %s: %w", path, err)
+	}
+	if state.Sources == nil {
+		state.Sources = make(map[string]*SourceHealth)
+	}
+	return state, nil
+}
+
+// Save writes the health state file, creating its directory if needed
+func (s *HealthState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode health state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create health state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write health state: %w", err)
+	}
+	return nil
+}
+
+func (s *HealthState) source(name string) *SourceHealth {
+	health, ok := s.Sources[name]
+	if !ok {
+		health = &SourceHealth{}
+		s.Sources[name] = health
+	}
+	return health
+}
+
+// RecordSuccess records a successful scrape whose newest version is latestVersion. The
+// staleness clock restarts whenever the newest version changes.
+func (s *HealthState) RecordSuccess(name string, latestVersion string, now time.Time) {
+	health := s.source(name)
+	if latestVersion != "" && latestVersion != health.LatestVersion {
+		health.LatestVersion = latestVersion
+		health.NewVersionAt = now
+	}
+	health.LastSuccessAt = now
+	health.ConsecutiveFailures = 0
+	health.LastError = ""
+}
+
+// RecordFailure records a failed scrape
+func (s *HealthState) RecordFailure(name string, err error, now time.Time) {
+	health := s.source(name)
+	health.LastFailureAt = now
+	health.ConsecutiveFailures++
+	if err != nil {
+		health.LastError = err.Error()
+	}
+}
+
+// Issues returns the sources of the configuration that have failed UnhealthyFailureCount
+// times in a row or have not produced a new version within their staleAfter period
+// (defaultStaleAfter if unset), in configuration order
+func (s *HealthState) Issues(config *Config, defaultStaleAfter time.Duration, now time.Time) []*SourceHealthIssue {
+	issues := make([]*SourceHealthIssue, 0)
+	for _, source := range config.PackageSources {
+		health, ok := s.Sources[source.Name]
+		if !ok {
+			continue
+		}
+
+		if health.ConsecutiveFailures >= UnhealthyFailureCount {
+			issues = append(issues, &SourceHealthIssue{
+				SourceName: source.Name,
+				Message:    fmt.Sprintf("failed to scrape %d times in a row (last error: %s), the repository or image may have moved", health.ConsecutiveFailures, health.LastError),
+			})
+			continue
+		}
+
+		staleAfter := defaultStaleAfter
+		if source.StaleAfter != "" {
+			parsed, err := util.ParseDuration(source.StaleAfter)
+			if err != nil {
+				continue
+			}
+			staleAfter = parsed
+		}
+		if staleAfter <= 0 || health.NewVersionAt.IsZero() {
+			continue
+		}
+		if age := now.Sub(health.NewVersionAt); age > staleAfter {
+			issues = append(issues, &SourceHealthIssue{
+				SourceName: source.Name,
+				Message:    fmt.Sprintf("no new version since %s (latest %s), the repository or image may have moved", health.NewVersionAt.Format("2006-01-02"), health.LatestVersion),
+			})
+		}
+	}
+
+	return issues
+}
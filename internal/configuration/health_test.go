@@ -0,0 +1,130 @@
+package configuration
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthState_Issues(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		staleAfter string
+		record     func(state *HealthState)
+		expected   string
+	}{
+		{
+			name: "healthy source",
+			record: func(state *HealthState) {
+				state.RecordSuccess("app", "1.0.0", now.AddDate(0, 0, -200))
+				state.RecordSuccess("app", "1.1.0", now.AddDate(0, 0, -10))
+			},
+		},
+		{
+			name: "stale source",
+			record: func(state *HealthState) {
+				state.RecordSuccess("app", "1.0.0", now.AddDate(0, 0, -200))
+				state.RecordSuccess("app", "1.0.0", now)
+			},
+			expected: "no new version since 2024-11-13",
+		},
+		{
+			name:       "staleAfter of the source overrides the default",
+			staleAfter: "365d",
+			record: func(state *HealthState) {
+				state.RecordSuccess("app", "1.0.0", now.AddDate(0, 0, -200))
+			},
+		},
+		{
+			name: "consistently failing source",
+			record: func(state *HealthState) {
+				state.RecordSuccess("app", "1.0.0", now.AddDate(0, 0, -3))
+				for i := 0; i < UnhealthyFailureCount; i++ {
+					state.RecordFailure("app", errors.New("HTTP 404"), now)
+				}
+			},
+			expected: "failed to scrape 3 times in a row (last error: HTTP 404)",
+		},
+		{
+			name: "success resets failures",
+			record: func(state *HealthState) {
+				state.RecordFailure("app", errors.New("HTTP 404"), now)
+				state.RecordFailure("app", errors.New("HTTP 404"), now)
+				state.RecordSuccess("app", "1.0.0", now)
+				state.RecordFailure("app", errors.New("HTTP 404"), now)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{PackageSources: []*PackageSource{{Name: "app", StaleAfter: tt.staleAfter}}}
+			state := &HealthState{Sources: make(map[string]*SourceHealth)}
+			tt.record(state)
+
+			issues := state.Issues(config, DefaultStaleAfter, now)
+			if tt.expected == "" {
+				if len(issues) != 0 {
+					t.Fatalf("Expected no issues, got %q", issues[0].Message)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d", len(issues))
+			}
+			if !strings.Contains(issues[0].Message, tt.expected) {
+				t.Errorf("Expected message containing %q, got %q", tt.expected, issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestHealthState_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health", "state.json")
+
+	state, err := LoadHealthState(path)
+	if err != nil {
+		t.Fatalf("Failed to load missing health state: %v", err)
+	}
+	if len(state.Sources) != 0 {
+		t.Fatalf("Expected empty health state, got %d sources", len(state.Sources))
+	}
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	state.RecordSuccess("app", "1.0.0", now)
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Failed to save health state: %v", err)
+	}
+
+	loaded, err := LoadHealthState(path)
+	if err != nil {
+		t.Fatalf("Failed to load health state: %v", err)
+	}
+	health := loaded.Sources["app"]
+	if health == nil || health.LatestVersion != "1.0.0" || !health.NewVersionAt.Equal(now) {
+		t.Errorf("Unexpected health state: %+v", health)
+	}
+}
+
+func TestLintSourceHealth(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	config := &Config{PackageSources: []*PackageSource{{Name: "fresh"}, {Name: "moved"}}}
+	state := &HealthState{Sources: make(map[string]*SourceHealth)}
+	state.RecordSuccess("fresh", "2.0.0", now)
+	state.RecordSuccess("moved", "1.0.0", now.AddDate(-1, 0, 0))
+
+	result := &LintResult{}
+	LintSourceHealth(result, config, state, DefaultStaleAfter, now)
+
+	if len(result.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(result.Findings))
+	}
+	finding := result.Findings[0]
+	if finding.RuleID != LintRuleUnhealthySource.ID || finding.Field != "packageSources[1]" {
+		t.Errorf("Unexpected finding: %s", finding.Error())
+	}
+}
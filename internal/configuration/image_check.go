@@ -0,0 +1,57 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImageCheck verifies before apply that a new tag can be pulled from the registry the
+// target deploys from, which may differ from the registry the source is scraped from
+type ImageCheck struct {
+	Image     string   `yaml:"image,omitempty"`     // Image the target deploys, e.g. registry.example.com/team/app, the source image if empty
+	Provider  string   `yaml:"provider,omitempty"`  // Docker provider whose credentials the registry is queried with, anonymous if empty
+	Platforms []string `yaml:"platforms,omitempty"` // Platforms the tag must be published for, e.g. linux/arm64
+}
+
+// Platform is an os/architecture[/variant] an image is published for
+type Platform struct {
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// String formats the platform as os/architecture[/variant]
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return fmt.Sprintf("%s/%s/%s", p.OS, p.Architecture, p.Variant)
+	}
+	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+}
+
+// ParsePlatform parses a platform given as os/architecture[/variant]
+func ParsePlatform(platform string) (Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Platform{}, fmt.Errorf("invalid platform %q (expected os/architecture[/variant])", platform)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return Platform{}, fmt.Errorf("invalid platform %q (expected os/architecture[/variant])", platform)
+		}
+	}
+
+	parsed := Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		parsed.Variant = parts[2]
+	}
+	return parsed, nil
+}
+
+// ResolveImageCheck returns the image check of a target item, the item's overriding the
+// target's
+func ResolveImageCheck(target *Target, item *TargetItem) *ImageCheck {
+	if item.ImageCheck != nil {
+		return item.ImageCheck
+	}
+	return target.ImageCheck
+}
@@ -0,0 +1,119 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		platform  string
+		expected  Platform
+		expectErr bool
+	}{
+		{platform: "linux/amd64", expected: Platform{OS: "linux", Architecture: "amd64"}},
+		{platform: "linux/arm/v7", expected: Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+		{platform: "linux", expectErr: true},
+		{platform: "linux/", expectErr: true},
+		{platform: "linux/arm/v7/extra", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			platform, err := ParsePlatform(tt.platform)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.platform)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePlatform() error = %v", err)
+			}
+			if platform != tt.expected || platform.String() != tt.platform {
+				t.Errorf("ParsePlatform() = %+v (%s), expected %+v", platform, platform, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_ImageCheck(t *testing.T) {
+	newConfig := func(sourceType PackageSourceType, targetCheck *ImageCheck, itemCheck *ImageCheck) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "dockerhub", Type: PackageSourceProviderTypeDocker},
+				{Name: "github", Type: PackageSourceProviderTypeGitHub},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "dockerhub", Type: sourceType, URI: "example/app"},
+			},
+			Targets: []*Target{
+				{
+					Name:       "app",
+					Type:       TargetTypeYamlField,
+					File:       "values.yaml",
+					ImageCheck: targetCheck,
+					Items: []TargetItem{
+						{YamlPath: "image.tag", Source: "app", ImageCheck: itemCheck},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "source image",
+			config:      newConfig(PackageSourceTypeDockerImage, &ImageCheck{Platforms: []string{"linux/arm64"}}, nil),
+			expectValid: true,
+		},
+		{
+			name:        "deployment registry with credentials",
+			config:      newConfig(PackageSourceTypeDockerImage, nil, &ImageCheck{Image: "registry.example.com/app", Provider: "dockerhub"}),
+			expectValid: true,
+		},
+		{
+			name:          "unknown provider",
+			config:        newConfig(PackageSourceTypeDockerImage, &ImageCheck{Provider: "mirror"}, nil),
+			errorContains: "provider 'mirror' not found",
+		},
+		{
+			name:          "provider of other type",
+			config:        newConfig(PackageSourceTypeDockerImage, nil, &ImageCheck{Provider: "github"}),
+			errorContains: "must be of type docker",
+		},
+		{
+			name:          "invalid platform",
+			config:        newConfig(PackageSourceTypeDockerImage, &ImageCheck{Platforms: []string{"arm64"}}, nil),
+			errorContains: "invalid platform",
+		},
+		{
+			name:          "image required for other sources",
+			config:        newConfig(PackageSourceTypeGitRelease, &ImageCheck{}, nil),
+			errorContains: "image is required for source 'app'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+			if result.Valid != tt.expectValid {
+				t.Fatalf("Expected valid=%v, got valid=%v, errors: %v", tt.expectValid, result.Valid, result.Errors)
+			}
+			if tt.errorContains == "" {
+				return
+			}
+			for _, err := range result.Errors {
+				if strings.Contains(err.Message, tt.errorContains) {
+					return
+				}
+			}
+			t.Errorf("Expected error containing %q, got: %v", tt.errorContains, result.Errors)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// discoveredImage is an image reference found in a manifest
+type discoveredImage struct {
+	Image    string // Image name without tag as written, e.g. "ghcr.io/acme/api"
+	YamlPath string // Path of the field holding the tag, either "image" or "image.tag"
+	Document int    // Index of the YAML document in its file
+}
+
+// ExpandImageDiscoveryTargets replaces every yaml-field target with discover set by one target
+// per manifest under its file, with an item per container image found there. Images are
+// followed by the configured docker-image source of the same image or, with a discover
+// provider, by a docker-image source created for them. Targets discovering no images are
+// dropped with a warning.
+func ExpandImageDiscoveryTargets(config *Config) error {
+	sourceNames := make(map[string]bool, len(config.PackageSources))
+	sourceByImage := make(map[string]string)
+	for _, source := range config.PackageSources {
+		sourceNames[source.Name] = true
+		if source.Type == PackageSourceTypeDockerImage {
+			if _, ok := sourceByImage[normalizeImageName(source.URI)]; !ok {
+				sourceByImage[normalizeImageName(source.URI)] = source.Name
+			}
+		}
+	}
+
+	expandedTargets := make([]*Target, 0, len(config.Targets))
+	for _, target := range config.Targets {
+		if target.Discover == nil {
+			expandedTargets = append(expandedTargets, target)
+			continue
+		}
+		if target.Type != TargetTypeYamlField {
+			return fmt.Errorf("target %s: discover is only supported for yaml-field targets", target.Name)
+		}
+		if len(target.Items) > 0 {
+			return fmt.Errorf("target %s: discover targets cannot list items", target.Name)
+		}
+
+		excluded := make([]*regexp.Regexp, len(target.Discover.ExcludeImages))
+		for i, pattern := range target.Discover.ExcludeImages {
+			var err error
+			if excluded[i], err = CompilePattern(pattern); err != nil {
+				return fmt.Errorf("target %s: invalid excludeImages pattern: %w", target.Name, err)
+			}
+		}
+
+		files, err := manifestFiles(target.File)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		if len(target.ExcludeFiles) > 0 {
+			files = excludeMatches(files, target.ExcludeFiles)
+		}
+
+		for _, file := range files {
+			images, err := readManifestImages(file)
+			if err != nil {
+				return fmt.Errorf("target %s: failed to read %s: %w", target.Name, file, err)
+			}
+
+			expandedTarget := *target
+			expandedTarget.File = file
+			expandedTarget.Discover = nil
+			expandedTarget.WildcardPattern = target.File
+			expandedTarget.IsWildcardMatch = true
+			expandedTarget.Items = nil
+			for _, image := range images {
+				if matchesAny(excluded, image.Image) {
+					continue
+				}
+
+				key := normalizeImageName(image.Image)
+				source, ok := sourceByImage[key]
+				if !ok {
+					if target.Discover.Provider == "" {
+						log.Debug().
+							Str("file", file).
+							Str("image", image.Image).
+							Msg("No package source for discovered image, skipping image")
+						continue
+					}
+					source = uniqueSourceName(sourceNames, discoveredSourceName(image.Image))
+					sourceByImage[key] = source
+					config.PackageSources = append(config.PackageSources, &PackageSource{
+						Name:     source,
+						Provider: target.Discover.Provider,
+						Type:     PackageSourceTypeDockerImage,
+						URI:      image.Image,
+					})
+				}
+
+				document := image.Document
+				expandedTarget.Items = append(expandedTarget.Items, TargetItem{
+					Name:     image.Image,
+					YamlPath: image.YamlPath,
+					Document: &document,
+					Source:   source,
+				})
+			}
+
+			if len(expandedTarget.Items) > 0 {
+				expandedTargets = append(expandedTargets, &expandedTarget)
+			}
+		}
+
+		log.Debug().
+			Str("target", target.Name).
+			Int("files", len(files)).
+			Msg("Discovered manifest images")
+	}
+
+	for _, target := range config.Targets {
+		if target.Discover == nil {
+			continue
+		}
+		found := false
+		for _, expanded := range expandedTargets {
+			if expanded.Name == target.Name && expanded.WildcardPattern == target.File {
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Warn().Str("target", target.Name).Str("file", target.File).Msg("Discovered no images to update")
+		}
+	}
+
+	config.Targets = expandedTargets
+	return nil
+}
+
+// manifestFiles returns the YAML files of a directory tree, or the files matching a wildcard
+// pattern. Hidden directories are not searched.
+func manifestFiles(path string) ([]string, error) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return expandFilePatterns([]string{path})
+	}
+
+	var files []string
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readManifestImages returns the tagged images of all documents of a YAML file: image fields
+// holding a reference such as "nginx:1.25.0", as in the containers of Kubernetes workloads,
+// and image mappings with repository and tag, as in Helm values. Templated values, digests
+// and tags without a version such as "latest" are skipped.
+func readManifestImages(file string) ([]*discoveredImage, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []*discoveredImage
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for document := 0; ; document++ {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		collectManifestImages(&node, nil, document, &images)
+	}
+	return images, nil
+}
+
+// collectManifestImages walks a YAML node and collects the images below it
+func collectManifestImages(node *yaml.Node, path []string, document int, images *[]*discoveredImage) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectManifestImages(child, path, document, images)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			collectManifestImages(child, append(path, strconv.Itoa(i)), document, images)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if strings.Contains(key, ".") {
+				// Keys with dots cannot be addressed by a yamlPath
+				continue
+			}
+			childPath := append(append([]string{}, path...), key)
+			if key == "image" {
+				if image := manifestImage(value, childPath, document); image != nil {
+					*images = append(*images, image)
+					continue
+				}
+			}
+			collectManifestImages(value, childPath, document, images)
+		}
+	}
+}
+
+// manifestImage returns the image of an image field, or nil if it holds no updatable tag
+func manifestImage(node *yaml.Node, path []string, document int) *discoveredImage {
+	var name, tag, yamlPath string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		reference := node.Value
+		if strings.Contains(reference, "@") {
+			return nil
+		}
+		separator := strings.LastIndex(reference, ":")
+		if separator <= strings.LastIndex(reference, "/") {
+			return nil
+		}
+		name, tag, yamlPath = reference[:separator], reference[separator+1:], strings.Join(path, ".")
+	case yaml.MappingNode:
+		var repository, tagNode *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			switch node.Content[i].Value {
+			case "repository":
+				repository = node.Content[i+1]
+			case "tag":
+				tagNode = node.Content[i+1]
+			}
+		}
+		if repository == nil || tagNode == nil || repository.Kind != yaml.ScalarNode || tagNode.Kind != yaml.ScalarNode {
+			return nil
+		}
+		name, tag, yamlPath = repository.Value, tagNode.Value, strings.Join(path, ".")+".tag"
+	default:
+		return nil
+	}
+
+	if name == "" || strings.ContainsAny(name+tag, "{}$ ") || !strings.ContainsAny(tag, "0123456789") {
+		return nil
+	}
+	return &discoveredImage{Image: name, YamlPath: yamlPath, Document: document}
+}
+
+// normalizeImageName returns the canonical name of an image for matching references written
+// differently, e.g. "library/nginx" for "nginx" and "docker.io/library/nginx:1.25"
+func normalizeImageName(image string) string {
+	image = strings.TrimPrefix(image, "docker://")
+	image = strings.TrimPrefix(image, "https://")
+	image = strings.TrimPrefix(image, "http://")
+	if separator := strings.LastIndex(image, ":"); separator > strings.LastIndex(image, "/") {
+		image = image[:separator]
+	}
+	for _, registry := range []string{"docker.io/", "index.docker.io/", "registry-1.docker.io/"} {
+		image = strings.TrimPrefix(image, registry)
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	return strings.ToLower(image)
+}
+
+// discoveredSourceName names the source created for a discovered image after its last path
+// segment, e.g. "api" for "ghcr.io/acme/api"
+func discoveredSourceName(image string) string {
+	return image[strings.LastIndex(image, "/")+1:]
+}
+
+// matchesAny reports whether any of the patterns matches the value
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandImageDiscoveryTargets(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"manifests/api/deployment.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: ghcr.io/acme/api:1.2.0
+      containers:
+        - name: api
+          image: ghcr.io/acme/api:1.2.0
+        - name: proxy
+          image: docker.io/library/nginx:1.25.3
+        - name: pinned
+          image: ghcr.io/acme/sidecar@sha256:0123
+        - name: latest
+          image: ghcr.io/acme/debug:latest
+`,
+		"manifests/web/values.yml": `image:
+  repository: ghcr.io/acme/web
+  tag: "2.0.1"
+templated:
+  image: "{{ .Values.image }}:1.0.0"
+`,
+		"manifests/web/README.md":       "image: ghcr.io/acme/ignored:1.0.0\n",
+		"manifests/.git/config.yaml":    "image: ghcr.io/acme/ignored:1.0.0\n",
+		"manifests/empty/service.yaml":  "kind: Service\n",
+		"manifests/tools/excluded.yaml": "image: ghcr.io/acme/tools:0.1.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{
+		PackageSources: []*PackageSource{
+			{Name: "nginx", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "nginx"},
+		},
+		Targets: []*Target{
+			{
+				Name:   "cluster",
+				Type:   TargetTypeYamlField,
+				File:   filepath.Join(tmpDir, "manifests"),
+				Labels: []string{"images"},
+				Discover: &TargetDiscovery{
+					Provider:      "ghcr",
+					ExcludeImages: []string{"/tools$"},
+				},
+			},
+		},
+	}
+
+	if err := ExpandImageDiscoveryTargets(config); err != nil {
+		t.Fatalf("ExpandImageDiscoveryTargets() error = %v", err)
+	}
+
+	if len(config.Targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d: %+v", len(config.Targets), config.Targets)
+	}
+
+	deployment := config.Targets[0]
+	if deployment.File != filepath.Join(tmpDir, "manifests/api/deployment.yaml") || !deployment.IsWildcardMatch || deployment.Discover != nil {
+		t.Errorf("unexpected deployment target: %+v", deployment)
+	}
+	if len(deployment.Labels) != 1 || deployment.Labels[0] != "images" {
+		t.Errorf("expected labels to be copied, got %v", deployment.Labels)
+	}
+	expectedItems := []struct {
+		name, yamlPath, source string
+	}{
+		{"ghcr.io/acme/api", "spec.template.spec.initContainers.0.image", "api"},
+		{"ghcr.io/acme/api", "spec.template.spec.containers.0.image", "api"},
+		{"docker.io/library/nginx", "spec.template.spec.containers.1.image", "nginx"},
+	}
+	if len(deployment.Items) != len(expectedItems) {
+		t.Fatalf("expected %d items, got %+v", len(expectedItems), deployment.Items)
+	}
+	for i, expected := range expectedItems {
+		item := deployment.Items[i]
+		if item.Name != expected.name || item.YamlPath != expected.yamlPath || item.Source != expected.source {
+			t.Errorf("item %d = %+v, want %+v", i, item, expected)
+		}
+		if item.Document == nil || *item.Document != 1 {
+			t.Errorf("item %d: expected document 1, got %v", i, item.Document)
+		}
+	}
+
+	values := config.Targets[1]
+	if len(values.Items) != 1 || values.Items[0].YamlPath != "image.tag" || values.Items[0].Source != "web" {
+		t.Errorf("unexpected values items: %+v", values.Items)
+	}
+
+	// One source is created per image without a configured source
+	if len(config.PackageSources) != 3 {
+		t.Fatalf("expected 3 sources, got %d", len(config.PackageSources))
+	}
+	for _, source := range config.PackageSources[1:] {
+		if source.Provider != "ghcr" || source.Type != PackageSourceTypeDockerImage {
+			t.Errorf("unexpected created source: %+v", source)
+		}
+	}
+	if config.PackageSources[1].URI != "ghcr.io/acme/api" || config.PackageSources[2].URI != "ghcr.io/acme/web" {
+		t.Errorf("unexpected created sources: %+v, %+v", config.PackageSources[1], config.PackageSources[2])
+	}
+}
+
+func TestExpandImageDiscoveryTargets_WithoutProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "deployment.yaml")
+	if err := os.WriteFile(file, []byte("containers:\n  - image: nginx:1.25.3\n  - image: ghcr.io/acme/api:1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		PackageSources: []*PackageSource{
+			{Name: "nginx", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "docker.io/library/nginx"},
+		},
+		Targets: []*Target{
+			{Name: "cluster", Type: TargetTypeYamlField, File: filepath.Join(tmpDir, "*.yaml"), Discover: &TargetDiscovery{}},
+		},
+	}
+
+	if err := ExpandImageDiscoveryTargets(config); err != nil {
+		t.Fatalf("ExpandImageDiscoveryTargets() error = %v", err)
+	}
+	if len(config.PackageSources) != 1 {
+		t.Errorf("expected no sources to be created, got %d", len(config.PackageSources))
+	}
+	if len(config.Targets) != 1 || len(config.Targets[0].Items) != 1 || config.Targets[0].Items[0].Source != "nginx" {
+		t.Errorf("expected only the image with a configured source, got %+v", config.Targets)
+	}
+}
+
+func TestExpandImageDiscoveryTargets_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target *Target
+	}{
+		{name: "unsupported type", target: &Target{Name: "t", Type: TargetTypeTerraformVariable, File: ".", Discover: &TargetDiscovery{}}},
+		{name: "listed items", target: &Target{Name: "t", Type: TargetTypeYamlField, File: ".", Discover: &TargetDiscovery{}, Items: []TargetItem{{YamlPath: "image"}}}},
+		{name: "invalid exclude pattern", target: &Target{Name: "t", Type: TargetTypeYamlField, File: ".", Discover: &TargetDiscovery{ExcludeImages: []string{"("}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ExpandImageDiscoveryTargets(&Config{Targets: []*Target{tt.target}}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package configuration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// isRemoteInclude reports whether an include refers to a URL instead of a file
+func isRemoteInclude(include string) bool {
+	return strings.HasPrefix(include, "https://") || strings.HasPrefix(include, "http://")
+}
+
+// resolveIncludeLocations makes the relative includes of a configuration loaded from base
+// absolute, so they keep pointing at the right location after configurations are merged
+func resolveIncludeLocations(config *Config, base string) error {
+	for i, include := range config.Include {
+		if isRemoteInclude(include) || filepath.IsAbs(include) {
+			continue
+		}
+
+		if isRemoteInclude(base) {
+			baseURL, err := url.Parse(base)
+			if err != nil {
+				return fmt.Errorf("invalid include URL %s: %w", base, err)
+			}
+			reference, err := url.Parse(include)
+			if err != nil {
+				return fmt.Errorf("invalid include %s: %w", include, err)
+			}
+			config.Include[i] = baseURL.ResolveReference(reference).String()
+			continue
+		}
+
+		config.Include[i] = filepath.Join(filepath.Dir(base), include)
+	}
+	return nil
+}
+
+// resolveIncludes loads the includes of a configuration, recursively, and layers the
+// configuration on top of them. Includes are applied in order, and later includes as well
+// as the configuration itself override earlier definitions with the same name.
+func resolveIncludes(config *Config, chain []string) (*Config, error) {
+	if len(config.Include) == 0 {
+		return config, nil
+	}
+
+	base := &Config{}
+	for _, include := range config.Include {
+		for _, visited := range chain {
+			if visited == include {
+				return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(chain, " -> "), include)
+			}
+		}
+
+		log.Debug().Str("include", include).Msg("Loading included configuration")
+
+		included, err := loadInclude(include)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", include, err)
+		}
+		included, err = resolveIncludes(included, append(chain, include))
+		if err != nil {
+			return nil, err
+		}
+		base = overlayConfiguration(base, included)
+	}
+
+	config = overlayConfiguration(base, config)
+	config.Include = nil
+	return config, nil
+}
+
+// loadInclude reads and parses an included file or URL
+func loadInclude(include string) (*Config, error) {
+	var data []byte
+	if isRemoteInclude(include) {
+		response, err := util.NewHTTPClient(30 * time.Second).Get(include)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", response.StatusCode)
+		}
+		if data, err = io.ReadAll(response.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(include); err != nil {
+			return nil, err
+		}
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration YAML: %w", err)
+	}
+	if err := resolveIncludeLocations(&config, include); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// overlayConfiguration returns base with the definitions of override applied: entries with
+// the name of a base entry replace it in place, new entries are appended
+func overlayConfiguration(base *Config, override *Config) *Config {
+	result := &Config{
+		Include:                override.Include,
+		PackageSourceProviders: overlayByName(base.PackageSourceProviders, override.PackageSourceProviders, func(p *PackageSourceProvider) string { return p.Name }),
+		PackageSources:         overlayByName(base.PackageSources, override.PackageSources, func(s *PackageSource) string { return s.Name }),
+		Targets:                overlayByName(base.Targets, override.Targets, func(t *Target) string { return t.Name }),
+		TargetTemplates:        append(append([]*TargetTemplate{}, base.TargetTemplates...), override.TargetTemplates...),
+		TargetActor:            base.TargetActor,
+		ArgoCDImageUpdater:     append(append([]*ArgoCDImageUpdater{}, base.ArgoCDImageUpdater...), override.ArgoCDImageUpdater...),
+	}
+	if override.TargetActor != nil {
+		result.TargetActor = override.TargetActor
+	}
+	result.Rollout = base.Rollout
+	if override.Rollout != nil {
+		result.Rollout = override.Rollout
+	}
+	for _, environments := range []map[string]*Environment{base.Environments, override.Environments} {
+		for name, environment := range environments {
+			if result.Environments == nil {
+				result.Environments = make(map[string]*Environment)
+			}
+			result.Environments[name] = mergeEnvironments(result.Environments[name], environment)
+		}
+	}
+	for _, registryMap := range []map[string]string{base.RegistryMap, override.RegistryMap} {
+		for prefix, mirror := range registryMap {
+			if result.RegistryMap == nil {
+				result.RegistryMap = make(map[string]string)
+			}
+			result.RegistryMap[prefix] = mirror
+		}
+	}
+	for _, patchGroups := range []map[string]*PatchGroup{base.PatchGroups, override.PatchGroups} {
+		for name, patchGroup := range patchGroups {
+			if result.PatchGroups == nil {
+				result.PatchGroups = make(map[string]*PatchGroup)
+			}
+			result.PatchGroups[name] = patchGroup
+		}
+	}
+	return result
+}
+
+func overlayByName[T any](base []T, override []T, name func(T) string) []T {
+	result := append([]T{}, base...)
+	index := make(map[string]int, len(result))
+	for i, entry := range result {
+		index[name(entry)] = i
+	}
+	for _, entry := range override {
+		if i, ok := index[name(entry)]; ok {
+			result[i] = entry
+			continue
+		}
+		index[name(entry)] = len(result)
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package configuration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRawConfiguration_Include(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog/sources.yml":
+			w.Write([]byte(`include:
+  - providers.yml
+packageSources:
+  - name: nginx
+    provider: dockerhub
+    type: docker-image
+    uri: nginx
+    tagPattern: "^1\\."
+  - name: redis
+    provider: dockerhub
+    type: docker-image
+    uri: redis
+`))
+		case "/catalog/providers.yml":
+			w.Write([]byte(`packageSourceProviders:
+  - name: dockerhub
+    type: docker
+`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared", "actor.yml")
+	if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(shared, []byte(`targetActor:
+  name: bot
+  email: bot@example.com
+  username: bot
+`), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	configFile := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(configFile, []byte(`include:
+  - `+server.URL+`/catalog/sources.yml
+  - shared/actor.yml
+packageSources:
+  - name: nginx
+    provider: dockerhub
+    type: docker-image
+    uri: nginx
+    tagPattern: "^2\\."
+`), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	config, err := LoadRawConfiguration(configFile)
+	if err != nil {
+		t.Fatalf("LoadRawConfiguration() error = %v", err)
+	}
+
+	if len(config.PackageSourceProviders) != 1 || config.PackageSourceProviders[0].Name != "dockerhub" {
+		t.Errorf("expected provider from nested remote include, got %v", config.PackageSourceProviders)
+	}
+	if len(config.PackageSources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(config.PackageSources))
+	}
+	if config.PackageSources[0].Name != "nginx" || config.PackageSources[0].TagPattern != `^2\.` {
+		t.Errorf("expected local nginx definition to override the included one, got %+v", config.PackageSources[0])
+	}
+	if config.PackageSources[1].Name != "redis" {
+		t.Errorf("expected included redis source, got %s", config.PackageSources[1].Name)
+	}
+	if config.TargetActor == nil || config.TargetActor.Name != "bot" {
+		t.Errorf("expected target actor from relative include, got %v", config.TargetActor)
+	}
+	if len(config.Include) != 0 {
+		t.Errorf("expected includes to be resolved, got %v", config.Include)
+	}
+}
+
+func TestLoadRawConfiguration_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yml": "include: [a.yml]\n",
+		"a.yml":      "include: [b.yml]\n",
+		"b.yml":      "include: [a.yml]\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	_, err := LoadRawConfiguration(filepath.Join(dir, "config.yml"))
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+}
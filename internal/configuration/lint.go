@@ -0,0 +1,306 @@
+package configuration
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityNote    LintSeverity = "note"
+)
+
+// LintRule is an opinionated check of a configuration that is structurally valid
+type LintRule struct {
+	ID          string       `json:"id" yaml:"id"`
+	Severity    LintSeverity `json:"severity" yaml:"severity"`
+	Description string       `json:"description" yaml:"description"`
+}
+
+var (
+	LintRuleUnusedSource = &LintRule{
+		ID:          "unused-source",
+		Severity:    LintSeverityWarning,
+		Description: "Package source is not referenced by any target",
+	}
+	LintRuleUnrelatedPatchGroup = &LintRule{
+		ID:          "unrelated-patch-group",
+		Severity:    LintSeverityWarning,
+		Description: "Patch group bundles targets that share neither a directory nor a source",
+	}
+	LintRuleWildcardNoMatch = &LintRule{
+		ID:          "wildcard-no-match",
+		Severity:    LintSeverityWarning,
+		Description: "Wildcard target file pattern matches no files",
+	}
+	LintRuleDockerHubTagPattern = &LintRule{
+		ID:          "dockerhub-tag-pattern",
+		Severity:    LintSeverityNote,
+		Description: "Docker Hub source without tagPattern considers every tag, including latest and variants",
+	}
+	LintRuleUnhealthySource = &LintRule{
+		ID:          "unhealthy-source",
+		Severity:    LintSeverityWarning,
+		Description: "Package source produced no new version for its staleAfter period or keeps failing to scrape",
+	}
+	LintRulePlainTextCredential = &LintRule{
+		ID:          "plaintext-credential",
+		Severity:    LintSeverityError,
+		Description: "Credential is written in plain text instead of an environment or SOPS reference",
+	}
+)
+
+// LintRules lists all lint rules in the order they are checked
+var LintRules = []*LintRule{
+	LintRuleUnusedSource,
+	LintRuleUnrelatedPatchGroup,
+	LintRuleWildcardNoMatch,
+	LintRuleDockerHubTagPattern,
+	LintRuleUnhealthySource,
+	LintRulePlainTextCredential,
+}
+
+// LintFinding is a single violation of a lint rule
+type LintFinding struct {
+	RuleID   string       `json:"rule" yaml:"rule"`
+	Severity LintSeverity `json:"severity" yaml:"severity"`
+	Field    string       `json:"field" yaml:"field"`
+	Message  string       `json:"message" yaml:"message"`
+}
+
+func (f *LintFinding) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Field, f.Message, f.RuleID)
+}
+
+// LintResult holds the findings of all lint rules
+type LintResult struct {
+	Findings []*LintFinding
+}
+
+func (r *LintResult) add(rule *LintRule, field, message string) {
+	r.Findings = append(r.Findings, &LintFinding{
+		RuleID:   rule.ID,
+		Severity: rule.Severity,
+		Field:    field,
+		Message:  message,
+	})
+}
+
+// HasErrors reports whether any finding has error severity
+func (r *LintResult) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == LintSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintConfiguration runs all lint rules against a configuration as written, see
+// LoadRawConfiguration. Plain-text credentials can only be detected before substitution.
+func LintConfiguration(config *Config) *LintResult {
+	result := &LintResult{Findings: make([]*LintFinding, 0)}
+	lintUnusedSources(result, config)
+	lintUnrelatedPatchGroups(result, config)
+	lintWildcardTargets(result, config)
+	lintDockerHubTagPatterns(result, config)
+	lintPlainTextCredentials(result, config)
+	return result
+}
+
+// LintSourceHealth adds a finding for every source the health state recorded by previous
+// compare runs reports as stale or failing
+func LintSourceHealth(result *LintResult, config *Config, state *HealthState, defaultStaleAfter time.Duration, now time.Time) {
+	indices := make(map[string]int, len(config.PackageSources))
+	for i, source := range config.PackageSources {
+		indices[source.Name] = i
+	}
+	for _, issue := range state.Issues(config, defaultStaleAfter, now) {
+		result.add(LintRuleUnhealthySource, fmt.Sprintf("packageSources[%d]", indices[issue.SourceName]),
+			fmt.Sprintf("source '%s' %s", issue.SourceName, issue.Message))
+	}
+}
+
+func lintUnusedSources(result *LintResult, config *Config) {
+	for _, target := range config.Targets {
+		if target.SourcePattern != "" {
+			// Sources derived from matched paths are only known after wildcard expansion
+			return
+		}
+	}
+
+	referenced := ReferencedSources(config)
+	for name := range ReferencedSources(config) {
+		// Packages of a discovery source are referenced as <source>/<package>
+		if discovery := DiscoverySource(config.PackageSources, name); discovery != nil {
+			referenced[discovery.Name] = true
+		}
+	}
+	for i, source := range config.PackageSources {
+		if !referenced[source.Name] {
+			result.add(LintRuleUnusedSource, fmt.Sprintf("packageSources[%d]", i),
+				fmt.Sprintf("source '%s' is scraped by load but never compared or applied", source.Name))
+		}
+	}
+}
+
+// lintUnrelatedPatchGroups reports explicit patch groups whose targets fall into more than
+// one cluster, where targets are related if they share a directory or a source
+func lintUnrelatedPatchGroups(result *LintResult, config *Config) {
+	groupTargets := make(map[string][]int)
+	for i, target := range config.Targets {
+		seen := make(map[string]bool)
+		for _, item := range target.Items {
+			group := item.PatchGroup
+			if group == "" {
+				group = target.PatchGroup
+			}
+			if group == "" || seen[group] {
+				continue
+			}
+			seen[group] = true
+			groupTargets[group] = append(groupTargets[group], i)
+		}
+	}
+
+	groups := make([]string, 0, len(groupTargets))
+	for group := range groupTargets {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		indices := groupTargets[group]
+		clusters := clusterRelatedTargets(config.Targets, indices)
+		if len(clusters) < 2 {
+			continue
+		}
+
+		names := make([]string, len(clusters))
+		for i, cluster := range clusters {
+			names[i] = config.Targets[cluster[0]].Name
+		}
+		result.add(LintRuleUnrelatedPatchGroup, fmt.Sprintf("targets[%d].patchGroup", indices[0]),
+			fmt.Sprintf("patch group '%s' combines %d unrelated sets of targets (%s) into one pull request", group, len(clusters), strings.Join(names, ", ")))
+	}
+}
+
+// clusterRelatedTargets groups target indices into sets of targets that are transitively
+// related by directory or source
+func clusterRelatedTargets(targets []*Target, indices []int) [][]int {
+	visited := make(map[int]bool)
+	clusters := make([][]int, 0)
+	for _, start := range indices {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		cluster := []int{start}
+		for next := 0; next < len(cluster); next++ {
+			for _, j := range indices {
+				if !visited[j] && targetsRelated(targets[cluster[next]], targets[j]) {
+					visited[j] = true
+					cluster = append(cluster, j)
+				}
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+func targetsRelated(a, b *Target) bool {
+	if filepath.Dir(a.File) == filepath.Dir(b.File) {
+		return true
+	}
+	for _, itemA := range a.Items {
+		for _, itemB := range b.Items {
+			if itemA.Source != "" && itemA.Source == itemB.Source {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lintWildcardTargets(result *LintResult, config *Config) {
+	for i, target := range config.Targets {
+		if !strings.ContainsAny(target.File, "*?[") {
+			continue
+		}
+
+		var matches []string
+		var err error
+		if strings.Contains(target.File, "**") {
+			matches, err = recursiveGlob(target.File, target.FollowSymlinks)
+		} else {
+			matches, err = filepath.Glob(target.File)
+		}
+		if len(target.ExcludeFiles) > 0 {
+			matches = excludeMatches(matches, target.ExcludeFiles)
+		}
+		if err == nil && len(matches) == 0 {
+			result.add(LintRuleWildcardNoMatch, fmt.Sprintf("targets[%d].file", i),
+				fmt.Sprintf("pattern '%s' of target '%s' matches no files", target.File, target.Name))
+		}
+	}
+}
+
+func lintDockerHubTagPatterns(result *LintResult, config *Config) {
+	providers := make(map[string]*PackageSourceProvider)
+	for _, provider := range config.PackageSourceProviders {
+		providers[provider.Name] = provider
+	}
+
+	for i, source := range config.PackageSources {
+		if source.Type != PackageSourceTypeDockerImage || source.TagPattern != "" || source.ExtractPattern != "" {
+			continue
+		}
+		provider := providers[source.Provider]
+		if provider == nil || provider.Type != PackageSourceProviderTypeDocker || !isDockerHubURL(provider.BaseUrl) {
+			continue
+		}
+		result.add(LintRuleDockerHubTagPattern, fmt.Sprintf("packageSources[%d].tagPattern", i),
+			fmt.Sprintf("source '%s' has no tagPattern, tags like 'latest' or '-alpine' variants may be proposed", source.Name))
+	}
+}
+
+// isDockerHubURL reports whether a docker provider base URL points at Docker Hub
+func isDockerHubURL(baseURL string) bool {
+	host := strings.TrimPrefix(baseURL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	host = strings.TrimSuffix(host, "/")
+	switch host {
+	case "", "registry.hub.docker.com", "hub.docker.com", "registry-1.docker.io", "index.docker.io", "docker.io":
+		return true
+	}
+	return false
+}
+
+func lintPlainTextCredentials(result *LintResult, config *Config) {
+	for i, provider := range config.PackageSourceProviders {
+		fieldPrefix := fmt.Sprintf("packageSourceProviders[%d]", i)
+		if isPlainTextCredential(provider.Password) {
+			result.add(LintRulePlainTextCredential, fieldPrefix+".password",
+				fmt.Sprintf("password of provider '%s' should be an ${ENV} or ${SOPS[...]} reference", provider.Name))
+		}
+		if isPlainTextCredential(provider.Token) {
+			result.add(LintRulePlainTextCredential, fieldPrefix+".token",
+				fmt.Sprintf("token of provider '%s' should be an ${ENV} or ${SOPS[...]} reference", provider.Name))
+		}
+	}
+	if config.TargetActor != nil && isPlainTextCredential(config.TargetActor.Token) {
+		result.add(LintRulePlainTextCredential, "targetActor.token",
+			"targetActor token should be an ${ENV} or ${SOPS[...]} reference")
+	}
+}
+
+func isPlainTextCredential(value string) bool {
+	return strings.TrimSpace(value) != "" && !strings.Contains(value, "${")
+}
@@ -0,0 +1,120 @@
+package configuration
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLintConfiguration(t *testing.T) {
+	dockerHub := &PackageSourceProvider{Name: "dockerhub", Type: PackageSourceProviderTypeDocker}
+	harbor := &PackageSourceProvider{Name: "harbor", Type: PackageSourceProviderTypeHarbor, BaseUrl: "https://harbor.example.com"}
+
+	tests := []struct {
+		name     string
+		config   *Config
+		expected []string
+	}{
+		{
+			name: "clean configuration",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{dockerHub},
+				PackageSources: []*PackageSource{
+					{Name: "nginx", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "nginx", TagPattern: `^\d+\.\d+\.\d+$`},
+				},
+				Targets: []*Target{
+					{Name: "web", File: "apps/web/values.yaml", Items: []TargetItem{{YamlPath: "image.tag", Source: "nginx"}}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "unused source",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{harbor},
+				PackageSources: []*PackageSource{
+					{Name: "app", Provider: "harbor", Type: PackageSourceTypeDockerImage, URI: "team/app"},
+				},
+			},
+			expected: []string{"unused-source"},
+		},
+		{
+			name: "unrelated patch group",
+			config: &Config{
+				Targets: []*Target{
+					{Name: "web", File: "apps/web/values.yaml", PatchGroup: "weekly", Items: []TargetItem{{Source: "nginx"}}},
+					{Name: "api", File: "apps/api/values.yaml", PatchGroup: "weekly", Items: []TargetItem{{Source: "postgres"}}},
+				},
+			},
+			expected: []string{"unrelated-patch-group"},
+		},
+		{
+			name: "patch group related by source",
+			config: &Config{
+				Targets: []*Target{
+					{Name: "web-dev", File: "dev/web/values.yaml", PatchGroup: "web", Items: []TargetItem{{Source: "nginx"}}},
+					{Name: "web-prod", File: "prod/web/values.yaml", PatchGroup: "web", Items: []TargetItem{{Source: "nginx"}}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "wildcard without matches",
+			config: &Config{
+				Targets: []*Target{
+					{Name: "all", File: filepath.Join(t.TempDir(), "*", "values.yaml")},
+				},
+			},
+			expected: []string{"wildcard-no-match"},
+		},
+		{
+			name: "docker hub source without tag pattern",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{dockerHub},
+				PackageSources: []*PackageSource{
+					{Name: "nginx", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "nginx"},
+				},
+				Targets: []*Target{
+					{Name: "web", File: "values.yaml", Items: []TargetItem{{Source: "nginx"}}},
+				},
+			},
+			expected: []string{"dockerhub-tag-pattern"},
+		},
+		{
+			name: "plain-text credentials",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub, AuthType: PackageSourceProviderAuthTypeToken, Token: "ghp_plaintext"},
+					{Name: "harbor", Type: PackageSourceProviderTypeHarbor, AuthType: PackageSourceProviderAuthTypeBasic, Username: "robot", Password: "${HARBOR_PASSWORD}"},
+				},
+				TargetActor: &TargetActor{Name: "bot", Token: "${SOPS[secrets.yml].github.token}"},
+			},
+			expected: []string{"plaintext-credential"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := LintConfiguration(tt.config)
+			if len(result.Findings) != len(tt.expected) {
+				t.Fatalf("expected %d findings, got %d: %v", len(tt.expected), len(result.Findings), result.Findings)
+			}
+			for i, rule := range tt.expected {
+				if result.Findings[i].RuleID != rule {
+					t.Errorf("finding %d: expected rule %s, got %s", i, rule, result.Findings[i].RuleID)
+				}
+			}
+		})
+	}
+}
+
+func TestLintResultHasErrors(t *testing.T) {
+	result := &LintResult{}
+	result.add(LintRuleUnusedSource, "packageSources[0]", "unused")
+	if result.HasErrors() {
+		t.Error("warning findings should not count as errors")
+	}
+	result.add(LintRulePlainTextCredential, "targetActor.token", "plain text")
+	if !result.HasErrors() {
+		t.Error("expected error finding to be reported")
+	}
+}
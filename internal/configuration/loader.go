@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
@@ -15,6 +18,87 @@ import (
 // If the path is a directory, it loads all .yml files within it and merges them
 // It also performs environment variable and SOPS substitution
 func LoadConfiguration(configPath string) (*Config, error) {
+	return LoadEnvironmentConfiguration(configPath, "")
+}
+
+// LoadEnvironmentConfiguration loads the configuration like LoadConfiguration with the
+// overrides of the named environment applied
+func LoadEnvironmentConfiguration(configPath string, environment string) (*Config, error) {
+	config, err := LoadRawConfiguration(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ApplyEnvironment(config, environment); err != nil {
+		return nil, err
+	}
+
+	return prepareConfiguration(config)
+}
+
+// RootConfiguration prepares a configuration as written, e.g. loaded with LoadRawConfiguration
+// or given inline, like LoadEnvironmentConfiguration: includes are resolved, target templates
+// expanded and the overrides of the named environment applied. Relative includes and file
+// paths are resolved against dir, so the configuration can be used from any working directory.
+func RootConfiguration(config *Config, dir string, environment string) (*Config, error) {
+	if err := resolveIncludeLocations(config, dir+string(filepath.Separator)); err != nil {
+		return nil, err
+	}
+	config, err := resolveIncludes(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ExpandTargetTemplates(config); err != nil {
+		return nil, fmt.Errorf("failed to expand target templates: %w", err)
+	}
+
+	if err := ApplyEnvironment(config, environment); err != nil {
+		return nil, err
+	}
+	scopeConfiguration(config, ".", dir)
+
+	return prepareConfiguration(config)
+}
+
+// prepareConfiguration applies the --set overrides and performs variable substitution,
+// annotation ingestion and wildcard expansion on a configuration as written
+func prepareConfiguration(config *Config) (*Config, error) {
+	// Apply the --set overrides of the run
+	if err := ApplyOverrides(config); err != nil {
+		return nil, err
+	}
+
+	// Perform variable substitution
+	ctx := NewSubstitutionContext()
+	if err := ctx.SubstituteInConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to substitute variables: %w", err)
+	}
+
+	// Mask credentials in all log and command output
+	registerSecrets(config)
+
+	// Synthesize sources and targets from argocd-image-updater annotations
+	if err := ExpandArgoCDImageUpdater(config); err != nil {
+		return nil, fmt.Errorf("failed to ingest argocd-image-updater annotations: %w", err)
+	}
+
+	// Find the items of discover targets in their manifests
+	if err := ExpandImageDiscoveryTargets(config); err != nil {
+		return nil, fmt.Errorf("failed to discover target images: %w", err)
+	}
+
+	// Expand wildcard patterns in target files
+	if err := ExpandWildcardTargets(config); err != nil {
+		return nil, fmt.Errorf("failed to expand wildcard targets: %w", err)
+	}
+
+	return config, nil
+}
+
+// LoadRawConfiguration reads and merges the configuration from the given path as written,
+// without variable substitution or wildcard expansion. Includes are resolved and target
+// templates expanded.
+func LoadRawConfiguration(configPath string) (*Config, error) {
 	// Check if path is a directory
 	fileInfo, err := os.Stat(configPath)
 	if err != nil {
@@ -25,26 +109,22 @@ func LoadConfiguration(configPath string) (*Config, error) {
 	if fileInfo.IsDir() {
 		// Load all .yml files from directory
 		config, err = loadConfigurationFromDirectory(configPath)
-		if err != nil {
-			return nil, err
-		}
 	} else {
 		// Load single configuration file
 		config, err = loadSingleConfigurationFile(configPath)
-		if err != nil {
-			return nil, err
-		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Perform variable substitution
-	ctx := NewSubstitutionContext()
-	if err := ctx.SubstituteInConfig(config); err != nil {
-		return nil, fmt.Errorf("failed to substitute variables: %w", err)
+	// Layer the configuration on top of its includes
+	if config, err = resolveIncludes(config, nil); err != nil {
+		return nil, err
 	}
 
-	// Expand wildcard patterns in target files
-	if err := ExpandWildcardTargets(config); err != nil {
-		return nil, fmt.Errorf("failed to expand wildcard targets: %w", err)
+	// Generate the targets of target templates
+	if err := ExpandTargetTemplates(config); err != nil {
+		return nil, fmt.Errorf("failed to expand target templates: %w", err)
 	}
 
 	return config, nil
@@ -64,6 +144,10 @@ func loadSingleConfigurationFile(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse configuration YAML: %w", err)
 	}
 
+	if err := resolveIncludeLocations(&config, configPath); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -133,11 +217,20 @@ func mergeConfigurations(configs []*Config) (*Config, error) {
 	}
 
 	// Track names for duplicate detection
+	includes := make(map[string]bool)
 	providerNames := make(map[string]bool)
 	sourceNames := make(map[string]bool)
 	targetNames := make(map[string]bool)
 
 	for _, config := range configs {
+		// Merge includes, each is applied once
+		for _, include := range config.Include {
+			if !includes[include] {
+				includes[include] = true
+				merged.Include = append(merged.Include, include)
+			}
+		}
+
 		// Merge package source providers
 		for _, provider := range config.PackageSourceProviders {
 			if providerNames[provider.Name] {
@@ -165,20 +258,76 @@ func mergeConfigurations(configs []*Config) (*Config, error) {
 			merged.Targets = append(merged.Targets, target)
 		}
 
-		// Use the last non-nil targetActor
+		merged.TargetTemplates = append(merged.TargetTemplates, config.TargetTemplates...)
+		merged.ArgoCDImageUpdater = append(merged.ArgoCDImageUpdater, config.ArgoCDImageUpdater...)
+
+		// Collect environment overrides of all files
+		for name, environment := range config.Environments {
+			if merged.Environments == nil {
+				merged.Environments = make(map[string]*Environment)
+			}
+			merged.Environments[name] = mergeEnvironments(merged.Environments[name], environment)
+		}
+
+		// Collect patch group settings, duplicates are ambiguous
+		for name, patchGroup := range config.PatchGroups {
+			if merged.PatchGroups == nil {
+				merged.PatchGroups = make(map[string]*PatchGroup)
+			}
+			if _, exists := merged.PatchGroups[name]; exists {
+				return nil, fmt.Errorf("duplicate patch group settings: %s", name)
+			}
+			merged.PatchGroups[name] = patchGroup
+		}
+
+		// Collect registry mappings, conflicting mirrors are ambiguous
+		for prefix, mirror := range config.RegistryMap {
+			if merged.RegistryMap == nil {
+				merged.RegistryMap = make(map[string]string)
+			}
+			if existing, exists := merged.RegistryMap[prefix]; exists && existing != mirror {
+				return nil, fmt.Errorf("conflicting registry mappings for %s: %s and %s", prefix, existing, mirror)
+			}
+			merged.RegistryMap[prefix] = mirror
+		}
+
+		// Use the last non-nil targetActor and rollout
 		if config.TargetActor != nil {
 			merged.TargetActor = config.TargetActor
 		}
+		if config.Rollout != nil {
+			merged.Rollout = config.Rollout
+		}
 	}
 
 	return merged, nil
 }
 
+// mergeEnvironments concatenates the overrides of two definitions of the same environment
+func mergeEnvironments(a *Environment, b *Environment) *Environment {
+	if a == nil {
+		return b
+	}
+	return &Environment{
+		PackageSources: append(append([]yaml.Node{}, a.PackageSources...), b.PackageSources...),
+		Targets:        append(append([]yaml.Node{}, a.Targets...), b.Targets...),
+	}
+}
+
 // ExpandWildcardTargets expands wildcard patterns in target file paths
 // Supports both single-level wildcards (*) and recursive wildcards (**)
 func ExpandWildcardTargets(config *Config) error {
 	expandedTargets := make([]*Target, 0, len(config.Targets))
 
+	sourceNames := make(map[string]bool, len(config.PackageSources))
+	discoveryNames := make(map[string]bool)
+	for _, source := range config.PackageSources {
+		sourceNames[source.Name] = true
+		if source.Discovers() {
+			discoveryNames[source.Name] = true
+		}
+	}
+
 	for _, target := range config.Targets {
 		// Check if file path contains wildcard characters
 		if strings.Contains(target.File, "*") || strings.Contains(target.File, "?") || strings.Contains(target.File, "[") {
@@ -187,7 +336,7 @@ func ExpandWildcardTargets(config *Config) error {
 
 			// Check if pattern contains ** for recursive matching
 			if strings.Contains(target.File, "**") {
-				matches, err = recursiveGlob(target.File)
+				matches, err = recursiveGlob(target.File, target.FollowSymlinks)
 			} else {
 				// Use standard filepath.Glob for single-level wildcards
 				matches, err = filepath.Glob(target.File)
@@ -203,6 +352,11 @@ func ExpandWildcardTargets(config *Config) error {
 				continue
 			}
 
+			// Drop matches of the exclusion patterns
+			if len(target.ExcludeFiles) > 0 {
+				matches = excludeMatches(matches, target.ExcludeFiles)
+			}
+
 			if len(matches) == 0 {
 				log.Warn().
 					Str("pattern", target.File).
@@ -216,22 +370,50 @@ func ExpandWildcardTargets(config *Config) error {
 				Int("matches", len(matches)).
 				Msg("Expanded wildcard pattern")
 
+			var sourcePattern *regexp.Regexp
+			if target.SourcePattern != "" {
+				if sourcePattern, err = regexp.Compile(target.SourcePattern); err != nil {
+					return fmt.Errorf("target %s: invalid sourcePattern: %w", target.Name, err)
+				}
+			}
+
 			// Create a new target for each matched file
 			for _, match := range matches {
-				expandedTarget := &Target{
-					Name:            target.Name,
-					Type:            target.Type,
-					File:            match,
-					Items:           target.Items,
-					PatchGroup:      target.PatchGroup,
-					Labels:          target.Labels,
-					WildcardPattern: target.File, // Store the original pattern
-					IsWildcardMatch: true,
+				// Copy the target so all target-level settings carry over
+				expandedTarget := *target
+				expandedTarget.File = match
+				if sourcePattern != nil {
+					source, ok := sourceFromPath(sourcePattern, match)
+					if !ok || (!sourceNames[source] && !referencesDiscoverySource(target, discoveryNames)) {
+						log.Debug().
+							Str("file", match).
+							Str("source", source).
+							Msg("No package source derived from wildcard match, skipping file")
+						continue
+					}
+					expandedTarget.Items = make([]TargetItem, len(target.Items))
+					for i, item := range target.Items {
+						if item.Source == "" {
+							item.Source = source
+						} else if discoveryNames[item.Source] {
+							// Items of a discovery source follow the package of the match
+							item.Source = item.Source + "/" + source
+						}
+						expandedTarget.Items[i] = item
+					}
 				}
-				expandedTargets = append(expandedTargets, expandedTarget)
+				if err := renderPatchGroups(&expandedTarget, newWildcardMatchData(target.File, match)); err != nil {
+					return fmt.Errorf("target %s: %w", target.Name, err)
+				}
+				expandedTarget.WildcardPattern = target.File // Store the original pattern
+				expandedTarget.IsWildcardMatch = true
+				expandedTargets = append(expandedTargets, &expandedTarget)
 			}
 		} else {
 			// No wildcard, keep as-is
+			if hasPatchGroupTemplate(target) {
+				return fmt.Errorf("target %s: patchGroup templates require a wildcard file pattern", target.Name)
+			}
 			expandedTargets = append(expandedTargets, target)
 		}
 	}
@@ -240,70 +422,194 @@ func ExpandWildcardTargets(config *Config) error {
 	return nil
 }
 
-// recursiveGlob performs recursive glob matching for patterns containing **
-// The ** pattern matches zero or more directories
-func recursiveGlob(pattern string) ([]string, error) {
-	// Split pattern into parts
-	parts := strings.Split(filepath.ToSlash(pattern), "/")
+// excludeMatches removes the paths matching any of the exclusion patterns. Patterns are
+// matched against the whole path and support * and ? within a path segment and ** across
+// segments, e.g. "**/test/**".
+func excludeMatches(matches []string, patterns []string) []string {
+	expressions := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		expressions[i] = pathGlobToRegex(pattern)
+	}
 
-	// Find the index of the first ** in the pattern
-	recursiveIndex := -1
-	for i, part := range parts {
-		if part == "**" {
-			recursiveIndex = i
-			break
+	kept := make([]string, 0, len(matches))
+	for _, match := range matches {
+		path := filepath.ToSlash(filepath.Clean(match))
+		excluded := false
+		for _, expression := range expressions {
+			if expression.MatchString(path) {
+				excluded = true
+				break
+			}
 		}
+		if excluded {
+			log.Debug().Str("file", match).Msg("Wildcard match excluded")
+			continue
+		}
+		kept = append(kept, match)
 	}
+	return kept
+}
 
-	if recursiveIndex == -1 {
-		// No ** found, use standard glob
-		return filepath.Glob(pattern)
+// pathGlobToRegex converts a path glob to an anchored regex
+func pathGlobToRegex(pattern string) *regexp.Regexp {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+
+	var expression strings.Builder
+	expression.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expression.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expression.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
 	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
 
-	// Get the base directory (everything before **)
-	var baseDir string
-	if recursiveIndex == 0 {
-		baseDir = "."
-	} else {
-		// Reconstruct the base directory path
-		// If the original pattern started with /, preserve it
-		if strings.HasPrefix(pattern, string(filepath.Separator)) {
-			baseDir = string(filepath.Separator) + filepath.Join(parts[:recursiveIndex]...)
-		} else {
-			baseDir = filepath.Join(parts[:recursiveIndex]...)
+// sourceFromPath derives a source name from a file path with the "source" (or first) capture
+// group of the pattern
+func sourceFromPath(pattern *regexp.Regexp, path string) (string, bool) {
+	match := pattern.FindStringSubmatch(filepath.ToSlash(path))
+	if match == nil || len(match) < 2 {
+		return "", false
+	}
+	group := 1
+	if idx := pattern.SubexpIndex("source"); idx > 0 {
+		group = idx
+	}
+	return match[group], match[group] != ""
+}
+
+// referencesDiscoverySource reports whether an item of the target references a discovery source
+func referencesDiscoverySource(target *Target, discoveryNames map[string]bool) bool {
+	for _, item := range target.Items {
+		if discoveryNames[item.Source] {
+			return true
 		}
 	}
+	return false
+}
 
-	// Get the pattern after ** (everything after **)
-	var afterPattern string
-	if recursiveIndex < len(parts)-1 {
-		afterPattern = filepath.Join(parts[recursiveIndex+1:]...)
+// WildcardMatchData holds the fields available to patchGroup templates of wildcard targets
+type WildcardMatchData struct {
+	Path string   // Matched file path
+	dirs []string // Directories of the path below the part of the pattern before its first wildcard
+}
+
+// newWildcardMatchData describes a file matched by a wildcard pattern
+func newWildcardMatchData(pattern string, match string) *WildcardMatchData {
+	staticParts := 0
+	for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		staticParts++
 	}
 
-	// Collect all matches
-	var matches []string
+	data := &WildcardMatchData{Path: match}
+	matchParts := strings.Split(filepath.ToSlash(match), "/")
+	if staticParts < len(matchParts)-1 {
+		data.dirs = matchParts[staticParts : len(matchParts)-1]
+	}
+	return data
+}
 
-	// Walk the directory tree starting from baseDir
-	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			// Skip directories we can't read
-			if d != nil && d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+// Dir returns the n-th directory (1-based) of the matched path below the part of the pattern
+// before its first wildcard, e.g. "prod" for Dir 1 of envs/prod/values.yaml matched by
+// envs/*/values.yaml. It returns an empty string if the path has fewer directories.
+func (d *WildcardMatchData) Dir(n int) string {
+	if n < 1 || n > len(d.dirs) {
+		return ""
+	}
+	return d.dirs[n-1]
+}
+
+// hasPatchGroupTemplate reports whether the patch group of the target or an item is a template
+func hasPatchGroupTemplate(target *Target) bool {
+	if strings.Contains(target.PatchGroup, "{{") {
+		return true
+	}
+	for _, item := range target.Items {
+		if strings.Contains(item.PatchGroup, "{{") {
+			return true
 		}
+	}
+	return false
+}
 
-		// Skip directories in matching
-		if d.IsDir() {
-			return nil
+// renderPatchGroups renders the patchGroup templates of an expanded wildcard target and its
+// items, e.g. "{{ .Dir 1 }}" to group the matched files per environment
+func renderPatchGroups(target *Target, data *WildcardMatchData) error {
+	if !hasPatchGroupTemplate(target) {
+		return nil
+	}
+
+	var err error
+	if target.PatchGroup, err = renderPatchGroup(target.PatchGroup, data); err != nil {
+		return err
+	}
+	items := make([]TargetItem, len(target.Items))
+	for i, item := range target.Items {
+		if item.PatchGroup, err = renderPatchGroup(item.PatchGroup, data); err != nil {
+			return err
 		}
+		items[i] = item
+	}
+	target.Items = items
+	return nil
+}
+
+// renderPatchGroup renders a patchGroup template, returning values without a template unchanged
+func renderPatchGroup(patchGroup string, data *WildcardMatchData) (string, error) {
+	if !strings.Contains(patchGroup, "{{") {
+		return patchGroup, nil
+	}
+
+	tmpl, err := template.New("patchGroup").Option("missingkey=error").Parse(patchGroup)
+	if err != nil {
+		return "", fmt.Errorf("invalid patchGroup template: %w", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render patchGroup template: %w", err)
+	}
+	if strings.TrimSpace(rendered.String()) == "" {
+		return "", fmt.Errorf("patchGroup template %q renders to an empty patch group for %s", patchGroup, data.Path)
+	}
+	return strings.TrimSpace(rendered.String()), nil
+}
+
+// recursiveGlob performs recursive glob matching for patterns containing **
+// The ** pattern matches zero or more directories. Symlinked directories are only
+// descended into with followSymlinks.
+func recursiveGlob(pattern string, followSymlinks bool) ([]string, error) {
+	baseDir, afterPattern, recursive := splitRecursivePattern(pattern)
+	if !recursive {
+		// No ** found, use standard glob
+		return filepath.Glob(pattern)
+	}
+
+	// Collect all matches
+	var matches []string
 
+	// Walk the directory tree starting from baseDir
+	err := walkFiles(baseDir, followSymlinks, func(path string) {
 		// If we have a pattern after **, match it
 		if afterPattern != "" {
 			// Get the relative path from baseDir
 			relPath, err := filepath.Rel(baseDir, path)
 			if err != nil {
-				return nil
+				return
 			}
 
 			// Check if the path ends with the after pattern
@@ -315,8 +621,6 @@ func recursiveGlob(pattern string) ([]string, error) {
 			// No pattern after **, match all files
 			matches = append(matches, path)
 		}
-
-		return nil
 	})
 
 	if err != nil {
@@ -326,28 +630,127 @@ func recursiveGlob(pattern string) ([]string, error) {
 	return matches, nil
 }
 
+// splitRecursivePattern splits a pattern at its first ** into the base directory to walk and
+// the slash-separated pattern after **. It reports false if the pattern has no **.
+func splitRecursivePattern(pattern string) (baseDir string, afterPattern string, recursive bool) {
+	// Split pattern into parts
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// Find the index of the first ** in the pattern
+	recursiveIndex := -1
+	for i, part := range parts {
+		if part == "**" {
+			recursiveIndex = i
+			break
+		}
+	}
+
+	if recursiveIndex == -1 {
+		return "", "", false
+	}
+
+	// Get the base directory (everything before **)
+	if recursiveIndex == 0 {
+		baseDir = "."
+	} else {
+		// Reconstruct the base directory path from the slash-separated parts, which keeps
+		// a leading / as well as Windows drive letters ("C:/") and UNC prefixes ("//host/")
+		baseDir = filepath.FromSlash(strings.Join(parts[:recursiveIndex], "/"))
+		if baseDir == "" || strings.HasSuffix(baseDir, ":") {
+			baseDir += string(filepath.Separator)
+		}
+	}
+
+	// Get the pattern after ** (everything after **)
+	afterPattern = strings.Join(parts[recursiveIndex+1:], "/")
+
+	return baseDir, afterPattern, true
+}
+
+// walkFiles calls fn for every file below root. With followSymlinks, symlinked directories
+// are walked as well, reporting paths below the link; every real directory is walked once,
+// so symlink cycles terminate.
+func walkFiles(root string, followSymlinks bool, fn func(path string)) error {
+	visited := make(map[string]bool)
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		// WalkDir does not descend into a symlinked root, so walk its target instead
+		realDir := dir
+		if followSymlinks {
+			var err error
+			if realDir, err = filepath.EvalSymlinks(dir); err != nil {
+				return nil
+			}
+		}
+
+		return filepath.WalkDir(realDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Skip directories we can't read
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// Report paths below the directory as it was reached
+			if realDir != dir {
+				relPath, err := filepath.Rel(realDir, path)
+				if err != nil {
+					return nil
+				}
+				path = filepath.Join(dir, relPath)
+			}
+
+			if d.IsDir() {
+				if followSymlinks {
+					realPath, err := filepath.EvalSymlinks(path)
+					if err != nil || visited[realPath] {
+						log.Debug().Str("directory", path).Msg("Skipping directory that was already walked")
+						return filepath.SkipDir
+					}
+					visited[realPath] = true
+				}
+				return nil
+			}
+
+			if followSymlinks && d.Type()&fs.ModeSymlink != 0 {
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					return walk(path)
+				}
+			}
+
+			fn(path)
+			return nil
+		})
+	}
+
+	return walk(root)
+}
+
 // matchesAfterPattern checks if a path matches the pattern after **
 // It supports both exact matches and suffix matches
-func matchesAfterPattern(path, pattern string) bool {
+func matchesAfterPattern(filePath, pattern string) bool {
 	// Normalize path separators
-	path = filepath.ToSlash(path)
+	filePath = filepath.ToSlash(filePath)
 	pattern = filepath.ToSlash(pattern)
 
 	// If pattern has no wildcards, check if path ends with pattern
 	if !strings.ContainsAny(pattern, "*?[") {
-		return strings.HasSuffix(path, pattern) || path == pattern
+		return strings.HasSuffix(filePath, pattern) || filePath == pattern
 	}
 
 	// For patterns with wildcards, try matching against each segment
 	// This handles cases like "*/*.yaml" matching "dev/Chart.yaml"
-	matched, _ := filepath.Match(pattern, path)
+	// Both are slash-separated, so match with path.Match on every platform
+	matched, _ := path.Match(pattern, filePath)
 	if matched {
 		return true
 	}
 
 	// Also check if the basename matches the pattern
 	// This handles "*.yaml" matching files at any depth
-	basename := filepath.Base(path)
-	matched, _ = filepath.Match(pattern, basename)
+	basename := path.Base(filePath)
+	matched, _ = path.Match(pattern, basename)
 	return matched
 }
@@ -1,10 +1,13 @@
 package configuration
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"testing"
+
+	"github.com/rs/zerolog"
 )
 
 func TestExpandWildcardTargets(t *testing.T) {
@@ -266,6 +269,87 @@ version: 1.0.0
 		}
 	}
 }
+
+func TestExpandWildcardTargets_PreservesUpdateSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(target *Target)
+		preserved func(target *Target) bool
+	}{
+		{
+			name:      "track",
+			configure: func(target *Target) { target.Track = "stable" },
+			preserved: func(target *Target) bool { return target.Track == "stable" },
+		},
+		{
+			name:      "maxUpdateType",
+			configure: func(target *Target) { target.MaxUpdateType = "minor" },
+			preserved: func(target *Target) bool { return target.MaxUpdateType == "minor" },
+		},
+		{
+			name:      "versionPrefix",
+			configure: func(target *Target) { target.VersionPrefix = "v" },
+			preserved: func(target *Target) bool { return target.VersionPrefix == "v" },
+		},
+		{
+			name:      "versionTemplate",
+			configure: func(target *Target) { target.VersionTemplate = "{{version}}-alpine" },
+			preserved: func(target *Target) bool { return target.VersionTemplate == "{{version}}-alpine" },
+		},
+		{
+			name:      "extractPattern",
+			configure: func(target *Target) { target.ExtractPattern = `:(?P<version>[^@]+)` },
+			preserved: func(target *Target) bool { return target.ExtractPattern == `:(?P<version>[^@]+)` },
+		},
+		{
+			name:      "writeTemplate",
+			configure: func(target *Target) { target.WriteTemplate = "app:{{version}}" },
+			preserved: func(target *Target) bool { return target.WriteTemplate == "app:{{version}}" },
+		},
+		{
+			name:      "versionSet",
+			configure: func(target *Target) { target.VersionSet = "backend" },
+			preserved: func(target *Target) bool { return target.VersionSet == "backend" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for _, env := range []string{"env1", "env2"} {
+				dir := filepath.Join(tmpDir, env)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create test directory: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\nname: test\nversion: 1.0.0\n"), 0644); err != nil {
+					t.Fatalf("Failed to write test file: %v", err)
+				}
+			}
+
+			target := &Target{
+				Name:  "test-charts",
+				Type:  TargetTypeSubchart,
+				File:  filepath.Join(tmpDir, "*", "Chart.yaml"),
+				Items: []TargetItem{{SubchartName: "backend", Source: "backend-source"}},
+			}
+			tt.configure(target)
+			config := &Config{Targets: []*Target{target}}
+
+			if err := ExpandWildcardTargets(config); err != nil {
+				t.Fatalf("ExpandWildcardTargets failed: %v", err)
+			}
+			if len(config.Targets) != 2 {
+				t.Fatalf("Expected 2 expanded targets, got %d", len(config.Targets))
+			}
+			for _, expanded := range config.Targets {
+				if !tt.preserved(expanded) {
+					t.Errorf("%s not preserved for %s, got: %+v", tt.name, expanded.File, expanded)
+				}
+			}
+		})
+	}
+}
+
 func TestExpandWildcardTargets_RecursiveGlob(t *testing.T) {
 	// Create a nested directory structure with Chart.yaml files
 	tmpDir := t.TempDir()
@@ -437,3 +521,468 @@ func TestExpandWildcardTargets_RecursiveGlobSingleLevel(t *testing.T) {
 		t.Errorf("Expected to match %s", subFile)
 	}
 }
+
+func TestExpandWildcardTargets_SourcePattern(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, app := range []string{"api", "web", "unknown"} {
+		dir := filepath.Join(tmpDir, "apps", app)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	config := &Config{
+		PackageSources: []*PackageSource{
+			{Name: "api"},
+			{Name: "web"},
+			{Name: "sidecar"},
+		},
+		Targets: []*Target{
+			{
+				Name:          "apps",
+				Type:          TargetTypeYamlField,
+				File:          filepath.Join(tmpDir, "apps", "*", "values.yaml"),
+				SourcePattern: `apps/(?P<source>[^/]+)/values\.yaml$`,
+				Items: []TargetItem{
+					{YamlPath: "image.tag"},
+					{YamlPath: "sidecar.tag", Source: "sidecar"},
+				},
+			},
+		},
+	}
+
+	if err := ExpandWildcardTargets(config); err != nil {
+		t.Fatalf("ExpandWildcardTargets() error = %v", err)
+	}
+
+	if len(config.Targets) != 2 {
+		t.Fatalf("expected 2 targets (unknown source skipped), got %d", len(config.Targets))
+	}
+	for _, target := range config.Targets {
+		app := filepath.Base(filepath.Dir(target.File))
+		if target.Items[0].Source != app {
+			t.Errorf("expected derived source %s for %s, got %s", app, target.File, target.Items[0].Source)
+		}
+		if target.Items[1].Source != "sidecar" {
+			t.Errorf("explicit source should be kept, got %s", target.Items[1].Source)
+		}
+	}
+
+	invalid := &Config{
+		Targets: []*Target{
+			{Name: "apps", File: filepath.Join(tmpDir, "apps", "*", "values.yaml"), SourcePattern: "("},
+		},
+	}
+	if err := ExpandWildcardTargets(invalid); err == nil {
+		t.Error("expected error for invalid sourcePattern")
+	}
+}
+
+func TestExpandWildcardTargets_SourcePatternNamespace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, app := range []string{"api", "new-service"} {
+		dir := filepath.Join(tmpDir, "apps", app)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	config := &Config{
+		PackageSources: []*PackageSource{
+			{Name: "acme", Type: PackageSourceTypeDockerNamespace},
+		},
+		Targets: []*Target{
+			{
+				Name:          "apps",
+				Type:          TargetTypeYamlField,
+				File:          filepath.Join(tmpDir, "apps", "*", "values.yaml"),
+				SourcePattern: `apps/(?P<source>[^/]+)/values\.yaml$`,
+				Items:         []TargetItem{{YamlPath: "image.tag", Source: "acme"}},
+			},
+		},
+	}
+
+	if err := ExpandWildcardTargets(config); err != nil {
+		t.Fatalf("ExpandWildcardTargets() error = %v", err)
+	}
+
+	// Every matched app follows its repository of the namespace, without a source per app
+	if len(config.Targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(config.Targets))
+	}
+	for _, target := range config.Targets {
+		expected := "acme/" + filepath.Base(filepath.Dir(target.File))
+		if target.Items[0].Source != expected {
+			t.Errorf("expected source %s for %s, got %s", expected, target.File, target.Items[0].Source)
+		}
+	}
+}
+
+func TestExpandWildcardTargets_PatchGroupTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, env := range []string{"dev", "staging", "prod"} {
+		dir := filepath.Join(tmpDir, "envs", env, "app")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	config := &Config{
+		Targets: []*Target{
+			{
+				Name:       "apps",
+				Type:       TargetTypeYamlField,
+				File:       filepath.Join(tmpDir, "envs", "*", "*", "values.yaml"),
+				PatchGroup: "{{ .Dir 1 }}-{{ .Dir 2 }}",
+				Items: []TargetItem{
+					{YamlPath: "image.tag", Source: "app"},
+					{YamlPath: "sidecar.tag", Source: "sidecar", PatchGroup: "sidecar-{{ .Dir 1 }}"},
+					{YamlPath: "proxy.tag", Source: "proxy", PatchGroup: "proxy"},
+				},
+			},
+		},
+	}
+
+	if err := ExpandWildcardTargets(config); err != nil {
+		t.Fatalf("ExpandWildcardTargets() error = %v", err)
+	}
+
+	if len(config.Targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(config.Targets))
+	}
+	for _, target := range config.Targets {
+		env := filepath.Base(filepath.Dir(filepath.Dir(target.File)))
+		if target.PatchGroup != env+"-app" {
+			t.Errorf("expected patch group %s-app for %s, got %s", env, target.File, target.PatchGroup)
+		}
+		if target.Items[1].PatchGroup != "sidecar-"+env {
+			t.Errorf("expected item patch group sidecar-%s, got %s", env, target.Items[1].PatchGroup)
+		}
+		if target.Items[2].PatchGroup != "proxy" {
+			t.Errorf("plain item patch group should be kept, got %s", target.Items[2].PatchGroup)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		target *Target
+	}{
+		{
+			name:   "invalid template",
+			target: &Target{Name: "apps", File: filepath.Join(tmpDir, "envs", "*", "*", "values.yaml"), PatchGroup: "{{ .Dir"},
+		},
+		{
+			name:   "empty patch group",
+			target: &Target{Name: "apps", File: filepath.Join(tmpDir, "envs", "*", "*", "values.yaml"), PatchGroup: "{{ .Dir 5 }}"},
+		},
+		{
+			name:   "unknown field",
+			target: &Target{Name: "apps", File: filepath.Join(tmpDir, "envs", "*", "*", "values.yaml"), PatchGroup: "{{ .Env }}"},
+		},
+		{
+			name:   "no wildcard",
+			target: &Target{Name: "apps", File: filepath.Join(tmpDir, "envs", "dev", "app", "values.yaml"), PatchGroup: "{{ .Dir 1 }}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ExpandWildcardTargets(&Config{Targets: []*Target{tt.target}}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestWildcardMatchData_Dir(t *testing.T) {
+	data := newWildcardMatchData("deploy/envs/*/**/values.yaml", "deploy/envs/prod/eu/app/values.yaml")
+
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, ""},
+		{1, "prod"},
+		{2, "eu"},
+		{3, "app"},
+		{4, ""},
+	}
+	for _, tt := range tests {
+		if dir := data.Dir(tt.n); dir != tt.expected {
+			t.Errorf("Dir(%d) = %q, expected %q", tt.n, dir, tt.expected)
+		}
+	}
+}
+
+func TestExpandWildcardTargets_ExcludeFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := []string{
+		filepath.Join("envs", "dev", "values.yaml"),
+		filepath.Join("envs", "prod", "values.yaml"),
+		filepath.Join("envs", "archived", "old", "values.yaml"),
+		filepath.Join("envs", "dev", "test", "values.yaml"),
+	}
+	for _, file := range files {
+		path := filepath.Join(tmpDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		excludeFiles []string
+		expected     []string
+	}{
+		{
+			name:     "no exclusions",
+			expected: files,
+		},
+		{
+			name:         "exclude test directories at any depth",
+			excludeFiles: []string{"**/test/**"},
+			expected:     files[:3],
+		},
+		{
+			name:         "exclude archived environments",
+			excludeFiles: []string{filepath.Join(tmpDir, "envs", "archived", "**")},
+			expected:     []string{files[0], files[1], files[3]},
+		},
+		{
+			name:         "exclude everything",
+			excludeFiles: []string{"**/values.yaml"},
+			expected:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Targets: []*Target{
+					{
+						Name:         "values",
+						Type:         TargetTypeYamlField,
+						File:         filepath.Join(tmpDir, "**", "values.yaml"),
+						ExcludeFiles: tt.excludeFiles,
+						Items:        []TargetItem{{YamlPath: "image.tag", Source: "app"}},
+					},
+				},
+			}
+
+			if err := ExpandWildcardTargets(config); err != nil {
+				t.Fatalf("ExpandWildcardTargets() error = %v", err)
+			}
+
+			actual := make([]string, len(config.Targets))
+			for i, target := range config.Targets {
+				actual[i], _ = filepath.Rel(tmpDir, target.File)
+			}
+			expected := append([]string{}, tt.expected...)
+			sort.Strings(actual)
+			sort.Strings(expected)
+			if len(actual) != len(expected) {
+				t.Fatalf("expected files %v, got %v", expected, actual)
+			}
+			for i := range expected {
+				if actual[i] != expected[i] {
+					t.Errorf("expected files %v, got %v", expected, actual)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestExpandWildcardTargets_FollowSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	shared := filepath.Join(tmpDir, "shared", "app")
+	if err := os.MkdirAll(shared, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(shared, "values.yaml"), []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	envs := filepath.Join(tmpDir, "envs")
+	if err := os.MkdirAll(envs, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Symlink(shared, filepath.Join(envs, "app")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	// Cycle back to the root of the walk
+	if err := os.Symlink(envs, filepath.Join(shared, "loop")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		expected       []string
+	}{
+		{
+			name:     "symlinks not followed",
+			expected: []string{},
+		},
+		{
+			name:           "symlinks followed once",
+			followSymlinks: true,
+			expected:       []string{filepath.Join(envs, "app", "values.yaml")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := recursiveGlob(filepath.Join(envs, "**", "values.yaml"), tt.followSymlinks)
+			if err != nil {
+				t.Fatalf("recursiveGlob() error = %v", err)
+			}
+			if len(matches) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, matches)
+			}
+			for i := range tt.expected {
+				if matches[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, matches)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitRecursivePattern(t *testing.T) {
+	tests := []struct {
+		name         string
+		pattern      string
+		baseDir      string
+		afterPattern string
+		recursive    bool
+	}{
+		{
+			name:      "no recursive wildcard",
+			pattern:   filepath.Join("envs", "*", "values.yaml"),
+			recursive: false,
+		},
+		{
+			name:         "leading recursive wildcard",
+			pattern:      filepath.Join("**", "values.yaml"),
+			baseDir:      ".",
+			afterPattern: "values.yaml",
+			recursive:    true,
+		},
+		{
+			name:         "relative base directory",
+			pattern:      filepath.Join("envs", "prod", "**", "*", "values.yaml"),
+			baseDir:      filepath.Join("envs", "prod"),
+			afterPattern: "*/values.yaml",
+			recursive:    true,
+		},
+		{
+			name:         "absolute base directory",
+			pattern:      filepath.Join(string(filepath.Separator), "repo", "**", "Chart.yaml"),
+			baseDir:      filepath.Join(string(filepath.Separator), "repo"),
+			afterPattern: "Chart.yaml",
+			recursive:    true,
+		},
+		{
+			name:         "root base directory",
+			pattern:      filepath.Join(string(filepath.Separator), "**", "Chart.yaml"),
+			baseDir:      string(filepath.Separator),
+			afterPattern: "Chart.yaml",
+			recursive:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir, afterPattern, recursive := splitRecursivePattern(tt.pattern)
+			if recursive != tt.recursive {
+				t.Fatalf("expected recursive %v, got %v", tt.recursive, recursive)
+			}
+			if baseDir != tt.baseDir || afterPattern != tt.afterPattern {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.baseDir, tt.afterPattern, baseDir, afterPattern)
+			}
+		})
+	}
+}
+
+func TestMatchesAfterPattern(t *testing.T) {
+	tests := []struct {
+		path     string
+		pattern  string
+		expected bool
+	}{
+		{path: filepath.Join("dev", "app", "Chart.yaml"), pattern: "Chart.yaml", expected: true},
+		{path: filepath.Join("dev", "Chart.yaml"), pattern: "*/Chart.yaml", expected: true},
+		{path: filepath.Join("dev", "app", "values.yaml"), pattern: "*.yaml", expected: true},
+		{path: filepath.Join("dev", "app", "Chart.yaml"), pattern: "*/Chart.yaml", expected: false},
+		{path: filepath.Join("dev", "values.json"), pattern: "*.yaml", expected: false},
+	}
+
+	for _, tt := range tests {
+		if actual := matchesAfterPattern(tt.path, tt.pattern); actual != tt.expected {
+			t.Errorf("matchesAfterPattern(%q, %q) = %v, expected %v", tt.path, tt.pattern, actual, tt.expected)
+		}
+	}
+}
+
+func BenchmarkExpandWildcardTargets(b *testing.B) {
+	// Logging per file would dominate the measurements
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	// 20 environments with 50 applications each, plus templates that no pattern matches
+	root := b.TempDir()
+	for env := 0; env < 20; env++ {
+		for app := 0; app < 50; app++ {
+			dir := filepath.Join(root, "environments", fmt.Sprintf("env-%d", env), fmt.Sprintf("app-%d", app))
+			if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+				b.Fatal(err)
+			}
+			for _, name := range []string{"values.yaml", "Chart.yaml", filepath.Join("templates", "deployment.yaml")} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("image:\n  tag: 1.0.0\n"), 0644); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	patterns := map[string]string{
+		"single-level": filepath.Join(root, "environments", "*", "*", "values.yaml"),
+		"recursive":    filepath.Join(root, "**", "values.yaml"),
+	}
+	for name, pattern := range patterns {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				config := &Config{
+					Targets: []*Target{{
+						Name:  "apps",
+						File:  pattern,
+						Items: []TargetItem{{YamlPath: "image.tag", Source: "app"}},
+					}},
+				}
+				if err := ExpandWildcardTargets(config); err != nil {
+					b.Fatal(err)
+				}
+				if len(config.Targets) != 1000 {
+					b.Fatalf("expected 1000 targets, got %d", len(config.Targets))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package configuration
+
+import "testing"
+
+func TestSplitRecursivePattern_Windows(t *testing.T) {
+	tests := []struct {
+		name         string
+		pattern      string
+		baseDir      string
+		afterPattern string
+	}{
+		{
+			name:         "drive letter",
+			pattern:      `C:\repo\**\values.yaml`,
+			baseDir:      `C:\repo`,
+			afterPattern: "values.yaml",
+		},
+		{
+			name:         "drive root",
+			pattern:      `C:\**\Chart.yaml`,
+			baseDir:      `C:\`,
+			afterPattern: "Chart.yaml",
+		},
+		{
+			name:         "forward slashes",
+			pattern:      `D:/repo/envs/**/*/values.yaml`,
+			baseDir:      `D:\repo\envs`,
+			afterPattern: "*/values.yaml",
+		},
+		{
+			name:         "UNC path",
+			pattern:      `\\server\share\repo\**\values.yaml`,
+			baseDir:      `\\server\share\repo`,
+			afterPattern: "values.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir, afterPattern, recursive := splitRecursivePattern(tt.pattern)
+			if !recursive {
+				t.Fatal("expected recursive pattern")
+			}
+			if baseDir != tt.baseDir || afterPattern != tt.afterPattern {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.baseDir, tt.afterPattern, baseDir, afterPattern)
+			}
+		})
+	}
+}
+
+func TestExcludeMatches_Windows(t *testing.T) {
+	matches := []string{`C:\repo\envs\dev\values.yaml`, `C:\repo\envs\test\values.yaml`}
+
+	kept := excludeMatches(matches, []string{`**\test\**`})
+	if len(kept) != 1 || kept[0] != matches[0] {
+		t.Errorf("expected only %s to be kept, got %v", matches[0], kept)
+	}
+}
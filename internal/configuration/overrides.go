@@ -0,0 +1,237 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// override sets a single configuration value from the command line, e.g.
+// targets[0].file=charts/app/Chart.yaml or sources.myapp.versionConstraint=<2
+type override struct {
+	raw      string            // Path of the value as given
+	value    string            // Value, decoded as YAML unless the field is a string
+	segments []overrideSegment // Parsed path
+}
+
+// overrideSegment is a step of an override path: a field or map key, an entry of a list
+// selected by its name, or an index into a list
+type overrideSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// overrideAliases are short names of top-level configuration keys
+var overrideAliases = map[string]string{
+	"sources":   "packageSources",
+	"providers": "packageSourceProviders",
+}
+
+// overrides are the --set values applied to every configuration that is loaded
+var overrides []*override
+
+// SetOverrides parses the --set values applied to every configuration that is loaded. Each
+// value has the form <path>=<value>.
+func SetOverrides(values []string) error {
+	parsed := make([]*override, 0, len(values))
+	for _, value := range values {
+		o, err := parseOverride(value)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, o)
+	}
+	overrides = parsed
+	return nil
+}
+
+// parseOverride parses a <path>=<value> override. Path segments are separated by dots; list
+// entries are selected by index (targets[0]) or by name (sources.myapp or targets[my.app]).
+func parseOverride(value string) (*override, error) {
+	path, fieldValue, ok := strings.Cut(value, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid --set %s: expected <path>=<value>", value)
+	}
+
+	segments, err := parseOverridePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --set %s: %w", value, err)
+	}
+	if alias, ok := overrideAliases[segments[0].key]; ok && !segments[0].isIndex {
+		segments[0].key = alias
+	}
+
+	return &override{raw: path, value: fieldValue, segments: segments}, nil
+}
+
+// parseOverridePath splits an override path into its segments
+func parseOverridePath(path string) ([]overrideSegment, error) {
+	var segments []overrideSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i-1] == '.' {
+				return nil, fmt.Errorf("empty path segment")
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 2 {
+				return nil, fmt.Errorf("unterminated or empty [] in path")
+			}
+			key := path[i+1 : i+end]
+			if index, err := strconv.Atoi(key); err == nil {
+				if index < 0 {
+					return nil, fmt.Errorf("negative index %d in path", index)
+				}
+				segments = append(segments, overrideSegment{index: index, isIndex: true})
+			} else {
+				segments = append(segments, overrideSegment{key: key})
+			}
+			i += end + 1
+			if i < len(path) && path[i] != '.' && path[i] != '[' {
+				return nil, fmt.Errorf("expected . or [ after ]")
+			}
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, overrideSegment{key: path[i : i+end]})
+			i += end
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
+
+// ApplyOverrides applies the --set overrides to a configuration as written
+func ApplyOverrides(config *Config) error {
+	for _, o := range overrides {
+		log.Debug().Str("path", o.raw).Msg("Applying configuration override")
+		if err := o.apply(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply sets the value of the override in the configuration
+func (o *override) apply(config *Config) error {
+	if err := setOverrideValue(reflect.ValueOf(config).Elem(), o.segments, o.value); err != nil {
+		return fmt.Errorf("--set %s: %w", o.raw, err)
+	}
+	return nil
+}
+
+// setOverrideValue follows the path from v and sets the value it leads to. Pointers, lists
+// and maps on the way are created as needed.
+func setOverrideValue(v reflect.Value, path []overrideSegment, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if len(path) == 0 {
+		return decodeOverrideValue(v, value)
+	}
+	segment, rest := path[0], path[1:]
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if segment.isIndex {
+			return fmt.Errorf("cannot index into %s", v.Type().Name())
+		}
+		field, ok := yamlField(v, segment.key)
+		if !ok {
+			return fmt.Errorf("unknown field '%s' of %s", segment.key, v.Type().Name())
+		}
+		return setOverrideValue(field, rest, value)
+
+	case reflect.Slice:
+		if segment.isIndex {
+			switch {
+			case segment.index == v.Len():
+				v.Set(reflect.Append(v, reflect.New(v.Type().Elem()).Elem()))
+			case segment.index > v.Len():
+				return fmt.Errorf("index %d is out of range, the list has %d entries", segment.index, v.Len())
+			}
+			return setOverrideValue(v.Index(segment.index), rest, value)
+		}
+		for i := 0; i < v.Len(); i++ {
+			entry := reflect.Indirect(v.Index(i))
+			if entry.Kind() != reflect.Struct {
+				break
+			}
+			if name, ok := yamlField(entry, "name"); ok && name.Kind() == reflect.String && name.String() == segment.key {
+				return setOverrideValue(v.Index(i), rest, value)
+			}
+		}
+		return fmt.Errorf("no entry named '%s'", segment.key)
+
+	case reflect.Map:
+		if segment.isIndex {
+			return fmt.Errorf("cannot index into a map")
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(segment.key).Convert(v.Type().Key())
+		entry := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			entry.Set(existing)
+		}
+		if err := setOverrideValue(entry, rest, value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, entry)
+		return nil
+
+	default:
+		return fmt.Errorf("cannot set '%s' of a %s value", segment.key, v.Kind())
+	}
+}
+
+// decodeOverrideValue sets a value: strings are taken verbatim, everything else is decoded
+// as YAML, e.g. true, 5 or [a, b]. An empty value resets the field.
+func decodeOverrideValue(v reflect.Value, value string) error {
+	v.Set(reflect.Zero(v.Type()))
+	if value == "" {
+		return nil
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	if err := yaml.Unmarshal([]byte(value), v.Addr().Interface()); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	return nil
+}
+
+// yamlField returns the field of a struct with the given YAML key
+func yamlField(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,126 @@
+package configuration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newOverrideTestConfig() *Config {
+	return &Config{
+		PackageSources: []*PackageSource{
+			{Name: "app", VersionConstraint: "<3"},
+			{Name: "nginx.conf"},
+		},
+		Targets: []*Target{
+			{
+				Name:  "app",
+				File:  "charts/app/Chart.yaml",
+				Items: []TargetItem{{YamlPath: "image.tag", Source: "app"}},
+			},
+		},
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		check  func(config *Config) bool
+	}{
+		{
+			name:   "list index",
+			values: []string{"targets[0].file=charts/other/Chart.yaml"},
+			check:  func(c *Config) bool { return c.Targets[0].File == "charts/other/Chart.yaml" },
+		},
+		{
+			name:   "entry by name through alias",
+			values: []string{"sources.app.versionConstraint=<2"},
+			check:  func(c *Config) bool { return c.PackageSources[0].VersionConstraint == "<2" },
+		},
+		{
+			name:   "bracketed name with dots",
+			values: []string{"packageSources[nginx.conf].tagPattern=^1\\."},
+			check:  func(c *Config) bool { return c.PackageSources[1].TagPattern == "^1\\." },
+		},
+		{
+			name:   "nested item",
+			values: []string{"targets.app.items[0].source=other"},
+			check:  func(c *Config) bool { return c.Targets[0].Items[0].Source == "other" },
+		},
+		{
+			name:   "decoded non-string values",
+			values: []string{"sources.app.limit=5", "targets.app.labels=[a, b]"},
+			check: func(c *Config) bool {
+				return c.PackageSources[0].Limit == 5 && reflect.DeepEqual(c.Targets[0].Labels, []string{"a", "b"})
+			},
+		},
+		{
+			name:   "value containing equals sign",
+			values: []string{"targets.app.file=a=b.yaml"},
+			check:  func(c *Config) bool { return c.Targets[0].File == "a=b.yaml" },
+		},
+		{
+			name:   "empty value resets",
+			values: []string{"sources.app.versionConstraint="},
+			check:  func(c *Config) bool { return c.PackageSources[0].VersionConstraint == "" },
+		},
+		{
+			name:   "map entry and nil pointer are created",
+			values: []string{"patchGroups.prod.schedule=weekdays 09:00-17:00", "rollout.stages[0].name=dev"},
+			check: func(c *Config) bool {
+				return c.PatchGroups["prod"].Schedule == "weekdays 09:00-17:00" && c.Rollout.Stages[0].Name == "dev"
+			},
+		},
+		{
+			name:   "appending to a list",
+			values: []string{"targets[1].name=new"},
+			check:  func(c *Config) bool { return len(c.Targets) == 2 && c.Targets[1].Name == "new" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer SetOverrides(nil)
+			if err := SetOverrides(tt.values); err != nil {
+				t.Fatalf("SetOverrides() error = %v", err)
+			}
+			config := newOverrideTestConfig()
+			if err := ApplyOverrides(config); err != nil {
+				t.Fatalf("ApplyOverrides() error = %v", err)
+			}
+			if !tt.check(config) {
+				t.Errorf("override not applied: %+v", config)
+			}
+		})
+	}
+}
+
+func TestApplyOverrides_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedError string
+	}{
+		{"missing value", "targets[0].file", "expected <path>=<value>"},
+		{"empty segment", "targets..file=x", "empty path segment"},
+		{"unterminated index", "targets[0.file=x", "unterminated"},
+		{"unknown field", "targets[0].filename=x", "unknown field 'filename'"},
+		{"unknown name", "sources.missing.limit=1", "no entry named 'missing'"},
+		{"index out of range", "targets[5].file=x", "out of range"},
+		{"invalid value", "sources.app.limit=many", "invalid value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer SetOverrides(nil)
+			err := SetOverrides([]string{tt.value})
+			if err == nil {
+				err = ApplyOverrides(newOverrideTestConfig())
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
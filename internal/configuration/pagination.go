@@ -0,0 +1,79 @@
+package configuration
+
+import (
+	"regexp"
+)
+
+// semverLikePattern matches versions that start with a numeric version component
+var semverLikePattern = regexp.MustCompile(`^[vV]?\d+`)
+
+// PaginationStop decides when paginating a version feed sorted newest first can stop
+// because the oldest current version of the targets referencing a source was passed
+type PaginationStop struct {
+	template       string
+	extractPattern *regexp.Regexp
+	current        *PackageSourceVersion
+}
+
+// NewPaginationStop creates the pagination stop for a source. It returns nil if the source
+// does not enable incremental scraping, compares versions loosely or the current version is
+// unknown. Incremental sources declare that their feed lists versions newest first.
+func NewPaginationStop(source *PackageSource, currentVersion string) *PaginationStop {
+	if !source.Incremental || source.VersionScheme == VersionSchemeLoose || !semverLikePattern.MatchString(currentVersion) {
+		return nil
+	}
+
+	stop := &PaginationStop{
+		template: ResolveVersionTemplate(source.VersionTemplate, source.VersionPrefix),
+		current:  parsePaginationVersion(currentVersion),
+	}
+	if source.ExtractPattern != "" {
+		pattern, err := CompilePattern(source.ExtractPattern)
+		if err != nil {
+			return nil
+		}
+		stop.extractPattern = pattern
+	}
+	return stop
+}
+
+// Passed reports whether all versions of a scraped page are at or below the current version,
+// meaning all newer versions have been seen. A single older version on a page is not enough,
+// since feeds are rarely sorted strictly, e.g. when an old release line gets a patch. Tags that
+// are not versions are ignored, and a page without versions never passes. A nil stop never
+// passes.
+func (p *PaginationStop) Passed(versions []string) bool {
+	if p == nil {
+		return false
+	}
+
+	seen := false
+	for _, version := range versions {
+		bare, ok := p.bareVersion(version)
+		if !ok || !semverLikePattern.MatchString(bare) {
+			continue
+		}
+		if CompareVersions(parsePaginationVersion(bare), p.current) > 0 {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
+
+// parsePaginationVersion parses a bare version for comparison with CompareVersions
+func parsePaginationVersion(version string) *PackageSourceVersion {
+	parsed := &PackageSourceVersion{Version: version}
+	parsed.MajorVersion, parsed.MinorVersion, parsed.PatchVersion = ParseSemver(version)
+	parsed.BuildVersion, parsed.Revision = ParseBuild(version)
+	return parsed
+}
+
+// bareVersion strips the source version format from a scraped version
+func (p *PaginationStop) bareVersion(version string) (string, bool) {
+	if p.extractPattern != nil {
+		bare, _, ok := ExtractVersionMatch(p.extractPattern, version)
+		return bare, ok
+	}
+	return ExtractVersion(p.template, version)
+}
@@ -0,0 +1,113 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestNewPaginationStop(t *testing.T) {
+	if stop := NewPaginationStop(&PackageSource{}, "1.2.3"); stop != nil {
+		t.Errorf("Expected nil stop for non-incremental source")
+	}
+	if stop := NewPaginationStop(&PackageSource{Incremental: true}, ""); stop != nil {
+		t.Errorf("Expected nil stop for unknown current version")
+	}
+	if stop := NewPaginationStop(&PackageSource{Incremental: true}, "latest"); stop != nil {
+		t.Errorf("Expected nil stop for non-numeric current version")
+	}
+	if stop := NewPaginationStop(&PackageSource{Incremental: true, VersionScheme: VersionSchemeLoose}, "1.2.3"); stop != nil {
+		t.Errorf("Expected nil stop for loose version scheme")
+	}
+	if stop := NewPaginationStop(&PackageSource{Incremental: true}, "1.2.3"); stop == nil {
+		t.Errorf("Expected stop for incremental source")
+	}
+}
+
+func TestPaginationStop_Passed(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   *PackageSource
+		current  string
+		versions []string
+		expected bool
+	}{
+		{
+			name:     "only newer versions",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"v2.0.0", "v1.3.0", "v1.2.4"},
+			expected: false,
+		},
+		{
+			name:     "page at or below the current version",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"v1.2.3", "v1.2.2", "v0.9.0"},
+			expected: true,
+		},
+		{
+			name:     "page with a newer version",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"v1.2.4", "v1.2.3"},
+			expected: false,
+		},
+		{
+			name:     "older version before a newer one",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"v0.9.0", "v1.3.0"},
+			expected: false,
+		},
+		{
+			name:     "newer build is not passed",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"1.2.3.1"},
+			expected: false,
+		},
+		{
+			name:     "non-numeric tags are ignored",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"latest", "main", "v1.1.0"},
+			expected: true,
+		},
+		{
+			name:     "page without versions",
+			source:   &PackageSource{Incremental: true},
+			current:  "1.2.3",
+			versions: []string{"latest", "main"},
+			expected: false,
+		},
+		{
+			name:     "version template is stripped",
+			source:   &PackageSource{Incremental: true, VersionTemplate: "{{version}}-alpine"},
+			current:  "1.2.3",
+			versions: []string{"1.2.3-bookworm", "1.2.2-alpine"},
+			expected: true,
+		},
+		{
+			name:     "versions not matching the template are ignored",
+			source:   &PackageSource{Incremental: true, VersionTemplate: "{{version}}-alpine"},
+			current:  "1.2.3",
+			versions: []string{"1.0.0-bookworm"},
+			expected: false,
+		},
+		{
+			name:     "extract pattern is applied",
+			source:   &PackageSource{Incremental: true, ExtractPattern: `^release-(\d+\.\d+\.\d+)$`},
+			current:  "1.2.3",
+			versions: []string{"release-1.1.0"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop := NewPaginationStop(tt.source, tt.current)
+			if got := stop.Passed(tt.versions); got != tt.expected {
+				t.Errorf("Passed(%v) = %v, expected %v", tt.versions, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package configuration
+
+import (
+	"regexp"
+	"sync"
+)
+
+// patternCache holds the compiled regexes of configured patterns by expression. Patterns are
+// matched against every scraped tag, so each is compiled once, when the configuration is
+// validated, and shared by all sources and concurrent scrapes.
+var patternCache sync.Map
+
+// CompilePattern returns the compiled regex of a configured pattern such as a tagPattern,
+// compiling it on first use
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := patternCache.LoadOrStore(pattern, compiled)
+	return actual.(*regexp.Regexp), nil
+}
@@ -0,0 +1,38 @@
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	projectURLRegex     = regexp.MustCompile(`^https?://[^/]+/(?:orgs|users)/([\w.-]+)/projects/(\d+)/?$`)
+	projectShortRegex   = regexp.MustCompile(`^([\w.-]+)/(\d+)$`)
+	issueReferenceRegex = regexp.MustCompile(`^(?:([\w.-]+/[\w.-]+)#|#)?(\d+)$`)
+)
+
+// ParseProjectReference parses a GitHub project reference, either "owner/number" or the
+// project URL (e.g. https://github.com/orgs/acme/projects/5)
+func ParseProjectReference(ref string) (owner string, number int, err error) {
+	match := projectURLRegex.FindStringSubmatch(ref)
+	if match == nil {
+		match = projectShortRegex.FindStringSubmatch(ref)
+	}
+	if match == nil {
+		return "", 0, fmt.Errorf("invalid project reference %q, expected owner/number or a project URL", ref)
+	}
+	number, _ = strconv.Atoi(match[2])
+	return match[1], number, nil
+}
+
+// FormatIssueReference normalizes an issue reference ("123", "#123" or "owner/repo#123") to
+// the form GitHub links in pull request bodies
+func FormatIssueReference(ref string) (string, error) {
+	match := issueReferenceRegex.FindStringSubmatch(strings.TrimSpace(ref))
+	if match == nil {
+		return "", fmt.Errorf("invalid issue reference %q, expected 123, #123 or owner/repo#123", ref)
+	}
+	return match[1] + "#" + match[2], nil
+}
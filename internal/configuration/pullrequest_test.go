@@ -0,0 +1,68 @@
+package configuration
+
+import "testing"
+
+func TestParseProjectReference(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantOwner   string
+		wantNumber  int
+		expectError bool
+	}{
+		{ref: "acme/5", wantOwner: "acme", wantNumber: 5},
+		{ref: "https://github.com/orgs/acme/projects/12", wantOwner: "acme", wantNumber: 12},
+		{ref: "https://github.example.com/users/alice/projects/3/", wantOwner: "alice", wantNumber: 3},
+		{ref: "acme", expectError: true},
+		{ref: "https://github.com/acme/repo/projects/1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			owner, number, err := ParseProjectReference(tt.ref)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseProjectReference() error = %v", err)
+			}
+			if owner != tt.wantOwner || number != tt.wantNumber {
+				t.Errorf("ParseProjectReference() = %s, %d, expected %s, %d", owner, number, tt.wantOwner, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestFormatIssueReference(t *testing.T) {
+	tests := []struct {
+		ref         string
+		expected    string
+		expectError bool
+	}{
+		{ref: "123", expected: "#123"},
+		{ref: "#123", expected: "#123"},
+		{ref: "acme/platform#7", expected: "acme/platform#7"},
+		{ref: "acme/platform/7", expectError: true},
+		{ref: "issue", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := FormatIssueReference(tt.ref)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FormatIssueReference() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("FormatIssueReference() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package configuration
+
+import "strings"
+
+// ImagePlaceholder is replaced with the image name of a docker source, mapped through the
+// registry map, when rendering a writeTemplate
+const ImagePlaceholder = "{{image}}"
+
+// MapImage rewrites a fully qualified image name (registry/repository) to the mirror the
+// registry map assigns to it. Keys are registries or registry/repository prefixes, the
+// longest key matching whole path segments wins. Images without a mapping are returned
+// unchanged.
+func MapImage(registryMap map[string]string, image string) string {
+	longest := ""
+	for prefix := range registryMap {
+		if len(prefix) <= len(longest) {
+			continue
+		}
+		if image == prefix || strings.HasPrefix(image, prefix+"/") {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return image
+	}
+	return registryMap[longest] + image[len(longest):]
+}
@@ -0,0 +1,110 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapImage(t *testing.T) {
+	registryMap := map[string]string{
+		"docker.io":         "mirror.example.com/dockerhub",
+		"docker.io/bitnami": "mirror.example.com/bitnami",
+		"ghcr.io":           "mirror.example.com/ghcr",
+	}
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "docker.io/library/nginx", expected: "mirror.example.com/dockerhub/library/nginx"},
+		{image: "docker.io/bitnami/redis", expected: "mirror.example.com/bitnami/redis"},
+		{image: "docker.io/bitnamilegacy/redis", expected: "mirror.example.com/dockerhub/bitnamilegacy/redis"},
+		{image: "ghcr.io/org/app", expected: "mirror.example.com/ghcr/org/app"},
+		{image: "ghcr.io.example.com/org/app", expected: "ghcr.io.example.com/org/app"},
+		{image: "quay.io/org/app", expected: "quay.io/org/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if mapped := MapImage(registryMap, tt.image); mapped != tt.expected {
+				t.Errorf("MapImage(%q) = %q, expected %q", tt.image, mapped, tt.expected)
+			}
+		})
+	}
+
+	if mapped := MapImage(nil, "docker.io/library/nginx"); mapped != "docker.io/library/nginx" {
+		t.Errorf("expected images to be unchanged without a registry map, got %q", mapped)
+	}
+}
+
+func TestValidateConfiguration_RegistryMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		registryMap map[string]string
+		expectValid bool
+	}{
+		{name: "registry to mirror path", registryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub"}, expectValid: true},
+		{name: "repository prefix", registryMap: map[string]string{"ghcr.io/org": "mirror.example.com/org"}, expectValid: true},
+		{name: "scheme", registryMap: map[string]string{"docker.io": "https://mirror.example.com"}},
+		{name: "trailing slash", registryMap: map[string]string{"docker.io/": "mirror.example.com"}},
+		{name: "empty mirror", registryMap: map[string]string{"docker.io": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(&Config{RegistryMap: tt.registryMap})
+			if result.Valid != tt.expectValid {
+				t.Errorf("Expected valid=%v, got valid=%v, errors: %v", tt.expectValid, result.Valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestMergeConfigurations_RegistryMap(t *testing.T) {
+	merged, err := mergeConfigurations([]*Config{
+		{RegistryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub"}},
+		{RegistryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub", "ghcr.io": "mirror.example.com/ghcr"}},
+	})
+	if err != nil {
+		t.Fatalf("mergeConfigurations() error = %v", err)
+	}
+	if len(merged.RegistryMap) != 2 {
+		t.Errorf("expected 2 registry mappings, got %v", merged.RegistryMap)
+	}
+
+	_, err = mergeConfigurations([]*Config{
+		{RegistryMap: map[string]string{"docker.io": "mirror.example.com/dockerhub"}},
+		{RegistryMap: map[string]string{"docker.io": "other.example.com/dockerhub"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "conflicting registry mappings for docker.io") {
+		t.Errorf("expected a conflicting mapping error, got %v", err)
+	}
+}
+
+func TestValidateConfiguration_ImagePlaceholder(t *testing.T) {
+	newConfig := func(sourceType PackageSourceType) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{{Name: "dockerhub", Type: PackageSourceProviderTypeDocker}},
+			PackageSources:         []*PackageSource{{Name: "app", Provider: "dockerhub", Type: sourceType, URI: "example/app"}},
+			Targets: []*Target{{
+				Name:           "app",
+				Type:           TargetTypeYamlField,
+				File:           "values.yaml",
+				ExtractPattern: `:(?P<version>[^:]+)$`,
+				WriteTemplate:  "{{image}}:{{version}}",
+				Items:          []TargetItem{{YamlPath: "image", Source: "app"}},
+			}},
+		}
+	}
+
+	if result := ValidateConfiguration(newConfig(PackageSourceTypeDockerImage)); !result.Valid {
+		t.Errorf("expected {{image}} to be valid for docker sources, got %v", result.Errors)
+	}
+	result := ValidateConfiguration(newConfig(PackageSourceTypeGitRelease))
+	for _, err := range result.Errors {
+		if strings.Contains(err.Message, "requires a docker-image source") {
+			return
+		}
+	}
+	t.Errorf("expected {{image}} to require a docker source, got %v", result.Errors)
+}
@@ -0,0 +1,288 @@
+package configuration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleSearchLimit bounds the search for the next allowed time of a schedule
+const scheduleSearchLimit = 366 * 24 * time.Hour
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule is a maintenance window in which apply may update a patch group. It is either a
+// window like "weekdays 09:00-17:00 Europe/Berlin" or a cron expression like
+// "* 9-16 * * 1-5" matching every allowed minute, optionally prefixed with "CRON_TZ=<zone>".
+type Schedule struct {
+	location *time.Location
+
+	// Window schedules
+	days  [7]bool
+	start int // Minutes after midnight
+	end   int // Minutes after midnight, before start for windows spanning midnight
+
+	// Cron schedules
+	cron *cronExpression
+}
+
+type cronExpression struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	anyDOM      bool
+	anyDOW      bool
+}
+
+// ParseSchedule parses a window or cron schedule. Times are in UTC unless a zone is given.
+func ParseSchedule(schedule string) (*Schedule, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("schedule cannot be empty")
+	}
+
+	location := time.UTC
+	if zone, ok := strings.CutPrefix(fields[0], "CRON_TZ="); ok {
+		loaded, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %s: %w", zone, err)
+		}
+		location = loaded
+		fields = fields[1:]
+	}
+
+	if len(fields) == 5 {
+		cron, err := parseCronExpression(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron schedule %q: %w", schedule, err)
+		}
+		return &Schedule{location: location, cron: cron}, nil
+	}
+
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("invalid schedule %q: expected \"<days> <HH:MM>-<HH:MM> [zone]\" or a cron expression", schedule)
+	}
+	s := &Schedule{location: location}
+	if err := s.parseDays(fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	if err := s.parseTimeRange(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	if len(fields) == 3 {
+		loaded, err := time.LoadLocation(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %s: %w", fields[2], err)
+		}
+		s.location = loaded
+	}
+	return s, nil
+}
+
+// parseDays parses "daily", "weekdays", "weekends" or a comma separated list of days and
+// day ranges such as "mon,wed" or "mon-thu"
+func (s *Schedule) parseDays(days string) error {
+	switch strings.ToLower(days) {
+	case "daily":
+		days = "sun-sat"
+	case "weekdays":
+		days = "mon-fri"
+	case "weekends":
+		days = "sat,sun"
+	}
+
+	for _, part := range strings.Split(strings.ToLower(days), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := scheduleWeekdays[first]
+		if !ok {
+			return fmt.Errorf("unknown day %s (expected daily, weekdays, weekends or mon..sun)", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = scheduleWeekdays[last]; !ok {
+				return fmt.Errorf("unknown day %s (expected mon..sun)", last)
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			s.days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseTimeRange parses "HH:MM-HH:MM"
+func (s *Schedule) parseTimeRange(timeRange string) error {
+	start, end, ok := strings.Cut(timeRange, "-")
+	if !ok {
+		return fmt.Errorf("invalid time range %s (expected HH:MM-HH:MM)", timeRange)
+	}
+	var err error
+	if s.start, err = parseClock(start); err != nil {
+		return err
+	}
+	if s.end, err = parseClock(end); err != nil {
+		return err
+	}
+	if s.start == s.end {
+		return fmt.Errorf("time range %s is empty", timeRange)
+	}
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight, "24:00" being the end of the day
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		if clock == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time %s (expected HH:MM)", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Allows reports whether the schedule allows updates at the given time
+func (s *Schedule) Allows(t time.Time) bool {
+	t = t.In(s.location)
+	if s.cron != nil {
+		return s.cron.matches(t)
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if s.start < s.end {
+		return s.days[t.Weekday()] && minute >= s.start && minute < s.end
+	}
+	// Windows spanning midnight belong to the day they start on
+	if minute >= s.start {
+		return s.days[t.Weekday()]
+	}
+	return minute < s.end && s.days[(t.Weekday()+6)%7]
+}
+
+// Next returns the next time from t on that the schedule allows updates, with minute
+// precision. It returns false if there is none within a year.
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	candidate := t.Truncate(time.Minute)
+	for limit := t.Add(scheduleSearchLimit); candidate.Before(limit); candidate = candidate.Add(time.Minute) {
+		if s.Allows(candidate) {
+			if candidate.Before(t) {
+				return t, true
+			}
+			return candidate.In(s.location), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseCronExpression parses the five fields minute, hour, day of month, month and day of week
+func parseCronExpression(fields []string) (*cronExpression, error) {
+	bounds := []struct {
+		name     string
+		min, max int
+	}{
+		{"minute", 0, 59},
+		{"hour", 0, 23},
+		{"day of month", 1, 31},
+		{"month", 1, 12},
+		{"day of week", 0, 7},
+	}
+
+	sets := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", bounds[i].name, err)
+		}
+		sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+
+	return &cronExpression{
+		minutes:     sets[0],
+		hours:       sets[1],
+		daysOfMonth: sets[2],
+		months:      sets[3],
+		daysOfWeek:  sets[4],
+		anyDOM:      fields[2] == "*",
+		anyDOW:      fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of "*", values and ranges, each with an
+// optional "/step"
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed < 1 {
+				return nil, fmt.Errorf("invalid step %s", stepPart)
+			}
+			step = parsed
+		}
+
+		from, to := min, max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = strconv.Atoi(first); err != nil {
+				return nil, fmt.Errorf("invalid value %s", first)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("invalid value %s", last)
+				}
+			} else if hasStep {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("value %s out of range %d-%d", rangePart, min, max)
+		}
+
+		for value := from; value <= to; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
+
+// matches reports whether a time matches the cron expression. As in cron, a time matches
+// either restricted day field if both day of month and day of week are restricted.
+func (c *cronExpression) matches(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+	domMatch := c.daysOfMonth[t.Day()]
+	dowMatch := c.daysOfWeek[int(t.Weekday())]
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dowMatch
+	case c.anyDOW:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
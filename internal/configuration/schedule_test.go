@@ -0,0 +1,97 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleAllows(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	// Wednesday 2026-03-04
+	at := func(day int, hour int, minute int, location *time.Location) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, location)
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		time     time.Time
+		expected bool
+	}{
+		{name: "inside weekday window", schedule: "weekdays 09:00-17:00", time: at(4, 10, 30, time.UTC), expected: true},
+		{name: "window end is exclusive", schedule: "weekdays 09:00-17:00", time: at(4, 17, 0, time.UTC), expected: false},
+		{name: "weekend outside weekday window", schedule: "weekdays 09:00-17:00", time: at(7, 10, 0, time.UTC), expected: false},
+		{name: "time zone applied", schedule: "weekdays 09:00-17:00 Europe/Berlin", time: at(4, 8, 30, time.UTC), expected: true},
+		{name: "time zone outside window", schedule: "weekdays 09:00-17:00 Europe/Berlin", time: at(4, 16, 30, time.UTC), expected: false},
+		{name: "day list", schedule: "mon,wed 00:00-24:00", time: at(4, 23, 59, time.UTC), expected: true},
+		{name: "day range", schedule: "tue-thu 06:00-08:00", time: at(6, 7, 0, time.UTC), expected: false},
+		{name: "overnight window evening", schedule: "fri 22:00-06:00", time: at(6, 23, 0, time.UTC), expected: true},
+		{name: "overnight window next morning", schedule: "fri 22:00-06:00", time: at(7, 5, 0, time.UTC), expected: true},
+		{name: "overnight window wrong day", schedule: "fri 22:00-06:00", time: at(6, 5, 0, time.UTC), expected: false},
+		{name: "cron hours on weekdays", schedule: "* 9-16 * * 1-5", time: at(4, 16, 59, time.UTC), expected: true},
+		{name: "cron outside hours", schedule: "* 9-16 * * 1-5", time: at(4, 17, 0, time.UTC), expected: false},
+		{name: "cron sunday as 7", schedule: "* * * * 7", time: at(8, 12, 0, time.UTC), expected: true},
+		{name: "cron step", schedule: "*/15 * * * *", time: at(4, 12, 30, time.UTC), expected: true},
+		{name: "cron step mismatch", schedule: "*/15 * * * *", time: at(4, 12, 31, time.UTC), expected: false},
+		{name: "cron day of month or day of week", schedule: "* * 1 * 3", time: at(4, 12, 0, time.UTC), expected: true},
+		{name: "cron time zone", schedule: "CRON_TZ=Europe/Berlin * 9 * * *", time: at(4, 8, 15, time.UTC), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.schedule, err)
+			}
+			if got := schedule.Allows(tt.time); got != tt.expected {
+				t.Errorf("Allows(%s) = %v, expected %v", tt.time.In(berlin), got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	schedule, err := ParseSchedule("weekdays 09:00-17:00")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+
+	// Friday evening opens on Monday morning
+	next, ok := schedule.Next(time.Date(2026, time.March, 6, 18, 20, 30, 0, time.UTC))
+	if !ok {
+		t.Fatal("Expected a next window")
+	}
+	if expected := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("Next() = %s, expected %s", next, expected)
+	}
+
+	// Inside the window the time itself is returned
+	now := time.Date(2026, time.March, 4, 10, 0, 30, 0, time.UTC)
+	if next, _ := schedule.Next(now); !next.Equal(now) {
+		t.Errorf("Next() = %s, expected %s", next, now)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	schedules := []string{
+		"",
+		"weekdays",
+		"someday 09:00-17:00",
+		"weekdays 9-17",
+		"weekdays 09:00-09:00",
+		"weekdays 09:00-17:00 Mars/Olympus",
+		"* 25 * * *",
+		"* * * * mon",
+		"*/0 * * * *",
+		"CRON_TZ=Nowhere * * * * *",
+	}
+
+	for _, schedule := range schedules {
+		if _, err := ParseSchedule(schedule); err == nil {
+			t.Errorf("ParseSchedule(%q) expected error", schedule)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package configuration
+
+import "github.com/mxcd/updater/internal/util"
+
+// registerSecrets marks the credentials of providers and the target actor as secret so
+// they are masked in logs and serialized output
+func registerSecrets(config *Config) {
+	for _, provider := range config.PackageSourceProviders {
+		util.RegisterSecret(provider.Password)
+		util.RegisterSecret(provider.Token)
+	}
+	if config.TargetActor != nil {
+		util.RegisterSecret(config.TargetActor.Token)
+	}
+}
+
+// redactCredential masks a credential value of any length, keeping empty values empty
+func redactCredential(value string) string {
+	if value == "" {
+		return ""
+	}
+	return util.RedactedValue
+}
+
+// Redacted returns a copy of the provider with its password and token masked for output
+func (p *PackageSourceProvider) Redacted() *PackageSourceProvider {
+	redacted := *p
+	redacted.Password = redactCredential(p.Password)
+	redacted.Token = redactCredential(p.Token)
+	return &redacted
+}
+
+// Redacted returns a copy of the target actor with its token masked for output
+func (a *TargetActor) Redacted() *TargetActor {
+	redacted := *a
+	redacted.Token = redactCredential(a.Token)
+	return &redacted
+}
+
+// RedactedProviders returns copies of the providers of a configuration that are safe to serialize
+func RedactedProviders(config *Config) []*PackageSourceProvider {
+	providers := make([]*PackageSourceProvider, 0, len(config.PackageSourceProviders))
+	for _, provider := range config.PackageSourceProviders {
+		providers = append(providers, provider.Redacted())
+	}
+	return providers
+}
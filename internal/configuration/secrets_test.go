@@ -0,0 +1,50 @@
+package configuration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/util"
+)
+
+func TestRedactedProviders(t *testing.T) {
+	config := &Config{
+		PackageSourceProviders: []*PackageSourceProvider{
+			{Name: "registry", Type: PackageSourceProviderTypeDocker, Username: "ci", Password: "a&b"},
+			{Name: "github", Type: PackageSourceProviderTypeGitHub, Token: "x"},
+			{Name: "public", Type: PackageSourceProviderTypeDocker},
+		},
+	}
+
+	data, err := json.Marshal(RedactedProviders(config))
+	if err != nil {
+		t.Fatalf("failed to marshal providers: %v", err)
+	}
+	output := string(data)
+	// Short secrets and secrets with characters escaped by JSON are masked as well
+	for _, secret := range []string{`"a&b"`, `"x"`} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected %s to be masked, got %s", secret, output)
+		}
+	}
+	if strings.Count(output, util.RedactedValue) != 2 {
+		t.Errorf("expected 2 masked credentials, got %s", output)
+	}
+	if config.PackageSourceProviders[0].Password != "a&b" {
+		t.Errorf("redaction must not modify the configuration")
+	}
+}
+
+func TestTargetActor_Redacted(t *testing.T) {
+	actor := &TargetActor{Name: "bot", Token: "tok"}
+	if redacted := actor.Redacted(); redacted.Token != util.RedactedValue || redacted.Name != "bot" {
+		t.Errorf("unexpected redacted actor: %+v", redacted)
+	}
+	if actor.Token != "tok" {
+		t.Errorf("redaction must not modify the target actor")
+	}
+	if (&TargetActor{}).Redacted().Token != "" {
+		t.Errorf("empty token must stay empty")
+	}
+}
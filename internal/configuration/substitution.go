@@ -5,6 +5,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+
+	"github.com/mxcd/updater/internal/util"
 )
 
 // SubstitutionContext holds the state for variable substitution
@@ -102,8 +104,10 @@ func (ctx *SubstitutionContext) resolveSOPSReference(expression string) (string,
 		return "", fmt.Errorf("failed to access path %s in SOPS file %s: %w", yamlPath, filePath, err)
 	}
 
-	// Convert value to string
-	return fmt.Sprintf("%v", value), nil
+	// Convert value to string; decrypted values are secret and masked in all output
+	secret := fmt.Sprintf("%v", value)
+	util.RegisterSecret(secret)
+	return secret, nil
 }
 
 // loadSOPSFile loads and decrypts a SOPS file, with caching
@@ -0,0 +1,91 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// ExpandTargetTemplates generates the targets of all target templates and appends them to
+// the targets of the configuration
+func ExpandTargetTemplates(config *Config) error {
+	for i, targetTemplate := range config.TargetTemplates {
+		if len(targetTemplate.Services) == 0 {
+			return fmt.Errorf("targetTemplates[%d]: no services defined", i)
+		}
+		if targetTemplate.Target.Kind == 0 {
+			return fmt.Errorf("targetTemplates[%d]: no target defined", i)
+		}
+
+		names := make(map[string]bool, len(targetTemplate.Services))
+		for _, service := range targetTemplate.Services {
+			target, err := renderTargetTemplate(&targetTemplate.Target, service)
+			if err != nil {
+				return fmt.Errorf("targetTemplates[%d]: service %s: %w", i, service, err)
+			}
+			if names[target.Name] {
+				return fmt.Errorf("targetTemplates[%d]: duplicate target name %s, the name must reference {{ .name }}", i, target.Name)
+			}
+			names[target.Name] = true
+			config.Targets = append(config.Targets, target)
+		}
+
+		log.Debug().
+			Int("template", i).
+			Int("services", len(targetTemplate.Services)).
+			Msg("Expanded target template")
+	}
+
+	config.TargetTemplates = nil
+	return nil
+}
+
+// renderTargetTemplate renders every scalar of the target definition for a service and
+// decodes the result into a target
+func renderTargetTemplate(definition *yaml.Node, service string) (*Target, error) {
+	data := map[string]string{"name": service}
+
+	var render func(node *yaml.Node) (*yaml.Node, error)
+	render = func(node *yaml.Node) (*yaml.Node, error) {
+		rendered := *node
+		if node.Kind == yaml.ScalarNode {
+			if !strings.Contains(node.Value, "{{") {
+				return &rendered, nil
+			}
+			tmpl, err := template.New("target").Option("missingkey=error").Parse(node.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template at line %d: %w", node.Line, err)
+			}
+			var value strings.Builder
+			if err := tmpl.Execute(&value, data); err != nil {
+				return nil, fmt.Errorf("failed to render template at line %d: %w", node.Line, err)
+			}
+			rendered.Value = value.String()
+			return &rendered, nil
+		}
+
+		rendered.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			renderedChild, err := render(child)
+			if err != nil {
+				return nil, err
+			}
+			rendered.Content[i] = renderedChild
+		}
+		return &rendered, nil
+	}
+
+	node, err := render(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var target Target
+	if err := node.Decode(&target); err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	return &target, nil
+}
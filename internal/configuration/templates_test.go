@@ -0,0 +1,113 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandTargetTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		wantErr  string
+		expected []*Target
+	}{
+		{
+			name: "one target per service",
+			config: `
+targets:
+  - name: existing
+    type: yaml-field
+    file: values.yaml
+targetTemplates:
+  - services: [api, web]
+    target:
+      name: "{{ .name }}"
+      type: yaml-field
+      file: apps/{{ .name }}/values.yaml
+      patchGroup: apps
+      items:
+        - yamlPath: image.tag
+          source: "{{ .name }}-image"
+`,
+			expected: []*Target{
+				{Name: "existing", Type: TargetTypeYamlField, File: "values.yaml"},
+				{Name: "api", Type: TargetTypeYamlField, File: "apps/api/values.yaml", PatchGroup: "apps", Items: []TargetItem{{YamlPath: "image.tag", Source: "api-image"}}},
+				{Name: "web", Type: TargetTypeYamlField, File: "apps/web/values.yaml", PatchGroup: "apps", Items: []TargetItem{{YamlPath: "image.tag", Source: "web-image"}}},
+			},
+		},
+		{
+			name: "name without service reference",
+			config: `
+targetTemplates:
+  - services: [api, web]
+    target:
+      name: app
+      file: apps/{{ .name }}/values.yaml
+`,
+			wantErr: "duplicate target name app",
+		},
+		{
+			name: "unknown template field",
+			config: `
+targetTemplates:
+  - services: [api]
+    target:
+      name: "{{ .service }}"
+`,
+			wantErr: "failed to render template",
+		},
+		{
+			name: "no services",
+			config: `
+targetTemplates:
+  - target:
+      name: "{{ .name }}"
+`,
+			wantErr: "no services defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			if err := yaml.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatalf("Failed to parse test config: %v", err)
+			}
+
+			err := ExpandTargetTemplates(&config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandTargetTemplates() error = %v", err)
+			}
+
+			if config.TargetTemplates != nil {
+				t.Error("target templates should be cleared after expansion")
+			}
+			if len(config.Targets) != len(tt.expected) {
+				t.Fatalf("expected %d targets, got %d", len(tt.expected), len(config.Targets))
+			}
+			for i, expected := range tt.expected {
+				actual := config.Targets[i]
+				if actual.Name != expected.Name || actual.Type != expected.Type || actual.File != expected.File || actual.PatchGroup != expected.PatchGroup {
+					t.Errorf("target %d: expected %+v, got %+v", i, expected, actual)
+				}
+				if len(actual.Items) != len(expected.Items) {
+					t.Fatalf("target %d: expected %d items, got %d", i, len(expected.Items), len(actual.Items))
+				}
+				for j := range expected.Items {
+					if actual.Items[j].YamlPath != expected.Items[j].YamlPath || actual.Items[j].Source != expected.Items[j].Source {
+						t.Errorf("target %d item %d: expected %+v, got %+v", i, j, expected.Items[j], actual.Items[j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,53 +1,183 @@
 package configuration
 
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 type Config struct {
+	Include                []string                 `yaml:"include,omitempty"` // Files or URLs whose definitions this configuration extends
 	PackageSourceProviders []*PackageSourceProvider `yaml:"packageSourceProviders"`
 	PackageSources         []*PackageSource         `yaml:"packageSources"`
 	Targets                []*Target                `yaml:"targets"`
+	TargetTemplates        []*TargetTemplate        `yaml:"targetTemplates,omitempty"`
 	TargetActor            *TargetActor             `yaml:"targetActor,omitempty"`
+	ArgoCDImageUpdater     []*ArgoCDImageUpdater    `yaml:"argocdImageUpdater,omitempty"`
+	Environments           map[string]*Environment  `yaml:"environments,omitempty"`
+	PatchGroups            map[string]*PatchGroup   `yaml:"patchGroups,omitempty"` // Settings per patch group name
+	Rollout                *Rollout                 `yaml:"rollout,omitempty"`     // Stages a version is promoted through
+	RegistryMap            map[string]string        `yaml:"registryMap,omitempty"` // Mirror deployments pull each upstream registry or repository prefix from
+}
+
+// Rollout promotes versions through ordered stages: targets of a stage are only offered the
+// version the targets of the previous stage run, once it has soaked there or was approved
+type Rollout struct {
+	Stages []*RolloutStage `yaml:"stages"`
+}
+
+// RolloutStage is a stage of a rollout, referenced by the stage field of targets
+type RolloutStage struct {
+	Name          string `yaml:"name"`
+	SoakTime      string `yaml:"soakTime,omitempty"`      // How long the previous stage must run a version before it is promoted (e.g. "2d")
+	ApprovalLabel string `yaml:"approvalLabel,omitempty"` // Label on the previous stage's pull request promoting its version before the soak time
+}
+
+// PatchGroup holds settings of the patch group of the same name
+type PatchGroup struct {
+	Schedule   string   `yaml:"schedule,omitempty"`   // Maintenance window of apply, e.g. "weekdays 09:00-17:00 Europe/Berlin" or a cron expression
+	PostUpdate []string `yaml:"postUpdate,omitempty"` // Shell commands run once in the repository root after the postUpdate hooks of the group's targets, before the last commit
+}
+
+// Environment overrides sources and targets when selected with --env. Each entry is matched
+// by name and only the fields it sets replace those of the base definition; entries with a
+// new name are added.
+type Environment struct {
+	PackageSources []yaml.Node `yaml:"packageSources,omitempty"`
+	Targets        []yaml.Node `yaml:"targets,omitempty"`
+}
+
+// TargetTemplate generates a target per service. Every string of the target definition may
+// reference the service with {{ .name }}.
+type TargetTemplate struct {
+	Services []string  `yaml:"services"`
+	Target   yaml.Node `yaml:"target"`
+}
+
+// ArgoCDImageUpdater ingests argocd-image-updater annotations from Argo CD Application
+// manifests and synthesizes the equivalent package sources and targets
+type ArgoCDImageUpdater struct {
+	Files      []string `yaml:"files"`                // Application manifests, wildcards allowed
+	Provider   string   `yaml:"provider"`             // Docker provider used for all images of the applications
+	PatchGroup string   `yaml:"patchGroup,omitempty"` // Patch group of the synthesized targets
+	Labels     []string `yaml:"labels,omitempty"`     // Labels of the synthesized targets
 }
 
 type PackageSourceType string
 
 const (
-	PackageSourceTypeGitRelease     PackageSourceType = "git-release"
-	PackageSourceTypeGitTag         PackageSourceType = "git-tag"
-	PackageSourceTypeGitHelmChart   PackageSourceType = "git-helm-chart"
-	PackageSourceTypeDockerImage    PackageSourceType = "docker-image"
-	PackageSourceTypeHelmRepository PackageSourceType = "helm-chart"
+	PackageSourceTypeGitRelease      PackageSourceType = "git-release"
+	PackageSourceTypeGitTag          PackageSourceType = "git-tag"
+	PackageSourceTypeGitHelmChart    PackageSourceType = "git-helm-chart"
+	PackageSourceTypeDockerImage     PackageSourceType = "docker-image"
+	PackageSourceTypeHelmRepository  PackageSourceType = "helm-chart"
+	PackageSourceTypeStatic          PackageSourceType = "static"           // Versions listed in staticVersions, without a provider
+	PackageSourceTypeDockerNamespace PackageSourceType = "docker-namespace" // Repositories of a registry namespace, scraped as docker-image sources
+	PackageSourceTypeHelmRepoAll     PackageSourceType = "helm-repo-all"    // Charts of a Helm repository, scraped as helm-chart sources
 )
 
 type PackageSource struct {
-	Name              string                  `yaml:"name"`
-	Provider          string                  `yaml:"provider"`
-	Type              PackageSourceType       `yaml:"type"`
-	URI               string                  `yaml:"uri"`
-	Branch            string                  `yaml:"branch,omitempty"`    // Git branch (for git-helm-chart), defaults to "main"
-	Path              string                  `yaml:"path,omitempty"`      // File path in repository (for git-helm-chart)
-	ChartName         string                  `yaml:"chartName,omitempty"` // Helm chart name (for helm-chart)
-	VersionConstraint string                  `yaml:"versionConstraint,omitempty"`
-	TagPattern        string                  `yaml:"tagPattern,omitempty"`     // Regex to match desired tags
-	ExcludePattern    string                  `yaml:"excludePattern,omitempty"` // Regex to exclude unwanted tags
-	TagLimit          int                     `yaml:"tagLimit,omitempty"`       // Maximum number of tags to fetch from registry (before filtering)
-	SortBy            string                  `yaml:"sortBy,omitempty"`         // How to sort: "semantic", "date", "alphabetical"
-	Versions          []*PackageSourceVersion `yaml:"versions,omitempty"`
+	Name               string                     `yaml:"name"`
+	Provider           string                     `yaml:"provider"`
+	Type               PackageSourceType          `yaml:"type"`
+	URI                string                     `yaml:"uri"`
+	Branch             string                     `yaml:"branch,omitempty"`             // Git branch (for git-helm-chart), defaults to "main"
+	Path               string                     `yaml:"path,omitempty"`               // Chart directory or Chart.yaml path in repository (for git-helm-chart)
+	ExcludeDrafts      bool                       `yaml:"excludeDrafts,omitempty"`      // Skip draft releases (for git-release)
+	ExcludePrereleases bool                       `yaml:"excludePrereleases,omitempty"` // Skip prereleases (for git-release)
+	RequireAsset       string                     `yaml:"requireAsset,omitempty"`       // Regex an asset name of the release must match (for git-release)
+	ChartHistory       bool                       `yaml:"chartHistory,omitempty"`       // Read Chart.yaml at every matching tag (for git-helm-chart)
+	ChartName          string                     `yaml:"chartName,omitempty"`          // Helm chart name (for helm-chart)
+	VersionConstraint  string                     `yaml:"versionConstraint,omitempty"`
+	TagPattern         string                     `yaml:"tagPattern,omitempty"`        // Regex to match desired tags
+	ExcludePattern     string                     `yaml:"excludePattern,omitempty"`    // Regex to exclude unwanted tags
+	TagLimit           int                        `yaml:"tagLimit,omitempty"`          // Maximum number of tags to fetch from registry (before filtering)
+	Limit              int                        `yaml:"limit,omitempty"`             // Maximum versions kept after sorting and filtering, overrides --limit
+	SortBy             string                     `yaml:"sortBy,omitempty"`            // How to sort: "semantic", "date", "alphabetical"
+	VersionScheme      string                     `yaml:"versionScheme,omitempty"`     // How versions compare: "semantic" (default) or "loose"
+	Tracks             []*PackageSourceTrack      `yaml:"tracks,omitempty"`            // Named release channels targets can subscribe to
+	VersionPrefix      string                     `yaml:"versionPrefix,omitempty"`     // Prefix stripped from tags before comparing (e.g. "release-")
+	VersionTemplate    string                     `yaml:"versionTemplate,omitempty"`   // Tag format with {{version}} placeholder (e.g. "{{version}}-alpine")
+	ExtractPattern     string                     `yaml:"extractPattern,omitempty"`    // Regex whose "version" (or first) capture group holds the version
+	Verification       *PackageSourceVerification `yaml:"verification,omitempty"`      // Supply-chain policy candidate versions must pass
+	Incremental        bool                       `yaml:"incremental,omitempty"`       // Stop paginating once the current versions of all targets are passed; the feed must list versions newest first
+	StaleAfter         string                     `yaml:"staleAfter,omitempty"`        // Report the source as stale without a new version for this long (e.g. "90d")
+	StaticVersions     []string                   `yaml:"staticVersions,omitempty"`    // Versions of a static source
+	RepositoryPattern  string                     `yaml:"repositoryPattern,omitempty"` // Regex the repositories of a docker-namespace source must match
+	ChartPattern       string                     `yaml:"chartPattern,omitempty"`      // Regex the charts of a helm-repo-all source must match
+	DiscoveredBy       string                     `yaml:"-"`                           // Discovery source a discovered source belongs to
+	Versions           []*PackageSourceVersion    `yaml:"versions,omitempty"`
+}
+
+// Version schemes of a package source
+const (
+	VersionSchemeSemantic = "semantic" // major.minor.patch with build numbers and revisions
+	VersionSchemeLoose    = "loose"    // Debian version rules for tags that are not semver
+)
+
+// PackageSourceTrack is a named release channel of a source (e.g. lts, stable, mainline)
+// defined by a regex that matches the versions belonging to the channel
+type PackageSourceTrack struct {
+	Name       string `yaml:"name"`
+	TagPattern string `yaml:"tagPattern"`
+}
+
+// Discovers reports whether the source is a discovery source, whose packages are listed at
+// scrape time and scraped as sources of their own named <source>/<package>
+func (s *PackageSource) Discovers() bool {
+	return s.Type == PackageSourceTypeDockerNamespace || s.Type == PackageSourceTypeHelmRepoAll
+}
+
+// FindTrack returns the track with the given name, or nil if the source does not define it
+func (s *PackageSource) FindTrack(name string) *PackageSourceTrack {
+	for _, track := range s.Tracks {
+		if track.Name == name {
+			return track
+		}
+	}
+	return nil
+}
+
+type PackageSourceVerificationType string
+
+const (
+	PackageSourceVerificationTypeSignature  PackageSourceVerificationType = "signature"  // cosign signature
+	PackageSourceVerificationTypeProvenance PackageSourceVerificationType = "provenance" // SLSA provenance attestation
+	// PackageSourceVerificationTypeTagSignature verifies the git tag signature with GnuPG or gitsign
+	PackageSourceVerificationTypeTagSignature PackageSourceVerificationType = "tag-signature"
+)
+
+// PackageSourceVerification configures verification of candidate versions, either with a
+// public key or with a keyless certificate identity and OIDC issuer. Image tags are verified
+// with cosign, git tags with GnuPG (key) or gitsign (keyless).
+type PackageSourceVerification struct {
+	Type                  PackageSourceVerificationType `yaml:"type"`
+	Key                   string                        `yaml:"key,omitempty"`
+	CertificateIdentity   string                        `yaml:"certificateIdentity,omitempty"`
+	CertificateOidcIssuer string                        `yaml:"certificateOidcIssuer,omitempty"`
 }
 
 type PackageSourceVersion struct {
-	Version            string `yaml:"version"`
-	VersionInformation string `yaml:"versionInformation,omitempty"`
-	MajorVersion       int    `yaml:"majorVersion,omitempty"`
-	MinorVersion       int    `yaml:"minorVersion,omitempty"`
-	PatchVersion       int    `yaml:"patchVersion,omitempty"`
+	Version            string    `yaml:"version"`
+	VersionInformation string    `yaml:"versionInformation,omitempty"`
+	MajorVersion       int       `yaml:"majorVersion,omitempty"`
+	MinorVersion       int       `yaml:"minorVersion,omitempty"`
+	PatchVersion       int       `yaml:"patchVersion,omitempty"`
+	BuildVersion       int       `yaml:"buildVersion,omitempty"` // Fourth numeric component, e.g. 4 in "1.2.3.4"
+	Revision           string    `yaml:"revision,omitempty"`     // Numeric build suffix, e.g. "2023-11-01" in "7.4.0-2023-11-01"
+	ReleasedAt         time.Time `yaml:"releasedAt,omitempty"`   // When the version was published, zero if the provider does not tell
+	Breaking           bool      `yaml:"breaking,omitempty"`     // Release notes announce breaking changes
+	CVEs               []string  `yaml:"cves,omitempty"`         // CVE IDs mentioned in the release notes, e.g. fixed vulnerabilities
 }
 
 type PackageSourceProviderType string
 
 const (
-	PackageSourceProviderTypeGitHub PackageSourceProviderType = "github"
-	PackageSourceProviderTypeHarbor PackageSourceProviderType = "harbor"
-	PackageSourceProviderTypeDocker PackageSourceProviderType = "docker"
-	PackageSourceProviderTypeHelm   PackageSourceProviderType = "helm"
+	PackageSourceProviderTypeGitHub  PackageSourceProviderType = "github"
+	PackageSourceProviderTypeHarbor  PackageSourceProviderType = "harbor"
+	PackageSourceProviderTypeDocker  PackageSourceProviderType = "docker"
+	PackageSourceProviderTypeHelm    PackageSourceProviderType = "helm"
+	PackageSourceProviderTypeOffline PackageSourceProviderType = "offline" // Versions read from a version feed, for air-gapped networks
 )
 
 type PackageSourceProviderAuthType string
@@ -58,14 +188,28 @@ const (
 	PackageSourceProviderAuthTypeToken PackageSourceProviderAuthType = "token"
 )
 
+// HelmAPIType selects a chart API that helm providers query instead of index.yaml
+type HelmAPIType string
+
+const (
+	HelmAPITypeChartMuseum HelmAPIType = "chartmuseum"
+	HelmAPITypeHarbor      HelmAPIType = "harbor"
+)
+
 type PackageSourceProvider struct {
-	Name     string                        `yaml:"name"`
-	Type     PackageSourceProviderType     `yaml:"type"`
-	BaseUrl  string                        `yaml:"baseUrl,omitempty"`
-	AuthType PackageSourceProviderAuthType `yaml:"authType,omitempty"`
-	Username string                        `yaml:"username,omitempty"`
-	Password string                        `yaml:"password,omitempty"`
-	Token    string                        `yaml:"token,omitempty"`
+	Name        string                        `yaml:"name"`
+	Type        PackageSourceProviderType     `yaml:"type"`
+	BaseUrl     string                        `yaml:"baseUrl,omitempty"`
+	AuthType    PackageSourceProviderAuthType `yaml:"authType,omitempty"`
+	Username    string                        `yaml:"username,omitempty"`
+	Password    string                        `yaml:"password,omitempty"`
+	Token       string                        `yaml:"token,omitempty"`
+	CertFile    string                        `yaml:"certFile,omitempty"`    // TLS client certificate (PEM) presented to helm repositories
+	KeyFile     string                        `yaml:"keyFile,omitempty"`     // Private key (PEM) of the TLS client certificate
+	HelmAPI     HelmAPIType                   `yaml:"helmApi,omitempty"`     // Chart API of helm repositories, falls back to index.yaml
+	GraphQL     bool                          `yaml:"graphql,omitempty"`     // Prefetch git-tag sources with batched GraphQL queries (github only)
+	BatchTokens bool                          `yaml:"batchTokens,omitempty"` // Exchange the registry tokens of docker-image sources in batched multi-scope requests (docker and harbor only)
+	Feed        string                        `yaml:"feed,omitempty"`        // Version feed file or directory of feed files (offline only)
 }
 
 type TargetType string
@@ -74,32 +218,122 @@ const (
 	TargetTypeTerraformVariable TargetType = "terraform-variable"
 	TargetTypeSubchart          TargetType = "subchart"
 	TargetTypeYamlField         TargetType = "yaml-field"
+	TargetTypeJsonnetField      TargetType = "jsonnet-field"
+	TargetTypeHelmfile          TargetType = "helmfile"
+	TargetTypeCargoToml         TargetType = "cargo-toml"
+	TargetTypeMavenPom          TargetType = "maven-pom"
+	TargetTypeGradleCatalog     TargetType = "gradle-catalog"
+	TargetTypeMakeVariable      TargetType = "make-variable"
+	TargetTypeToolVersions      TargetType = "tool-versions"
+	TargetTypeDevcontainer      TargetType = "devcontainer"
+	TargetTypeCIImage           TargetType = "ci-image"
 )
 
 type Target struct {
-	Name            string       `yaml:"name"`
-	Type            TargetType   `yaml:"type"`
-	File            string       `yaml:"file"`
-	Items           []TargetItem `yaml:"items"`
-	PatchGroup      string       `yaml:"patchGroup,omitempty"`
-	Labels          []string     `yaml:"labels,omitempty"`
-	WildcardPattern string       `yaml:"-"` // Original pattern if expanded from wildcard
-	IsWildcardMatch bool         `yaml:"-"` // Flag indicating this was expanded from wildcard
+	Name            string            `yaml:"name"`
+	Type            TargetType        `yaml:"type"`
+	File            string            `yaml:"file"`
+	Items           []TargetItem      `yaml:"items"`
+	PatchGroup      string            `yaml:"patchGroup,omitempty"`
+	Labels          []string          `yaml:"labels,omitempty"`
+	Reviewers       []string          `yaml:"reviewers,omitempty"`       // Users or "org/team" teams requested to review the PR
+	Assignees       []string          `yaml:"assignees,omitempty"`       // Users assigned to the PR
+	Milestone       string            `yaml:"milestone,omitempty"`       // Milestone title or number of the PR, overrides the targetActor's
+	Projects        []string          `yaml:"projects,omitempty"`        // Projects the PR is added to ("owner/number" or project URL)
+	ClosesIssues    []string          `yaml:"closesIssues,omitempty"`    // Issues the PR closes when merged ("123", "#123" or "owner/repo#123")
+	PostUpdate      []string          `yaml:"postUpdate,omitempty"`      // Shell commands run in the repository root after the target was updated, before commit
+	Stage           string            `yaml:"stage,omitempty"`           // Rollout stage of the target
+	ReadFrom        TargetReadFrom    `yaml:"readFrom,omitempty"`        // Where compare reads the current version: file (default) or cluster
+	Cluster         *ClusterReference `yaml:"cluster,omitempty"`         // Deployed resource read with readFrom cluster
+	ImageCheck      *ImageCheck       `yaml:"imageCheck,omitempty"`      // Verify new tags are pullable from the deployment registry before apply
+	Track           string            `yaml:"track,omitempty"`           // Source track all items follow unless overridden per item
+	MaxUpdateType   string            `yaml:"maxUpdateType,omitempty"`   // Largest update type to propose: major, minor, patch
+	VersionPrefix   string            `yaml:"versionPrefix,omitempty"`   // Prefix of versions stored in the file (e.g. "v")
+	VersionTemplate string            `yaml:"versionTemplate,omitempty"` // Format of versions stored in the file with {{version}} placeholder
+	ExtractPattern  string            `yaml:"extractPattern,omitempty"`  // Regex extracting the version from a compound value
+	WriteTemplate   string            `yaml:"writeTemplate,omitempty"`   // Template rebuilding the compound value from {{version}} and capture groups
+	VersionSet      string            `yaml:"versionSet,omitempty"`      // Version set whose items are always bumped together
+	SourcePattern   string            `yaml:"sourcePattern,omitempty"`   // Regex deriving the source of items without one from each wildcard match
+	ExcludeFiles    []string          `yaml:"excludeFiles,omitempty"`    // Glob patterns of wildcard matches to skip (e.g. "**/test/**")
+	FollowSymlinks  bool              `yaml:"followSymlinks,omitempty"`  // Descend into symlinked directories when expanding ** wildcards
+	Discover        *TargetDiscovery  `yaml:"discover,omitempty"`        // Find the items as the container images of the manifests under file
+	WildcardPattern string            `yaml:"-"`                         // Original pattern if expanded from wildcard
+	IsWildcardMatch bool              `yaml:"-"`                         // Flag indicating this was expanded from wildcard
+}
+
+// TargetDiscovery finds the items of a yaml-field target as the image fields of all manifests
+// under its file, instead of listing them
+type TargetDiscovery struct {
+	Provider      string   `yaml:"provider,omitempty"`      // Docker provider of the sources created for images without a configured source
+	ExcludeImages []string `yaml:"excludeImages,omitempty"` // Regex patterns of images to leave alone
 }
 
 type TargetItem struct {
-	Name                  string   `yaml:"name,omitempty"`
-	TerraformVariableName string   `yaml:"terraformVariableName,omitempty"`
-	SubchartName          string   `yaml:"subchartName,omitempty"`
-	YamlPath              string   `yaml:"yamlPath,omitempty"`
-	Source                string   `yaml:"source"`
-	PatchGroup            string   `yaml:"patchGroup,omitempty"`
-	Labels                []string `yaml:"labels,omitempty"`
+	Name                  string            `yaml:"name,omitempty"`
+	TerraformVariableName string            `yaml:"terraformVariableName,omitempty"`
+	SubchartName          string            `yaml:"subchartName,omitempty"`
+	YamlPath              string            `yaml:"yamlPath,omitempty"`
+	Document              *int              `yaml:"document,omitempty"`        // Zero-based document of a multi-document file yamlPath resolves in, the first match if unset
+	JsonnetPath           string            `yaml:"jsonnetPath,omitempty"`     // Dotted path of a string field or local variable in a Jsonnet file
+	ReleaseName           string            `yaml:"releaseName,omitempty"`     // Helmfile release as "name" or "namespace/name"
+	ValuePath             string            `yaml:"valuePath,omitempty"`       // Value overridden by the helmfile release instead of its chart version
+	CrateName             string            `yaml:"crateName,omitempty"`       // Dependency key of a crate in Cargo.toml
+	DependencyTable       string            `yaml:"dependencyTable,omitempty"` // Restrict the crate to one dependency table, e.g. "workspace.dependencies"
+	Artifact              string            `yaml:"artifact,omitempty"`        // Maven dependency or plugin as "groupId:artifactId"
+	Property              string            `yaml:"property,omitempty"`        // Maven project property holding a version
+	CatalogEntry          string            `yaml:"catalogEntry,omitempty"`    // Gradle version catalog entry as "<table>.<alias>", e.g. "libraries.okhttp"
+	VariableName          string            `yaml:"variableName,omitempty"`    // Variable assigned in a Makefile or shell script, e.g. "KUBECTL_VERSION"
+	ToolName              string            `yaml:"toolName,omitempty"`        // Tool in a .tool-versions or mise.toml file, e.g. "nodejs"
+	Image                 string            `yaml:"image,omitempty"`           // Image repository without tag whose references are updated, e.g. "golang" or "mcr.microsoft.com/devcontainers/go"
+	Source                string            `yaml:"source"`
+	PatchGroup            string            `yaml:"patchGroup,omitempty"`
+	Labels                []string          `yaml:"labels,omitempty"`
+	Track                 string            `yaml:"track,omitempty"`           // Source track to follow instead of the overall latest version
+	MaxUpdateType         string            `yaml:"maxUpdateType,omitempty"`   // Override the target's maxUpdateType
+	VersionPrefix         string            `yaml:"versionPrefix,omitempty"`   // Override the target's versionPrefix
+	VersionTemplate       string            `yaml:"versionTemplate,omitempty"` // Override the target's versionTemplate
+	ExtractPattern        string            `yaml:"extractPattern,omitempty"`  // Override the target's extractPattern
+	WriteTemplate         string            `yaml:"writeTemplate,omitempty"`   // Override the target's writeTemplate
+	VersionSet            string            `yaml:"versionSet,omitempty"`      // Override the target's versionSet
+	Cluster               *ClusterReference `yaml:"cluster,omitempty"`         // Override the target's cluster resource
+	ImageCheck            *ImageCheck       `yaml:"imageCheck,omitempty"`      // Override the target's image check
+	DigestPin             *DigestPin        `yaml:"digestPin,omitempty"`       // yamlPath holds a digest pinned next to a tag comment or field
 }
 
 type TargetActor struct {
-	Name     string `yaml:"name"`
-	Email    string `yaml:"email"`
-	Username string `yaml:"username"`
-	Token    string `yaml:"token,omitempty"`
+	Name                string               `yaml:"name"`
+	Email               string               `yaml:"email"`
+	Username            string               `yaml:"username"`
+	Token               string               `yaml:"token,omitempty"`
+	CodeOwnerReviews    bool                 `yaml:"codeOwnerReviews,omitempty"`    // Request reviews from the CODEOWNERS of the changed files
+	Milestone           string               `yaml:"milestone,omitempty"`           // Milestone title or number of all PRs
+	Projects            []string             `yaml:"projects,omitempty"`            // Projects all PRs are added to ("owner/number" or project URL)
+	BranchTemplate      string               `yaml:"branchTemplate,omitempty"`      // Go template of update branch names, defaults to "chore/update/{{ .PatchGroup }}"
+	BranchMaxLength     int                  `yaml:"branchMaxLength,omitempty"`     // Truncate update branch names to this length, unlimited if 0
+	CommitStrategy      CommitStrategy       `yaml:"commitStrategy,omitempty"`      // How updates of a patch group are split into commits, defaults to per-file
+	ConventionalCommits *ConventionalCommits `yaml:"conventionalCommits,omitempty"` // Infer Conventional Commits types of commit messages and PR titles
+	Changelog           *Changelog           `yaml:"changelog,omitempty"`           // Append an entry to a changelog file of the target repository with every commit
+}
+
+// CommitStrategy determines how the updates of a patch group are split into commits. Files
+// linked by a version set are always committed together.
+type CommitStrategy string
+
+const (
+	CommitStrategyPerFile CommitStrategy = "per-file" // One commit per changed file
+	CommitStrategyPerItem CommitStrategy = "per-item" // One commit per updated item
+	CommitStrategySingle  CommitStrategy = "single"   // One commit for the whole patch group
+)
+
+// FindStage returns the stage with the given name, nil if not found or without rollout
+func (r *Rollout) FindStage(name string) *RolloutStage {
+	if r == nil {
+		return nil
+	}
+	for _, stage := range r.Stages {
+		if stage.Name == name {
+			return stage
+		}
+	}
+	return nil
 }
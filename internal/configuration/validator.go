@@ -3,6 +3,8 @@ package configuration
 import (
 	"fmt"
 	"strings"
+
+	"github.com/mxcd/updater/internal/util"
 )
 
 // ValidationError represents a configuration validation error
@@ -30,6 +32,16 @@ func (r *ValidationResult) AddError(field, message string) {
 	})
 }
 
+// versionSetMember records the source, patch group and update policy of the first item of a
+// version set
+type versionSetMember struct {
+	source        string
+	patchGroup    string
+	track         string
+	maxUpdateType string
+	stage         string
+}
+
 // ValidateConfiguration performs validation on the configuration
 func ValidateConfiguration(config *Config) *ValidationResult {
 	result := &ValidationResult{
@@ -77,10 +89,55 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 				result.AddError(fmt.Sprintf("%s.token", fieldPrefix), "token is required for token auth")
 			}
 		}
+
+		// Validate helm chart API
+		if provider.HelmAPI != "" {
+			if provider.Type != PackageSourceProviderTypeHelm {
+				result.AddError(fmt.Sprintf("%s.helmApi", fieldPrefix), "helmApi is only supported for helm providers")
+			} else if provider.HelmAPI != HelmAPITypeChartMuseum && provider.HelmAPI != HelmAPITypeHarbor {
+				result.AddError(fmt.Sprintf("%s.helmApi", fieldPrefix), fmt.Sprintf("invalid helm API: %s (expected chartmuseum or harbor)", provider.HelmAPI))
+			}
+		}
+
+		// GraphQL batching is a GitHub API and requires a token
+		if provider.GraphQL {
+			if provider.Type != PackageSourceProviderTypeGitHub {
+				result.AddError(fmt.Sprintf("%s.graphql", fieldPrefix), "graphql is only supported for github providers")
+			} else if provider.AuthType != PackageSourceProviderAuthTypeToken {
+				result.AddError(fmt.Sprintf("%s.graphql", fieldPrefix), "graphql requires authType token")
+			}
+		}
+
+		// Batched token exchange is part of the registry token authentication
+		if provider.BatchTokens && provider.Type != PackageSourceProviderTypeDocker && provider.Type != PackageSourceProviderTypeHarbor {
+			result.AddError(fmt.Sprintf("%s.batchTokens", fieldPrefix), "batchTokens is only supported for docker and harbor providers")
+		}
+
+		// Offline providers read their versions from a feed
+		if provider.Type == PackageSourceProviderTypeOffline && strings.TrimSpace(provider.Feed) == "" {
+			result.AddError(fmt.Sprintf("%s.feed", fieldPrefix), "feed is required for offline providers")
+		}
+		if provider.Feed != "" && provider.Type != PackageSourceProviderTypeOffline {
+			result.AddError(fmt.Sprintf("%s.feed", fieldPrefix), "feed is only supported for offline providers")
+		}
+
+		// Validate TLS client certificate
+		if provider.CertFile != "" || provider.KeyFile != "" {
+			if provider.Type != PackageSourceProviderTypeHelm {
+				result.AddError(fmt.Sprintf("%s.certFile", fieldPrefix), "TLS client certificates are only supported for helm providers")
+			}
+			if strings.TrimSpace(provider.CertFile) == "" {
+				result.AddError(fmt.Sprintf("%s.certFile", fieldPrefix), "certFile is required when keyFile is set")
+			}
+			if strings.TrimSpace(provider.KeyFile) == "" {
+				result.AddError(fmt.Sprintf("%s.keyFile", fieldPrefix), "keyFile is required when certFile is set")
+			}
+		}
 	}
 
 	// Validate package sources
 	sourceNames := make(map[string]bool)
+	sourceByName := make(map[string]*PackageSource)
 	providerByName := make(map[string]*PackageSourceProvider)
 	for _, provider := range config.PackageSourceProviders {
 		providerByName[provider.Name] = provider
@@ -97,11 +154,16 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 				result.AddError(fmt.Sprintf("%s.name", fieldPrefix), fmt.Sprintf("duplicate source name: %s", source.Name))
 			}
 			sourceNames[source.Name] = true
+			sourceByName[source.Name] = source
 		}
 
-		// Validate provider reference
+		// Validate provider reference, static sources have none
 		var provider *PackageSourceProvider
-		if strings.TrimSpace(source.Provider) == "" {
+		if source.Type == PackageSourceTypeStatic {
+			if source.Provider != "" {
+				result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), "static sources do not use a provider")
+			}
+		} else if strings.TrimSpace(source.Provider) == "" {
 			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), "provider reference cannot be empty")
 		} else if !providerNames[source.Provider] {
 			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' not found in packageSourceProviders", source.Provider))
@@ -122,23 +184,138 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 		}
 
 		// Validate URI (not required for helm-repository as it uses provider's baseUrl)
-		if source.Type != PackageSourceTypeHelmRepository && strings.TrimSpace(source.URI) == "" {
+		if source.Type != PackageSourceTypeHelmRepository && source.Type != PackageSourceTypeHelmRepoAll && source.Type != PackageSourceTypeStatic && strings.TrimSpace(source.URI) == "" {
 			result.AddError(fmt.Sprintf("%s.uri", fieldPrefix), "URI cannot be empty")
 		}
 
+		// Static sources list their versions inline
+		if source.Type == PackageSourceTypeStatic && len(source.StaticVersions) == 0 {
+			result.AddError(fmt.Sprintf("%s.staticVersions", fieldPrefix), "staticVersions is required for static sources")
+		}
+		if source.Type != PackageSourceTypeStatic && len(source.StaticVersions) > 0 {
+			result.AddError(fmt.Sprintf("%s.staticVersions", fieldPrefix), "staticVersions is only supported for static sources")
+		}
+		for j, version := range source.StaticVersions {
+			if strings.TrimSpace(version) == "" {
+				result.AddError(fmt.Sprintf("%s.staticVersions[%d]", fieldPrefix, j), "version cannot be empty")
+			}
+		}
+
+		// Repository patterns select the repositories of a namespace
+		if source.RepositoryPattern != "" {
+			if source.Type != PackageSourceTypeDockerNamespace {
+				result.AddError(fmt.Sprintf("%s.repositoryPattern", fieldPrefix), "repositoryPattern is only supported for docker-namespace sources")
+			} else if _, err := CompilePattern(source.RepositoryPattern); err != nil {
+				result.AddError(fmt.Sprintf("%s.repositoryPattern", fieldPrefix), fmt.Sprintf("invalid repositoryPattern: %v", err))
+			}
+		}
+
+		if source.ChartPattern != "" {
+			if source.Type != PackageSourceTypeHelmRepoAll {
+				result.AddError(fmt.Sprintf("%s.chartPattern", fieldPrefix), "chartPattern is only supported for helm-repo-all sources")
+			} else if _, err := CompilePattern(source.ChartPattern); err != nil {
+				result.AddError(fmt.Sprintf("%s.chartPattern", fieldPrefix), fmt.Sprintf("invalid chartPattern: %v", err))
+			}
+		}
+
 		// Validate helm-repository specific fields
+		if source.Type == PackageSourceTypeHelmRepoAll && provider != nil && provider.Type != PackageSourceProviderTypeOffline && strings.TrimSpace(provider.BaseUrl) == "" {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' must have baseUrl configured for helm-repo-all source type", source.Provider))
+		}
 		if source.Type == PackageSourceTypeHelmRepository {
 			if strings.TrimSpace(source.ChartName) == "" {
 				result.AddError(fmt.Sprintf("%s.chartName", fieldPrefix), "chartName is required for helm-repository source type")
 			}
 			// Validate that the provider has baseUrl configured
-			if provider != nil && strings.TrimSpace(provider.BaseUrl) == "" {
+			if provider != nil && provider.Type != PackageSourceProviderTypeOffline && strings.TrimSpace(provider.BaseUrl) == "" {
 				result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' must have baseUrl configured for helm-repository source type", source.Provider))
 			}
 		}
+
+		validateVersionFormat(result, fieldPrefix, source.VersionTemplate, source.VersionPrefix, source.ExtractPattern, "")
+
+		// Patterns are compiled once here and matched against every scraped tag later
+		if source.TagPattern != "" {
+			if _, err := CompilePattern(source.TagPattern); err != nil {
+				result.AddError(fmt.Sprintf("%s.tagPattern", fieldPrefix), fmt.Sprintf("invalid tagPattern: %v", err))
+			}
+		}
+		if source.ExcludePattern != "" {
+			if _, err := CompilePattern(source.ExcludePattern); err != nil {
+				result.AddError(fmt.Sprintf("%s.excludePattern", fieldPrefix), fmt.Sprintf("invalid excludePattern: %v", err))
+			}
+		}
+
+		if source.Limit < 0 {
+			result.AddError(fmt.Sprintf("%s.limit", fieldPrefix), "limit cannot be negative")
+		}
+
+		if source.VersionConstraint != "" {
+			if _, err := ParseVersionConstraint(source.VersionConstraint); err != nil {
+				result.AddError(fmt.Sprintf("%s.versionConstraint", fieldPrefix), err.Error())
+			}
+		}
+
+		if source.VersionScheme != "" && source.VersionScheme != VersionSchemeSemantic && source.VersionScheme != VersionSchemeLoose {
+			result.AddError(fmt.Sprintf("%s.versionScheme", fieldPrefix), fmt.Sprintf("invalid version scheme '%s', must be '%s' or '%s'", source.VersionScheme, VersionSchemeSemantic, VersionSchemeLoose))
+		}
+
+		// Incremental scraping relies on paginated feeds sorted newest first
+		if source.Incremental && source.Type != PackageSourceTypeGitTag && source.Type != PackageSourceTypeDockerImage && source.Type != PackageSourceTypeDockerNamespace {
+			result.AddError(fmt.Sprintf("%s.incremental", fieldPrefix), fmt.Sprintf("incremental scraping is not supported for source type %s", source.Type))
+		}
+		if source.Incremental && source.VersionScheme == VersionSchemeLoose {
+			result.AddError(fmt.Sprintf("%s.incremental", fieldPrefix), "incremental scraping requires the semantic version scheme")
+		}
+
+		if source.StaleAfter != "" {
+			if _, err := util.ParseDuration(source.StaleAfter); err != nil {
+				result.AddError(fmt.Sprintf("%s.staleAfter", fieldPrefix), err.Error())
+			}
+		}
+
+		// Chart history reads Chart.yaml from git tags
+		if source.ChartHistory && source.Type != PackageSourceTypeGitHelmChart {
+			result.AddError(fmt.Sprintf("%s.chartHistory", fieldPrefix), "chartHistory is only supported for git-helm-chart sources")
+		}
+
+		// Release filters apply to the GitHub releases list
+		if (source.ExcludeDrafts || source.ExcludePrereleases || source.RequireAsset != "") && source.Type != PackageSourceTypeGitRelease {
+			result.AddError(fmt.Sprintf("%s.type", fieldPrefix), "excludeDrafts, excludePrereleases and requireAsset are only supported for git-release sources")
+		}
+		if source.RequireAsset != "" {
+			if _, err := CompilePattern(source.RequireAsset); err != nil {
+				result.AddError(fmt.Sprintf("%s.requireAsset", fieldPrefix), fmt.Sprintf("invalid regex: %v", err))
+			}
+		}
+
+		// Validate verification policy
+		if source.Verification != nil {
+			validateVerification(result, fmt.Sprintf("%s.verification", fieldPrefix), source)
+		}
+
+		// Validate tracks
+		trackNames := make(map[string]bool)
+		for j, track := range source.Tracks {
+			trackPrefix := fmt.Sprintf("%s.tracks[%d]", fieldPrefix, j)
+			if strings.TrimSpace(track.Name) == "" {
+				result.AddError(fmt.Sprintf("%s.name", trackPrefix), "track name cannot be empty")
+			} else {
+				if trackNames[track.Name] {
+					result.AddError(fmt.Sprintf("%s.name", trackPrefix), fmt.Sprintf("duplicate track name: %s", track.Name))
+				}
+				trackNames[track.Name] = true
+			}
+			if strings.TrimSpace(track.TagPattern) == "" {
+				result.AddError(fmt.Sprintf("%s.tagPattern", trackPrefix), "tagPattern is required for a track")
+			} else if _, err := CompilePattern(track.TagPattern); err != nil {
+				result.AddError(fmt.Sprintf("%s.tagPattern", trackPrefix), fmt.Sprintf("invalid tagPattern: %v", err))
+			}
+		}
 	}
 
 	// Validate targets
+	versionSets := make(map[string]*versionSetMember)
 	for i, target := range config.Targets {
 		fieldPrefix := fmt.Sprintf("targets[%d]", i)
 
@@ -157,6 +334,46 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 			result.AddError(fmt.Sprintf("%s.file", fieldPrefix), "file path cannot be empty")
 		}
 
+		// Validate update policy
+		if target.MaxUpdateType != "" && !isValidUpdateType(target.MaxUpdateType) {
+			result.AddError(fmt.Sprintf("%s.maxUpdateType", fieldPrefix), fmt.Sprintf("invalid maxUpdateType: %s (must be major, minor, or patch)", target.MaxUpdateType))
+		}
+
+		validateVersionFormat(result, fieldPrefix, target.VersionTemplate, target.VersionPrefix, target.ExtractPattern, target.WriteTemplate)
+
+		// Validate wildcard options, only wildcard targets have matched paths
+		if target.SourcePattern != "" && !target.IsWildcardMatch {
+			result.AddError(fmt.Sprintf("%s.sourcePattern", fieldPrefix), "sourcePattern requires a wildcard file pattern")
+		}
+		if len(target.ExcludeFiles) > 0 && !target.IsWildcardMatch {
+			result.AddError(fmt.Sprintf("%s.excludeFiles", fieldPrefix), "excludeFiles requires a wildcard file pattern")
+		}
+
+		validatePullRequestLinks(result, fieldPrefix, target.Projects, target.ClosesIssues)
+
+		if target.Stage != "" && config.Rollout.FindStage(target.Stage) == nil {
+			result.AddError(fmt.Sprintf("%s.stage", fieldPrefix), fmt.Sprintf("stage '%s' not defined in rollout", target.Stage))
+		}
+
+		for j, command := range target.PostUpdate {
+			if strings.TrimSpace(command) == "" {
+				result.AddError(fmt.Sprintf("%s.postUpdate[%d]", fieldPrefix, j), "postUpdate command cannot be empty")
+			}
+		}
+
+		// Validate where the current version is read from
+		switch target.ReadFrom {
+		case "", TargetReadFromFile, TargetReadFromCluster:
+		default:
+			result.AddError(fmt.Sprintf("%s.readFrom", fieldPrefix), fmt.Sprintf("invalid readFrom: %s (must be file or cluster)", target.ReadFrom))
+		}
+		if target.Cluster != nil {
+			validateClusterReference(result, fmt.Sprintf("%s.cluster", fieldPrefix), target.ReadFrom, target.Cluster)
+		}
+		if target.ImageCheck != nil {
+			validateImageCheck(result, fmt.Sprintf("%s.imageCheck", fieldPrefix), target.ImageCheck, providerByName)
+		}
+
 		// Validate updateItems
 		if len(target.Items) == 0 {
 			result.AddError(fmt.Sprintf("%s.updateItems", fieldPrefix), "at least one updateItem is required")
@@ -168,10 +385,115 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 			// Validate source reference
 			if strings.TrimSpace(item.Source) == "" {
 				result.AddError(fmt.Sprintf("%s.source", itemPrefix), "source reference cannot be empty")
-			} else if !sourceNames[item.Source] {
+			} else if source := sourceByName[item.Source]; source != nil && source.Discovers() {
+				result.AddError(fmt.Sprintf("%s.source", itemPrefix), fmt.Sprintf("%s source '%s' must be referenced as '%s/<%s>'", source.Type, item.Source, item.Source, discoveredPackage(source.Type)))
+			} else if !sourceNames[item.Source] && DiscoverySource(config.PackageSources, item.Source) == nil {
 				result.AddError(fmt.Sprintf("%s.source", itemPrefix), fmt.Sprintf("source '%s' not found in packageSources", item.Source))
 			}
 
+			if item.MaxUpdateType != "" && !isValidUpdateType(item.MaxUpdateType) {
+				result.AddError(fmt.Sprintf("%s.maxUpdateType", itemPrefix), fmt.Sprintf("invalid maxUpdateType: %s (must be major, minor, or patch)", item.MaxUpdateType))
+			}
+
+			validateVersionFormat(result, itemPrefix, item.VersionTemplate, item.VersionPrefix, item.ExtractPattern, item.WriteTemplate)
+
+			// Only docker sources have an image to render into the written value
+			writeTemplate := item.WriteTemplate
+			if item.VersionTemplate == "" && item.VersionPrefix == "" && item.ExtractPattern == "" {
+				writeTemplate = target.WriteTemplate
+			}
+			if source := sourceByName[item.Source]; source != nil && source.Type != PackageSourceTypeDockerImage && strings.Contains(writeTemplate, ImagePlaceholder) {
+				result.AddError(fmt.Sprintf("%s.writeTemplate", itemPrefix), fmt.Sprintf("%s requires a docker-image source", ImagePlaceholder))
+			}
+
+			// Validate version set membership (item version set overrides target version set)
+			versionSet := item.VersionSet
+			if versionSet == "" {
+				versionSet = target.VersionSet
+			}
+			if versionSet != "" {
+				member := &versionSetMember{
+					source:        item.Source,
+					patchGroup:    item.PatchGroup,
+					track:         item.Track,
+					maxUpdateType: item.MaxUpdateType,
+					stage:         target.Stage,
+				}
+				if member.patchGroup == "" {
+					member.patchGroup = target.PatchGroup
+				}
+				if member.track == "" {
+					member.track = target.Track
+				}
+				if member.maxUpdateType == "" {
+					member.maxUpdateType = target.MaxUpdateType
+				}
+				if first, exists := versionSets[versionSet]; !exists {
+					versionSets[versionSet] = member
+				} else {
+					if first.source != item.Source {
+						result.AddError(fmt.Sprintf("%s.versionSet", itemPrefix), fmt.Sprintf("all items of version set '%s' must use the same source (expected '%s', got '%s')", versionSet, first.source, item.Source))
+					}
+					if first.patchGroup != member.patchGroup {
+						result.AddError(fmt.Sprintf("%s.versionSet", itemPrefix), fmt.Sprintf("all items of version set '%s' must be in the same patch group (expected '%s', got '%s')", versionSet, first.patchGroup, member.patchGroup))
+					}
+					if first.track != member.track {
+						result.AddError(fmt.Sprintf("%s.versionSet", itemPrefix), fmt.Sprintf("all items of version set '%s' must follow the same track (expected '%s', got '%s')", versionSet, first.track, member.track))
+					}
+					if first.maxUpdateType != member.maxUpdateType {
+						result.AddError(fmt.Sprintf("%s.versionSet", itemPrefix), fmt.Sprintf("all items of version set '%s' must have the same maxUpdateType (expected '%s', got '%s')", versionSet, first.maxUpdateType, member.maxUpdateType))
+					}
+					if first.stage != member.stage {
+						result.AddError(fmt.Sprintf("%s.versionSet", itemPrefix), fmt.Sprintf("all items of version set '%s' must be in the same rollout stage (expected '%s', got '%s')", versionSet, first.stage, member.stage))
+					}
+				}
+			}
+
+			// Validate the cluster resource of items read from the cluster
+			if item.Cluster != nil {
+				validateClusterReference(result, fmt.Sprintf("%s.cluster", itemPrefix), target.ReadFrom, item.Cluster)
+			}
+			if target.ReadFrom == TargetReadFromCluster && ResolveClusterReference(target, &item) == nil {
+				result.AddError(fmt.Sprintf("%s.cluster", itemPrefix), "cluster resource is required with readFrom cluster")
+			}
+
+			// Digest pins resolve the digest of the new tag from the source registry
+			if item.DigestPin != nil {
+				if target.Type != TargetTypeYamlField {
+					result.AddError(fmt.Sprintf("%s.digestPin", itemPrefix), "digestPin is only supported for yaml-field targets")
+				}
+				if source := sourceByName[item.Source]; source != nil && source.Type != PackageSourceTypeDockerImage {
+					result.AddError(fmt.Sprintf("%s.digestPin", itemPrefix), fmt.Sprintf("digestPin requires a docker-image source, '%s' is of type %s", item.Source, source.Type))
+				}
+				if strings.ContainsAny(item.DigestPin.TagField, ". ") {
+					result.AddError(fmt.Sprintf("%s.digestPin.tagField", itemPrefix), "tagField must be the key of a field next to the digest")
+				}
+			}
+
+			// Validate the image check, which defaults to the image of docker sources
+			if item.ImageCheck != nil {
+				validateImageCheck(result, fmt.Sprintf("%s.imageCheck", itemPrefix), item.ImageCheck, providerByName)
+			}
+			if check := ResolveImageCheck(target, &item); check != nil && check.Image == "" {
+				if source := sourceByName[item.Source]; source != nil && source.Type != PackageSourceTypeDockerImage {
+					result.AddError(fmt.Sprintf("%s.imageCheck.image", itemPrefix), fmt.Sprintf("image is required for source '%s' of type %s", item.Source, source.Type))
+				}
+			}
+
+			// Validate track reference (item track overrides target track)
+			track := item.Track
+			if track == "" {
+				track = target.Track
+			}
+			source := sourceByName[item.Source]
+			if source == nil {
+				// Discovered sources have the tracks of their discovery source
+				source = DiscoverySource(config.PackageSources, item.Source)
+			}
+			if track != "" && source != nil && source.FindTrack(track) == nil {
+				result.AddError(fmt.Sprintf("%s.track", itemPrefix), fmt.Sprintf("track '%s' not defined on source '%s'", track, item.Source))
+			}
+
 			// Type-specific validation
 			switch target.Type {
 			case TargetTypeTerraformVariable:
@@ -186,6 +508,115 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 				if strings.TrimSpace(item.YamlPath) == "" {
 					result.AddError(fmt.Sprintf("%s.yamlPath", itemPrefix), "yamlPath is required for yaml-field target")
 				}
+			case TargetTypeJsonnetField:
+				if strings.TrimSpace(item.JsonnetPath) == "" {
+					result.AddError(fmt.Sprintf("%s.jsonnetPath", itemPrefix), "jsonnetPath is required for jsonnet-field target")
+				}
+			case TargetTypeHelmfile:
+				if strings.TrimSpace(item.ReleaseName) == "" {
+					result.AddError(fmt.Sprintf("%s.releaseName", itemPrefix), "releaseName is required for helmfile target")
+				}
+			case TargetTypeCargoToml:
+				if strings.TrimSpace(item.CrateName) == "" {
+					result.AddError(fmt.Sprintf("%s.crateName", itemPrefix), "crateName is required for cargo-toml target")
+				}
+			case TargetTypeMavenPom:
+				if (strings.TrimSpace(item.Artifact) == "") == (strings.TrimSpace(item.Property) == "") {
+					result.AddError(itemPrefix, "either artifact or property is required for maven-pom target")
+				} else if item.Artifact != "" && strings.Count(item.Artifact, ":") != 1 {
+					result.AddError(fmt.Sprintf("%s.artifact", itemPrefix), "artifact must be given as groupId:artifactId")
+				}
+			case TargetTypeGradleCatalog:
+				table, alias, _ := strings.Cut(item.CatalogEntry, ".")
+				if alias == "" || (table != "versions" && table != "libraries" && table != "plugins") {
+					result.AddError(fmt.Sprintf("%s.catalogEntry", itemPrefix), "catalogEntry is required for gradle-catalog target as versions.<alias>, libraries.<alias> or plugins.<alias>")
+				}
+			case TargetTypeMakeVariable:
+				if strings.TrimSpace(item.VariableName) == "" {
+					result.AddError(fmt.Sprintf("%s.variableName", itemPrefix), "variableName is required for make-variable target")
+				}
+			case TargetTypeToolVersions:
+				if strings.TrimSpace(item.ToolName) == "" {
+					result.AddError(fmt.Sprintf("%s.toolName", itemPrefix), "toolName is required for tool-versions target")
+				}
+			case TargetTypeDevcontainer, TargetTypeCIImage:
+				if strings.TrimSpace(item.Image) == "" {
+					result.AddError(fmt.Sprintf("%s.image", itemPrefix), fmt.Sprintf("image is required for %s target", target.Type))
+				} else if name := item.Image[strings.LastIndex(item.Image, "/")+1:]; strings.ContainsAny(name, ":@") {
+					result.AddError(fmt.Sprintf("%s.image", itemPrefix), "image must be given without tag or digest")
+				}
+			}
+		}
+	}
+
+	// Validate argocd-image-updater ingestion
+	for i, ingest := range config.ArgoCDImageUpdater {
+		fieldPrefix := fmt.Sprintf("argocdImageUpdater[%d]", i)
+
+		if len(ingest.Files) == 0 {
+			result.AddError(fmt.Sprintf("%s.files", fieldPrefix), "at least one application file is required")
+		}
+
+		if strings.TrimSpace(ingest.Provider) == "" {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), "provider reference cannot be empty")
+		} else if provider, ok := providerByName[ingest.Provider]; !ok {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' not found in packageSourceProviders", ingest.Provider))
+		} else if err := validateSourceProviderCombination(PackageSourceTypeDockerImage, provider.Type); err != nil {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), err.Error())
+		}
+	}
+
+	// Validate rollout stages
+	if config.Rollout != nil {
+		if len(config.Rollout.Stages) < 2 {
+			result.AddError("rollout.stages", "a rollout needs at least two stages")
+		}
+		stageNames := make(map[string]bool)
+		for i, stage := range config.Rollout.Stages {
+			fieldPrefix := fmt.Sprintf("rollout.stages[%d]", i)
+			if strings.TrimSpace(stage.Name) == "" {
+				result.AddError(fmt.Sprintf("%s.name", fieldPrefix), "stage name cannot be empty")
+			} else if stageNames[stage.Name] {
+				result.AddError(fmt.Sprintf("%s.name", fieldPrefix), fmt.Sprintf("duplicate stage name: %s", stage.Name))
+			}
+			stageNames[stage.Name] = true
+
+			if stage.SoakTime != "" {
+				if _, err := util.ParseDuration(stage.SoakTime); err != nil {
+					result.AddError(fmt.Sprintf("%s.soakTime", fieldPrefix), err.Error())
+				}
+			}
+			if i == 0 && (stage.SoakTime != "" || stage.ApprovalLabel != "") {
+				result.AddError(fieldPrefix, "the first stage has no previous stage to soak in or be approved by")
+			}
+		}
+	}
+
+	// Validate patch group settings
+	for name, patchGroup := range config.PatchGroups {
+		fieldPrefix := fmt.Sprintf("patchGroups.%s", name)
+		if patchGroup == nil {
+			continue
+		}
+		if patchGroup.Schedule != "" {
+			if _, err := ParseSchedule(patchGroup.Schedule); err != nil {
+				result.AddError(fmt.Sprintf("%s.schedule", fieldPrefix), err.Error())
+			}
+		}
+		for j, command := range patchGroup.PostUpdate {
+			if strings.TrimSpace(command) == "" {
+				result.AddError(fmt.Sprintf("%s.postUpdate[%d]", fieldPrefix, j), "postUpdate command cannot be empty")
+			}
+		}
+	}
+
+	// Validate registry mappings, both sides are image name prefixes without scheme
+	for prefix, mirror := range config.RegistryMap {
+		fieldPrefix := fmt.Sprintf("registryMap.%s", prefix)
+		for _, value := range []string{prefix, mirror} {
+			if strings.TrimSpace(value) == "" || strings.Contains(value, "://") || strings.HasSuffix(value, "/") {
+				result.AddError(fieldPrefix, fmt.Sprintf("invalid registry mapping %q -> %q (expected registry[/path] without scheme or trailing slash)", prefix, mirror))
+				break
 			}
 		}
 	}
@@ -210,18 +641,57 @@ func ValidateConfiguration(config *Config) *ValidationResult {
 		}
 
 		// Token is optional, so no validation needed
+
+		validatePullRequestLinks(result, fieldPrefix, config.TargetActor.Projects, nil)
+
+		// Validate branch naming by rendering the template with sample values
+		sample := &BranchNameData{PatchGroup: "default", Source: "source", Version: "1.0.0", Date: "2006-01-02"}
+		if _, err := RenderBranchName(config.TargetActor.BranchTemplate, sample, config.TargetActor.BranchMaxLength); err != nil {
+			result.AddError(fmt.Sprintf("%s.branchTemplate", fieldPrefix), err.Error())
+		}
+		if config.TargetActor.BranchMaxLength < 0 {
+			result.AddError(fmt.Sprintf("%s.branchMaxLength", fieldPrefix), "branchMaxLength cannot be negative")
+		}
+
+		// Validate commit strategy
+		if config.TargetActor.CommitStrategy != "" && !isValidCommitStrategy(config.TargetActor.CommitStrategy) {
+			result.AddError(fmt.Sprintf("%s.commitStrategy", fieldPrefix), fmt.Sprintf("invalid commit strategy: %s (must be one of: per-file, per-item, single)", config.TargetActor.CommitStrategy))
+		}
+
+		if config.TargetActor.ConventionalCommits != nil {
+			validateConventionalCommits(result, fmt.Sprintf("%s.conventionalCommits", fieldPrefix), config.TargetActor.ConventionalCommits)
+		}
+
+		if config.TargetActor.Changelog != nil {
+			validateChangelog(result, fmt.Sprintf("%s.changelog", fieldPrefix), config.TargetActor.Changelog)
+		}
 	}
 
 	return result
 }
 
+// validatePullRequestLinks checks the project and issue references pull requests are linked to
+func validatePullRequestLinks(result *ValidationResult, fieldPrefix string, projects []string, issues []string) {
+	for i, project := range projects {
+		if _, _, err := ParseProjectReference(project); err != nil {
+			result.AddError(fmt.Sprintf("%s.projects[%d]", fieldPrefix, i), err.Error())
+		}
+	}
+	for i, issue := range issues {
+		if _, err := FormatIssueReference(issue); err != nil {
+			result.AddError(fmt.Sprintf("%s.closesIssues[%d]", fieldPrefix, i), err.Error())
+		}
+	}
+}
+
 // isValidProviderType checks if the provider type is valid
 func isValidProviderType(providerType PackageSourceProviderType) bool {
 	switch providerType {
 	case PackageSourceProviderTypeGitHub,
 		PackageSourceProviderTypeHarbor,
 		PackageSourceProviderTypeDocker,
-		PackageSourceProviderTypeHelm:
+		PackageSourceProviderTypeHelm,
+		PackageSourceProviderTypeOffline:
 		return true
 	default:
 		return false
@@ -240,6 +710,18 @@ func isValidAuthType(authType PackageSourceProviderAuthType) bool {
 	}
 }
 
+// isValidCommitStrategy checks if the commit strategy is valid
+func isValidCommitStrategy(strategy CommitStrategy) bool {
+	switch strategy {
+	case CommitStrategyPerFile,
+		CommitStrategyPerItem,
+		CommitStrategySingle:
+		return true
+	default:
+		return false
+	}
+}
+
 // isValidSourceType checks if the source type is valid
 func isValidSourceType(sourceType PackageSourceType) bool {
 	switch sourceType {
@@ -247,25 +729,32 @@ func isValidSourceType(sourceType PackageSourceType) bool {
 		PackageSourceTypeGitTag,
 		PackageSourceTypeGitHelmChart,
 		PackageSourceTypeDockerImage,
-		PackageSourceTypeHelmRepository:
+		PackageSourceTypeHelmRepository,
+		PackageSourceTypeStatic,
+		PackageSourceTypeDockerNamespace,
+		PackageSourceTypeHelmRepoAll:
 		return true
 	default:
 		return false
 	}
 }
 
-// validateSourceProviderCombination validates that the source type is compatible with the provider type
+// validateSourceProviderCombination validates that the source type is compatible with the provider type.
+// Offline providers serve sources of every type from their feed.
 func validateSourceProviderCombination(sourceType PackageSourceType, providerType PackageSourceProviderType) error {
+	if providerType == PackageSourceProviderTypeOffline {
+		return nil
+	}
 	switch sourceType {
 	case PackageSourceTypeGitRelease, PackageSourceTypeGitTag, PackageSourceTypeGitHelmChart:
 		if providerType != PackageSourceProviderTypeGitHub {
 			return fmt.Errorf("source type '%s' requires provider type 'github', but provider type is '%s'", sourceType, providerType)
 		}
-	case PackageSourceTypeDockerImage:
+	case PackageSourceTypeDockerImage, PackageSourceTypeDockerNamespace:
 		if providerType != PackageSourceProviderTypeDocker && providerType != PackageSourceProviderTypeHarbor {
 			return fmt.Errorf("source type '%s' requires provider type 'docker' or 'harbor', but provider type is '%s'", sourceType, providerType)
 		}
-	case PackageSourceTypeHelmRepository:
+	case PackageSourceTypeHelmRepository, PackageSourceTypeHelmRepoAll:
 		if providerType != PackageSourceProviderTypeHelm {
 			return fmt.Errorf("source type '%s' requires provider type 'helm', but provider type is '%s'", sourceType, providerType)
 		}
@@ -273,12 +762,120 @@ func validateSourceProviderCombination(sourceType PackageSourceType, providerTyp
 	return nil
 }
 
+// discoveredPackage names the packages a discovery source lists
+func discoveredPackage(sourceType PackageSourceType) string {
+	if sourceType == PackageSourceTypeHelmRepoAll {
+		return "chart"
+	}
+	return "repository"
+}
+
+// validateVersionFormat validates the version format options of a source, target, or item
+func validateVersionFormat(result *ValidationResult, fieldPrefix string, template string, prefix string, extractPattern string, writeTemplate string) {
+	if template != "" && prefix != "" {
+		result.AddError(fmt.Sprintf("%s.versionPrefix", fieldPrefix), "versionPrefix and versionTemplate are mutually exclusive")
+	}
+	if template != "" && strings.Count(template, VersionPlaceholder) != 1 {
+		result.AddError(fmt.Sprintf("%s.versionTemplate", fieldPrefix), fmt.Sprintf("versionTemplate must contain %s exactly once", VersionPlaceholder))
+	}
+	if extractPattern != "" {
+		if template != "" || prefix != "" {
+			result.AddError(fmt.Sprintf("%s.extractPattern", fieldPrefix), "extractPattern cannot be combined with versionPrefix or versionTemplate")
+		}
+		if _, err := CompilePattern(extractPattern); err != nil {
+			result.AddError(fmt.Sprintf("%s.extractPattern", fieldPrefix), fmt.Sprintf("invalid extractPattern: %v", err))
+		}
+	}
+	if writeTemplate != "" {
+		if extractPattern == "" {
+			result.AddError(fmt.Sprintf("%s.writeTemplate", fieldPrefix), "writeTemplate requires extractPattern")
+		}
+		if !strings.Contains(writeTemplate, VersionPlaceholder) {
+			result.AddError(fmt.Sprintf("%s.writeTemplate", fieldPrefix), fmt.Sprintf("writeTemplate must contain %s", VersionPlaceholder))
+		}
+	}
+}
+
+// validateVerification validates the supply-chain verification policy of a source
+func validateVerification(result *ValidationResult, fieldPrefix string, source *PackageSource) {
+	verification := source.Verification
+	switch verification.Type {
+	case PackageSourceVerificationTypeSignature, PackageSourceVerificationTypeProvenance:
+		if source.Type != PackageSourceTypeDockerImage {
+			result.AddError(fieldPrefix, fmt.Sprintf("%s verification is only supported for docker-image sources, not '%s'", verification.Type, source.Type))
+		}
+	case PackageSourceVerificationTypeTagSignature:
+		if source.Type != PackageSourceTypeGitTag && source.Type != PackageSourceTypeGitRelease {
+			result.AddError(fieldPrefix, fmt.Sprintf("tag-signature verification is only supported for git-tag and git-release sources, not '%s'", source.Type))
+		}
+	default:
+		result.AddError(fmt.Sprintf("%s.type", fieldPrefix), fmt.Sprintf("invalid verification type: %s (must be signature, provenance or tag-signature)", verification.Type))
+	}
+
+	keyless := verification.CertificateIdentity != "" || verification.CertificateOidcIssuer != ""
+	if verification.Key != "" && keyless {
+		result.AddError(fieldPrefix, "key and certificateIdentity/certificateOidcIssuer are mutually exclusive")
+	} else if verification.Key == "" && !keyless {
+		result.AddError(fieldPrefix, "either key or certificateIdentity and certificateOidcIssuer are required")
+	} else if keyless && (verification.CertificateIdentity == "" || verification.CertificateOidcIssuer == "") {
+		result.AddError(fieldPrefix, "certificateIdentity and certificateOidcIssuer must be set together")
+	}
+}
+
+// validateClusterReference validates the cluster resource a version is read from
+func validateClusterReference(result *ValidationResult, fieldPrefix string, readFrom TargetReadFrom, cluster *ClusterReference) {
+	if readFrom != TargetReadFromCluster {
+		result.AddError(fieldPrefix, "cluster requires readFrom cluster")
+	}
+	kind, _, err := ParseClusterResource(cluster.Resource)
+	if err != nil {
+		result.AddError(fmt.Sprintf("%s.resource", fieldPrefix), err.Error())
+	} else if kind == ClusterKindHelmRelease && cluster.Container != "" {
+		result.AddError(fmt.Sprintf("%s.container", fieldPrefix), "container is not supported for helmrelease resources")
+	}
+}
+
+// validateImageCheck validates the registry check of new image tags
+func validateImageCheck(result *ValidationResult, fieldPrefix string, check *ImageCheck, providerByName map[string]*PackageSourceProvider) {
+	if check.Provider != "" {
+		if provider, ok := providerByName[check.Provider]; !ok {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' not found in packageSourceProviders", check.Provider))
+		} else if provider.Type != PackageSourceProviderTypeDocker {
+			result.AddError(fmt.Sprintf("%s.provider", fieldPrefix), fmt.Sprintf("provider '%s' must be of type docker", check.Provider))
+		}
+	}
+	for i, platform := range check.Platforms {
+		if _, err := ParsePlatform(platform); err != nil {
+			result.AddError(fmt.Sprintf("%s.platforms[%d]", fieldPrefix, i), err.Error())
+		}
+	}
+}
+
+// isValidUpdateType checks if the update type is valid for an update policy
+func isValidUpdateType(updateType string) bool {
+	switch updateType {
+	case "major", "minor", "patch":
+		return true
+	default:
+		return false
+	}
+}
+
 // isValidTargetType checks if the target type is valid
 func isValidTargetType(targetType TargetType) bool {
 	switch targetType {
 	case TargetTypeTerraformVariable,
 		TargetTypeSubchart,
-		TargetTypeYamlField:
+		TargetTypeYamlField,
+		TargetTypeJsonnetField,
+		TargetTypeHelmfile,
+		TargetTypeCargoToml,
+		TargetTypeMavenPom,
+		TargetTypeGradleCatalog,
+		TargetTypeMakeVariable,
+		TargetTypeToolVersions,
+		TargetTypeDevcontainer,
+		TargetTypeCIImage:
 		return true
 	default:
 		return false
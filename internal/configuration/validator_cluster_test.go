@@ -0,0 +1,144 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestParseClusterResource(t *testing.T) {
+	tests := []struct {
+		resource     string
+		expectedKind string
+		expectedName string
+		expectErr    bool
+	}{
+		{resource: "deployment/app", expectedKind: ClusterKindDeployment, expectedName: "app"},
+		{resource: "HelmRelease/podinfo", expectedKind: ClusterKindHelmRelease, expectedName: "podinfo"},
+		{resource: "cronjob/backup", expectedKind: ClusterKindCronJob, expectedName: "backup"},
+		{resource: "deployment", expectErr: true},
+		{resource: "deployment/", expectErr: true},
+		{resource: "pod/app-123", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			kind, name, err := ParseClusterResource(tt.resource)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.resource)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseClusterResource() error = %v", err)
+			}
+			if kind != tt.expectedKind || name != tt.expectedName {
+				t.Errorf("ParseClusterResource() = %s, %s, expected %s, %s", kind, name, tt.expectedKind, tt.expectedName)
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_ReadFromCluster(t *testing.T) {
+	newConfig := func(readFrom TargetReadFrom, targetCluster *ClusterReference, itemCluster *ClusterReference) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "dockerhub", Type: PackageSourceProviderTypeDocker},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "example/app"},
+			},
+			Targets: []*Target{
+				{
+					Name:     "app",
+					Type:     TargetTypeYamlField,
+					File:     "values.yaml",
+					ReadFrom: readFrom,
+					Cluster:  targetCluster,
+					Items: []TargetItem{
+						{YamlPath: "image.tag", Source: "app", Cluster: itemCluster},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "read from file",
+			config:      newConfig("", nil, nil),
+			expectValid: true,
+		},
+		{
+			name:        "read from deployment",
+			config:      newConfig(TargetReadFromCluster, &ClusterReference{Resource: "deployment/app", Container: "app"}, nil),
+			expectValid: true,
+		},
+		{
+			name:        "item overrides resource",
+			config:      newConfig(TargetReadFromCluster, nil, &ClusterReference{Resource: "helmrelease/app"}),
+			expectValid: true,
+		},
+		{
+			name:          "invalid readFrom",
+			config:        newConfig("registry", nil, nil),
+			expectValid:   false,
+			errorContains: "invalid readFrom",
+		},
+		{
+			name:          "cluster without resource",
+			config:        newConfig(TargetReadFromCluster, nil, nil),
+			expectValid:   false,
+			errorContains: "cluster resource is required",
+		},
+		{
+			name:          "cluster without readFrom",
+			config:        newConfig("", &ClusterReference{Resource: "deployment/app"}, nil),
+			expectValid:   false,
+			errorContains: "requires readFrom cluster",
+		},
+		{
+			name:          "unsupported kind",
+			config:        newConfig(TargetReadFromCluster, &ClusterReference{Resource: "pod/app"}, nil),
+			expectValid:   false,
+			errorContains: "unsupported cluster resource kind",
+		},
+		{
+			name:          "container of helm release",
+			config:        newConfig(TargetReadFromCluster, &ClusterReference{Resource: "helmrelease/app", Container: "app"}, nil),
+			expectValid:   false,
+			errorContains: "container is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
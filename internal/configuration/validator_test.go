@@ -211,6 +211,103 @@ func TestValidateConfiguration_Targets(t *testing.T) {
 			},
 			expectValid: true,
 		},
+		{
+			name: "target with invalid maxUpdateType",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub},
+				},
+				PackageSources: []*PackageSource{
+					{Name: "test-source", Provider: "github", Type: PackageSourceTypeGitRelease, URI: "https://github.com/test/repo"},
+				},
+				Targets: []*Target{
+					{
+						Name:          "test-target",
+						Type:          TargetTypeTerraformVariable,
+						File:          "test.tf",
+						MaxUpdateType: "breaking",
+						Items: []TargetItem{
+							{TerraformVariableName: "version", Source: "test-source"},
+						},
+					},
+				},
+			},
+			expectValid:   false,
+			errorContains: "invalid maxUpdateType: breaking",
+		},
+		{
+			name: "item with valid maxUpdateType",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub},
+				},
+				PackageSources: []*PackageSource{
+					{Name: "test-source", Provider: "github", Type: PackageSourceTypeGitRelease, URI: "https://github.com/test/repo"},
+				},
+				Targets: []*Target{
+					{
+						Name: "test-target",
+						Type: TargetTypeTerraformVariable,
+						File: "test.tf",
+						Items: []TargetItem{
+							{TerraformVariableName: "version", Source: "test-source", MaxUpdateType: "minor"},
+						},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "version set items with different maxUpdateType",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub},
+				},
+				PackageSources: []*PackageSource{
+					{Name: "test-source", Provider: "github", Type: PackageSourceTypeGitRelease, URI: "https://github.com/test/repo"},
+				},
+				Targets: []*Target{
+					{
+						Name:       "test-target",
+						Type:       TargetTypeTerraformVariable,
+						File:       "test.tf",
+						VersionSet: "app",
+						Items: []TargetItem{
+							{TerraformVariableName: "server_version", Source: "test-source"},
+							{TerraformVariableName: "worker_version", Source: "test-source", MaxUpdateType: "minor"},
+						},
+					},
+				},
+			},
+			expectValid:   false,
+			errorContains: "must have the same maxUpdateType",
+		},
+		{
+			name: "version set items with different tracks",
+			config: &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub},
+				},
+				PackageSources: []*PackageSource{
+					{Name: "test-source", Provider: "github", Type: PackageSourceTypeGitRelease, URI: "https://github.com/test/repo",
+						Tracks: []*PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}}},
+				},
+				Targets: []*Target{
+					{
+						Name:       "test-target",
+						Type:       TargetTypeTerraformVariable,
+						File:       "test.tf",
+						VersionSet: "app",
+						Items: []TargetItem{
+							{TerraformVariableName: "server_version", Source: "test-source"},
+							{TerraformVariableName: "worker_version", Source: "test-source", Track: "lts"},
+						},
+					},
+				},
+			},
+			expectValid:   false,
+			errorContains: "must follow the same track",
+		},
 	}
 
 	for _, tt := range tests {
@@ -249,6 +346,15 @@ func TestIsValidTargetType(t *testing.T) {
 		{TargetTypeTerraformVariable, true},
 		{TargetTypeSubchart, true},
 		{TargetTypeYamlField, true},
+		{TargetTypeJsonnetField, true},
+		{TargetTypeHelmfile, true},
+		{TargetTypeCargoToml, true},
+		{TargetTypeMavenPom, true},
+		{TargetTypeGradleCatalog, true},
+		{TargetTypeMakeVariable, true},
+		{TargetTypeToolVersions, true},
+		{TargetTypeDevcontainer, true},
+		{TargetTypeCIImage, true},
 		{TargetType("invalid"), false},
 		{TargetType(""), false},
 	}
@@ -262,3 +368,850 @@ func TestIsValidTargetType(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfiguration_PackageManagerTargets(t *testing.T) {
+	tests := []struct {
+		name          string
+		targetType    TargetType
+		item          TargetItem
+		errorContains string
+	}{
+		{name: "maven artifact", targetType: TargetTypeMavenPom, item: TargetItem{Artifact: "org.slf4j:slf4j-api"}},
+		{name: "maven property", targetType: TargetTypeMavenPom, item: TargetItem{Property: "jackson.version"}},
+		{name: "maven artifact and property", targetType: TargetTypeMavenPom, item: TargetItem{Artifact: "org.slf4j:slf4j-api", Property: "slf4j.version"}, errorContains: "either artifact or property is required"},
+		{name: "maven artifact without group", targetType: TargetTypeMavenPom, item: TargetItem{Artifact: "slf4j-api"}, errorContains: "groupId:artifactId"},
+		{name: "gradle library", targetType: TargetTypeGradleCatalog, item: TargetItem{CatalogEntry: "libraries.okhttp"}},
+		{name: "gradle bundle", targetType: TargetTypeGradleCatalog, item: TargetItem{CatalogEntry: "bundles.testing"}, errorContains: "catalogEntry is required"},
+		{name: "gradle without alias", targetType: TargetTypeGradleCatalog, item: TargetItem{CatalogEntry: "versions"}, errorContains: "catalogEntry is required"},
+		{name: "make variable", targetType: TargetTypeMakeVariable, item: TargetItem{VariableName: "KUBECTL_VERSION"}},
+		{name: "make variable without name", targetType: TargetTypeMakeVariable, item: TargetItem{}, errorContains: "variableName is required"},
+		{name: "tool", targetType: TargetTypeToolVersions, item: TargetItem{ToolName: "nodejs"}},
+		{name: "tool without name", targetType: TargetTypeToolVersions, item: TargetItem{}, errorContains: "toolName is required"},
+		{name: "devcontainer image", targetType: TargetTypeDevcontainer, item: TargetItem{Image: "mcr.microsoft.com/devcontainers/go"}},
+		{name: "ci image without image", targetType: TargetTypeCIImage, item: TargetItem{}, errorContains: "image is required for ci-image target"},
+		{name: "ci image with tag", targetType: TargetTypeCIImage, item: TargetItem{Image: "golang:1.22"}, errorContains: "without tag"},
+		{name: "ci image with registry port", targetType: TargetTypeCIImage, item: TargetItem{Image: "registry.local:5000/golang"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.Source = "test-source"
+			config := &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "github", Type: PackageSourceProviderTypeGitHub},
+				},
+				PackageSources: []*PackageSource{
+					{Name: "test-source", Provider: "github", Type: PackageSourceTypeGitTag, URI: "https://github.com/test/repo"},
+				},
+				Targets: []*Target{
+					{Name: "test-target", Type: tt.targetType, File: "build-file", Items: []TargetItem{tt.item}},
+				},
+			}
+
+			result := ValidateConfiguration(config)
+			if tt.errorContains == "" {
+				if !result.Valid {
+					t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+				}
+				return
+			}
+			for _, err := range result.Errors {
+				if contains(err.Message, tt.errorContains) {
+					return
+				}
+			}
+			t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+		})
+	}
+}
+
+func TestIsValidUpdateType(t *testing.T) {
+	tests := []struct {
+		updateType string
+		expected   bool
+	}{
+		{"major", true},
+		{"minor", true},
+		{"patch", true},
+		{"none", false},
+		{"Minor", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.updateType, func(t *testing.T) {
+			result := isValidUpdateType(tt.updateType)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsValidCommitStrategy(t *testing.T) {
+	tests := []struct {
+		strategy CommitStrategy
+		expected bool
+	}{
+		{CommitStrategyPerFile, true},
+		{CommitStrategyPerItem, true},
+		{CommitStrategySingle, true},
+		{CommitStrategy("per-commit"), false},
+		{CommitStrategy(""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			result := isValidCommitStrategy(tt.strategy)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_VersionSets(t *testing.T) {
+	newConfig := func(workerSource string, workerPatchGroup string) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "dockerhub", Type: PackageSourceProviderTypeDocker},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "example/app"},
+				{Name: "other", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "example/other"},
+			},
+			Targets: []*Target{
+				{
+					Name:       "server",
+					Type:       TargetTypeYamlField,
+					File:       "server/values.yaml",
+					VersionSet: "app",
+					Items: []TargetItem{
+						{YamlPath: "image.tag", Source: "app"},
+					},
+				},
+				{
+					Name:       "worker",
+					Type:       TargetTypeYamlField,
+					File:       "worker/values.yaml",
+					PatchGroup: workerPatchGroup,
+					Items: []TargetItem{
+						{YamlPath: "image.tag", Source: workerSource, VersionSet: "app"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "consistent version set",
+			config:      newConfig("app", ""),
+			expectValid: true,
+		},
+		{
+			name:          "version set with different sources",
+			config:        newConfig("other", ""),
+			expectValid:   false,
+			errorContains: "must use the same source",
+		},
+		{
+			name:          "version set across patch groups",
+			config:        newConfig("app", "staging"),
+			expectValid:   false,
+			errorContains: "must be in the same patch group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_Rollout(t *testing.T) {
+	newConfig := func(rollout *Rollout, stage string) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "dockerhub", Type: PackageSourceProviderTypeDocker},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "dockerhub", Type: PackageSourceTypeDockerImage, URI: "example/app"},
+			},
+			Targets: []*Target{
+				{
+					Name:  "app",
+					Type:  TargetTypeYamlField,
+					File:  "dev/values.yaml",
+					Stage: stage,
+					Items: []TargetItem{
+						{YamlPath: "image.tag", Source: "app"},
+					},
+				},
+			},
+			Rollout: rollout,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name: "valid rollout",
+			config: newConfig(&Rollout{Stages: []*RolloutStage{
+				{Name: "dev"},
+				{Name: "prod", SoakTime: "2d", ApprovalLabel: "promote"},
+			}}, "dev"),
+			expectValid: true,
+		},
+		{
+			name:          "stage without rollout",
+			config:        newConfig(nil, "dev"),
+			expectValid:   false,
+			errorContains: "not defined in rollout",
+		},
+		{
+			name: "unknown stage",
+			config: newConfig(&Rollout{Stages: []*RolloutStage{
+				{Name: "dev"},
+				{Name: "prod"},
+			}}, "staging"),
+			expectValid:   false,
+			errorContains: "not defined in rollout",
+		},
+		{
+			name:          "single stage",
+			config:        newConfig(&Rollout{Stages: []*RolloutStage{{Name: "dev"}}}, ""),
+			expectValid:   false,
+			errorContains: "at least two stages",
+		},
+		{
+			name: "duplicate stage",
+			config: newConfig(&Rollout{Stages: []*RolloutStage{
+				{Name: "dev"},
+				{Name: "dev"},
+			}}, ""),
+			expectValid:   false,
+			errorContains: "duplicate stage name",
+		},
+		{
+			name: "invalid soak time",
+			config: newConfig(&Rollout{Stages: []*RolloutStage{
+				{Name: "dev"},
+				{Name: "prod", SoakTime: "two days"},
+			}}, ""),
+			expectValid: false,
+		},
+		{
+			name: "soak time on first stage",
+			config: newConfig(&Rollout{Stages: []*RolloutStage{
+				{Name: "dev", SoakTime: "1d"},
+				{Name: "prod"},
+			}}, ""),
+			expectValid:   false,
+			errorContains: "no previous stage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_Verification(t *testing.T) {
+	newConfig := func(sourceType PackageSourceType, verification *PackageSourceVerification) *Config {
+		providerType := PackageSourceProviderTypeDocker
+		if sourceType != PackageSourceTypeDockerImage {
+			providerType = PackageSourceProviderTypeGitHub
+		}
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{Name: "provider", Type: providerType},
+			},
+			PackageSources: []*PackageSource{
+				{Name: "app", Provider: "provider", Type: sourceType, URI: "example/app", Verification: verification},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name: "signature with key",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type: PackageSourceVerificationTypeSignature,
+				Key:  "cosign.pub",
+			}),
+			expectValid: true,
+		},
+		{
+			name: "keyless provenance",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type:                  PackageSourceVerificationTypeProvenance,
+				CertificateIdentity:   "https://github.com/example/app/.github/workflows/release.yml@refs/heads/main",
+				CertificateOidcIssuer: "https://token.actions.githubusercontent.com",
+			}),
+			expectValid: true,
+		},
+		{
+			name: "invalid type",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type: "sbom",
+				Key:  "cosign.pub",
+			}),
+			expectValid:   false,
+			errorContains: "invalid verification type",
+		},
+		{
+			name: "no key or identity",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type: PackageSourceVerificationTypeSignature,
+			}),
+			expectValid:   false,
+			errorContains: "either key or certificateIdentity",
+		},
+		{
+			name: "identity without issuer",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type:                PackageSourceVerificationTypeSignature,
+				CertificateIdentity: "release@example.com",
+			}),
+			expectValid:   false,
+			errorContains: "must be set together",
+		},
+		{
+			name: "non-docker source",
+			config: newConfig(PackageSourceTypeGitRelease, &PackageSourceVerification{
+				Type: PackageSourceVerificationTypeSignature,
+				Key:  "cosign.pub",
+			}),
+			expectValid:   false,
+			errorContains: "only supported for docker-image sources",
+		},
+		{
+			name: "tag signature with key",
+			config: newConfig(PackageSourceTypeGitTag, &PackageSourceVerification{
+				Type: PackageSourceVerificationTypeTagSignature,
+				Key:  "release-key.asc",
+			}),
+			expectValid: true,
+		},
+		{
+			name: "keyless tag signature of releases",
+			config: newConfig(PackageSourceTypeGitRelease, &PackageSourceVerification{
+				Type:                  PackageSourceVerificationTypeTagSignature,
+				CertificateIdentity:   "release@example.com",
+				CertificateOidcIssuer: "https://accounts.google.com",
+			}),
+			expectValid: true,
+		},
+		{
+			name: "tag signature of docker source",
+			config: newConfig(PackageSourceTypeDockerImage, &PackageSourceVerification{
+				Type: PackageSourceVerificationTypeTagSignature,
+				Key:  "release-key.asc",
+			}),
+			expectValid:   false,
+			errorContains: "only supported for git-tag and git-release sources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_VersionScheme(t *testing.T) {
+	tests := []struct {
+		name          string
+		scheme        string
+		incremental   bool
+		expectValid   bool
+		errorContains string
+	}{
+		{name: "default", expectValid: true},
+		{name: "semantic", scheme: "semantic", expectValid: true},
+		{name: "loose", scheme: "loose", expectValid: true},
+		{name: "invalid", scheme: "debian", errorContains: "invalid version scheme 'debian'"},
+		{name: "semantic incremental", scheme: "semantic", incremental: true, expectValid: true},
+		{name: "loose incremental", scheme: "loose", incremental: true, errorContains: "requires the semantic version scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				PackageSourceProviders: []*PackageSourceProvider{{Name: "provider", Type: PackageSourceProviderTypeDocker}},
+				PackageSources: []*PackageSource{
+					{Name: "app", Provider: "provider", Type: PackageSourceTypeDockerImage, URI: "example/app", VersionScheme: tt.scheme, Incremental: tt.incremental},
+				},
+			}
+			result := ValidateConfiguration(config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+			if !tt.expectValid {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_Patterns(t *testing.T) {
+	tests := []struct {
+		name           string
+		tagPattern     string
+		excludePattern string
+		expectValid    bool
+		errorContains  string
+	}{
+		{name: "no patterns", expectValid: true},
+		{name: "valid patterns", tagPattern: `^\d+\.\d+\.\d+$`, excludePattern: `-rc`, expectValid: true},
+		{name: "invalid tagPattern", tagPattern: `^(\d+`, errorContains: "invalid tagPattern"},
+		{name: "invalid excludePattern", excludePattern: `[`, errorContains: "invalid excludePattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				PackageSourceProviders: []*PackageSourceProvider{{Name: "provider", Type: PackageSourceProviderTypeDocker}},
+				PackageSources: []*PackageSource{
+					{Name: "app", Provider: "provider", Type: PackageSourceTypeDockerImage, URI: "example/app", TagPattern: tt.tagPattern, ExcludePattern: tt.excludePattern},
+				},
+			}
+			result := ValidateConfiguration(config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+			if !tt.expectValid {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_StaticSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        *PackageSource
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "valid static source",
+			source:      &PackageSource{Name: "app", Type: PackageSourceTypeStatic, StaticVersions: []string{"1.0.0", "1.1.0"}},
+			expectValid: true,
+		},
+		{
+			name:          "missing versions",
+			source:        &PackageSource{Name: "app", Type: PackageSourceTypeStatic},
+			errorContains: "staticVersions is required",
+		},
+		{
+			name:          "empty version",
+			source:        &PackageSource{Name: "app", Type: PackageSourceTypeStatic, StaticVersions: []string{"1.0.0", " "}},
+			errorContains: "version cannot be empty",
+		},
+		{
+			name:          "provider set",
+			source:        &PackageSource{Name: "app", Provider: "provider", Type: PackageSourceTypeStatic, StaticVersions: []string{"1.0.0"}},
+			errorContains: "do not use a provider",
+		},
+		{
+			name:          "versions on another source type",
+			source:        &PackageSource{Name: "app", Provider: "provider", Type: PackageSourceTypeDockerImage, URI: "example/app", StaticVersions: []string{"1.0.0"}},
+			errorContains: "only supported for static sources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				PackageSourceProviders: []*PackageSourceProvider{{Name: "provider", Type: PackageSourceProviderTypeDocker}},
+				PackageSources:         []*PackageSource{tt.source},
+			}
+			result := ValidateConfiguration(config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+			if !tt.expectValid {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_DiscoverySource(t *testing.T) {
+	namespace := func() *PackageSource {
+		return &PackageSource{Name: "acme", Provider: "provider", Type: PackageSourceTypeDockerNamespace, URI: "ghcr.io/acme"}
+	}
+	tests := []struct {
+		name          string
+		source        *PackageSource
+		itemSource    string
+		itemTrack     string
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "repository of the namespace",
+			source:      namespace(),
+			itemSource:  "acme/api",
+			expectValid: true,
+		},
+		{
+			name: "track of the namespace",
+			source: func() *PackageSource {
+				source := namespace()
+				source.Tracks = []*PackageSourceTrack{{Name: "stable", TagPattern: `^\d+\.\d+\.\d+$`}}
+				return source
+			}(),
+			itemSource:  "acme/api",
+			itemTrack:   "stable",
+			expectValid: true,
+		},
+		{
+			name:          "undefined track",
+			source:        namespace(),
+			itemSource:    "acme/api",
+			itemTrack:     "stable",
+			errorContains: "track 'stable' not defined",
+		},
+		{
+			name:          "namespace referenced directly",
+			source:        namespace(),
+			itemSource:    "acme",
+			errorContains: "must be referenced as 'acme/<repository>'",
+		},
+		{
+			name:          "repository of another namespace",
+			source:        namespace(),
+			itemSource:    "other/api",
+			errorContains: "not found in packageSources",
+		},
+		{
+			name: "invalid repository pattern",
+			source: func() *PackageSource {
+				source := namespace()
+				source.RepositoryPattern = "("
+				return source
+			}(),
+			itemSource:    "acme/api",
+			errorContains: "invalid repositoryPattern",
+		},
+		{
+			name:        "chart of a helm repository",
+			source:      &PackageSource{Name: "charts", Provider: "helm", Type: PackageSourceTypeHelmRepoAll, ChartPattern: "^postgres"},
+			itemSource:  "charts/postgres",
+			expectValid: true,
+		},
+		{
+			name:          "helm repository referenced directly",
+			source:        &PackageSource{Name: "charts", Provider: "helm", Type: PackageSourceTypeHelmRepoAll},
+			itemSource:    "charts",
+			errorContains: "must be referenced as 'charts/<chart>'",
+		},
+		{
+			name:          "helm repository without base URL",
+			source:        &PackageSource{Name: "charts", Provider: "helm-without-url", Type: PackageSourceTypeHelmRepoAll},
+			itemSource:    "charts/postgres",
+			errorContains: "must have baseUrl configured for helm-repo-all",
+		},
+		{
+			name:          "chart pattern on another source type",
+			source:        &PackageSource{Name: "acme", Provider: "provider", Type: PackageSourceTypeDockerNamespace, URI: "acme", ChartPattern: "^api$"},
+			itemSource:    "acme/api",
+			errorContains: "only supported for helm-repo-all sources",
+		},
+		{
+			name:          "repository pattern on another source type",
+			source:        &PackageSource{Name: "acme/api", Provider: "provider", Type: PackageSourceTypeDockerImage, URI: "acme/api", RepositoryPattern: "^api$"},
+			itemSource:    "acme/api",
+			errorContains: "only supported for docker-namespace sources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				PackageSourceProviders: []*PackageSourceProvider{
+					{Name: "provider", Type: PackageSourceProviderTypeDocker},
+					{Name: "helm", Type: PackageSourceProviderTypeHelm, BaseUrl: "https://charts.example.com"},
+					{Name: "helm-without-url", Type: PackageSourceProviderTypeHelm},
+				},
+				PackageSources: []*PackageSource{tt.source},
+				Targets: []*Target{{
+					Name:  "app",
+					Type:  TargetTypeYamlField,
+					File:  "values.yaml",
+					Items: []TargetItem{{YamlPath: "image.tag", Source: tt.itemSource, Track: tt.itemTrack}},
+				}},
+			}
+			result := ValidateConfiguration(config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+			if !tt.expectValid {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	first, err := CompilePattern(`^v\d+$`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := CompilePattern(`^v\d+$`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first != second {
+		t.Error("Expected the compiled pattern to be cached")
+	}
+	if _, err := CompilePattern(`(`); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestValidateConfiguration_ClientCertificate(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      *PackageSourceProvider
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "helm provider with certificate and key",
+			provider:    &PackageSourceProvider{Name: "repo", Type: PackageSourceProviderTypeHelm, BaseUrl: "https://charts.example.com", CertFile: "client.pem", KeyFile: "client-key.pem"},
+			expectValid: true,
+		},
+		{
+			name:          "certificate without key",
+			provider:      &PackageSourceProvider{Name: "repo", Type: PackageSourceProviderTypeHelm, BaseUrl: "https://charts.example.com", CertFile: "client.pem"},
+			expectValid:   false,
+			errorContains: "keyFile is required",
+		},
+		{
+			name:          "non-helm provider",
+			provider:      &PackageSourceProvider{Name: "repo", Type: PackageSourceProviderTypeDocker, CertFile: "client.pem", KeyFile: "client-key.pem"},
+			expectValid:   false,
+			errorContains: "only supported for helm providers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(&Config{PackageSourceProviders: []*PackageSourceProvider{tt.provider}})
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration_OfflineProvider(t *testing.T) {
+	offline := &PackageSourceProvider{Name: "mirror", Type: PackageSourceProviderTypeOffline, Feed: "feeds/"}
+	tests := []struct {
+		name          string
+		provider      *PackageSourceProvider
+		source        *PackageSource
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "git source",
+			provider:    offline,
+			source:      &PackageSource{Name: "app", Provider: "mirror", Type: PackageSourceTypeGitRelease, URI: "https://github.com/org/app"},
+			expectValid: true,
+		},
+		{
+			name:        "helm chart without baseUrl",
+			provider:    offline,
+			source:      &PackageSource{Name: "chart", Provider: "mirror", Type: PackageSourceTypeHelmRepository, ChartName: "chart"},
+			expectValid: true,
+		},
+		{
+			name:          "missing feed",
+			provider:      &PackageSourceProvider{Name: "mirror", Type: PackageSourceProviderTypeOffline},
+			source:        &PackageSource{Name: "image", Provider: "mirror", Type: PackageSourceTypeDockerImage, URI: "nginx"},
+			expectValid:   false,
+			errorContains: "feed is required",
+		},
+		{
+			name:          "feed of another provider type",
+			provider:      &PackageSourceProvider{Name: "mirror", Type: PackageSourceProviderTypeDocker, Feed: "feeds/"},
+			source:        &PackageSource{Name: "image", Provider: "mirror", Type: PackageSourceTypeDockerImage, URI: "nginx"},
+			expectValid:   false,
+			errorContains: "only supported for offline providers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(&Config{
+				PackageSourceProviders: []*PackageSourceProvider{tt.provider},
+				PackageSources:         []*PackageSource{tt.source},
+			})
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
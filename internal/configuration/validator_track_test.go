@@ -0,0 +1,134 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestValidateConfiguration_Tracks(t *testing.T) {
+	newConfig := func(tracks []*PackageSourceTrack, targetTrack string, itemTrack string) *Config {
+		return &Config{
+			PackageSourceProviders: []*PackageSourceProvider{
+				{
+					Name: "dockerhub",
+					Type: PackageSourceProviderTypeDocker,
+				},
+			},
+			PackageSources: []*PackageSource{
+				{
+					Name:     "nginx",
+					Provider: "dockerhub",
+					Type:     PackageSourceTypeDockerImage,
+					URI:      "nginx",
+					Tracks:   tracks,
+				},
+			},
+			Targets: []*Target{
+				{
+					Name:  "nginx-values",
+					Type:  TargetTypeYamlField,
+					File:  "values.yaml",
+					Track: targetTrack,
+					Items: []TargetItem{
+						{
+							YamlPath: "image.tag",
+							Source:   "nginx",
+							Track:    itemTrack,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	stableAndMainline := []*PackageSourceTrack{
+		{Name: "stable", TagPattern: `^1\.26\.\d+$`},
+		{Name: "mainline", TagPattern: `^1\.27\.\d+$`},
+	}
+
+	tests := []struct {
+		name          string
+		config        *Config
+		expectValid   bool
+		errorContains string
+	}{
+		{
+			name:        "no tracks",
+			config:      newConfig(nil, "", ""),
+			expectValid: true,
+		},
+		{
+			name:        "item subscribes to defined track",
+			config:      newConfig(stableAndMainline, "", "stable"),
+			expectValid: true,
+		},
+		{
+			name:        "target subscribes to defined track",
+			config:      newConfig(stableAndMainline, "mainline", ""),
+			expectValid: true,
+		},
+		{
+			name:          "item subscribes to undefined track",
+			config:        newConfig(stableAndMainline, "", "lts"),
+			expectValid:   false,
+			errorContains: "track 'lts' not defined on source 'nginx'",
+		},
+		{
+			name:          "item track overrides target track",
+			config:        newConfig(stableAndMainline, "stable", "lts"),
+			expectValid:   false,
+			errorContains: "track 'lts' not defined",
+		},
+		{
+			name: "duplicate track name",
+			config: newConfig([]*PackageSourceTrack{
+				{Name: "stable", TagPattern: `^1\.26\.`},
+				{Name: "stable", TagPattern: `^1\.27\.`},
+			}, "", ""),
+			expectValid:   false,
+			errorContains: "duplicate track name: stable",
+		},
+		{
+			name: "track without tagPattern",
+			config: newConfig([]*PackageSourceTrack{
+				{Name: "stable"},
+			}, "", ""),
+			expectValid:   false,
+			errorContains: "tagPattern is required",
+		},
+		{
+			name: "track with invalid tagPattern",
+			config: newConfig([]*PackageSourceTrack{
+				{Name: "stable", TagPattern: `^1\.(26`},
+			}, "", ""),
+			expectValid:   false,
+			errorContains: "invalid tagPattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfiguration(tt.config)
+
+			if tt.expectValid && !result.Valid {
+				t.Errorf("Expected valid configuration, but got errors: %v", result.Errors)
+			}
+
+			if !tt.expectValid && result.Valid {
+				t.Errorf("Expected invalid configuration, but validation passed")
+			}
+
+			if !tt.expectValid && tt.errorContains != "" {
+				found := false
+				for _, err := range result.Errors {
+					if contains(err.Message, tt.errorContains) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected error containing '%s', but got errors: %v", tt.errorContains, result.Errors)
+				}
+			}
+		})
+	}
+}
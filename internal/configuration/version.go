@@ -1,6 +1,8 @@
 package configuration
 
 import (
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -34,3 +36,330 @@ func ParseSemver(version string) (major, minor, patch int) {
 
 	return major, minor, patch
 }
+
+// ParseBuild extracts the version components following major, minor and patch: the fourth
+// numeric component of versions like "1.2.3.4" and the revision of versions like
+// "7.4.0-2023-11-01" or "1.2.3_2", a suffix starting with a digit. Pre-release suffixes
+// such as "-beta1" are not revisions.
+func ParseBuild(version string) (build int, revision string) {
+	versionStr := strings.TrimPrefix(version, "v")
+	versionStr = strings.TrimPrefix(versionStr, "V")
+
+	base := versionStr
+	if idx := strings.IndexAny(versionStr, "-_+"); idx >= 0 {
+		base = versionStr[:idx]
+		if suffix := versionStr[idx+1:]; suffix != "" && suffix[0] >= '0' && suffix[0] <= '9' {
+			revision = suffix
+		}
+	}
+
+	parts := strings.Split(base, ".")
+	if len(parts) >= 4 {
+		build, _ = strconv.Atoi(parts[3])
+	}
+
+	return build, revision
+}
+
+// CompareRevisions compares two revisions by their numeric components in order, so that
+// "2023-11-01" < "2023-12-01" and "2" < "10". It returns -1, 0 or 1; no revision is the oldest.
+func CompareRevisions(a string, b string) int {
+	isSeparator := func(r rune) bool { return r < '0' || r > '9' }
+	partsA, partsB := strings.FieldsFunc(a, isSeparator), strings.FieldsFunc(b, isSeparator)
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, numberB := strings.TrimLeft(partsA[i], "0"), strings.TrimLeft(partsB[i], "0")
+		if len(numberA) != len(numberB) {
+			return compareInts(len(numberA), len(numberB))
+		}
+		if numberA != numberB {
+			return strings.Compare(numberA, numberB)
+		}
+	}
+	return compareInts(len(partsA), len(partsB))
+}
+
+// CompareVersions compares two versions by their major, minor, patch and build numbers and
+// their revisions. It returns -1, 0 or 1.
+func CompareVersions(a *PackageSourceVersion, b *PackageSourceVersion) int {
+	if a.MajorVersion != b.MajorVersion {
+		return compareInts(a.MajorVersion, b.MajorVersion)
+	}
+	if a.MinorVersion != b.MinorVersion {
+		return compareInts(a.MinorVersion, b.MinorVersion)
+	}
+	if a.PatchVersion != b.PatchVersion {
+		return compareInts(a.PatchVersion, b.PatchVersion)
+	}
+	if a.BuildVersion != b.BuildVersion {
+		return compareInts(a.BuildVersion, b.BuildVersion)
+	}
+	return CompareRevisions(a.Revision, b.Revision)
+}
+
+// CompareLooseVersions compares two arbitrary version strings with the rules of Debian
+// package versions and returns -1, 0 or 1. A numeric epoch before ":" ranks first. The rest
+// is compared in alternating runs of non-digits, compared character by character with
+// letters before other characters and "~" before anything, and digits, compared as numbers.
+// A "v" prefix is ignored.
+func CompareLooseVersions(a string, b string) int {
+	epochA, restA := splitEpoch(a)
+	epochB, restB := splitEpoch(b)
+	if epochA != epochB {
+		return compareInts(epochA, epochB)
+	}
+
+	i, j := 0, 0
+	for i < len(restA) || j < len(restB) {
+		for (i < len(restA) && !isDigit(restA[i])) || (j < len(restB) && !isDigit(restB[j])) {
+			orderA, orderB := looseCharOrder(restA, i), looseCharOrder(restB, j)
+			if orderA != orderB {
+				return compareInts(orderA, orderB)
+			}
+			i++
+			j++
+		}
+
+		for i < len(restA) && restA[i] == '0' {
+			i++
+		}
+		for j < len(restB) && restB[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(restA) && isDigit(restA[i]) && j < len(restB) && isDigit(restB[j]) {
+			if firstDiff == 0 {
+				firstDiff = compareInts(int(restA[i]), int(restB[j]))
+			}
+			i++
+			j++
+		}
+		if i < len(restA) && isDigit(restA[i]) {
+			return 1
+		}
+		if j < len(restB) && isDigit(restB[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// splitEpoch splits a version into its numeric epoch (0 if none) and the rest, removing a
+// "v" prefix
+func splitEpoch(version string) (int, string) {
+	if idx := strings.IndexByte(version, ':'); idx > 0 {
+		if epoch, err := strconv.Atoi(version[:idx]); err == nil {
+			version = version[idx+1:]
+			return epoch, trimVersionPrefix(version)
+		}
+	}
+	return 0, trimVersionPrefix(version)
+}
+
+// trimVersionPrefix removes a "v" or "V" prefix followed by a digit
+func trimVersionPrefix(version string) string {
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && isDigit(version[1]) {
+		return version[1:]
+	}
+	return version
+}
+
+// looseCharOrder ranks the character at position i of a version for CompareLooseVersions.
+// Digits and the end of the string rank 0, "~" ranks below them and letters below all other
+// characters.
+func looseCharOrder(version string, i int) int {
+	if i >= len(version) {
+		return 0
+	}
+	c := version[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// CompareVersions compares two versions of the source by its version scheme and returns
+// -1, 0 or 1
+func (s *PackageSource) CompareVersions(a *PackageSourceVersion, b *PackageSourceVersion) int {
+	if s.VersionScheme == VersionSchemeLoose {
+		return CompareLooseVersions(a.Version, b.Version)
+	}
+	return CompareVersions(a, b)
+}
+
+// compareInts returns -1, 0 or 1 for a < b, a == b and a > b
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortVersions returns a copy of the versions ordered newest first by the sort order of a
+// source: "semantic" (the default) compares them by its version scheme, "alphabetical" the
+// version strings. "date" keeps the given order, as only scrapers know release dates.
+// Versions that compare equal, e.g. pre-releases of the same version, keep their order.
+func SortVersions(versions []*PackageSourceVersion, source *PackageSource) []*PackageSourceVersion {
+	sorted := make([]*PackageSourceVersion, len(versions))
+	copy(sorted, versions)
+
+	switch source.SortBy {
+	case "date":
+	case "alphabetical":
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Version > sorted[j].Version
+		})
+	default:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return source.CompareVersions(sorted[i], sorted[j]) > 0
+		})
+	}
+	return sorted
+}
+
+// LatestVersion returns the newest version of the source by its sort order that satisfies
+// its version constraint, or nil if there is none. Scraped versions are already sorted
+// newest first, but versions loaded from elsewhere may not be.
+func (s *PackageSource) LatestVersion() *PackageSourceVersion {
+	var constraint *VersionConstraint
+	if s.VersionConstraint != "" {
+		constraint, _ = ParseVersionConstraint(s.VersionConstraint)
+	}
+	for _, version := range SortVersions(s.Versions, s) {
+		if constraint == nil || constraint.Allows(version) {
+			return version
+		}
+	}
+	return nil
+}
+
+// VersionPlaceholder marks the position of the bare version in a version template
+const VersionPlaceholder = "{{version}}"
+
+// ResolveVersionTemplate returns the effective version template. An explicit template takes
+// precedence; a prefix is shorthand for prefix + VersionPlaceholder. Returns "" if neither is set.
+func ResolveVersionTemplate(template string, prefix string) string {
+	if template != "" {
+		return template
+	}
+	if prefix != "" {
+		return prefix + VersionPlaceholder
+	}
+	return ""
+}
+
+// FormatVersion renders a bare version (e.g. "1.2.3") using a version template
+// (e.g. "release-{{version}}"). An empty template returns the version unchanged.
+func FormatVersion(template string, version string) string {
+	if template == "" {
+		return version
+	}
+	return strings.Replace(template, VersionPlaceholder, version, 1)
+}
+
+// ExtractVersion extracts the bare version from a value formatted with a version template.
+// It returns false if the value does not match the template. An empty template returns the value unchanged.
+func ExtractVersion(template string, value string) (string, bool) {
+	if template == "" {
+		return value, true
+	}
+
+	prefix, suffix, found := strings.Cut(template, VersionPlaceholder)
+	if !found {
+		return "", false
+	}
+	if len(value) <= len(prefix)+len(suffix) || !strings.HasPrefix(value, prefix) || !strings.HasSuffix(value, suffix) {
+		return "", false
+	}
+
+	return value[len(prefix) : len(value)-len(suffix)], true
+}
+
+// versionGroupIndex returns the capture group holding the version: the group named
+// "version" if present, otherwise the first group, otherwise the whole match (0)
+func versionGroupIndex(pattern *regexp.Regexp) int {
+	if idx := pattern.SubexpIndex("version"); idx > 0 {
+		return idx
+	}
+	if pattern.NumSubexp() >= 1 {
+		return 1
+	}
+	return 0
+}
+
+// ExtractVersionMatch applies an extract pattern to a value (e.g. "6.2.2-php8.2-apache") and
+// returns the extracted version together with all capture groups, keyed by name and by index.
+// It returns false if the pattern does not match or the version group did not participate.
+func ExtractVersionMatch(pattern *regexp.Regexp, value string) (string, map[string]string, bool) {
+	match := pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return "", nil, false
+	}
+
+	idx := versionGroupIndex(pattern)
+	start, end := match[2*idx], match[2*idx+1]
+	if start < 0 || start == end {
+		return "", nil, false
+	}
+
+	groups := make(map[string]string)
+	for i, name := range pattern.SubexpNames() {
+		if i == 0 || match[2*i] < 0 {
+			continue
+		}
+		groupValue := value[match[2*i]:match[2*i+1]]
+		groups[strconv.Itoa(i)] = groupValue
+		if name != "" {
+			groups[name] = groupValue
+		}
+	}
+
+	return value[start:end], groups, true
+}
+
+// ReplaceVersionMatch replaces the version extracted by the pattern with a new version,
+// leaving the rest of the value intact. The value is returned unchanged if the pattern does not match.
+func ReplaceVersionMatch(pattern *regexp.Regexp, value string, version string) string {
+	match := pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return value
+	}
+
+	idx := versionGroupIndex(pattern)
+	start, end := match[2*idx], match[2*idx+1]
+	if start < 0 {
+		return value
+	}
+
+	return value[:start] + version + value[end:]
+}
+
+// RenderWriteTemplate renders a write template such as "{{version}}-{{variant}}" using the new
+// version and the capture groups extracted from the current value
+func RenderWriteTemplate(template string, version string, groups map[string]string) string {
+	rendered := template
+	for name, value := range groups {
+		if name == "version" {
+			continue
+		}
+		rendered = strings.ReplaceAll(rendered, "{{"+name+"}}", value)
+	}
+	return strings.ReplaceAll(rendered, VersionPlaceholder, version)
+}
@@ -0,0 +1,309 @@
+package configuration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestResolveVersionTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		prefix   string
+		expected string
+	}{
+		{"neither set", "", "", ""},
+		{"prefix only", "", "v", "v{{version}}"},
+		{"template only", "{{version}}-alpine", "", "{{version}}-alpine"},
+		{"template takes precedence", "release-{{version}}", "v", "release-{{version}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ResolveVersionTemplate(tt.template, tt.prefix)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	tests := []struct {
+		template string
+		version  string
+		expected string
+	}{
+		{"", "1.2.3", "1.2.3"},
+		{"v{{version}}", "1.2.3", "v1.2.3"},
+		{"release-{{version}}", "1.2.3", "release-1.2.3"},
+		{"{{version}}-alpine", "1.2.3", "1.2.3-alpine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			result := FormatVersion(tt.template, tt.version)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		value       string
+		expected    string
+		expectMatch bool
+	}{
+		{"empty template", "", "v1.2.3", "v1.2.3", true},
+		{"v prefix", "v{{version}}", "v1.2.3", "1.2.3", true},
+		{"release prefix", "release-{{version}}", "release-1.2.3", "1.2.3", true},
+		{"suffix", "{{version}}-alpine", "1.2.3-alpine", "1.2.3", true},
+		{"prefix and suffix", "app-{{version}}-slim", "app-1.2.3-slim", "1.2.3", true},
+		{"prefix mismatch", "release-{{version}}", "v1.2.3", "", false},
+		{"suffix mismatch", "{{version}}-alpine", "1.2.3-slim", "", false},
+		{"empty version", "release-{{version}}", "release-", "", false},
+		{"template without placeholder", "release", "release", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ExtractVersion(tt.template, tt.value)
+			if ok != tt.expectMatch {
+				t.Fatalf("Expected match %v, got %v", tt.expectMatch, ok)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractVersionMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		value       string
+		expected    string
+		expectMatch bool
+	}{
+		{"named group", `^immich-v(?P<version>\d+\.\d+\.\d+)$`, "immich-v2.5.3", "2.5.3", true},
+		{"first group", `^(\d+\.\d+\.\d+)-php`, "6.2.2-php8.2-apache", "6.2.2", true},
+		{"named group wins over first group", `^(?P<app>\w+)-(?P<version>\d+\.\d+\.\d+)$`, "app-1.2.3", "1.2.3", true},
+		{"whole match without groups", `\d+\.\d+\.\d+`, "release 1.2.3 final", "1.2.3", true},
+		{"no match", `^immich-v(\d+\.\d+\.\d+)$`, "2.5.3", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, _, ok := ExtractVersionMatch(regexp.MustCompile(tt.pattern), tt.value)
+			if ok != tt.expectMatch {
+				t.Fatalf("Expected match %v, got %v", tt.expectMatch, ok)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, version)
+			}
+		})
+	}
+}
+
+func TestReplaceVersionMatch(t *testing.T) {
+	pattern := regexp.MustCompile(`^(?P<version>\d+\.\d+\.\d+)-(?P<variant>.+)$`)
+
+	result := ReplaceVersionMatch(pattern, "6.2.2-php8.2-apache", "6.3.0")
+	if result != "6.3.0-php8.2-apache" {
+		t.Errorf("Expected %q, got %q", "6.3.0-php8.2-apache", result)
+	}
+
+	result = ReplaceVersionMatch(pattern, "latest", "6.3.0")
+	if result != "latest" {
+		t.Errorf("Expected unmatched value to be unchanged, got %q", result)
+	}
+}
+
+func TestRenderWriteTemplate(t *testing.T) {
+	pattern := regexp.MustCompile(`^(?P<version>\d+\.\d+\.\d+)-php(?P<php>[\d.]+)-(?P<server>\w+)$`)
+	_, groups, ok := ExtractVersionMatch(pattern, "6.2.2-php8.2-apache")
+	if !ok {
+		t.Fatal("Expected pattern to match")
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{{version}}-php{{php}}-{{server}}", "6.3.0-php8.2-apache"},
+		{"{{version}}-php{{2}}-fpm", "6.3.0-php8.2-fpm"},
+		{"{{version}}", "6.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			result := RenderWriteTemplate(tt.template, "6.3.0", groups)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	newVersion := func(version string) *PackageSourceVersion {
+		v := &PackageSourceVersion{Version: version}
+		v.MajorVersion, v.MinorVersion, v.PatchVersion = ParseSemver(version)
+		v.BuildVersion, v.Revision = ParseBuild(version)
+		return v
+	}
+	versions := []*PackageSourceVersion{
+		newVersion("1.9.0"), newVersion("1.10.0-rc.1"), newVersion("1.10.0"), newVersion("v2.0.0"),
+		newVersion("1.9.0.2"), newVersion("1.9.0-2023-11-01"),
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected []string
+	}{
+		{"", []string{"v2.0.0", "1.10.0-rc.1", "1.10.0", "1.9.0.2", "1.9.0-2023-11-01", "1.9.0"}},
+		{"semantic", []string{"v2.0.0", "1.10.0-rc.1", "1.10.0", "1.9.0.2", "1.9.0-2023-11-01", "1.9.0"}},
+		{"alphabetical", []string{"v2.0.0", "1.9.0.2", "1.9.0-2023-11-01", "1.9.0", "1.10.0-rc.1", "1.10.0"}},
+		{"date", []string{"1.9.0", "1.10.0-rc.1", "1.10.0", "v2.0.0", "1.9.0.2", "1.9.0-2023-11-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			sorted := SortVersions(versions, &PackageSource{SortBy: tt.sortBy})
+			var result []string
+			for _, v := range sorted {
+				result = append(result, v.Version)
+			}
+			if strings.Join(result, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+	if versions[0].Version != "1.9.0" {
+		t.Errorf("SortVersions modified its input")
+	}
+}
+
+func TestPackageSource_LatestVersion(t *testing.T) {
+	source := &PackageSource{
+		VersionConstraint: "<2.0.0",
+		Versions: []*PackageSourceVersion{
+			{Version: "1.2.0", MajorVersion: 1, MinorVersion: 2},
+			{Version: "2.0.0", MajorVersion: 2},
+			{Version: "1.3.0", MajorVersion: 1, MinorVersion: 3},
+		},
+	}
+	if latest := source.LatestVersion(); latest == nil || latest.Version != "1.3.0" {
+		t.Errorf("Expected 1.3.0, got %v", latest)
+	}
+	if latest := (&PackageSource{}).LatestVersion(); latest != nil {
+		t.Errorf("Expected no version, got %v", latest)
+	}
+}
+
+func TestParseBuild(t *testing.T) {
+	tests := []struct {
+		version          string
+		expectedBuild    int
+		expectedRevision string
+	}{
+		{"1.2.3", 0, ""},
+		{"1.2.3.4", 4, ""},
+		{"v10.0.19041.1", 1, ""},
+		{"7.4.0-2023-11-01", 0, "2023-11-01"},
+		{"1.2.3_2", 0, "2"},
+		{"1.2.3.4-1", 4, "1"},
+		{"1.2.3-beta1", 0, ""},
+		{"1.2.3-alpine", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			build, revision := ParseBuild(tt.version)
+			if build != tt.expectedBuild || revision != tt.expectedRevision {
+				t.Errorf("Expected (%d, %q), got (%d, %q)", tt.expectedBuild, tt.expectedRevision, build, revision)
+			}
+		})
+	}
+}
+
+func TestCompareRevisions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"2023-11-01", "2023-12-01", -1},
+		{"2023-12-01", "2023-11-01", 1},
+		{"10", "2", 1},
+		{"02", "2", 0},
+		{"", "1", -1},
+		{"1", "1.1", -1},
+		{"", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if result := CompareRevisions(tt.a, tt.b); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCompareLooseVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"v1.0", "1.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.0.1", "1.0", 1},
+		{"1:0.9", "2.0", 1},
+		{"2.0", "1:0.9", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0+", -1},
+		{"RELEASE.2023-11-01T01-57-10Z", "RELEASE.2023-10-25T06-33-25Z", 1},
+		{"r1095", "r987", 1},
+		{"2.4.57-2", "2.4.57-10", -1},
+		{"007", "7", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if result := CompareLooseVersions(tt.a, tt.b); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func BenchmarkSortVersions(b *testing.B) {
+	versions := make([]*PackageSourceVersion, 0, 5000)
+	for i := 0; i < cap(versions); i++ {
+		v := &PackageSourceVersion{Version: fmt.Sprintf("v%d.%d.%d", i%7, (i/7)%30, i%23)}
+		v.MajorVersion, v.MinorVersion, v.PatchVersion = ParseSemver(v.Version)
+		v.BuildVersion, v.Revision = ParseBuild(v.Version)
+		versions = append(versions, v)
+	}
+
+	for _, sortBy := range []string{"semantic", "alphabetical"} {
+		b.Run(sortBy, func(b *testing.B) {
+			source := &PackageSource{SortBy: sortBy}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				SortVersions(versions, source)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/actions"
+)
+
+// writeConfig writes an updater configuration outside the repository that updates the image
+// tag in values.yaml from the registry of the harness
+func writeConfig(t *testing.T, h *Harness) string {
+	t.Helper()
+	config := fmt.Sprintf(`packageSourceProviders:
+  - name: registry
+    type: docker
+    baseUrl: %s
+
+packageSources:
+  - name: app
+    provider: registry
+    type: docker-image
+    uri: %s/acme/app
+    tagPattern: "^\\d+\\.\\d+\\.\\d+$"
+
+targetActor:
+  name: Updater Bot
+  email: updater@example.com
+  username: updater-bot
+  token: test-token
+
+targets:
+  - name: app
+    type: yaml-field
+    file: %s
+    patchGroup: app
+    labels: [dependencies]
+    items:
+      - yamlPath: image.tag
+        source: app
+`, h.URL(), h.Registry(), filepath.Join(h.WorkDir, "values.yaml"))
+
+	path := filepath.Join(t.TempDir(), ".updater.yml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+	return path
+}
+
+// apply runs apply as the command line does with its default flags
+func apply(configPath string) error {
+	return actions.Apply(&actions.ApplyOptions{
+		ConfigPath:   configPath,
+		OutputFormat: "table",
+		Limit:        10,
+		Only:         "all",
+		OnConflict:   "warn",
+		Parallel:     1,
+	})
+}
+
+func TestApply_CreatesPullRequest(t *testing.T) {
+	h := New(t, map[string]string{"values.yaml": "image:\n  repository: acme/app\n  tag: 1.0.0\n"})
+	h.SetTags("acme/app", "1.0.0", "1.1.0", "1.2.0", "latest", "2.0.0-rc.1")
+
+	if err := apply(writeConfig(t, h)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if branches := h.RemoteBranches(); !reflect.DeepEqual(branches, []string{"chore/update/app", "main"}) {
+		t.Fatalf("expected the update branch to be pushed, got branches %v", branches)
+	}
+	if content := h.RemoteFile("chore/update/app", "values.yaml"); !strings.Contains(content, "tag: 1.2.0") {
+		t.Errorf("expected the update branch to set tag 1.2.0, got:\n%s", content)
+	}
+	if content := h.RemoteFile("main", "values.yaml"); !strings.Contains(content, "tag: 1.0.0") {
+		t.Errorf("expected main to be unchanged, got:\n%s", content)
+	}
+	// Updates are applied in a worktree, the working directory stays untouched
+	if content := h.ReadFile("values.yaml"); !strings.Contains(content, "tag: 1.0.0") {
+		t.Errorf("expected the working directory to be unchanged, got:\n%s", content)
+	}
+	if branch := h.Git("rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("expected the working directory to stay on main, got %s", branch)
+	}
+	if author := h.Git("log", "-1", "--format=%an <%ae>", "origin/chore/update/app"); author != "Updater Bot <updater@example.com>" {
+		t.Errorf("expected the update to be committed by the target actor, got %s", author)
+	}
+
+	pulls := h.PullRequests()
+	if len(pulls) != 1 {
+		t.Fatalf("expected 1 pull request, got %d", len(pulls))
+	}
+	pull := pulls[0]
+	if pull.Head != "chore/update/app" || pull.Base != "main" {
+		t.Errorf("expected pull request from chore/update/app into main, got %s into %s", pull.Head, pull.Base)
+	}
+	if !strings.Contains(pull.Body, "1.0.0") || !strings.Contains(pull.Body, "1.2.0") {
+		t.Errorf("expected the pull request body to list the update, got:\n%s", pull.Body)
+	}
+	if !reflect.DeepEqual(pull.Labels, []string{"dependencies"}) {
+		t.Errorf("expected label dependencies, got %v", pull.Labels)
+	}
+}
+
+func TestApply_UpdatesExistingPullRequest(t *testing.T) {
+	h := New(t, map[string]string{"values.yaml": "image:\n  tag: 1.0.0\n"})
+	h.SetTags("acme/app", "1.0.0", "1.1.0")
+	configPath := writeConfig(t, h)
+
+	if err := apply(configPath); err != nil {
+		t.Fatalf("first Apply() error = %v", err)
+	}
+
+	// A newer release before the pull request is merged moves the same branch forward
+	h.SetTags("acme/app", "1.0.0", "1.1.0", "1.2.0")
+	if err := apply(configPath); err != nil {
+		t.Fatalf("second Apply() error = %v", err)
+	}
+
+	pulls := h.PullRequests()
+	if len(pulls) != 1 {
+		t.Fatalf("expected the pull request to be reused, got %d pull requests", len(pulls))
+	}
+	if !strings.Contains(pulls[0].Body, "1.2.0") {
+		t.Errorf("expected the pull request body to be updated to 1.2.0, got:\n%s", pulls[0].Body)
+	}
+	if content := h.RemoteFile("chore/update/app", "values.yaml"); !strings.Contains(content, "tag: 1.2.0") {
+		t.Errorf("expected the update branch to set tag 1.2.0, got:\n%s", content)
+	}
+	if commits := h.run(h.remote, "rev-list", "--count", "main..chore/update/app"); commits != "2" {
+		t.Errorf("expected 2 update commits on the branch, got %s", commits)
+	}
+}
+
+func TestApply_UpToDate(t *testing.T) {
+	h := New(t, map[string]string{"values.yaml": "image:\n  tag: 1.1.0\n"})
+	h.SetTags("acme/app", "1.0.0", "1.1.0")
+
+	if err := apply(writeConfig(t, h)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if branches := h.RemoteBranches(); !reflect.DeepEqual(branches, []string{"main"}) {
+		t.Errorf("expected no update branch, got branches %v", branches)
+	}
+	if pulls := h.PullRequests(); len(pulls) != 0 {
+		t.Errorf("expected no pull request, got %d", len(pulls))
+	}
+}
@@ -0,0 +1,360 @@
+// Package e2e runs updater end to end against local stand-ins of the services it talks to: a
+// git server, a container registry and the GitHub pull request API, all served by one TLS
+// test server. It is only imported by tests.
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// Owner and repository name of the repository served by the harness
+const (
+	Owner = "acme"
+	Repo  = "app"
+)
+
+// Harness is a git repository cloned from a local git server, with a container registry and
+// a GitHub API for pull requests served alongside
+type Harness struct {
+	t       testing.TB
+	server  *httptest.Server
+	remote  string // Bare repository served by the git server
+	WorkDir string // Clone of the repository updater runs in
+
+	mu    sync.Mutex
+	tags  map[string][]string // Registry tags by repository
+	pulls []*PullRequest
+}
+
+// PullRequest is a pull request opened on the fake GitHub API
+type PullRequest struct {
+	Number    int
+	URL       string
+	Title     string
+	Body      string
+	Head      string // Branch of the changes
+	Base      string // Branch the changes are merged into
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+}
+
+// apiObject returns the pull request as the GitHub API represents it
+func (p *PullRequest) apiObject() map[string]any {
+	labels := make([]map[string]string, 0, len(p.Labels))
+	for _, label := range p.Labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	return map[string]any{
+		"number":   p.Number,
+		"node_id":  fmt.Sprintf("PR_%d", p.Number),
+		"html_url": p.URL,
+		"state":    "open",
+		"title":    p.Title,
+		"body":     p.Body,
+		"head":     map[string]string{"ref": p.Head},
+		"base":     map[string]string{"ref": p.Base},
+		"labels":   labels,
+	}
+}
+
+// New starts the servers and clones a repository holding files, committed on main. git and
+// git-http-backend must be installed, the test is skipped otherwise. The HTTP transport of
+// updater trusts the test server until the test ends.
+func New(t testing.TB, files map[string]string) *Harness {
+	t.Helper()
+	backend := gitHTTPBackend(t)
+
+	root := t.TempDir()
+	h := &Harness{
+		t:       t,
+		remote:  filepath.Join(root, "server", Owner, Repo+".git"),
+		WorkDir: filepath.Join(root, "work"),
+		tags:    make(map[string][]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/", h.serveAPI)
+	mux.HandleFunc("/v2/", h.serveRegistry)
+	mux.Handle("/", &cgi.Handler{
+		Path: backend,
+		Env:  []string{"GIT_PROJECT_ROOT=" + filepath.Join(root, "server"), "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	h.server = httptest.NewTLSServer(mux)
+	t.Cleanup(h.server.Close)
+
+	// git trusts the test server through the environment, updater through its transport
+	t.Setenv("GIT_SSL_NO_VERIFY", "true")
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(root, "gitconfig"))
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = h.server.Client().Transport
+	util.ConfigureHTTPTransport("e2e", false)
+
+	// Only failures are of interest in test output
+	logLevel := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	util.SetQuiet(true)
+
+	t.Cleanup(func() {
+		http.DefaultTransport = defaultTransport
+		util.ConfigureHTTPTransport("dev", false)
+		zerolog.SetGlobalLevel(logLevel)
+		util.SetQuiet(false)
+	})
+
+	h.run(root, "init", "-q", "--bare", "--initial-branch=main", h.remote)
+	h.run(h.remote, "config", "http.receivepack", "true")
+	h.run(root, "clone", "-q", h.RepoURL(), h.WorkDir)
+	h.run(h.WorkDir, "checkout", "-q", "-b", "main")
+	for name, content := range files {
+		h.WriteFile(name, content)
+	}
+	h.Git("add", ".")
+	h.Git("commit", "-q", "-m", "initial")
+	h.Git("push", "-q", "-u", "origin", "main")
+	h.Git("remote", "set-head", "origin", "main")
+
+	return h
+}
+
+// gitHTTPBackend returns the path of git-http-backend, skipping the test without it
+func gitHTTPBackend(t testing.TB) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	output, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skipf("git exec path not available: %v", err)
+	}
+	backend := filepath.Join(strings.TrimSpace(string(output)), "git-http-backend")
+	if _, err := os.Stat(backend); err != nil {
+		t.Skip("git-http-backend not available")
+	}
+	return backend
+}
+
+// URL returns the base URL of the test server
+func (h *Harness) URL() string {
+	return h.server.URL
+}
+
+// RepoURL returns the clone URL of the repository, from which updater derives the GitHub
+// API at /api/v3 of the same server like for GitHub Enterprise
+func (h *Harness) RepoURL() string {
+	return fmt.Sprintf("%s/%s/%s.git", h.server.URL, Owner, Repo)
+}
+
+// Registry returns the host of the container registry, e.g. for image references
+func (h *Harness) Registry() string {
+	return strings.TrimPrefix(h.server.URL, "https://")
+}
+
+// SetTags sets the tags the registry lists for a repository, e.g. acme/app
+func (h *Harness) SetTags(repository string, tags ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tags[repository] = tags
+}
+
+// PullRequests returns the pull requests opened so far
+func (h *Harness) PullRequests() []*PullRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*PullRequest{}, h.pulls...)
+}
+
+// WriteFile writes a file of the working directory
+func (h *Harness) WriteFile(name string, content string) {
+	h.t.Helper()
+	path := filepath.Join(h.WorkDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.t.Fatalf("failed to create directory of %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		h.t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// ReadFile returns a file of the working directory
+func (h *Harness) ReadFile(name string) string {
+	h.t.Helper()
+	data, err := os.ReadFile(filepath.Join(h.WorkDir, name))
+	if err != nil {
+		h.t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+// RemoteFile returns a file at the tip of a branch of the git server
+func (h *Harness) RemoteFile(branch string, name string) string {
+	h.t.Helper()
+	return h.run(h.remote, "show", branch+":"+name)
+}
+
+// RemoteBranches returns the branches of the git server
+func (h *Harness) RemoteBranches() []string {
+	h.t.Helper()
+	return strings.Fields(h.run(h.remote, "for-each-ref", "--format=%(refname:short)", "refs/heads"))
+}
+
+// Git runs git in the working directory and returns its output
+func (h *Harness) Git(args ...string) string {
+	h.t.Helper()
+	return h.run(h.WorkDir, args...)
+}
+
+func (h *Harness) run(dir string, args ...string) string {
+	h.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		h.t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// registryTagsPath matches the tag list endpoint of the registry API
+var registryTagsPath = regexp.MustCompile(`^/v2/(.+)/tags/list$`)
+
+// serveRegistry serves the tag lists of the Docker Registry HTTP API V2 without auth
+func (h *Harness) serveRegistry(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	match := registryTagsPath.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.mu.Lock()
+	tags, ok := h.tags[match[1]]
+	h.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"errors": []map[string]string{{"code": "NAME_UNKNOWN"}}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"name": match[1], "tags": tags})
+}
+
+// apiPullPath matches the pull request and issue endpoints of the GitHub API
+var apiPullPath = regexp.MustCompile(`^/api/v3/repos/` + Owner + `/` + Repo + `/(pulls|issues)(?:/(\d+)(?:/(files|labels|requested_reviewers|assignees))?)?$`)
+
+// serveAPI serves the GitHub API endpoints updater uses to open and update pull requests
+func (h *Harness) serveAPI(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "token ") {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Requires authentication"})
+		return
+	}
+	match := apiPullPath.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	var request struct {
+		Title     string   `json:"title"`
+		Body      string   `json:"body"`
+		Head      string   `json:"head"`
+		Base      string   `json:"base"`
+		Labels    []string `json:"labels"`
+		Reviewers []string `json:"reviewers"`
+		Assignees []string `json:"assignees"`
+	}
+	if r.Body != nil && r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if match[2] == "" {
+		switch {
+		case match[1] == "pulls" && r.Method == http.MethodGet:
+			head := strings.TrimPrefix(r.URL.Query().Get("head"), Owner+":")
+			base := r.URL.Query().Get("base")
+			open := make([]map[string]any, 0)
+			for _, pull := range h.pulls {
+				if (head == "" || pull.Head == head) && (base == "" || pull.Base == base) {
+					open = append(open, pull.apiObject())
+				}
+			}
+			writeJSON(w, http.StatusOK, open)
+		case match[1] == "pulls" && r.Method == http.MethodPost:
+			number := len(h.pulls) + 1
+			pull := &PullRequest{
+				Number: number,
+				URL:    fmt.Sprintf("%s/%s/%s/pull/%d", h.server.URL, Owner, Repo, number),
+				Title:  request.Title,
+				Body:   request.Body,
+				Head:   request.Head,
+				Base:   request.Base,
+			}
+			h.pulls = append(h.pulls, pull)
+			writeJSON(w, http.StatusCreated, pull.apiObject())
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Method Not Allowed"})
+		}
+		return
+	}
+
+	number, _ := strconv.Atoi(match[2])
+	if number < 1 || number > len(h.pulls) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	pull := h.pulls[number-1]
+
+	switch match[3] {
+	case "":
+		if r.Method == http.MethodPatch {
+			pull.Title, pull.Body = request.Title, request.Body
+		}
+		writeJSON(w, http.StatusOK, pull.apiObject())
+	case "files":
+		// Changed files are not tracked, so pull requests never conflict
+		writeJSON(w, http.StatusOK, []any{})
+	case "labels":
+		pull.Labels = append(pull.Labels, request.Labels...)
+		writeJSON(w, http.StatusOK, []any{})
+	case "requested_reviewers":
+		pull.Reviewers = append(pull.Reviewers, request.Reviewers...)
+		writeJSON(w, http.StatusCreated, pull.apiObject())
+	case "assignees":
+		pull.Assignees = append(pull.Assignees, request.Assignees...)
+		writeJSON(w, http.StatusCreated, pull.apiObject())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
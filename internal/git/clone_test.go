@@ -0,0 +1,89 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	upstream := filepath.Join(root, "upstream")
+	clone := filepath.Join(root, "clones", "team", "platform")
+	run := func(workDir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(content string) {
+		if err := os.WriteFile(filepath.Join(upstream, "values.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		run(upstream, "add", ".")
+		run(upstream, "commit", "-q", "-m", content)
+		run(upstream, "push", "-q", "origin", "main")
+	}
+
+	run(root, "init", "-q", "--bare", "--initial-branch=main", remote)
+	run(root, "clone", "-q", remote, upstream)
+	run(upstream, "checkout", "-q", "-b", "main")
+	commit("tag: 1.0.0\n")
+
+	if err := SyncClone(remote, "", clone, "secret-token"); err != nil {
+		t.Fatalf("SyncClone() failed: %v", err)
+	}
+	if branch := run(clone, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("Expected the default branch to be checked out, got %s", branch)
+	}
+	if header := run(clone, "config", "--local", "http.extraHeader"); !strings.HasPrefix(header, "AUTHORIZATION: basic ") {
+		t.Errorf("Expected the token to be configured as auth header, got %q", header)
+	}
+	if url := run(clone, "remote", "get-url", "origin"); strings.Contains(url, "secret-token") {
+		t.Errorf("Token must not be part of the remote URL: %s", url)
+	}
+
+	// Leftovers of an earlier run are discarded and new commits are picked up
+	commit("tag: 1.1.0\n")
+	if err := os.WriteFile(filepath.Join(clone, "values.yaml"), []byte("tag: local\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "untracked.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	run(clone, "checkout", "-q", "-b", "chore/update/prod")
+
+	if err := SyncClone(remote, "main", clone, ""); err != nil {
+		t.Fatalf("SyncClone() failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(clone, "values.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	if string(content) != "tag: 1.1.0\n" {
+		t.Errorf("Expected the remote branch state, got %q", content)
+	}
+	if exists(filepath.Join(clone, "untracked.txt")) {
+		t.Errorf("Expected untracked files to be removed")
+	}
+	if branch := run(clone, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("Expected main to be checked out, got %s", branch)
+	}
+	if output, err := exec.Command("git", "-C", clone, "config", "--local", "http.extraHeader").Output(); err == nil {
+		t.Errorf("Expected the auth header to be removed without token, got %q", output)
+	}
+}
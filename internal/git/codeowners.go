@@ -0,0 +1,137 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersLocations lists where GitHub looks for CODEOWNERS, in order of precedence
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners holds the rules of a CODEOWNERS file
+type CodeOwners struct {
+	rules []*codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// LoadCodeOwners reads the CODEOWNERS file of a repository. It returns nil without error if
+// the repository has none.
+func LoadCodeOwners(repoRoot string) (*CodeOwners, error) {
+	for _, location := range codeOwnersLocations {
+		file, err := os.Open(filepath.Join(repoRoot, location))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", location, err)
+		}
+		defer file.Close()
+
+		owners, err := ParseCodeOwners(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", location, err)
+		}
+		return owners, nil
+	}
+	return nil, nil
+}
+
+// ParseCodeOwners parses CODEOWNERS content. Lines without owners reset ownership of their
+// pattern, as on GitHub.
+func ParseCodeOwners(reader io.Reader) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx != -1 {
+			line = line[:idx]
+		}
+
+		fields := strings.Fields(line)
+		pattern, err := codeOwnersPatternRegex(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		codeOwners.rules = append(codeOwners.rules, &codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return codeOwners, nil
+}
+
+// Owners returns the owners of a path relative to the repository root. The last matching
+// rule wins.
+func (c *CodeOwners) Owners(relPath string) []string {
+	if c == nil {
+		return nil
+	}
+	relPath = strings.TrimPrefix(filepath.ToSlash(relPath), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(relPath) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPatternRegex converts a CODEOWNERS pattern (gitignore syntax) to a regex matching
+// repository-relative paths. Patterns with a leading or inner slash are anchored at the root,
+// others match at any depth; a pattern also matches everything below a matching directory.
+func codeOwnersPatternRegex(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	directoryOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("(^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if directoryOnly {
+		sb.WriteString("/")
+	} else {
+		sb.WriteString("(/|$)")
+	}
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid CODEOWNERS pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	content := `# Default owners
+*                       @org/platform
+*.tf                    @org/infra
+/apps/web/              @org/web @alice
+apps/**/values.yaml     @org/helm
+docs/                   @org/docs
+/apps/web/legacy.yaml
+charts/*/Chart.yaml     @bob # chart maintainers
+`
+	codeOwners, err := ParseCodeOwners(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseCodeOwners() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "README.md", expected: []string{"@org/platform"}},
+		{path: "infra/main.tf", expected: []string{"@org/infra"}},
+		{path: "apps/web/deployment.yaml", expected: []string{"@org/web", "@alice"}},
+		{path: "apps/web/values.yaml", expected: []string{"@org/helm"}},
+		{path: "apps/api/prod/values.yaml", expected: []string{"@org/helm"}},
+		{path: "services/docs/index.md", expected: []string{"@org/docs"}},
+		{path: "apps/web/legacy.yaml", expected: []string{}},
+		{path: "charts/app/Chart.yaml", expected: []string{"@bob"}},
+		{path: "charts/app/sub/Chart.yaml", expected: []string{"@org/platform"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := codeOwners.Owners(tt.path); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Owners(%s) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadCodeOwners(t *testing.T) {
+	root := t.TempDir()
+
+	codeOwners, err := LoadCodeOwners(root)
+	if err != nil || codeOwners != nil {
+		t.Fatalf("Expected no CODEOWNERS, got %v, %v", codeOwners, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, ".github"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("* @org/platform\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	codeOwners, err = LoadCodeOwners(root)
+	if err != nil {
+		t.Fatalf("LoadCodeOwners() error = %v", err)
+	}
+	if owners := codeOwners.Owners("values.yaml"); !reflect.DeepEqual(owners, []string{"@org/platform"}) {
+		t.Errorf("Unexpected owners: %v", owners)
+	}
+}
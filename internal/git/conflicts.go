@@ -0,0 +1,175 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// PullRequestFile is a file changed by a pull request together with its unified diff
+type PullRequestFile struct {
+	Filename string `json:"filename"`
+	Patch    string `json:"patch"`
+}
+
+// ListOpenPullRequests lists all open pull requests against the base branch
+func (c *GitHubClient) ListOpenPullRequests(baseBranch string) ([]PullRequest, error) {
+	var all []PullRequest
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&base=%s&per_page=100&page=%d",
+			c.BaseURL, c.Owner, c.Repo, baseBranch, page)
+
+		var prs []PullRequest
+		if err := c.getJSON(url, &prs); err != nil {
+			return nil, fmt.Errorf("failed to list open pull requests: %w", err)
+		}
+		all = append(all, prs...)
+		if len(prs) < 100 {
+			break
+		}
+	}
+
+	log.Debug().Int("count", len(all)).Str("base", baseBranch).Msg("Listed open pull requests")
+	return all, nil
+}
+
+// ListCommitPullRequests lists the pull requests a commit belongs to, e.g. the merged pull
+// request that introduced it to the base branch
+func (c *GitHubClient) ListCommitPullRequests(sha string) ([]PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s/pulls", c.BaseURL, c.Owner, c.Repo, sha)
+
+	var prs []PullRequest
+	if err := c.getJSON(url, &prs); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests of commit %s: %w", sha, err)
+	}
+	return prs, nil
+}
+
+// ListPullRequestFiles lists the files changed by a pull request
+func (c *GitHubClient) ListPullRequestFiles(prNumber int) ([]PullRequestFile, error) {
+	var all []PullRequestFile
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=100&page=%d",
+			c.BaseURL, c.Owner, c.Repo, prNumber, page)
+
+		var files []PullRequestFile
+		if err := c.getJSON(url, &files); err != nil {
+			return nil, fmt.Errorf("failed to list files of pull request #%d: %w", prNumber, err)
+		}
+		all = append(all, files...)
+		if len(files) < 100 {
+			break
+		}
+	}
+	return all, nil
+}
+
+// getJSON sends an authenticated GET request and decodes the JSON response
+func (c *GitHubClient) getJSON(url string, target interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	client := util.NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, string(responseBody))
+	}
+
+	if err := json.Unmarshal(responseBody, target); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// DiffAgainstBase returns the unified diff without context of a file between the base
+// branch and HEAD
+func (r *Repository) DiffAgainstBase(relPath string) (string, error) {
+	cmd := exec.Command("git", "diff", "--unified=0", fmt.Sprintf("%s...HEAD", r.BaseBranch), "--", relPath)
+	cmd.Dir = r.WorkingDirectory
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s against %s: %w, output: %s", relPath, r.BaseBranch, err, string(output))
+	}
+	return string(output), nil
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// ChangedLines returns the line numbers in the original file that a unified diff removes
+// or inserts before. Context lines are not included.
+func ChangedLines(patch string) []int {
+	var lines []int
+	oldLine := 0
+	inHunk := false
+	replacing := false // Added lines directly after removed lines replace them
+	for _, line := range strings.Split(patch, "\n") {
+		if match := hunkHeaderRegex.FindStringSubmatch(line); match != nil {
+			oldLine, _ = strconv.Atoi(match[1])
+			inHunk = true
+			replacing = false
+			// A pure insertion reports the line after which it inserts
+			if strings.HasPrefix(line, "@@ -"+match[1]+",0 ") {
+				oldLine++
+			}
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			lines = append(lines, oldLine)
+			oldLine++
+			replacing = true
+		case '+':
+			if !replacing {
+				lines = append(lines, oldLine)
+			}
+		case ' ':
+			oldLine++
+			replacing = false
+		case '\\': // "\ No newline at end of file"
+		default:
+			inHunk = false
+		}
+	}
+	return lines
+}
+
+// LinesConflict reports whether two sets of changed lines of the same file touch the same
+// or adjacent lines, which git cannot merge automatically
+func LinesConflict(a, b []int) bool {
+	for _, lineA := range a {
+		for _, lineB := range b {
+			if lineA-lineB <= 1 && lineB-lineA <= 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected []int
+	}{
+		{
+			name: "replaced line without context",
+			patch: `diff --git a/values.yaml b/values.yaml
+--- a/values.yaml
++++ b/values.yaml
+@@ -3 +3 @@ image:
+-  tag: 1.24.0
++  tag: 1.25.0`,
+			expected: []int{3},
+		},
+		{
+			name: "pull request patch with context",
+			patch: `@@ -1,5 +1,5 @@
+ image:
+   repository: nginx
+-  tag: 1.24.0
++  tag: 1.24.1
+ replicas: 2
+@@ -10,2 +10,3 @@ resources:
+   limits:
++    cpu: 100m
+     memory: 128Mi`,
+			expected: []int{3, 11},
+		},
+		{
+			name:     "pure insertion",
+			patch:    "@@ -7,0 +8 @@\n+extra: true",
+			expected: []int{8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangedLines(tt.patch); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ChangedLines() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLinesConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        []int
+		b        []int
+		expected bool
+	}{
+		{name: "same line", a: []int{3}, b: []int{3}, expected: true},
+		{name: "adjacent lines", a: []int{3}, b: []int{4}, expected: true},
+		{name: "distant lines", a: []int{3}, b: []int{10, 20}, expected: false},
+		{name: "no changes", a: nil, b: []int{3}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LinesConflict(tt.a, tt.b); got != tt.expected {
+				t.Errorf("LinesConflict() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
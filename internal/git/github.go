@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 )
 
@@ -197,7 +199,7 @@ func (c *GitHubClient) CreatePullRequest(options *PullRequestOptions) (string, e
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := util.NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -219,6 +221,7 @@ func (c *GitHubClient) CreatePullRequest(options *PullRequestOptions) (string, e
 	var prResponse struct {
 		HTMLURL string `json:"html_url"`
 		Number  int    `json:"number"`
+		NodeID  string `json:"node_id"`
 	}
 
 	if err := json.Unmarshal(responseBody, &prResponse); err != nil {
@@ -237,20 +240,42 @@ func (c *GitHubClient) CreatePullRequest(options *PullRequestOptions) (string, e
 		}
 	}
 
+	c.addReviewersAndAssignees(prResponse.Number, options)
+	c.linkPlanning(prResponse.Number, prResponse.NodeID, options)
+
 	return prResponse.HTMLURL, nil
 }
 
 // PullRequest represents a GitHub pull request
 type PullRequest struct {
 	Number  int    `json:"number"`
+	NodeID  string `json:"node_id"`
 	HTMLURL string `json:"html_url"`
 	State   string `json:"state"`
-	Head    struct {
+	Title   string `json:"title"`
+	User    struct {
+		Login string `json:"login"`
+		Type  string `json:"type"` // "User" or "Bot"
+	} `json:"user"`
+	Head struct {
 		Ref string `json:"ref"`
 	} `json:"head"`
 	Base struct {
 		Ref string `json:"ref"`
 	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// HasLabel reports whether the pull request carries the given label
+func (pr *PullRequest) HasLabel(label string) bool {
+	for _, l := range pr.Labels {
+		if strings.EqualFold(l.Name, label) {
+			return true
+		}
+	}
+	return false
 }
 
 // FindOpenPullRequest finds an open PR for the given branch
@@ -271,7 +296,7 @@ func (c *GitHubClient) FindOpenPullRequest(headBranch string) (*PullRequest, err
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := util.NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -333,7 +358,7 @@ func (c *GitHubClient) UpdatePullRequest(prNumber int, options *PullRequestOptio
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := util.NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
@@ -350,6 +375,13 @@ func (c *GitHubClient) UpdatePullRequest(prNumber int, options *PullRequestOptio
 
 	log.Debug().Int("number", prNumber).Msg("Updated pull request")
 
+	var prResponse struct {
+		NodeID string `json:"node_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&prResponse); err != nil {
+		log.Debug().Err(err).Msg("Failed to parse updated pull request")
+	}
+
 	// Update labels if specified
 	if len(options.Labels) > 0 {
 		if err := c.addLabels(prNumber, options.Labels); err != nil {
@@ -357,6 +389,9 @@ func (c *GitHubClient) UpdatePullRequest(prNumber int, options *PullRequestOptio
 		}
 	}
 
+	c.addReviewersAndAssignees(prNumber, options)
+	c.linkPlanning(prNumber, prResponse.NodeID, options)
+
 	return nil
 }
 
@@ -386,7 +421,7 @@ func (c *GitHubClient) addLabels(prNumber int, labels []string) error {
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := util.NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
@@ -405,3 +440,178 @@ func (c *GitHubClient) addLabels(prNumber int, labels []string) error {
 
 	return nil
 }
+
+// addReviewersAndAssignees requests the reviewers and adds the assignees of the options to
+// a pull request. Failures are logged, since a missing reviewer must not fail the update.
+func (c *GitHubClient) addReviewersAndAssignees(prNumber int, options *PullRequestOptions) {
+	if len(options.Reviewers) > 0 {
+		if err := c.requestReviewers(prNumber, options.Reviewers); err != nil {
+			log.Warn().Err(err).Msg("Failed to request reviewers on PR")
+		}
+	}
+	if len(options.Assignees) > 0 {
+		if err := c.addAssignees(prNumber, options.Assignees); err != nil {
+			log.Warn().Err(err).Msg("Failed to add assignees to PR")
+		}
+	}
+}
+
+// requestReviewers requests reviews on a pull request from users and "org/team" teams
+func (c *GitHubClient) requestReviewers(prNumber int, reviewers []string) error {
+	log.Debug().
+		Int("pr", prNumber).
+		Strs("reviewers", reviewers).
+		Msg("Requesting reviewers on pull request")
+
+	users, teams := SplitReviewers(reviewers)
+	requestBody := map[string]interface{}{
+		"reviewers":      users,
+		"team_reviewers": teams,
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", c.BaseURL, c.Owner, c.Repo, prNumber)
+	if err := c.postJSON(url, requestBody, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to request reviewers: %w", err)
+	}
+
+	log.Debug().Strs("reviewers", reviewers).Msg("Requested reviewers on pull request")
+	return nil
+}
+
+// addAssignees assigns users to a pull request
+func (c *GitHubClient) addAssignees(prNumber int, assignees []string) error {
+	log.Debug().
+		Int("pr", prNumber).
+		Strs("assignees", assignees).
+		Msg("Adding assignees to pull request")
+
+	logins := make([]string, len(assignees))
+	for i, assignee := range assignees {
+		logins[i] = strings.TrimPrefix(assignee, "@")
+	}
+	requestBody := map[string]interface{}{
+		"assignees": logins,
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/assignees", c.BaseURL, c.Owner, c.Repo, prNumber)
+	if err := c.postJSON(url, requestBody, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to add assignees: %w", err)
+	}
+
+	log.Debug().Strs("assignees", assignees).Msg("Added assignees to pull request")
+	return nil
+}
+
+// postJSON sends an authenticated POST request with a JSON body and checks the status code
+func (c *GitHubClient) postJSON(url string, requestBody interface{}, expectedStatus int) error {
+	bodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := util.NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		responseBody, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("status: %d (could not read response body: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, string(responseBody))
+	}
+	return nil
+}
+
+// SplitReviewers splits reviewers into user logins and team slugs. Teams are written as
+// "org/team" and users as "login", both optionally prefixed with "@"; email addresses, which
+// CODEOWNERS allows, cannot be requested and are dropped.
+func SplitReviewers(reviewers []string) (users []string, teams []string) {
+	users = make([]string, 0)
+	teams = make([]string, 0)
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimPrefix(reviewer, "@")
+		switch {
+		case reviewer == "" || strings.Contains(reviewer, "@"):
+			continue
+		case strings.Contains(reviewer, "/"):
+			teams = append(teams, reviewer[strings.Index(reviewer, "/")+1:])
+		default:
+			users = append(users, reviewer)
+		}
+	}
+	return users, teams
+}
+
+// ParsePullRequestNumber extracts the pull request number from its HTML URL
+// (e.g. https://github.com/owner/repo/pull/42)
+func ParsePullRequestNumber(prURL string) (int, error) {
+	idx := strings.LastIndex(prURL, "/pull/")
+	if idx == -1 {
+		return 0, fmt.Errorf("not a pull request URL: %s", prURL)
+	}
+
+	number, err := strconv.Atoi(strings.TrimSuffix(prURL[idx+len("/pull/"):], "/"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pull request number in URL %s: %w", prURL, err)
+	}
+
+	return number, nil
+}
+
+// AddPullRequestComment adds a comment to a pull request
+func (c *GitHubClient) AddPullRequestComment(prNumber int, body string) error {
+	log.Debug().
+		Int("pr", prNumber).
+		Msg("Adding comment to pull request")
+
+	requestBody := map[string]interface{}{
+		"body": body,
+	}
+
+	bodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.BaseURL, c.Owner, c.Repo, prNumber)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := util.NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		responseBody, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("failed to add comment, status: %d (could not read response body: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("failed to add comment, status: %d, body: %s", resp.StatusCode, string(responseBody))
+	}
+
+	log.Debug().Int("number", prNumber).Msg("Added comment to pull request")
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -158,3 +159,72 @@ func TestExtractAPIBaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePullRequestNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "GitHub.com pull request",
+			url:  "https://github.com/owner/repo/pull/42",
+			want: 42,
+		},
+		{
+			name: "trailing slash",
+			url:  "https://git.example.com/owner/repo/pull/7/",
+			want: 7,
+		},
+		{
+			name:    "not a pull request",
+			url:     "https://github.com/owner/repo/issues/42",
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			url:     "https://github.com/owner/repo/pull/abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePullRequestNumber(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePullRequestNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePullRequestNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitReviewers(t *testing.T) {
+	users, teams := SplitReviewers([]string{"@alice", "bob", "@org/platform", "org/web", "dev@example.com", ""})
+
+	if !reflect.DeepEqual(users, []string{"alice", "bob"}) {
+		t.Errorf("users = %v, expected [alice bob]", users)
+	}
+	if !reflect.DeepEqual(teams, []string{"platform", "web"}) {
+		t.Errorf("teams = %v, expected [platform web]", teams)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		apiBaseURL string
+		expected   string
+	}{
+		{apiBaseURL: "https://api.github.com", expected: "https://api.github.com/graphql"},
+		{apiBaseURL: "https://github.example.com/api/v3", expected: "https://github.example.com/api/graphql"},
+	}
+
+	for _, tt := range tests {
+		if got := graphQLURL(tt.apiBaseURL); got != tt.expected {
+			t.Errorf("graphQLURL(%s) = %s, expected %s", tt.apiBaseURL, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LineCommitTime returns the committer time of the commit that last changed the given
+// 1-based line of a file. Uncommitted lines report the current time.
+func LineCommitTime(filePath string, line int) (time.Time, error) {
+	_, committed, err := LineCommit(filePath, line)
+	return committed, err
+}
+
+// LineCommit returns the SHA and committer time of the commit that last changed the given
+// 1-based line of a file. Uncommitted lines report an all-zero SHA and the current time.
+func LineCommit(filePath string, line int) (string, time.Time, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	cmd := exec.Command("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filepath.Base(absPath))
+	cmd.Dir = filepath.Dir(absPath)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to blame line %d of %s: %w", line, filePath, err)
+	}
+
+	lines := strings.Split(string(output), "\n")
+	sha := strings.Fields(lines[0] + " ")[0]
+	for _, outputLine := range lines {
+		if value, ok := strings.CutPrefix(outputLine, "committer-time "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("invalid committer time %q: %w", value, err)
+			}
+			return sha, time.Unix(seconds, 0), nil
+		}
+	}
+
+	return "", time.Time{}, fmt.Errorf("no committer time in blame output of %s", filePath)
+}
+
+// IsUncommittedSHA reports whether a SHA from git blame marks uncommitted changes
+func IsUncommittedSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
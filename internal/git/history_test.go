@@ -0,0 +1,65 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLineCommitTime(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(date string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	file := filepath.Join(dir, "values.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	run("", "init", "-q")
+	write("image:\n  repository: nginx\n  tag: 1.24.0\n")
+	run("2024-01-01T00:00:00Z", "add", "values.yaml")
+	run("2024-01-01T00:00:00Z", "commit", "-q", "-m", "initial")
+	write("image:\n  repository: nginx\n  tag: 1.25.0\n")
+	run("2024-03-01T00:00:00Z", "commit", "-q", "-am", "bump")
+
+	tests := []struct {
+		line     int
+		expected time.Time
+	}{
+		{line: 2, expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{line: 3, expected: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := LineCommitTime(file, tt.line)
+		if err != nil {
+			t.Fatalf("LineCommitTime() error = %v", err)
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("line %d: got %v, want %v", tt.line, got.UTC(), tt.expected)
+		}
+	}
+
+	if _, err := LineCommitTime(file, 10); err == nil {
+		t.Error("expected error for line beyond end of file")
+	}
+}
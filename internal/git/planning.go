@@ -0,0 +1,197 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// linkPlanning sets the milestone of a pull request and adds it to the projects of the
+// options. Failures are logged, since planning metadata must not fail the update.
+func (c *GitHubClient) linkPlanning(prNumber int, nodeID string, options *PullRequestOptions) {
+	if options.Milestone != "" {
+		if err := c.setMilestone(prNumber, options.Milestone); err != nil {
+			log.Warn().Err(err).Str("milestone", options.Milestone).Msg("Failed to set milestone on PR")
+		}
+	}
+	for _, project := range options.Projects {
+		if nodeID == "" {
+			log.Warn().Str("project", project).Msg("Unknown PR node ID, cannot add PR to project")
+			break
+		}
+		if err := c.addToProject(project, nodeID); err != nil {
+			log.Warn().Err(err).Str("project", project).Msg("Failed to add PR to project")
+		}
+	}
+}
+
+// setMilestone sets the milestone of a pull request by title or number
+func (c *GitHubClient) setMilestone(prNumber int, milestone string) error {
+	number, err := strconv.Atoi(milestone)
+	if err != nil {
+		if number, err = c.findMilestone(milestone); err != nil {
+			return err
+		}
+	}
+
+	requestBody := map[string]interface{}{
+		"milestone": number,
+	}
+	bodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.BaseURL, c.Owner, c.Repo, prNumber)
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := util.NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to set milestone, status: %d, body: %s", resp.StatusCode, string(responseBody))
+	}
+
+	log.Debug().Int("pr", prNumber).Int("milestone", number).Msg("Set milestone on pull request")
+	return nil
+}
+
+// findMilestone returns the number of the open milestone with the given title
+func (c *GitHubClient) findMilestone(title string) (int, error) {
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/milestones?state=open&per_page=100&page=%d", c.BaseURL, c.Owner, c.Repo, page)
+
+		var milestones []struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+		}
+		if err := c.getJSON(url, &milestones); err != nil {
+			return 0, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		for _, milestone := range milestones {
+			if strings.EqualFold(milestone.Title, title) {
+				return milestone.Number, nil
+			}
+		}
+		if len(milestones) < 100 {
+			return 0, fmt.Errorf("no open milestone %q in %s/%s", title, c.Owner, c.Repo)
+		}
+	}
+}
+
+// addToProject adds a pull request, identified by its GraphQL node ID, to a project
+func (c *GitHubClient) addToProject(project string, contentID string) error {
+	owner, number, err := configuration.ParseProjectReference(project)
+	if err != nil {
+		return err
+	}
+
+	// Projects belong to an organization or a user
+	var projectID string
+	for _, ownerType := range []string{"organization", "user"} {
+		var response struct {
+			Owner map[string]*struct {
+				ID string `json:"id"`
+			} `json:"owner"`
+		}
+		query := fmt.Sprintf(`query($login: String!, $number: Int!) { owner: %s(login: $login) { projectV2(number: $number) { id } } }`, ownerType)
+		if err := c.graphQL(query, map[string]interface{}{"login": owner, "number": number}, &response); err != nil {
+			log.Debug().Err(err).Str("ownerType", ownerType).Str("project", project).Msg("Project not found")
+			continue
+		}
+		if p := response.Owner["projectV2"]; p != nil && p.ID != "" {
+			projectID = p.ID
+			break
+		}
+	}
+	if projectID == "" {
+		return fmt.Errorf("project %s not found", project)
+	}
+
+	mutation := `mutation($project: ID!, $content: ID!) { addProjectV2ItemById(input: {projectId: $project, contentId: $content}) { item { id } } }`
+	if err := c.graphQL(mutation, map[string]interface{}{"project": projectID, "content": contentID}, nil); err != nil {
+		return fmt.Errorf("failed to add item to project %s: %w", project, err)
+	}
+
+	log.Debug().Str("project", project).Msg("Added pull request to project")
+	return nil
+}
+
+// graphQL sends a GraphQL request and decodes its data into target (if not nil)
+func (c *GitHubClient) graphQL(query string, variables map[string]interface{}, target interface{}) error {
+	bodyJSON, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", graphQLURL(c.BaseURL), bytes.NewBuffer(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("bearer %s", c.Token))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := util.NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, string(responseBody))
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", response.Errors[0].Message)
+	}
+	if target != nil {
+		if err := json.Unmarshal(response.Data, target); err != nil {
+			return fmt.Errorf("failed to parse response data: %w", err)
+		}
+	}
+	return nil
+}
+
+// graphQLURL derives the GraphQL endpoint from the REST API base URL
+func graphQLURL(apiBaseURL string) string {
+	if strings.HasSuffix(apiBaseURL, "/api/v3") {
+		return strings.TrimSuffix(apiBaseURL, "/v3") + "/graphql"
+	}
+	return apiBaseURL + "/graphql"
+}
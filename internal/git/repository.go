@@ -19,6 +19,15 @@ func NewRepository(workingDirectory string, targetActor *configuration.TargetAct
 	}
 }
 
+// FindRoot returns the root of the git working directory containing a file
+func FindRoot(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	return (&Repository{}).findGitRoot(absPath)
+}
+
 // DetectRepository detects git repository information from a file path
 func (r *Repository) DetectRepository(filePath string) error {
 	log.Debug().Str("file", filePath).Msg("Detecting git repository for file")
@@ -70,7 +79,14 @@ func (r *Repository) findGitRoot(startPath string) (string, error) {
 
 	for {
 		gitDir := filepath.Join(dir, ".git")
+		if isDirectory(gitDir) {
+			return dir, nil
+		}
 		if exists(gitDir) {
+			// Worktrees and submodules have a .git file pointing at the real git directory
+			if _, err := readGitFile(gitDir); err != nil {
+				return "", err
+			}
 			return dir, nil
 		}
 
@@ -82,6 +98,27 @@ func (r *Repository) findGitRoot(startPath string) (string, error) {
 	}
 }
 
+// readGitFile resolves the git directory a .git file of a worktree or submodule points at
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return "", fmt.Errorf("invalid .git file %s: missing gitdir", path)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	if !isDirectory(gitDir) {
+		return "", fmt.Errorf("invalid .git file %s: git directory %s does not exist", path, gitDir)
+	}
+	return gitDir, nil
+}
+
 // getRemoteURL gets the remote URL for origin
 func (r *Repository) getRemoteURL() (string, error) {
 	cmd := exec.Command("git", "remote", "get-url", "origin")
@@ -162,7 +199,7 @@ func (r *Repository) CreateBranch(branchName string) error {
 		Msg("Creating new branch")
 
 	// Ensure we're on the base branch
-	if err := r.CheckoutBranch(r.BaseBranch); err != nil {
+	if err := r.checkoutBase(); err != nil {
 		return fmt.Errorf("failed to checkout base branch: %w", err)
 	}
 
@@ -194,7 +231,7 @@ func (r *Repository) CheckoutOrCreateBranch(branchName string) (bool, error) {
 		Msg("Checking out or creating branch")
 
 	// Ensure we're on the base branch first
-	if err := r.CheckoutBranch(r.BaseBranch); err != nil {
+	if err := r.checkoutBase(); err != nil {
 		return false, fmt.Errorf("failed to checkout base branch: %w", err)
 	}
 
@@ -260,6 +297,55 @@ func (r *Repository) CheckoutOrCreateBranch(branchName string) (bool, error) {
 	return false, nil
 }
 
+// ResetBranch deletes a branch locally and on the remote so that it can be recreated from
+// the base branch. Uncommitted changes are discarded if the branch is checked out, since
+// they are left over from an interrupted run.
+func (r *Repository) ResetBranch(branchName string) error {
+	log.Debug().Str("branch", branchName).Msg("Resetting branch")
+
+	currentBranch, err := r.getCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if currentBranch == branchName {
+		cmd := exec.Command("git", "reset", "--hard", "HEAD")
+		cmd.Dir = r.WorkingDirectory
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to discard changes on branch %s: %w, output: %s", branchName, err, string(output))
+		}
+	}
+
+	if err := r.checkoutBase(); err != nil {
+		return fmt.Errorf("failed to checkout base branch: %w", err)
+	}
+
+	// Delete the local branch if it exists
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branchName)
+	cmd.Dir = r.WorkingDirectory
+	if cmd.Run() == nil {
+		cmd = exec.Command("git", "branch", "-D", branchName)
+		cmd.Dir = r.WorkingDirectory
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete local branch %s: %w, output: %s", branchName, err, string(output))
+		}
+		log.Debug().Str("branch", branchName).Msg("Deleted local branch")
+	}
+
+	// Delete the remote branch if it exists
+	cmd = exec.Command("git", "ls-remote", "--exit-code", "--heads", "origin", branchName)
+	cmd.Dir = r.WorkingDirectory
+	if cmd.Run() == nil {
+		cmd = exec.Command("git", "push", "origin", "--delete", branchName)
+		cmd.Dir = r.WorkingDirectory
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete remote branch %s: %w, output: %s", branchName, err, string(output))
+		}
+		log.Debug().Str("branch", branchName).Msg("Deleted remote branch")
+	}
+
+	return nil
+}
+
 // fetchBranch attempts to fetch a branch from remote
 func (r *Repository) fetchBranch(branchName string) error {
 	cmd := exec.Command("git", "fetch", "origin", branchName)
@@ -288,6 +374,25 @@ func (r *Repository) CheckoutBranch(branchName string) error {
 	return nil
 }
 
+// checkoutBase checks out the base branch. Worktrees detach at the base branch of origin, as
+// the local base branch is usually checked out in the main working directory, git checks out
+// a branch only once, and it may be stale.
+func (r *Repository) checkoutBase() error {
+	if r.MainWorkingDirectory == "" {
+		return r.CheckoutBranch(r.BaseBranch)
+	}
+
+	cmd := exec.Command("git", "checkout", "--detach", remoteBranch(r.BaseBranch))
+	cmd.Dir = r.WorkingDirectory
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to checkout %s detached: %w, output: %s", r.BaseBranch, err, string(output))
+	}
+
+	return nil
+}
+
 // pull pulls latest changes from remote for the current branch
 func (r *Repository) pull() error {
 	// Get current branch name
@@ -381,6 +486,35 @@ func (r *Repository) Push() error {
 	return nil
 }
 
+// RelativePath returns the path of a file relative to the repository root with forward
+// slashes, the form git uses on every platform. In a worktree, files of the main working
+// directory are relative to its root. Files outside the repository keep their path.
+func (r *Repository) RelativePath(filePath string) string {
+	if relPath, ok := r.relativePath(filePath); ok {
+		return relPath
+	}
+	return filePath
+}
+
+// relativePath returns the path of a file relative to the repository root, false if the
+// file is outside the repository
+func (r *Repository) relativePath(filePath string) (string, bool) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false
+	}
+	for _, root := range []string{r.WorkingDirectory, r.MainWorkingDirectory} {
+		if root == "" {
+			continue
+		}
+		relPath, err := filepath.Rel(root, absPath)
+		if err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(relPath), true
+		}
+	}
+	return "", false
+}
+
 // isDirectory checks if a path is a directory
 func isDirectory(path string) bool {
 	info, err := os.Stat(path)
@@ -409,6 +543,33 @@ func (r *Repository) HasUncommittedChanges() (bool, error) {
 	return len(strings.TrimSpace(string(output))) > 0, nil
 }
 
+// ChangedFiles returns the paths of all modified, deleted and untracked files in the working
+// directory relative to the repository root
+func (r *Repository) ChangedFiles() ([]string, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = r.WorkingDirectory
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	files := make([]string, 0)
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		// Renames and copies are followed by their original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files, nil
+}
+
 // HasUnpushedCommits checks if there are commits that haven't been pushed to remote
 func (r *Repository) HasUnpushedCommits() (bool, error) {
 	if r.BranchName == "" {
@@ -463,3 +624,16 @@ func (r *Repository) GetLastCommitMessage() (string, error) {
 
 	return strings.TrimSpace(string(output)), nil
 }
+
+// GetBranchCommit returns the commit SHA the given branch points to
+func (r *Repository) GetBranchCommit(branchName string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", branchName)
+	cmd.Dir = r.WorkingDirectory
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve commit of branch %s: %w", branchName, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,232 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFindGitRoot(t *testing.T) {
+	root := t.TempDir()
+
+	mkdir := func(path string) {
+		if err := os.MkdirAll(filepath.Join(root, path), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	write := func(path string, content string) {
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	// Main repository with a submodule whose git directory lives in .git/modules
+	mkdir(filepath.Join("repo", ".git", "modules", "lib"))
+	mkdir(filepath.Join("repo", "lib", "charts"))
+	write(filepath.Join("repo", "lib", ".git"), "gitdir: ../.git/modules/lib\n")
+	mkdir(filepath.Join("repo", "apps"))
+
+	// Worktree with a broken .git file
+	mkdir(filepath.Join("broken", "apps"))
+	write(filepath.Join("broken", ".git"), "not a git file\n")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		wantErr  string
+	}{
+		{
+			name:     "git directory",
+			path:     filepath.Join("repo", "apps"),
+			expected: "repo",
+		},
+		{
+			name:     "submodule git file",
+			path:     filepath.Join("repo", "lib", "charts"),
+			expected: filepath.Join("repo", "lib"),
+		},
+		{
+			name:    "invalid git file",
+			path:    filepath.Join("broken", "apps"),
+			wantErr: "missing gitdir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepository("", nil)
+			gitRoot, err := repo.findGitRoot(filepath.Join(root, tt.path))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findGitRoot() error = %v", err)
+			}
+			if gitRoot != filepath.Join(root, tt.expected) {
+				t.Errorf("expected root %s, got %s", filepath.Join(root, tt.expected), gitRoot)
+			}
+		})
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	root := t.TempDir()
+	repo := NewRepository(root, nil)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "file in repository",
+			path:     filepath.Join(root, "envs", "prod", "values.yaml"),
+			expected: "envs/prod/values.yaml",
+		},
+		{
+			name:     "file outside repository",
+			path:     filepath.Join(filepath.Dir(root), "other", "values.yaml"),
+			expected: filepath.Join(filepath.Dir(root), "other", "values.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := repo.RelativePath(tt.path); actual != tt.expected {
+				t.Errorf("RelativePath(%q) = %q, expected %q", tt.path, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResetBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	dir := filepath.Join(root, "work")
+	run := func(workDir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	run(root, "init", "-q", "--bare", remote)
+	run(root, "clone", "-q", remote, dir)
+	file := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(file, []byte("tag: 1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	run(dir, "add", "values.yaml")
+	run(dir, "commit", "-q", "-m", "initial")
+	run(dir, "branch", "-M", "main")
+	run(dir, "push", "-q", "origin", "main")
+
+	// Leave a pushed update branch with a commit and partial uncommitted changes
+	branch := "chore/update/app"
+	run(dir, "checkout", "-q", "-b", branch)
+	if err := os.WriteFile(file, []byte("tag: 1.1.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	run(dir, "commit", "-q", "-am", "update")
+	run(dir, "push", "-q", "origin", branch)
+	if err := os.WriteFile(file, []byte("tag: 1.2."), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	repo := &Repository{WorkingDirectory: dir, BaseBranch: "main"}
+	if err := repo.ResetBranch(branch); err != nil {
+		t.Fatalf("ResetBranch() error = %v", err)
+	}
+
+	if current := run(dir, "rev-parse", "--abbrev-ref", "HEAD"); current != "main" {
+		t.Errorf("Expected base branch checked out, got %s", current)
+	}
+	if branches := run(dir, "branch", "--list", branch); branches != "" {
+		t.Errorf("Expected local branch to be deleted, got %q", branches)
+	}
+	if heads := run(dir, "ls-remote", "--heads", "origin", branch); heads != "" {
+		t.Errorf("Expected remote branch to be deleted, got %q", heads)
+	}
+	if status := run(dir, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected clean working tree, got %q", status)
+	}
+
+	// Resetting a branch that does not exist is a no-op
+	if err := repo.ResetBranch(branch); err != nil {
+		t.Errorf("ResetBranch() of missing branch error = %v", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	run("init", "-q")
+	write("Chart.yaml", "version: 1.0.0\n")
+	write("old.txt", "old\n")
+	write("removed.txt", "removed\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	write("Chart.yaml", "version: 1.1.0\n")
+	write("charts/dep-1.1.0.tgz", "archive")
+	write("file with space.txt", "new\n")
+	run("mv", "old.txt", "new.txt")
+	if err := os.Remove(filepath.Join(dir, "removed.txt")); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+
+	repo := &Repository{WorkingDirectory: dir}
+	files, err := repo.ChangedFiles()
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+
+	sort.Strings(files)
+	expected := []string{"Chart.yaml", "charts/dep-1.1.0.tgz", "file with space.txt", "new.txt", "removed.txt"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("ChangedFiles() = %v, expected %v", files, expected)
+	}
+}
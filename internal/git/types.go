@@ -9,6 +9,9 @@ type Repository struct {
 	RepoURL          string
 	BaseBranch       string
 	BranchName       string
+	// MainWorkingDirectory is the working directory the repository was detected in if the
+	// repository operates in a worktree created by AddWorktree
+	MainWorkingDirectory string
 }
 
 // CommitOptions represents options for creating a commit
@@ -24,5 +27,9 @@ type PullRequestOptions struct {
 	BaseBranch string
 	HeadBranch string
 	Labels     []string
+	Reviewers  []string // Users and "org/team" teams requested for review, optionally prefixed with "@"
+	Assignees  []string
+	Milestone  string   // Milestone title or number
+	Projects   []string // Projects as "owner/number" or project URL
 	PatchGroup string
 }
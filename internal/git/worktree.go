@@ -0,0 +1,83 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AddWorktree creates a worktree of the repository in a new temporary directory, detached at
+// the base branch freshly fetched from origin, and returns a repository operating in it. The
+// local base branch may be stale or carry unpushed commits, so it is not used. Branches are
+// checked out in the worktree, so the HEAD and files of the main working directory are left
+// alone.
+func (r *Repository) AddWorktree() (*Repository, error) {
+	if err := r.fetchBranch(r.BaseBranch); err != nil {
+		return nil, fmt.Errorf("failed to fetch base branch %s: %w", r.BaseBranch, err)
+	}
+
+	dir, err := os.MkdirTemp("", "updater-worktree-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "--detach", dir, remoteBranch(r.BaseBranch))
+	cmd.Dir = r.WorkingDirectory
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to add worktree: %w, output: %s", err, string(output))
+	}
+
+	log.Debug().Str("worktree", dir).Str("repository", r.WorkingDirectory).Msg("Added worktree")
+
+	return &Repository{
+		WorkingDirectory:     dir,
+		TargetActor:          r.TargetActor,
+		RepoURL:              r.RepoURL,
+		BaseBranch:           r.BaseBranch,
+		MainWorkingDirectory: r.WorkingDirectory,
+	}, nil
+}
+
+// RemoveWorktree removes the worktree of a repository returned by AddWorktree, discarding
+// changes left in it. Commits stay on their branches.
+func (r *Repository) RemoveWorktree() error {
+	if r.MainWorkingDirectory == "" {
+		return fmt.Errorf("%s is not a worktree", r.WorkingDirectory)
+	}
+
+	cmd := exec.Command("git", "worktree", "remove", "--force", r.WorkingDirectory)
+	cmd.Dir = r.MainWorkingDirectory
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w, output: %s", r.WorkingDirectory, err, string(output))
+	}
+
+	log.Debug().Str("worktree", r.WorkingDirectory).Msg("Removed worktree")
+
+	return nil
+}
+
+// remoteBranch returns the remote-tracking ref of a branch of origin
+func remoteBranch(branchName string) string {
+	return "origin/" + branchName
+}
+
+// WorktreePath returns the path of a file of the main working directory in the worktree. The
+// path is returned unchanged if the repository is not a worktree or the file is outside it.
+func (r *Repository) WorktreePath(filePath string) string {
+	if r.MainWorkingDirectory == "" {
+		return filePath
+	}
+	relPath, ok := r.relativePath(filePath)
+	if !ok {
+		return filePath
+	}
+	return filepath.Join(r.WorkingDirectory, filepath.FromSlash(relPath))
+}
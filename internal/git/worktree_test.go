@@ -0,0 +1,186 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	dir := filepath.Join(root, "work")
+	run := func(workDir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	run(root, "init", "-q", "--bare", remote)
+	run(root, "clone", "-q", remote, dir)
+	file := filepath.Join(dir, "envs", "values.yaml")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("tag: 1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	run(dir, "add", ".")
+	run(dir, "commit", "-q", "-m", "initial")
+	run(dir, "branch", "-M", "main")
+	run(dir, "push", "-q", "origin", "main")
+
+	// Local work of the operator must survive applying updates
+	if err := os.WriteFile(file, []byte("tag: 1.0.0 # local\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	repo := &Repository{
+		WorkingDirectory: dir,
+		BaseBranch:       "main",
+		TargetActor:      &configuration.TargetActor{Name: "updater", Email: "updater@example.com"},
+	}
+	worktree, err := repo.AddWorktree()
+	if err != nil {
+		t.Fatalf("AddWorktree() error = %v", err)
+	}
+
+	if relPath := worktree.RelativePath(file); relPath != "envs/values.yaml" {
+		t.Errorf("RelativePath() of main working directory file = %q, expected envs/values.yaml", relPath)
+	}
+	worktreeFile := worktree.WorktreePath(file)
+	if expected := filepath.Join(worktree.WorkingDirectory, "envs", "values.yaml"); worktreeFile != expected {
+		t.Errorf("WorktreePath() = %q, expected %q", worktreeFile, expected)
+	}
+
+	branch := "chore/update/app"
+	if _, err := worktree.CheckoutOrCreateBranch(branch); err != nil {
+		t.Fatalf("CheckoutOrCreateBranch() in worktree error = %v", err)
+	}
+	if err := os.WriteFile(worktreeFile, []byte("tag: 1.1.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write worktree file: %v", err)
+	}
+	if err := worktree.Commit(&CommitOptions{Message: "update", Files: []string{worktree.RelativePath(file)}}); err != nil {
+		t.Fatalf("Commit() in worktree error = %v", err)
+	}
+
+	if err := worktree.RemoveWorktree(); err != nil {
+		t.Fatalf("RemoveWorktree() error = %v", err)
+	}
+	if _, err := os.Stat(worktree.WorkingDirectory); !os.IsNotExist(err) {
+		t.Errorf("Expected worktree directory to be removed, stat error = %v", err)
+	}
+
+	if current := run(dir, "rev-parse", "--abbrev-ref", "HEAD"); current != "main" {
+		t.Errorf("Expected main working directory to stay on main, got %s", current)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "tag: 1.0.0 # local\n" {
+		t.Errorf("Expected local changes to be kept, got %q", content)
+	}
+	if content := run(dir, "show", branch+":envs/values.yaml"); content != "tag: 1.1.0" {
+		t.Errorf("Expected update committed on %s, got %q", branch, content)
+	}
+}
+
+func TestWorktree_BasedOnRemoteBaseBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	dir := filepath.Join(root, "work")
+	other := filepath.Join(root, "other")
+	run := func(workDir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(workDir string, content string) {
+		if err := os.WriteFile(filepath.Join(workDir, "values.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		run(workDir, "add", ".")
+		run(workDir, "commit", "-q", "-m", content)
+	}
+
+	run(root, "init", "-q", "--bare", "--initial-branch=main", remote)
+	run(root, "clone", "-q", remote, dir)
+	run(dir, "checkout", "-q", "-b", "main")
+	commit(dir, "tag: 1.0.0\n")
+	run(dir, "push", "-q", "origin", "main")
+
+	// The base branch moves on upstream while the local base branch has an unpushed commit
+	run(root, "clone", "-q", remote, other)
+	commit(other, "tag: 1.0.0\nreplicas: 3\n")
+	run(other, "push", "-q", "origin", "main")
+	commit(dir, "tag: 1.0.0 # local\n")
+	upstream := run(other, "rev-parse", "HEAD")
+
+	repo := &Repository{
+		WorkingDirectory: dir,
+		BaseBranch:       "main",
+		TargetActor:      &configuration.TargetActor{Name: "updater", Email: "updater@example.com"},
+	}
+	worktree, err := repo.AddWorktree()
+	if err != nil {
+		t.Fatalf("AddWorktree() error = %v", err)
+	}
+	defer worktree.RemoveWorktree()
+
+	if head := run(worktree.WorkingDirectory, "rev-parse", "HEAD"); head != upstream {
+		t.Errorf("Expected worktree at origin/main %s, got %s", upstream, head)
+	}
+
+	if _, err := worktree.CheckoutOrCreateBranch("chore/update/app"); err != nil {
+		t.Fatalf("CheckoutOrCreateBranch() in worktree error = %v", err)
+	}
+	if base := run(worktree.WorkingDirectory, "rev-parse", "HEAD"); base != upstream {
+		t.Errorf("Expected branch created from origin/main %s, got %s", upstream, base)
+	}
+	if content, _ := os.ReadFile(worktree.WorktreePath(filepath.Join(dir, "values.yaml"))); string(content) != "tag: 1.0.0\nreplicas: 3\n" {
+		t.Errorf("Expected the upstream file in the worktree, got %q", content)
+	}
+}
+
+func TestAddWorktree_FetchFails(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+
+	repo := &Repository{WorkingDirectory: dir, BaseBranch: "main"}
+	if worktree, err := repo.AddWorktree(); err == nil {
+		worktree.RemoveWorktree()
+		t.Fatal("Expected an error without a remote to fetch the base branch from")
+	}
+}
@@ -0,0 +1,6 @@
+package history
+
+import (
+	// Postgres driver, registered as "pgx"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
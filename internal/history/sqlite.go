@@ -0,0 +1,12 @@
+//go:build cgo
+
+package history
+
+import (
+	// SQLite driver, which requires cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sqliteDriver = "sqlite3"
+}
@@ -0,0 +1,199 @@
+//go:build cgo
+
+package history
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDatabaseURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantDriver string
+		wantDSN    string
+		wantErr    bool
+	}{
+		{url: "postgres://updater:secret@db/updater", wantDriver: "pgx", wantDSN: "postgres://updater:secret@db/updater"},
+		{url: "postgresql://db/updater?sslmode=disable", wantDriver: "pgx", wantDSN: "postgresql://db/updater?sslmode=disable"},
+		{url: "sqlite:///var/lib/updater/history.db", wantDriver: "sqlite3", wantDSN: "file:/var/lib/updater/history.db?_foreign_keys=on&_busy_timeout=5000"},
+		{url: "history.db", wantDriver: "sqlite3", wantDSN: "file:history.db?_foreign_keys=on&_busy_timeout=5000"},
+		{url: "mysql://db/updater", wantErr: true},
+		{url: "sqlite://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			driver, dsn, err := parseDatabaseURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDatabaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if driver != tt.wantDriver || dsn != tt.wantDSN {
+				t.Errorf("parseDatabaseURL() = %q, %q, want %q, %q", driver, dsn, tt.wantDriver, tt.wantDSN)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	query := `SELECT id FROM runs WHERE scope = ? AND command = ?`
+	if bound := (&Store{}).bind(query); bound != query {
+		t.Errorf("SQLite query rewritten to %q", bound)
+	}
+	if bound := (&Store{postgres: true}).bind(query); bound != `SELECT id FROM runs WHERE scope = $1 AND command = $2` {
+		t.Errorf("Postgres query = %q", bound)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store, err := Open("sqlite://" + filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	comparison := func(current, latest string) *Comparison {
+		return &Comparison{Target: "app", File: "values.yaml", Item: "image.tag", Source: "api", CurrentVersion: current, LatestVersion: latest, UpdateType: "minor", NeedsUpdate: current != latest}
+	}
+	runs := []*Run{
+		{ID: "run-1", Scope: "platform/prod", Command: "compare", StartedAt: start, PendingUpdates: 1, Comparisons: []*Comparison{comparison("1.0.0", "1.1.0")}},
+		{ID: "run-2", Scope: "platform/prod", Command: "compare", StartedAt: start.Add(time.Hour), PendingUpdates: 1, Comparisons: []*Comparison{comparison("1.0.0", "1.1.0")}},
+		{ID: "run-3", Scope: "platform/prod", Command: "apply", StartedAt: start.Add(2 * time.Hour), Comparisons: []*Comparison{
+			comparison("1.1.0", "1.1.0"),
+			{Target: "app", File: "values.yaml", Item: "broken", Source: "api", Error: "path not found"},
+		}},
+		{ID: "run-4", Scope: "platform/dev", Command: "compare", StartedAt: start.Add(3 * time.Hour), Summary: json.RawMessage(`{"runId":"run-4"}`)},
+	}
+	for _, run := range runs {
+		if err := store.RecordRun(run); err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+	}
+	// Recording a run again replaces it
+	if err := store.RecordRun(runs[2]); err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+
+	listed, err := store.Runs(RunFilter{Scope: "platform/prod", Limit: 2})
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != "run-3" || listed[1].ID != "run-2" {
+		t.Fatalf("Runs() = %+v, want run-3 and run-2", listed)
+	}
+	if !listed[0].StartedAt.Equal(runs[2].StartedAt) || listed[0].Command != "apply" {
+		t.Errorf("unexpected run: %+v", listed[0])
+	}
+
+	all, err := store.Runs(RunFilter{})
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(all) != 4 || string(all[0].Summary) != `{"runId":"run-4"}` {
+		t.Errorf("expected 4 runs with the summary of run-4 first, got %+v", all)
+	}
+
+	comparisons, err := store.Comparisons("run-3")
+	if err != nil {
+		t.Fatalf("Comparisons() error = %v", err)
+	}
+	if len(comparisons) != 2 || comparisons[0].Error != "path not found" || !reflect.DeepEqual(comparisons[1], runs[2].Comparisons[0]) {
+		t.Errorf("unexpected comparisons: %+v", comparisons)
+	}
+
+	timeline, err := store.Timeline(TimelineFilter{Source: "api"})
+	if err != nil {
+		t.Fatalf("Timeline() error = %v", err)
+	}
+	if len(timeline) != 2 {
+		t.Fatalf("expected 2 timeline entries, got %d: %+v", len(timeline), timeline)
+	}
+	if timeline[0].RunID != "run-1" || timeline[0].CurrentVersion != "1.0.0" || timeline[1].RunID != "run-3" || timeline[1].CurrentVersion != "1.1.0" {
+		t.Errorf("unexpected timeline: %+v, %+v", timeline[0], timeline[1])
+	}
+}
+
+func TestStoreSnoozes(t *testing.T) {
+	store, err := Open("sqlite://" + filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	snoozes := []*Snooze{
+		{Scope: "platform", Target: "app", File: "values.yaml", Item: "image.tag", Version: "1.1.0", Until: now.Add(7 * 24 * time.Hour), CreatedAt: now},
+		{Scope: "platform", Target: "db", File: "values.yaml", Item: "postgres", Version: "16.0.0", Until: now.Add(-time.Hour), CreatedAt: now},
+		{Scope: "other", Target: "app", File: "values.yaml", Item: "image.tag", Version: "1.1.0", Until: now.Add(time.Hour), CreatedAt: now},
+		// Replaces the first snooze
+		{Scope: "platform", Target: "app", File: "values.yaml", Item: "image.tag", Version: "1.1.0", Until: now.Add(24 * time.Hour), CreatedAt: now},
+	}
+	for _, snooze := range snoozes {
+		if err := store.AddSnooze(snooze); err != nil {
+			t.Fatalf("AddSnooze() error = %v", err)
+		}
+	}
+
+	active, err := store.Snoozes("platform", now)
+	if err != nil {
+		t.Fatalf("Snoozes() error = %v", err)
+	}
+	if len(active) != 1 || !reflect.DeepEqual(active[0], snoozes[3]) {
+		t.Fatalf("Snoozes() = %+v, want the replaced snooze of app", active)
+	}
+	if !active[0].Matches("app", "values.yaml", "image.tag", "1.1.0") || active[0].Matches("app", "values.yaml", "image.tag", "1.2.0") {
+		t.Error("snooze should only match the snoozed version")
+	}
+}
+
+func TestStoreLocks(t *testing.T) {
+	store, err := Open("sqlite://" + filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	steps := []struct {
+		name   string
+		holder string
+		at     time.Time
+		held   bool
+	}{
+		{name: "first holder acquires", holder: "a", at: now, held: true},
+		{name: "second holder waits", holder: "b", at: now.Add(10 * time.Second), held: false},
+		{name: "holder renews", holder: "a", at: now.Add(20 * time.Second), held: true},
+		{name: "renewed lock is kept", holder: "b", at: now.Add(40 * time.Second), held: false},
+		{name: "expired lock is taken over", holder: "b", at: now.Add(50 * time.Second), held: true},
+		{name: "previous holder lost it", holder: "a", at: now.Add(55 * time.Second), held: false},
+	}
+	for _, step := range steps {
+		held, err := store.AcquireLock("serve", step.holder, step.at, 30*time.Second)
+		if err != nil {
+			t.Fatalf("%s: AcquireLock() error = %v", step.name, err)
+		}
+		if held != step.held {
+			t.Errorf("%s: AcquireLock() = %v, want %v", step.name, held, step.held)
+		}
+	}
+
+	if held, _ := store.AcquireLock("other", "a", now.Add(55*time.Second), 30*time.Second); !held {
+		t.Error("expected locks of other names to be independent")
+	}
+	if err := store.ReleaseLock("serve", "a"); err != nil {
+		t.Fatalf("ReleaseLock() error = %v", err)
+	}
+	if held, _ := store.AcquireLock("serve", "a", now.Add(56*time.Second), 30*time.Second); held {
+		t.Error("expected a release by another holder to keep the lock")
+	}
+	if err := store.ReleaseLock("serve", "b"); err != nil {
+		t.Fatalf("ReleaseLock() error = %v", err)
+	}
+	if held, _ := store.AcquireLock("serve", "a", now.Add(57*time.Second), 30*time.Second); !held {
+		t.Error("expected a released lock to be acquired")
+	}
+}
@@ -0,0 +1,169 @@
+package lock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// microTimeFormat is the format of the MicroTime fields of a Lease
+const microTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// KubernetesLease is a lock in a coordination.k8s.io Lease, accessed with kubectl, which must be
+// on the PATH and configured through the kubeconfig or the in-cluster service account
+type KubernetesLease struct {
+	Context   string // kubeconfig context, the current context if empty
+	Namespace string // Namespace of the Lease, the namespace of the context if empty
+	Name      string
+}
+
+// lease is a coordination.k8s.io/v1 Lease
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// Acquire creates the Lease or takes it over if its holder did not renew it in time. Updates
+// are sent with the resource version read, so of concurrent attempts only one succeeds.
+func (k *KubernetesLease) Acquire(holder string, now time.Time, duration time.Duration) (bool, error) {
+	current, err := k.get()
+	if err != nil {
+		return false, err
+	}
+	if current == nil {
+		created := &lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: k.Name, Namespace: k.Namespace},
+		}
+		created.renew(holder, now, duration)
+		return k.write("create", created)
+	}
+	if !current.availableTo(holder, now) {
+		return false, nil
+	}
+	current.renew(holder, now, duration)
+	return k.write("replace", current)
+}
+
+// Release clears the holder of the Lease if holder holds it, so another replica takes over
+// without waiting for the lease to end
+func (k *KubernetesLease) Release(holder string) error {
+	current, err := k.get()
+	if err != nil || current == nil || current.Spec.HolderIdentity != holder {
+		return err
+	}
+	current.Spec.HolderIdentity = ""
+	current.Spec.LeaseDurationSeconds = 1
+	_, err = k.write("replace", current)
+	return err
+}
+
+// availableTo reports whether holder may acquire the lease at the given time: it holds the
+// lease already, nobody does, or its holder did not renew it in time
+func (l *lease) availableTo(holder string, now time.Time) bool {
+	if l.Spec.HolderIdentity == "" || l.Spec.HolderIdentity == holder {
+		return true
+	}
+	renewed, err := time.Parse(time.RFC3339Nano, l.Spec.RenewTime)
+	if err != nil {
+		// A lease without valid renew time is never renewed by a working holder
+		return true
+	}
+	return !now.Before(renewed.Add(time.Duration(l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// renew sets holder as the holder of the lease, renewed at the given time
+func (l *lease) renew(holder string, now time.Time, duration time.Duration) {
+	if l.Spec.HolderIdentity != holder {
+		if l.Spec.HolderIdentity != "" {
+			l.Spec.LeaseTransitions++
+		}
+		l.Spec.HolderIdentity = holder
+		l.Spec.AcquireTime = now.UTC().Format(microTimeFormat)
+	}
+	l.Spec.RenewTime = now.UTC().Format(microTimeFormat)
+	l.Spec.LeaseDurationSeconds = int(math.Ceil(duration.Seconds()))
+}
+
+// get returns the Lease, nil if it does not exist
+func (k *KubernetesLease) get() (*lease, error) {
+	output, err := k.kubectl(nil, k.namespaced("get", "lease", k.Name, "--ignore-not-found", "-o", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	current := &lease{}
+	if err := json.Unmarshal(output, current); err != nil {
+		return nil, fmt.Errorf("failed to parse lease %s: %w", k.Name, err)
+	}
+	return current, nil
+}
+
+// write creates or replaces the Lease and reports whether it was written. Losing a race to
+// another replica is not an error.
+func (k *KubernetesLease) write(verb string, l *lease) (bool, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal lease %s: %w", k.Name, err)
+	}
+	if _, err := k.kubectl(data, k.namespaced(verb, "-f", "-")...); err != nil {
+		if strings.Contains(err.Error(), "AlreadyExists") || strings.Contains(err.Error(), "Conflict") {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// namespaced appends the namespace of the Lease to kubectl arguments
+func (k *KubernetesLease) namespaced(args ...string) []string {
+	if k.Namespace == "" {
+		return args
+	}
+	return append(args, "--namespace", k.Namespace)
+}
+
+// kubectl runs kubectl with the configured context and returns its output
+func (k *KubernetesLease) kubectl(stdin []byte, args ...string) ([]byte, error) {
+	if k.Context != "" {
+		args = append(args, "--context", k.Context)
+	}
+	log.Trace().Strs("args", args).Msg("Running kubectl")
+
+	cmd := exec.Command("kubectl", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kubectl %s lease %s failed: %s", args[0], k.Name, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
@@ -0,0 +1,54 @@
+package lock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaseAvailableTo(t *testing.T) {
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	renewed := now.Add(-20 * time.Second).Format(microTimeFormat)
+
+	tests := []struct {
+		name      string
+		spec      leaseSpec
+		available bool
+	}{
+		{name: "no holder", spec: leaseSpec{}, available: true},
+		{name: "own lease", spec: leaseSpec{HolderIdentity: "replica-1", LeaseDurationSeconds: 30, RenewTime: renewed}, available: true},
+		{name: "renewed lease of another holder", spec: leaseSpec{HolderIdentity: "replica-2", LeaseDurationSeconds: 30, RenewTime: renewed}, available: false},
+		{name: "expired lease of another holder", spec: leaseSpec{HolderIdentity: "replica-2", LeaseDurationSeconds: 15, RenewTime: renewed}, available: true},
+		{name: "invalid renew time", spec: leaseSpec{HolderIdentity: "replica-2", LeaseDurationSeconds: 30, RenewTime: "yesterday"}, available: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &lease{Spec: tt.spec}
+			if got := l.availableTo("replica-1", now); got != tt.available {
+				t.Errorf("availableTo() = %v, want %v", got, tt.available)
+			}
+		})
+	}
+}
+
+func TestLeaseRenew(t *testing.T) {
+	acquired := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	l := &lease{}
+	l.renew("replica-1", acquired, 30*time.Second)
+	if l.Spec.HolderIdentity != "replica-1" || l.Spec.LeaseDurationSeconds != 30 || l.Spec.LeaseTransitions != 0 {
+		t.Fatalf("unexpected new lease %+v", l.Spec)
+	}
+	if l.Spec.AcquireTime != "2026-10-01T06:00:00.000000Z" || l.Spec.RenewTime != l.Spec.AcquireTime {
+		t.Errorf("unexpected lease times %+v", l.Spec)
+	}
+
+	renewed := acquired.Add(10 * time.Second)
+	l.renew("replica-1", renewed, 30*time.Second)
+	if l.Spec.AcquireTime != "2026-10-01T06:00:00.000000Z" || l.Spec.RenewTime != "2026-10-01T06:00:10.000000Z" {
+		t.Errorf("expected a renewal to keep the acquire time, got %+v", l.Spec)
+	}
+
+	l.renew("replica-2", renewed.Add(time.Minute), 30*time.Second)
+	if l.Spec.HolderIdentity != "replica-2" || l.Spec.LeaseTransitions != 1 || l.Spec.AcquireTime != "2026-10-01T06:01:10.000000Z" {
+		t.Errorf("expected a takeover to count a transition, got %+v", l.Spec)
+	}
+}
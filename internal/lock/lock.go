@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mxcd/updater/internal/history"
+	"github.com/rs/zerolog/log"
+)
+
+// Lock is held by at most one holder at a time, for a lease its holder keeps renewing
+type Lock interface {
+	// Acquire acquires the lock for holder until now plus duration, or renews it if holder
+	// already holds it, and reports whether holder holds the lock
+	Acquire(holder string, now time.Time, duration time.Duration) (bool, error)
+	// Release releases the lock if holder holds it
+	Release(holder string) error
+}
+
+// Database is a lock in a history database, shared by every replica using the database
+type Database struct {
+	Store *history.Store
+	Name  string
+}
+
+// Acquire acquires or renews the lock row of the database
+func (d *Database) Acquire(holder string, now time.Time, duration time.Duration) (bool, error) {
+	return d.Store.AcquireLock(d.Name, holder, now, duration)
+}
+
+// Release deletes the lock row of the database if holder holds it
+func (d *Database) Release(holder string) error {
+	return d.Store.ReleaseLock(d.Name, holder)
+}
+
+// Holder returns the identity of this process for locks: the host name, which is the pod name
+// on Kubernetes, and the process ID
+func Holder() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "updater"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Elector keeps acquiring a lock, so that one of several replicas is the leader
+type Elector struct {
+	lock     Lock
+	holder   string
+	duration time.Duration
+
+	mu        sync.Mutex
+	heldUntil time.Time // End of the lease last acquired or renewed, zero if not held
+}
+
+// NewElector creates an elector acquiring the lock for holder with leases of the given duration
+func NewElector(lock Lock, holder string, duration time.Duration) *Elector {
+	return &Elector{lock: lock, holder: holder, duration: duration}
+}
+
+// Start tries to acquire the lock once, then keeps renewing or retrying it every third of the
+// lease duration until the context is done. The returned channel is closed once the lock has
+// been released after that.
+func (e *Elector) Start(ctx context.Context) <-chan struct{} {
+	e.try(time.Now())
+
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		ticker := time.NewTicker(e.duration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case <-ticker.C:
+				e.try(time.Now())
+			}
+		}
+	}()
+	return released
+}
+
+// IsLeader reports whether the lock is held
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.heldUntil)
+}
+
+// try acquires or renews the lock once
+func (e *Elector) try(now time.Time) {
+	held, err := e.lock.Acquire(e.holder, now, e.duration)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	wasLeader := now.Before(e.heldUntil)
+	switch {
+	case err != nil:
+		// The lease stays valid until it ends, a later attempt may still renew it
+		log.Warn().Err(err).Str("holder", e.holder).Msg("Failed to acquire or renew the lock")
+	case held:
+		e.heldUntil = now.Add(e.duration)
+		if !wasLeader {
+			log.Info().Str("holder", e.holder).Msg("Acquired the lock, this replica is the leader")
+		}
+	default:
+		e.heldUntil = time.Time{}
+		if wasLeader {
+			log.Warn().Str("holder", e.holder).Msg("Lost the lock to another replica")
+		}
+	}
+}
+
+// release releases the lock if it is held
+func (e *Elector) release() {
+	e.mu.Lock()
+	held := !e.heldUntil.IsZero()
+	e.heldUntil = time.Time{}
+	e.mu.Unlock()
+
+	if !held {
+		return
+	}
+	if err := e.lock.Release(e.holder); err != nil {
+		log.Warn().Err(err).Str("holder", e.holder).Msg("Failed to release the lock")
+		return
+	}
+	log.Info().Str("holder", e.holder).Msg("Released the lock")
+}
@@ -0,0 +1,91 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLock answers acquire attempts with a fixed outcome and counts releases
+type fakeLock struct {
+	mu       sync.Mutex
+	held     bool
+	err      error
+	released int
+}
+
+func (f *fakeLock) Acquire(holder string, now time.Time, duration time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.held, f.err
+}
+
+func (f *fakeLock) Release(holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released++
+	return nil
+}
+
+func (f *fakeLock) set(held bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.held, f.err = held, err
+}
+
+func TestElector(t *testing.T) {
+	lock := &fakeLock{}
+	elector := NewElector(lock, "replica-1", time.Minute)
+	now := time.Now()
+
+	steps := []struct {
+		name   string
+		held   bool
+		err    error
+		leader bool
+	}{
+		{name: "held by another replica", held: false, leader: false},
+		{name: "acquired", held: true, leader: true},
+		{name: "renewal failed within the lease", err: errors.New("connection refused"), leader: true},
+		{name: "taken over", held: false, leader: false},
+	}
+	for _, step := range steps {
+		lock.set(step.held, step.err)
+		elector.try(now)
+		if got := elector.IsLeader(); got != step.leader {
+			t.Errorf("%s: IsLeader() = %v, want %v", step.name, got, step.leader)
+		}
+	}
+
+	// A lease that failed to renew ends with its duration
+	lock.set(true, nil)
+	elector.try(now.Add(-2 * time.Minute))
+	lock.set(false, errors.New("connection refused"))
+	elector.try(now)
+	if elector.IsLeader() {
+		t.Error("expected the leadership to end with the lease")
+	}
+}
+
+func TestElectorStart(t *testing.T) {
+	lock := &fakeLock{held: true}
+	elector := NewElector(lock, "replica-1", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	released := elector.Start(ctx)
+	if !elector.IsLeader() {
+		t.Fatal("expected the lock to be acquired on start")
+	}
+
+	cancel()
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lock to be released")
+	}
+	if elector.IsLeader() || lock.released != 1 {
+		t.Errorf("expected the lock to be released once, leader %v, released %d", elector.IsLeader(), lock.released)
+	}
+}
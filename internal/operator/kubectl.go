@@ -0,0 +1,154 @@
+package operator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Client reads and updates UpdaterConfig resources
+type Client interface {
+	List() ([]*UpdaterConfig, error)
+	UpdateStatus(config *UpdaterConfig) error
+	ReadSecret(namespace string, ref *SecretReference) (string, error)
+}
+
+// KubectlClient accesses the cluster with kubectl, which must be on the PATH and configured
+// through the kubeconfig or the in-cluster service account, like the cluster reader
+type KubectlClient struct {
+	Context   string // kubeconfig context, the current context if empty
+	Namespace string // Namespace of the UpdaterConfigs, all namespaces if empty
+}
+
+// List returns all UpdaterConfigs of the watched namespaces
+func (c *KubectlClient) List() ([]*UpdaterConfig, error) {
+	output, err := c.kubectl(nil, c.namespaced("get", Resource, "-o", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	return parseUpdaterConfigList(output)
+}
+
+// UpdateStatus writes the status of an UpdaterConfig through the status subresource
+func (c *KubectlClient) UpdateStatus(config *UpdaterConfig) error {
+	patch, err := json.Marshal(map[string]any{"status": config.Status})
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+	_, err = c.kubectl(patch, "patch", Resource, config.Metadata.Name, "--namespace", config.Metadata.Namespace,
+		"--subresource=status", "--type=merge", "--patch-file=/dev/stdin")
+	return err
+}
+
+// ReadSecret returns the decoded value of a key of a Secret, "token" if no key is given
+func (c *KubectlClient) ReadSecret(namespace string, ref *SecretReference) (string, error) {
+	output, err := c.kubectl(nil, "get", "secret", ref.Name, "--namespace", namespace, "-o", "json")
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(output, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse secret %s: %w", ref.Name, err)
+	}
+	key := ref.Key
+	if key == "" {
+		key = "token"
+	}
+	encoded, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, key)
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode key %s of secret %s: %w", key, ref.Name, err)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// Watch signals changes of UpdaterConfigs until the context is done or the watch ends.
+// Events are coalesced: a pending signal is not duplicated.
+func (c *KubectlClient) Watch(ctx context.Context, changed chan<- struct{}) error {
+	args := c.namespaced("get", Resource, "--watch", "--output-watch-events", "-o", "json")
+	if c.Context != "" {
+		args = append(args, "--context", c.Context)
+	}
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", Resource, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", Resource, err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(stdout))
+	for {
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := decoder.Decode(&event); err != nil {
+			break
+		}
+		log.Debug().Str("event", event.Type).Msg("UpdaterConfig changed")
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("kubectl watch of %s failed: %s", Resource, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// namespaced appends the namespace selection to kubectl arguments
+func (c *KubectlClient) namespaced(args ...string) []string {
+	if c.Namespace == "" {
+		return append(args, "--all-namespaces")
+	}
+	return append(args, "--namespace", c.Namespace)
+}
+
+// kubectl runs kubectl with the configured context and returns its output
+func (c *KubectlClient) kubectl(stdin []byte, args ...string) ([]byte, error) {
+	if c.Context != "" {
+		args = append(args, "--context", c.Context)
+	}
+	log.Debug().Strs("args", args).Msg("Running kubectl")
+
+	cmd := exec.Command("kubectl", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kubectl %s failed: %s", strings.Join(args[:2], " "), strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// parseUpdaterConfigList parses the output of kubectl get for a list of UpdaterConfigs
+func parseUpdaterConfigList(data []byte) ([]*UpdaterConfig, error) {
+	var list struct {
+		Items []*UpdaterConfig `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Resource, err)
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,94 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// RunResult is the outcome of one run of an UpdaterConfig
+type RunResult struct {
+	RunID          string
+	PendingUpdates int
+	PullRequests   []PullRequestStatus
+	Errors         []string // Failures of sources and targets that did not fail the run
+	Err            error    // Error that failed the run
+}
+
+// Due reports whether a run of the UpdaterConfig is due: on its first reconcile, after its
+// spec changed, and once its schedule allows a run after the last one. Without schedule it
+// runs every interval.
+func (c *UpdaterConfig) Due(now time.Time, interval time.Duration) (bool, error) {
+	var schedule *configuration.Schedule
+	if c.Spec.Schedule != "" {
+		var err error
+		if schedule, err = configuration.ParseSchedule(c.Spec.Schedule); err != nil {
+			return false, fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
+	if c.Spec.Suspend {
+		return false, nil
+	}
+	lastRun := c.Status.LastRunTime
+	if lastRun == nil || c.Status.ObservedGeneration != c.Metadata.Generation {
+		return true, nil
+	}
+	if schedule == nil {
+		return !now.Before(lastRun.Add(interval)), nil
+	}
+	next, ok := schedule.Next(lastRun.Add(time.Minute))
+	return ok && !now.Before(next), nil
+}
+
+// RecordRun updates the status with the outcome of a run
+func (c *UpdaterConfig) RecordRun(result *RunResult, now time.Time) {
+	runTime := now.UTC().Truncate(time.Second)
+	c.Status.ObservedGeneration = c.Metadata.Generation
+	c.Status.LastRunTime = &runTime
+	c.Status.LastRunID = result.RunID
+
+	if result.Err != nil {
+		c.setCondition(ConditionReady, "False", "RunFailed", result.Err.Error(), runTime)
+		c.setCondition(ConditionUpdatesAvailable, "Unknown", "RunFailed", "", runTime)
+		c.setCondition(ConditionDegraded, "True", "RunFailed", result.Err.Error(), runTime)
+		return
+	}
+
+	c.Status.PendingUpdates = result.PendingUpdates
+	c.Status.PullRequests = result.PullRequests
+	c.setCondition(ConditionReady, "True", "RunSucceeded", "", runTime)
+	if result.PendingUpdates > 0 {
+		c.setCondition(ConditionUpdatesAvailable, "True", "UpdatesFound", fmt.Sprintf("%d update(s) pending", result.PendingUpdates), runTime)
+	} else {
+		c.setCondition(ConditionUpdatesAvailable, "False", "UpToDate", "", runTime)
+	}
+	if len(result.Errors) > 0 {
+		c.setCondition(ConditionDegraded, "True", "PartialFailure", strings.Join(result.Errors, "; "), runTime)
+	} else {
+		c.setCondition(ConditionDegraded, "False", "AllSucceeded", "", runTime)
+	}
+}
+
+// RecordError marks the UpdaterConfig as not ready without running it, e.g. for an invalid spec
+func (c *UpdaterConfig) RecordError(reason string, err error, now time.Time) {
+	c.Status.ObservedGeneration = c.Metadata.Generation
+	c.setCondition(ConditionReady, "False", reason, err.Error(), now.UTC().Truncate(time.Second))
+}
+
+// setCondition sets a condition, keeping its transition time if the status did not change
+func (c *UpdaterConfig) setCondition(conditionType string, status string, reason string, message string, now time.Time) {
+	condition := c.Condition(conditionType)
+	if condition == nil {
+		c.Status.Conditions = append(c.Status.Conditions, Condition{Type: conditionType})
+		condition = &c.Status.Conditions[len(c.Status.Conditions)-1]
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = now
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+}
@@ -0,0 +1,196 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdaterConfig_Due(t *testing.T) {
+	now := time.Date(2026, 10, 16, 6, 30, 0, 0, time.UTC)
+	at := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("invalid time %s: %v", value, err)
+		}
+		return &parsed
+	}
+
+	tests := []struct {
+		name          string
+		spec          UpdaterConfigSpec
+		generation    int64
+		status        UpdaterConfigStatus
+		expected      bool
+		errorExpected bool
+	}{
+		{
+			name:       "never run",
+			spec:       UpdaterConfigSpec{Schedule: "0 6 * * *"},
+			generation: 1,
+			expected:   true,
+		},
+		{
+			name:       "spec changed since the last run",
+			spec:       UpdaterConfigSpec{Schedule: "0 6 * * *"},
+			generation: 2,
+			status:     UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: at("2026-10-16T06:00:10Z")},
+			expected:   true,
+		},
+		{
+			name:       "scheduled run already done",
+			spec:       UpdaterConfigSpec{Schedule: "0 6 * * *"},
+			generation: 1,
+			status:     UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: at("2026-10-16T06:00:10Z")},
+		},
+		{
+			name:       "scheduled run pending",
+			spec:       UpdaterConfigSpec{Schedule: "0 6 * * *"},
+			generation: 1,
+			status:     UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: at("2026-10-15T06:00:10Z")},
+			expected:   true,
+		},
+		{
+			name:       "without schedule runs every interval",
+			generation: 1,
+			status:     UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: at("2026-10-16T06:20:00Z")},
+			expected:   true,
+		},
+		{
+			name:       "without schedule within the interval",
+			generation: 1,
+			status:     UpdaterConfigStatus{ObservedGeneration: 1, LastRunTime: at("2026-10-16T06:27:00Z")},
+		},
+		{
+			name:       "suspended",
+			spec:       UpdaterConfigSpec{Suspend: true},
+			generation: 1,
+		},
+		{
+			name:          "invalid schedule",
+			spec:          UpdaterConfigSpec{Schedule: "every day"},
+			generation:    1,
+			errorExpected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &UpdaterConfig{
+				Metadata: ObjectMeta{Name: "platform", Namespace: "updater", Generation: tt.generation},
+				Spec:     tt.spec,
+				Status:   tt.status,
+			}
+			due, err := config.Due(now, 5*time.Minute)
+			if (err != nil) != tt.errorExpected {
+				t.Fatalf("Due() error = %v, errorExpected %v", err, tt.errorExpected)
+			}
+			if due != tt.expected {
+				t.Errorf("Due() = %v, expected %v", due, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUpdaterConfig_RecordRun(t *testing.T) {
+	now := time.Date(2026, 10, 16, 6, 0, 12, 0, time.UTC)
+	config := &UpdaterConfig{Metadata: ObjectMeta{Name: "platform", Namespace: "updater", Generation: 3}}
+
+	config.RecordRun(&RunResult{
+		RunID:          "20261016T060000Z-3f9a1c2e",
+		PendingUpdates: 2,
+		PullRequests:   []PullRequestStatus{{PatchGroup: "prod", URL: "https://github.com/o/r/pull/1"}},
+		Errors:         []string{"source app (provider github): timeout"},
+	}, now)
+
+	if config.Status.ObservedGeneration != 3 || !config.Status.LastRunTime.Equal(now) || config.Status.LastRunID != "20261016T060000Z-3f9a1c2e" {
+		t.Errorf("unexpected run status: %+v", config.Status)
+	}
+	if config.Status.PendingUpdates != 2 || len(config.Status.PullRequests) != 1 {
+		t.Errorf("unexpected run outcome: %+v", config.Status)
+	}
+	expected := map[string]string{
+		ConditionReady:            "True",
+		ConditionUpdatesAvailable: "True",
+		ConditionDegraded:         "True",
+	}
+	for conditionType, status := range expected {
+		if condition := config.Condition(conditionType); condition == nil || condition.Status != status {
+			t.Errorf("expected condition %s %s, got %+v", conditionType, status, condition)
+		}
+	}
+	if message := config.Condition(ConditionDegraded).Message; message != "source app (provider github): timeout" {
+		t.Errorf("unexpected Degraded message: %q", message)
+	}
+
+	// A failed run keeps the last known updates and only moves changed conditions
+	later := now.Add(24 * time.Hour)
+	config.RecordRun(&RunResult{RunID: "next", Err: errors.New("failed to synchronize repository")}, later)
+	ready := config.Condition(ConditionReady)
+	if ready.Status != "False" || ready.Reason != "RunFailed" || !ready.LastTransitionTime.Equal(later) {
+		t.Errorf("unexpected Ready condition: %+v", ready)
+	}
+	if degraded := config.Condition(ConditionDegraded); !degraded.LastTransitionTime.Equal(now) {
+		t.Errorf("expected unchanged Degraded status to keep its transition time, got %+v", degraded)
+	}
+	if config.Status.PendingUpdates != 2 {
+		t.Errorf("expected pending updates of the last successful run, got %d", config.Status.PendingUpdates)
+	}
+
+	config.RecordRun(&RunResult{RunID: "last"}, later.Add(time.Hour))
+	for conditionType, status := range map[string]string{
+		ConditionReady:            "True",
+		ConditionUpdatesAvailable: "False",
+		ConditionDegraded:         "False",
+	} {
+		if condition := config.Condition(conditionType); condition.Status != status {
+			t.Errorf("expected condition %s %s, got %+v", conditionType, status, condition)
+		}
+	}
+	if len(config.Status.Conditions) != 3 {
+		t.Errorf("expected conditions to be updated in place, got %d", len(config.Status.Conditions))
+	}
+}
+
+func TestParseUpdaterConfigList(t *testing.T) {
+	data := []byte(`{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "updater.mxcd.de/v1alpha1",
+      "kind": "UpdaterConfig",
+      "metadata": {"name": "platform", "namespace": "updater", "generation": 2},
+      "spec": {
+        "schedule": "0 6 * * 1-5",
+        "apply": true,
+        "repository": {"url": "https://github.com/example/gitops.git", "credentialsSecretRef": {"name": "updater-github"}},
+        "config": {"targets": []}
+      },
+      "status": {"observedGeneration": 1, "lastRunId": "run"}
+    }
+  ]
+}`)
+
+	configs, err := parseUpdaterConfigList(data)
+	if err != nil {
+		t.Fatalf("parseUpdaterConfigList() failed: %v", err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 UpdaterConfig, got %d", len(configs))
+	}
+	config := configs[0]
+	if config.Key() != "updater/platform" || config.Metadata.Generation != 2 || !config.Spec.Apply {
+		t.Errorf("unexpected UpdaterConfig: %+v", config)
+	}
+	if config.Spec.Repository.CredentialsSecretRef.Name != "updater-github" || config.Spec.Config == nil {
+		t.Errorf("unexpected spec: %+v", config.Spec)
+	}
+	if config.Status.ObservedGeneration != 1 || config.Status.LastRunID != "run" {
+		t.Errorf("unexpected status: %+v", config.Status)
+	}
+
+	if _, err := parseUpdaterConfigList([]byte("not json")); err == nil {
+		t.Errorf("expected an error for invalid output")
+	}
+}
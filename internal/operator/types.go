@@ -0,0 +1,91 @@
+package operator
+
+import "time"
+
+// Resource is the kubectl resource name of UpdaterConfig custom resources
+const Resource = "updaterconfigs.updater.mxcd.de"
+
+// Condition types of the UpdaterConfig status
+const (
+	ConditionReady            = "Ready"            // The last run completed
+	ConditionUpdatesAvailable = "UpdatesAvailable" // The last run found updates
+	ConditionDegraded         = "Degraded"         // Sources or targets failed in the last run
+)
+
+// UpdaterConfig is a custom resource running updater on a schedule against a git repository
+type UpdaterConfig struct {
+	Metadata ObjectMeta          `json:"metadata"`
+	Spec     UpdaterConfigSpec   `json:"spec"`
+	Status   UpdaterConfigStatus `json:"status"`
+}
+
+// ObjectMeta holds the metadata fields of a custom resource used by the controller
+type ObjectMeta struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Generation int64  `json:"generation"`
+}
+
+// UpdaterConfigSpec is the desired state of an UpdaterConfig
+type UpdaterConfigSpec struct {
+	Schedule    string         `json:"schedule,omitempty"`    // Window or cron schedule of runs, every resync interval if empty
+	Apply       bool           `json:"apply,omitempty"`       // Run apply instead of compare
+	Suspend     bool           `json:"suspend,omitempty"`     // Skip scheduled runs
+	Repository  Repository     `json:"repository"`            // Git repository holding the target files
+	ConfigPath  string         `json:"configPath,omitempty"`  // Configuration file or directory in the repository, defaults to ".updater"
+	Config      map[string]any `json:"config,omitempty"`      // Inline configuration used instead of configPath
+	Environment string         `json:"environment,omitempty"` // Environment whose overrides are applied
+}
+
+// Repository is the git repository an UpdaterConfig runs against
+type Repository struct {
+	URL                  string           `json:"url"`
+	Branch               string           `json:"branch,omitempty"`               // Base branch, the default branch of the remote if empty
+	CredentialsSecretRef *SecretReference `json:"credentialsSecretRef,omitempty"` // Secret holding the token used to clone and push
+}
+
+// SecretReference references a key of a Secret in the namespace of the UpdaterConfig
+type SecretReference struct {
+	Name string `json:"name"`
+	Key  string `json:"key,omitempty"` // Defaults to "token"
+}
+
+// UpdaterConfigStatus is the observed state of an UpdaterConfig
+type UpdaterConfigStatus struct {
+	ObservedGeneration int64               `json:"observedGeneration,omitempty"`
+	LastRunTime        *time.Time          `json:"lastRunTime,omitempty"`
+	LastRunID          string              `json:"lastRunId,omitempty"`
+	PendingUpdates     int                 `json:"pendingUpdates"`
+	PullRequests       []PullRequestStatus `json:"pullRequests"` // null clears the pull requests of earlier runs in merge patches
+	Conditions         []Condition         `json:"conditions,omitempty"`
+}
+
+// PullRequestStatus is a pull request opened or updated by the last run
+type PullRequestStatus struct {
+	PatchGroup string `json:"patchGroup"`
+	URL        string `json:"url"`
+}
+
+// Condition is a Kubernetes status condition
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"` // "True", "False" or "Unknown"
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// Key returns the namespaced name of the custom resource
+func (c *UpdaterConfig) Key() string {
+	return c.Metadata.Namespace + "/" + c.Metadata.Name
+}
+
+// Condition returns the condition of a type, nil if it is not set
+func (c *UpdaterConfig) Condition(conditionType string) *Condition {
+	for i := range c.Status.Conditions {
+		if c.Status.Conditions[i].Type == conditionType {
+			return &c.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/mxcd/updater/internal/scraper/github"
+)
+
+// SBOM is the inventory of a software bill of materials: the identities of its components,
+// by which package sources are matched against it
+type SBOM struct {
+	Components int             // Number of components read
+	keys       map[string]bool // Identities of the components, see componentKeys
+}
+
+// cycloneDXDocument holds the parts of a CycloneDX JSON document identifying its components
+type cycloneDXDocument struct {
+	Metadata struct {
+		Component *cycloneDXComponent `json:"component"`
+	} `json:"metadata"`
+	Components []*cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type               string                  `json:"type"`
+	Name               string                  `json:"name"`
+	Group              string                  `json:"group"`
+	PURL               string                  `json:"purl"`
+	ExternalReferences []*cycloneDXExternalRef `json:"externalReferences"`
+	Components         []*cycloneDXComponent   `json:"components"`
+}
+
+type cycloneDXExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// spdxDocument holds the parts of an SPDX JSON document identifying its packages
+type spdxDocument struct {
+	SPDXVersion string         `json:"spdxVersion"`
+	Packages    []*spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	Name                  string             `json:"name"`
+	DownloadLocation      string             `json:"downloadLocation"`
+	PrimaryPackagePurpose string             `json:"primaryPackagePurpose"`
+	ExternalRefs          []*spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	ReferenceType    string `json:"referenceType"`
+	ReferenceLocator string `json:"referenceLocator"`
+}
+
+// Load reads a CycloneDX or SPDX SBOM in JSON format
+func Load(path string) (*SBOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a CycloneDX or SPDX SBOM in JSON format, telling them apart by the bomFormat
+// and spdxVersion fields
+func Parse(data []byte) (*SBOM, error) {
+	var format struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM, only CycloneDX and SPDX JSON are supported: %w", err)
+	}
+
+	s := &SBOM{keys: make(map[string]bool)}
+	switch {
+	case format.BOMFormat == "CycloneDX":
+		document := &cycloneDXDocument{}
+		if err := json.Unmarshal(data, document); err != nil {
+			return nil, fmt.Errorf("failed to parse CycloneDX SBOM: %w", err)
+		}
+		if document.Metadata.Component != nil {
+			s.addCycloneDX(document.Metadata.Component)
+		}
+		for _, component := range document.Components {
+			s.addCycloneDX(component)
+		}
+	case strings.HasPrefix(format.SPDXVersion, "SPDX-"):
+		document := &spdxDocument{}
+		if err := json.Unmarshal(data, document); err != nil {
+			return nil, fmt.Errorf("failed to parse SPDX SBOM: %w", err)
+		}
+		for _, pkg := range document.Packages {
+			s.addSPDX(pkg)
+		}
+	default:
+		return nil, fmt.Errorf("unknown SBOM format, expected CycloneDX (bomFormat) or SPDX (spdxVersion) JSON")
+	}
+	return s, nil
+}
+
+// Merge adds the components of another SBOM, e.g. of another cluster of the platform
+func (s *SBOM) Merge(other *SBOM) {
+	s.Components += other.Components
+	for key := range other.keys {
+		s.keys[key] = true
+	}
+}
+
+// addCycloneDX adds a component and the components nested in it
+func (s *SBOM) addCycloneDX(component *cycloneDXComponent) {
+	s.Components++
+	name := component.Name
+	if component.Group != "" {
+		name = component.Group + "/" + component.Name
+	}
+	s.addKeys(componentKeys(name, component.Type == "container", component.PURL))
+	for _, reference := range component.ExternalReferences {
+		if reference.Type == "vcs" || reference.Type == "website" {
+			s.addKeys(repositoryKeys(reference.URL))
+		}
+	}
+	for _, nested := range component.Components {
+		s.addCycloneDX(nested)
+	}
+}
+
+// addSPDX adds a package
+func (s *SBOM) addSPDX(pkg *spdxPackage) {
+	s.Components++
+	var purls []string
+	for _, reference := range pkg.ExternalRefs {
+		if reference.ReferenceType == "purl" {
+			purls = append(purls, reference.ReferenceLocator)
+		}
+	}
+	container := pkg.PrimaryPackagePurpose == "CONTAINER"
+	s.addKeys(componentKeys(pkg.Name, container, ""))
+	for _, purl := range purls {
+		s.addKeys(componentKeys("", false, purl))
+	}
+	s.addKeys(repositoryKeys(pkg.DownloadLocation))
+}
+
+func (s *SBOM) addKeys(keys []string) {
+	for _, key := range keys {
+		s.keys[key] = true
+	}
+}
+
+// Contains reports whether a component of the SBOM is the package of a source: the image of
+// a docker-image source, the repository of a git source, or the chart of a helm-chart source
+func (s *SBOM) Contains(source *configuration.PackageSource) bool {
+	for _, key := range sourceKeys(source) {
+		if s.keys[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceKeys returns the identities of the package of a source
+func sourceKeys(source *configuration.PackageSource) []string {
+	switch source.Type {
+	case configuration.PackageSourceTypeDockerImage:
+		return imageKeys(source.URI)
+	case configuration.PackageSourceTypeGitRelease, configuration.PackageSourceTypeGitTag, configuration.PackageSourceTypeGitHelmChart:
+		return repositoryKeys(source.URI)
+	case configuration.PackageSourceTypeHelmRepository:
+		if source.ChartName != "" {
+			return []string{"chart:" + strings.ToLower(source.ChartName)}
+		}
+	}
+	return nil
+}
+
+// componentKeys returns the identities of a component from its name, if it names a container
+// image, and its package URL
+func componentKeys(name string, container bool, purl string) []string {
+	var keys []string
+	if container && name != "" {
+		keys = append(keys, imageKeys(name)...)
+	}
+	if purl == "" {
+		return keys
+	}
+
+	purlType, path, qualifiers, err := parsePURL(purl)
+	if err != nil {
+		return keys
+	}
+	switch purlType {
+	case "docker", "oci":
+		// The repository_url qualifier holds the full image name, otherwise docker purls are
+		// Docker Hub images and oci purls are not matched by their bare name
+		if repositoryURL := qualifiers.Get("repository_url"); repositoryURL != "" {
+			keys = append(keys, imageKeys(repositoryURL)...)
+		} else if purlType == "docker" {
+			keys = append(keys, imageKeys(path)...)
+		}
+	case "github":
+		keys = append(keys, repositoryKeys("github.com/"+path)...)
+	case "golang":
+		keys = append(keys, repositoryKeys(path)...)
+	case "helm":
+		segments := strings.Split(path, "/")
+		keys = append(keys, "chart:"+strings.ToLower(segments[len(segments)-1]))
+	}
+	return keys
+}
+
+// imageKeys returns the identity of an image reference, its registry and repository without
+// tag or digest, with Docker Hub as docker.io
+func imageKeys(reference string) []string {
+	reference, _, _ = strings.Cut(reference, "@")
+	info, err := docker.ParseImageURL(reference)
+	if err != nil {
+		return nil
+	}
+	registry := info.Registry
+	if registry == "" {
+		registry = "docker.io"
+	}
+	return []string{"image:" + strings.ToLower(registry+"/"+info.Repository)}
+}
+
+// repositoryKeys returns the identity of a git repository URL: its host, with the GitHub API
+// host as github.com, owner, and name
+func repositoryKeys(uri string) []string {
+	uri = strings.TrimPrefix(uri, "git+")
+	if uri == "" || uri == "NOASSERTION" || uri == "NONE" {
+		return nil
+	}
+	if !strings.Contains(uri, "://") {
+		uri = "https://" + uri
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	repository, err := github.ParseRepositoryURL(uri)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(parsed.Host)
+	if host == "api.github.com" || host == "www.github.com" {
+		host = "github.com"
+	}
+	return []string{"repo:" + strings.ToLower(host+"/"+repository.Owner+"/"+repository.Repo)}
+}
+
+// parsePURL splits a package URL, pkg:type/namespace/name@version?qualifiers#subpath, into its
+// type, its unescaped namespace and name path, and its qualifiers
+func parsePURL(purl string) (string, string, url.Values, error) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "", "", nil, fmt.Errorf("not a package URL: %s", purl)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, rawQualifiers, _ := strings.Cut(rest, "?")
+	if at := strings.LastIndex(rest, "@"); at != -1 {
+		rest = rest[:at]
+	}
+	purlType, path, ok := strings.Cut(rest, "/")
+	if !ok || path == "" {
+		return "", "", nil, fmt.Errorf("package URL without name: %s", purl)
+	}
+	path, err := url.PathUnescape(strings.Trim(path, "/"))
+	if err != nil {
+		return "", "", nil, err
+	}
+	qualifiers, err := url.ParseQuery(rawQualifiers)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return strings.ToLower(purlType), path, qualifiers, nil
+}
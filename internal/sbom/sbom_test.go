@@ -0,0 +1,110 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const cycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {"type": "application", "name": "platform"}
+  },
+  "components": [
+    {"type": "container", "name": "ghcr.io/org/api:1.2.0"},
+    {"type": "container", "name": "nginx", "purl": "pkg:docker/library/nginx@1.25.0"},
+    {
+      "type": "application",
+      "name": "operator",
+      "externalReferences": [{"type": "vcs", "url": "https://github.com/org/operator.git"}],
+      "components": [
+        {"type": "library", "name": "cert-manager", "purl": "pkg:helm/jetstack/cert-manager@1.14.0"}
+      ]
+    },
+    {"type": "container", "name": "worker", "purl": "pkg:oci/worker@sha256%3Aabc?repository_url=registry.example.com/team/worker"}
+  ]
+}`
+
+const spdx = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"name": "registry.example.com/team/web", "primaryPackagePurpose": "CONTAINER", "downloadLocation": "NOASSERTION"},
+    {
+      "name": "cli",
+      "downloadLocation": "git+https://github.com/org/cli",
+      "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/github.com/org/lib@v1.0.0"}]
+    }
+  ]
+}`
+
+func TestParse_CycloneDX(t *testing.T) {
+	inventory, err := Parse([]byte(cycloneDX))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if inventory.Components != 6 {
+		t.Errorf("Expected 6 components, got %d", inventory.Components)
+	}
+
+	tests := []struct {
+		source   *configuration.PackageSource
+		contains bool
+	}{
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "ghcr.io/org/api"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "nginx"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "docker.io/library/nginx"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/team/worker"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "ghcr.io/org/other"}, false},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/operator"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/org/other"}, false},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeHelmRepository, URI: "https://charts.jetstack.io", ChartName: "cert-manager"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeStatic, StaticVersions: []string{"1.0.0"}}, false},
+	}
+	for _, tt := range tests {
+		if got := inventory.Contains(tt.source); got != tt.contains {
+			t.Errorf("Contains(%s %s) = %v, want %v", tt.source.Type, tt.source.URI, got, tt.contains)
+		}
+	}
+}
+
+func TestParse_SPDX(t *testing.T) {
+	inventory, err := Parse([]byte(spdx))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	tests := []struct {
+		source   *configuration.PackageSource
+		contains bool
+	}{
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/team/web"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/cli"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/org/lib"}, true},
+		{&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "cli"}, false},
+	}
+	for _, tt := range tests {
+		if got := inventory.Contains(tt.source); got != tt.contains {
+			t.Errorf("Contains(%s %s) = %v, want %v", tt.source.Type, tt.source.URI, got, tt.contains)
+		}
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	if _, err := Parse([]byte(`{"name": "not an SBOM"}`)); err == nil {
+		t.Error("Expected documents without bomFormat or spdxVersion to fail")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first, _ := Parse([]byte(cycloneDX))
+	second, _ := Parse([]byte(spdx))
+	first.Merge(second)
+	if first.Components != 8 {
+		t.Errorf("Expected 8 components, got %d", first.Components)
+	}
+	if !first.Contains(&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/team/web"}) {
+		t.Error("Expected the merged SBOM to contain the components of both")
+	}
+}
@@ -0,0 +1,146 @@
+package scraper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/rs/zerolog/log"
+)
+
+// discoverSources lists the packages of the discovery sources to scrape and materializes a
+// source per package, named <discovery source>/<package> and configured like the discovery
+// source: a docker-image source per repository of a docker-namespace source and a helm-chart
+// source per chart of a helm-repo-all source. Packages with an explicitly configured source
+// of that name are left to it. The sources discovered by an earlier scrape are replaced, or
+// kept if the packages cannot be listed.
+func (o *Orchestrator) discoverSources(options *ScrapeOptions) []*SourceScrapeStat {
+	var discoveries []*configuration.PackageSource
+	for _, source := range o.config.PackageSources {
+		if source.Discovers() && o.discoverySelected(source, options) {
+			discoveries = append(discoveries, source)
+		}
+	}
+
+	stats := make([]*SourceScrapeStat, 0, len(discoveries))
+	for _, discovery := range discoveries {
+		start := time.Now()
+		sources, err := o.listDiscoveredSources(discovery)
+		stats = append(stats, &SourceScrapeStat{
+			SourceName: discovery.Name,
+			Provider:   discovery.Provider,
+			Type:       discovery.Type,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+		if err != nil {
+			continue
+		}
+
+		kept := make([]*configuration.PackageSource, 0, len(o.config.PackageSources)+len(sources))
+		for _, source := range o.config.PackageSources {
+			if source.DiscoveredBy != discovery.Name {
+				kept = append(kept, source)
+			}
+		}
+		o.config.PackageSources = append(kept, sources...)
+
+		log.Debug().
+			Str("source", discovery.Name).
+			Int("packages", len(sources)).
+			Msg("Discovered package sources")
+	}
+	return stats
+}
+
+// discoverySelected reports whether the packages of a discovery source are scraped, that is
+// if the options do not restrict the sources or reference one of them
+func (o *Orchestrator) discoverySelected(discovery *configuration.PackageSource, options *ScrapeOptions) bool {
+	if options == nil || options.Sources == nil {
+		return true
+	}
+	for name := range options.Sources {
+		if configuration.DiscoverySource(o.config.PackageSources, name) == discovery {
+			return true
+		}
+	}
+	log.Debug().Str("source", discovery.Name).Msg("Skipping discovery source not referenced by any selected target")
+	return false
+}
+
+// listDiscoveredSources returns a source for every package of a discovery source matching
+// its repository or chart pattern
+func (o *Orchestrator) listDiscoveredSources(discovery *configuration.PackageSource) ([]*configuration.PackageSource, error) {
+	client, exists := o.providerClients[discovery.Provider]
+	if !exists {
+		return nil, fmt.Errorf("provider %s not found", discovery.Provider)
+	}
+	lister, ok := client.(PackageLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s cannot list packages of %s sources", discovery.Provider, discovery.Type)
+	}
+
+	packages, err := lister.ListPackages(discovery)
+	if err != nil {
+		return nil, err
+	}
+	patternValue := discovery.RepositoryPattern
+	if discovery.Type == configuration.PackageSourceTypeHelmRepoAll {
+		patternValue = discovery.ChartPattern
+	}
+	pattern, err := configuration.CompilePattern(patternValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", patternValue, err)
+	}
+
+	configured := make(map[string]bool)
+	for _, source := range o.config.PackageSources {
+		if source.DiscoveredBy == "" {
+			configured[source.Name] = true
+		}
+	}
+
+	sources := make([]*configuration.PackageSource, 0, len(packages))
+	for _, name := range packages {
+		if !pattern.MatchString(name) {
+			continue
+		}
+		if configured[discovery.Name+"/"+name] {
+			log.Debug().Str("source", discovery.Name+"/"+name).Msg("Package has a configured source, skipping discovered source")
+			continue
+		}
+		source, err := MaterializeSource(discovery, name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// MaterializeSource returns the source of a package of a discovery source
+func MaterializeSource(discovery *configuration.PackageSource, name string) (*configuration.PackageSource, error) {
+	source := *discovery
+	source.Name = discovery.Name + "/" + name
+	source.RepositoryPattern = ""
+	source.ChartPattern = ""
+	source.DiscoveredBy = discovery.Name
+	source.Versions = nil
+
+	switch discovery.Type {
+	case configuration.PackageSourceTypeDockerNamespace:
+		namespace, err := docker.ParseNamespaceURL(discovery.URI)
+		if err != nil {
+			return nil, err
+		}
+		source.Type = configuration.PackageSourceTypeDockerImage
+		source.URI = namespace.ImageURI(name)
+	case configuration.PackageSourceTypeHelmRepoAll:
+		source.Type = configuration.PackageSourceTypeHelmRepository
+		source.ChartName = name
+	default:
+		return nil, fmt.Errorf("unsupported discovery source type: %s", discovery.Type)
+	}
+	return &source, nil
+}
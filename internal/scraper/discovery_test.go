@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestScrapeAllSources_DockerNamespace(t *testing.T) {
+	repositories := []string{"acme/api", "acme/web", "acme/worker", "other/api"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/_catalog" {
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": repositories})
+			return
+		}
+		repository := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/"), "/tags/list")
+		json.NewEncoder(w).Encode(map[string]any{"name": repository, "tags": []string{"1.0.0", "1.1.0", "latest"}})
+	}))
+	defer server.Close()
+
+	newConfig := func() *configuration.Config {
+		return &configuration.Config{
+			PackageSourceProviders: []*configuration.PackageSourceProvider{
+				{Name: "registry", Type: configuration.PackageSourceProviderTypeDocker, BaseUrl: server.URL},
+			},
+			PackageSources: []*configuration.PackageSource{
+				{
+					Name:              "acme",
+					Provider:          "registry",
+					Type:              configuration.PackageSourceTypeDockerNamespace,
+					URI:               "registry.example.com/acme",
+					RepositoryPattern: "^(api|web)$",
+					TagPattern:        `^\d+\.\d+\.\d+$`,
+				},
+			},
+		}
+	}
+	scrape := func(t *testing.T, config *configuration.Config, options *ScrapeOptions) map[string][]string {
+		t.Helper()
+		orchestrator, err := NewOrchestrator(config, nil)
+		if err != nil {
+			t.Fatalf("NewOrchestrator() error = %v", err)
+		}
+		if result := orchestrator.ScrapeAllSources(options); result.HasErrors() {
+			t.Fatalf("unexpected scrape errors: %v", result.Errors[0])
+		}
+		scraped := make(map[string][]string)
+		for _, source := range config.PackageSources {
+			if source.Type != configuration.PackageSourceTypeDockerImage {
+				continue
+			}
+			versions := []string{}
+			for _, version := range source.Versions {
+				versions = append(versions, version.Version)
+			}
+			scraped[source.Name+" "+source.URI] = versions
+		}
+		return scraped
+	}
+
+	t.Run("discovers matching repositories", func(t *testing.T) {
+		scraped := scrape(t, newConfig(), &ScrapeOptions{})
+		expected := map[string][]string{
+			"acme/api registry.example.com/acme/api": {"1.1.0", "1.0.0"},
+			"acme/web registry.example.com/acme/web": {"1.1.0", "1.0.0"},
+		}
+		if !reflect.DeepEqual(scraped, expected) {
+			t.Errorf("scraped %v, want %v", scraped, expected)
+		}
+	})
+
+	t.Run("scrapes only referenced repositories", func(t *testing.T) {
+		scraped := scrape(t, newConfig(), &ScrapeOptions{Sources: map[string]bool{"acme/web": true}})
+		expected := map[string][]string{
+			"acme/api registry.example.com/acme/api": {},
+			"acme/web registry.example.com/acme/web": {"1.1.0", "1.0.0"},
+		}
+		if !reflect.DeepEqual(scraped, expected) {
+			t.Errorf("scraped %v, want %v", scraped, expected)
+		}
+	})
+
+	t.Run("skips unreferenced namespaces", func(t *testing.T) {
+		if scraped := scrape(t, newConfig(), &ScrapeOptions{Sources: map[string]bool{}}); len(scraped) != 0 {
+			t.Errorf("expected no discovered sources, got %v", scraped)
+		}
+	})
+
+	t.Run("configured sources take precedence", func(t *testing.T) {
+		config := newConfig()
+		config.PackageSources = append(config.PackageSources, &configuration.PackageSource{
+			Name: "acme/api", Provider: "registry", Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/other/api",
+		})
+		scraped := scrape(t, config, &ScrapeOptions{})
+		if _, ok := scraped["acme/api registry.example.com/other/api"]; !ok || len(scraped) != 2 {
+			t.Errorf("expected the configured acme/api source to be kept, got %v", scraped)
+		}
+	})
+
+	t.Run("rescraping replaces discovered sources", func(t *testing.T) {
+		config := newConfig()
+		orchestrator, err := NewOrchestrator(config, nil)
+		if err != nil {
+			t.Fatalf("NewOrchestrator() error = %v", err)
+		}
+		orchestrator.ScrapeAllSources(&ScrapeOptions{})
+		repositories = []string{"acme/api"}
+		defer func() { repositories = []string{"acme/api", "acme/web", "acme/worker", "other/api"} }()
+		orchestrator.ScrapeAllSources(&ScrapeOptions{})
+
+		var names []string
+		for _, source := range config.PackageSources {
+			names = append(names, source.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"acme", "acme/api"}) {
+			t.Errorf("expected sources [acme acme/api], got %v", names)
+		}
+	})
+}
+
+func TestScrapeAllSources_HelmRepoAll(t *testing.T) {
+	indexRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		indexRequests++
+		io.WriteString(w, `apiVersion: v1
+entries:
+  postgres:
+    - {name: postgres, version: 12.1.0}
+    - {name: postgres, version: 12.2.0}
+  redis:
+    - {name: redis, version: 18.0.0}
+  legacy-app:
+    - {name: legacy-app, version: 0.1.0}
+  empty: []
+`)
+	}))
+	defer server.Close()
+
+	config := &configuration.Config{
+		PackageSourceProviders: []*configuration.PackageSourceProvider{
+			{Name: "catalog", Type: configuration.PackageSourceProviderTypeHelm, BaseUrl: server.URL},
+		},
+		PackageSources: []*configuration.PackageSource{
+			{Name: "charts", Provider: "catalog", Type: configuration.PackageSourceTypeHelmRepoAll, ChartPattern: "^(postgres|redis|empty)$"},
+		},
+	}
+	orchestrator, err := NewOrchestrator(config, nil)
+	if err != nil {
+		t.Fatalf("NewOrchestrator() error = %v", err)
+	}
+	if result := orchestrator.ScrapeAllSources(&ScrapeOptions{}); result.HasErrors() {
+		t.Fatalf("unexpected scrape errors: %v", result.Errors[0])
+	}
+
+	scraped := make(map[string][]string)
+	for _, source := range config.PackageSources[1:] {
+		if source.Type != configuration.PackageSourceTypeHelmRepository {
+			t.Errorf("expected helm-chart source, got %s for %s", source.Type, source.Name)
+		}
+		for _, version := range source.Versions {
+			scraped[source.Name+" "+source.ChartName] = append(scraped[source.Name+" "+source.ChartName], version.Version)
+		}
+	}
+	expected := map[string][]string{
+		"charts/postgres postgres": {"12.2.0", "12.1.0"},
+		"charts/redis redis":       {"18.0.0"},
+	}
+	if !reflect.DeepEqual(scraped, expected) {
+		t.Errorf("scraped %v, want %v", scraped, expected)
+	}
+	// Listing and scraping the charts share one download of the index
+	if indexRequests != 1 {
+		t.Errorf("expected index.yaml to be fetched once, got %d requests", indexRequests)
+	}
+}
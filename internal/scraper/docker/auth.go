@@ -77,25 +77,27 @@ func splitAuthParams(s string) []string {
 	return parts
 }
 
-// exchangeForBearerToken calls the token endpoint from the challenge to get a Bearer token
-func exchangeForBearerToken(client *http.Client, challenge *wwwAuthenticateChallenge, provider *configuration.PackageSourceProvider, repository string) (string, error) {
+// exchangeForBearerToken calls the token endpoint from the challenge to get a Bearer token.
+// A challenge scope of several space-separated scopes is requested as one scope parameter each.
+func exchangeForBearerToken(client *http.Client, challenge *wwwAuthenticateChallenge, provider *configuration.PackageSourceProvider, repository string) (*bearerToken, error) {
+	requestedAt := time.Now()
 	tokenURL, err := url.Parse(challenge.Realm)
 	if err != nil {
-		return "", fmt.Errorf("invalid token realm URL: %w", err)
+		return nil, fmt.Errorf("invalid token realm URL: %w", err)
 	}
 
 	q := tokenURL.Query()
 	if challenge.Service != "" {
 		q.Set("service", challenge.Service)
 	}
-	if challenge.Scope != "" {
-		q.Set("scope", challenge.Scope)
+	for _, scope := range strings.Fields(challenge.Scope) {
+		q.Add("scope", scope)
 	}
 	tokenURL.RawQuery = q.Encode()
 
 	req, err := http.NewRequest("GET", tokenURL.String(), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create token request: %w", err)
+		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 
 	// Add credentials based on provider auth type
@@ -113,27 +115,28 @@ func exchangeForBearerToken(client *http.Client, challenge *wwwAuthenticateChall
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("token exchange request failed: %w", err)
+		return nil, fmt.Errorf("token exchange request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token exchange failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("token exchange failed: HTTP %d: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read token response: %w", err)
+		return nil, fmt.Errorf("failed to read token response: %w", err)
 	}
 
 	// Token response can have "token" or "access_token" field
 	var tokenResp struct {
 		Token       string `json:"token"`
 		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Lifetime in seconds
 	}
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", fmt.Errorf("failed to parse token response: %w", err)
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
 	}
 
 	token := tokenResp.Token
@@ -141,31 +144,61 @@ func exchangeForBearerToken(client *http.Client, challenge *wwwAuthenticateChall
 		token = tokenResp.AccessToken
 	}
 	if token == "" {
-		return "", fmt.Errorf("no token in token exchange response")
+		return nil, fmt.Errorf("no token in token exchange response")
 	}
 
-	return token, nil
+	// The lifetime counts from before the request, so clock differences do not extend it
+	lifetime := defaultTokenLifetime
+	if tokenResp.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResp.ExpiresIn) * time.Second
+	}
+	return &bearerToken{Value: token, ExpiresAt: requestedAt.Add(lifetime)}, nil
 }
 
 // doAuthenticatedRequest makes a GET request with auth challenge handling.
 // First tries with static credentials; if 401, exchanges for a Bearer token and retries.
 func doAuthenticatedRequest(client *http.Client, requestURL string, provider *configuration.PackageSourceProvider, repository string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return doRegistryRequest(client, http.MethodGet, requestURL, nil, provider, repository)
+}
 
-	// Try static auth first
-	applyStaticAuth(req, provider)
+// doRegistryRequest makes a registry request with the given method and headers, handling
+// auth challenges like doAuthenticatedRequest. Bearer tokens are cached until shortly before
+// they expire; a cached token the registry rejects is exchanged anew.
+func doRegistryRequest(client *http.Client, method string, requestURL string, header http.Header, provider *configuration.PackageSourceProvider, repository string) (*http.Response, error) {
+	cacheKey := repositoryKey(requestURL, provider, repository)
+
+	var resp *http.Response
+	var err error
+	if cached, ok := registryTokens.get(cacheKey, time.Now()); ok {
+		// Reuse the bearer token of earlier requests of the repository
+		resp, err = sendRegistryRequest(client, method, requestURL, header, "Bearer "+cached.Value)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
+		// The token expired early or was revoked mid-run
+		log.Debug().Str("repository", repository).Msg("cached bearer token was rejected, exchanging a new one")
+		registryTokens.invalidate(cacheKey)
+	} else {
+		// Try static auth first
+		req, err := http.NewRequest(method, requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		copyHeader(req, header)
+		applyStaticAuth(req, provider)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
+		resp, err = client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
 
-	// If not 401, return the response as-is
-	if resp.StatusCode != http.StatusUnauthorized {
-		return resp, nil
+		// If not 401, return the response as-is
+		if resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
 	}
 
 	// Got 401 — try token exchange via Www-Authenticate challenge
@@ -187,20 +220,35 @@ func doAuthenticatedRequest(client *http.Client, requestURL string, provider *co
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange for bearer token: %w", err)
 	}
+	registryTokens.put(cacheKey, tokenKey(challenge, provider), token, time.Now())
 
 	// Retry with the bearer token
-	retryReq, err := http.NewRequest("GET", requestURL, nil)
+	return sendRegistryRequest(client, method, requestURL, header, "Bearer "+token.Value)
+}
+
+// sendRegistryRequest sends a registry request with the given headers and Authorization header
+func sendRegistryRequest(client *http.Client, method string, requestURL string, header http.Header, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(method, requestURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create retry request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	retryReq.Header.Set("Authorization", "Bearer "+token)
+	copyHeader(req, header)
+	req.Header.Set("Authorization", authorization)
 
-	retryResp, err := client.Do(retryReq)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("retry request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	return resp, nil
+}
 
-	return retryResp, nil
+// copyHeader adds the given headers to a request
+func copyHeader(req *http.Request, header http.Header) {
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
 }
 
 // applyStaticAuth sets auth headers on a request based on the provider config
@@ -251,10 +299,11 @@ func getNextPageURL(linkHeader string, registryBaseURL string) string {
 	return ""
 }
 
-// fetchV2TagsPaginated fetches tags from a V2 registry with pagination and auth challenge support
-func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]string, error) {
-	allTags := make([]string, 0)
-	client := &http.Client{Timeout: 30 * time.Second}
+// fetchV2TagsPaginated fetches tags from a V2 registry with pagination and auth challenge
+// support, passing each page to onPage
+func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions, onPage tagPageFunc) error {
+	tagCount := 0
+	client := opts.httpClient()
 
 	tagLimit := source.TagLimit
 	if tagLimit < 0 {
@@ -266,9 +315,9 @@ func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *co
 	pageCount := 0
 
 	for nextURL != "" {
-		if tagLimit > 0 && len(allTags) >= tagLimit {
+		if tagLimit > 0 && tagCount >= tagLimit {
 			log.Debug().
-				Int("tags_fetched", len(allTags)).
+				Int("tags_fetched", tagCount).
 				Int("tag_limit", tagLimit).
 				Msg("reached tag limit, stopping pagination")
 			break
@@ -282,12 +331,12 @@ func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *co
 
 		resp, err := doAuthenticatedRequest(client, nextURL, provider, imageInfo.Repository)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+			return fmt.Errorf("failed to fetch tags: %w", err)
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("failed to fetch tags: HTTP %d", resp.StatusCode)
+			return fmt.Errorf("failed to fetch tags: HTTP %d", resp.StatusCode)
 		}
 
 		body, err := io.ReadAll(resp.Body)
@@ -295,7 +344,7 @@ func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *co
 		resp.Body.Close()
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to read tags response: %w", err)
+			return fmt.Errorf("failed to read tags response: %w", err)
 		}
 
 		var tagsResp struct {
@@ -303,32 +352,33 @@ func fetchV2TagsPaginated(registryURL string, imageInfo *ImageInfo, provider *co
 			Tags []string `json:"tags"`
 		}
 		if err := json.Unmarshal(body, &tagsResp); err != nil {
-			return nil, fmt.Errorf("failed to parse registry response: %w", err)
+			return fmt.Errorf("failed to parse registry response: %w", err)
 		}
 
-		for _, tag := range tagsResp.Tags {
-			if tagLimit > 0 && len(allTags) >= tagLimit {
-				break
-			}
-			allTags = append(allTags, tag)
+		// Only pass on tags up to the tag limit
+		pageTags := tagsResp.Tags
+		if tagLimit > 0 && tagCount+len(pageTags) > tagLimit {
+			pageTags = pageTags[:tagLimit-tagCount]
 		}
+		tagCount += len(pageTags)
+		onPage(pageTags)
 
 		nextURL = getNextPageURL(linkHeader, registryURL)
 
 		log.Trace().
 			Int("page", pageCount).
 			Int("page_tags", len(tagsResp.Tags)).
-			Int("total_tags", len(allTags)).
+			Int("total_tags", tagCount).
 			Bool("has_next", nextURL != "").
 			Msg("fetched V2 registry tags page")
 	}
 
 	log.Debug().
-		Int("total_tags", len(allTags)).
+		Int("total_tags", tagCount).
 		Int("pages", pageCount).
 		Int("tag_limit", tagLimit).
-		Bool("limit_reached", tagLimit > 0 && len(allTags) >= tagLimit).
+		Bool("limit_reached", tagLimit > 0 && tagCount >= tagLimit).
 		Msg("finished fetching V2 registry tags")
 
-	return allTags, nil
+	return nil
 }
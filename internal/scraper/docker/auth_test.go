@@ -292,7 +292,10 @@ func TestFetchV2TagsPaginated_SinglePage(t *testing.T) {
 	provider := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone}
 	source := &configuration.PackageSource{}
 
-	tags, err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{})
+	var tags []string
+	err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{}, func(page []string) {
+		tags = append(tags, page...)
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -334,7 +337,10 @@ func TestFetchV2TagsPaginated_MultiPage(t *testing.T) {
 	provider := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone}
 	source := &configuration.PackageSource{}
 
-	tags, err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{})
+	var tags []string
+	err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{}, func(page []string) {
+		tags = append(tags, page...)
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -365,7 +371,10 @@ func TestFetchV2TagsPaginated_TagLimit(t *testing.T) {
 	provider := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone}
 	source := &configuration.PackageSource{TagLimit: 5}
 
-	tags, err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{})
+	var tags []string
+	err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{}, func(page []string) {
+		tags = append(tags, page...)
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -417,7 +426,10 @@ func TestFetchV2TagsPaginated_AuthChallenge(t *testing.T) {
 	}
 	source := &configuration.PackageSource{}
 
-	tags, err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{})
+	var tags []string
+	err := fetchV2TagsPaginated(server.URL, imageInfo, provider, source, &ScrapeOptions{}, func(page []string) {
+		tags = append(tags, page...)
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -452,7 +464,7 @@ func TestExchangeForBearerToken_AccessTokenField(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if token != "alt-token-456" {
-		t.Errorf("token = %q, want %q", token, "alt-token-456")
+	if token.Value != "alt-token-456" {
+		t.Errorf("token = %q, want %q", token.Value, "alt-token-456")
 	}
 }
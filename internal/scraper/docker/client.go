@@ -2,12 +2,35 @@ package docker
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 )
 
 type ScrapeOptions struct {
-	Limit int
+	PaginationStop *configuration.PaginationStop // Stops paginating sorted feeds once the current version is passed
+	HTTPClient     *http.Client                  // Client of all registry requests, optional
+	Candidates     int                           // Newest matching versions kept while streaming tags, all if 0
+}
+
+// candidateLimit returns the number of newest versions kept while streaming tags, 0 to keep
+// all of them
+func (o *ScrapeOptions) candidateLimit() int {
+	if o == nil || o.Candidates < 0 {
+		return 0
+	}
+	return o.Candidates
+}
+
+// httpClient returns the client requests are sent with, a client on the updater transport if
+// none was passed
+func (o *ScrapeOptions) httpClient() *http.Client {
+	if o != nil && o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return util.NewHTTPClient(30 * time.Second)
 }
 
 type DockerProviderClient struct {
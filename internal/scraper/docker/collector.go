@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"container/heap"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// tagCollector parses and filters the tags of a repository page by page as they are fetched,
+// so that repositories with tens of thousands of tags never hold all of them in memory. With
+// a limit, only the newest matching versions up to the limit are kept in a bounded heap.
+type tagCollector struct {
+	tagPattern     *regexp.Regexp
+	excludePattern *regexp.Regexp
+	constraint     *configuration.VersionConstraint
+	limit          int // Versions kept, unbounded if 0
+	versions       versionHeap
+	fetched        int // Tags seen
+	matched        int // Tags passing the patterns and the version constraint
+}
+
+// newTagCollector creates a collector for the tags of a source. The version constraint is
+// only applied with a limit, since the orchestrator checks it on the complete list otherwise.
+func newTagCollector(source *configuration.PackageSource, limit int) (*tagCollector, error) {
+	collector := &tagCollector{
+		limit:    limit,
+		versions: versionHeap{newer: versionOrder(source)},
+	}
+
+	var err error
+	if source.TagPattern != "" {
+		if collector.tagPattern, err = configuration.CompilePattern(source.TagPattern); err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", source.TagPattern, err)
+		}
+	}
+	if source.ExcludePattern != "" {
+		if collector.excludePattern, err = configuration.CompilePattern(source.ExcludePattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", source.ExcludePattern, err)
+		}
+	}
+	if limit > 0 && source.VersionConstraint != "" {
+		if collector.constraint, err = configuration.ParseVersionConstraint(source.VersionConstraint); err != nil {
+			return nil, err
+		}
+	}
+
+	return collector, nil
+}
+
+// add parses and filters a page of tags
+func (c *tagCollector) add(tags []string) {
+	for _, tag := range tags {
+		c.fetched++
+		if c.tagPattern != nil && !c.tagPattern.MatchString(tag) {
+			continue
+		}
+		if c.excludePattern != nil && c.excludePattern.MatchString(tag) {
+			continue
+		}
+
+		version := parseDockerTag(tag)
+		if c.constraint != nil && !c.constraint.Allows(version) {
+			continue
+		}
+		c.matched++
+
+		if c.limit <= 0 || c.versions.Len() < c.limit {
+			heap.Push(&c.versions, version)
+			continue
+		}
+		// Replace the oldest kept version if this one is newer
+		if c.versions.newer(version, c.versions.items[0]) {
+			c.versions.items[0] = version
+			heap.Fix(&c.versions, 0)
+		}
+	}
+}
+
+// result returns the kept versions in no particular order
+func (c *tagCollector) result() []*configuration.PackageSourceVersion {
+	versions := make([]*configuration.PackageSourceVersion, c.versions.Len())
+	copy(versions, c.versions.items)
+	return versions
+}
+
+// versionOrder returns whether a version sorts before another in the order of the source,
+// matching sortVersions. Docker tags carry no dates, so date sorting falls back to semantic.
+func versionOrder(source *configuration.PackageSource) func(a, b *configuration.PackageSourceVersion) bool {
+	if source.SortBy == "alphabetical" {
+		return func(a, b *configuration.PackageSourceVersion) bool {
+			return strings.Compare(a.Version, b.Version) > 0
+		}
+	}
+	return func(a, b *configuration.PackageSourceVersion) bool {
+		return source.CompareVersions(a, b) > 0
+	}
+}
+
+// versionHeap is a min-heap of versions with the oldest version at the root
+type versionHeap struct {
+	items []*configuration.PackageSourceVersion
+	newer func(a, b *configuration.PackageSourceVersion) bool
+}
+
+func (h versionHeap) Len() int           { return len(h.items) }
+func (h versionHeap) Less(i, j int) bool { return h.newer(h.items[j], h.items[i]) }
+func (h versionHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *versionHeap) Push(x any) {
+	h.items = append(h.items, x.(*configuration.PackageSourceVersion))
+}
+
+func (h *versionHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
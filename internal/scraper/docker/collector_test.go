@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestTagCollector(t *testing.T) {
+	pages := [][]string{
+		{"1.0.0", "latest", "2.0.0-alpine", "1.2.0"},
+		{"2.0.0", "0.9.0", "1.10.0", "2.1.0-rc.1"},
+		{"1.9.0", "2.0.1"},
+	}
+
+	tests := []struct {
+		name     string
+		source   *configuration.PackageSource
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "unbounded keeps all matching tags",
+			source:   &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+$`},
+			expected: []string{"2.0.1", "2.0.0", "1.10.0", "1.9.0", "1.2.0", "1.0.0", "0.9.0"},
+		},
+		{
+			name:     "limit keeps the newest versions",
+			source:   &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+$`},
+			limit:    3,
+			expected: []string{"2.0.1", "2.0.0", "1.10.0"},
+		},
+		{
+			name:     "exclude pattern",
+			source:   &configuration.PackageSource{ExcludePattern: `-|latest`},
+			limit:    2,
+			expected: []string{"2.0.1", "2.0.0"},
+		},
+		{
+			name:     "version constraint applies before the limit",
+			source:   &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+$`, VersionConstraint: "<2"},
+			limit:    2,
+			expected: []string{"1.10.0", "1.9.0"},
+		},
+		{
+			name:     "alphabetical order",
+			source:   &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+$`, SortBy: "alphabetical"},
+			limit:    2,
+			expected: []string{"2.0.1", "2.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector, err := newTagCollector(tt.source, tt.limit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, page := range pages {
+				collector.add(page)
+			}
+
+			versions := collector.result()
+			sortVersions(versions, tt.source)
+			var got []string
+			for _, version := range versions {
+				got = append(got, version.Version)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if collector.fetched != 10 {
+				t.Errorf("Expected 10 fetched tags, got %d", collector.fetched)
+			}
+		})
+	}
+
+	if _, err := newTagCollector(&configuration.PackageSource{TagPattern: "("}, 0); err == nil {
+		t.Error("Expected error for invalid tag pattern")
+	}
+}
+
+// benchmarkTags generates n tags shaped like those of a large official image: releases in
+// several variants, e.g. 1.2.3, 1.2.3-alpine or 1.2.3-rc.1-slim
+func benchmarkTags(n int) []string {
+	variants := []string{"", "-alpine", "-slim", "-bookworm", "-rc.1"}
+	tags := make([]string, 0, n)
+	for i := 0; len(tags) < n; i++ {
+		version := fmt.Sprintf("%d.%d.%d", i/400, (i/20)%20, i%20)
+		for _, variant := range variants {
+			if len(tags) == n {
+				break
+			}
+			tags = append(tags, version+variant)
+		}
+	}
+	return tags
+}
+
+func BenchmarkTagCollector(b *testing.B) {
+	tags := benchmarkTags(20000)
+	source := &configuration.PackageSource{TagPattern: `^\d+\.\d+\.\d+`, ExcludePattern: `-rc`}
+
+	for _, limit := range []int{0, 10} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				collector, err := newTagCollector(source, limit)
+				if err != nil {
+					b.Fatal(err)
+				}
+				// Pages of the size returned by the registries
+				for start := 0; start < len(tags); start += 100 {
+					collector.add(tags[start:min(start+100, len(tags))])
+				}
+				sortVersions(collector.result(), source)
+			}
+		})
+	}
+}
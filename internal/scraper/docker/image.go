@@ -5,10 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/rs/zerolog/log"
@@ -28,78 +26,54 @@ func scrapeDockerImage(provider *configuration.PackageSourceProvider, source *co
 	// Build registry URL
 	registryURL := BuildRegistryURL(provider.BaseUrl, imageInfo.Registry)
 
-	// Fetch tags from registry
-	tags, err := fetchDockerTags(registryURL, imageInfo, provider, source, opts)
+	// Filter and parse the tags page by page as they are fetched, keeping only the newest
+	// candidates when the orchestrator needs no more than a bounded number of them
+	collector, err := newTagCollector(source, opts.candidateLimit())
 	if err != nil {
 		return nil, err
 	}
-
-	log.Debug().
-		Int("total_tags_fetched", len(tags)).
-		Str("image", imageInfo.Repository).
-		Msg("fetched tags from registry")
-
-	// Convert ALL tags to PackageSourceVersion objects FIRST
-	allVersions := make([]*configuration.PackageSourceVersion, 0, len(tags))
-	for _, tag := range tags {
-		version := parseDockerTag(tag)
-		allVersions = append(allVersions, version)
-	}
-
-	// Sort ALL versions based on configuration BEFORE filtering
-	sortVersions(allVersions, source)
-
-	log.Debug().
-		Int("total_versions", len(allVersions)).
-		Msg("sorted all versions")
-
-	// NOW filter the sorted versions based on patterns
-	filteredVersions, err := filterVersions(allVersions, source)
-	if err != nil {
+	if err := fetchDockerTags(registryURL, imageInfo, provider, source, opts, collector.add); err != nil {
 		return nil, err
 	}
 
-	log.Debug().
-		Int("filtered_versions", len(filteredVersions)).
-		Int("removed", len(allVersions)-len(filteredVersions)).
-		Msg("filtered versions")
-
-	// Apply limit if specified and we have more versions than requested
-	versions := filteredVersions
-	if opts.Limit > 0 && len(versions) > opts.Limit {
-		versions = versions[:opts.Limit]
-	}
+	versions := collector.result()
+	sortVersions(versions, source)
 
+	// The limit is applied by the orchestrator on the sorted candidate list
 	log.Debug().
 		Int("count", len(versions)).
-		Int("total_fetched", len(tags)).
-		Int("after_filtering", len(filteredVersions)).
-		Int("after_limit", len(versions)).
+		Int("matched", collector.matched).
+		Int("total_fetched", collector.fetched).
+		Int("candidate_limit", collector.limit).
 		Str("image", imageInfo.Repository).
 		Msg("scraped Docker image tags")
 
 	return versions, nil
 }
 
-func fetchDockerTags(registryURL string, imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]string, error) {
+// tagPageFunc receives the tags of each page fetched from a registry
+type tagPageFunc func(tags []string)
+
+// fetchDockerTags fetches the tags of an image page by page, passing each page to onPage
+func fetchDockerTags(registryURL string, imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions, onPage tagPageFunc) error {
 	// Determine if this is Docker Hub or a custom registry
 	isDockerHub := imageInfo.Registry == "" || imageInfo.Registry == "docker.io"
 
 	if isDockerHub {
-		return fetchDockerHubTagsPaginated(imageInfo, provider, source, opts)
+		return fetchDockerHubTagsPaginated(imageInfo, provider, source, opts, onPage)
 	}
 
 	// Docker Registry API v2 for custom registries (ghcr.io, gcr.io, etc.)
 	// Uses token exchange auth flow and pagination
-	return fetchV2TagsPaginated(registryURL, imageInfo, provider, source, opts)
+	return fetchV2TagsPaginated(registryURL, imageInfo, provider, source, opts, onPage)
 }
 
-func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]string, error) {
-	allTags := make([]string, 0)
+func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions, onPage tagPageFunc) error {
+	tagCount := 0
 	pageSize := 100
 	nextURL := fmt.Sprintf("https://registry.hub.docker.com/v2/repositories/%s/tags?page_size=%d", imageInfo.Repository, pageSize)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := opts.httpClient()
 
 	// Determine tag limit (default to 0 = unlimited)
 	tagLimit := source.TagLimit
@@ -111,9 +85,9 @@ func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.P
 
 	for nextURL != "" {
 		// Check if we've reached the tag limit
-		if tagLimit > 0 && len(allTags) >= tagLimit {
+		if tagLimit > 0 && tagCount >= tagLimit {
 			log.Debug().
-				Int("tags_fetched", len(allTags)).
+				Int("tags_fetched", tagCount).
 				Int("tag_limit", tagLimit).
 				Msg("reached tag limit, stopping pagination")
 			break
@@ -127,7 +101,7 @@ func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.P
 
 		request, err := http.NewRequest("GET", nextURL, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return fmt.Errorf("failed to create request: %w", err)
 		}
 
 		// Add authentication if configured
@@ -139,19 +113,19 @@ func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.P
 
 		response, err := client.Do(request)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+			return fmt.Errorf("failed to fetch tags: %w", err)
 		}
 
 		if response.StatusCode != http.StatusOK {
 			response.Body.Close()
-			return nil, fmt.Errorf("failed to fetch tags: HTTP %d", response.StatusCode)
+			return fmt.Errorf("failed to fetch tags: HTTP %d", response.StatusCode)
 		}
 
 		body, err := io.ReadAll(response.Body)
 		response.Body.Close()
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to read tags response: %w", err)
+			return fmt.Errorf("failed to read tags response: %w", err)
 		}
 
 		var pageResponse struct {
@@ -163,81 +137,49 @@ func fetchDockerHubTagsPaginated(imageInfo *ImageInfo, provider *configuration.P
 		}
 
 		if err := json.Unmarshal(body, &pageResponse); err != nil {
-			return nil, fmt.Errorf("failed to parse Docker Hub response: %w", err)
+			return fmt.Errorf("failed to parse Docker Hub response: %w", err)
 		}
 
+		pageTags := make([]string, 0, len(pageResponse.Results))
 		for _, result := range pageResponse.Results {
-			// Check tag limit before adding more tags
-			if tagLimit > 0 && len(allTags) >= tagLimit {
-				break
-			}
-			allTags = append(allTags, result.Name)
+			pageTags = append(pageTags, result.Name)
+		}
+		// Only pass on tags up to the tag limit
+		kept := pageTags
+		if tagLimit > 0 && tagCount+len(kept) > tagLimit {
+			kept = kept[:tagLimit-tagCount]
 		}
+		tagCount += len(kept)
+		onPage(kept)
 
 		// Use the Next URL from the response, or stop if there isn't one
 		nextURL = pageResponse.Next
 
+		// Stop once the current version of all targets has been passed
+		if nextURL != "" && opts.PaginationStop.Passed(pageTags) {
+			log.Debug().
+				Int("page", pageCount).
+				Int("tags_fetched", tagCount).
+				Msg("passed current version, stopping pagination")
+			nextURL = ""
+		}
+
 		log.Trace().
 			Int("page", pageCount).
 			Int("page_tags", len(pageResponse.Results)).
-			Int("total_tags", len(allTags)).
+			Int("total_tags", tagCount).
 			Bool("has_next", nextURL != "").
 			Msg("fetched Docker Hub tags page")
 	}
 
 	log.Debug().
-		Int("total_tags", len(allTags)).
+		Int("total_tags", tagCount).
 		Int("pages", pageCount).
 		Int("tag_limit", tagLimit).
-		Bool("limit_reached", tagLimit > 0 && len(allTags) >= tagLimit).
+		Bool("limit_reached", tagLimit > 0 && tagCount >= tagLimit).
 		Msg("finished fetching Docker Hub tags")
 
-	return allTags, nil
-}
-
-func filterVersions(versions []*configuration.PackageSourceVersion, source *configuration.PackageSource) ([]*configuration.PackageSourceVersion, error) {
-	// Compile regex patterns once before the loop
-	var tagPatternRe *regexp.Regexp
-	if source.TagPattern != "" {
-		var err error
-		tagPatternRe, err = regexp.Compile(source.TagPattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid tag pattern %q: %w", source.TagPattern, err)
-		}
-	}
-
-	var excludePatternRe *regexp.Regexp
-	if source.ExcludePattern != "" {
-		var err error
-		excludePatternRe, err = regexp.Compile(source.ExcludePattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid exclude pattern %q: %w", source.ExcludePattern, err)
-		}
-	}
-
-	filtered := make([]*configuration.PackageSourceVersion, 0, len(versions))
-
-	for _, version := range versions {
-		tag := version.Version
-
-		// Apply tag pattern if specified
-		if tagPatternRe != nil {
-			if !tagPatternRe.MatchString(tag) {
-				continue
-			}
-		}
-
-		// Apply exclude pattern if specified
-		if excludePatternRe != nil {
-			if excludePatternRe.MatchString(tag) {
-				continue
-			}
-		}
-
-		filtered = append(filtered, version)
-	}
-
-	return filtered, nil
+	return nil
 }
 
 func sortVersions(versions []*configuration.PackageSourceVersion, source *configuration.PackageSource) {
@@ -250,16 +192,8 @@ func sortVersions(versions []*configuration.PackageSourceVersion, source *config
 	case "semantic":
 		// Sort by semantic version (highest first)
 		sort.Slice(versions, func(i, j int) bool {
-			// Compare major version
-			if versions[i].MajorVersion != versions[j].MajorVersion {
-				return versions[i].MajorVersion > versions[j].MajorVersion
-			}
-			// Compare minor version
-			if versions[i].MinorVersion != versions[j].MinorVersion {
-				return versions[i].MinorVersion > versions[j].MinorVersion
-			}
-			// Compare patch version
-			return versions[i].PatchVersion > versions[j].PatchVersion
+			// Compare by the version scheme of the source
+			return source.CompareVersions(versions[i], versions[j]) > 0
 		})
 	case "alphabetical":
 		// Sort alphabetically
@@ -270,10 +204,10 @@ func sortVersions(versions []*configuration.PackageSourceVersion, source *config
 		// For Docker tags, we don't have date information from the tags list
 		// This would require additional API calls to get image metadata
 		log.Warn().Msg("date sorting not yet implemented for Docker images, using semantic sort")
-		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic"})
+		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic", VersionScheme: source.VersionScheme})
 	default:
 		log.Warn().Str("sortBy", sortBy).Msg("unknown sort method, using semantic")
-		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic"})
+		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic", VersionScheme: source.VersionScheme})
 	}
 }
 
@@ -283,6 +217,7 @@ func parseDockerTag(tag string) *configuration.PackageSourceVersion {
 	}
 
 	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(tag)
+	version.BuildVersion, version.Revision = configuration.ParseBuild(tag)
 
 	// Add additional tag information if it's not a plain version
 	if strings.Contains(tag, "-") || strings.Contains(tag, "_") {
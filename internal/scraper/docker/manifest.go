@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// dockerHubRegistryURL serves the manifests of Docker Hub images. The Hub API scraped for
+// tags does not serve manifests.
+const dockerHubRegistryURL = "https://registry-1.docker.io"
+
+// manifestMediaTypes are the manifest and index formats accepted from registries
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var (
+	// ErrManifestNotFound is returned if a tag is not published in the registry
+	ErrManifestNotFound = errors.New("manifest not found")
+	// ErrPlatformNotFound is returned if a tag is not published for a required platform
+	ErrPlatformNotFound = errors.New("platform not found")
+)
+
+// manifest holds the fields of an image manifest or index needed to match platforms
+type manifest struct {
+	Manifests []struct {
+		Platform *imagePlatform `json:"platform"`
+	} `json:"manifests"`
+	Config *struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// imagePlatform is the platform of an index entry or image config
+type imagePlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant"`
+}
+
+// CheckManifest verifies that a tag of an image can be pulled from the image's registry and
+// is published for all given platforms. The tag is checked with a HEAD request; the manifest
+// and, for single-platform images, its config are only fetched to match platforms. The
+// provider supplies credentials, anonymous access is used without one.
+func CheckManifest(client *http.Client, provider *configuration.PackageSourceProvider, image string, tag string, platforms []configuration.Platform) error {
+	imageInfo, registryURL, err := registryLocation(image)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		provider = &configuration.PackageSourceProvider{}
+	}
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", registryURL, imageInfo.Repository, url.PathEscape(tag))
+	header := http.Header{"Accept": manifestMediaTypes}
+
+	if _, err := headManifest(client, provider, image, tag, imageInfo.Repository, manifestURL, header); err != nil {
+		return err
+	}
+
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	available, err := manifestPlatforms(client, provider, registryURL, imageInfo.Repository, manifestURL, header)
+	if err != nil {
+		return fmt.Errorf("failed to read platforms of %s:%s: %w", image, tag, err)
+	}
+	for _, platform := range platforms {
+		if !containsPlatform(available, platform) {
+			return fmt.Errorf("%s:%s is not published for %s: %w", image, tag, platform, ErrPlatformNotFound)
+		}
+	}
+
+	log.Debug().
+		Str("image", image).
+		Str("tag", tag).
+		Int("platforms", len(available)).
+		Msg("Image manifest found for all platforms")
+	return nil
+}
+
+// ManifestDigest returns the digest of the manifest a tag of an image points to, the index
+// for multi-platform images. The digest is read from the Docker-Content-Digest header of a
+// HEAD request, or computed from the manifest if the registry does not send it.
+func ManifestDigest(client *http.Client, provider *configuration.PackageSourceProvider, image string, tag string) (string, error) {
+	imageInfo, registryURL, err := registryLocation(image)
+	if err != nil {
+		return "", err
+	}
+	if provider == nil {
+		provider = &configuration.PackageSourceProvider{}
+	}
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", registryURL, imageInfo.Repository, url.PathEscape(tag))
+	header := http.Header{"Accept": manifestMediaTypes}
+
+	resp, err := headManifest(client, provider, image, tag, imageInfo.Repository, manifestURL, header)
+	if err != nil {
+		return "", err
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	resp, err = doRegistryRequest(client, http.MethodGet, manifestURL, header, provider, imageInfo.Repository)
+	if err != nil {
+		return "", fmt.Errorf("manifest request for %s:%s failed: %w", image, tag, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("manifest request for %s:%s failed: HTTP %d", image, tag, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest of %s:%s: %w", image, tag, err)
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// registryLocation parses an image and returns the URL of the registry serving its manifests
+func registryLocation(image string) (*ImageInfo, string, error) {
+	imageInfo, err := ParseImageURL(image)
+	if err != nil {
+		return nil, "", err
+	}
+	if imageInfo.Registry == "" {
+		return imageInfo, dockerHubRegistryURL, nil
+	}
+	return imageInfo, BuildRegistryURL("", imageInfo.Registry), nil
+}
+
+// headManifest sends a HEAD request for the manifest of a tag and fails unless it exists
+func headManifest(client *http.Client, provider *configuration.PackageSourceProvider, image string, tag string, repository string, manifestURL string, header http.Header) (*http.Response, error) {
+	resp, err := doRegistryRequest(client, http.MethodHead, manifestURL, header, provider, repository)
+	if err != nil {
+		return nil, fmt.Errorf("manifest request for %s:%s failed: %w", image, tag, err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s:%s: %w", image, tag, ErrManifestNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("manifest request for %s:%s failed: HTTP %d", image, tag, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// manifestPlatforms returns the platforms a manifest is published for, the entries of an
+// index or the platform of the config of a single image
+func manifestPlatforms(client *http.Client, provider *configuration.PackageSourceProvider, registryURL string, repository string, manifestURL string, header http.Header) ([]imagePlatform, error) {
+	var parsed manifest
+	if err := getRegistryJSON(client, provider, repository, manifestURL, header, &parsed); err != nil {
+		return nil, err
+	}
+
+	if len(parsed.Manifests) > 0 {
+		platforms := make([]imagePlatform, 0, len(parsed.Manifests))
+		for _, entry := range parsed.Manifests {
+			if entry.Platform != nil {
+				platforms = append(platforms, *entry.Platform)
+			}
+		}
+		return platforms, nil
+	}
+
+	if parsed.Config == nil || parsed.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest has neither entries nor a config")
+	}
+	var config imagePlatform
+	blobURL := fmt.Sprintf("%s/v2/%s/blobs/%s", registryURL, repository, parsed.Config.Digest)
+	if err := getRegistryJSON(client, provider, repository, blobURL, nil, &config); err != nil {
+		return nil, err
+	}
+	return []imagePlatform{config}, nil
+}
+
+// getRegistryJSON fetches a registry document and decodes it into value
+func getRegistryJSON(client *http.Client, provider *configuration.PackageSourceProvider, repository string, requestURL string, header http.Header, value any) error {
+	resp, err := doRegistryRequest(client, http.MethodGet, requestURL, header, provider, repository)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, requestURL)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(body, value); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// containsPlatform reports whether a required platform is available. A required platform
+// without variant matches every variant.
+func containsPlatform(available []imagePlatform, required configuration.Platform) bool {
+	for _, platform := range available {
+		if platform.OS != required.OS || platform.Architecture != required.Architecture {
+			continue
+		}
+		if required.Variant == "" || platform.Variant == required.Variant {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// newManifestRegistry starts a TLS registry serving the given manifests by tag and blobs by
+// digest for the repository team/app, and returns the image name pointing to it
+func newManifestRegistry(t *testing.T, manifests map[string]string, blobs map[string]string) (*httptest.Server, string) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tag, ok := strings.CutPrefix(r.URL.Path, "/v2/team/app/manifests/"); ok {
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("manifest request without index media type: %q", r.Header.Get("Accept"))
+			}
+			body, found := manifests[tag]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if body == "error" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if r.Method == http.MethodHead {
+				return
+			}
+			fmt.Fprint(w, body)
+			return
+		}
+		if digest, ok := strings.CutPrefix(r.URL.Path, "/v2/team/app/blobs/"); ok {
+			if body, found := blobs[digest]; found {
+				fmt.Fprint(w, body)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return server, strings.TrimPrefix(server.URL, "https://") + "/team/app"
+}
+
+func TestCheckManifest(t *testing.T) {
+	manifests := map[string]string{
+		"1.0.0": `{"manifests": [
+			{"platform": {"os": "linux", "architecture": "amd64"}},
+			{"platform": {"os": "linux", "architecture": "arm", "variant": "v7"}}
+		]}`,
+		"1.1.0": `{"config": {"digest": "sha256:abc"}}`,
+		"1.2.0": "error",
+	}
+	blobs := map[string]string{
+		"sha256:abc": `{"os": "linux", "architecture": "arm64", "variant": "v8"}`,
+	}
+	server, image := newManifestRegistry(t, manifests, blobs)
+
+	tests := []struct {
+		name        string
+		tag         string
+		platforms   []string
+		expectedErr error
+		errContains string
+	}{
+		{name: "tag exists", tag: "1.0.0"},
+		{name: "index platform", tag: "1.0.0", platforms: []string{"linux/amd64"}},
+		{name: "index platform with variant", tag: "1.0.0", platforms: []string{"linux/amd64", "linux/arm/v7"}},
+		{name: "index without platform", tag: "1.0.0", platforms: []string{"linux/arm64"}, expectedErr: ErrPlatformNotFound},
+		{name: "index without variant", tag: "1.0.0", platforms: []string{"linux/arm/v6"}, expectedErr: ErrPlatformNotFound},
+		{name: "single image config platform", tag: "1.1.0", platforms: []string{"linux/arm64"}},
+		{name: "single image other platform", tag: "1.1.0", platforms: []string{"linux/amd64"}, expectedErr: ErrPlatformNotFound},
+		{name: "tag missing", tag: "2.0.0", expectedErr: ErrManifestNotFound},
+		{name: "registry error", tag: "1.2.0", errContains: "HTTP 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platforms := make([]configuration.Platform, 0, len(tt.platforms))
+			for _, platform := range tt.platforms {
+				parsed, err := configuration.ParsePlatform(platform)
+				if err != nil {
+					t.Fatalf("ParsePlatform() error = %v", err)
+				}
+				platforms = append(platforms, parsed)
+			}
+
+			err := CheckManifest(server.Client(), nil, image, tt.tag, platforms)
+			switch {
+			case tt.expectedErr != nil:
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected %v, got %v", tt.expectedErr, err)
+				}
+			case tt.errContains != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckManifest_Credentials(t *testing.T) {
+	var methods []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "deploy" || pass != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{
+		AuthType: configuration.PackageSourceProviderAuthTypeBasic,
+		Username: "deploy",
+		Password: "secret",
+	}
+	image := strings.TrimPrefix(server.URL, "https://") + "/team/app"
+	if err := CheckManifest(server.Client(), provider, image, "1.0.0", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("expected a single HEAD request, got %v", methods)
+	}
+}
+
+func TestManifestDigest(t *testing.T) {
+	manifest := `{"config": {"digest": "sha256:abc"}}`
+	sum := sha256.Sum256([]byte(manifest))
+	server, image := newManifestRegistry(t, map[string]string{"1.0.0": manifest}, nil)
+
+	// Without a digest header, the digest is computed from the manifest
+	digest, err := ManifestDigest(server.Client(), nil, image, "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "sha256:" + hex.EncodeToString(sum[:]); digest != expected {
+		t.Errorf("expected computed digest %s, got %s", expected, digest)
+	}
+
+	if _, err := ManifestDigest(server.Client(), nil, image, "2.0.0"); !errors.Is(err, ErrManifestNotFound) {
+		t.Errorf("expected %v for a missing tag, got %v", ErrManifestNotFound, err)
+	}
+}
+
+func TestManifestDigest_Header(t *testing.T) {
+	var methods []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Header().Set("Docker-Content-Digest", "sha256:def")
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "https://") + "/team/app"
+	digest, err := ManifestDigest(server.Client(), nil, image, "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:def" {
+		t.Errorf("expected digest from header, got %s", digest)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("expected a single HEAD request, got %v", methods)
+	}
+}
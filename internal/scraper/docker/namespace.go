@@ -0,0 +1,266 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// APIs namespaces are listed with, variables so tests can serve them locally
+var (
+	dockerHubAPIURL = "https://registry.hub.docker.com"
+	gitHubAPIURL    = "https://api.github.com"
+)
+
+// NamespaceInfo contains parsed registry namespace information
+type NamespaceInfo struct {
+	Registry  string // e.g., "ghcr.io", "harbor.example.com", or empty for Docker Hub
+	Namespace string // e.g., "myorg", or the project of a Harbor registry
+}
+
+// ParseNamespaceURL extracts registry and namespace from a namespace URI
+// Supports multiple URI formats:
+// - myorg (Docker Hub namespace)
+// - ghcr.io/myorg (GitHub Container Registry organization or user)
+// - harbor.example.com/myproject (Harbor project)
+// - registry.example.com:5000/myorg/team (Custom registry, nested namespace)
+func ParseNamespaceURL(uri string) (*NamespaceInfo, error) {
+	uri = strings.TrimPrefix(uri, "docker://")
+	uri = strings.TrimPrefix(uri, "https://")
+	uri = strings.TrimPrefix(uri, "http://")
+	uri = strings.Trim(uri, "/")
+	if uri == "" {
+		return nil, fmt.Errorf("empty namespace URI provided")
+	}
+
+	info := &NamespaceInfo{Namespace: uri}
+	parts := strings.SplitN(uri, "/", 2)
+	firstPart := parts[0]
+	if strings.Contains(firstPart, ".") || strings.Contains(firstPart, ":") || firstPart == "localhost" {
+		if len(parts) == 1 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid namespace URI: %s (no namespace found)", uri)
+		}
+		info.Registry = firstPart
+		info.Namespace = parts[1]
+	}
+
+	// Normalize Docker Hub registry
+	if info.Registry == "docker.io" || info.Registry == "index.docker.io" {
+		info.Registry = ""
+	}
+	return info, nil
+}
+
+// ImageURI returns the URI of a repository of the namespace as docker-image sources use it
+func (n *NamespaceInfo) ImageURI(repository string) string {
+	if n.Registry == "" {
+		return n.Namespace + "/" + repository
+	}
+	return n.Registry + "/" + n.Namespace + "/" + repository
+}
+
+// listStatusError is returned for a repository list request answered with an error status
+type listStatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *listStatusError) Error() string {
+	return fmt.Sprintf("failed to list repositories: HTTP %d from %s", e.StatusCode, e.URL)
+}
+
+// repositoryPageFunc decodes a page of a repository list into repository names and the URL of
+// the next page, if the API returns it in the body
+type repositoryPageFunc func(body []byte) ([]string, string, error)
+
+// ListRepositories returns the repositories of a namespace, relative to the namespace and
+// sorted. Docker Hub namespaces are listed with the Docker Hub API, ghcr.io namespaces with
+// the GitHub packages API, namespaces of harbor providers with the Harbor project API and all
+// other registries with the /v2/_catalog endpoint.
+func ListRepositories(provider *configuration.PackageSourceProvider, namespace *NamespaceInfo, opts *ScrapeOptions) ([]string, error) {
+	var repositories []string
+	var err error
+	switch {
+	case namespace.Registry == "":
+		repositories, err = listDockerHubRepositories(provider, namespace, opts)
+	case namespace.Registry == "ghcr.io" && provider.BaseUrl == "":
+		repositories, err = listGitHubPackages(provider, namespace, opts)
+	case provider.Type == configuration.PackageSourceProviderTypeHarbor:
+		repositories, err = listHarborRepositories(provider, namespace, opts)
+	default:
+		repositories, err = listCatalogRepositories(provider, namespace, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(repositories)
+	log.Debug().
+		Str("registry", namespace.Registry).
+		Str("namespace", namespace.Namespace).
+		Int("count", len(repositories)).
+		Msg("listed namespace repositories")
+	return repositories, nil
+}
+
+// listDockerHubRepositories lists a Docker Hub namespace, following the next links of the body
+func listDockerHubRepositories(provider *configuration.PackageSourceProvider, namespace *NamespaceInfo, opts *ScrapeOptions) ([]string, error) {
+	client := opts.httpClient()
+	request := func(requestURL string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		applyStaticAuth(req, provider)
+		return client.Do(req)
+	}
+
+	firstURL := fmt.Sprintf("%s/v2/repositories/%s/?page_size=100", dockerHubAPIURL, url.PathEscape(namespace.Namespace))
+	return listRepositoryPages(firstURL, dockerHubAPIURL, request, func(body []byte) ([]string, string, error) {
+		var page struct {
+			Next    string `json:"next"`
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, "", err
+		}
+		names := make([]string, 0, len(page.Results))
+		for _, result := range page.Results {
+			names = append(names, result.Name)
+		}
+		return names, page.Next, nil
+	})
+}
+
+// listGitHubPackages lists the container packages of a GitHub organization, or of a user if
+// no organization of the name exists
+func listGitHubPackages(provider *configuration.PackageSourceProvider, namespace *NamespaceInfo, opts *ScrapeOptions) ([]string, error) {
+	client := opts.httpClient()
+	request := func(requestURL string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		applyStaticAuth(req, provider)
+		return client.Do(req)
+	}
+	page := func(body []byte) ([]string, string, error) {
+		var packages []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &packages); err != nil {
+			return nil, "", err
+		}
+		names := make([]string, 0, len(packages))
+		for _, pkg := range packages {
+			names = append(names, pkg.Name)
+		}
+		return names, "", nil
+	}
+
+	owner := url.PathEscape(namespace.Namespace)
+	names, err := listRepositoryPages(fmt.Sprintf("%s/orgs/%s/packages?package_type=container&per_page=100", gitHubAPIURL, owner), gitHubAPIURL, request, page)
+	var statusErr *listStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return listRepositoryPages(fmt.Sprintf("%s/users/%s/packages?package_type=container&per_page=100", gitHubAPIURL, owner), gitHubAPIURL, request, page)
+	}
+	return names, err
+}
+
+// listHarborRepositories lists the repositories of a Harbor project. Harbor returns their
+// names with the project prefix, which is stripped.
+func listHarborRepositories(provider *configuration.PackageSourceProvider, namespace *NamespaceInfo, opts *ScrapeOptions) ([]string, error) {
+	client := opts.httpClient()
+	registryURL := BuildRegistryURL(provider.BaseUrl, namespace.Registry)
+	request := func(requestURL string) (*http.Response, error) {
+		return doAuthenticatedRequest(client, requestURL, provider, namespace.Namespace)
+	}
+
+	firstURL := fmt.Sprintf("%s/api/v2.0/projects/%s/repositories?page_size=100", registryURL, url.PathEscape(namespace.Namespace))
+	return listRepositoryPages(firstURL, registryURL, request, func(body []byte) ([]string, string, error) {
+		var repositories []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &repositories); err != nil {
+			return nil, "", err
+		}
+		names := make([]string, 0, len(repositories))
+		for _, repository := range repositories {
+			names = append(names, strings.TrimPrefix(repository.Name, namespace.Namespace+"/"))
+		}
+		return names, "", nil
+	})
+}
+
+// listCatalogRepositories lists the repositories of a V2 registry below the namespace
+func listCatalogRepositories(provider *configuration.PackageSourceProvider, namespace *NamespaceInfo, opts *ScrapeOptions) ([]string, error) {
+	client := opts.httpClient()
+	registryURL := BuildRegistryURL(provider.BaseUrl, namespace.Registry)
+	request := func(requestURL string) (*http.Response, error) {
+		return doAuthenticatedRequest(client, requestURL, provider, namespace.Namespace)
+	}
+
+	prefix := namespace.Namespace + "/"
+	return listRepositoryPages(registryURL+"/v2/_catalog?n=100", registryURL, request, func(body []byte) ([]string, string, error) {
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+		if err := json.Unmarshal(body, &catalog); err != nil {
+			return nil, "", err
+		}
+		var names []string
+		for _, repository := range catalog.Repositories {
+			if strings.HasPrefix(repository, prefix) {
+				names = append(names, strings.TrimPrefix(repository, prefix))
+			}
+		}
+		return names, "", nil
+	})
+}
+
+// listRepositoryPages fetches all pages of a repository list, following the next URL of the
+// body or else the Link header of each page
+func listRepositoryPages(nextURL string, baseURL string, request func(string) (*http.Response, error), decode repositoryPageFunc) ([]string, error) {
+	var repositories []string
+	visited := make(map[string]bool)
+	for nextURL != "" && !visited[nextURL] {
+		visited[nextURL] = true
+		log.Trace().Str("url", nextURL).Msg("fetching repository list page")
+
+		resp, err := request(nextURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, &listStatusError{StatusCode: resp.StatusCode, URL: nextURL}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read repository list: %w", err)
+		}
+
+		names, next, err := decode(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse repository list: %w", err)
+		}
+		repositories = append(repositories, names...)
+
+		if next == "" {
+			next = getNextPageURL(resp.Header.Get("Link"), baseURL)
+		}
+		nextURL = next
+	}
+	return repositories, nil
+}
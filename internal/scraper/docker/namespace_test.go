@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestParseNamespaceURL(t *testing.T) {
+	tests := []struct {
+		uri          string
+		wantRegistry string
+		wantNS       string
+		wantImageURI string
+		wantErr      bool
+	}{
+		{uri: "myorg", wantNS: "myorg", wantImageURI: "myorg/app"},
+		{uri: "docker.io/myorg", wantNS: "myorg", wantImageURI: "myorg/app"},
+		{uri: "ghcr.io/myorg", wantRegistry: "ghcr.io", wantNS: "myorg", wantImageURI: "ghcr.io/myorg/app"},
+		{uri: "https://harbor.example.com/project/", wantRegistry: "harbor.example.com", wantNS: "project", wantImageURI: "harbor.example.com/project/app"},
+		{uri: "localhost:5000/org/team", wantRegistry: "localhost:5000", wantNS: "org/team", wantImageURI: "localhost:5000/org/team/app"},
+		{uri: "ghcr.io", wantErr: true},
+		{uri: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			info, err := ParseNamespaceURL(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNamespaceURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if info.Registry != tt.wantRegistry || info.Namespace != tt.wantNS {
+				t.Errorf("ParseNamespaceURL() = %+v, want registry %q namespace %q", info, tt.wantRegistry, tt.wantNS)
+			}
+			if uri := info.ImageURI("app"); uri != tt.wantImageURI {
+				t.Errorf("ImageURI() = %q, want %q", uri, tt.wantImageURI)
+			}
+		})
+	}
+}
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/_catalog":
+			// Token exchange as registries require for the catalog
+			if r.Header.Get("Authorization") != "Bearer catalog-token" {
+				w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",scope="registry:catalog:*"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/_catalog?n=100&last=acme/web>; rel="next"`)
+				io.WriteString(w, `{"repositories":["acme/api","acme/web"]}`)
+				return
+			}
+			io.WriteString(w, `{"repositories":["acme/team/worker","other/api"]}`)
+		case "/token":
+			io.WriteString(w, `{"token":"catalog-token"}`)
+		case "/api/v2.0/projects/acme/repositories":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `</api/v2.0/projects/acme/repositories?page=2&page_size=100>; rel="next"`)
+				io.WriteString(w, `[{"name":"acme/web"},{"name":"acme/api"}]`)
+				return
+			}
+			io.WriteString(w, `[{"name":"acme/worker"}]`)
+		case "/v2/repositories/acme/":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"next":"http://%s/v2/repositories/acme/?page=2","results":[{"name":"web"}]}`, r.Host)
+				return
+			}
+			io.WriteString(w, `{"next":null,"results":[{"name":"api"}]}`)
+		case "/orgs/someone/packages":
+			w.WriteHeader(http.StatusNotFound)
+		case "/users/someone/packages":
+			if r.URL.Query().Get("package_type") != "container" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			io.WriteString(w, `[{"name":"dotfiles"},{"name":"blog"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(dockerHub string, gitHub string) { dockerHubAPIURL, gitHubAPIURL = dockerHub, gitHub }(dockerHubAPIURL, gitHubAPIURL)
+	dockerHubAPIURL, gitHubAPIURL = server.URL, server.URL
+
+	tests := []struct {
+		name      string
+		provider  *configuration.PackageSourceProvider
+		namespace string
+		expected  []string
+	}{
+		{
+			name:      "registry catalog",
+			provider:  &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeDocker, BaseUrl: server.URL},
+			namespace: "registry.example.com/acme",
+			expected:  []string{"api", "team/worker", "web"},
+		},
+		{
+			name:      "harbor project",
+			provider:  &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeHarbor, BaseUrl: server.URL},
+			namespace: "harbor.example.com/acme",
+			expected:  []string{"api", "web", "worker"},
+		},
+		{
+			name:      "docker hub",
+			provider:  &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeDocker},
+			namespace: "acme",
+			expected:  []string{"api", "web"},
+		},
+		{
+			name:      "ghcr user packages",
+			provider:  &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeDocker},
+			namespace: "ghcr.io/someone",
+			expected:  []string{"blog", "dotfiles"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseNamespaceURL(tt.namespace)
+			if err != nil {
+				t.Fatalf("ParseNamespaceURL() error = %v", err)
+			}
+			repositories, err := ListRepositories(tt.provider, info, &ScrapeOptions{HTTPClient: server.Client()})
+			if err != nil {
+				t.Fatalf("ListRepositories() error = %v", err)
+			}
+			if !reflect.DeepEqual(repositories, tt.expected) {
+				t.Errorf("ListRepositories() = %v, want %v", repositories, tt.expected)
+			}
+		})
+	}
+
+	t.Run("error status", func(t *testing.T) {
+		provider := &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeHarbor, BaseUrl: server.URL}
+		_, err := ListRepositories(provider, &NamespaceInfo{Registry: "harbor.example.com", Namespace: "missing"}, &ScrapeOptions{HTTPClient: server.Client()})
+		if err == nil {
+			t.Fatal("expected an error for a missing project")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenBatchSize is the number of repository scopes requested in one token exchange, which
+// keeps the token request URL and the token itself small enough for any registry
+const tokenBatchSize = 25
+
+// PrefetchTokens exchanges the bearer tokens for the docker-image sources of a provider in
+// batches: one token exchange per registry and up to 25 repositories, each requested with
+// its own pull scope, instead of one exchange per repository. The tokens are cached for the
+// requests of the scrape. Docker Hub images, which are listed through the Docker Hub API,
+// and registries accepting the static credentials of the provider need no tokens.
+// Repositories whose scope the registry did not grant are challenged and exchanged one by
+// one when they are scraped.
+func PrefetchTokens(client *http.Client, provider *configuration.PackageSourceProvider, sources []*configuration.PackageSource) error {
+	// Repositories by registry URL, the same URL the tags are fetched from
+	repositories := make(map[string][]string)
+	for _, source := range sources {
+		if source.Type != configuration.PackageSourceTypeDockerImage {
+			continue
+		}
+		imageInfo, err := ParseImageURL(source.URI)
+		if err != nil || imageInfo.Registry == "" {
+			continue
+		}
+		registryURL := BuildRegistryURL(provider.BaseUrl, imageInfo.Registry)
+		if !containsString(repositories[registryURL], imageInfo.Repository) {
+			repositories[registryURL] = append(repositories[registryURL], imageInfo.Repository)
+		}
+	}
+
+	registryURLs := make([]string, 0, len(repositories))
+	for registryURL := range repositories {
+		registryURLs = append(registryURLs, registryURL)
+	}
+	sort.Strings(registryURLs)
+
+	var errs []error
+	for _, registryURL := range registryURLs {
+		if err := prefetchRegistryTokens(client, provider, registryURL, repositories[registryURL]); err != nil {
+			errs = append(errs, fmt.Errorf("registry %s: %w", registryURL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prefetchRegistryTokens exchanges the tokens for the repositories of one registry. The
+// registry is probed at its API base for the challenge, which names the token realm and
+// service shared by all of its repositories.
+func prefetchRegistryTokens(client *http.Client, provider *configuration.PackageSourceProvider, registryURL string, repositories []string) error {
+	baseURL := registryURL + "/v2/"
+	req, err := http.NewRequest(http.MethodGet, baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	applyStaticAuth(req, provider)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		log.Debug().Str("registry", registryURL).Int("status", resp.StatusCode).Msg("registry accepts requests without bearer token, skipping token prefetch")
+		return nil
+	}
+
+	challenge, err := parseWwwAuthenticate(resp.Header.Get("Www-Authenticate"))
+	if err != nil {
+		return fmt.Errorf("failed to parse auth challenge: %w", err)
+	}
+
+	for start := 0; start < len(repositories); start += tokenBatchSize {
+		batch := repositories[start:min(start+tokenBatchSize, len(repositories))]
+		scopes := make([]string, 0, len(batch))
+		for _, repository := range batch {
+			scopes = append(scopes, fmt.Sprintf("repository:%s:pull", repository))
+		}
+		batchChallenge := &wwwAuthenticateChallenge{
+			Realm:   challenge.Realm,
+			Service: challenge.Service,
+			Scope:   strings.Join(scopes, " "),
+		}
+
+		token, err := exchangeForBearerToken(client, batchChallenge, provider, "")
+		if err != nil {
+			return fmt.Errorf("failed to exchange for bearer token: %w", err)
+		}
+		now := time.Now()
+		for _, repository := range batch {
+			registryTokens.put(repositoryKey(baseURL, provider, repository), tokenKey(batchChallenge, provider), token, now)
+		}
+
+		log.Debug().
+			Str("registry", registryURL).
+			Int("repositories", len(batch)).
+			Msg("prefetched bearer token for a batch of repositories")
+	}
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// scopeRegistry is a registry granting tokens for the requested pull scopes. The scopes of
+// denied repositories are only granted when requested on their own.
+type scopeRegistry struct {
+	mu        sync.Mutex
+	denied    map[string]bool
+	exchanges [][]string          // Scopes requested by each exchange
+	granted   map[string][]string // Repositories by token
+}
+
+func (r *scopeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		scopes := req.URL.Query()["scope"]
+		r.exchanges = append(r.exchanges, scopes)
+		token := fmt.Sprintf("token-%d", len(r.exchanges))
+		for _, scope := range scopes {
+			repository := strings.TrimSuffix(strings.TrimPrefix(scope, "repository:"), ":pull")
+			if !r.denied[repository] || len(scopes) == 1 {
+				r.granted[token] = append(r.granted[token], repository)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"token": token, "expires_in": 300})
+		return
+	}
+
+	repository := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/v2/"), "/tags/list")
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if repository != "" && containsString(r.granted[token], repository) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"name": %q, "tags": ["1.0.0"]}`, repository)
+		return
+	}
+
+	challenge := fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, req.Host)
+	if repository != "" {
+		challenge += fmt.Sprintf(`,scope="repository:%s:pull"`, repository)
+	}
+	w.Header().Set("Www-Authenticate", challenge)
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+func TestPrefetchTokens(t *testing.T) {
+	registry := &scopeRegistry{
+		denied:  map[string]bool{"org/private": true},
+		granted: make(map[string][]string),
+	}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	// Sources are keyed by host and credentials, a unique user keeps them apart from other tests
+	provider := &configuration.PackageSourceProvider{
+		Type:     configuration.PackageSourceProviderTypeHarbor,
+		BaseUrl:  server.URL,
+		AuthType: configuration.PackageSourceProviderAuthTypeBasic,
+		Username: t.Name(),
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	var sources []*configuration.PackageSource
+	for i := range 30 {
+		sources = append(sources, &configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: fmt.Sprintf("%s/org/app-%d", host, i)})
+	}
+	sources = append(sources,
+		&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: host + "/org/app-0:1.0.0"},
+		&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: host + "/org/private"},
+		&configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: "nginx"},
+		&configuration.PackageSource{Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/org/repo"},
+	)
+
+	if err := PrefetchTokens(server.Client(), provider, sources); err != nil {
+		t.Fatalf("PrefetchTokens() failed: %v", err)
+	}
+	if len(registry.exchanges) != 2 || len(registry.exchanges[0]) != tokenBatchSize || len(registry.exchanges[1]) != 6 {
+		t.Fatalf("expected two exchanges of 25 and 6 scopes, got %v", registry.exchanges)
+	}
+	if registry.exchanges[0][0] != "repository:org/app-0:pull" {
+		t.Errorf("unexpected scope %q", registry.exchanges[0][0])
+	}
+
+	opts := &ScrapeOptions{HTTPClient: server.Client()}
+	for _, repository := range []string{"org/app-0", "org/app-29", "org/private"} {
+		source := &configuration.PackageSource{Type: configuration.PackageSourceTypeDockerImage, URI: host + "/" + repository}
+		err := fetchDockerTags(server.URL, &ImageInfo{Registry: host, Repository: repository}, provider, source, opts, func([]string) {})
+		if err != nil {
+			t.Fatalf("fetching the tags of %s failed: %v", repository, err)
+		}
+	}
+
+	// Only the repository whose scope was not granted exchanges its own token
+	if len(registry.exchanges) != 3 || strings.Join(registry.exchanges[2], " ") != "repository:org/private:pull" {
+		t.Errorf("expected one more exchange for org/private, got %v", registry.exchanges)
+	}
+}
+
+func TestPrefetchTokens_StaticAuth(t *testing.T) {
+	// Registries accepting the static credentials need no tokens
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			exchanges++
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Type: configuration.PackageSourceProviderTypeDocker, BaseUrl: server.URL}
+	sources := []*configuration.PackageSource{{Type: configuration.PackageSourceTypeDockerImage, URI: "registry.example.com/org/app"}}
+	if err := PrefetchTokens(server.Client(), provider, sources); err != nil {
+		t.Fatalf("PrefetchTokens() failed: %v", err)
+	}
+	if exchanges != 0 {
+		t.Errorf("expected no token exchange, got %d", exchanges)
+	}
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// tokenExpiryMargin is how long before its expiry a cached bearer token is exchanged anew
+const tokenExpiryMargin = 10 * time.Second
+
+// defaultTokenLifetime is the lifetime of bearer tokens without expires_in, as specified by
+// the registry token authentication
+const defaultTokenLifetime = 60 * time.Second
+
+// bearerToken is a registry bearer token and the time it expires
+type bearerToken struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// tokenCache keeps the bearer tokens of registries per scope and credentials until shortly
+// before they expire, and remembers which token the requests of each repository use, so the
+// requests of a scrape share one token instead of exchanging one per request
+type tokenCache struct {
+	mu     sync.Mutex
+	keys   map[string]string       // Token key by repository key
+	tokens map[string]*bearerToken // By token key
+}
+
+// registryTokens is the token cache of all registry requests of the process
+var registryTokens = newTokenCache()
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		keys:   make(map[string]string),
+		tokens: make(map[string]*bearerToken),
+	}
+}
+
+// get returns the token for requests of a repository if one is cached that is valid at the
+// given time
+func (c *tokenCache) get(repositoryKey string, now time.Time) (*bearerToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token, ok := c.tokens[c.keys[repositoryKey]]
+	if !ok || !now.Before(token.ExpiresAt.Add(-tokenExpiryMargin)) {
+		return nil, false
+	}
+	return token, true
+}
+
+// put caches the token exchanged for a request of a repository and drops expired tokens
+func (c *tokenCache) put(repositoryKey string, tokenKey string, token *bearerToken, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, cached := range c.tokens {
+		if !now.Before(cached.ExpiresAt) {
+			delete(c.tokens, key)
+		}
+	}
+	c.keys[repositoryKey] = tokenKey
+	c.tokens[tokenKey] = token
+}
+
+// invalidate stops the requests of a repository from using their token after the registry
+// rejected it. Other repositories keep a shared token, as a token exchanged for a batch of
+// repositories may lack the scope of just this one.
+func (c *tokenCache) invalidate(repositoryKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.keys, repositoryKey)
+}
+
+// repositoryKey identifies the requests of a repository on a registry with the credentials of
+// a provider
+func repositoryKey(requestURL string, provider *configuration.PackageSourceProvider, repository string) string {
+	host := requestURL
+	if parsed, err := url.Parse(requestURL); err == nil {
+		host = parsed.Host
+	}
+	return strings.Join([]string{host, repository, credentialsKey(provider)}, "\x00")
+}
+
+// tokenKey identifies the token exchanged for a challenge with the credentials of a provider,
+// so repositories challenged with the same scope share their token
+func tokenKey(challenge *wwwAuthenticateChallenge, provider *configuration.PackageSourceProvider) string {
+	return strings.Join([]string{challenge.Realm, challenge.Service, challenge.Scope, credentialsKey(provider)}, "\x00")
+}
+
+// credentialsKey identifies the credentials of a provider by a hash, so they are not kept in
+// cache keys
+func credentialsKey(provider *configuration.PackageSourceProvider) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{string(provider.AuthType), provider.Username, provider.Password, provider.Token}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
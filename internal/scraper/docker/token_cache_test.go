@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// tokenRegistry is a registry issuing numbered bearer tokens and accepting only the newest one
+type tokenRegistry struct {
+	mu        sync.Mutex
+	exchanges int
+	requests  int
+	expiresIn int
+}
+
+func (r *tokenRegistry) revoke() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges++
+}
+
+func (r *tokenRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		r.exchanges++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("token-%d", r.exchanges),
+			"expires_in": r.expiresIn,
+		})
+		return
+	}
+
+	r.requests++
+	if req.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", r.exchanges) {
+		w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test",scope="repository:myorg/myimage:pull"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestDoRegistryRequest_CachesToken(t *testing.T) {
+	registry := &tokenRegistry{expiresIn: 300}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone}
+	request := func() {
+		t.Helper()
+		resp, err := doAuthenticatedRequest(server.Client(), server.URL+"/v2/myorg/myimage/tags/list", provider, "myorg/myimage")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	request()
+	request()
+	request()
+	if registry.exchanges != 1 || registry.requests != 4 {
+		t.Errorf("expected 1 exchange and 4 registry requests (challenge, retry, 2 cached), got %d and %d", registry.exchanges, registry.requests)
+	}
+
+	// A token revoked mid-run is exchanged anew
+	registry.revoke()
+	request()
+	if registry.exchanges != 3 || registry.requests != 6 {
+		t.Errorf("expected a new exchange after the rejected token, got %d exchanges and %d registry requests", registry.exchanges, registry.requests)
+	}
+	request()
+	if registry.exchanges != 3 || registry.requests != 7 {
+		t.Errorf("expected the new token to be cached, got %d exchanges and %d registry requests", registry.exchanges, registry.requests)
+	}
+}
+
+func TestDoRegistryRequest_ExpiredToken(t *testing.T) {
+	// A token expiring within the margin is never reused
+	registry := &tokenRegistry{expiresIn: 5}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone}
+	for range 2 {
+		resp, err := doAuthenticatedRequest(server.Client(), server.URL+"/v2/myorg/myimage/tags/list", provider, "myorg/myimage")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if registry.exchanges != 2 {
+		t.Errorf("expected an exchange per request, got %d", registry.exchanges)
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	cache := newTokenCache()
+	now := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	challenge := &wwwAuthenticateChallenge{Realm: "https://auth.example.com/token", Service: "registry", Scope: "repository:org/app:pull"}
+	alice := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeBasic, Username: "alice", Password: "secret"}
+	bob := &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeBasic, Username: "bob", Password: "secret"}
+
+	app := repositoryKey("https://registry.example.com/v2/org/app/tags/list", alice, "org/app")
+	cache.put(app, tokenKey(challenge, alice), &bearerToken{Value: "token", ExpiresAt: now.Add(time.Minute)}, now)
+
+	if token, ok := cache.get(repositoryKey("https://registry.example.com/v2/org/app/manifests/1.0", alice, "org/app"), now); !ok || token.Value != "token" {
+		t.Errorf("expected requests of the repository to share the token, got %v, %v", token, ok)
+	}
+	if _, ok := cache.get(repositoryKey("https://registry.example.com/v2/org/app/tags/list", bob, "org/app"), now); ok {
+		t.Error("expected other credentials not to share the token")
+	}
+	if _, ok := cache.get(repositoryKey("https://registry.example.com/v2/org/other/tags/list", alice, "org/other"), now); ok {
+		t.Error("expected other repositories not to share the token before they are challenged")
+	}
+	if _, ok := cache.get(app, now.Add(50*time.Second)); ok {
+		t.Error("expected a token expiring within the margin to be exchanged anew")
+	}
+
+	// A token shared with a batch of repositories stays with the others
+	other := repositoryKey("https://registry.example.com/v2/org/other/tags/list", alice, "org/other")
+	cache.put(other, tokenKey(challenge, alice), &bearerToken{Value: "token", ExpiresAt: now.Add(time.Minute)}, now)
+	cache.invalidate(app)
+	if _, ok := cache.get(app, now); ok {
+		t.Error("expected an invalidated token to be dropped")
+	}
+	if _, ok := cache.get(other, now); !ok {
+		t.Error("expected other repositories to keep the shared token")
+	}
+}
@@ -116,3 +116,41 @@ func BuildRegistryURL(baseURL string, imageRegistry string) string {
 	// Default to Docker Hub registry
 	return "https://registry.hub.docker.com"
 }
+
+// BuildImageReference constructs a fully qualified image reference (registry/repository:tag)
+// suitable for tools such as cosign. The registry is taken from the provider base URL if set,
+// otherwise from the image URI, defaulting to docker.io for Docker Hub images.
+func BuildImageReference(baseURL string, uri string, tag string) (string, error) {
+	name, err := BuildImageName(baseURL, uri)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", name, tag), nil
+}
+
+// BuildImageName constructs the fully qualified image name (registry/repository) without
+// a tag, resolving the registry like BuildImageReference
+func BuildImageName(baseURL string, uri string) (string, error) {
+	imageInfo, err := ParseImageURL(uri)
+	if err != nil {
+		return "", err
+	}
+
+	registry := imageInfo.Registry
+	if baseURL != "" {
+		host := strings.TrimPrefix(baseURL, "https://")
+		host = strings.TrimPrefix(host, "http://")
+		host = strings.TrimSuffix(host, "/")
+		switch host {
+		case "registry.hub.docker.com", "hub.docker.com", "registry-1.docker.io", "index.docker.io", "docker.io":
+			registry = ""
+		default:
+			registry = host
+		}
+	}
+	if registry == "" {
+		registry = "docker.io"
+	}
+
+	return fmt.Sprintf("%s/%s", registry, imageInfo.Repository), nil
+}
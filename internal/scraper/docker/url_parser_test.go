@@ -240,6 +240,55 @@ func TestBuildRegistryURL(t *testing.T) {
 	}
 }
 
+func TestBuildImageReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		uri     string
+		tag     string
+		want    string
+	}{
+		{
+			name: "docker hub library image",
+			uri:  "nginx",
+			tag:  "1.27.0",
+			want: "docker.io/library/nginx:1.27.0",
+		},
+		{
+			name:    "docker hub base url",
+			baseURL: "https://registry.hub.docker.com",
+			uri:     "myorg/myapp",
+			tag:     "v1.2.3",
+			want:    "docker.io/myorg/myapp:v1.2.3",
+		},
+		{
+			name: "registry from uri",
+			uri:  "ghcr.io/myorg/myapp",
+			tag:  "2.0.0",
+			want: "ghcr.io/myorg/myapp:2.0.0",
+		},
+		{
+			name:    "registry from base url",
+			baseURL: "https://harbor.example.com/",
+			uri:     "project/app",
+			tag:     "1.0.0",
+			want:    "harbor.example.com/project/app:1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildImageReference(tt.baseURL, tt.uri, tt.tag)
+			if err != nil {
+				t.Fatalf("BuildImageReference() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildImageReference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && hasSubstring(s, substr)))
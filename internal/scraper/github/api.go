@@ -0,0 +1,56 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// errNotFound is returned by fetchGitHubAPI when the requested resource does not exist
+var errNotFound = errors.New("not found")
+
+// fetchGitHubAPI performs an authenticated GET request against the GitHub REST API within
+// the rate limit budget and returns the response body
+func fetchGitHubAPI(client *http.Client, apiURL string, provider *configuration.PackageSourceProvider, limiter *RateLimiter, accept string) ([]byte, error) {
+	// Create HTTP request
+	request, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add authentication if configured
+	if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", provider.Token))
+	} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
+		request.SetBasicAuth(provider.Username, provider.Password)
+	}
+
+	// Add GitHub API headers
+	request.Header.Set("Accept", accept)
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	// Execute request
+	response, err := doGitHubRequest(client, request, limiter, rateLimitResourceCore)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("HTTP %d: %w", response.StatusCode, errNotFound)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", response.StatusCode)
+	}
+
+	// Read response body
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return body, nil
+}
@@ -2,12 +2,27 @@ package github
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 )
 
 type ScrapeOptions struct {
-	Limit int
+	PaginationStop *configuration.PaginationStop // Stops paginating sorted feeds once the current version is passed
+	TagBatch       *TagBatch                     // Tags prefetched with GraphQL, optional
+	RateLimiter    *RateLimiter                  // Rate limit budget of the provider, optional
+	HTTPClient     *http.Client                  // Client of all GitHub requests, optional
+}
+
+// httpClient returns the client requests are sent with, a client on the updater transport if
+// none was passed
+func (o *ScrapeOptions) httpClient() *http.Client {
+	if o != nil && o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return util.NewHTTPClient(30 * time.Second)
 }
 
 type GitHubProviderClient struct {
@@ -0,0 +1,191 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// graphQLBatchSize is the number of repositories queried in a single GraphQL request
+const graphQLBatchSize = 25
+
+// graphQLTagsPerRepository is the number of tags fetched per repository. Repositories with
+// more tags are scraped with the paginated REST API instead.
+const graphQLTagsPerRepository = 100
+
+// TagBatch holds the tags of repositories prefetched with batched GraphQL queries, so that
+// git-tag sources of a provider cost one API call per batch instead of one per page
+type TagBatch struct {
+	mu   sync.Mutex
+	tags map[string][]GitHubTag
+}
+
+// NewTagBatch creates an empty tag batch
+func NewTagBatch() *TagBatch {
+	return &TagBatch{
+		tags: make(map[string][]GitHubTag),
+	}
+}
+
+// get returns the prefetched tags of a repository. A nil batch never hits.
+func (b *TagBatch) get(repoInfo *RepositoryInfo) ([]GitHubTag, bool) {
+	if b == nil {
+		return nil, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tags, ok := b.tags[tagBatchKey(repoInfo.Owner, repoInfo.Repo)]
+	return tags, ok
+}
+
+func (b *TagBatch) put(owner string, repo string, tags []GitHubTag) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tags[tagBatchKey(owner, repo)] = tags
+}
+
+func tagBatchKey(owner string, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+// Prefetch fetches the tags of the repositories of all git-tag sources with batched GraphQL
+// queries. Repositories with more tags than a single query returns, and repositories the
+// query could not resolve, are left to the REST API.
+func (b *TagBatch) Prefetch(client *http.Client, provider *configuration.PackageSourceProvider, sources []*configuration.PackageSource, limiter *RateLimiter) error {
+	seen := make(map[string]bool)
+	var repositories []*RepositoryInfo
+	for _, source := range sources {
+		if source.Type != configuration.PackageSourceTypeGitTag {
+			continue
+		}
+		repoInfo, err := ParseRepositoryURL(source.URI)
+		if err != nil {
+			continue // Reported when the source is scraped
+		}
+		key := tagBatchKey(repoInfo.Owner, repoInfo.Repo)
+		if !seen[key] {
+			seen[key] = true
+			repositories = append(repositories, repoInfo)
+		}
+	}
+
+	graphQLURL := BuildGraphQLURL(provider.BaseUrl)
+	for start := 0; start < len(repositories); start += graphQLBatchSize {
+		end := min(start+graphQLBatchSize, len(repositories))
+		if err := b.fetchBatch(client, graphQLURL, provider, repositories[start:end], limiter); err != nil {
+			return err
+		}
+	}
+
+	log.Debug().
+		Str("provider", provider.Name).
+		Int("repositories", len(repositories)).
+		Int("requests", (len(repositories)+graphQLBatchSize-1)/graphQLBatchSize).
+		Msg("prefetched GitHub tags with GraphQL")
+	return nil
+}
+
+// graphQLRefs is the refs connection of a repository in the GraphQL response
+type graphQLRefs struct {
+	Refs struct {
+		PageInfo struct {
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"pageInfo"`
+		Nodes []struct {
+			Name   string `json:"name"`
+			Target struct {
+				OID    string `json:"oid"`
+				Target *struct {
+					OID string `json:"oid"`
+				} `json:"target"` // Commit of an annotated tag
+			} `json:"target"`
+		} `json:"nodes"`
+	} `json:"refs"`
+}
+
+func (b *TagBatch) fetchBatch(client *http.Client, graphQLURL string, provider *configuration.PackageSourceProvider, repositories []*RepositoryInfo, limiter *RateLimiter) error {
+	var query strings.Builder
+	query.WriteString("query {")
+	for i, repoInfo := range repositories {
+		owner, _ := json.Marshal(repoInfo.Owner)
+		name, _ := json.Marshal(repoInfo.Repo)
+		fmt.Fprintf(&query, ` r%d: repository(owner: %s, name: %s) { refs(refPrefix: "refs/tags/", first: %d, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) { pageInfo { hasNextPage } nodes { name target { oid ... on Tag { target { oid } } } } } }`,
+			i, owner, name, graphQLTagsPerRepository)
+	}
+	query.WriteString(" }")
+
+	requestBody, err := json.Marshal(map[string]string{"query": query.String()})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL query: %w", err)
+	}
+
+	request, err := http.NewRequest("POST", graphQLURL, bytes.NewReader(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", provider.Token))
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := doGitHubRequest(client, request, limiter, rateLimitResourceGraphQL)
+	if err != nil {
+		return fmt.Errorf("failed to query GitHub GraphQL API: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to query GitHub GraphQL API: HTTP %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GraphQL response: %w", err)
+	}
+
+	// Unresolvable repositories are null in data and reported in errors
+	var result struct {
+		Data   map[string]*graphQLRefs `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	for _, graphQLErr := range result.Errors {
+		log.Debug().Str("error", graphQLErr.Message).Msg("GitHub GraphQL query error")
+	}
+
+	for i, repoInfo := range repositories {
+		repository := result.Data[fmt.Sprintf("r%d", i)]
+		if repository == nil || repository.Refs.PageInfo.HasNextPage {
+			continue
+		}
+		tags := make([]GitHubTag, 0, len(repository.Refs.Nodes))
+		for _, node := range repository.Refs.Nodes {
+			tag := GitHubTag{Name: node.Name}
+			tag.Commit.SHA = node.Target.OID
+			if node.Target.Target != nil {
+				tag.Commit.SHA = node.Target.Target.OID
+			}
+			tags = append(tags, tag)
+		}
+		b.put(repoInfo.Owner, repoInfo.Repo, tags)
+	}
+	return nil
+}
+
+// BuildGraphQLURL constructs the GraphQL API URL from the provider base URL
+func BuildGraphQLURL(baseURL string) string {
+	apiBaseURL := BuildAPIURL(baseURL)
+	if strings.HasSuffix(apiBaseURL, "/v3") {
+		return strings.TrimSuffix(apiBaseURL, "/v3") + "/graphql"
+	}
+	return apiBaseURL + "/graphql"
+}
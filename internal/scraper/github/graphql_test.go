@@ -0,0 +1,120 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestBuildGraphQLURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{
+			name:     "github.com",
+			baseURL:  "",
+			expected: "https://api.github.com/graphql",
+		},
+		{
+			name:     "github enterprise",
+			baseURL:  "https://github.example.com",
+			expected: "https://github.example.com/api/graphql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildGraphQLURL(tt.baseURL); got != tt.expected {
+				t.Errorf("BuildGraphQLURL() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTagBatch_Prefetch(t *testing.T) {
+	graphQLRequests := 0
+	restRequests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/graphql":
+			graphQLRequests++
+			var request struct {
+				Query string `json:"query"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			if !strings.Contains(request.Query, `r0: repository(owner: "owner", name: "small")`) {
+				t.Errorf("Unexpected query: %s", request.Query)
+			}
+			w.Write([]byte(`{"data": {
+				"r0": {"refs": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"name": "v1.1.0", "target": {"oid": "tag1", "target": {"oid": "commit1"}}},
+					{"name": "v1.0.0", "target": {"oid": "commit0"}}
+				]}},
+				"r1": {"refs": {"pageInfo": {"hasNextPage": true}, "nodes": [{"name": "v9.0.0", "target": {"oid": "commit9"}}]}},
+				"r2": null
+			}}`))
+		case "/api/v3/repos/owner/large/tags":
+			restRequests["large"]++
+			w.Write([]byte(`[{"name": "v2.0.0", "commit": {"sha": "a"}}]`))
+		default:
+			restRequests[r.URL.Path]++
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{
+		Name:     "github",
+		Type:     configuration.PackageSourceProviderTypeGitHub,
+		BaseUrl:  server.URL,
+		AuthType: configuration.PackageSourceProviderAuthTypeToken,
+		Token:    "token",
+		GraphQL:  true,
+	}
+	sources := []*configuration.PackageSource{
+		{Name: "small", Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/owner/small"},
+		{Name: "large", Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/owner/large"},
+		{Name: "missing", Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/owner/missing"},
+		{Name: "release", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/owner/release"},
+	}
+
+	batch := NewTagBatch()
+	if err := batch.Prefetch(server.Client(), provider, sources, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if graphQLRequests != 1 {
+		t.Errorf("Expected 1 GraphQL request, got %d", graphQLRequests)
+	}
+
+	// Prefetched repository: annotated tags resolve to their commit, no REST request
+	versions, err := scrapeTag(provider, sources[0], &ScrapeOptions{TagBatch: batch})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "v1.1.0" {
+		t.Errorf("Expected prefetched versions v1.1.0 and v1.0.0, got %v", versions)
+	}
+	if tags, _ := batch.get(&RepositoryInfo{Owner: "owner", Repo: "small"}); tags[0].Commit.SHA != "commit1" {
+		t.Errorf("Expected annotated tag to resolve to commit1, got %s", tags[0].Commit.SHA)
+	}
+
+	// Repository with more tags than one query returns falls back to REST
+	versions, err = scrapeTag(provider, sources[1], &ScrapeOptions{TagBatch: batch})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restRequests["large"] != 1 || len(versions) != 1 || versions[0].Version != "v2.0.0" {
+		t.Errorf("Expected REST fallback for large repository, got %d requests and %v", restRequests["large"], versions)
+	}
+
+	// Repository the query could not resolve is not prefetched
+	if _, ok := batch.get(&RepositoryInfo{Owner: "owner", Repo: "missing"}); ok {
+		t.Errorf("Expected unresolved repository not to be prefetched")
+	}
+}
@@ -1,11 +1,12 @@
 package github
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/rs/zerolog/log"
@@ -21,19 +22,40 @@ func scrapeHelmChart(provider *configuration.PackageSourceProvider, source *conf
 	// Check if URI is a raw.githubusercontent.com URL
 	if isRawGitHubURL(source.URI) {
 		log.Debug().Str("uri", source.URI).Msg("detected raw.githubusercontent.com URL, fetching directly")
-		body, err = fetchFromRawURL(source.URI, provider)
+		body, err = fetchFromRawURL(opts.httpClient(), source.URI, provider)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// Use GitHub API for regular repository URLs
-		body, err = fetchViaGitHubAPI(provider, source)
+		body, err = fetchViaGitHubAPI(provider, source, opts)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Parse the YAML content
+	version, err := parseChartVersion(body)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().
+		Str("version", version.Version).
+		Int("major", version.MajorVersion).
+		Int("minor", version.MinorVersion).
+		Int("patch", version.PatchVersion).
+		Msg("scraped Helm chart version")
+
+	if !source.ChartHistory {
+		return []*configuration.PackageSourceVersion{version}, nil
+	}
+
+	// Add the chart versions released at the repository tags
+	return scrapeHelmChartHistory(provider, source, version, opts)
+}
+
+// parseChartVersion reads the chart version and appVersion from Chart.yaml content
+func parseChartVersion(body []byte) (*configuration.PackageSourceVersion, error) {
 	var chartData struct {
 		Version     string `yaml:"version"`
 		AppVersion  string `yaml:"appVersion"`
@@ -55,20 +77,115 @@ func scrapeHelmChart(provider *configuration.PackageSourceProvider, source *conf
 	}
 
 	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(chartData.Version)
+	version.BuildVersion, version.Revision = configuration.ParseBuild(chartData.Version)
 
 	// Add version information if appVersion is available
 	if chartData.AppVersion != "" {
 		version.VersionInformation = fmt.Sprintf("appVersion: %s", chartData.AppVersion)
 	}
 
+	return version, nil
+}
+
+// maxChartHistoryTags bounds the number of tags Chart.yaml is read at without a tagLimit, as
+// every tag costs one API request
+const maxChartHistoryTags = 100
+
+// scrapeHelmChartHistory reads Chart.yaml at the newest repository tags selected by tagPattern
+// and excludePattern, so that earlier chart versions are available besides the branch tip.
+// Tags at which the chart does not exist are skipped; any other error fails the scrape so
+// that a truncated history is never reported.
+func scrapeHelmChartHistory(provider *configuration.PackageSourceProvider, source *configuration.PackageSource, tip *configuration.PackageSourceVersion, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
+	if isRawGitHubURL(source.URI) {
+		return nil, fmt.Errorf("chartHistory requires a repository URI, not a raw URL")
+	}
+
+	repoInfo, err := ParseRepositoryURL(source.URI)
+	if err != nil {
+		return nil, err
+	}
+	apiBaseURL := BuildAPIURL(provider.BaseUrl)
+
+	tags, err := fetchAllGitHubTags(apiBaseURL, repoInfo, provider, source, &ScrapeOptions{RateLimiter: opts.RateLimiter})
+	if err != nil {
+		return nil, err
+	}
+
+	// Select the tags to read by name
+	tagVersions := make([]*configuration.PackageSourceVersion, 0, len(tags))
+	for _, tag := range tags {
+		tagVersions = append(tagVersions, parseGitTag(tag.Name, tag.Commit.SHA))
+	}
+	tagVersions, err = filterGitVersions(tagVersions, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the newest tags first and stop at the limit
+	sortVersions(tagVersions, source)
+	limit := source.TagLimit
+	if limit <= 0 {
+		limit = maxChartHistoryTags
+	}
+	if len(tagVersions) > limit {
+		log.Debug().
+			Int("tags", len(tagVersions)).
+			Int("limit", limit).
+			Msg("reading Chart.yaml at the newest tags only")
+		tagVersions = tagVersions[:limit]
+	}
+
+	chartPath := resolveChartPath(source)
+	versions := []*configuration.PackageSourceVersion{tip}
+	seen := map[string]bool{tip.Version: true}
+	for _, tag := range tagVersions {
+		body, err := fetchChartViaGitHubAPI(opts.httpClient(), provider, apiBaseURL, repoInfo, chartPath, tag.Version, opts.RateLimiter)
+		if errors.Is(err, errNotFound) {
+			log.Debug().Str("tag", tag.Version).Msg("no Chart.yaml at tag, skipping")
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart history at tag %s: %w", tag.Version, err)
+		}
+		version, err := parseChartVersion(body)
+		if err != nil {
+			log.Debug().Err(err).Str("tag", tag.Version).Msg("invalid Chart.yaml at tag, skipping")
+			continue
+		}
+		if seen[version.Version] {
+			continue
+		}
+		seen[version.Version] = true
+		versions = append(versions, version)
+	}
+
+	sortVersions(versions, source)
+
 	log.Debug().
-		Str("version", version.Version).
-		Int("major", version.MajorVersion).
-		Int("minor", version.MinorVersion).
-		Int("patch", version.PatchVersion).
-		Msg("scraped Helm chart version")
+		Int("count", len(versions)).
+		Int("tags_read", len(tagVersions)).
+		Str("path", chartPath).
+		Msg("scraped Helm chart history")
+
+	return versions, nil
+}
 
-	return []*configuration.PackageSourceVersion{version}, nil
+// resolveChartPath returns the path of Chart.yaml in the repository. The path may name the
+// chart directory (e.g. charts/my-chart) or the Chart.yaml file itself.
+func resolveChartPath(source *configuration.PackageSource) string {
+	chartPath := strings.Trim(source.Path, "/")
+	if chartPath == "" {
+		// Try to extract path from old-style raw content URLs
+		chartPath = extractPathFromRawURL(source.URI)
+	}
+	if chartPath == "" {
+		// Default path for Helm charts
+		return "Chart.yaml"
+	}
+	if strings.HasSuffix(chartPath, ".yaml") || strings.HasSuffix(chartPath, ".yml") {
+		return chartPath
+	}
+	return chartPath + "/Chart.yaml"
 }
 
 // isRawGitHubURL checks if the URI is a raw.githubusercontent.com URL
@@ -77,7 +194,7 @@ func isRawGitHubURL(uri string) bool {
 }
 
 // fetchFromRawURL fetches Chart.yaml content directly from raw.githubusercontent.com URL
-func fetchFromRawURL(uri string, provider *configuration.PackageSourceProvider) ([]byte, error) {
+func fetchFromRawURL(client *http.Client, uri string, provider *configuration.PackageSourceProvider) ([]byte, error) {
 	log.Debug().Str("uri", uri).Msg("fetching from raw URL (bypassing GitHub API)")
 
 	// Create HTTP request
@@ -94,7 +211,6 @@ func fetchFromRawURL(uri string, provider *configuration.PackageSourceProvider)
 	}
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
 	response, err := client.Do(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Chart.yaml from raw URL: %w", err)
@@ -114,8 +230,8 @@ func fetchFromRawURL(uri string, provider *configuration.PackageSourceProvider)
 	return body, nil
 }
 
-// fetchViaGitHubAPI fetches Chart.yaml content via GitHub API
-func fetchViaGitHubAPI(provider *configuration.PackageSourceProvider, source *configuration.PackageSource) ([]byte, error) {
+// fetchViaGitHubAPI fetches Chart.yaml content at the tip of the source branch via GitHub API
+func fetchViaGitHubAPI(provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]byte, error) {
 	// Parse repository information from URI
 	repoInfo, err := ParseRepositoryURL(source.URI)
 	if err != nil {
@@ -128,67 +244,29 @@ func fetchViaGitHubAPI(provider *configuration.PackageSourceProvider, source *co
 		branch = "main"
 	}
 
-	// Determine path (try to extract from old-style raw URLs or use explicit path field)
-	chartPath := source.Path
-	if chartPath == "" {
-		// Try to extract path from old-style raw content URLs
-		chartPath = extractPathFromRawURL(source.URI)
-		if chartPath == "" {
-			// Default path for Helm charts
-			chartPath = "Chart.yaml"
-		}
-	}
-
-	// Build API base URL
-	apiBaseURL := BuildAPIURL(provider.BaseUrl)
+	return fetchChartViaGitHubAPI(opts.httpClient(), provider, BuildAPIURL(provider.BaseUrl), repoInfo, resolveChartPath(source), branch, opts.RateLimiter)
+}
 
+// fetchChartViaGitHubAPI fetches the content of the Chart.yaml at chartPath and ref (a
+// branch, tag or commit) via GitHub API
+func fetchChartViaGitHubAPI(client *http.Client, provider *configuration.PackageSourceProvider, apiBaseURL string, repoInfo *RepositoryInfo, chartPath string, ref string, limiter *RateLimiter) ([]byte, error) {
 	// Construct GitHub API URL for file contents
-	// Format: /repos/{owner}/{repo}/contents/{path}?ref={branch}
+	// Format: /repos/{owner}/{repo}/contents/{path}?ref={ref}
 	apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s",
-		apiBaseURL, repoInfo.Owner, repoInfo.Repo, chartPath, branch)
+		apiBaseURL, repoInfo.Owner, repoInfo.Repo, chartPath, url.QueryEscape(ref))
 
 	log.Debug().
 		Str("api_url", apiURL).
 		Str("owner", repoInfo.Owner).
 		Str("repo", repoInfo.Repo).
 		Str("path", chartPath).
-		Str("branch", branch).
+		Str("ref", ref).
 		Msg("fetching Helm chart via GitHub API")
 
-	// Create HTTP request
-	request, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add authentication if configured
-	if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", provider.Token))
-	} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
-		request.SetBasicAuth(provider.Username, provider.Password)
-	}
-
-	// Add GitHub API headers
-	request.Header.Set("Accept", "application/vnd.github.raw") // Get raw file content
-	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	response, err := client.Do(request)
+	body, err := fetchGitHubAPI(client, apiURL, provider, limiter, "application/vnd.github.raw") // Get raw file content
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Chart.yaml: %w", err)
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch Chart.yaml: HTTP %d", response.StatusCode)
-	}
-
-	// Read the response body
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read Chart.yaml: %w", err)
-	}
 
 	return body, nil
 }
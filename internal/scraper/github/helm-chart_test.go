@@ -0,0 +1,216 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestResolveChartPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   *configuration.PackageSource
+		expected string
+	}{
+		{
+			name:     "default",
+			source:   &configuration.PackageSource{URI: "https://github.com/owner/repo"},
+			expected: "Chart.yaml",
+		},
+		{
+			name:     "chart directory",
+			source:   &configuration.PackageSource{URI: "https://github.com/owner/repo", Path: "charts/my-chart/"},
+			expected: "charts/my-chart/Chart.yaml",
+		},
+		{
+			name:     "Chart.yaml file",
+			source:   &configuration.PackageSource{URI: "https://github.com/owner/repo", Path: "charts/my-chart/Chart.yaml"},
+			expected: "charts/my-chart/Chart.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveChartPath(tt.source); got != tt.expected {
+				t.Errorf("resolveChartPath() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScrapeHelmChart_History(t *testing.T) {
+	charts := map[string]string{
+		"main":            "name: my-chart\nversion: 1.3.0\nappVersion: 2.3.0\n",
+		"my-chart-1.10.0": "name: my-chart\nversion: 1.10.0\n",
+		"my-chart-1.2.0":  "name: my-chart\nversion: 1.2.0\nappVersion: 2.2.0\n",
+		"my-chart-1.1.0":  "name: my-chart\nversion: 1.1.0\n",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/monorepo/tags":
+			w.Write([]byte(`[
+				{"name": "other-chart-3.0.0", "commit": {"sha": "a1"}},
+				{"name": "my-chart-1.10.0", "commit": {"sha": "e5"}},
+				{"name": "my-chart-1.2.0", "commit": {"sha": "b2"}},
+				{"name": "my-chart-1.1.0", "commit": {"sha": "c3"}},
+				{"name": "my-chart-1.0.0", "commit": {"sha": "d4"}}
+			]`))
+		case "/api/v3/repos/owner/monorepo/contents/charts/my-chart/Chart.yaml":
+			chart, ok := charts[r.URL.Query().Get("ref")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(chart))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+
+	tests := []struct {
+		name             string
+		source           *configuration.PackageSource
+		expectedVersions []string
+	}{
+		{
+			name: "branch tip only",
+			source: &configuration.PackageSource{
+				URI:  "https://github.com/owner/monorepo",
+				Path: "charts/my-chart",
+			},
+			expectedVersions: []string{"1.3.0"},
+		},
+		{
+			name: "history from matching tags",
+			source: &configuration.PackageSource{
+				URI:          "https://github.com/owner/monorepo",
+				Path:         "charts/my-chart",
+				ChartHistory: true,
+				TagPattern:   "^my-chart-",
+			},
+			// my-chart-1.0.0 has no Chart.yaml at the path and is skipped
+			expectedVersions: []string{"1.10.0", "1.3.0", "1.2.0", "1.1.0"},
+		},
+		{
+			name: "history ordered by sortBy",
+			source: &configuration.PackageSource{
+				URI:          "https://github.com/owner/monorepo",
+				Path:         "charts/my-chart",
+				ChartHistory: true,
+				TagPattern:   "^my-chart-",
+				SortBy:       "alphabetical",
+			},
+			expectedVersions: []string{"1.3.0", "1.2.0", "1.10.0", "1.1.0"},
+		},
+		{
+			name: "history of the newest tags within tagLimit",
+			source: &configuration.PackageSource{
+				URI:            "https://github.com/owner/monorepo",
+				Path:           "charts/my-chart",
+				ChartHistory:   true,
+				TagPattern:     "^my-chart-",
+				TagLimit:       5,
+				ExcludePattern: "1\\.1\\.0$",
+			},
+			expectedVersions: []string{"1.10.0", "1.3.0", "1.2.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := scrapeHelmChart(provider, tt.source, &ScrapeOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(versions) != len(tt.expectedVersions) {
+				t.Fatalf("Expected %d versions, got %d", len(tt.expectedVersions), len(versions))
+			}
+			for i, expected := range tt.expectedVersions {
+				if versions[i].Version != expected {
+					t.Errorf("Expected version %d to be %s, got %s", i, expected, versions[i].Version)
+				}
+			}
+		})
+	}
+}
+
+func TestScrapeHelmChart_HistoryErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/repo/tags":
+			w.Write([]byte(`[{"name": "v1.1.0", "commit": {"sha": "b2"}}, {"name": "v1.0.0", "commit": {"sha": "c3"}}]`))
+		case "/api/v3/repos/owner/repo/contents/Chart.yaml":
+			switch r.URL.Query().Get("ref") {
+			case "main":
+				w.Write([]byte("name: my-chart\nversion: 1.2.0\n"))
+			case "v1.1.0":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				http.NotFound(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+	source := &configuration.PackageSource{URI: "https://github.com/owner/repo", ChartHistory: true}
+
+	_, err := scrapeHelmChart(provider, source, &ScrapeOptions{})
+	if err == nil || !strings.Contains(err.Error(), "tag v1.1.0") || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Errorf("expected the server error at tag v1.1.0 to fail the scrape, got %v", err)
+	}
+}
+
+func TestScrapeHelmChart_HistoryTagCap(t *testing.T) {
+	var chartRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/repo/tags":
+			// Two pages of tags, v1.0.0 to v1.149.0 in ascending order
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			var tags []string
+			for minor := (page - 1) * 100; minor < page*100 && minor < 150; minor++ {
+				tags = append(tags, fmt.Sprintf(`{"name": "v1.%d.0", "commit": {"sha": "s%d"}}`, minor, minor))
+			}
+			w.Write([]byte("[" + strings.Join(tags, ",") + "]"))
+		case "/api/v3/repos/owner/repo/contents/Chart.yaml":
+			chartRequests.Add(1)
+			ref := strings.TrimPrefix(r.URL.Query().Get("ref"), "v")
+			if ref == "main" {
+				ref = "1.150.0"
+			}
+			fmt.Fprintf(w, "name: my-chart\nversion: %s\n", ref)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+	source := &configuration.PackageSource{URI: "https://github.com/owner/repo", ChartHistory: true}
+
+	versions, err := scrapeHelmChart(provider, source, &ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// One request at the branch tip and one per tag up to the cap
+	if got := chartRequests.Load(); got != maxChartHistoryTags+1 {
+		t.Errorf("expected %d Chart.yaml requests, got %d", maxChartHistoryTags+1, got)
+	}
+	if len(versions) != maxChartHistoryTags+1 || versions[1].Version != "1.149.0" || versions[len(versions)-1].Version != "1.50.0" {
+		t.Errorf("expected the tip and the newest %d tags, got %d versions from %s to %s",
+			maxChartHistoryTags, len(versions), versions[0].Version, versions[len(versions)-1].Version)
+	}
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrRateLimited reports that the GitHub API rate limit is exhausted for longer than the
+// run is willing to wait
+var ErrRateLimited = errors.New("GitHub API rate limit exhausted")
+
+// GitHub API rate limit resources
+const (
+	rateLimitResourceCore    = "core"
+	rateLimitResourceGraphQL = "graphql"
+)
+
+const (
+	// maxRateLimitWait is the longest the scheduler waits for an exhausted budget to reset
+	// or for a secondary rate limit to clear
+	maxRateLimitWait = time.Minute
+	// maxPacingDelay is the longest delay between requests while pacing a low budget
+	maxPacingDelay = 2 * time.Second
+	// lowBudgetFraction is the remaining fraction of the budget below which requests are paced
+	lowBudgetFraction = 10
+)
+
+// RateLimiter tracks the remaining GitHub API budget of a provider per rate limit resource
+// (core REST, GraphQL) from the response headers. When the budget runs low, requests are
+// spread over the time left until it resets; when it is exhausted, requests fail with
+// ErrRateLimited unless the reset is imminent.
+type RateLimiter struct {
+	mu      sync.Mutex
+	budgets map[string]*rateBudget
+	sleep   func(time.Duration) // Replaced in tests
+	now     func() time.Time
+}
+
+type rateBudget struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// NewRateLimiter creates a rate limiter without any known budget
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		budgets: make(map[string]*rateBudget),
+		sleep:   time.Sleep,
+		now:     time.Now,
+	}
+}
+
+// wait blocks until a request against the resource may be sent. A nil limiter never waits.
+func (l *RateLimiter) wait(resource string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	budget, ok := l.budgets[resource]
+	if !ok {
+		l.mu.Unlock()
+		return nil
+	}
+	untilReset := budget.reset.Sub(l.now())
+	remaining, limit := budget.remaining, budget.limit
+	if remaining > 0 {
+		budget.remaining-- // Reserve a request until the response updates the budget
+	}
+	l.mu.Unlock()
+
+	if untilReset <= 0 {
+		return nil
+	}
+
+	if remaining <= 0 {
+		if untilReset > maxRateLimitWait {
+			return fmt.Errorf("%w for %s requests until %s", ErrRateLimited, resource, budget.reset.Format(time.RFC3339))
+		}
+		log.Warn().Str("resource", resource).Dur("wait", untilReset).Msg("GitHub API rate limit exhausted, waiting for reset")
+		l.pause(untilReset)
+		return nil
+	}
+
+	// Spread the remaining requests over the time until the budget resets
+	if limit > 0 && remaining < limit/lowBudgetFraction {
+		delay := min(untilReset/time.Duration(remaining), maxPacingDelay)
+		log.Debug().Str("resource", resource).Int("remaining", remaining).Dur("delay", delay).Msg("pacing GitHub API requests")
+		l.pause(delay)
+	}
+	return nil
+}
+
+// update records the budget reported in the rate limit headers of a response
+func (l *RateLimiter) update(header http.Header) {
+	if l == nil {
+		return
+	}
+
+	limit, limitErr := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	reset, resetErr := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if limitErr != nil || remainingErr != nil || resetErr != nil {
+		return
+	}
+	resource := header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = rateLimitResourceCore
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.budgets[resource] = &rateBudget{
+		limit:     limit,
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+}
+
+// pause sleeps for the duration. A nil limiter sleeps with time.Sleep.
+func (l *RateLimiter) pause(duration time.Duration) {
+	if l == nil {
+		time.Sleep(duration)
+		return
+	}
+	l.sleep(duration)
+}
+
+// doGitHubRequest sends a request within the rate limit budget of the resource. Secondary
+// rate limits (403 or 429 with Retry-After) are waited out and retried once; an exhausted
+// budget fails with ErrRateLimited.
+func doGitHubRequest(client *http.Client, request *http.Request, limiter *RateLimiter, resource string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(resource); err != nil {
+			return nil, err
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		limiter.update(response.Header)
+
+		if response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests {
+			return response, nil
+		}
+
+		retryAfter, retryErr := strconv.Atoi(response.Header.Get("Retry-After"))
+		delay := time.Duration(retryAfter) * time.Second
+		replayable := request.Body == nil || request.GetBody != nil
+		if retryErr == nil && attempt == 0 && delay <= maxRateLimitWait && replayable {
+			response.Body.Close()
+			log.Warn().Dur("wait", delay).Str("path", request.URL.Path).Msg("GitHub API secondary rate limit hit, retrying")
+			limiter.pause(delay)
+			if request.GetBody != nil {
+				if request.Body, err = request.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if retryErr == nil || response.Header.Get("X-RateLimit-Remaining") == "0" {
+			response.Body.Close()
+			return nil, fmt.Errorf("%w (HTTP %d)", ErrRateLimited, response.StatusCode)
+		}
+		return response, nil
+	}
+}
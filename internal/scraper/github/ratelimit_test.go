@@ -0,0 +1,204 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func newTestRateLimiter(now time.Time, slept *[]time.Duration) *RateLimiter {
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) { *slept = append(*slept, d) }
+	return limiter
+}
+
+func rateLimitHeader(limit, remaining int, reset time.Time) http.Header {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return header
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		expectErr   bool
+		expectSleep []time.Duration
+	}{
+		{
+			name:   "unknown budget",
+			header: http.Header{},
+		},
+		{
+			name:   "plenty of budget",
+			header: rateLimitHeader(5000, 4000, now.Add(time.Hour)),
+		},
+		{
+			name:        "low budget is paced",
+			header:      rateLimitHeader(5000, 100, now.Add(100*time.Second)),
+			expectSleep: []time.Duration{time.Second},
+		},
+		{
+			name:        "pacing delay is capped",
+			header:      rateLimitHeader(5000, 10, now.Add(time.Hour)),
+			expectSleep: []time.Duration{maxPacingDelay},
+		},
+		{
+			name:        "exhausted budget with imminent reset waits",
+			header:      rateLimitHeader(5000, 0, now.Add(30*time.Second)),
+			expectSleep: []time.Duration{30 * time.Second},
+		},
+		{
+			name:      "exhausted budget with distant reset fails",
+			header:    rateLimitHeader(5000, 0, now.Add(time.Hour)),
+			expectErr: true,
+		},
+		{
+			name:   "exhausted budget after reset",
+			header: rateLimitHeader(5000, 0, now.Add(-time.Second)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var slept []time.Duration
+			limiter := newTestRateLimiter(now, &slept)
+			limiter.update(tt.header)
+
+			err := limiter.wait(rateLimitResourceCore)
+			if tt.expectErr {
+				if !errors.Is(err, ErrRateLimited) {
+					t.Fatalf("Expected ErrRateLimited, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(slept) != len(tt.expectSleep) {
+				t.Fatalf("Expected sleeps %v, got %v", tt.expectSleep, slept)
+			}
+			for i := range slept {
+				if slept[i] != tt.expectSleep[i] {
+					t.Errorf("Expected sleep %v, got %v", tt.expectSleep[i], slept[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Resources(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var slept []time.Duration
+	limiter := newTestRateLimiter(now, &slept)
+
+	header := rateLimitHeader(5000, 0, now.Add(time.Hour))
+	header.Set("X-RateLimit-Resource", rateLimitResourceGraphQL)
+	limiter.update(header)
+
+	if err := limiter.wait(rateLimitResourceCore); err != nil {
+		t.Errorf("Exhausted GraphQL budget should not limit core requests: %v", err)
+	}
+	if err := limiter.wait(rateLimitResourceGraphQL); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited for GraphQL requests, got %v", err)
+	}
+}
+
+func TestFetchGitHubAPI_RateLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []func(w http.ResponseWriter)
+		expectErr    bool
+		expectLimit  bool
+		expectCalls  int
+		expectSleeps int
+	}{
+		{
+			name: "secondary rate limit is retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "5")
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { w.Write([]byte("ok")) },
+			},
+			expectCalls:  2,
+			expectSleeps: 1,
+		},
+		{
+			name: "repeated secondary rate limit fails",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "5")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "5")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			expectErr:    true,
+			expectLimit:  true,
+			expectCalls:  2,
+			expectSleeps: 1,
+		},
+		{
+			name: "exhausted primary rate limit fails",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectErr:   true,
+			expectLimit: true,
+			expectCalls: 1,
+		},
+		{
+			name: "forbidden without rate limit",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) },
+			},
+			expectErr:   true,
+			expectCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.responses[calls](w)
+				calls++
+			}))
+			defer server.Close()
+
+			var slept []time.Duration
+			limiter := newTestRateLimiter(time.Now(), &slept)
+			_, err := fetchGitHubAPI(server.Client(), server.URL, &configuration.PackageSourceProvider{}, limiter, "application/vnd.github+json")
+
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectLimit != errors.Is(err, ErrRateLimited) {
+				t.Errorf("Expected ErrRateLimited %v, got %v", tt.expectLimit, err)
+			}
+			if calls != tt.expectCalls {
+				t.Errorf("Expected %d requests, got %d", tt.expectCalls, calls)
+			}
+			if len(slept) != tt.expectSleeps {
+				t.Errorf("Expected %d sleeps, got %v", tt.expectSleeps, slept)
+			}
+		})
+	}
+}
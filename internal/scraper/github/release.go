@@ -3,8 +3,8 @@ package github
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,6 +12,23 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// GitHubRelease is a release returned by the GitHub releases API
+type GitHubRelease struct {
+	TagName     string               `json:"tag_name"`
+	Name        string               `json:"name"`
+	Body        string               `json:"body"`
+	Draft       bool                 `json:"draft"`
+	PreRelease  bool                 `json:"prerelease"`
+	CreatedAt   string               `json:"created_at"`
+	PublishedAt string               `json:"published_at"`
+	Assets      []GitHubReleaseAsset `json:"assets"`
+}
+
+// GitHubReleaseAsset is a file attached to a GitHub release
+type GitHubReleaseAsset struct {
+	Name string `json:"name"`
+}
+
 func scrapeRelease(provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
 	log.Debug().Str("uri", source.URI).Msg("scraping GitHub release")
 
@@ -24,93 +41,184 @@ func scrapeRelease(provider *configuration.PackageSourceProvider, source *config
 	// Build API base URL
 	apiBaseURL := BuildAPIURL(provider.BaseUrl)
 
+	// Release filters need the full release list, the latest release is enough otherwise
+	if source.ExcludeDrafts || source.ExcludePrereleases || source.RequireAsset != "" {
+		return scrapeReleaseList(opts.httpClient(), apiBaseURL, repoInfo, provider, source, opts.RateLimiter)
+	}
+
 	// Construct GitHub API URL for latest release
 	apiURL := fmt.Sprintf("%s/repos/%s/%s/releases/latest", apiBaseURL, repoInfo.Owner, repoInfo.Repo)
 
-	// Create HTTP request
-	request, err := http.NewRequest("GET", apiURL, nil)
+	body, err := fetchGitHubAPI(opts.httpClient(), apiURL, provider, opts.RateLimiter, "application/vnd.github+json")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to fetch release: %w", err)
 	}
 
-	// Add authentication if configured
-	if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", provider.Token))
-	} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
-		request.SetBasicAuth(provider.Username, provider.Password)
+	// Parse JSON response
+	var releaseData GitHubRelease
+	if err := json.Unmarshal(body, &releaseData); err != nil {
+		return nil, fmt.Errorf("failed to parse release response: %w", err)
+	}
+
+	if releaseData.TagName == "" {
+		return nil, fmt.Errorf("no tag found in release")
 	}
 
-	// Add GitHub API headers
-	request.Header.Set("Accept", "application/vnd.github+json")
-	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	version := convertReleaseToVersion(&releaseData)
 
-	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	response, err := client.Do(request)
+	log.Debug().
+		Str("version", version.Version).
+		Int("major", version.MajorVersion).
+		Int("minor", version.MinorVersion).
+		Int("patch", version.PatchVersion).
+		Bool("prerelease", releaseData.PreRelease).
+		Msg("scraped GitHub release version")
+
+	return []*configuration.PackageSourceVersion{version}, nil
+}
+
+// scrapeReleaseList lists the releases of a repository and keeps those passing the draft,
+// prerelease and asset filters of the source
+func scrapeReleaseList(client *http.Client, apiBaseURL string, repoInfo *RepositoryInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, limiter *RateLimiter) ([]*configuration.PackageSourceVersion, error) {
+	var assetPatternRe *regexp.Regexp
+	if source.RequireAsset != "" {
+		var err error
+		assetPatternRe, err = configuration.CompilePattern(source.RequireAsset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requireAsset pattern %q: %w", source.RequireAsset, err)
+		}
+	}
+
+	releases, err := fetchAllGitHubReleases(client, apiBaseURL, repoInfo, provider, source.TagLimit, limiter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release: %w", err)
+		return nil, err
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch release: HTTP %d", response.StatusCode)
+	versions := make([]*configuration.PackageSourceVersion, 0, len(releases))
+	for i := range releases {
+		release := &releases[i]
+		switch {
+		case release.TagName == "":
+			continue
+		case source.ExcludeDrafts && release.Draft:
+			log.Trace().Str("tag", release.TagName).Msg("skipping draft release")
+			continue
+		case source.ExcludePrereleases && release.PreRelease:
+			log.Trace().Str("tag", release.TagName).Msg("skipping prerelease")
+			continue
+		case assetPatternRe != nil && !hasMatchingAsset(release, assetPatternRe):
+			log.Debug().Str("tag", release.TagName).Str("requireAsset", source.RequireAsset).Msg("skipping release without required asset")
+			continue
+		}
+		versions = append(versions, convertReleaseToVersion(release))
 	}
 
-	// Read response body
-	body, err := io.ReadAll(response.Body)
+	sortVersions(versions, source)
+	filteredVersions, err := filterGitVersions(versions, source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read release response: %w", err)
+		return nil, err
 	}
 
-	// Parse JSON response
-	var releaseData struct {
-		TagName     string `json:"tag_name"`
-		Name        string `json:"name"`
-		Body        string `json:"body"`
-		Draft       bool   `json:"draft"`
-		PreRelease  bool   `json:"prerelease"`
-		CreatedAt   string `json:"created_at"`
-		PublishedAt string `json:"published_at"`
+	log.Debug().
+		Int("count", len(filteredVersions)).
+		Int("total_fetched", len(releases)).
+		Str("repo", fmt.Sprintf("%s/%s", repoInfo.Owner, repoInfo.Repo)).
+		Msg("scraped GitHub releases")
+
+	return filteredVersions, nil
+}
+
+// fetchAllGitHubReleases pages through the releases of a repository, newest first, up to
+// limit releases (0 = unlimited)
+func fetchAllGitHubReleases(client *http.Client, apiBaseURL string, repoInfo *RepositoryInfo, provider *configuration.PackageSourceProvider, limit int, limiter *RateLimiter) ([]GitHubRelease, error) {
+	allReleases := make([]GitHubRelease, 0)
+	perPage := 100
+
+	for page := 1; limit <= 0 || len(allReleases) < limit; page++ {
+		apiURL := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%d&page=%d", apiBaseURL, repoInfo.Owner, repoInfo.Repo, perPage, page)
+
+		body, err := fetchGitHubAPI(client, apiURL, provider, limiter, "application/vnd.github+json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch releases: %w", err)
+		}
+
+		var pageReleases []GitHubRelease
+		if err := json.Unmarshal(body, &pageReleases); err != nil {
+			return nil, fmt.Errorf("failed to parse releases response: %w", err)
+		}
+
+		allReleases = append(allReleases, pageReleases...)
+		if len(pageReleases) < perPage {
+			break
+		}
 	}
 
-	if err := json.Unmarshal(body, &releaseData); err != nil {
-		return nil, fmt.Errorf("failed to parse release response: %w", err)
+	if limit > 0 && len(allReleases) > limit {
+		allReleases = allReleases[:limit]
 	}
+	return allReleases, nil
+}
 
-	if releaseData.TagName == "" {
-		return nil, fmt.Errorf("no tag found in release")
+// cvePattern matches CVE IDs such as CVE-2024-12345 in release notes
+var cvePattern = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+
+// hasMatchingAsset reports whether any asset name of the release matches the pattern
+func hasMatchingAsset(release *GitHubRelease, pattern *regexp.Regexp) bool {
+	for _, asset := range release.Assets {
+		if pattern.MatchString(asset.Name) {
+			return true
+		}
 	}
+	return false
+}
 
+// releaseCVEs returns the CVE IDs mentioned in release notes in order of appearance, nil if
+// there are none
+func releaseCVEs(body string) []string {
+	var cves []string
+	seen := make(map[string]bool)
+	for _, cve := range cvePattern.FindAllString(body, -1) {
+		if !seen[cve] {
+			seen[cve] = true
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+// convertReleaseToVersion converts a GitHub release to a PackageSourceVersion
+func convertReleaseToVersion(release *GitHubRelease) *configuration.PackageSourceVersion {
 	// Parse version from tag
 	version := &configuration.PackageSourceVersion{
-		Version: releaseData.TagName,
+		Version: release.TagName,
 	}
 
 	// Parse semantic version components
-	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(releaseData.TagName)
+	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(release.TagName)
+	version.BuildVersion, version.Revision = configuration.ParseBuild(release.TagName)
 
 	// Add version information if available
 	var infoItems []string
-	if releaseData.Name != "" {
-		infoItems = append(infoItems, fmt.Sprintf("name: %s", releaseData.Name))
+	if release.Name != "" {
+		infoItems = append(infoItems, fmt.Sprintf("name: %s", release.Name))
+	}
+	if release.Draft {
+		infoItems = append(infoItems, "draft: true")
 	}
-	if releaseData.PreRelease {
+	if release.PreRelease {
 		infoItems = append(infoItems, "prerelease: true")
 	}
-	if releaseData.PublishedAt != "" {
-		infoItems = append(infoItems, fmt.Sprintf("published: %s", releaseData.PublishedAt))
+	if release.PublishedAt != "" {
+		infoItems = append(infoItems, fmt.Sprintf("published: %s", release.PublishedAt))
+		if publishedAt, err := time.Parse(time.RFC3339, release.PublishedAt); err == nil {
+			version.ReleasedAt = publishedAt
+		}
 	}
+	version.Breaking = strings.Contains(release.Body, "BREAKING")
+	version.CVEs = releaseCVEs(release.Body)
 	if len(infoItems) > 0 {
 		version.VersionInformation = strings.Join(infoItems, ", ")
 	}
 
-	log.Debug().
-		Str("version", version.Version).
-		Int("major", version.MajorVersion).
-		Int("minor", version.MinorVersion).
-		Int("patch", version.PatchVersion).
-		Bool("prerelease", releaseData.PreRelease).
-		Msg("scraped GitHub release version")
-
-	return []*configuration.PackageSourceVersion{version}, nil
+	return version
 }
@@ -0,0 +1,98 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestScrapeRelease_Filters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/owner/tool/releases/latest":
+			w.Write([]byte(`{"tag_name": "v1.2.0"}`))
+		case "/api/v3/repos/owner/tool/releases":
+			w.Write([]byte(`[
+				{"tag_name": "v1.5.0", "draft": true, "assets": [{"name": "tool-linux-amd64"}]},
+				{"tag_name": "v1.4.0-rc.1", "prerelease": true, "assets": [{"name": "tool-linux-amd64"}]},
+				{"tag_name": "v1.3.0", "assets": [{"name": "tool-darwin-arm64"}]},
+				{"tag_name": "v1.2.0", "assets": [{"name": "tool-linux-amd64"}, {"name": "tool-darwin-arm64"}]}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+
+	tests := []struct {
+		name             string
+		source           *configuration.PackageSource
+		expectedVersions []string
+	}{
+		{
+			name:             "latest release without filters",
+			source:           &configuration.PackageSource{URI: "https://github.com/owner/tool"},
+			expectedVersions: []string{"v1.2.0"},
+		},
+		{
+			name:             "exclude drafts",
+			source:           &configuration.PackageSource{URI: "https://github.com/owner/tool", ExcludeDrafts: true},
+			expectedVersions: []string{"v1.4.0-rc.1", "v1.3.0", "v1.2.0"},
+		},
+		{
+			name:             "exclude drafts and prereleases",
+			source:           &configuration.PackageSource{URI: "https://github.com/owner/tool", ExcludeDrafts: true, ExcludePrereleases: true},
+			expectedVersions: []string{"v1.3.0", "v1.2.0"},
+		},
+		{
+			name:             "require asset",
+			source:           &configuration.PackageSource{URI: "https://github.com/owner/tool", ExcludeDrafts: true, ExcludePrereleases: true, RequireAsset: "linux-amd64$"},
+			expectedVersions: []string{"v1.2.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := scrapeRelease(provider, tt.source, &ScrapeOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(versions) != len(tt.expectedVersions) {
+				t.Fatalf("Expected %d versions, got %d", len(tt.expectedVersions), len(versions))
+			}
+			for i, expected := range tt.expectedVersions {
+				if versions[i].Version != expected {
+					t.Errorf("Expected version %d to be %s, got %s", i, expected, versions[i].Version)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertReleaseToVersion_RiskInformation(t *testing.T) {
+	version := convertReleaseToVersion(&GitHubRelease{
+		TagName:     "v2.0.0",
+		Body:        "## Changes\n\nBREAKING CHANGE: the config format changed",
+		PublishedAt: "2026-10-01T08:00:00Z",
+	})
+	if !version.Breaking {
+		t.Error("Expected release notes mentioning BREAKING to mark the version as breaking")
+	}
+	if version.ReleasedAt.IsZero() || version.ReleasedAt.Day() != 1 {
+		t.Errorf("Expected the publication time as release time, got %v", version.ReleasedAt)
+	}
+
+	version = convertReleaseToVersion(&GitHubRelease{TagName: "v2.0.1", Body: "Non-breaking bug fixes"})
+	if version.Breaking || !version.ReleasedAt.IsZero() || version.CVEs != nil {
+		t.Errorf("Expected a non-breaking version without release time and CVEs, got %+v", version)
+	}
+
+	version = convertReleaseToVersion(&GitHubRelease{TagName: "v2.0.2", Body: "Fixes CVE-2026-1234 and CVE-2026-56789 (see CVE-2026-1234)"})
+	if len(version.CVEs) != 2 || version.CVEs[0] != "CVE-2026-1234" || version.CVEs[1] != "CVE-2026-56789" {
+		t.Errorf("Expected the CVE IDs of the release notes once each, got %v", version.CVEs)
+	}
+}
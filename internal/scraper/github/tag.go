@@ -3,11 +3,8 @@ package github
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"regexp"
 	"sort"
-	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/rs/zerolog/log"
@@ -25,10 +22,18 @@ func scrapeTag(provider *configuration.PackageSourceProvider, source *configurat
 	// Build API base URL
 	apiBaseURL := BuildAPIURL(provider.BaseUrl)
 
-	// Fetch all tags from GitHub
-	tags, err := fetchAllGitHubTags(apiBaseURL, repoInfo, provider, source)
-	if err != nil {
-		return nil, err
+	// Use the tags prefetched with GraphQL, or fetch all tags from GitHub
+	tags, ok := opts.TagBatch.get(repoInfo)
+	if ok {
+		log.Debug().Str("repo", fmt.Sprintf("%s/%s", repoInfo.Owner, repoInfo.Repo)).Msg("using tags prefetched with GraphQL")
+		if source.TagLimit > 0 && len(tags) > source.TagLimit {
+			tags = tags[:source.TagLimit]
+		}
+	} else {
+		tags, err = fetchAllGitHubTags(apiBaseURL, repoInfo, provider, source, opts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	log.Debug().
@@ -61,21 +66,14 @@ func scrapeTag(provider *configuration.PackageSourceProvider, source *configurat
 		Int("removed", len(allVersions)-len(filteredVersions)).
 		Msg("filtered versions")
 
-	// Apply limit if specified and we have more versions than requested
-	versions := filteredVersions
-	if opts.Limit > 0 && len(versions) > opts.Limit {
-		versions = versions[:opts.Limit]
-	}
-
+	// The limit is applied by the orchestrator on the full candidate list
 	log.Debug().
-		Int("count", len(versions)).
+		Int("count", len(filteredVersions)).
 		Int("total_fetched", len(tags)).
-		Int("after_filtering", len(filteredVersions)).
-		Int("after_limit", len(versions)).
 		Str("repo", fmt.Sprintf("%s/%s", repoInfo.Owner, repoInfo.Repo)).
 		Msg("scraped GitHub tags")
 
-	return versions, nil
+	return filteredVersions, nil
 }
 
 type GitHubTag struct {
@@ -86,7 +84,7 @@ type GitHubTag struct {
 	} `json:"commit"`
 }
 
-func fetchAllGitHubTags(apiBaseURL string, repoInfo *RepositoryInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource) ([]GitHubTag, error) {
+func fetchAllGitHubTags(apiBaseURL string, repoInfo *RepositoryInfo, provider *configuration.PackageSourceProvider, source *configuration.PackageSource, opts *ScrapeOptions) ([]GitHubTag, error) {
 	allTags := make([]GitHubTag, 0)
 	perPage := 100
 	page := 1
@@ -97,8 +95,6 @@ func fetchAllGitHubTags(apiBaseURL string, repoInfo *RepositoryInfo, provider *c
 		tagLimit = 0 // Normalize negative values to unlimited
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-
 	for {
 		// Check if we've reached the tag limit
 		if tagLimit > 0 && len(allTags) >= tagLimit {
@@ -116,39 +112,11 @@ func fetchAllGitHubTags(apiBaseURL string, repoInfo *RepositoryInfo, provider *c
 			Int("page", page).
 			Msg("fetching GitHub tags page")
 
-		request, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		// Add authentication if configured
-		if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
-			request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", provider.Token))
-		} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
-			request.SetBasicAuth(provider.Username, provider.Password)
-		}
-
-		// Add GitHub API headers
-		request.Header.Set("Accept", "application/vnd.github+json")
-		request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-		response, err := client.Do(request)
+		body, err := fetchGitHubAPI(opts.httpClient(), apiURL, provider, opts.RateLimiter, "application/vnd.github+json")
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch tags: %w", err)
 		}
 
-		if response.StatusCode != http.StatusOK {
-			response.Body.Close()
-			return nil, fmt.Errorf("failed to fetch tags: HTTP %d", response.StatusCode)
-		}
-
-		body, err := io.ReadAll(response.Body)
-		response.Body.Close()
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to read tags response: %w", err)
-		}
-
 		var pageTags []GitHubTag
 		if err := json.Unmarshal(body, &pageTags); err != nil {
 			return nil, fmt.Errorf("failed to parse tags response: %w", err)
@@ -178,6 +146,19 @@ func fetchAllGitHubTags(apiBaseURL string, repoInfo *RepositoryInfo, provider *c
 			break
 		}
 
+		// Stop once the current version of all targets has been passed
+		pageNames := make([]string, 0, len(pageTags))
+		for _, tag := range pageTags {
+			pageNames = append(pageNames, tag.Name)
+		}
+		if opts.PaginationStop.Passed(pageNames) {
+			log.Debug().
+				Int("page", page).
+				Int("tags_fetched", len(allTags)).
+				Msg("passed current version, stopping pagination")
+			break
+		}
+
 		page++
 	}
 
@@ -197,6 +178,7 @@ func parseGitTag(tagName string, commitSHA string) *configuration.PackageSourceV
 	}
 
 	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(tagName)
+	version.BuildVersion, version.Revision = configuration.ParseBuild(tagName)
 
 	// Add commit SHA as version information
 	if commitSHA != "" {
@@ -211,7 +193,7 @@ func filterGitVersions(versions []*configuration.PackageSourceVersion, source *c
 	var tagPatternRe *regexp.Regexp
 	if source.TagPattern != "" {
 		var err error
-		tagPatternRe, err = regexp.Compile(source.TagPattern)
+		tagPatternRe, err = configuration.CompilePattern(source.TagPattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid tag pattern %q: %w", source.TagPattern, err)
 		}
@@ -220,7 +202,7 @@ func filterGitVersions(versions []*configuration.PackageSourceVersion, source *c
 	var excludePatternRe *regexp.Regexp
 	if source.ExcludePattern != "" {
 		var err error
-		excludePatternRe, err = regexp.Compile(source.ExcludePattern)
+		excludePatternRe, err = configuration.CompilePattern(source.ExcludePattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid exclude pattern %q: %w", source.ExcludePattern, err)
 		}
@@ -261,16 +243,8 @@ func sortVersions(versions []*configuration.PackageSourceVersion, source *config
 	case "semantic":
 		// Sort by semantic version (highest first)
 		sort.Slice(versions, func(i, j int) bool {
-			// Compare major version
-			if versions[i].MajorVersion != versions[j].MajorVersion {
-				return versions[i].MajorVersion > versions[j].MajorVersion
-			}
-			// Compare minor version
-			if versions[i].MinorVersion != versions[j].MinorVersion {
-				return versions[i].MinorVersion > versions[j].MinorVersion
-			}
-			// Compare patch version
-			return versions[i].PatchVersion > versions[j].PatchVersion
+			// Compare by the version scheme of the source
+			return source.CompareVersions(versions[i], versions[j]) > 0
 		})
 	case "alphabetical":
 		// Sort alphabetically (reverse)
@@ -279,6 +253,6 @@ func sortVersions(versions []*configuration.PackageSourceVersion, source *config
 		})
 	default:
 		log.Warn().Str("sortBy", sortBy).Msg("unknown sort method, using semantic")
-		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic"})
+		sortVersions(versions, &configuration.PackageSource{SortBy: "semantic", VersionScheme: source.VersionScheme})
 	}
 }
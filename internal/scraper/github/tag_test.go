@@ -0,0 +1,58 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestFetchAllGitHubTags_Pagination(t *testing.T) {
+	// 250 tags served in pages of 100
+	totalTags := 250
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var tags []GitHubTag
+		for i := (page - 1) * 100; i < min(page*100, totalTags); i++ {
+			tags = append(tags, GitHubTag{Name: fmt.Sprintf("v1.0.%d", i)})
+		}
+		json.NewEncoder(w).Encode(tags)
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+	repoInfo := &RepositoryInfo{Owner: "owner", Repo: "repo"}
+
+	tests := []struct {
+		name     string
+		tagLimit int
+		expected int
+	}{
+		{
+			name:     "all pages",
+			expected: 250,
+		},
+		{
+			name:     "tag limit across pages",
+			tagLimit: 150,
+			expected: 150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &configuration.PackageSource{URI: "https://github.com/owner/repo", TagLimit: tt.tagLimit}
+			tags, err := fetchAllGitHubTags(BuildAPIURL(provider.BaseUrl), repoInfo, provider, source, &ScrapeOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(tags) != tt.expected {
+				t.Errorf("Expected %d tags, got %d", tt.expected, len(tags))
+			}
+		})
+	}
+}
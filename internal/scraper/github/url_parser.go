@@ -66,6 +66,17 @@ func ParseRepositoryURL(uri string) (*RepositoryInfo, error) {
 	}, nil
 }
 
+// BuildCloneURL constructs the HTTPS clone URL of a repository based on the base URL
+// configuration of the provider, which may point at the API of GitHub or GitHub Enterprise
+func BuildCloneURL(baseURL string, repoInfo *RepositoryInfo) string {
+	host := strings.TrimSuffix(baseURL, "/")
+	host = strings.TrimSuffix(host, "/api/v3")
+	if host == "" || host == "https://api.github.com" {
+		host = "https://github.com"
+	}
+	return fmt.Sprintf("%s/%s/%s.git", host, repoInfo.Owner, repoInfo.Repo)
+}
+
 // BuildAPIURL constructs the appropriate GitHub API URL based on the base URL configuration.
 // For GitHub Enterprise, it automatically adds /api/v3 if not present.
 func BuildAPIURL(baseURL string) string {
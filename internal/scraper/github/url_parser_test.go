@@ -227,6 +227,44 @@ func TestBuildAPIURL(t *testing.T) {
 	}
 }
 
+func TestBuildCloneURL(t *testing.T) {
+	repoInfo := &RepositoryInfo{Owner: "owner", Repo: "repo"}
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{
+			name:    "empty base url - defaults to public github",
+			baseURL: "",
+			want:    "https://github.com/owner/repo.git",
+		},
+		{
+			name:    "public github api",
+			baseURL: "https://api.github.com",
+			want:    "https://github.com/owner/repo.git",
+		},
+		{
+			name:    "github enterprise",
+			baseURL: "https://github.enterprise.com/",
+			want:    "https://github.enterprise.com/owner/repo.git",
+		},
+		{
+			name:    "github enterprise with api/v3",
+			baseURL: "https://github.enterprise.com/api/v3",
+			want:    "https://github.enterprise.com/owner/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildCloneURL(tt.baseURL, repoInfo); got != tt.want {
+				t.Errorf("BuildCloneURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && hasSubstring(s, substr)))
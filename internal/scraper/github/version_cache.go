@@ -0,0 +1,80 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// VersionCache stores the last successfully scraped versions of GitHub sources on disk, so
+// that a run whose API rate limit is exhausted can fall back to them instead of failing
+type VersionCache struct {
+	dir string
+}
+
+// cachedVersions is the on-disk format of a VersionCache entry
+type cachedVersions struct {
+	ScrapedAt time.Time                             `json:"scrapedAt"`
+	Versions  []*configuration.PackageSourceVersion `json:"versions"`
+}
+
+// NewVersionCache creates a version cache in dir
+func NewVersionCache(dir string) *VersionCache {
+	return &VersionCache{dir: dir}
+}
+
+// Load returns the cached versions of a source and when they were scraped
+func (c *VersionCache) Load(provider *configuration.PackageSourceProvider, source *configuration.PackageSource) ([]*configuration.PackageSourceVersion, time.Time, error) {
+	if c == nil {
+		return nil, time.Time{}, fmt.Errorf("no version cache configured")
+	}
+	data, err := os.ReadFile(c.path(provider, source))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read version cache: %w", err)
+	}
+	var entry cachedVersions
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse version cache: %w", err)
+	}
+	return entry.Versions, entry.ScrapedAt, nil
+}
+
+// Store saves the scraped versions of a source. A nil cache ignores the versions.
+func (c *VersionCache) Store(provider *configuration.PackageSourceProvider, source *configuration.PackageSource, versions []*configuration.PackageSourceVersion) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(&cachedVersions{ScrapedAt: time.Now(), Versions: versions})
+	if err != nil {
+		return fmt.Errorf("failed to encode version cache: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create version cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path(provider, source), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+	return nil
+}
+
+// path returns the cache file of a source. The key is derived from the provider URL and all
+// settings of the source that change which versions are scraped, so editing the source does
+// not serve stale versions.
+func (c *VersionCache) path(provider *configuration.PackageSourceProvider, source *configuration.PackageSource) string {
+	settings := *source
+	settings.Name = ""
+	settings.StaleAfter = ""
+	settings.Versions = nil
+	key, _ := json.Marshal(struct {
+		BaseUrl string
+		Source  configuration.PackageSource
+	}{provider.BaseUrl, settings})
+	sum := sha256.Sum256(key)
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestVersionCache(t *testing.T) {
+	cache := NewVersionCache(t.TempDir())
+	provider := &configuration.PackageSourceProvider{Name: "github"}
+	source := &configuration.PackageSource{
+		Type: configuration.PackageSourceTypeGitTag,
+		URI:  "https://github.com/owner/repo",
+	}
+
+	if _, _, err := cache.Load(provider, source); err == nil {
+		t.Fatal("Expected error for missing cache entry")
+	}
+
+	versions := []*configuration.PackageSourceVersion{
+		{Version: "v1.2.3", MajorVersion: 1, MinorVersion: 2, PatchVersion: 3},
+	}
+	if err := cache.Store(provider, source, versions); err != nil {
+		t.Fatalf("Failed to store versions: %v", err)
+	}
+
+	cached, scrapedAt, err := cache.Load(provider, source)
+	if err != nil {
+		t.Fatalf("Failed to load versions: %v", err)
+	}
+	if scrapedAt.IsZero() {
+		t.Error("Expected scrape time to be recorded")
+	}
+	if len(cached) != 1 || cached[0].Version != "v1.2.3" || cached[0].PatchVersion != 3 {
+		t.Errorf("Unexpected cached versions: %+v", cached)
+	}
+
+	// A different tag pattern selects different versions and must not hit the entry
+	filtered := *source
+	filtered.TagPattern = `^v2\.`
+	if _, _, err := cache.Load(provider, &filtered); err == nil {
+		t.Error("Expected cache miss for a source with a different tag pattern")
+	}
+}
+
+func TestVersionCache_Key(t *testing.T) {
+	cache := NewVersionCache(t.TempDir())
+	provider := &configuration.PackageSourceProvider{Name: "github", BaseUrl: "https://github.com"}
+	base := configuration.PackageSource{
+		Name: "app",
+		Type: configuration.PackageSourceTypeGitRelease,
+		URI:  "https://github.com/owner/repo",
+	}
+
+	tests := []struct {
+		name      string
+		change    func(source *configuration.PackageSource)
+		sameEntry bool
+	}{
+		{name: "exclude drafts", change: func(s *configuration.PackageSource) { s.ExcludeDrafts = true }},
+		{name: "exclude prereleases", change: func(s *configuration.PackageSource) { s.ExcludePrereleases = true }},
+		{name: "require asset", change: func(s *configuration.PackageSource) { s.RequireAsset = `linux-amd64` }},
+		{name: "chart history", change: func(s *configuration.PackageSource) { s.ChartHistory = true }},
+		{name: "tracks", change: func(s *configuration.PackageSource) {
+			s.Tracks = []*configuration.PackageSourceTrack{{Name: "lts", TagPattern: `-lts$`}}
+		}},
+		{name: "limit", change: func(s *configuration.PackageSource) { s.Limit = 5 }},
+		{name: "tag limit", change: func(s *configuration.PackageSource) { s.TagLimit = 50 }},
+		{name: "renamed source", change: func(s *configuration.PackageSource) { s.Name = "renamed" }, sameEntry: true},
+		{name: "scraped versions", change: func(s *configuration.PackageSource) {
+			s.Versions = []*configuration.PackageSourceVersion{{Version: "v1.0.0"}}
+		}, sameEntry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.change(&changed)
+			if same := cache.path(provider, &base) == cache.path(provider, &changed); same != tt.sameEntry {
+				t.Errorf("expected same cache entry %v, got %v", tt.sameEntry, same)
+			}
+		})
+	}
+
+	otherProvider := &configuration.PackageSourceProvider{Name: "github", BaseUrl: "https://git.example.com"}
+	if cache.path(provider, &base) == cache.path(otherProvider, &base) {
+		t.Error("expected a different cache entry for a different provider URL")
+	}
+}
@@ -0,0 +1,66 @@
+package helm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// errChartAPIUnavailable reports that the repository does not serve the configured chart API
+var errChartAPIUnavailable = errors.New("chart API not available")
+
+// fetchChartAPIEntries lists the versions of a chart with the ChartMuseum or Harbor chart
+// API. Both serve the versions of a single chart below /api, followed by the repository path
+// of the base URL: ChartMuseum at /api[/<tenant path>]/charts/<name> and Harbor at
+// /api/chartrepo/<project>/charts/<name>.
+func fetchChartAPIEntries(client *http.Client, provider *configuration.PackageSourceProvider, chartName string) ([]*HelmIndexEntry, error) {
+	apiURL, err := buildChartAPIURL(provider.BaseUrl, chartName)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Str("apiURL", apiURL).Str("helmApi", string(provider.HelmAPI)).Msg("fetching Helm chart versions")
+	body, statusCode, err := fetchHelmResource(client, apiURL, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chart versions: %w", err)
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		// The API is disabled or the chart is unknown; index.yaml gives the definitive answer
+		return nil, errChartAPIUnavailable
+	default:
+		return nil, fmt.Errorf("failed to fetch chart versions: HTTP %d", statusCode)
+	}
+
+	var entries []*HelmIndexEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		// Repositories without the API may serve an HTML page for unknown paths
+		log.Debug().Err(err).Msg("failed to parse Helm chart API response")
+		return nil, errChartAPIUnavailable
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no versions found for chart '%s'", chartName)
+	}
+
+	return entries, nil
+}
+
+// buildChartAPIURL constructs the chart API URL from the repository base URL
+func buildChartAPIURL(baseURL string, chartName string) (string, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid baseUrl %q: %w", baseURL, err)
+	}
+	repositoryPath := strings.TrimSuffix(parsed.Path, "/")
+	parsed.Path = fmt.Sprintf("/api%s/charts/%s", repositoryPath, chartName)
+	parsed.RawPath = ""
+	return parsed.String(), nil
+}
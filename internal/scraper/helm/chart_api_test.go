@@ -0,0 +1,136 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestBuildChartAPIURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{
+			name:     "chartmuseum",
+			baseURL:  "https://charts.example.com",
+			expected: "https://charts.example.com/api/charts/nginx",
+		},
+		{
+			name:     "chartmuseum multitenancy",
+			baseURL:  "https://charts.example.com/org/repo/",
+			expected: "https://charts.example.com/api/org/repo/charts/nginx",
+		},
+		{
+			name:     "harbor project",
+			baseURL:  "https://harbor.example.com/chartrepo/library",
+			expected: "https://harbor.example.com/api/chartrepo/library/charts/nginx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildChartAPIURL(tt.baseURL, "nginx")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected URL %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestScrapeHelmRepository_ChartAPI(t *testing.T) {
+	mockAPIJSON := `[
+  {"name": "nginx", "version": "1.5.0", "appVersion": "1.21.6", "created": "2024-01-15T10:00:00Z"},
+  {"name": "nginx", "version": "1.4.2", "appVersion": "1.21.5", "created": "2024-01-10T10:00:00Z"}
+]`
+	mockIndexYAML := `apiVersion: v1
+entries:
+  nginx:
+    - name: nginx
+      version: 1.3.0
+`
+
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/chartrepo/library/charts/nginx":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(mockAPIJSON))
+		case "/chartrepo/library/index.yaml", "/index.yaml":
+			w.Write([]byte(mockIndexYAML))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		baseURL       string
+		helmAPI       configuration.HelmAPIType
+		expectedFirst string
+		expectedInfo  string
+		expectedPaths []string
+	}{
+		{
+			name:          "harbor chart API",
+			baseURL:       server.URL + "/chartrepo/library",
+			helmAPI:       configuration.HelmAPITypeHarbor,
+			expectedFirst: "1.5.0",
+			expectedInfo:  "appVersion: 1.21.6, created: 2024-01-15T10:00:00Z",
+			expectedPaths: []string{"/api/chartrepo/library/charts/nginx"},
+		},
+		{
+			name:          "fallback to index.yaml without API",
+			baseURL:       server.URL,
+			helmAPI:       configuration.HelmAPITypeChartMuseum,
+			expectedFirst: "1.3.0",
+			expectedPaths: []string{"/api/charts/nginx", "/index.yaml"},
+		},
+		{
+			name:          "index.yaml without helmApi",
+			baseURL:       server.URL + "/chartrepo/library",
+			expectedFirst: "1.3.0",
+			expectedPaths: []string{"/chartrepo/library/index.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestedPaths = nil
+			provider := &configuration.PackageSourceProvider{
+				Name:    "helm-repo",
+				Type:    configuration.PackageSourceProviderTypeHelm,
+				BaseUrl: tt.baseURL,
+				HelmAPI: tt.helmAPI,
+			}
+			source := &configuration.PackageSource{Name: "nginx", ChartName: "nginx"}
+
+			versions, err := scrapeHelmRepository(provider, source, &ScrapeOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if versions[0].Version != tt.expectedFirst {
+				t.Errorf("Expected first version %s, got %s", tt.expectedFirst, versions[0].Version)
+			}
+			if tt.expectedInfo != "" && versions[0].VersionInformation != tt.expectedInfo {
+				t.Errorf("Expected version info '%s', got '%s'", tt.expectedInfo, versions[0].VersionInformation)
+			}
+			if len(requestedPaths) != len(tt.expectedPaths) {
+				t.Fatalf("Expected requests %v, got %v", tt.expectedPaths, requestedPaths)
+			}
+			for i, path := range tt.expectedPaths {
+				if requestedPaths[i] != path {
+					t.Errorf("Expected request %d to %s, got %s", i, path, requestedPaths[i])
+				}
+			}
+		})
+	}
+}
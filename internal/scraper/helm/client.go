@@ -2,12 +2,43 @@ package helm
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/util"
 )
 
 type ScrapeOptions struct {
-	Limit int
+	IndexCache *IndexCache  // Run-scoped cache of parsed index.yaml documents, optional
+	HTTPClient *http.Client // Client of all repository requests, optional
+}
+
+// httpClient returns the client requests are sent with, a client on the updater transport if
+// none was passed
+func (o *ScrapeOptions) httpClient() *http.Client {
+	if o != nil && o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return util.NewHTTPClient(30 * time.Second)
+}
+
+// ProviderHTTPClient returns the client requests to a provider's repository are sent with:
+// client itself, or for repositories behind mutual TLS a client on a copy of its transport
+// that presents the provider's TLS client certificate. It is built once per provider so the
+// certificate is loaded once and connections are reused across requests.
+func ProviderHTTPClient(client *http.Client, provider *configuration.PackageSourceProvider) (*http.Client, error) {
+	if client == nil {
+		client = util.NewHTTPClient(30 * time.Second)
+	}
+	if provider.CertFile == "" {
+		return client, nil
+	}
+	transport, err := util.ClientCertificateTransport(client.Transport, provider.CertFile, provider.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: client.Timeout, Transport: transport}, nil
 }
 
 type HelmProviderClient struct {
@@ -0,0 +1,45 @@
+package helm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IndexCache holds parsed index.yaml documents keyed by provider and index URL, so sources
+// sharing a Helm repository fetch and parse the index only once per run
+type IndexCache struct {
+	mu      sync.Mutex
+	entries map[string]*HelmIndex
+}
+
+// NewIndexCache creates an empty index cache
+func NewIndexCache() *IndexCache {
+	return &IndexCache{
+		entries: make(map[string]*HelmIndex),
+	}
+}
+
+// get returns the cached index for a provider and URL. A nil cache never hits.
+func (c *IndexCache) get(providerName string, indexURL string) (*HelmIndex, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, ok := c.entries[indexCacheKey(providerName, indexURL)]
+	return index, ok
+}
+
+// put stores a parsed index for a provider and URL. A nil cache ignores the index.
+func (c *IndexCache) put(providerName string, indexURL string, index *HelmIndex) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[indexCacheKey(providerName, indexURL)] = index
+}
+
+func indexCacheKey(providerName string, indexURL string) string {
+	return fmt.Sprintf("%s|%s", providerName, indexURL)
+}
@@ -0,0 +1,34 @@
+package helm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestIndexCache_ConcurrentAccess(t *testing.T) {
+	cache := NewIndexCache()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			indexURL := fmt.Sprintf("https://charts.example.com/%d/index.yaml", i%4)
+			if _, ok := cache.get("helm-repo", indexURL); !ok {
+				cache.put("helm-repo", indexURL, &HelmIndex{APIVersion: "v1"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		indexURL := fmt.Sprintf("https://charts.example.com/%d/index.yaml", i)
+		if index, ok := cache.get("helm-repo", indexURL); !ok || index.APIVersion != "v1" {
+			t.Errorf("expected a cached index for %s", indexURL)
+		}
+	}
+	if _, ok := cache.get("other-repo", "https://charts.example.com/0/index.yaml"); ok {
+		t.Error("expected indexes to be cached per provider")
+	}
+}
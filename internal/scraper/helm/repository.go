@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,12 +16,13 @@ import (
 )
 
 // HelmIndexEntry represents a single chart version in the Helm index.yaml
+// and in the chart version lists of the ChartMuseum and Harbor APIs
 type HelmIndexEntry struct {
-	Name        string `yaml:"name"`
-	Version     string `yaml:"version"`
-	AppVersion  string `yaml:"appVersion,omitempty"`
-	Description string `yaml:"description,omitempty"`
-	Created     string `yaml:"created,omitempty"`
+	Name        string `yaml:"name" json:"name"`
+	Version     string `yaml:"version" json:"version"`
+	AppVersion  string `yaml:"appVersion,omitempty" json:"appVersion,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Created     string `yaml:"created,omitempty" json:"created,omitempty"`
 }
 
 // HelmIndex represents the structure of a Helm repository index.yaml
@@ -46,33 +48,10 @@ func scrapeHelmRepository(provider *configuration.PackageSourceProvider, source
 		return nil, fmt.Errorf("baseUrl is required in provider configuration for helm-repository source type")
 	}
 
-	// Construct index.yaml URL from provider's baseUrl
-	indexURL := buildIndexURL(provider.BaseUrl)
-	log.Debug().Str("indexURL", indexURL).Msg("fetching Helm index.yaml")
-
-	// Fetch index.yaml
-	indexData, err := fetchHelmIndex(indexURL, provider)
+	// Query the chart API of the repository if configured, otherwise index.yaml
+	chartEntries, err := loadChartEntries(provider, source.ChartName, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch Helm index: %w", err)
-	}
-
-	// Parse index.yaml
-	var index HelmIndex
-	if err := yaml.Unmarshal(indexData, &index); err != nil {
-		return nil, fmt.Errorf("failed to parse Helm index.yaml: %w", err)
-	}
-
-	// Find the chart in the index
-	if index.Entries == nil {
-		return nil, fmt.Errorf("Helm index.yaml contains no entries")
-	}
-	chartEntries, exists := index.Entries[source.ChartName]
-	if !exists {
-		return nil, fmt.Errorf("chart '%s' not found in Helm repository", source.ChartName)
-	}
-
-	if len(chartEntries) == 0 {
-		return nil, fmt.Errorf("no versions found for chart '%s'", source.ChartName)
+		return nil, err
 	}
 
 	// Convert ALL entries to PackageSourceVersion FIRST
@@ -83,7 +62,7 @@ func scrapeHelmRepository(provider *configuration.PackageSourceProvider, source
 	}
 
 	// Sort ALL versions by semantic version (descending) BEFORE filtering
-	sortVersions(allVersions)
+	sortVersions(allVersions, source)
 
 	log.Debug().
 		Int("total_versions", len(allVersions)).
@@ -100,21 +79,14 @@ func scrapeHelmRepository(provider *configuration.PackageSourceProvider, source
 		Int("removed", len(allVersions)-len(filteredVersions)).
 		Msg("filtered versions")
 
-	// Apply limit if specified
-	versions := filteredVersions
-	if opts.Limit > 0 && len(versions) > opts.Limit {
-		versions = versions[:opts.Limit]
-	}
-
+	// The limit is applied by the orchestrator on the full candidate list
 	log.Debug().
-		Int("count", len(versions)).
+		Int("count", len(filteredVersions)).
 		Int("total_fetched", len(chartEntries)).
-		Int("after_filtering", len(filteredVersions)).
-		Int("after_limit", len(versions)).
 		Str("chartName", source.ChartName).
 		Msg("successfully scraped Helm repository")
 
-	return versions, nil
+	return filteredVersions, nil
 }
 
 // filterVersions filters versions based on tagPattern and excludePattern
@@ -123,7 +95,7 @@ func filterVersions(versions []*configuration.PackageSourceVersion, source *conf
 	var tagPatternRe *regexp.Regexp
 	if source.TagPattern != "" {
 		var err error
-		tagPatternRe, err = regexp.Compile(source.TagPattern)
+		tagPatternRe, err = configuration.CompilePattern(source.TagPattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid tag pattern %q: %w", source.TagPattern, err)
 		}
@@ -132,7 +104,7 @@ func filterVersions(versions []*configuration.PackageSourceVersion, source *conf
 	var excludePatternRe *regexp.Regexp
 	if source.ExcludePattern != "" {
 		var err error
-		excludePatternRe, err = regexp.Compile(source.ExcludePattern)
+		excludePatternRe, err = configuration.CompilePattern(source.ExcludePattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid exclude pattern %q: %w", source.ExcludePattern, err)
 		}
@@ -163,6 +135,93 @@ func filterVersions(versions []*configuration.PackageSourceVersion, source *conf
 	return filtered, nil
 }
 
+// loadChartEntries returns all versions of a chart. With a chart API configured on the
+// provider, only the versions of that chart are fetched; repositories where the API is not
+// available fall back to index.yaml.
+func loadChartEntries(provider *configuration.PackageSourceProvider, chartName string, opts *ScrapeOptions) ([]*HelmIndexEntry, error) {
+	if provider.HelmAPI != "" {
+		entries, err := fetchChartAPIEntries(opts.httpClient(), provider, chartName)
+		if err == nil {
+			return entries, nil
+		}
+		if !errors.Is(err, errChartAPIUnavailable) {
+			return nil, err
+		}
+		log.Debug().
+			Str("provider", provider.Name).
+			Str("helmApi", string(provider.HelmAPI)).
+			Msg("Helm chart API not available, falling back to index.yaml")
+	}
+
+	// Construct index.yaml URL from provider's baseUrl
+	indexURL := buildIndexURL(provider.BaseUrl)
+
+	// Fetch and parse index.yaml, or reuse it if another source already loaded it
+	index, err := loadHelmIndex(opts.httpClient(), indexURL, provider, opts.IndexCache)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the chart in the index
+	if index.Entries == nil {
+		return nil, fmt.Errorf("Helm index.yaml contains no entries")
+	}
+	chartEntries, exists := index.Entries[chartName]
+	if !exists {
+		return nil, fmt.Errorf("chart '%s' not found in Helm repository", chartName)
+	}
+
+	if len(chartEntries) == 0 {
+		return nil, fmt.Errorf("no versions found for chart '%s'", chartName)
+	}
+
+	return chartEntries, nil
+}
+
+// ListCharts returns the names of all charts of the repository of a provider with at least
+// one version, sorted. The index.yaml is cached like for scraping, so the charts scraped
+// afterwards cost no further download.
+func ListCharts(provider *configuration.PackageSourceProvider, opts *ScrapeOptions) ([]string, error) {
+	index, err := loadHelmIndex(opts.httpClient(), buildIndexURL(provider.BaseUrl), provider, opts.IndexCache)
+	if err != nil {
+		return nil, err
+	}
+
+	charts := make([]string, 0, len(index.Entries))
+	for name, entries := range index.Entries {
+		if len(entries) > 0 {
+			charts = append(charts, name)
+		}
+	}
+	sort.Strings(charts)
+	return charts, nil
+}
+
+// loadHelmIndex fetches and parses the index.yaml at indexURL, using the cache when given
+func loadHelmIndex(client *http.Client, indexURL string, provider *configuration.PackageSourceProvider, cache *IndexCache) (*HelmIndex, error) {
+	if index, ok := cache.get(provider.Name, indexURL); ok {
+		log.Debug().Str("indexURL", indexURL).Msg("using cached Helm index.yaml")
+		return index, nil
+	}
+
+	log.Debug().Str("indexURL", indexURL).Msg("fetching Helm index.yaml")
+
+	// Fetch index.yaml
+	indexData, err := fetchHelmIndex(client, indexURL, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Helm index: %w", err)
+	}
+
+	// Parse index.yaml
+	index := &HelmIndex{}
+	if err := yaml.Unmarshal(indexData, index); err != nil {
+		return nil, fmt.Errorf("failed to parse Helm index.yaml: %w", err)
+	}
+
+	cache.put(provider.Name, indexURL, index)
+	return index, nil
+}
+
 // buildIndexURL constructs the full URL to the index.yaml file
 func buildIndexURL(baseURL string) string {
 	// Ensure baseURL doesn't end with a slash
@@ -171,11 +230,24 @@ func buildIndexURL(baseURL string) string {
 }
 
 // fetchHelmIndex fetches the index.yaml from the Helm repository
-func fetchHelmIndex(indexURL string, provider *configuration.PackageSourceProvider) ([]byte, error) {
+func fetchHelmIndex(client *http.Client, indexURL string, provider *configuration.PackageSourceProvider) ([]byte, error) {
+	body, statusCode, err := fetchHelmResource(client, indexURL, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index.yaml: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch index.yaml: HTTP %d", statusCode)
+	}
+	return body, nil
+}
+
+// fetchHelmResource fetches a URL of the Helm repository with the provider credentials and
+// returns the response body and status code
+func fetchHelmResource(client *http.Client, resourceURL string, provider *configuration.PackageSourceProvider) ([]byte, int, error) {
 	// Create HTTP request
-	request, err := http.NewRequest("GET", indexURL, nil)
+	request, err := http.NewRequest("GET", resourceURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add authentication if configured
@@ -186,24 +258,19 @@ func fetchHelmIndex(indexURL string, provider *configuration.PackageSourceProvid
 	}
 
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch index.yaml: %w", err)
+		return nil, 0, err
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch index.yaml: HTTP %d", response.StatusCode)
-	}
-
 	// Read the response body
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read index.yaml: %w", err)
+		return nil, response.StatusCode, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return body, nil
+	return body, response.StatusCode, nil
 }
 
 // convertToPackageSourceVersion converts a HelmIndexEntry to PackageSourceVersion
@@ -213,33 +280,32 @@ func convertToPackageSourceVersion(entry *HelmIndexEntry) *configuration.Package
 	}
 
 	version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(entry.Version)
+	version.BuildVersion, version.Revision = configuration.ParseBuild(entry.Version)
 
+	var infoItems []string
 	if entry.AppVersion != "" {
-		version.VersionInformation = fmt.Sprintf("appVersion: %s", entry.AppVersion)
+		infoItems = append(infoItems, fmt.Sprintf("appVersion: %s", entry.AppVersion))
+	}
+	if entry.Created != "" {
+		infoItems = append(infoItems, fmt.Sprintf("created: %s", entry.Created))
+		if created, err := time.Parse(time.RFC3339Nano, entry.Created); err == nil {
+			version.ReleasedAt = created
+		}
 	}
+	version.VersionInformation = strings.Join(infoItems, ", ")
 
 	return version
 }
 
-// sortVersions sorts versions by semantic version in descending order (newest first)
-func sortVersions(versions []*configuration.PackageSourceVersion) {
+// sortVersions sorts versions by the version scheme of the source in descending order (newest first)
+func sortVersions(versions []*configuration.PackageSourceVersion, source *configuration.PackageSource) {
 	sort.Slice(versions, func(i, j int) bool {
 		v1 := versions[i]
 		v2 := versions[j]
 
-		// Compare major version
-		if v1.MajorVersion != v2.MajorVersion {
-			return v1.MajorVersion > v2.MajorVersion
-		}
-
-		// Compare minor version
-		if v1.MinorVersion != v2.MinorVersion {
-			return v1.MinorVersion > v2.MinorVersion
-		}
-
-		// Compare patch version
-		if v1.PatchVersion != v2.PatchVersion {
-			return v1.PatchVersion > v2.PatchVersion
+		// Compare by the version scheme of the source
+		if comparison := source.CompareVersions(v1, v2); comparison != 0 {
+			return comparison > 0
 		}
 
 		// If all numeric parts are equal, compare version strings lexicographically
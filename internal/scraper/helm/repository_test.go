@@ -1,9 +1,22 @@
 package helm
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
 )
@@ -73,34 +86,11 @@ generated: "2024-01-20T12:00:00Z"
 				Type:      configuration.PackageSourceTypeHelmRepository,
 				ChartName: "nginx",
 			},
-			opts: &ScrapeOptions{
-				Limit: 0,
-			},
+			opts:          &ScrapeOptions{},
 			expectError:   false,
 			expectedCount: 3,
 			firstVersion:  "1.5.0", // Should be sorted with newest first
 		},
-		{
-			name: "scrape with limit",
-			provider: &configuration.PackageSourceProvider{
-				Name:     "helm-repo",
-				Type:     configuration.PackageSourceProviderTypeHelm,
-				BaseUrl:  server.URL,
-				AuthType: configuration.PackageSourceProviderAuthTypeNone,
-			},
-			source: &configuration.PackageSource{
-				Name:      "nginx-chart",
-				Provider:  "helm-repo",
-				Type:      configuration.PackageSourceTypeHelmRepository,
-				ChartName: "nginx",
-			},
-			opts: &ScrapeOptions{
-				Limit: 2,
-			},
-			expectError:   false,
-			expectedCount: 2,
-			firstVersion:  "1.5.0",
-		},
 		{
 			name: "scrape different chart",
 			provider: &configuration.PackageSourceProvider{
@@ -115,9 +105,7 @@ generated: "2024-01-20T12:00:00Z"
 				Type:      configuration.PackageSourceTypeHelmRepository,
 				ChartName: "redis",
 			},
-			opts: &ScrapeOptions{
-				Limit: 0,
-			},
+			opts:          &ScrapeOptions{},
 			expectError:   false,
 			expectedCount: 1,
 			firstVersion:  "2.1.0",
@@ -136,9 +124,7 @@ generated: "2024-01-20T12:00:00Z"
 				Type:      configuration.PackageSourceTypeHelmRepository,
 				ChartName: "",
 			},
-			opts: &ScrapeOptions{
-				Limit: 0,
-			},
+			opts:          &ScrapeOptions{},
 			expectError:   true,
 			errorContains: "chartName is required",
 		},
@@ -156,9 +142,7 @@ generated: "2024-01-20T12:00:00Z"
 				Type:      configuration.PackageSourceTypeHelmRepository,
 				ChartName: "nonexistent",
 			},
-			opts: &ScrapeOptions{
-				Limit: 0,
-			},
+			opts:          &ScrapeOptions{},
 			expectError:   true,
 			errorContains: "not found in Helm repository",
 		},
@@ -193,6 +177,261 @@ generated: "2024-01-20T12:00:00Z"
 	}
 }
 
+func TestScrapeHelmRepository_IndexCache(t *testing.T) {
+	mockIndexYAML := `apiVersion: v1
+entries:
+  nginx:
+    - name: nginx
+      version: 1.5.0
+  redis:
+    - name: redis
+      version: 2.1.0
+`
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Write([]byte(mockIndexYAML))
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{
+		Name:     "helm-repo",
+		Type:     configuration.PackageSourceProviderTypeHelm,
+		BaseUrl:  server.URL,
+		AuthType: configuration.PackageSourceProviderAuthTypeNone,
+	}
+	opts := &ScrapeOptions{IndexCache: NewIndexCache()}
+
+	for _, chartName := range []string{"nginx", "redis"} {
+		source := &configuration.PackageSource{
+			Name:      chartName,
+			Provider:  "helm-repo",
+			Type:      configuration.PackageSourceTypeHelmRepository,
+			ChartName: chartName,
+		}
+		versions, err := scrapeHelmRepository(provider, source, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", chartName, err)
+		}
+		if len(versions) != 1 {
+			t.Errorf("Expected 1 version for %s, got %d", chartName, len(versions))
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected index.yaml to be fetched once, got %d requests", requests)
+	}
+
+	// A different provider with the same URL does not share the cached index
+	otherProvider := *provider
+	otherProvider.Name = "other-helm-repo"
+	source := &configuration.PackageSource{Name: "nginx", ChartName: "nginx"}
+	if _, err := scrapeHelmRepository(&otherProvider, source, opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a second fetch for another provider, got %d requests", requests)
+	}
+}
+
+func TestScrapeHelmRepository_Auth(t *testing.T) {
+	mockIndexYAML := `apiVersion: v1
+entries:
+  nginx:
+    - name: nginx
+      version: 1.5.0
+`
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := false
+		switch r.Header.Get("Authorization") {
+		case "Bearer secret-token":
+			authorized = true
+		default:
+			username, password, ok := r.BasicAuth()
+			authorized = ok && username == "user" && password == "secret"
+		}
+		if len(r.TLS.PeerCertificates) > 0 && r.TLS.PeerCertificates[0].Subject.CommonName == "updater" {
+			authorized = true
+		}
+		if !authorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(mockIndexYAML))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := writeClientCertificate(t)
+
+	tests := []struct {
+		name        string
+		provider    *configuration.PackageSourceProvider
+		expectError bool
+	}{
+		{
+			name: "bearer token",
+			provider: &configuration.PackageSourceProvider{
+				AuthType: configuration.PackageSourceProviderAuthTypeToken,
+				Token:    "secret-token",
+			},
+		},
+		{
+			name: "basic auth",
+			provider: &configuration.PackageSourceProvider{
+				AuthType: configuration.PackageSourceProviderAuthTypeBasic,
+				Username: "user",
+				Password: "secret",
+			},
+		},
+		{
+			name: "TLS client certificate",
+			provider: &configuration.PackageSourceProvider{
+				CertFile: certFile,
+				KeyFile:  keyFile,
+			},
+		},
+		{
+			name:        "no credentials",
+			provider:    &configuration.PackageSourceProvider{},
+			expectError: true,
+		},
+		{
+			name: "missing certificate file",
+			provider: &configuration.PackageSourceProvider{
+				CertFile: filepath.Join(t.TempDir(), "missing.pem"),
+				KeyFile:  keyFile,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.provider.Name = "helm-repo"
+			tt.provider.Type = configuration.PackageSourceProviderTypeHelm
+			tt.provider.BaseUrl = server.URL
+			source := &configuration.PackageSource{Name: "nginx", ChartName: "nginx"}
+
+			// Trust the test server certificate; the client certificate is added on top of its transport
+			client, err := ProviderHTTPClient(server.Client(), tt.provider)
+			var versions []*configuration.PackageSourceVersion
+			if err == nil {
+				versions, err = scrapeHelmRepository(tt.provider, source, &ScrapeOptions{HTTPClient: client})
+			}
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(versions) != 1 {
+				t.Errorf("Expected 1 version, got %d", len(versions))
+			}
+		})
+	}
+}
+
+func TestProviderHTTPClient(t *testing.T) {
+	mockIndexYAML := `apiVersion: v1
+entries:
+  nginx:
+    - name: nginx
+      version: 1.5.0
+`
+
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(mockIndexYAML))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	base := server.Client()
+	base.Timeout = 5 * time.Second
+
+	// Without a client certificate the client is used as is
+	plain := &configuration.PackageSourceProvider{Name: "helm-repo", BaseUrl: server.URL}
+	client, err := ProviderHTTPClient(base, plain)
+	if err != nil || client != base {
+		t.Fatalf("expected the base client without a certificate, got %v (%v)", client, err)
+	}
+
+	certFile, keyFile := writeClientCertificate(t)
+	provider := &configuration.PackageSourceProvider{Name: "helm-repo", BaseUrl: server.URL, CertFile: certFile, KeyFile: keyFile}
+	client, err = ProviderHTTPClient(base, provider)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client == base || client.Transport == base.Transport || client.Timeout != base.Timeout {
+		t.Fatalf("expected a new client on a copied transport with the same timeout")
+	}
+
+	// All requests of the provider share the client and its connections
+	source := &configuration.PackageSource{Name: "nginx", ChartName: "nginx"}
+	for i := 0; i < 3; i++ {
+		if _, err := scrapeHelmRepository(provider, source, &ScrapeOptions{HTTPClient: client}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("expected 1 connection for all requests, got %d", got)
+	}
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key to PEM files
+func writeClientCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "updater"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return certFile, keyFile
+}
+
 func TestSortVersions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -231,7 +470,7 @@ func TestSortVersions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sortVersions(tt.versions)
+			sortVersions(tt.versions, &configuration.PackageSource{})
 
 			for i, expected := range tt.expected {
 				if tt.versions[i].Version != expected {
@@ -265,6 +504,20 @@ func TestConvertToPackageSourceVersion(t *testing.T) {
 			expectedPatch:   3,
 			expectedInfo:    "appVersion: 1.21.0",
 		},
+		{
+			name: "created timestamp",
+			entry: &HelmIndexEntry{
+				Name:       "nginx",
+				Version:    "1.2.4",
+				AppVersion: "1.21.1",
+				Created:    "2024-01-15T10:00:00Z",
+			},
+			expectedVersion: "1.2.4",
+			expectedMajor:   1,
+			expectedMinor:   2,
+			expectedPatch:   4,
+			expectedInfo:    "appVersion: 1.21.1, created: 2024-01-15T10:00:00Z",
+		},
 		{
 			name: "version with v prefix",
 			entry: &HelmIndexEntry{
@@ -314,6 +567,10 @@ func TestConvertToPackageSourceVersion(t *testing.T) {
 			if version.VersionInformation != tt.expectedInfo {
 				t.Errorf("Expected version info '%s', got '%s'", tt.expectedInfo, version.VersionInformation)
 			}
+
+			if (tt.entry.Created != "") == version.ReleasedAt.IsZero() {
+				t.Errorf("Expected the release time to be read from created '%s', got %v", tt.entry.Created, version.ReleasedAt)
+			}
 		})
 	}
 }
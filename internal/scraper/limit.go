@@ -0,0 +1,102 @@
+package scraper
+
+import (
+	"regexp"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// versionLimiter decides which versions of a sorted candidate list are kept. The newest
+// versions are kept up to the limit, and so are the newest versions of each track, so that
+// tracks following an older release line are not starved by the overall limit.
+type versionLimiter struct {
+	limit       int
+	count       int
+	tracks      []*regexp.Regexp
+	trackCounts []int
+}
+
+// newVersionLimiter creates a limiter for a source. The source's own limit takes precedence
+// over the default; a limit of 0 keeps all versions.
+func newVersionLimiter(source *configuration.PackageSource, defaultLimit int) *versionLimiter {
+	limit := defaultLimit
+	if source.Limit > 0 {
+		limit = source.Limit
+	}
+
+	limiter := &versionLimiter{limit: limit}
+	for _, track := range source.Tracks {
+		pattern, err := configuration.CompilePattern(track.TagPattern)
+		if err != nil {
+			// Invalid track patterns are reported by the validator and when comparing
+			continue
+		}
+		limiter.tracks = append(limiter.tracks, pattern)
+	}
+	limiter.trackCounts = make([]int, len(limiter.tracks))
+	return limiter
+}
+
+// wants reports whether a version is still needed for the overall list or one of its tracks
+func (l *versionLimiter) wants(version *configuration.PackageSourceVersion) bool {
+	if l.limit <= 0 || l.count < l.limit {
+		return true
+	}
+	for i, track := range l.tracks {
+		if l.trackCounts[i] < l.limit && track.MatchString(version.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// first reports whether a version would be the newest kept version overall or of one of
+// its tracks, i.e. a version compare may propose
+func (l *versionLimiter) first(version *configuration.PackageSourceVersion) bool {
+	if l.count == 0 {
+		return true
+	}
+	for i, track := range l.tracks {
+		if l.trackCounts[i] == 0 && track.MatchString(version.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records a kept version
+func (l *versionLimiter) add(version *configuration.PackageSourceVersion) {
+	l.count++
+	for i, track := range l.tracks {
+		if track.MatchString(version.Version) {
+			l.trackCounts[i]++
+		}
+	}
+}
+
+// full reports whether no further version can be kept
+func (l *versionLimiter) full() bool {
+	if l.limit <= 0 || l.count < l.limit {
+		return false
+	}
+	for _, count := range l.trackCounts {
+		if count < l.limit {
+			return false
+		}
+	}
+	return true
+}
+
+// candidateLimit returns how many of the newest candidate versions a scraper streaming tags
+// has to keep for the limiter, or 0 if it needs all of them: tracks are limited separately
+// from the overall list and verification may reject any number of candidates.
+func candidateLimit(source *configuration.PackageSource, defaultLimit int) int {
+	limit := defaultLimit
+	if source.Limit > 0 {
+		limit = source.Limit
+	}
+	if limit <= 0 || len(source.Tracks) > 0 || source.Verification != nil {
+		return 0
+	}
+	return limit
+}
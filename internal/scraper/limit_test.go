@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestVersionLimiter(t *testing.T) {
+	candidates := []string{"2.1.0", "2.0.0", "1.9.0", "1.8.0", "1.7.0", "1.6.0"}
+
+	tests := []struct {
+		name         string
+		source       *configuration.PackageSource
+		defaultLimit int
+		expected     []string
+	}{
+		{
+			name:         "no limit keeps all versions",
+			source:       &configuration.PackageSource{},
+			defaultLimit: 0,
+			expected:     candidates,
+		},
+		{
+			name:         "default limit",
+			source:       &configuration.PackageSource{},
+			defaultLimit: 2,
+			expected:     []string{"2.1.0", "2.0.0"},
+		},
+		{
+			name:         "source limit overrides default",
+			source:       &configuration.PackageSource{Limit: 3},
+			defaultLimit: 2,
+			expected:     []string{"2.1.0", "2.0.0", "1.9.0"},
+		},
+		{
+			name: "tracks keep their newest versions",
+			source: &configuration.PackageSource{
+				Tracks: []*configuration.PackageSourceTrack{
+					{Name: "v1", TagPattern: `^1\.`},
+				},
+			},
+			defaultLimit: 2,
+			expected:     []string{"2.1.0", "2.0.0", "1.9.0", "1.8.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newVersionLimiter(tt.source, tt.defaultLimit)
+			kept := make([]string, 0)
+			for _, candidate := range candidates {
+				if limiter.full() {
+					break
+				}
+				version := &configuration.PackageSourceVersion{Version: candidate}
+				if !limiter.wants(version) {
+					continue
+				}
+				limiter.add(version)
+				kept = append(kept, candidate)
+			}
+
+			if len(kept) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, kept)
+			}
+			for i := range kept {
+				if kept[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, kept)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestVersionLimiter_First(t *testing.T) {
+	source := &configuration.PackageSource{Tracks: []*configuration.PackageSourceTrack{{Name: "v1", TagPattern: `^1\.`}}}
+	limiter := newVersionLimiter(source, 0)
+	expected := map[string]bool{"2.1.0": true, "2.0.0": false, "1.9.0": true, "1.8.0": false}
+	for _, candidate := range []string{"2.1.0", "2.0.0", "1.9.0", "1.8.0"} {
+		version := &configuration.PackageSourceVersion{Version: candidate}
+		if first := limiter.first(version); first != expected[candidate] {
+			t.Errorf("Expected first(%s) to be %v, got %v", candidate, expected[candidate], first)
+		}
+		limiter.add(version)
+	}
+}
+
+func TestSelectVersions_VersionConstraint(t *testing.T) {
+	var candidates []*configuration.PackageSourceVersion
+	for _, version := range []string{"2.1.0", "2.0.0", "1.9.0", "1.8.0", "1.7.0"} {
+		major, minor, patch := configuration.ParseSemver(version)
+		candidates = append(candidates, &configuration.PackageSourceVersion{Version: version, MajorVersion: major, MinorVersion: minor, PatchVersion: patch})
+	}
+
+	source := &configuration.PackageSource{Name: "app", VersionConstraint: ">=1.0 <2.0", Limit: 2}
+	selected, err := (&Orchestrator{}).selectVersions(source, candidates, 0)
+	if err != nil {
+		t.Fatalf("selectVersions() failed: %v", err)
+	}
+	// Versions outside the constraint do not count towards the limit
+	if len(selected) != 2 || selected[0].Version != "1.9.0" || selected[1].Version != "1.8.0" {
+		t.Errorf("unexpected selected versions: %v", selected)
+	}
+
+	source.VersionConstraint = "newest"
+	if _, err := (&Orchestrator{}).selectVersions(source, candidates, 0); err == nil {
+		t.Errorf("expected an error for an invalid constraint")
+	}
+}
+
+func TestCandidateLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       *configuration.PackageSource
+		defaultLimit int
+		expected     int
+	}{
+		{"no limit", &configuration.PackageSource{}, 0, 0},
+		{"default limit", &configuration.PackageSource{}, 5, 5},
+		{"source limit", &configuration.PackageSource{Limit: 3}, 5, 3},
+		{"tracks need all candidates", &configuration.PackageSource{Tracks: []*configuration.PackageSourceTrack{{Name: "v1", TagPattern: `^1\.`}}}, 5, 0},
+		{"verification needs all candidates", &configuration.PackageSource{Verification: &configuration.PackageSourceVerification{}}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if limit := candidateLimit(tt.source, tt.defaultLimit); limit != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, limit)
+			}
+		})
+	}
+}
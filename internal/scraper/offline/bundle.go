@@ -0,0 +1,98 @@
+package offline
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BundleFeedFile is the name of the feed file in a feed bundle
+const BundleFeedFile = "feed.yaml"
+
+// BundleSignatureFile is the name of the cosign signature bundle of the feed file in a feed
+// bundle, verified with cosign verify-blob before the feed is trusted
+const BundleSignatureFile = BundleFeedFile + ".bundle"
+
+// IsBundle reports whether a feed path names a feed bundle, a gzip-compressed tar archive
+func IsBundle(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// WriteBundle writes the given files into a feed bundle at path, named by their base names
+func WriteBundle(path string, files []string, modTime time.Time) error {
+	output, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create feed bundle: %w", err)
+	}
+	defer output.Close()
+
+	gzipWriter := gzip.NewWriter(output)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		header := &tar.Header{
+			Name:    filepath.Base(file),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write feed bundle: %w", err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return fmt.Errorf("failed to write feed bundle: %w", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write feed bundle: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write feed bundle: %w", err)
+	}
+	return output.Close()
+}
+
+// readBundle reads the feed files of a feed bundle. Signatures are not verified here but when
+// the bundle is transferred into the network.
+func readBundle(path string) (*Feed, error) {
+	input, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	defer input.Close()
+
+	gzipReader, err := gzip.NewReader(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed bundle %s: %w", path, err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	var files []*feedFile
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feed bundle %s: %w", path, err)
+		}
+		if header.Typeflag != tar.TypeReg || !isFeedFile(header.Name) {
+			continue
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feed bundle %s: %w", path, err)
+		}
+		files = append(files, &feedFile{name: path + ":" + header.Name, data: data})
+	}
+	return mergeFeeds(path, files)
+}
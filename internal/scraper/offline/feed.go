@@ -0,0 +1,176 @@
+package offline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"gopkg.in/yaml.v3"
+)
+
+// Feed is a version feed: the versions of package sources, scraped by an updater with internet
+// access and read by offline providers inside air-gapped networks. Feeds are YAML or JSON
+// files with the same keys.
+type Feed struct {
+	GeneratedAt time.Time     `yaml:"generatedAt,omitempty"`
+	Sources     []*FeedSource `yaml:"sources"`
+}
+
+// FeedSource holds the versions of a package source, identified by its name. The type and URI
+// of the source as scraped guard against feeds of a different configuration.
+type FeedSource struct {
+	Name     string                                `yaml:"name"`
+	Type     configuration.PackageSourceType       `yaml:"type"`
+	URI      string                                `yaml:"uri,omitempty"`
+	Versions []*configuration.PackageSourceVersion `yaml:"versions"`
+}
+
+// LoadFeed reads a feed file, a feed bundle written by export-feed (.tar.gz), or all .yaml,
+// .yml and .json feed files of a directory or bundle merged into one feed. A source may only
+// be listed once across all files.
+func LoadFeed(path string) (*Feed, error) {
+	if IsBundle(path) {
+		return readBundle(path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feed: %w", err)
+		}
+		return parseFeed(path, data)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed directory: %w", err)
+	}
+	var files []*feedFile
+	for _, entry := range entries {
+		if entry.IsDir() || !isFeedFile(entry.Name()) {
+			continue
+		}
+		file := filepath.Join(path, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feed: %w", err)
+		}
+		files = append(files, &feedFile{name: file, data: data})
+	}
+	return mergeFeeds(path, files)
+}
+
+// feedFile is the content of a feed file of a directory or bundle
+type feedFile struct {
+	name string
+	data []byte
+}
+
+// mergeFeeds parses the feed files of a directory or bundle into one feed
+func mergeFeeds(path string, files []*feedFile) (*Feed, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no feed files (.yaml, .yml, .json) in %s", path)
+	}
+	feed := &Feed{}
+	listedIn := make(map[string]string)
+	for _, file := range files {
+		fileFeed, err := parseFeed(file.name, file.data)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range fileFeed.Sources {
+			if other, ok := listedIn[source.Name]; ok {
+				return nil, fmt.Errorf("source %s is listed in both %s and %s", source.Name, other, file.name)
+			}
+			listedIn[source.Name] = file.name
+		}
+		feed.Sources = append(feed.Sources, fileFeed.Sources...)
+		if fileFeed.GeneratedAt.After(feed.GeneratedAt) {
+			feed.GeneratedAt = fileFeed.GeneratedAt
+		}
+	}
+	return feed, nil
+}
+
+// parseFeed parses the content of a feed file
+func parseFeed(name string, data []byte) (*Feed, error) {
+	feed := &Feed{}
+	if err := yaml.Unmarshal(data, feed); err != nil {
+		return nil, fmt.Errorf("failed to parse feed %s: %w", name, err)
+	}
+	for i, source := range feed.Sources {
+		if strings.TrimSpace(source.Name) == "" {
+			return nil, fmt.Errorf("feed %s: sources[%d] has no name", name, i)
+		}
+	}
+	return feed, nil
+}
+
+// isFeedFile reports whether a file name has the extension of a feed file
+func isFeedFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// Versions returns the versions of a source, parsed and sorted like scraped versions. Sources
+// the feed does not list, or lists with another type or URI, are errors, as comparing against
+// them would report every target up to date.
+func (f *Feed) Versions(source *configuration.PackageSource) ([]*configuration.PackageSourceVersion, error) {
+	var entry *FeedSource
+	for _, candidate := range f.Sources {
+		if candidate.Name == source.Name {
+			entry = candidate
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("source %s is not in the feed", source.Name)
+	}
+	if entry.Type != source.Type || entry.URI != source.URI {
+		return nil, fmt.Errorf("feed lists source %s as %s %s, but it is configured as %s %s", source.Name, entry.Type, entry.URI, source.Type, source.URI)
+	}
+
+	versions := make([]*configuration.PackageSourceVersion, 0, len(entry.Versions))
+	for _, feedVersion := range entry.Versions {
+		value := strings.TrimSpace(feedVersion.Version)
+		if value == "" {
+			continue
+		}
+		version := &configuration.PackageSourceVersion{
+			Version:            value,
+			VersionInformation: feedVersion.VersionInformation,
+			ReleasedAt:         feedVersion.ReleasedAt,
+			Breaking:           feedVersion.Breaking,
+			CVEs:               feedVersion.CVEs,
+		}
+		version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(value)
+		version.BuildVersion, version.Revision = configuration.ParseBuild(value)
+		versions = append(versions, version)
+	}
+	return configuration.SortVersions(versions, source), nil
+}
+
+// Packages returns the packages of a discovery source the feed lists, from the names of their
+// sources, <discovery source>/<package>
+func (f *Feed) Packages(discovery *configuration.PackageSource) []string {
+	var packages []string
+	for _, source := range f.Sources {
+		if name, ok := strings.CutPrefix(source.Name, discovery.Name+"/"); ok && name != "" {
+			packages = append(packages, name)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
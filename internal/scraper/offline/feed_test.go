@@ -0,0 +1,135 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const yamlFeed = `generatedAt: 2026-10-01T06:00:00Z
+sources:
+  - name: app
+    type: git-release
+    uri: https://github.com/org/app
+    versions:
+      - version: 1.9.0
+      - version: 1.10.0
+        versionInformation: Released 2026-09-30
+      - version: 2.0.0-rc.1
+  - name: images/api
+    type: docker-image
+    uri: registry.example.com/images/api
+    versions:
+      - version: 3.1.0
+  - name: images/web
+    type: docker-image
+    uri: registry.example.com/images/web
+    versions:
+      - version: 1.0.0
+`
+
+const jsonFeed = `{
+  "generatedAt": "2026-10-02T06:00:00Z",
+  "sources": [
+    {"name": "chart", "type": "helm-chart", "versions": [{"version": "0.4.2"}]}
+  ]
+}`
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func versionStrings(versions []*configuration.PackageSourceVersion) []string {
+	values := make([]string, 0, len(versions))
+	for _, version := range versions {
+		values = append(values, version.Version)
+	}
+	return values
+}
+
+func TestLoadFeed(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.yaml"), yamlFeed)
+	writeFile(t, filepath.Join(dir, "helm.json"), jsonFeed)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a feed")
+
+	feed, err := LoadFeed(dir)
+	if err != nil {
+		t.Fatalf("LoadFeed() failed: %v", err)
+	}
+	if len(feed.Sources) != 4 {
+		t.Errorf("expected the sources of both feed files, got %d", len(feed.Sources))
+	}
+	if feed.GeneratedAt.Day() != 2 {
+		t.Errorf("expected the time of the newest feed file, got %s", feed.GeneratedAt)
+	}
+
+	versions, err := feed.Versions(&configuration.PackageSource{Name: "app", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/app"})
+	if err != nil {
+		t.Fatalf("Versions() failed: %v", err)
+	}
+	if got, expected := versionStrings(versions), []string{"2.0.0-rc.1", "1.10.0", "1.9.0"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected versions %v, got %v", expected, got)
+	}
+	if versions[1].MinorVersion != 10 || versions[1].VersionInformation != "Released 2026-09-30" {
+		t.Errorf("unexpected version %+v", versions[1])
+	}
+
+	if versions, err := feed.Versions(&configuration.PackageSource{Name: "chart", Type: configuration.PackageSourceTypeHelmRepository, ChartName: "chart"}); err != nil || len(versions) != 1 {
+		t.Errorf("expected the version of the JSON feed, got %v, %v", versions, err)
+	}
+
+	packages := feed.Packages(&configuration.PackageSource{Name: "images"})
+	if expected := []string{"api", "web"}; !reflect.DeepEqual(packages, expected) {
+		t.Errorf("expected packages %v, got %v", expected, packages)
+	}
+}
+
+func TestLoadFeed_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), yamlFeed)
+	writeFile(t, filepath.Join(dir, "b.yaml"), yamlFeed)
+	if _, err := LoadFeed(dir); err == nil || !strings.Contains(err.Error(), "listed in both") {
+		t.Errorf("expected duplicate sources to fail, got %v", err)
+	}
+
+	if _, err := LoadFeed(t.TempDir()); err == nil {
+		t.Error("expected a directory without feed files to fail")
+	}
+	if _, err := LoadFeed(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected a missing feed to fail")
+	}
+}
+
+func TestFeedVersions_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.yaml")
+	writeFile(t, path, yamlFeed)
+	feed, err := LoadFeed(path)
+	if err != nil {
+		t.Fatalf("LoadFeed() failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		source *configuration.PackageSource
+		err    string
+	}{
+		{name: "not in feed", source: &configuration.PackageSource{Name: "other", Type: configuration.PackageSourceTypeGitTag}, err: "not in the feed"},
+		{name: "other URI", source: &configuration.PackageSource{Name: "app", Type: configuration.PackageSourceTypeGitRelease, URI: "https://github.com/org/fork"}, err: "configured as"},
+		{name: "other type", source: &configuration.PackageSource{Name: "app", Type: configuration.PackageSourceTypeGitTag, URI: "https://github.com/org/app"}, err: "configured as"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := feed.Versions(tt.source); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
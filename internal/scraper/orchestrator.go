@@ -2,8 +2,15 @@ package scraper
 
 import (
 	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/mxcd/updater/internal/scraper/github"
+	"github.com/mxcd/updater/internal/scraper/helm"
+	"github.com/mxcd/updater/internal/util"
 	"github.com/rs/zerolog/log"
 
 	"github.com/schollz/progressbar/v3"
@@ -20,11 +27,24 @@ func (e *ScrapeError) Error() string {
 	return fmt.Sprintf("source %s (provider %s): %v", e.SourceName, e.Provider, e.Err)
 }
 
+// SourceScrapeStat records timing and outcome for a single scraped source
+type SourceScrapeStat struct {
+	SourceName string
+	Provider   string
+	Type       configuration.PackageSourceType
+	Duration   time.Duration
+	Versions   int
+	Latest     string // Newest version, empty if the source has none
+	Err        error
+}
+
 // ScrapeResult holds the outcome of a ScrapeAllSources call
 type ScrapeResult struct {
 	Succeeded int
 	Failed    int
 	Errors    []*ScrapeError
+	Sources   []*SourceScrapeStat
+	Duration  time.Duration
 }
 
 // HasErrors returns true if any sources failed to scrape
@@ -33,14 +53,76 @@ func (r *ScrapeResult) HasErrors() bool {
 }
 
 type Orchestrator struct {
-	config          *configuration.Config
-	providerClients map[string]ProviderClient
+	config           *configuration.Config
+	providerClients  map[string]ProviderClient
+	helmIndexCache   *helm.IndexCache
+	githubTagBatches map[string]*github.TagBatch    // GraphQL tag batches by provider name
+	githubLimiters   map[string]*github.RateLimiter // API rate limit budgets by provider name
+	githubCache      *github.VersionCache           // Last scraped GitHub versions, nil if not enabled
+	httpClient       *http.Client                   // Client of all provider requests
 }
 
-func NewOrchestrator(config *configuration.Config) (*Orchestrator, error) {
+// OrchestratorOptions configures how an orchestrator reaches the providers, usually from the
+// --github-cache-dir, --record and --replay flags. A nil *OrchestratorOptions uses the
+// defaults: no cache and live requests.
+type OrchestratorOptions struct {
+	GitHubCacheDir string // Cache the last scraped GitHub versions here as a fallback when the API rate limit is exhausted, disabled if empty
+	RecordDir      string // Store every provider response here
+	ReplayDir      string // Scrape sources from the responses stored here without network access
+}
+
+// Validate checks that at most one of RecordDir and ReplayDir is set and that ReplayDir exists
+func (o *OrchestratorOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.RecordDir != "" && o.ReplayDir != "" {
+		return fmt.Errorf("--record and --replay cannot be combined")
+	}
+	if o.ReplayDir != "" {
+		if info, err := os.Stat(o.ReplayDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("replay directory %s does not exist", o.ReplayDir)
+		}
+	}
+	return nil
+}
+
+// newProviderHTTPClient returns the client of all provider requests, recording or replaying
+// the responses if enabled
+func newProviderHTTPClient(options *OrchestratorOptions) *http.Client {
+	client := util.NewHTTPClient(30 * time.Second)
+	switch {
+	case options.ReplayDir != "":
+		log.Info().Str("dir", options.ReplayDir).Msg("Replaying recorded provider responses")
+		client.Transport = util.NewReplayTransport(options.ReplayDir)
+	case options.RecordDir != "":
+		log.Info().Str("dir", options.RecordDir).Msg("Recording provider responses")
+		client.Transport = util.NewRecordingTransport(client.Transport, options.RecordDir)
+	}
+	return client
+}
+
+// NewOrchestrator creates the orchestrator scraping the sources of a configuration, with the
+// default options if options is nil
+func NewOrchestrator(config *configuration.Config, options *OrchestratorOptions) (*Orchestrator, error) {
+	if options == nil {
+		options = &OrchestratorOptions{}
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
 	o := &Orchestrator{
-		config:          config,
-		providerClients: make(map[string]ProviderClient),
+		config:           config,
+		providerClients:  make(map[string]ProviderClient),
+		helmIndexCache:   helm.NewIndexCache(),
+		githubTagBatches: make(map[string]*github.TagBatch),
+		githubLimiters:   make(map[string]*github.RateLimiter),
+		httpClient:       newProviderHTTPClient(options),
+	}
+
+	if options.GitHubCacheDir != "" {
+		o.githubCache = github.NewVersionCache(options.GitHubCacheDir)
 	}
 
 	for _, provider := range config.PackageSourceProviders {
@@ -57,58 +139,83 @@ func NewOrchestrator(config *configuration.Config) (*Orchestrator, error) {
 func (o *Orchestrator) createProviderClient(provider *configuration.PackageSourceProvider) (ProviderClient, error) {
 	switch provider.Type {
 	case configuration.PackageSourceProviderTypeGitHub:
-		return NewGitHubProviderClient(provider), nil
-	case configuration.PackageSourceProviderTypeDocker:
-		return NewDockerProviderClient(provider), nil
+		var tagBatch *github.TagBatch
+		if provider.GraphQL {
+			tagBatch = github.NewTagBatch()
+			o.githubTagBatches[provider.Name] = tagBatch
+		}
+		rateLimiter := github.NewRateLimiter()
+		o.githubLimiters[provider.Name] = rateLimiter
+		return NewGitHubProviderClient(provider, tagBatch, rateLimiter, o.githubCache, o.httpClient), nil
+	case configuration.PackageSourceProviderTypeDocker, configuration.PackageSourceProviderTypeHarbor:
+		return NewDockerProviderClient(provider, o.httpClient), nil
 	case configuration.PackageSourceProviderTypeHelm:
-		return NewHelmProviderClient(provider), nil
+		return NewHelmProviderClient(provider, o.helmIndexCache, o.httpClient)
+	case configuration.PackageSourceProviderTypeOffline:
+		return NewOfflineProviderClient(provider), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", provider.Type)
 	}
 }
 
 func (o *Orchestrator) ScrapeAllSources(options *ScrapeOptions) *ScrapeResult {
-	log.Debug().Int("count", len(o.config.PackageSources)).Msg("Starting to scrape all package sources")
+	result := &ScrapeResult{}
+	start := time.Now()
+
+	// Discovery sources are listed first, their packages are scraped like configured sources
+	for _, stat := range o.discoverSources(options) {
+		result.Sources = append(result.Sources, stat)
+		o.recordScrapeOutcome(result, stat)
+	}
 
-	bar := progressbar.NewOptions(len(o.config.PackageSources),
+	sources := o.selectSources(options)
+	log.Debug().
+		Int("count", len(sources)).
+		Int("skipped", len(o.config.PackageSources)-len(sources)).
+		Msg("Starting to scrape package sources")
+
+	bar := progressbar.NewOptions(len(sources),
 		progressbar.OptionSetDescription("Scraping package sources:"),
 		progressbar.OptionSetItsString("pkg"),
 		progressbar.OptionShowIts(),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(40),
-		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetWriter(util.StatusOutput()),
+		progressbar.OptionEnableColorCodes(util.ColorEnabled()),
 		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
+			Saucer:        progressSaucer("="),
+			SaucerHead:    progressSaucer(">"),
 			SaucerPadding: " ",
 			BarStart:      "[",
 			BarEnd:        "]",
 		}),
 	)
 
-	result := &ScrapeResult{}
+	o.prefetchGitHubTags(sources)
+	o.prefetchRegistryTokens(sources)
 
-	for _, source := range o.config.PackageSources {
+	for _, source := range sources {
 		bar.Add(1)
-		if err := o.scrapeSource(source, options); err != nil {
-			log.Error().
-				Err(err).
-				Str("source", source.Name).
-				Str("provider", source.Provider).
-				Msg("Failed to scrape package source")
-			result.Failed++
-			result.Errors = append(result.Errors, &ScrapeError{
-				SourceName: source.Name,
-				Provider:   source.Provider,
-				Err:        err,
-			})
-		} else {
-			result.Succeeded++
+		sourceStart := time.Now()
+		err := o.scrapeSource(source, options)
+		stat := &SourceScrapeStat{
+			SourceName: source.Name,
+			Provider:   source.Provider,
+			Type:       source.Type,
+			Duration:   time.Since(sourceStart),
+			Versions:   len(source.Versions),
+			Err:        err,
 		}
+		if latest := source.LatestVersion(); latest != nil {
+			stat.Latest = latest.Version
+		}
+		result.Sources = append(result.Sources, stat)
+		o.recordScrapeOutcome(result, stat)
 	}
 
 	bar.Finish()
-	fmt.Printf("\n")
+	fmt.Fprintln(util.StatusOutput())
+	result.Duration = time.Since(start)
 
 	if result.HasErrors() {
 		log.Warn().
@@ -121,6 +228,84 @@ func (o *Orchestrator) ScrapeAllSources(options *ScrapeOptions) *ScrapeResult {
 	return result
 }
 
+// recordScrapeOutcome counts a scraped source as succeeded or failed
+func (o *Orchestrator) recordScrapeOutcome(result *ScrapeResult, stat *SourceScrapeStat) {
+	if stat.Err == nil {
+		result.Succeeded++
+		return
+	}
+	log.Error().
+		Err(stat.Err).
+		Str("source", stat.SourceName).
+		Str("provider", stat.Provider).
+		Msg("Failed to scrape package source")
+	result.Failed++
+	result.Errors = append(result.Errors, &ScrapeError{
+		SourceName: stat.SourceName,
+		Provider:   stat.Provider,
+		Err:        stat.Err,
+	})
+}
+
+// prefetchGitHubTags fetches the tags of the git-tag sources of GitHub providers with GraphQL
+// enabled in batched queries. Sources the batch does not cover, or all sources of a provider
+// whose batch failed, are scraped with the REST API.
+func (o *Orchestrator) prefetchGitHubTags(sources []*configuration.PackageSource) {
+	for _, provider := range o.config.PackageSourceProviders {
+		tagBatch, ok := o.githubTagBatches[provider.Name]
+		if !ok {
+			continue
+		}
+		var providerSources []*configuration.PackageSource
+		for _, source := range sources {
+			if source.Provider == provider.Name {
+				providerSources = append(providerSources, source)
+			}
+		}
+		if err := tagBatch.Prefetch(o.httpClient, provider, providerSources, o.githubLimiters[provider.Name]); err != nil {
+			log.Warn().Err(err).Str("provider", provider.Name).Msg("Failed to prefetch GitHub tags with GraphQL, falling back to the REST API")
+		}
+	}
+}
+
+// prefetchRegistryTokens exchanges the registry tokens of the docker-image sources of docker
+// and harbor providers with batchTokens enabled in batched requests. Sources the batch does
+// not cover exchange their own token when they are scraped.
+func (o *Orchestrator) prefetchRegistryTokens(sources []*configuration.PackageSource) {
+	for _, provider := range o.config.PackageSourceProviders {
+		if !provider.BatchTokens {
+			continue
+		}
+		var providerSources []*configuration.PackageSource
+		for _, source := range sources {
+			if source.Provider == provider.Name {
+				providerSources = append(providerSources, source)
+			}
+		}
+		if err := docker.PrefetchTokens(o.httpClient, provider, providerSources); err != nil {
+			log.Warn().Err(err).Str("provider", provider.Name).Msg("Failed to prefetch registry tokens, exchanging them per repository")
+		}
+	}
+}
+
+// selectSources returns the package sources to scrape. When the options restrict the
+// sources, all others are skipped. Discovery sources are not scraped themselves but through
+// the sources discovered from them.
+func (o *Orchestrator) selectSources(options *ScrapeOptions) []*configuration.PackageSource {
+	sources := make([]*configuration.PackageSource, 0, len(o.config.PackageSources))
+	for _, source := range o.config.PackageSources {
+		if source.Discovers() {
+			continue
+		}
+		if options == nil || options.Sources == nil || options.Sources[source.Name] {
+			sources = append(sources, source)
+		} else {
+			log.Debug().Str("source", source.Name).Msg("Skipping package source not referenced by any selected target")
+		}
+	}
+	return sources
+}
+
 func (o *Orchestrator) scrapeSource(source *configuration.PackageSource, options *ScrapeOptions) error {
 	log.Debug().
 		Str("source", source.Name).
@@ -129,16 +314,31 @@ func (o *Orchestrator) scrapeSource(source *configuration.PackageSource, options
 		Str("uri", source.URI).
 		Msg("Scraping package source")
 
-	// Get the provider client
-	client, exists := o.providerClients[source.Provider]
-	if !exists {
-		return fmt.Errorf("provider %s not found", source.Provider)
+	var versions []*configuration.PackageSourceVersion
+	var err error
+	if source.Type == configuration.PackageSourceTypeStatic {
+		// Static sources list their versions in the configuration
+		if versions, err = scrapeStaticSource(source); err != nil {
+			return err
+		}
+	} else {
+		// Get the provider client
+		client, exists := o.providerClients[source.Provider]
+		if !exists {
+			return fmt.Errorf("provider %s not found", source.Provider)
+		}
+
+		// Scrape the package source
+		versions, err = client.ScrapePackageSource(source, options)
+		if err != nil {
+			return fmt.Errorf("failed to scrape package source: %w", err)
+		}
 	}
 
-	// Scrape the package source
-	versions, err := client.ScrapePackageSource(source, options)
+	// Limit and verify on the full sorted and filtered candidate list
+	versions, err = o.selectVersions(source, versions, options.Limit)
 	if err != nil {
-		return fmt.Errorf("failed to scrape package source: %w", err)
+		return err
 	}
 
 	// Store versions in the source
@@ -152,6 +352,74 @@ func (o *Orchestrator) scrapeSource(source *configuration.PackageSource, options
 	return nil
 }
 
+// selectVersions keeps the newest candidate versions up to the limit of the source and its
+// tracks. Candidates outside the version constraint are dropped without counting towards the
+// limit. Only the newest kept version overall and of each track is verified against the
+// supply-chain policy; a candidate failing verification is dropped and the next one is
+// verified in its place.
+func (o *Orchestrator) selectVersions(source *configuration.PackageSource, versions []*configuration.PackageSourceVersion, defaultLimit int) ([]*configuration.PackageSourceVersion, error) {
+	var constraint *configuration.VersionConstraint
+	if source.VersionConstraint != "" {
+		var err error
+		if constraint, err = configuration.ParseVersionConstraint(source.VersionConstraint); err != nil {
+			return nil, err
+		}
+	}
+
+	verifier, err := o.newVersionVerifier(source)
+	if err != nil {
+		return nil, err
+	}
+	if verifier != nil {
+		defer verifier.close()
+	}
+
+	limiter := newVersionLimiter(source, defaultLimit)
+	selected := make([]*configuration.PackageSourceVersion, 0, len(versions))
+	rejected := 0
+	for _, version := range versions {
+		if limiter.full() {
+			break
+		}
+		if !limiter.wants(version) || (constraint != nil && !constraint.Allows(version)) {
+			continue
+		}
+		if verifier != nil && limiter.first(version) {
+			passed, err := verifier.verify(version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify package source versions: %w", err)
+			}
+			if !passed {
+				rejected++
+				continue
+			}
+		}
+		limiter.add(version)
+		selected = append(selected, version)
+	}
+
+	if verifier != nil && rejected > 0 && len(selected) == 0 {
+		return nil, fmt.Errorf("none of the %d scraped versions passed %s verification", rejected, source.Verification.Type)
+	}
+
+	log.Debug().
+		Str("source", source.Name).
+		Int("candidates", len(versions)).
+		Int("selected", len(selected)).
+		Int("rejected", rejected).
+		Msg("Selected package source versions")
+
+	return selected, nil
+}
+
 func (o *Orchestrator) GetConfig() *configuration.Config {
 	return o.config
 }
+
+// progressSaucer colors a progress bar character green unless color is disabled
+func progressSaucer(saucer string) string {
+	if !util.ColorEnabled() {
+		return saucer
+	}
+	return "[green]" + saucer + "[reset]"
+}
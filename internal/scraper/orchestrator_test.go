@@ -0,0 +1,36 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrchestratorOptions_Validate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name          string
+		options       *OrchestratorOptions
+		errorContains string
+	}{
+		{name: "defaults"},
+		{name: "record", options: &OrchestratorOptions{RecordDir: dir}},
+		{name: "replay", options: &OrchestratorOptions{ReplayDir: dir}},
+		{name: "record and replay", options: &OrchestratorOptions{RecordDir: dir, ReplayDir: dir}, errorContains: "cannot be combined"},
+		{name: "missing replay directory", options: &OrchestratorOptions{ReplayDir: dir + "/missing"}, errorContains: "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
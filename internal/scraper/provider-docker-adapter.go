@@ -1,25 +1,40 @@
 package scraper
 
 import (
+	"net/http"
+
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/scraper/docker"
 )
 
 type DockerProviderClientAdapter struct {
-	client *docker.DockerProviderClient
+	client     *docker.DockerProviderClient
+	httpClient *http.Client
 }
 
-func NewDockerProviderClient(provider *configuration.PackageSourceProvider) ProviderClient {
+func NewDockerProviderClient(provider *configuration.PackageSourceProvider, httpClient *http.Client) ProviderClient {
 	return &DockerProviderClientAdapter{
 		client: &docker.DockerProviderClient{
 			Options: provider,
 		},
+		httpClient: httpClient,
 	}
 }
 
 func (a *DockerProviderClientAdapter) ScrapePackageSource(source *configuration.PackageSource, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
 	dockerOpts := &docker.ScrapeOptions{
-		Limit: opts.Limit,
+		PaginationStop: configuration.NewPaginationStop(source, opts.StopAtVersions[source.Name]),
+		HTTPClient:     a.httpClient,
+		Candidates:     candidateLimit(source, opts.Limit),
 	}
 	return a.client.ScrapePackageSource(source, dockerOpts)
 }
+
+// ListPackages lists the repositories of a docker-namespace source
+func (a *DockerProviderClientAdapter) ListPackages(source *configuration.PackageSource) ([]string, error) {
+	namespace, err := docker.ParseNamespaceURL(source.URI)
+	if err != nil {
+		return nil, err
+	}
+	return docker.ListRepositories(a.client.Options, namespace, &docker.ScrapeOptions{HTTPClient: a.httpClient})
+}
@@ -1,25 +1,66 @@
 package scraper
 
 import (
+	"errors"
+	"net/http"
+	"time"
+
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/scraper/github"
+	"github.com/rs/zerolog/log"
 )
 
 type GitHubProviderClientAdapter struct {
-	client *github.GitHubProviderClient
+	client       *github.GitHubProviderClient
+	tagBatch     *github.TagBatch
+	rateLimiter  *github.RateLimiter
+	versionCache *github.VersionCache
+	httpClient   *http.Client
 }
 
-func NewGitHubProviderClient(provider *configuration.PackageSourceProvider) ProviderClient {
+func NewGitHubProviderClient(provider *configuration.PackageSourceProvider, tagBatch *github.TagBatch, rateLimiter *github.RateLimiter, versionCache *github.VersionCache, httpClient *http.Client) ProviderClient {
 	return &GitHubProviderClientAdapter{
 		client: &github.GitHubProviderClient{
 			Options: provider,
 		},
+		tagBatch:     tagBatch,
+		rateLimiter:  rateLimiter,
+		versionCache: versionCache,
+		httpClient:   httpClient,
 	}
 }
 
 func (a *GitHubProviderClientAdapter) ScrapePackageSource(source *configuration.PackageSource, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
 	githubOpts := &github.ScrapeOptions{
-		Limit: opts.Limit,
+		PaginationStop: configuration.NewPaginationStop(source, opts.StopAtVersions[source.Name]),
+		TagBatch:       a.tagBatch,
+		RateLimiter:    a.rateLimiter,
+		HTTPClient:     a.httpClient,
+	}
+	versions, err := a.client.ScrapePackageSource(source, githubOpts)
+	if err == nil {
+		// An incremental scrape stops at the current version and must not replace the full list
+		if githubOpts.PaginationStop != nil {
+			return versions, nil
+		}
+		if err := a.versionCache.Store(a.client.Options, source, versions); err != nil {
+			log.Debug().Err(err).Str("source", source.Name).Msg("Failed to cache GitHub versions")
+		}
+		return versions, nil
+	}
+	if !errors.Is(err, github.ErrRateLimited) {
+		return nil, err
+	}
+
+	// Degrade to the versions of the last successful scrape rather than failing the run
+	cached, scrapedAt, cacheErr := a.versionCache.Load(a.client.Options, source)
+	if cacheErr != nil {
+		log.Debug().Err(cacheErr).Str("source", source.Name).Msg("No cached GitHub versions available")
+		return nil, err
 	}
-	return a.client.ScrapePackageSource(source, githubOpts)
+	log.Warn().
+		Str("source", source.Name).
+		Str("cachedAt", scrapedAt.Format(time.RFC3339)).
+		Msg("GitHub API rate limit exhausted, using cached versions")
+	return cached, nil
 }
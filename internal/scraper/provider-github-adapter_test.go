@@ -0,0 +1,82 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/github"
+)
+
+func TestGitHubProviderClientAdapter_VersionCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/owner/repo/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"name": "v1.2.0", "commit": {"sha": "a1"}}, {"name": "v1.1.0", "commit": {"sha": "b2"}}]`))
+	}))
+	defer server.Close()
+
+	provider := &configuration.PackageSourceProvider{Name: "github", Type: configuration.PackageSourceProviderTypeGitHub, BaseUrl: server.URL}
+
+	tests := []struct {
+		name        string
+		incremental bool
+		expectCache bool
+	}{
+		{name: "full scrape is cached", expectCache: true},
+		{name: "incremental scrape is not cached", incremental: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := github.NewVersionCache(t.TempDir())
+			client := NewGitHubProviderClient(provider, nil, github.NewRateLimiter(), cache, server.Client())
+			source := &configuration.PackageSource{
+				Name:        "app",
+				Provider:    "github",
+				Type:        configuration.PackageSourceTypeGitTag,
+				URI:         "https://github.com/owner/repo",
+				Incremental: tt.incremental,
+			}
+
+			versions, err := client.ScrapePackageSource(source, &ScrapeOptions{StopAtVersions: map[string]string{"app": "1.1.0"}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(versions) == 0 {
+				t.Fatal("Expected scraped versions")
+			}
+
+			_, _, err = cache.Load(provider, source)
+			if cached := err == nil; cached != tt.expectCache {
+				t.Errorf("expected cached %v, got %v (%v)", tt.expectCache, cached, err)
+			}
+		})
+	}
+}
+
+func TestNewOrchestrator_GitHubCacheDir(t *testing.T) {
+	config := &configuration.Config{}
+	orchestrator, err := NewOrchestrator(config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if orchestrator.githubCache != nil {
+		t.Error("Expected no version cache without a cache directory")
+	}
+
+	orchestrator, err = NewOrchestrator(config, &OrchestratorOptions{GitHubCacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if orchestrator.githubCache == nil {
+		t.Error("Expected a version cache with a cache directory")
+	}
+}
@@ -1,25 +1,46 @@
 package scraper
 
 import (
+	"net/http"
+
 	"github.com/mxcd/updater/internal/configuration"
 	"github.com/mxcd/updater/internal/scraper/helm"
 )
 
 type HelmProviderClientAdapter struct {
-	client *helm.HelmProviderClient
+	client     *helm.HelmProviderClient
+	indexCache *helm.IndexCache
+	httpClient *http.Client
 }
 
-func NewHelmProviderClient(provider *configuration.PackageSourceProvider) ProviderClient {
+// NewHelmProviderClient returns the client of a Helm provider. The HTTP client presenting the
+// provider's TLS client certificate, if any, is built here once and shared by all its sources.
+func NewHelmProviderClient(provider *configuration.PackageSourceProvider, indexCache *helm.IndexCache, httpClient *http.Client) (ProviderClient, error) {
+	providerHTTPClient, err := helm.ProviderHTTPClient(httpClient, provider)
+	if err != nil {
+		return nil, err
+	}
 	return &HelmProviderClientAdapter{
 		client: &helm.HelmProviderClient{
 			Options: provider,
 		},
-	}
+		indexCache: indexCache,
+		httpClient: providerHTTPClient,
+	}, nil
 }
 
 func (a *HelmProviderClientAdapter) ScrapePackageSource(source *configuration.PackageSource, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
 	helmOpts := &helm.ScrapeOptions{
-		Limit: opts.Limit,
+		IndexCache: a.indexCache,
+		HTTPClient: a.httpClient,
 	}
 	return a.client.ScrapePackageSource(source, helmOpts)
 }
+
+// ListPackages lists the charts of the repository of a helm-repo-all source
+func (a *HelmProviderClientAdapter) ListPackages(source *configuration.PackageSource) ([]string, error) {
+	return helm.ListCharts(a.client.Options, &helm.ScrapeOptions{
+		IndexCache: a.indexCache,
+		HTTPClient: a.httpClient,
+	})
+}
@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"sync"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/offline"
+	"github.com/rs/zerolog/log"
+)
+
+// OfflineProviderClientAdapter reads the versions of sources from the feed of an offline
+// provider instead of scraping them
+type OfflineProviderClientAdapter struct {
+	provider *configuration.PackageSourceProvider
+
+	once sync.Once
+	feed *offline.Feed
+	err  error
+}
+
+// NewOfflineProviderClient returns the client of an offline provider. The feed is read once,
+// when the first source of the provider is scraped.
+func NewOfflineProviderClient(provider *configuration.PackageSourceProvider) ProviderClient {
+	return &OfflineProviderClientAdapter{provider: provider}
+}
+
+func (a *OfflineProviderClientAdapter) ScrapePackageSource(source *configuration.PackageSource, opts *ScrapeOptions) ([]*configuration.PackageSourceVersion, error) {
+	feed, err := a.loadFeed()
+	if err != nil {
+		return nil, err
+	}
+	return feed.Versions(source)
+}
+
+// ListPackages lists the packages of a discovery source the feed has sources of
+func (a *OfflineProviderClientAdapter) ListPackages(source *configuration.PackageSource) ([]string, error) {
+	feed, err := a.loadFeed()
+	if err != nil {
+		return nil, err
+	}
+	return feed.Packages(source), nil
+}
+
+func (a *OfflineProviderClientAdapter) loadFeed() (*offline.Feed, error) {
+	a.once.Do(func() {
+		a.feed, a.err = offline.LoadFeed(a.provider.Feed)
+		if a.err == nil {
+			log.Debug().
+				Str("provider", a.provider.Name).
+				Str("feed", a.provider.Feed).
+				Int("sources", len(a.feed.Sources)).
+				Time("generated_at", a.feed.GeneratedAt).
+				Msg("Loaded offline version feed")
+		}
+	})
+	return a.feed, a.err
+}
@@ -3,9 +3,17 @@ package scraper
 import "github.com/mxcd/updater/internal/configuration"
 
 type ScrapeOptions struct {
-	Limit int
+	Limit          int               // Versions kept per source and track unless the source sets its own limit
+	Sources        map[string]bool   // Only scrape these sources when set
+	StopAtVersions map[string]string // Oldest current version per source for incremental scraping
 }
 
 type ProviderClient interface {
 	ScrapePackageSource(*configuration.PackageSource, *ScrapeOptions) ([]*configuration.PackageSourceVersion, error)
 }
+
+// PackageLister is implemented by the provider clients of discovery sources, listing the
+// packages the discovery source covers
+type PackageLister interface {
+	ListPackages(*configuration.PackageSource) ([]string, error)
+}
@@ -0,0 +1,45 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+// scrapeStaticSource returns the versions listed in the configuration of a static source,
+// filtered by its tag and exclude patterns and sorted like scraped versions. With sortBy
+// date, the listed order is kept, newest first.
+func scrapeStaticSource(source *configuration.PackageSource) ([]*configuration.PackageSourceVersion, error) {
+	var tagPattern, excludePattern *regexp.Regexp
+	var err error
+	if source.TagPattern != "" {
+		if tagPattern, err = configuration.CompilePattern(source.TagPattern); err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", source.TagPattern, err)
+		}
+	}
+	if source.ExcludePattern != "" {
+		if excludePattern, err = configuration.CompilePattern(source.ExcludePattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", source.ExcludePattern, err)
+		}
+	}
+
+	versions := make([]*configuration.PackageSourceVersion, 0, len(source.StaticVersions))
+	for _, value := range source.StaticVersions {
+		value = strings.TrimSpace(value)
+		if tagPattern != nil && !tagPattern.MatchString(value) {
+			continue
+		}
+		if excludePattern != nil && excludePattern.MatchString(value) {
+			continue
+		}
+
+		version := &configuration.PackageSourceVersion{Version: value}
+		version.MajorVersion, version.MinorVersion, version.PatchVersion = configuration.ParseSemver(value)
+		version.BuildVersion, version.Revision = configuration.ParseBuild(value)
+		versions = append(versions, version)
+	}
+
+	return configuration.SortVersions(versions, source), nil
+}
@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestScrapeStaticSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   *configuration.PackageSource
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "sorted newest first",
+			source:   &configuration.PackageSource{StaticVersions: []string{"1.9.0", "2.0.0", "1.10.0"}},
+			expected: []string{"2.0.0", "1.10.0", "1.9.0"},
+		},
+		{
+			name: "patterns and version constraint",
+			source: &configuration.PackageSource{
+				StaticVersions:    []string{"1.9.0", "2.0.0", "1.10.0-rc.1", "1.10.0", "latest"},
+				TagPattern:        `^\d+\.\d+\.\d+`,
+				ExcludePattern:    `-rc`,
+				VersionConstraint: "<2",
+			},
+			expected: []string{"1.10.0", "1.9.0"},
+		},
+		{
+			name:     "limit",
+			source:   &configuration.PackageSource{StaticVersions: []string{"1.0.0", "1.1.0", "1.2.0"}},
+			limit:    2,
+			expected: []string{"1.2.0", "1.1.0"},
+		},
+		{
+			name:     "date sorting keeps the listed order",
+			source:   &configuration.PackageSource{StaticVersions: []string{"b", "c", "a"}, SortBy: "date"},
+			expected: []string{"b", "c", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.source.Name = "static"
+			tt.source.Type = configuration.PackageSourceTypeStatic
+			orchestrator, err := NewOrchestrator(&configuration.Config{PackageSources: []*configuration.PackageSource{tt.source}}, nil)
+			if err != nil {
+				t.Fatalf("NewOrchestrator() error = %v", err)
+			}
+
+			result := orchestrator.ScrapeAllSources(&ScrapeOptions{Limit: tt.limit})
+			if result.HasErrors() {
+				t.Fatalf("unexpected scrape errors: %v", result.Errors)
+			}
+			var versions []string
+			for _, version := range tt.source.Versions {
+				versions = append(versions, version.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, versions)
+			}
+		})
+	}
+}
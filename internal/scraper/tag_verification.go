@@ -0,0 +1,172 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/github"
+	"github.com/rs/zerolog/log"
+)
+
+// tagVerifier checks that the git tags of candidate versions are signed by the key or
+// Sigstore identity of the verification policy. Tags are fetched into a temporary bare
+// repository and verified with git verify-tag against a keyring holding only the configured
+// key, or with gitsign verify-tag for keyless policies.
+type tagVerifier struct {
+	source    *configuration.PackageSource
+	cloneURL  string
+	authEnv   []string // git configuration authenticating the fetch, passed by environment to keep tokens off the command line
+	dir       string   // Temporary bare repository the tags are fetched into
+	gnupgHome string   // GnuPG home holding the configured key, empty for keyless policies
+}
+
+// newTagVerifier creates the tag signature verifier for a source with a verification policy
+func (o *Orchestrator) newTagVerifier(source *configuration.PackageSource) (*tagVerifier, error) {
+	verification := source.Verification
+	required := []string{"git", "gitsign"}
+	if verification.Key != "" {
+		required = []string{"git", "gpg"}
+	}
+	for _, binary := range required {
+		if _, err := exec.LookPath(binary); err != nil {
+			return nil, fmt.Errorf("tag-signature verification requires the %s binary on the PATH: %w", binary, err)
+		}
+	}
+
+	repoInfo, err := github.ParseRepositoryURL(source.URI)
+	if err != nil {
+		return nil, err
+	}
+	var provider *configuration.PackageSourceProvider
+	for _, p := range o.config.PackageSourceProviders {
+		if p.Name == source.Provider {
+			provider = p
+			break
+		}
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider %s not found", source.Provider)
+	}
+
+	dir, err := os.MkdirTemp("", "updater-tags-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag verification directory: %w", err)
+	}
+	verifier := &tagVerifier{
+		source:   source,
+		cloneURL: github.BuildCloneURL(provider.BaseUrl, repoInfo),
+		authEnv:  gitAuthEnv(provider),
+		dir:      dir,
+	}
+
+	if output, err := verifier.git("init", "-q", "--bare"); err != nil {
+		verifier.close()
+		return nil, fmt.Errorf("failed to initialize tag verification repository: %w, output: %s", err, output)
+	}
+
+	if verification.Key != "" {
+		verifier.gnupgHome = filepath.Join(dir, "gnupg")
+		if err := os.Mkdir(verifier.gnupgHome, 0700); err != nil {
+			verifier.close()
+			return nil, fmt.Errorf("failed to create GnuPG home: %w", err)
+		}
+		cmd := exec.Command("gpg", "--batch", "--homedir", verifier.gnupgHome, "--import", verification.Key)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			verifier.close()
+			return nil, fmt.Errorf("failed to import verification key %s: %s", verification.Key, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return verifier, nil
+}
+
+// verify reports whether the git tag of a version is signed according to the policy.
+// Lightweight tags carry no signature and never pass.
+func (v *tagVerifier) verify(version *configuration.PackageSourceVersion) (bool, error) {
+	tag := version.Version
+	ref := "refs/tags/" + tag
+	if output, err := v.git("fetch", "--no-tags", "--depth", "1", v.cloneURL, "+"+ref+":"+ref); err != nil {
+		log.Warn().
+			Err(err).
+			Str("source", v.source.Name).
+			Str("tag", tag).
+			Str("output", output).
+			Msg("Failed to fetch tag for verification, skipping version")
+		return false, nil
+	}
+
+	if err := v.verifyTag(tag); err != nil {
+		log.Warn().
+			Err(err).
+			Str("source", v.source.Name).
+			Str("tag", tag).
+			Msg("Tag failed signature verification, skipping version")
+		return false, nil
+	}
+
+	log.Debug().
+		Str("source", v.source.Name).
+		Str("tag", tag).
+		Msg("Tag passed signature verification")
+	return true, nil
+}
+
+// verifyTag verifies the signature of a fetched tag with GnuPG or gitsign
+func (v *tagVerifier) verifyTag(tag string) error {
+	var cmd *exec.Cmd
+	if v.gnupgHome != "" {
+		cmd = exec.Command("git", "verify-tag", tag)
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+v.gnupgHome)
+	} else {
+		cmd = exec.Command("gitsign", "verify-tag",
+			"--certificate-identity", v.source.Verification.CertificateIdentity,
+			"--certificate-oidc-issuer", v.source.Verification.CertificateOidcIssuer,
+			tag)
+	}
+	cmd.Dir = v.dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %s", strings.Join(cmd.Args[:2], " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// git runs a git command in the verification repository
+func (v *tagVerifier) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = v.dir
+	cmd.Env = append(os.Environ(), v.authEnv...)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// close removes the verification repository
+func (v *tagVerifier) close() {
+	if err := os.RemoveAll(v.dir); err != nil {
+		log.Warn().Err(err).Str("dir", v.dir).Msg("Failed to remove tag verification directory")
+	}
+}
+
+// gitAuthEnv returns the environment configuring git to authenticate against the provider
+func gitAuthEnv(provider *configuration.PackageSourceProvider) []string {
+	var credentials string
+	if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
+		credentials = "x-access-token:" + provider.Token
+	} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
+		credentials = provider.Username + ":" + provider.Password
+	}
+	if credentials == "" {
+		return nil
+	}
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)),
+	}
+}
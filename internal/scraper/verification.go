@@ -0,0 +1,167 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/mxcd/updater/internal/scraper/docker"
+	"github.com/rs/zerolog/log"
+)
+
+// versionVerifier checks candidate versions of a source against its verification policy
+type versionVerifier interface {
+	verify(version *configuration.PackageSourceVersion) (bool, error)
+	close()
+}
+
+// newVersionVerifier creates the verifier for a source, or returns nil if the source has no
+// verification policy
+func (o *Orchestrator) newVersionVerifier(source *configuration.PackageSource) (versionVerifier, error) {
+	if source.Verification == nil {
+		return nil, nil
+	}
+	if source.Verification.Type == configuration.PackageSourceVerificationTypeTagSignature {
+		return o.newTagVerifier(source)
+	}
+	return o.newImageVerifier(source)
+}
+
+// imageVerifier checks candidate versions of a source against its verification policy.
+// Verification is delegated to the cosign CLI, which must be on the PATH.
+type imageVerifier struct {
+	source       *configuration.PackageSource
+	baseURL      string
+	dockerConfig string // Temporary Docker config directory with the registry credentials of the provider, empty without credentials
+}
+
+// newImageVerifier creates the cosign verifier for a source with a verification policy
+func (o *Orchestrator) newImageVerifier(source *configuration.PackageSource) (*imageVerifier, error) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return nil, fmt.Errorf("verification requires the cosign binary on the PATH: %w", err)
+	}
+
+	verifier := &imageVerifier{source: source}
+	for _, provider := range o.config.PackageSourceProviders {
+		if provider.Name != source.Provider {
+			continue
+		}
+		verifier.baseURL = provider.BaseUrl
+		imageName, err := docker.BuildImageName(provider.BaseUrl, source.URI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build image name: %w", err)
+		}
+		registry, _, _ := strings.Cut(imageName, "/")
+		if verifier.dockerConfig, err = writeDockerConfig(provider, registry); err != nil {
+			return nil, err
+		}
+		break
+	}
+	return verifier, nil
+}
+
+// writeDockerConfig writes the registry credentials of a provider to the config.json of a
+// temporary Docker config directory, which cosign reads through DOCKER_CONFIG. It returns an
+// empty directory name if the provider has no credentials.
+func writeDockerConfig(provider *configuration.PackageSourceProvider, registry string) (string, error) {
+	var credentials string
+	if provider.AuthType == configuration.PackageSourceProviderAuthTypeToken && provider.Token != "" {
+		credentials = "token:" + provider.Token
+	} else if provider.AuthType == configuration.PackageSourceProviderAuthTypeBasic && provider.Username != "" {
+		credentials = provider.Username + ":" + provider.Password
+	}
+	if credentials == "" {
+		return "", nil
+	}
+	if registry == "docker.io" {
+		// Docker Hub credentials are stored under the legacy index URL
+		registry = "https://index.docker.io/v1/"
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"auths": map[string]any{
+			registry: map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte(credentials))},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry credentials: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "updater-cosign-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create Docker config directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write Docker config: %w", err)
+	}
+	return dir, nil
+}
+
+// close removes the credentials of the verifier
+func (v *imageVerifier) close() {
+	if v.dockerConfig != "" {
+		os.RemoveAll(v.dockerConfig)
+	}
+}
+
+// verify reports whether the image tag of a version passes the verification policy
+func (v *imageVerifier) verify(version *configuration.PackageSourceVersion) (bool, error) {
+	imageRef, err := docker.BuildImageReference(v.baseURL, v.source.URI, version.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed to build image reference: %w", err)
+	}
+
+	if err := verifyImage(imageRef, v.source.Verification, v.dockerConfig); err != nil {
+		log.Warn().
+			Err(err).
+			Str("source", v.source.Name).
+			Str("image", imageRef).
+			Msg("Image failed verification, skipping version")
+		return false, nil
+	}
+
+	log.Debug().
+		Str("source", v.source.Name).
+		Str("image", imageRef).
+		Msg("Image passed verification")
+	return true, nil
+}
+
+// verifyImage runs cosign verify (signature) or cosign verify-attestation (SLSA provenance) for
+// an image reference, authenticating with the Docker config directory unless it is empty
+func verifyImage(imageRef string, verification *configuration.PackageSourceVerification, dockerConfig string) error {
+	var args []string
+	switch verification.Type {
+	case configuration.PackageSourceVerificationTypeSignature:
+		args = []string{"verify"}
+	case configuration.PackageSourceVerificationTypeProvenance:
+		args = []string{"verify-attestation", "--type", "slsaprovenance"}
+	default:
+		return fmt.Errorf("unsupported verification type: %s", verification.Type)
+	}
+
+	if verification.Key != "" {
+		args = append(args, "--key", verification.Key)
+	} else {
+		args = append(args,
+			"--certificate-identity", verification.CertificateIdentity,
+			"--certificate-oidc-issuer", verification.CertificateOidcIssuer)
+	}
+	args = append(args, imageRef)
+
+	cmd := exec.Command("cosign", args...)
+	if dockerConfig != "" {
+		cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+dockerConfig)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cosign %s failed: %s", args[0], strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
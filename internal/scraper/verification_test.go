@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestWriteDockerConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    *configuration.PackageSourceProvider
+		registry    string
+		expectedKey string
+		expected    string
+	}{
+		{
+			name:     "no credentials",
+			provider: &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeNone},
+			registry: "ghcr.io",
+		},
+		{
+			name:        "basic auth",
+			provider:    &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeBasic, Username: "robot", Password: "secret"},
+			registry:    "registry.example.com",
+			expectedKey: "registry.example.com",
+			expected:    "robot:secret",
+		},
+		{
+			name:        "token on Docker Hub",
+			provider:    &configuration.PackageSourceProvider{AuthType: configuration.PackageSourceProviderAuthTypeToken, Token: "dckr_pat"},
+			registry:    "docker.io",
+			expectedKey: "https://index.docker.io/v1/",
+			expected:    "token:dckr_pat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := writeDockerConfig(tt.provider, tt.registry)
+			if err != nil {
+				t.Fatalf("writeDockerConfig() failed: %v", err)
+			}
+			if tt.expected == "" {
+				if dir != "" {
+					t.Errorf("Expected no Docker config without credentials, got %s", dir)
+				}
+				return
+			}
+			defer os.RemoveAll(dir)
+
+			data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+			if err != nil {
+				t.Fatalf("Failed to read Docker config: %v", err)
+			}
+			var config struct {
+				Auths map[string]struct {
+					Auth string `json:"auth"`
+				} `json:"auths"`
+			}
+			if err := json.Unmarshal(data, &config); err != nil {
+				t.Fatalf("Failed to parse Docker config: %v", err)
+			}
+			auth, err := base64.StdEncoding.DecodeString(config.Auths[tt.expectedKey].Auth)
+			if err != nil || string(auth) != tt.expected {
+				t.Errorf("Expected credentials %q for %s, got %q (%v)", tt.expected, tt.expectedKey, auth, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+// maxAPIBodyBytes limits the size of API request bodies
+const maxAPIBodyBytes = 64 * 1024
+
+// ComparisonsResponse is the response of GET /api/v1/comparisons
+type ComparisonsResponse struct {
+	RunID     string                      `json:"runId,omitempty"` // Last finished run, empty if none has finished yet
+	StartedAt *time.Time                  `json:"startedAt,omitempty"`
+	Running   bool                        `json:"running"`
+	NextRun   *time.Time                  `json:"nextRun,omitempty"`
+	Results   []*compare.ComparisonResult `json:"results"` // Same as the results of compare --output json
+}
+
+// RunResponse is the response of POST /api/v1/runs
+type RunResponse struct {
+	Queued bool `json:"queued"` // False if a requested run was already pending
+}
+
+// APIError is the response of a failed API request
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// requireToken rejects API requests without the bearer token of the server
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiToken == "" {
+			writeJSON(w, http.StatusNotFound, &APIError{Error: "the API is disabled, start serve with --api-token"})
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="updater"`)
+			writeJSON(w, http.StatusUnauthorized, &APIError{Error: "missing or invalid bearer token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleAPIComparisons(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	response := &ComparisonsResponse{
+		Running: s.running,
+		Results: make([]*compare.ComparisonResult, 0),
+	}
+	if !s.nextRun.IsZero() {
+		nextRun := s.nextRun
+		response.NextRun = &nextRun
+	}
+	if s.lastRun != nil {
+		startedAt := s.lastRun.StartedAt
+		response.RunID = s.lastRun.ID
+		response.StartedAt = &startedAt
+		response.Results = append(response.Results, s.lastRun.Comparisons...)
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleAPIRuns(w http.ResponseWriter, r *http.Request) {
+	queued := s.requestRun()
+	if queued {
+		log.Info().Msg("Run requested through the API")
+	}
+	writeJSON(w, http.StatusAccepted, &RunResponse{Queued: queued})
+}
+
+func (s *Server) handleAPISnooze(w http.ResponseWriter, r *http.Request) {
+	request := &snoozeRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		writeJSON(w, http.StatusBadRequest, &APIError{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	snooze, err := s.newSnooze(request, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &APIError{Error: err.Error()})
+		return
+	}
+	if err := s.snoozes.AddSnooze(snooze); err != nil {
+		log.Error().Err(err).Msg("Failed to store snooze")
+		writeJSON(w, http.StatusInternalServerError, &APIError{Error: "failed to store snooze"})
+		return
+	}
+
+	log.Info().Str("target", snooze.Target).Str("version", snooze.Version).Time("until", snooze.Until).Msg("Update snoozed through the API")
+	writeJSON(w, http.StatusCreated, snooze)
+}
+
+// writeJSON writes a JSON response, masking secrets
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	writer := util.NewRedactingWriter(w)
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		log.Warn().Err(err).Msg("Failed to write API response")
+	}
+}
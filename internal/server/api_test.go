@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/history"
+)
+
+// apiRequest sends an API request with the given bearer token and returns the response
+func apiRequest(t *testing.T, method string, url string, token string, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// decodeResponse checks the status of an API response and decodes its body
+func decodeResponse(t *testing.T, resp *http.Response, status int, value any) {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("expected status %d, got %d: %s", status, resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, value); err != nil {
+		t.Fatalf("failed to decode %s: %v", body, err)
+	}
+}
+
+func TestAPIAuthentication(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/api/v1/comparisons", status: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, path: "/api/v1/comparisons", token: "wrong", status: http.StatusUnauthorized},
+		{name: "runs without token", method: http.MethodPost, path: "/api/v1/runs", status: http.StatusUnauthorized},
+		{name: "snooze without token", method: http.MethodPost, path: "/api/v1/snooze", status: http.StatusUnauthorized},
+		{name: "valid token", method: http.MethodGet, path: "/api/v1/comparisons", token: "secret-token", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := apiRequest(t, tt.method, ts.URL+tt.path, tt.token, "")
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := httptest.NewServer(New("test", "compare", time.Hour, &MemorySnoozes{}, "").Handler())
+		defer disabled.Close()
+		apiError := &APIError{}
+		decodeResponse(t, apiRequest(t, http.MethodGet, disabled.URL+"/api/v1/comparisons", "", ""), http.StatusNotFound, apiError)
+		if !strings.Contains(apiError.Error, "--api-token") {
+			t.Errorf("unexpected error %q", apiError.Error)
+		}
+	})
+}
+
+func TestAPIComparisons(t *testing.T) {
+	srv, ts := newTestServer(t)
+
+	response := &ComparisonsResponse{}
+	decodeResponse(t, apiRequest(t, http.MethodGet, ts.URL+"/api/v1/comparisons", "secret-token", ""), http.StatusOK, response)
+	if response.RunID != "" || response.Results == nil || len(response.Results) != 0 {
+		t.Errorf("expected no results before the first run, got %+v", response)
+	}
+
+	srv.FinishRun(&Run{
+		ID:        "run-1",
+		StartedAt: time.Now(),
+		Comparisons: []*compare.ComparisonResult{
+			{TargetName: "app", TargetFile: "main.tf", SourceName: "app", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", NeedsUpdate: true, UpdateType: compare.UpdateTypeMajor},
+		},
+	}, time.Now().Add(time.Hour))
+
+	response = &ComparisonsResponse{}
+	decodeResponse(t, apiRequest(t, http.MethodGet, ts.URL+"/api/v1/comparisons", "secret-token", ""), http.StatusOK, response)
+	if response.RunID != "run-1" || response.StartedAt == nil || response.NextRun == nil {
+		t.Errorf("unexpected run %+v", response)
+	}
+	if len(response.Results) != 1 || response.Results[0].LatestVersion != "2.0.0" || !response.Results[0].NeedsUpdate {
+		t.Errorf("unexpected results %+v", response.Results)
+	}
+}
+
+func TestAPIRuns(t *testing.T) {
+	srv, ts := newTestServer(t)
+
+	for _, queued := range []bool{true, false} {
+		response := &RunResponse{}
+		decodeResponse(t, apiRequest(t, http.MethodPost, ts.URL+"/api/v1/runs", "secret-token", ""), http.StatusAccepted, response)
+		if response.Queued != queued {
+			t.Errorf("expected queued %v, got %v", queued, response.Queued)
+		}
+	}
+	select {
+	case <-srv.Triggered():
+	default:
+		t.Fatal("expected a run to be triggered")
+	}
+}
+
+func TestAPISnooze(t *testing.T) {
+	srv, ts := newTestServer(t)
+
+	snooze := &history.Snooze{}
+	body := `{"target": "app", "file": "main.tf", "item": "app_version", "version": "2.0.0", "duration": "12h"}`
+	decodeResponse(t, apiRequest(t, http.MethodPost, ts.URL+"/api/v1/snooze", "secret-token", body), http.StatusCreated, snooze)
+	if !snooze.Matches("app", "main.tf", "app_version", "2.0.0") || snooze.Scope != "test" {
+		t.Errorf("unexpected snooze %+v", snooze)
+	}
+	if until := time.Until(snooze.Until); until < 11*time.Hour || until > 12*time.Hour {
+		t.Errorf("expected the snooze to end in 12 hours, ends in %s", until)
+	}
+
+	snoozes, err := srv.Snoozes(time.Now())
+	if err != nil {
+		t.Fatalf("Snoozes() failed: %v", err)
+	}
+	if len(snoozes) != 1 {
+		t.Fatalf("expected the snooze to be stored, got %d snoozes", len(snoozes))
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid JSON", body: `{"target": `},
+		{name: "unknown field", body: `{"target": "app", "version": "2.0.0", "duration": "1d", "reason": "later"}`},
+		{name: "missing version", body: `{"target": "app", "duration": "1d"}`},
+		{name: "invalid duration", body: `{"target": "app", "version": "2.0.0", "duration": "-1d"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiError := &APIError{}
+			decodeResponse(t, apiRequest(t, http.MethodPost, ts.URL+"/api/v1/snooze", "secret-token", tt.body), http.StatusBadRequest, apiError)
+			if apiError.Error == "" {
+				t.Error("expected an error message")
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+	"github.com/mxcd/updater/internal/history"
+	"github.com/mxcd/updater/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+//go:embed web
+var webFS embed.FS
+
+// pageTemplate renders the web UI
+var pageTemplate = template.Must(template.New("index.html").Funcs(template.FuncMap{
+	"since": func(t time.Time) string {
+		return time.Since(t).Round(time.Second).String()
+	},
+	"until": func(t time.Time) string {
+		return time.Until(t).Round(time.Second).String()
+	},
+}).ParseFS(webFS, "web/index.html"))
+
+// Source is the scrape outcome of a package source in a run
+type Source struct {
+	Name     string
+	Provider string
+	Type     string
+	Latest   string // Newest version, empty if the source has none
+	Versions int
+	Error    string
+}
+
+// PullRequest is a pull request created or updated by an apply run
+type PullRequest struct {
+	PatchGroup string
+	URL        string
+	Created    bool
+}
+
+// Run is the outcome of a compare or apply run shown in the web UI
+type Run struct {
+	ID           string
+	Command      string
+	StartedAt    time.Time
+	Duration     time.Duration
+	Sources      []*Source
+	Comparisons  []*compare.ComparisonResult
+	PullRequests []*PullRequest
+	Errors       []string
+	Log          string // Log output of the run
+	Standby      bool   // Compared without recording because another replica holds the lock
+}
+
+// SnoozeStore keeps the snoozed updates
+type SnoozeStore interface {
+	AddSnooze(snooze *history.Snooze) error
+	Snoozes(scope string, now time.Time) ([]*history.Snooze, error)
+}
+
+// MemorySnoozes keeps snoozes in memory, for servers without a history database
+type MemorySnoozes struct {
+	mu      sync.Mutex
+	snoozes []*history.Snooze
+}
+
+// AddSnooze stores a snooze, replacing an earlier snooze of the same update
+func (m *MemorySnoozes) AddSnooze(snooze *history.Snooze) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.snoozes[:0]
+	for _, existing := range m.snoozes {
+		if existing.Scope != snooze.Scope || !existing.Matches(snooze.Target, snooze.File, snooze.Item, snooze.Version) {
+			kept = append(kept, existing)
+		}
+	}
+	m.snoozes = append(kept, snooze)
+	return nil
+}
+
+// Snoozes returns the snoozes of a scope that have not ended at the given time, soonest
+// ending first
+func (m *MemorySnoozes) Snoozes(scope string, now time.Time) ([]*history.Snooze, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	active := make([]*history.Snooze, 0)
+	for _, snooze := range m.snoozes {
+		if snooze.Scope == scope && snooze.Until.After(now) {
+			active = append(active, snooze)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Until.Before(active[j].Until)
+	})
+	return active, nil
+}
+
+// LogBuffer collects the log output of a run, keeping at most the given number of bytes
+type LogBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	maxBytes int
+}
+
+// NewLogBuffer creates a log buffer keeping at most maxBytes
+func NewLogBuffer(maxBytes int) *LogBuffer {
+	return &LogBuffer{maxBytes: maxBytes}
+}
+
+// Write appends log output, dropping it once the buffer is full
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if remaining := b.maxBytes - b.buf.Len(); remaining > 0 {
+		b.buf.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+// Reset empties the buffer and returns its content
+func (b *LogBuffer) Reset() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	content := b.buf.String()
+	if b.buf.Len() >= b.maxBytes {
+		content += "\n[log truncated]\n"
+	}
+	b.buf.Reset()
+	return content
+}
+
+// Server serves the web UI of the runs of one configuration
+type Server struct {
+	scope    string
+	command  string // compare or apply
+	interval time.Duration
+	snoozes  SnoozeStore
+	apiToken string // Bearer token of the API, the API is disabled if empty
+	trigger  chan struct{}
+
+	mu      sync.RWMutex
+	running bool
+	nextRun time.Time
+	lastRun *Run
+}
+
+// New creates a server of the runs of a scope, which runs the command every interval. The
+// API requires the given bearer token and is disabled if it is empty; running and snoozing
+// in the web UI requires signing in with it, the web UI is read-only without.
+func New(scope string, command string, interval time.Duration, snoozes SnoozeStore, apiToken string) *Server {
+	return &Server{
+		scope:    scope,
+		command:  command,
+		interval: interval,
+		snoozes:  snoozes,
+		apiToken: apiToken,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Triggered receives when a run was requested in the web UI
+func (s *Server) Triggered() <-chan struct{} {
+	return s.trigger
+}
+
+// Snoozes returns the snoozes active at the given time
+func (s *Server) Snoozes(now time.Time) ([]*history.Snooze, error) {
+	return s.snoozes.Snoozes(s.scope, now)
+}
+
+// StartRun marks a run as running
+func (s *Server) StartRun() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+}
+
+// FinishRun records the outcome of a run and the time of the next one
+func (s *Server) FinishRun(run *Run, nextRun time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.lastRun = run
+	s.nextRun = nextRun
+}
+
+// Handler returns the handler of the web UI
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("POST /login", s.handleLogin)
+	mux.HandleFunc("POST /run", s.requireSession(s.handleRun))
+	mux.HandleFunc("POST /snooze", s.requireSession(s.handleSnooze))
+	mux.HandleFunc("GET /api/v1/comparisons", s.requireToken(s.handleAPIComparisons))
+	mux.HandleFunc("POST /api/v1/runs", s.requireToken(s.handleAPIRuns))
+	mux.HandleFunc("POST /api/v1/snooze", s.requireToken(s.handleAPISnooze))
+	return mux
+}
+
+// page is the data the web UI is rendered from
+type page struct {
+	Scope    string
+	Command  string
+	Interval time.Duration
+	Running  bool
+	NextRun  time.Time
+	Run      *Run
+	Updates  []*compare.ComparisonResult // Items with a pending or held back update
+	Failed   []*compare.ComparisonResult // Items that failed to compare
+	Snoozes  []*history.Snooze
+	Message  string
+	// SignedIn is true if the browser holds a session, which runs and snoozes require
+	SignedIn bool
+	// CanSignIn is true if the server has an API token to sign in with
+	CanSignIn bool
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data := &page{
+		Scope:    s.scope,
+		Command:  s.command,
+		Interval: s.interval,
+		Running:  s.running,
+		NextRun:  s.nextRun,
+		Run:      s.lastRun,
+		Message:  r.URL.Query().Get("message"),
+
+		SignedIn:  s.hasSession(r),
+		CanSignIn: s.apiToken != "",
+	}
+	s.mu.RUnlock()
+
+	if data.Run != nil {
+		for _, result := range data.Run.Comparisons {
+			switch {
+			case result.Error != nil:
+				data.Failed = append(data.Failed, result)
+			case result.NeedsUpdate || result.HeldBackVersion != "":
+				data.Updates = append(data.Updates, result)
+			}
+		}
+	}
+	snoozes, err := s.Snoozes(time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list snoozes")
+	}
+	data.Snoozes = snoozes
+
+	var body bytes.Buffer
+	if err := pageTemplate.Execute(&body, data); err != nil {
+		log.Error().Err(err).Msg("Failed to render web UI")
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(util.Redact(body.String())))
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if s.requestRun() {
+		log.Info().Msg("Run requested in the web UI")
+	}
+	redirect(w, r, "Run requested")
+}
+
+// requestRun triggers a run and reports whether it was queued, false if one is already pending
+func (s *Server) requestRun() bool {
+	select {
+	case s.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) handleSnooze(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	snooze, err := s.newSnooze(&snoozeRequest{
+		Target:   r.PostForm.Get("target"),
+		File:     r.PostForm.Get("file"),
+		Item:     r.PostForm.Get("item"),
+		Version:  r.PostForm.Get("version"),
+		Duration: r.PostForm.Get("duration"),
+	}, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.snoozes.AddSnooze(snooze); err != nil {
+		log.Error().Err(err).Msg("Failed to store snooze")
+		http.Error(w, "failed to store snooze", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("target", snooze.Target).Str("version", snooze.Version).Time("until", snooze.Until).Msg("Update snoozed in the web UI")
+	redirect(w, r, fmt.Sprintf("Snoozed %s %s until %s, effective from the next run", snooze.Target, snooze.Version, snooze.Until.Local().Format("2006-01-02 15:04")))
+}
+
+// snoozeRequest is a request to snooze the update of a target item, from the web UI or the API
+type snoozeRequest struct {
+	Target   string `json:"target"`
+	File     string `json:"file"`
+	Item     string `json:"item"`
+	Version  string `json:"version"`
+	Duration string `json:"duration"` // e.g. "7d" or "12h"
+}
+
+// newSnooze validates a snooze request and creates the snooze starting at the given time
+func (s *Server) newSnooze(request *snoozeRequest, now time.Time) (*history.Snooze, error) {
+	if request.Target == "" || request.Version == "" {
+		return nil, fmt.Errorf("target and version are required")
+	}
+	duration, err := util.ParseDuration(request.Duration)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q: must be a positive duration like 7d or 12h", request.Duration)
+	}
+	return &history.Snooze{
+		Scope:     s.scope,
+		Target:    request.Target,
+		File:      request.File,
+		Item:      request.Item,
+		Version:   request.Version,
+		Until:     now.Add(duration).UTC(),
+		CreatedAt: now.UTC(),
+	}, nil
+}
+
+// sessionCookie is the cookie holding the web UI session
+const sessionCookie = "updater_session"
+
+// sessionValue returns the value of the session cookie, derived from the API token so it
+// cannot be forged without it and becomes invalid when the token changes
+func (s *Server) sessionValue() string {
+	mac := hmac.New(sha256.New, []byte(s.apiToken))
+	mac.Write([]byte("updater web UI session"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hasSession reports whether a request carries a valid session cookie
+func (s *Server) hasSession(r *http.Request) bool {
+	if s.apiToken == "" {
+		return false
+	}
+	cookie, err := r.Cookie(sessionCookie)
+	return err == nil && subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(s.sessionValue())) == 1
+}
+
+// requireSession rejects web UI actions from browsers that did not sign in with the API token
+// and from other origins. Without an API token, the web UI is read-only.
+func (s *Server) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+		if s.apiToken == "" {
+			http.Error(w, "the web UI is read-only, start serve with --api-token to run and snooze", http.StatusForbidden)
+			return
+		}
+		if !s.hasSession(r) {
+			http.Error(w, "sign in with the API token first", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+		return
+	}
+	if s.apiToken == "" {
+		http.Error(w, "the web UI is read-only, start serve with --api-token to run and snooze", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("token")), []byte(s.apiToken)) != 1 {
+		log.Warn().Msg("Sign-in to the web UI with an invalid token")
+		redirect(w, r, "Invalid token")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    s.sessionValue(),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	redirect(w, r, "Signed in")
+}
+
+// sameOrigin reports whether a form was posted from the web UI itself, as browsers send the
+// Origin header with every POST. Requests without Origin, e.g. from curl, still need a session.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, r.Host)
+}
+
+// redirect returns to the web UI showing a message
+func redirect(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, "/?message="+url.QueryEscape(message), http.StatusSeeOther)
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mxcd/updater/internal/compare"
+)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := New("test", "compare", time.Hour, &MemorySnoozes{}, "secret-token")
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+// noRedirect keeps redirects as responses so their status can be checked
+var noRedirect = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// signIn returns a client signed in to the web UI, keeping redirects as responses
+func signIn(t *testing.T, ts *httptest.Server, token string) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar, CheckRedirect: noRedirect.CheckRedirect}
+	resp, err := client.PostForm(ts.URL+"/login", url.Values{"token": {token}})
+	if err != nil {
+		t.Fatalf("POST /login failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status 303, got %d", resp.StatusCode)
+	}
+	return client
+}
+
+func TestIndex(t *testing.T) {
+	srv, ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body := readBody(t, resp)
+	if !strings.Contains(body, "No run has finished yet.") {
+		t.Errorf("expected the page without a run, got:\n%s", body)
+	}
+
+	srv.FinishRun(&Run{
+		ID:        "run-1",
+		Command:   "compare",
+		StartedAt: time.Now(),
+		Sources:   []*Source{{Name: "app", Provider: "github", Type: "git-release", Latest: "2.0.0", Versions: 3}},
+		Comparisons: []*compare.ComparisonResult{
+			{TargetName: "app", TargetFile: "main.tf", SourceName: "app", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", NeedsUpdate: true, UpdateType: compare.UpdateTypeMajor},
+			{TargetName: "tools", TargetFile: "tools.tf", SourceName: "tools", CurrentVersion: "1.0.0", LatestVersion: "1.0.0"},
+		},
+		PullRequests: []*PullRequest{{PatchGroup: "default", URL: "https://github.com/org/repo/pull/1", Created: true}},
+		Log:          "compare finished",
+	}, time.Now().Add(time.Hour))
+
+	resp, err = signIn(t, ts, "secret-token").Get(ts.URL + "/?message=hello")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body = readBody(t, resp)
+	for _, want := range []string{"run-1", "hello", `name="version" value="2.0.0"`, "https://github.com/org/repo/pull/1", "compare finished", "Next run in"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected page to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "tools.tf") {
+		t.Errorf("expected the up-to-date target to be left out of the updates")
+	}
+
+	// Without a session, the page offers to sign in instead of running and snoozing
+	resp, err = http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body = readBody(t, resp)
+	if !strings.Contains(body, `action="/login"`) || strings.Contains(body, `action="/run"`) || strings.Contains(body, `action="/snooze"`) {
+		t.Errorf("expected a read-only page with a sign-in form, got:\n%s", body)
+	}
+}
+
+func TestSession(t *testing.T) {
+	srv, ts := newTestServer(t)
+
+	// Requests without a session, e.g. from curl without Origin header, are rejected
+	resp, err := noRedirect.PostForm(ts.URL+"/run", nil)
+	if err != nil {
+		t.Fatalf("POST /run failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a session, got %d", resp.StatusCode)
+	}
+
+	// A wrong token does not create a session
+	client := signIn(t, ts, "wrong-token")
+	resp, err = client.PostForm(ts.URL+"/run", nil)
+	if err != nil {
+		t.Fatalf("POST /run failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 after signing in with a wrong token, got %d", resp.StatusCode)
+	}
+
+	// A forged session cookie is rejected
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/run", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "forged"})
+	resp, err = noRedirect.Do(req)
+	if err != nil {
+		t.Fatalf("POST /run failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401 with a forged session, got %d", resp.StatusCode)
+	}
+	select {
+	case <-srv.Triggered():
+		t.Fatal("expected no run to be triggered without a session")
+	default:
+	}
+
+	// Without an API token, the web UI is read-only
+	readOnly := httptest.NewServer(New("test", "compare", time.Hour, &MemorySnoozes{}, "").Handler())
+	defer readOnly.Close()
+	for _, path := range []string{"/login", "/run", "/snooze"} {
+		resp, err := noRedirect.PostForm(readOnly.URL+path, url.Values{"token": {""}})
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status 403 for %s without an API token, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv, ts := newTestServer(t)
+	client := signIn(t, ts, "secret-token")
+
+	for range 2 {
+		resp, err := client.PostForm(ts.URL+"/run", nil)
+		if err != nil {
+			t.Fatalf("POST /run failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusSeeOther {
+			t.Errorf("expected status 303, got %d", resp.StatusCode)
+		}
+	}
+
+	select {
+	case <-srv.Triggered():
+	default:
+		t.Fatal("expected a run to be triggered")
+	}
+	select {
+	case <-srv.Triggered():
+		t.Fatal("expected repeated requests to trigger a single run")
+	default:
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	srv, ts := newTestServer(t)
+	client := signIn(t, ts, "secret-token")
+
+	form := url.Values{"target": {"app"}, "file": {"main.tf"}, "item": {"app_version"}, "version": {"2.0.0"}, "duration": {"7d"}}
+	resp, err := client.PostForm(ts.URL+"/snooze", form)
+	if err != nil {
+		t.Fatalf("POST /snooze failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status 303, got %d", resp.StatusCode)
+	}
+
+	snoozes, err := srv.Snoozes(time.Now())
+	if err != nil {
+		t.Fatalf("Snoozes() failed: %v", err)
+	}
+	if len(snoozes) != 1 || !snoozes[0].Matches("app", "main.tf", "app_version", "2.0.0") || snoozes[0].Scope != "test" {
+		t.Fatalf("expected the snooze to be stored, got %+v", snoozes)
+	}
+	if until := time.Until(snoozes[0].Until); until < 6*24*time.Hour || until > 7*24*time.Hour {
+		t.Errorf("expected the snooze to end in 7 days, ends in %s", until)
+	}
+
+	tests := []struct {
+		name   string
+		form   url.Values
+		origin string
+		status int
+	}{
+		{
+			name:   "invalid duration",
+			form:   url.Values{"target": {"app"}, "version": {"2.0.0"}, "duration": {"soon"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing version",
+			form:   url.Values{"target": {"app"}, "duration": {"1d"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "cross-origin",
+			form:   form,
+			origin: "https://attacker.example",
+			status: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/snooze", strings.NewReader(tt.form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("POST /snooze failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	buffer := NewLogBuffer(10)
+	buffer.Write([]byte("0123456"))
+	buffer.Write([]byte("789abc"))
+
+	if got := buffer.Reset(); got != "0123456789\n[log truncated]\n" {
+		t.Errorf("unexpected log %q", got)
+	}
+	if got := buffer.Reset(); got != "" {
+		t.Errorf("expected an empty log after reset, got %q", got)
+	}
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
@@ -0,0 +1,270 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// CargoTomlTarget implements the TargetClient interface for dependencies in Cargo.toml files
+type CargoTomlTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+}
+
+// cargoVersionOperator matches the operator of a single version requirement, e.g. "^" in "^1.2"
+var cargoVersionOperator = regexp.MustCompile(`^\s*(\^|~|=|>=|<=|>|<)?\s*`)
+
+// cargoVersion is the location of the version requirement of a dependency in the file
+type cargoVersion struct {
+	table string // Dependency table the crate is declared in
+	start int    // Offset of the string content
+	end   int
+}
+
+// NewCargoTomlTargetForUpdateItem creates a new cargo-toml target for a specific update item
+func NewCargoTomlTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*CargoTomlTarget, error) {
+	if updateItem.CrateName == "" {
+		return nil, fmt.Errorf("crateName is required for cargo-toml target")
+	}
+
+	target := &CargoTomlTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads the target file into memory
+func (t *CargoTomlTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	t.fileContents = string(content)
+	return nil
+}
+
+// findVersions returns the version requirements of the crate in the searched tables. A
+// crate declared in several tables, e.g. as dependency and dev-dependency, is kept in sync.
+func (t *CargoTomlTarget) findVersions() ([]cargoVersion, error) {
+	var versions []cargoVersion
+	for _, version := range scanCargoDependencies(t.fileContents, t.updateItem.CrateName) {
+		if t.isSearchedTable(version.table) {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, &DependencyNotFoundError{Dependency: t.updateItem.CrateName, File: t.config.File}
+	}
+	return versions, nil
+}
+
+// isSearchedTable reports whether a dependency table is searched for the crate, all of
+// them without a dependencyTable
+func (t *CargoTomlTarget) isSearchedTable(table string) bool {
+	return t.updateItem.DependencyTable == "" || table == t.updateItem.DependencyTable
+}
+
+// ReadCurrentVersion reads the version requirement of the crate without its operator
+func (t *CargoTomlTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("crate", t.updateItem.CrateName).
+		Msg("Reading current version from Cargo.toml")
+
+	versions, err := t.findVersions()
+	if err != nil {
+		return "", err
+	}
+
+	requirement := t.fileContents[versions[0].start:versions[0].end]
+	if strings.Contains(requirement, ",") {
+		return "", fmt.Errorf("version requirement '%s' of crate '%s' in file %s is a range, not a single version", requirement, t.updateItem.CrateName, t.config.File)
+	}
+	version := requirement[len(cargoVersionOperator.FindString(requirement)):]
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("crate", t.updateItem.CrateName).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new version to all version requirements of the crate, keeping
+// their operators
+func (t *CargoTomlTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("crate", t.updateItem.CrateName).
+		Str("version", version).
+		Msg("Writing new version to Cargo.toml")
+
+	versions, err := t.findVersions()
+	if err != nil {
+		return err
+	}
+
+	// Replace from the end, so earlier offsets stay valid
+	sort.Slice(versions, func(i, j int) bool { return versions[i].start > versions[j].start })
+	newContents := t.fileContents
+	for _, location := range versions {
+		requirement := newContents[location.start:location.end]
+		if strings.Contains(requirement, ",") {
+			return fmt.Errorf("version requirement '%s' of crate '%s' in file %s is a range, not a single version", requirement, t.updateItem.CrateName, t.config.File)
+		}
+		operator := cargoVersionOperator.FindString(requirement)
+		newContents = newContents[:location.start] + operator + version + newContents[location.end:]
+	}
+
+	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	t.fileContents = newContents
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("crate", t.updateItem.CrateName).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *CargoTomlTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("crate", t.updateItem.CrateName).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *CargoTomlTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	if filepath.Base(t.config.File) != "Cargo.toml" {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must be named Cargo.toml",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("crate", t.updateItem.CrateName).
+		Msg("Cargo.toml target validation successful")
+
+	return nil
+}
+
+// scanCargoDependencies returns the version requirements of a crate in all dependency
+// tables of a Cargo.toml: "crate = "1.0"", "crate = { version = "1.0", ... }" and
+// the "version" key of a [dependencies.crate] table. Dependencies inherited from the
+// workspace or without version, e.g. git dependencies, have none.
+func scanCargoDependencies(contents string, crate string) []cargoVersion {
+	var versions []cargoVersion
+	table := ""         // Dependency table of the current section, empty outside of one
+	crateTable := false // Current section is the [<table>.<crate>] table of the crate
+	offset := 0
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		// Array tables such as [[bin]] end the current section as well
+		if strings.HasPrefix(strings.TrimSpace(line), "[[") {
+			table, crateTable = "", false
+			continue
+		}
+		if header, ok := tomlTableHeader(line); ok {
+			table, crateTable = "", false
+			if isCargoDependencyTable(header) {
+				table = strings.Join(header, ".")
+			} else if len(header) > 1 && header[len(header)-1] == crate && isCargoDependencyTable(header[:len(header)-1]) {
+				table = strings.Join(header[:len(header)-1], ".")
+				crateTable = true
+			}
+			continue
+		}
+		if table == "" {
+			continue
+		}
+		key, value, valueStart := splitTomlLine(line)
+		if key == nil {
+			continue
+		}
+
+		switch {
+		case crateTable && len(key) == 1 && key[0] == "version":
+			if start, end, ok := tomlString(value); ok {
+				versions = append(versions, cargoVersion{table: table, start: lineStart + valueStart + start, end: lineStart + valueStart + end})
+			}
+		case !crateTable && len(key) == 1 && key[0] == crate:
+			if start, end, ok := tomlString(value); ok {
+				versions = append(versions, cargoVersion{table: table, start: lineStart + valueStart + start, end: lineStart + valueStart + end})
+			} else if start, end, ok := inlineTableString(value, "version"); ok {
+				versions = append(versions, cargoVersion{table: table, start: lineStart + valueStart + start, end: lineStart + valueStart + end})
+			}
+		case !crateTable && len(key) == 2 && key[0] == crate && key[1] == "version":
+			// Dotted keys: crate.version = "1.0"
+			if start, end, ok := tomlString(value); ok {
+				versions = append(versions, cargoVersion{table: table, start: lineStart + valueStart + start, end: lineStart + valueStart + end})
+			}
+		}
+	}
+	return versions
+}
+
+// isCargoDependencyTable reports whether a table header names a dependency table:
+// [dependencies], [workspace.dependencies] or [target.<cfg>.dependencies] and their
+// dev- and build- variants
+func isCargoDependencyTable(header []string) bool {
+	kind := header[len(header)-1]
+	if kind != "dependencies" && kind != "dev-dependencies" && kind != "build-dependencies" {
+		return false
+	}
+	switch len(header) {
+	case 1:
+		return true
+	case 2:
+		return header[0] == "workspace" && kind == "dependencies"
+	case 3:
+		return header[0] == "target"
+	default:
+		return false
+	}
+}
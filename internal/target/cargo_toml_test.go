@@ -0,0 +1,207 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testCargoToml = `[package]
+name = "app"
+version = "0.1.0"
+
+[workspace.dependencies]
+anyhow = "1.0.75"
+
+[dependencies]
+serde = { version = "^1.0.190", features = ["derive"] } # serialization
+tokio = { features = ["full", "rt"], version = '1.33' }
+"quoted-crate" = "=0.4.2"
+local = { path = "../local" }
+inherited = { workspace = true }
+log.version = "0.4.20"
+
+[dependencies.regex]
+default-features = false
+version = "~1.10.2"
+
+[dev-dependencies]
+serde = "1.0.190"
+range = ">=1.0, <2.0"
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.27.1"
+
+[[bin]]
+name = "serde"
+`
+
+func newCargoTomlTarget(t *testing.T, crateName string, dependencyTable string) (*CargoTomlTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(tmpFile, []byte(testCargoToml), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeCargoToml,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{CrateName: crateName, DependencyTable: dependencyTable, Source: "test-source"}},
+	}
+	target, err := NewCargoTomlTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestCargoTomlTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name            string
+		crateName       string
+		dependencyTable string
+		expectedVer     string
+		newVersion      string
+		expectedLines   []string
+	}{
+		{
+			name:          "workspace dependency",
+			crateName:     "anyhow",
+			expectedVer:   "1.0.75",
+			newVersion:    "1.0.80",
+			expectedLines: []string{`anyhow = "1.0.80"`},
+		},
+		{
+			name:        "inline table in several tables",
+			crateName:   "serde",
+			expectedVer: "1.0.190",
+			newVersion:  "1.0.195",
+			expectedLines: []string{
+				`serde = { version = "^1.0.195", features = ["derive"] } # serialization`,
+				`serde = "1.0.195"`,
+			},
+		},
+		{
+			name:            "restricted to dependency table",
+			crateName:       "serde",
+			dependencyTable: "dev-dependencies",
+			expectedVer:     "1.0.190",
+			newVersion:      "1.0.195",
+			expectedLines:   []string{`serde = "1.0.195"`},
+		},
+		{
+			name:          "version after other keys",
+			crateName:     "tokio",
+			expectedVer:   "1.33",
+			newVersion:    "1.35",
+			expectedLines: []string{`tokio = { features = ["full", "rt"], version = '1.35' }`},
+		},
+		{
+			name:          "quoted key",
+			crateName:     "quoted-crate",
+			expectedVer:   "0.4.2",
+			newVersion:    "0.5.0",
+			expectedLines: []string{`"quoted-crate" = "=0.5.0"`},
+		},
+		{
+			name:          "dotted key",
+			crateName:     "log",
+			expectedVer:   "0.4.20",
+			newVersion:    "0.4.21",
+			expectedLines: []string{`log.version = "0.4.21"`},
+		},
+		{
+			name:          "crate table",
+			crateName:     "regex",
+			expectedVer:   "1.10.2",
+			newVersion:    "1.10.3",
+			expectedLines: []string{`version = "~1.10.3"`},
+		},
+		{
+			name:          "target dependency",
+			crateName:     "nix",
+			expectedVer:   "0.27.1",
+			newVersion:    "0.28.0",
+			expectedLines: []string{`nix = "0.28.0"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newCargoTomlTarget(t, tt.crateName, tt.dependencyTable)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+			if version, err := target.ReadCurrentVersion(); err != nil || version != tt.newVersion {
+				t.Errorf("Expected version '%s' after write, got '%s' (%v)", tt.newVersion, version, err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(testCargoToml, "\n")
+			if len(lines) != len(original) {
+				t.Fatalf("Expected %d lines, got %d", len(original), len(lines))
+			}
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if strings.Join(changed, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLines)
+			}
+		})
+	}
+}
+
+func TestCargoTomlTarget_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		crateName       string
+		dependencyTable string
+		errorContains   string
+		notFound        bool
+	}{
+		{name: "missing crate", crateName: "rand", notFound: true},
+		{name: "path dependency", crateName: "local", notFound: true},
+		{name: "workspace inherited", crateName: "inherited", notFound: true},
+		{name: "package version", crateName: "version", notFound: true},
+		{name: "binary name", crateName: "name", notFound: true},
+		{name: "other table", crateName: "anyhow", dependencyTable: "dependencies", notFound: true},
+		{name: "version range", crateName: "range", errorContains: "is a range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newCargoTomlTarget(t, tt.crateName, tt.dependencyTable)
+			_, err := target.ReadCurrentVersion()
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			var notFound *DependencyNotFoundError
+			if tt.notFound != errors.As(err, &notFound) {
+				t.Errorf("Expected DependencyNotFoundError=%v, got %v", tt.notFound, err)
+			}
+			if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// CIImageTarget implements the TargetClient interface for the images of CI configurations.
+// It is a preset finding the image keys of GitLab CI, CircleCI and GitHub Actions workflows
+// by the referenced image, so no paths into the file are needed.
+type CIImageTarget struct {
+	config     *configuration.Target
+	updateItem *configuration.TargetItem
+	yaml       *YamlFieldTarget
+}
+
+// NewCIImageTargetForUpdateItem creates a new ci-image target for a specific update item
+func NewCIImageTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*CIImageTarget, error) {
+	if updateItem.Image == "" {
+		return nil, fmt.Errorf("image is required for ci-image target")
+	}
+
+	target := &CIImageTarget{
+		config:     config,
+		updateItem: updateItem,
+		yaml:       &YamlFieldTarget{config: config, updateItem: updateItem},
+	}
+
+	if err := target.yaml.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// findNodes returns the scalar nodes referencing the item's image in file order. The first
+// reference determines the current tag; references with other tags, such as variants like
+// 1.21.5-alpine, are not returned.
+func (t *CIImageTarget) findNodes() ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	currentTag := ""
+	for _, root := range t.yaml.rootNodes {
+		for _, node := range findCIImageNodes(root) {
+			repository, tag, tagged := splitImageReference(node.Value)
+			if !tagged || !sameImageRepository(repository, t.updateItem.Image) {
+				continue
+			}
+			if currentTag == "" {
+				currentTag = tag
+			}
+			if tag == currentTag {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, &ImageNotFoundError{Image: t.updateItem.Image, File: t.config.File}
+	}
+	return nodes, nil
+}
+
+// findCIImageNodes returns the scalar image references of a CI configuration:
+//   - image: golang:1.22 (GitLab CI, CircleCI docker executors, GitHub Actions containers and services)
+//   - image: { name: golang:1.22 } (GitLab CI)
+//   - services: [postgres:16, { name: redis:7 }] (GitLab CI)
+//   - container: node:20 (GitHub Actions)
+func findCIImageNodes(node *yaml.Node) []*yaml.Node {
+	var nodes []*yaml.Node
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			nodes = append(nodes, findCIImageNodes(child)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch {
+			case (key == "image" || key == "container") && value.Kind == yaml.ScalarNode:
+				nodes = append(nodes, value)
+			case key == "image" && value.Kind == yaml.MappingNode:
+				if name, err := findNode(value, []string{"name"}); err == nil && name.Kind == yaml.ScalarNode {
+					nodes = append(nodes, name)
+				}
+			case key == "services" && value.Kind == yaml.SequenceNode:
+				for _, service := range value.Content {
+					if service.Kind == yaml.ScalarNode {
+						nodes = append(nodes, service)
+					} else if name, err := findNode(service, []string{"name"}); err == nil && name.Kind == yaml.ScalarNode {
+						nodes = append(nodes, name)
+					}
+				}
+			default:
+				nodes = append(nodes, findCIImageNodes(value)...)
+			}
+		}
+	}
+	return nodes
+}
+
+// ReadCurrentVersion reads the tag of the first reference of the image
+func (t *CIImageTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Msg("Reading current version from CI configuration")
+
+	nodes, err := t.findNodes()
+	if err != nil {
+		return "", err
+	}
+	_, version, _ := splitImageReference(nodes[0].Value)
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new tag to all references of the image
+func (t *CIImageTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Writing new version to CI configuration")
+
+	nodes, err := t.findNodes()
+	if err != nil {
+		return err
+	}
+
+	// Replace from the end, so references sharing a line keep their columns
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Line != nodes[j].Line {
+			return nodes[i].Line > nodes[j].Line
+		}
+		return nodes[i].Column > nodes[j].Column
+	})
+	for _, node := range nodes {
+		if err := t.yaml.replaceScalar(node, replaceTagInImageReference(node.Value, version)); err != nil {
+			return err
+		}
+	}
+	if err := t.yaml.save(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *CIImageTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("image", t.updateItem.Image).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *CIImageTarget) Validate() error {
+	if err := t.yaml.readFile(); err != nil {
+		return err
+	}
+
+	fileName := strings.ToLower(t.config.File)
+	if !strings.HasSuffix(fileName, ".yaml") && !strings.HasSuffix(fileName, ".yml") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .yaml or .yml extension",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Msg("CI image target validation successful")
+
+	return nil
+}
+
+// splitImageReference splits an image reference into its repository and tag. References
+// without a tag or pinned to a digest are not tagged.
+func splitImageReference(reference string) (string, string, bool) {
+	if strings.Contains(reference, "@") {
+		return reference, "", false
+	}
+	colon := strings.LastIndex(reference, ":")
+	if colon <= strings.LastIndex(reference, "/") || colon == len(reference)-1 {
+		return reference, "", false
+	}
+	return reference[:colon], reference[colon+1:], true
+}
+
+// sameImageRepository reports whether two repositories name the same image, treating
+// Docker Hub's implicit registry and library namespace as optional
+func sameImageRepository(a string, b string) bool {
+	normalize := func(repository string) string {
+		repository = strings.TrimPrefix(repository, "index.docker.io/")
+		repository = strings.TrimPrefix(repository, "docker.io/")
+		return strings.TrimPrefix(repository, "library/")
+	}
+	return normalize(a) == normalize(b)
+}
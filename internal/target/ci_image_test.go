@@ -0,0 +1,210 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testGitlabCI = `image: golang:1.21.5
+
+variables:
+  GO_IMAGE: golang:1.21.5 # not an image key
+
+services: [postgres:15.4, { name: "docker.io/library/redis:7.2", alias: cache }]
+
+lint:
+  image:
+    name: golangci/golangci-lint:v1.55.2
+    entrypoint: [""]
+  script: golangci-lint run
+
+test:
+  image: golang:1.21.5
+  script: go test ./...
+
+build:
+  image: golang:1.21.5-alpine
+  script: go build ./...
+
+release:
+  image: golang@sha256:0123456789abcdef
+  script: goreleaser
+`
+
+const testCircleCI = `version: 2.1
+executors:
+  go:
+    docker:
+      - image: cimg/go:1.21.5
+      - image: cimg/postgres:15.4
+jobs:
+  test:
+    docker:
+      - image: cimg/go:1.21.5
+`
+
+const testGithubWorkflow = `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    container: node:20.10.0
+    services:
+      db:
+        image: postgres:15.4
+`
+
+func newCIImageTarget(t *testing.T, contents string, image string) (*CIImageTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "ci.yml")
+	if err := os.WriteFile(tmpFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeCIImage,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{Image: image, Source: "test-source"}},
+	}
+	target, err := NewCIImageTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestCIImageTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		contents      string
+		image         string
+		expectedVer   string
+		newVersion    string
+		expectedLines []string
+	}{
+		{
+			name:          "gitlab default and job images",
+			contents:      testGitlabCI,
+			image:         "golang",
+			expectedVer:   "1.21.5",
+			newVersion:    "1.22.0",
+			expectedLines: []string{"image: golang:1.22.0", "  image: golang:1.22.0"},
+		},
+		{
+			name:          "gitlab image variant",
+			contents:      strings.Replace(testGitlabCI, "image: golang:1.21.5\n", "image: golang:1.21.5-alpine\n", 1),
+			image:         "golang",
+			expectedVer:   "1.21.5-alpine",
+			newVersion:    "1.22.0-alpine",
+			expectedLines: []string{"image: golang:1.22.0-alpine", "  image: golang:1.22.0-alpine"},
+		},
+		{
+			name:          "gitlab image name",
+			contents:      testGitlabCI,
+			image:         "golangci/golangci-lint",
+			expectedVer:   "v1.55.2",
+			newVersion:    "v1.56.0",
+			expectedLines: []string{"    name: golangci/golangci-lint:v1.56.0"},
+		},
+		{
+			name:          "gitlab services",
+			contents:      testGitlabCI,
+			image:         "postgres",
+			expectedVer:   "15.4",
+			newVersion:    "16.1",
+			expectedLines: []string{`services: [postgres:16.1, { name: "docker.io/library/redis:7.2", alias: cache }]`},
+		},
+		{
+			name:          "gitlab service with docker hub prefix",
+			contents:      testGitlabCI,
+			image:         "redis",
+			expectedVer:   "7.2",
+			newVersion:    "7.4",
+			expectedLines: []string{`services: [postgres:15.4, { name: "docker.io/library/redis:7.4", alias: cache }]`},
+		},
+		{
+			name:          "circleci executor and job",
+			contents:      testCircleCI,
+			image:         "cimg/go",
+			expectedVer:   "1.21.5",
+			newVersion:    "1.22.0",
+			expectedLines: []string{"      - image: cimg/go:1.22.0", "      - image: cimg/go:1.22.0"},
+		},
+		{
+			name:          "github actions container",
+			contents:      testGithubWorkflow,
+			image:         "node",
+			expectedVer:   "20.10.0",
+			newVersion:    "20.11.0",
+			expectedLines: []string{"    container: node:20.11.0"},
+		},
+		{
+			name:          "github actions service",
+			contents:      testGithubWorkflow,
+			image:         "postgres",
+			expectedVer:   "15.4",
+			newVersion:    "16.1",
+			expectedLines: []string{"        image: postgres:16.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newCIImageTarget(t, tt.contents, tt.image)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(tt.contents, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if strings.Join(changed, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLines)
+			}
+		})
+	}
+}
+
+func TestCIImageTarget_NotFound(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+	}{
+		{name: "missing image", image: "node"},
+		{name: "variable only", image: "golang:1.21.5"},
+		{name: "repository prefix", image: "golangci"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newCIImageTarget(t, testGitlabCI, tt.image)
+			_, err := target.ReadCurrentVersion()
+			var notFound *ImageNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("Expected ImageNotFoundError, got %v", err)
+			}
+		})
+	}
+}
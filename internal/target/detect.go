@@ -0,0 +1,559 @@
+package target
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Detection is a version found in a repository that updater could manage
+type Detection struct {
+	File       string                    `json:"file" yaml:"file"`
+	Manager    string                    `json:"manager" yaml:"manager"` // Kind of file the version was found in, e.g. "helm-chart"
+	Dependency string                    `json:"dependency" yaml:"dependency"`
+	Version    string                    `json:"version" yaml:"version"`
+	TargetType configuration.TargetType  `json:"targetType,omitempty" yaml:"targetType,omitempty"` // Empty if no target type updates the version yet
+	Item       *configuration.TargetItem `json:"-" yaml:"-"`                                       // Item of the suggested target, nil if unsupported
+}
+
+// detector finds the versions of one kind of file
+type detector struct {
+	manager    string
+	targetType configuration.TargetType
+	match      func(file string) bool
+	candidates func(contents string) []detectionCandidate
+}
+
+// detectionCandidate is a dependency a detector found, before its version was read
+type detectionCandidate struct {
+	dependency string
+	version    string // Only set for unsupported candidates, supported ones are read by their target
+	item       *configuration.TargetItem
+}
+
+// detectSkippedDirectories are never searched for managed files
+var detectSkippedDirectories = map[string]bool{"node_modules": true, "vendor": true}
+
+// detectHiddenDirectories are the hidden directories holding managed files
+var detectHiddenDirectories = map[string]bool{".github": true, ".devcontainer": true, ".circleci": true}
+
+var (
+	dockerfileFromPattern   = regexp.MustCompile(`(?im)^[ \t]*FROM[ \t]+(?:--\S+[ \t]+)*(\S+)(?:[ \t]+AS[ \t]+(\S+))?`)
+	terraformVersionPattern = regexp.MustCompile(`(?i)variable\s+"([^"]*version[^"]*)"\s*\{`)
+	makeVersionPattern      = regexp.MustCompile(makeAssignmentPrefix + `([A-Za-z_][A-Za-z0-9_]*_VERSION)[ \t]*(?:\?|:{1,3})?=`)
+)
+
+// detectors are tried in order on every file, a file may match several
+var detectors = []detector{
+	{
+		manager: "dockerfile",
+		match: func(file string) bool {
+			name := path.Base(file)
+			return name == "Dockerfile" || name == "Containerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+		},
+		candidates: detectDockerfile,
+	},
+	{manager: "helm-chart", targetType: configuration.TargetTypeSubchart, match: baseNameIs("Chart.yaml"), candidates: detectHelmChart},
+	{manager: "kustomize", targetType: configuration.TargetTypeYamlField, match: baseNameIs("kustomization.yaml", "kustomization.yml", "Kustomization"), candidates: detectKustomization},
+	{manager: "helmfile", targetType: configuration.TargetTypeHelmfile, match: baseNameIs("helmfile.yaml", "helmfile.yml"), candidates: detectHelmfile},
+	{
+		manager:    "github-actions",
+		targetType: configuration.TargetTypeCIImage,
+		match: func(file string) bool {
+			return strings.HasSuffix(path.Dir(file), ".github/workflows") && (strings.HasSuffix(file, ".yml") || strings.HasSuffix(file, ".yaml"))
+		},
+		candidates: detectCIImages,
+	},
+	{manager: "gitlab-ci", targetType: configuration.TargetTypeCIImage, match: baseNameIs(".gitlab-ci.yml"), candidates: detectCIImages},
+	{
+		manager:    "circleci",
+		targetType: configuration.TargetTypeCIImage,
+		match:      func(file string) bool { return strings.HasSuffix(file, ".circleci/config.yml") },
+		candidates: detectCIImages,
+	},
+	{manager: "devcontainer", targetType: configuration.TargetTypeDevcontainer, match: baseNameIs("devcontainer.json", ".devcontainer.json"), candidates: detectDevcontainer},
+	{manager: "asdf", targetType: configuration.TargetTypeToolVersions, match: baseNameIs(".tool-versions"), candidates: detectAsdfTools},
+	{manager: "mise", targetType: configuration.TargetTypeToolVersions, match: baseNameIs("mise.toml", ".mise.toml"), candidates: detectMiseTools},
+	{manager: "cargo", targetType: configuration.TargetTypeCargoToml, match: baseNameIs("Cargo.toml"), candidates: detectCargoCrates},
+	{manager: "maven", targetType: configuration.TargetTypeMavenPom, match: baseNameIs("pom.xml"), candidates: detectMavenArtifacts},
+	{
+		manager:    "gradle",
+		targetType: configuration.TargetTypeGradleCatalog,
+		match:      func(file string) bool { return strings.HasSuffix(file, ".versions.toml") },
+		candidates: detectGradleCatalog,
+	},
+	{
+		manager:    "terraform",
+		targetType: configuration.TargetTypeTerraformVariable,
+		match:      func(file string) bool { return strings.HasSuffix(file, ".tf") },
+		candidates: detectTerraformVariables,
+	},
+	{
+		manager:    "make",
+		targetType: configuration.TargetTypeMakeVariable,
+		match: func(file string) bool {
+			name := path.Base(file)
+			return name == "Makefile" || name == "GNUmakefile" || name == "makefile" || strings.HasSuffix(name, ".mk")
+		},
+		candidates: detectVersionVariables,
+	},
+	{
+		manager:    "shell",
+		targetType: configuration.TargetTypeMakeVariable,
+		match:      func(file string) bool { return strings.HasSuffix(file, ".sh") || strings.HasSuffix(file, ".bash") },
+		candidates: detectVersionVariables,
+	},
+}
+
+// baseNameIs returns a matcher for files with one of the given names
+func baseNameIs(names ...string) func(file string) bool {
+	return func(file string) bool {
+		base := path.Base(file)
+		for _, name := range names {
+			if base == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Detect scans the files under root for versions updater could manage, whether or not a
+// target of their type is configured. Supported versions are read by the target that would
+// update them, so only versions the suggested item can actually handle are reported. Files
+// are reported relative to root.
+func Detect(root string) ([]Detection, error) {
+	var detections []Detection
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if file != root && (detectSkippedDirectories[name] || (strings.HasPrefix(name, ".") && !detectHiddenDirectories[name])) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relative, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		for _, detector := range detectors {
+			if detector.match(relative) {
+				detections = append(detections, detector.detect(root, relative)...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return detections, nil
+}
+
+// detect returns the detections of a matched file
+func (d *detector) detect(root string, file string) []Detection {
+	fullPath := filepath.Join(root, filepath.FromSlash(file))
+	contents, err := os.ReadFile(fullPath)
+	if err != nil {
+		log.Debug().Err(err).Str("file", file).Msg("Failed to read file for detection")
+		return nil
+	}
+
+	var detections []Detection
+	for _, candidate := range d.candidates(string(contents)) {
+		detection := Detection{File: file, Manager: d.manager, Dependency: candidate.dependency, Version: candidate.version}
+		if candidate.item != nil {
+			version, err := readDetectedVersion(fullPath, d.targetType, candidate.item)
+			if err != nil {
+				log.Debug().Err(err).Str("file", file).Str("dependency", candidate.dependency).Msg("Skipping detected dependency")
+				continue
+			}
+			detection.Version = version
+			detection.TargetType = d.targetType
+			detection.Item = candidate.item
+		}
+		if !isDetectedVersion(detection.Version) {
+			continue
+		}
+		detections = append(detections, detection)
+	}
+	return detections
+}
+
+// readDetectedVersion reads the version of a candidate with the target that would update it
+func readDetectedVersion(file string, targetType configuration.TargetType, item *configuration.TargetItem) (string, error) {
+	config := &configuration.Target{Type: targetType, File: file, Items: []configuration.TargetItem{*item}}
+	client, err := NewTargetFactory(nil).CreateTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		return "", err
+	}
+	return client.ReadCurrentVersion()
+}
+
+// isDetectedVersion reports whether a value looks like a pinned version rather than an
+// expression or a moving reference such as "latest"
+func isDetectedVersion(version string) bool {
+	return strings.ContainsAny(version, "0123456789") && !strings.ContainsAny(version, "${}()*")
+}
+
+// SuggestTargets groups supported detections into one target per file and type. Sources are
+// placeholders named after the dependency, to be replaced by package sources of the
+// configuration.
+func SuggestTargets(detections []Detection) []configuration.Target {
+	var targets []configuration.Target
+	index := make(map[string]int)
+	for _, detection := range detections {
+		if detection.Item == nil {
+			continue
+		}
+		key := detection.File + "|" + string(detection.TargetType)
+		i, found := index[key]
+		if !found {
+			i = len(targets)
+			index[key] = i
+			targets = append(targets, configuration.Target{
+				Name: detectedTargetName(detection.File),
+				Type: detection.TargetType,
+				File: detection.File,
+			})
+		}
+		item := *detection.Item
+		item.Source = detectedSourceName(detection.Dependency)
+		targets[i].Items = append(targets[i].Items, item)
+	}
+	return targets
+}
+
+var nonNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// detectedTargetName derives a target name from a file path, e.g. "charts-app-chart" from
+// "charts/app/Chart.yaml"
+func detectedTargetName(file string) string {
+	name := strings.TrimSuffix(file, path.Ext(file))
+	return strings.Trim(nonNameCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// detectedSourceName derives a source name from a dependency, e.g. "kubectl" from
+// "KUBECTL_VERSION" or "slf4j-api" from "org.slf4j:slf4j-api"
+func detectedSourceName(dependency string) string {
+	name := strings.ToLower(dependency)
+	for _, suffix := range []string{".version", "_version", "-version"} {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != "" {
+			name = trimmed
+		}
+	}
+	if separator := strings.LastIndexAny(name, "/:. "); separator >= 0 && separator < len(name)-1 {
+		name = name[separator+1:]
+	}
+	return strings.Trim(nonNameCharacters.ReplaceAllString(name, "-"), "-")
+}
+
+// detectDockerfile returns the tagged base images of a Dockerfile. No target type updates
+// Dockerfiles yet, so they are reported without a suggested item.
+func detectDockerfile(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	stages := make(map[string]bool)
+	for _, match := range dockerfileFromPattern.FindAllStringSubmatch(contents, -1) {
+		if match[2] != "" {
+			stages[strings.ToLower(match[2])] = true
+		}
+		if stages[strings.ToLower(match[1])] || strings.Contains(match[1], "$") {
+			continue
+		}
+		if repository, tag, tagged := splitImageReference(match[1]); tagged {
+			candidates = append(candidates, detectionCandidate{dependency: repository, version: tag})
+		}
+	}
+	return candidates
+}
+
+// detectHelmChart returns the dependencies of a Chart.yaml
+func detectHelmChart(contents string) []detectionCandidate {
+	var chart ChartYAML
+	if err := yaml.Unmarshal([]byte(contents), &chart); err != nil {
+		return nil
+	}
+	var candidates []detectionCandidate
+	for _, dependency := range chart.Dependencies {
+		candidates = append(candidates, detectionCandidate{
+			dependency: dependency.Name,
+			item:       &configuration.TargetItem{SubchartName: dependency.Name},
+		})
+	}
+	return candidates
+}
+
+// detectKustomization returns the image tags and Helm chart versions of a kustomization
+func detectKustomization(contents string) []detectionCandidate {
+	var kustomization struct {
+		Images []struct {
+			Name    string `yaml:"name"`
+			NewName string `yaml:"newName"`
+			NewTag  string `yaml:"newTag"`
+		} `yaml:"images"`
+		HelmCharts []struct {
+			Name    string `yaml:"name"`
+			Version string `yaml:"version"`
+		} `yaml:"helmCharts"`
+	}
+	if err := yaml.Unmarshal([]byte(contents), &kustomization); err != nil {
+		return nil
+	}
+
+	var candidates []detectionCandidate
+	for i, image := range kustomization.Images {
+		if image.NewTag == "" {
+			continue
+		}
+		name := image.NewName
+		if name == "" {
+			name = image.Name
+		}
+		candidates = append(candidates, detectionCandidate{
+			dependency: name,
+			item:       &configuration.TargetItem{YamlPath: "images." + strconv.Itoa(i) + ".newTag"},
+		})
+	}
+	for i, chart := range kustomization.HelmCharts {
+		if chart.Version == "" {
+			continue
+		}
+		candidates = append(candidates, detectionCandidate{
+			dependency: chart.Name,
+			item:       &configuration.TargetItem{YamlPath: "helmCharts." + strconv.Itoa(i) + ".version"},
+		})
+	}
+	return candidates
+}
+
+// detectHelmfile returns the releases of a helmfile with a chart version
+func detectHelmfile(contents string) []detectionCandidate {
+	var helmfile struct {
+		Releases []struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+			Version   string `yaml:"version"`
+		} `yaml:"releases"`
+	}
+	if err := yaml.Unmarshal([]byte(contents), &helmfile); err != nil {
+		return nil
+	}
+
+	var candidates []detectionCandidate
+	for _, release := range helmfile.Releases {
+		if release.Version == "" {
+			continue
+		}
+		name := release.Name
+		if release.Namespace != "" {
+			name = release.Namespace + "/" + name
+		}
+		candidates = append(candidates, detectionCandidate{
+			dependency: name,
+			item:       &configuration.TargetItem{ReleaseName: name},
+		})
+	}
+	return candidates
+}
+
+// detectCIImages returns the images of a CI configuration
+func detectCIImages(contents string) []detectionCandidate {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(contents), &root); err != nil {
+		return nil
+	}
+	var references []string
+	for _, node := range findCIImageNodes(&root) {
+		references = append(references, node.Value)
+	}
+	return imageCandidates(references)
+}
+
+// detectDevcontainer returns the image and features of a devcontainer.json
+func detectDevcontainer(contents string) []detectionCandidate {
+	literals, err := scanDevcontainerImages(contents)
+	if err != nil {
+		return nil
+	}
+	var references []string
+	for _, reference := range literals {
+		references = append(references, reference.value)
+	}
+	return imageCandidates(references)
+}
+
+// imageCandidates returns one candidate per repository of tagged image references
+func imageCandidates(references []string) []detectionCandidate {
+	var candidates []detectionCandidate
+	seen := make(map[string]bool)
+	for _, reference := range references {
+		repository, _, tagged := splitImageReference(reference)
+		if !tagged || seen[repository] {
+			continue
+		}
+		seen[repository] = true
+		candidates = append(candidates, detectionCandidate{
+			dependency: repository,
+			item:       &configuration.TargetItem{Image: repository},
+		})
+	}
+	return candidates
+}
+
+// detectAsdfTools returns the tools of a .tool-versions file
+func detectAsdfTools(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	for _, line := range strings.Split(contents, "\n") {
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			candidates = append(candidates, toolCandidate(fields[0]))
+		}
+	}
+	return candidates
+}
+
+// detectMiseTools returns the tools of the [tools] table of a mise.toml
+func detectMiseTools(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	var table []string
+	for _, line := range strings.Split(contents, "\n") {
+		if header, ok := tomlTableHeader(line); ok && !strings.HasPrefix(strings.TrimSpace(line), "[[") {
+			table = header
+			if len(header) == 2 && header[0] == "tools" {
+				candidates = append(candidates, toolCandidate(header[1]))
+			}
+			continue
+		}
+		if key, _, _ := splitTomlLine(line); len(key) == 1 && len(table) == 1 && table[0] == "tools" {
+			candidates = append(candidates, toolCandidate(key[0]))
+		}
+	}
+	return candidates
+}
+
+// toolCandidate returns the candidate of a tool of a tool versions file
+func toolCandidate(tool string) detectionCandidate {
+	return detectionCandidate{dependency: tool, item: &configuration.TargetItem{ToolName: tool}}
+}
+
+// detectCargoCrates returns the crates of the dependency tables of a Cargo.toml
+func detectCargoCrates(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	seen := make(map[string]bool)
+	add := func(crate string) {
+		if !seen[crate] {
+			seen[crate] = true
+			candidates = append(candidates, detectionCandidate{dependency: crate, item: &configuration.TargetItem{CrateName: crate}})
+		}
+	}
+
+	dependencyTable := false
+	for _, line := range strings.Split(contents, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "[[") {
+			dependencyTable = false
+			continue
+		}
+		if header, ok := tomlTableHeader(line); ok {
+			dependencyTable = isCargoDependencyTable(header)
+			if len(header) > 1 && isCargoDependencyTable(header[:len(header)-1]) {
+				add(header[len(header)-1])
+			}
+			continue
+		}
+		if key, _, _ := splitTomlLine(line); key != nil && dependencyTable {
+			add(key[0])
+		}
+	}
+	return candidates
+}
+
+// detectMavenArtifacts returns the artifacts of a pom.xml, or the properties holding their
+// versions
+func detectMavenArtifacts(contents string) []detectionCandidate {
+	pom, err := parseMavenPom(contents)
+	if err != nil {
+		return nil
+	}
+	var candidates []detectionCandidate
+	seen := make(map[string]bool)
+	for _, artifact := range pom.artifacts {
+		candidate := detectionCandidate{dependency: artifact.groupID + ":" + artifact.artifactID}
+		if property, ok := mavenPropertyReference(artifact.version.value); ok {
+			// Artifacts sharing a property are updated together through it
+			candidate = detectionCandidate{dependency: property, item: &configuration.TargetItem{Property: property}}
+		} else {
+			candidate.item = &configuration.TargetItem{Artifact: candidate.dependency}
+		}
+		if !seen[candidate.dependency] {
+			seen[candidate.dependency] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// detectGradleCatalog returns the entries of a Gradle version catalog holding a version.
+// Libraries and plugins referencing a version are covered by its entry in [versions].
+func detectGradleCatalog(contents string) []detectionCandidate {
+	entries := scanGradleCatalog(contents)
+	var candidates []detectionCandidate
+	for _, table := range []string{"versions", "libraries", "plugins"} {
+		aliases := make([]string, 0, len(entries[table]))
+		for alias, entry := range entries[table] {
+			if !strings.Contains(entry.value, "version.ref") {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Slice(aliases, func(i, j int) bool { return entries[table][aliases[i]].start < entries[table][aliases[j]].start })
+		for _, alias := range aliases {
+			entry := table + "." + alias
+			candidates = append(candidates, detectionCandidate{dependency: entry, item: &configuration.TargetItem{CatalogEntry: entry}})
+		}
+	}
+	return candidates
+}
+
+// detectTerraformVariables returns the variables of a Terraform file named like a version
+func detectTerraformVariables(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	for _, match := range terraformVersionPattern.FindAllStringSubmatch(contents, -1) {
+		candidates = append(candidates, detectionCandidate{
+			dependency: match[1],
+			item:       &configuration.TargetItem{TerraformVariableName: match[1]},
+		})
+	}
+	return candidates
+}
+
+// detectVersionVariables returns the variables of a Makefile or shell script named like
+// a version, e.g. KUBECTL_VERSION
+func detectVersionVariables(contents string) []detectionCandidate {
+	var candidates []detectionCandidate
+	seen := make(map[string]bool)
+	for _, match := range makeVersionPattern.FindAllStringSubmatch(contents, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			candidates = append(candidates, detectionCandidate{
+				dependency: match[1],
+				item:       &configuration.TargetItem{VariableName: match[1]},
+			})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,129 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Dockerfile": "ARG BASE=alpine:3.19\nFROM golang:1.22 AS build\nFROM build AS test\nFROM ${BASE}\nFROM gcr.io/distroless/static:nonroot\n",
+		"charts/app/Chart.yaml": `apiVersion: v2
+name: app
+version: 1.0.0
+dependencies:
+  - name: redis
+    version: 18.1.0
+    repository: https://charts.bitnami.com/bitnami
+`,
+		"deploy/kustomization.yaml": `images:
+  - name: app
+    newName: ghcr.io/example/app
+    newTag: v1.2.3
+  - name: sidecar
+    digest: sha256:0123
+helmCharts:
+  - name: ingress-nginx
+    version: 4.8.3
+`,
+		".github/workflows/ci.yml": `jobs:
+  test:
+    container: node:20.10.0
+    services:
+      db:
+        image: postgres:latest
+`,
+		".tool-versions":            "nodejs 20.10.0\npython system\n",
+		"Makefile":                  "KUBECTL_VERSION ?= 1.28.4\nGO_VERSION = $(shell go env GOVERSION)\n",
+		"variables.tf":              "variable \"chart_version\" {\n  default = \"1.2.3\"\n}\nvariable \"replicas\" {\n  default = \"2\"\n}\n",
+		"node_modules/pkg/Makefile": "KUBECTL_VERSION ?= 1.0.0\n",
+		".git/Makefile":             "KUBECTL_VERSION ?= 1.0.0\n",
+	}
+	for name, contents := range files {
+		file := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	detections, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type expectation struct {
+		file       string
+		manager    string
+		dependency string
+		version    string
+		targetType configuration.TargetType
+	}
+	expected := []expectation{
+		{".github/workflows/ci.yml", "github-actions", "node", "20.10.0", configuration.TargetTypeCIImage},
+		{".tool-versions", "asdf", "nodejs", "20.10.0", configuration.TargetTypeToolVersions},
+		{"Dockerfile", "dockerfile", "golang", "1.22", ""},
+		{"Makefile", "make", "KUBECTL_VERSION", "1.28.4", configuration.TargetTypeMakeVariable},
+		{"charts/app/Chart.yaml", "helm-chart", "redis", "18.1.0", configuration.TargetTypeSubchart},
+		{"deploy/kustomization.yaml", "kustomize", "ghcr.io/example/app", "v1.2.3", configuration.TargetTypeYamlField},
+		{"deploy/kustomization.yaml", "kustomize", "ingress-nginx", "4.8.3", configuration.TargetTypeYamlField},
+		{"variables.tf", "terraform", "chart_version", "1.2.3", configuration.TargetTypeTerraformVariable},
+	}
+	if len(detections) != len(expected) {
+		t.Fatalf("Expected %d detections, got %d: %+v", len(expected), len(detections), detections)
+	}
+	for i, want := range expected {
+		got := detections[i]
+		if got.File != want.file || got.Manager != want.manager || got.Dependency != want.dependency ||
+			got.Version != want.version || got.TargetType != want.targetType {
+			t.Errorf("Detection %d: expected %+v, got %+v", i, want, got)
+		}
+		if (got.Item == nil) != (want.targetType == "") {
+			t.Errorf("Detection %d: unexpected item %+v", i, got.Item)
+		}
+	}
+
+	targets := SuggestTargets(detections)
+	if len(targets) != 6 {
+		t.Fatalf("Expected 6 suggested targets, got %d", len(targets))
+	}
+	kustomization := targets[4]
+	if kustomization.Type != configuration.TargetTypeYamlField || len(kustomization.Items) != 2 {
+		t.Fatalf("Unexpected kustomization target %+v", kustomization)
+	}
+	if item := kustomization.Items[1]; item.YamlPath != "helmCharts.0.version" || item.Source != "ingress-nginx" {
+		t.Errorf("Unexpected kustomization item %+v", item)
+	}
+	if item := targets[2].Items[0]; item.VariableName != "KUBECTL_VERSION" || item.Source != "kubectl" {
+		t.Errorf("Unexpected make item %+v", item)
+	}
+}
+
+func TestDetectedSourceName(t *testing.T) {
+	tests := []struct {
+		dependency string
+		expected   string
+	}{
+		{"KUBECTL_VERSION", "kubectl"},
+		{"org.slf4j:slf4j-api", "slf4j-api"},
+		{"jackson.version", "jackson"},
+		{"versions.kotlin", "kotlin"},
+		{"mcr.microsoft.com/devcontainers/go", "go"},
+		{"monitoring/kube-prometheus-stack", "kube-prometheus-stack"},
+		{"version", "version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dependency, func(t *testing.T) {
+			if name := detectedSourceName(tt.dependency); name != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, name)
+			}
+		})
+	}
+}
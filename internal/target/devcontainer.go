@@ -0,0 +1,268 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// DevcontainerTarget implements the TargetClient interface for devcontainer.json files. It is
+// a preset updating the tag of the container image and of features referencing an image.
+type DevcontainerTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+}
+
+// jsonString is the location of the content of a string literal in a JSON file
+type jsonString struct {
+	value string
+	start int
+	end   int
+}
+
+// NewDevcontainerTargetForUpdateItem creates a new devcontainer target for a specific update item
+func NewDevcontainerTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*DevcontainerTarget, error) {
+	if updateItem.Image == "" {
+		return nil, fmt.Errorf("image is required for devcontainer target")
+	}
+
+	target := &DevcontainerTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads the target file into memory
+func (t *DevcontainerTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	t.fileContents = string(content)
+	return nil
+}
+
+// findReferences returns the references of the item's image in file order. The first
+// reference determines the current tag; references with other tags are not returned.
+func (t *DevcontainerTarget) findReferences() ([]jsonString, error) {
+	references, err := scanDevcontainerImages(t.fileContents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse devcontainer file %s: %w", t.config.File, err)
+	}
+
+	var matches []jsonString
+	currentTag := ""
+	for _, reference := range references {
+		repository, tag, tagged := splitImageReference(reference.value)
+		if !tagged || !sameImageRepository(repository, t.updateItem.Image) {
+			continue
+		}
+		if currentTag == "" {
+			currentTag = tag
+		}
+		if tag == currentTag {
+			matches = append(matches, reference)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, &ImageNotFoundError{Image: t.updateItem.Image, File: t.config.File}
+	}
+	return matches, nil
+}
+
+// ReadCurrentVersion reads the tag of the first reference of the image
+func (t *DevcontainerTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Msg("Reading current version from devcontainer file")
+
+	references, err := t.findReferences()
+	if err != nil {
+		return "", err
+	}
+	_, version, _ := splitImageReference(references[0].value)
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new tag to all references of the image
+func (t *DevcontainerTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Writing new version to devcontainer file")
+
+	references, err := t.findReferences()
+	if err != nil {
+		return err
+	}
+
+	var newContents strings.Builder
+	previous := 0
+	for _, reference := range references {
+		tagStart := reference.start + strings.LastIndex(reference.value, ":") + 1
+		newContents.WriteString(t.fileContents[previous:tagStart])
+		newContents.WriteString(version)
+		previous = reference.end
+	}
+	newContents.WriteString(t.fileContents[previous:])
+
+	if err := os.WriteFile(t.config.File, []byte(newContents.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	t.fileContents = newContents.String()
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *DevcontainerTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("image", t.updateItem.Image).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *DevcontainerTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(strings.ToLower(t.config.File), ".json") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .json extension",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("image", t.updateItem.Image).
+		Msg("Devcontainer target validation successful")
+
+	return nil
+}
+
+// scanDevcontainerImages returns the image references of a devcontainer.json: the value of
+// the top-level "image" and the keys of the "features" object. The file is JSON with
+// comments and trailing commas, which encoding/json does not accept.
+func scanDevcontainerImages(contents string) ([]jsonString, error) {
+	type container struct {
+		object    bool
+		parentKey string // Key of the container in its parent object
+		key       string // Current key of an object
+		expectKey bool
+	}
+	var stack []*container
+	var references []jsonString
+
+	for i := 0; i < len(contents); i++ {
+		switch contents[i] {
+		case '/':
+			switch {
+			case strings.HasPrefix(contents[i:], "//"):
+				end := strings.IndexByte(contents[i:], '\n')
+				if end < 0 {
+					end = len(contents) - i
+				}
+				i += end
+			case strings.HasPrefix(contents[i:], "/*"):
+				end := strings.Index(contents[i+2:], "*/")
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated comment")
+				}
+				i += end + 3
+			}
+		case '"':
+			start := i + 1
+			end := start
+			for end < len(contents) && contents[end] != '"' {
+				if contents[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(contents) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i = end
+			if len(stack) == 0 {
+				continue
+			}
+			literal := jsonString{value: contents[start:end], start: start, end: end}
+			top := stack[len(stack)-1]
+			switch {
+			case top.object && top.expectKey:
+				top.key = literal.value
+				top.expectKey = false
+				if len(stack) == 2 && top.parentKey == "features" {
+					references = append(references, literal)
+				}
+			case len(stack) == 1 && top.key == "image":
+				references = append(references, literal)
+			}
+		case '{', '[':
+			parentKey := ""
+			if len(stack) > 0 {
+				parentKey = stack[len(stack)-1].key
+			}
+			object := contents[i] == '{'
+			stack = append(stack, &container{object: object, parentKey: parentKey, expectKey: object})
+		case '}', ']':
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected '%c'", contents[i])
+			}
+			stack = stack[:len(stack)-1]
+		case ',':
+			if len(stack) > 0 && stack[len(stack)-1].object {
+				top := stack[len(stack)-1]
+				top.key = ""
+				top.expectKey = true
+			}
+		}
+	}
+	return references, nil
+}
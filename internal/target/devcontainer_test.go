@@ -0,0 +1,138 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testDevcontainer = `// For format details, see https://aka.ms/devcontainer.json
+{
+	"name": "Go",
+	"image": "mcr.microsoft.com/devcontainers/go:1-1.21-bookworm",
+	/* Features add tools to the container:
+	   "image": "ignored" */
+	"features": {
+		"ghcr.io/devcontainers/features/node:1": {
+			"version": "20"
+		},
+		"ghcr.io/devcontainers/features/docker-in-docker:2": {},
+	},
+	"customizations": {
+		"vscode": {
+			"image": "mcr.microsoft.com/devcontainers/go:1-1.20-bookworm"
+		}
+	},
+}
+`
+
+func newDevcontainerTarget(t *testing.T, image string) (*DevcontainerTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "devcontainer.json")
+	if err := os.WriteFile(tmpFile, []byte(testDevcontainer), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeDevcontainer,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{Image: image, Source: "test-source"}},
+	}
+	target, err := NewDevcontainerTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestDevcontainerTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		image        string
+		expectedVer  string
+		newVersion   string
+		expectedLine string
+	}{
+		{
+			name:         "image",
+			image:        "mcr.microsoft.com/devcontainers/go",
+			expectedVer:  "1-1.21-bookworm",
+			newVersion:   "1-1.22-bookworm",
+			expectedLine: `	"image": "mcr.microsoft.com/devcontainers/go:1-1.22-bookworm",`,
+		},
+		{
+			name:         "feature",
+			image:        "ghcr.io/devcontainers/features/node",
+			expectedVer:  "1",
+			newVersion:   "2",
+			expectedLine: `		"ghcr.io/devcontainers/features/node:2": {`,
+		},
+		{
+			name:         "feature followed by trailing comma",
+			image:        "ghcr.io/devcontainers/features/docker-in-docker",
+			expectedVer:  "2",
+			newVersion:   "3",
+			expectedLine: `		"ghcr.io/devcontainers/features/docker-in-docker:3": {},`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newDevcontainerTarget(t, tt.image)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(testDevcontainer, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if len(changed) != 1 || changed[0] != tt.expectedLine {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLine)
+			}
+		})
+	}
+}
+
+func TestDevcontainerTarget_NotFound(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+	}{
+		{name: "missing image", image: "mcr.microsoft.com/devcontainers/python"},
+		{name: "commented image", image: "ignored"},
+		{name: "feature option", image: "20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newDevcontainerTarget(t, tt.image)
+			_, err := target.ReadCurrentVersion()
+			var notFound *ImageNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("Expected ImageNotFoundError, got %v", err)
+			}
+		})
+	}
+}
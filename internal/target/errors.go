@@ -63,3 +63,43 @@ type YamlFieldNotFoundError struct {
 func (e *YamlFieldNotFoundError) Error() string {
 	return fmt.Sprintf("yaml path '%s' not found in file: %s", e.Path, e.File)
 }
+
+// JsonnetFieldNotFoundError is returned when a Jsonnet path cannot be resolved to a string literal
+type JsonnetFieldNotFoundError struct {
+	Path string
+	File string
+}
+
+func (e *JsonnetFieldNotFoundError) Error() string {
+	return fmt.Sprintf("jsonnet path '%s' not found in file: %s", e.Path, e.File)
+}
+
+// ReleaseNotFoundError is returned when a release is not found in the helmfile
+type ReleaseNotFoundError struct {
+	Release string
+	File    string
+}
+
+func (e *ReleaseNotFoundError) Error() string {
+	return fmt.Sprintf("release '%s' not found in file: %s", e.Release, e.File)
+}
+
+// ToolNotFoundError is returned when a tool is not found in the .tool-versions or mise.toml file
+type ToolNotFoundError struct {
+	Tool string
+	File string
+}
+
+func (e *ToolNotFoundError) Error() string {
+	return fmt.Sprintf("tool '%s' not found in file: %s", e.Tool, e.File)
+}
+
+// ImageNotFoundError is returned when no tagged reference of an image is found in the target file
+type ImageNotFoundError struct {
+	Image string
+	File  string
+}
+
+func (e *ImageNotFoundError) Error() string {
+	return fmt.Sprintf("image '%s' not found in file: %s", e.Image, e.File)
+}
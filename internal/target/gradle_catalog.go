@@ -0,0 +1,218 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// GradleCatalogTarget implements the TargetClient interface for Gradle version catalogs
+// (libs.versions.toml)
+type GradleCatalogTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+}
+
+// gradleCatalogEntry is the value of an alias in a table of the catalog
+type gradleCatalogEntry struct {
+	value string
+	start int // Offset of the value in the file
+}
+
+// gradleRichVersionKeys are the keys of a rich version holding a single version
+var gradleRichVersionKeys = []string{"require", "strictly", "prefer"}
+
+// NewGradleCatalogTargetForUpdateItem creates a new gradle-catalog target for a specific update item
+func NewGradleCatalogTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*GradleCatalogTarget, error) {
+	if updateItem.CatalogEntry == "" {
+		return nil, fmt.Errorf("catalogEntry is required for gradle-catalog target")
+	}
+
+	target := &GradleCatalogTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads the target file into memory
+func (t *GradleCatalogTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	t.fileContents = string(content)
+	return nil
+}
+
+// findVersion returns the offsets of the version of the item's catalog entry, following
+// version.ref of libraries and plugins into the versions table
+func (t *GradleCatalogTarget) findVersion() (int, int, error) {
+	table, alias, _ := strings.Cut(t.updateItem.CatalogEntry, ".")
+	entries := scanGradleCatalog(t.fileContents)
+	entry, found := entries[table][alias]
+	if !found {
+		return 0, 0, &DependencyNotFoundError{Dependency: t.updateItem.CatalogEntry, File: t.config.File}
+	}
+
+	if table != "versions" {
+		// "group:name:version" of libraries and "id:version" of plugins
+		if start, end, ok := tomlString(entry.value); ok {
+			notation := entry.value[start:end]
+			separator := strings.LastIndex(notation, ":")
+			if separator < 0 || (table == "libraries" && strings.Count(notation, ":") < 2) {
+				return 0, 0, fmt.Errorf("catalog entry '%s' in file %s has no version", t.updateItem.CatalogEntry, t.config.File)
+			}
+			return entry.start + start + separator + 1, entry.start + end, nil
+		}
+		if start, end, ok := inlineTableString(entry.value, "version", "ref"); ok {
+			reference := entry.value[start:end]
+			if entry, found = entries["versions"][reference]; !found {
+				return 0, 0, fmt.Errorf("version '%s' referenced by catalog entry '%s' is not defined in file %s", reference, t.updateItem.CatalogEntry, t.config.File)
+			}
+		} else if start, end, ok := inlineTableString(entry.value, "version"); ok {
+			return entry.start + start, entry.start + end, nil
+		}
+	}
+
+	if start, end, ok := tomlString(entry.value); ok {
+		return entry.start + start, entry.start + end, nil
+	}
+	for _, key := range gradleRichVersionKeys {
+		if start, end, ok := inlineTableString(entry.value, key); ok {
+			return entry.start + start, entry.start + end, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("catalog entry '%s' in file %s has no single version", t.updateItem.CatalogEntry, t.config.File)
+}
+
+// ReadCurrentVersion reads the version of the catalog entry
+func (t *GradleCatalogTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("catalogEntry", t.updateItem.CatalogEntry).
+		Msg("Reading current version from Gradle version catalog")
+
+	start, end, err := t.findVersion()
+	if err != nil {
+		return "", err
+	}
+	version := t.fileContents[start:end]
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("catalogEntry", t.updateItem.CatalogEntry).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new version to the catalog entry
+func (t *GradleCatalogTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("catalogEntry", t.updateItem.CatalogEntry).
+		Str("version", version).
+		Msg("Writing new version to Gradle version catalog")
+
+	start, end, err := t.findVersion()
+	if err != nil {
+		return err
+	}
+
+	newContents := t.fileContents[:start] + version + t.fileContents[end:]
+	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	t.fileContents = newContents
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("catalogEntry", t.updateItem.CatalogEntry).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *GradleCatalogTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("catalogEntry", t.updateItem.CatalogEntry).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *GradleCatalogTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(t.config.File, ".versions.toml") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .versions.toml extension",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("catalogEntry", t.updateItem.CatalogEntry).
+		Msg("Gradle version catalog target validation successful")
+
+	return nil
+}
+
+// scanGradleCatalog returns the entries of the tables of a version catalog by table and alias
+func scanGradleCatalog(contents string) map[string]map[string]gradleCatalogEntry {
+	entries := make(map[string]map[string]gradleCatalogEntry)
+	table := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if header, ok := tomlTableHeader(line); ok {
+			table = strings.Join(header, ".")
+			continue
+		}
+		key, value, valueStart := splitTomlLine(line)
+		if key == nil || table == "" {
+			continue
+		}
+		if entries[table] == nil {
+			entries[table] = make(map[string]gradleCatalogEntry)
+		}
+		entries[table][strings.Join(key, ".")] = gradleCatalogEntry{value: value, start: lineStart + valueStart}
+	}
+	return entries
+}
@@ -0,0 +1,171 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testGradleCatalog = `[versions]
+kotlin = "1.9.20"
+retrofit = { strictly = "2.9.0" }
+
+[libraries]
+okhttp = { module = "com.squareup.okhttp3:okhttp", version = "4.12.0" }
+retrofit = { group = "com.squareup.retrofit2", name = "retrofit", version.ref = "retrofit" }
+junit = "junit:junit:4.13.2"  # tests
+bom = { module = "org.example:bom" }
+broken = { module = "org.example:broken", version.ref = "missing" }
+
+[plugins]
+kotlin-jvm = { id = "org.jetbrains.kotlin.jvm", version.ref = "kotlin" }
+detekt = "io.gitlab.arturbosch.detekt:1.23.4"
+`
+
+func newGradleCatalogTarget(t *testing.T, entry string) (*GradleCatalogTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "libs.versions.toml")
+	if err := os.WriteFile(tmpFile, []byte(testGradleCatalog), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeGradleCatalog,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{CatalogEntry: entry, Source: "test-source"}},
+	}
+	target, err := NewGradleCatalogTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestGradleCatalogTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		entry        string
+		expectedVer  string
+		newVersion   string
+		expectedLine string
+	}{
+		{
+			name:         "version",
+			entry:        "versions.kotlin",
+			expectedVer:  "1.9.20",
+			newVersion:   "1.9.21",
+			expectedLine: `kotlin = "1.9.21"`,
+		},
+		{
+			name:         "rich version",
+			entry:        "versions.retrofit",
+			expectedVer:  "2.9.0",
+			newVersion:   "2.10.0",
+			expectedLine: `retrofit = { strictly = "2.10.0" }`,
+		},
+		{
+			name:         "library with version",
+			entry:        "libraries.okhttp",
+			expectedVer:  "4.12.0",
+			newVersion:   "4.12.1",
+			expectedLine: `okhttp = { module = "com.squareup.okhttp3:okhttp", version = "4.12.1" }`,
+		},
+		{
+			name:         "library with version reference",
+			entry:        "libraries.retrofit",
+			expectedVer:  "2.9.0",
+			newVersion:   "2.10.0",
+			expectedLine: `retrofit = { strictly = "2.10.0" }`,
+		},
+		{
+			name:         "library notation",
+			entry:        "libraries.junit",
+			expectedVer:  "4.13.2",
+			newVersion:   "4.13.3",
+			expectedLine: `junit = "junit:junit:4.13.3"  # tests`,
+		},
+		{
+			name:         "plugin with version reference",
+			entry:        "plugins.kotlin-jvm",
+			expectedVer:  "1.9.20",
+			newVersion:   "2.0.0",
+			expectedLine: `kotlin = "2.0.0"`,
+		},
+		{
+			name:         "plugin notation",
+			entry:        "plugins.detekt",
+			expectedVer:  "1.23.4",
+			newVersion:   "1.23.5",
+			expectedLine: `detekt = "io.gitlab.arturbosch.detekt:1.23.5"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newGradleCatalogTarget(t, tt.entry)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(testGradleCatalog, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if len(changed) != 1 || changed[0] != tt.expectedLine {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLine)
+			}
+		})
+	}
+}
+
+func TestGradleCatalogTarget_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         string
+		errorContains string
+		notFound      bool
+	}{
+		{name: "missing alias", entry: "libraries.guava", notFound: true},
+		{name: "missing table", entry: "bundles.testing", notFound: true},
+		{name: "no version", entry: "libraries.bom", errorContains: "has no single version"},
+		{name: "missing version reference", entry: "libraries.broken", errorContains: "version 'missing' referenced by catalog entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newGradleCatalogTarget(t, tt.entry)
+			_, err := target.ReadCurrentVersion()
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			var notFound *DependencyNotFoundError
+			if tt.notFound != errors.As(err, &notFound) {
+				t.Errorf("Expected DependencyNotFoundError=%v, got %v", tt.notFound, err)
+			}
+			if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// HelmfileTarget implements the TargetClient interface for releases in helmfile.yaml files.
+// It updates the chart version of a release, or a value it overrides with valuePath.
+type HelmfileTarget struct {
+	config     *configuration.Target
+	updateItem *configuration.TargetItem
+	yaml       *YamlFieldTarget
+}
+
+// NewHelmfileTargetForUpdateItem creates a new helmfile target for a specific update item
+func NewHelmfileTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*HelmfileTarget, error) {
+	if updateItem.ReleaseName == "" {
+		return nil, fmt.Errorf("releaseName is required for helmfile target")
+	}
+
+	target := &HelmfileTarget{
+		config:     config,
+		updateItem: updateItem,
+		yaml:       &YamlFieldTarget{config: config, updateItem: updateItem},
+	}
+
+	if err := target.yaml.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// findRelease returns the release matching the item's releaseName, given as "name" or
+// "namespace/name"
+func (t *HelmfileTarget) findRelease() (*yaml.Node, error) {
+	namespace, name, scoped := strings.Cut(t.updateItem.ReleaseName, "/")
+	if !scoped {
+		name, namespace = namespace, ""
+	}
+
+	var matches []*yaml.Node
+	for _, root := range t.yaml.rootNodes {
+		releases, err := findNode(root, []string{"releases"})
+		if err != nil || releases.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, release := range releases.Content {
+			if mappingValue(release, "name") != name {
+				continue
+			}
+			if scoped && mappingValue(release, "namespace") != namespace {
+				continue
+			}
+			matches = append(matches, release)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, &ReleaseNotFoundError{Release: t.updateItem.ReleaseName, File: t.config.File}
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("release '%s' is ambiguous in file %s, use namespace/name", t.updateItem.ReleaseName, t.config.File)
+	}
+}
+
+// mappingValue returns the scalar value of a key of a mapping node, empty if not found
+func mappingValue(node *yaml.Node, key string) string {
+	value, err := findNode(node, []string{key})
+	if err != nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+// findNodes returns the scalar nodes holding the item's version: the chart version of the
+// release, or all overrides of the valuePath. Overrides in set take precedence over values,
+// as in helmfile, so the first node holds the effective value.
+func (t *HelmfileTarget) findNodes() ([]*yaml.Node, error) {
+	release, err := t.findRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.updateItem.ValuePath == "" {
+		node, err := findNode(release, []string{"version"})
+		if err != nil || node.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("release '%s' in file %s has no chart version", t.updateItem.ReleaseName, t.config.File)
+		}
+		return []*yaml.Node{node}, nil
+	}
+
+	var nodes []*yaml.Node
+	if set, err := findNode(release, []string{"set"}); err == nil && set.Kind == yaml.SequenceNode {
+		for _, entry := range set.Content {
+			if mappingValue(entry, "name") != t.updateItem.ValuePath {
+				continue
+			}
+			if node, err := findNode(entry, []string{"value"}); err == nil && node.Kind == yaml.ScalarNode {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	// Values files referenced by path are not followed, only inline values
+	if values, err := findNode(release, []string{"values"}); err == nil && values.Kind == yaml.SequenceNode {
+		for _, entry := range values.Content {
+			if entry.Kind != yaml.MappingNode {
+				continue
+			}
+			if node, err := findNode(entry, parsePath(t.updateItem.ValuePath)); err == nil && node.Kind == yaml.ScalarNode {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("value '%s' of release '%s' not found in file %s", t.updateItem.ValuePath, t.updateItem.ReleaseName, t.config.File)
+	}
+	return nodes, nil
+}
+
+// ReadCurrentVersion reads the chart version or the overridden value of the release
+func (t *HelmfileTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("release", t.updateItem.ReleaseName).
+		Str("valuePath", t.updateItem.ValuePath).
+		Msg("Reading current version from helmfile")
+
+	nodes, err := t.findNodes()
+	if err != nil {
+		return "", err
+	}
+
+	value := nodes[0].Value
+	if t.updateItem.ValuePath != "" && isDockerImageReference(value) {
+		value = extractTagFromImageReference(value)
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("release", t.updateItem.ReleaseName).
+		Str("version", value).
+		Msg("Found current version")
+
+	return value, nil
+}
+
+// WriteVersion writes the chart version or all overrides of the value of the release
+func (t *HelmfileTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("release", t.updateItem.ReleaseName).
+		Str("valuePath", t.updateItem.ValuePath).
+		Str("version", version).
+		Msg("Writing new version to helmfile")
+
+	nodes, err := t.findNodes()
+	if err != nil {
+		return err
+	}
+
+	// Replace from the end, so earlier positions on a shared line stay valid
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Line != nodes[j].Line {
+			return nodes[i].Line > nodes[j].Line
+		}
+		return nodes[i].Column > nodes[j].Column
+	})
+	for _, node := range nodes {
+		newValue := version
+		if t.updateItem.ValuePath != "" && isDockerImageReference(node.Value) {
+			newValue = replaceTagInImageReference(node.Value, version)
+		}
+		if err := t.yaml.replaceScalar(node, newValue); err != nil {
+			return err
+		}
+	}
+	if err := t.yaml.save(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("release", t.updateItem.ReleaseName).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *HelmfileTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("release", t.updateItem.ReleaseName).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *HelmfileTarget) Validate() error {
+	if err := t.yaml.readFile(); err != nil {
+		return err
+	}
+
+	fileName := strings.ToLower(t.config.File)
+	if !strings.HasSuffix(fileName, ".yaml") && !strings.HasSuffix(fileName, ".yml") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .yaml or .yml extension",
+		}
+	}
+
+	if _, err := t.findRelease(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("release", t.updateItem.ReleaseName).
+		Msg("Helmfile target validation successful")
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testHelmfile = `repositories:
+  - name: bitnami
+    url: https://charts.bitnami.com/bitnami
+
+releases:
+  - name: postgres
+    namespace: db
+    chart: bitnami/postgresql
+    version: 12.1.0 # pinned
+    values:
+      - values/postgres.yaml
+      - image:
+          tag: "15.1.0"
+    set:
+      - name: image.tag
+        value: 15.1.0
+  - name: redis
+    namespace: cache
+    chart: bitnami/redis
+    version: "17.3.0"
+    values:
+      - image:
+          registry: docker.io
+          repository: bitnami/redis
+          tag: 7.0.5
+        sentinel:
+          image: bitnami/redis-sentinel:7.0.5
+---
+releases:
+  - name: redis
+    namespace: cache-replica
+    chart: bitnami/redis
+    version: '17.3.0'
+`
+
+func newHelmfileTarget(t *testing.T, releaseName string, valuePath string) (*HelmfileTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "helmfile.yaml")
+	if err := os.WriteFile(tmpFile, []byte(testHelmfile), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeHelmfile,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{ReleaseName: releaseName, ValuePath: valuePath, Source: "test-source"}},
+	}
+	target, err := NewHelmfileTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestHelmfileTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		releaseName   string
+		valuePath     string
+		expectedVer   string
+		newVersion    string
+		expectedLines []string
+	}{
+		{
+			name:          "chart version",
+			releaseName:   "postgres",
+			expectedVer:   "12.1.0",
+			newVersion:    "12.2.0",
+			expectedLines: []string{"    version: 12.2.0 # pinned"},
+		},
+		{
+			name:          "chart version by namespace",
+			releaseName:   "cache-replica/redis",
+			expectedVer:   "17.3.0",
+			newVersion:    "17.4.0",
+			expectedLines: []string{"    version: '17.4.0'"},
+		},
+		{
+			name:        "set and inline values",
+			releaseName: "db/postgres",
+			valuePath:   "image.tag",
+			expectedVer: "15.1.0",
+			newVersion:  "15.2.0",
+			expectedLines: []string{
+				`          tag: "15.2.0"`,
+				"        value: 15.2.0",
+			},
+		},
+		{
+			name:          "inline values",
+			releaseName:   "cache/redis",
+			valuePath:     "image.tag",
+			expectedVer:   "7.0.5",
+			newVersion:    "7.0.8",
+			expectedLines: []string{"          tag: 7.0.8"},
+		},
+		{
+			name:          "image reference",
+			releaseName:   "cache/redis",
+			valuePath:     "sentinel.image",
+			expectedVer:   "7.0.5",
+			newVersion:    "7.0.8",
+			expectedLines: []string{"          image: bitnami/redis-sentinel:7.0.8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newHelmfileTarget(t, tt.releaseName, tt.valuePath)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+			if version, err := target.ReadCurrentVersion(); err != nil || version != tt.newVersion {
+				t.Errorf("Expected version '%s' after write, got '%s' (%v)", tt.newVersion, version, err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(testHelmfile, "\n")
+			if len(lines) != len(original) {
+				t.Fatalf("Expected %d lines, got %d", len(original), len(lines))
+			}
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if strings.Join(changed, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLines)
+			}
+		})
+	}
+}
+
+func TestHelmfileTarget_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		releaseName   string
+		valuePath     string
+		errorContains string
+		notFound      bool
+	}{
+		{name: "missing release", releaseName: "mysql", notFound: true},
+		{name: "wrong namespace", releaseName: "db/redis", notFound: true},
+		{name: "ambiguous release", releaseName: "redis", errorContains: "ambiguous"},
+		{name: "missing value", releaseName: "db/postgres", valuePath: "image.registry", errorContains: "value 'image.registry' of release 'db/postgres' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newHelmfileTarget(t, tt.releaseName, tt.valuePath)
+			_, err := target.ReadCurrentVersion()
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			var notFound *ReleaseNotFoundError
+			if tt.notFound != errors.As(err, &notFound) {
+				t.Errorf("Expected ReleaseNotFoundError=%v, got %v", tt.notFound, err)
+			}
+			if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// JsonnetFieldTarget implements the TargetClient interface for string literals in Jsonnet files
+type JsonnetFieldTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+	fields       map[string][]jsonnetToken
+}
+
+// NewJsonnetFieldTargetForUpdateItem creates a new jsonnet-field target for a specific update item
+func NewJsonnetFieldTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*JsonnetFieldTarget, error) {
+	if updateItem.JsonnetPath == "" {
+		return nil, fmt.Errorf("jsonnetPath is required for jsonnet-field target")
+	}
+
+	target := &JsonnetFieldTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads the target file into memory and locates its string fields
+func (t *JsonnetFieldTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	return t.parse(string(content))
+}
+
+// parse locates the string fields of the given file contents
+func (t *JsonnetFieldTarget) parse(contents string) error {
+	tokens, err := tokenizeJsonnet(contents)
+	if err != nil {
+		return &InvalidFileFormatError{File: t.config.File, Reason: err.Error()}
+	}
+	t.fileContents = contents
+	t.fields = parseJsonnetFields(tokens)
+	return nil
+}
+
+// findField returns the string literal at the item's jsonnetPath
+func (t *JsonnetFieldTarget) findField() (jsonnetToken, error) {
+	literals := t.fields[t.updateItem.JsonnetPath]
+	switch len(literals) {
+	case 0:
+		return jsonnetToken{}, &JsonnetFieldNotFoundError{Path: t.updateItem.JsonnetPath, File: t.config.File}
+	case 1:
+		return literals[0], nil
+	default:
+		return jsonnetToken{}, fmt.Errorf("jsonnet path '%s' is ambiguous in file %s, it is assigned %d times", t.updateItem.JsonnetPath, t.config.File, len(literals))
+	}
+}
+
+// ReadCurrentVersion reads the current version from the string literal at the jsonnetPath
+func (t *JsonnetFieldTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("jsonnetPath", t.updateItem.JsonnetPath).
+		Msg("Reading current version from Jsonnet file")
+
+	literal, err := t.findField()
+	if err != nil {
+		return "", err
+	}
+
+	value := literal.value()
+	// Like yaml-field, only the tag of a Docker image reference is compared
+	if isDockerImageReference(value) {
+		value = extractTagFromImageReference(value)
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("jsonnetPath", t.updateItem.JsonnetPath).
+		Str("version", value).
+		Msg("Found current version")
+
+	return value, nil
+}
+
+// WriteVersion replaces the string literal at the jsonnetPath, keeping its quoting style
+func (t *JsonnetFieldTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("jsonnetPath", t.updateItem.JsonnetPath).
+		Str("version", version).
+		Msg("Writing new version to Jsonnet file")
+
+	literal, err := t.findField()
+	if err != nil {
+		return err
+	}
+
+	newValue := version
+	if oldValue := literal.value(); isDockerImageReference(oldValue) {
+		newValue = replaceTagInImageReference(oldValue, version)
+	}
+
+	newContents := t.fileContents[:literal.start] + literal.escape(newValue) + t.fileContents[literal.end:]
+	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	if err := t.parse(newContents); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("jsonnetPath", t.updateItem.JsonnetPath).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *JsonnetFieldTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("jsonnetPath", t.updateItem.JsonnetPath).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *JsonnetFieldTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	fileName := strings.ToLower(t.config.File)
+	if !strings.HasSuffix(fileName, ".jsonnet") && !strings.HasSuffix(fileName, ".libsonnet") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .jsonnet or .libsonnet extension",
+		}
+	}
+
+	// Like yaml-field, missing paths are reported by ReadCurrentVersion so wildcard
+	// matches without the path do not fail validation
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("jsonnetPath", t.updateItem.JsonnetPath).
+		Msg("Jsonnet field target validation successful")
+
+	return nil
+}
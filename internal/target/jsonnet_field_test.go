@@ -0,0 +1,295 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const tankaEnvironment = `// Grafana environment
+local k = import 'k.libsonnet';
+local versions = {
+  grafana: '10.2.0',  # pinned
+  loki: "2.9.1",
+};
+local agentVersion = @'0.38.0', unused = 1;
+
+{
+  _config+:: {
+    prometheus: {
+      version: 'v2.48.0',
+      args: ['--web.enable-lifecycle', '--storage.tsdb.retention=' + '15d'],
+    },
+  },
+  /* images */
+  _images+:: {
+    grafana: 'grafana/grafana:10.2.0',
+    'memcached': "memcached:1.6.22-alpine",
+  },
+  grafana: k.apps.v1.deployment.new('grafana', containers=[
+    k.core.v1.container.new('grafana', 'grafana/grafana:' + versions.grafana),
+  ]),
+  loki: {
+    local lokiVersion = '2.9.1',
+    image: 'grafana/loki:%s' % lokiVersion,
+    tiers: ['1.0.0', { version: '2.0.0' }],
+    description: |||
+      version: '9.9.9'
+    |||,
+    enabled: if versions.loki != '' then true else false,
+  },
+}
+`
+
+func newJsonnetTarget(t *testing.T, content string, path string) (*JsonnetFieldTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "main.jsonnet")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeJsonnetField,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{JsonnetPath: path, Source: "test-source"}},
+	}
+	target, err := NewJsonnetFieldTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestJsonnetFieldTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		jsonnetPath  string
+		expectedVer  string
+		newVersion   string
+		expectedLine string
+	}{
+		{
+			name:         "local object field",
+			jsonnetPath:  "versions.grafana",
+			expectedVer:  "10.2.0",
+			newVersion:   "10.3.1",
+			expectedLine: "  grafana: '10.3.1',  # pinned",
+		},
+		{
+			name:         "double-quoted local object field",
+			jsonnetPath:  "versions.loki",
+			expectedVer:  "2.9.1",
+			newVersion:   "3.0.0",
+			expectedLine: `  loki: "3.0.0",`,
+		},
+		{
+			name:         "verbatim local variable",
+			jsonnetPath:  "agentVersion",
+			expectedVer:  "0.38.0",
+			newVersion:   "0.39.0",
+			expectedLine: "local agentVersion = @'0.39.0', unused = 1;",
+		},
+		{
+			name:         "nested hidden field",
+			jsonnetPath:  "_config.prometheus.version",
+			expectedVer:  "v2.48.0",
+			newVersion:   "v2.49.1",
+			expectedLine: "      version: 'v2.49.1',",
+		},
+		{
+			name:         "image reference",
+			jsonnetPath:  "_images.grafana",
+			expectedVer:  "10.2.0",
+			newVersion:   "10.3.1",
+			expectedLine: "    grafana: 'grafana/grafana:10.3.1',",
+		},
+		{
+			name:         "quoted key",
+			jsonnetPath:  "_images.memcached",
+			expectedVer:  "1.6.22-alpine",
+			newVersion:   "1.6.23-alpine",
+			expectedLine: `    'memcached': "memcached:1.6.23-alpine",`,
+		},
+		{
+			name:         "object local",
+			jsonnetPath:  "loki.lokiVersion",
+			expectedVer:  "2.9.1",
+			newVersion:   "3.0.0",
+			expectedLine: "    local lokiVersion = '3.0.0',",
+		},
+		{
+			name:         "array element",
+			jsonnetPath:  "loki.tiers.0",
+			expectedVer:  "1.0.0",
+			newVersion:   "1.1.0",
+			expectedLine: "    tiers: ['1.1.0', { version: '2.0.0' }],",
+		},
+		{
+			name:         "object in array",
+			jsonnetPath:  "loki.tiers.1.version",
+			expectedVer:  "2.0.0",
+			newVersion:   "2.1.0",
+			expectedLine: "    tiers: ['1.0.0', { version: '2.1.0' }],",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newJsonnetTarget(t, tankaEnvironment, tt.jsonnetPath)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+			if version, err := target.ReadCurrentVersion(); err != nil || version != tt.newVersion {
+				t.Errorf("Expected version '%s' after write, got '%s' (%v)", tt.newVersion, version, err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(tankaEnvironment, "\n")
+			if len(lines) != len(original) {
+				t.Fatalf("Expected %d lines, got %d", len(original), len(lines))
+			}
+			changed := 0
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed++
+					if lines[i] != tt.expectedLine {
+						t.Errorf("Unexpected line %d: %q, expected %q", i+1, lines[i], tt.expectedLine)
+					}
+				}
+			}
+			if changed != 1 {
+				t.Errorf("Expected exactly one changed line, got %d", changed)
+			}
+		})
+	}
+}
+
+func TestJsonnetFieldTarget_NotAddressable(t *testing.T) {
+	for _, path := range []string{
+		"grafana",                   // function call
+		"loki.image",                // format expression
+		"loki.description",          // text block
+		"_config.prometheus.args.1", // concatenation
+		"versions.tempo",            // missing field
+		"grafana.containers.0",      // inside a function call
+		"k",                         // import
+	} {
+		t.Run(path, func(t *testing.T) {
+			target, _ := newJsonnetTarget(t, tankaEnvironment, path)
+			_, err := target.ReadCurrentVersion()
+			var notFound *JsonnetFieldNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("Expected JsonnetFieldNotFoundError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJsonnetFieldTarget_Ambiguous(t *testing.T) {
+	target, _ := newJsonnetTarget(t, "{ app: { version: '1.0.0' } } + { app+: { version: '1.1.0' } }\n", "app.version")
+	if _, err := target.ReadCurrentVersion(); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous path error, got %v", err)
+	}
+}
+
+func TestJsonnetFieldTarget_Escapes(t *testing.T) {
+	target, tmpFile := newJsonnetTarget(t, `{ tag: 'it\'s-1.0', verbatim: @'it''s-1.0' }`, "tag")
+	if version, err := target.ReadCurrentVersion(); err != nil || version != "it's-1.0" {
+		t.Fatalf("Expected unescaped version, got '%s' (%v)", version, err)
+	}
+	if err := target.WriteVersion("it's-2.0"); err != nil {
+		t.Fatalf("Failed to write version: %v", err)
+	}
+
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeJsonnetField,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{JsonnetPath: "verbatim", Source: "test-source"}},
+	}
+	verbatim, err := NewJsonnetFieldTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	if err := verbatim.WriteVersion("it's-2.0"); err != nil {
+		t.Fatalf("Failed to write version: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if expected := `{ tag: 'it\'s-2.0', verbatim: @'it''s-2.0' }`; string(content) != expected {
+		t.Errorf("Expected %s, got %s", expected, content)
+	}
+}
+
+func TestJsonnetFieldTarget_InvalidFile(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "main.jsonnet")
+	if err := os.WriteFile(tmpFile, []byte("{ version: '1.0.0 }\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeJsonnetField,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{JsonnetPath: "version", Source: "test-source"}},
+	}
+	_, err := NewJsonnetFieldTargetForUpdateItem(config, &config.Items[0])
+	var invalid *InvalidFileFormatError
+	if !errors.As(err, &invalid) {
+		t.Errorf("Expected InvalidFileFormatError for unterminated string, got %v", err)
+	}
+}
+
+func TestJsonnetFieldTarget_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		expectError bool
+	}{
+		{name: "jsonnet file", fileName: "main.jsonnet"},
+		{name: "libsonnet file", fileName: "versions.libsonnet"},
+		{name: "other extension", fileName: "main.json", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(tmpFile, []byte("{ version: '1.0.0' }\n"), 0644); err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+			config := &configuration.Target{
+				Name:  "test-target",
+				Type:  configuration.TargetTypeJsonnetField,
+				File:  tmpFile,
+				Items: []configuration.TargetItem{{JsonnetPath: "version", Source: "test-source"}},
+			}
+			target, err := NewJsonnetFieldTargetForUpdateItem(config, &config.Items[0])
+			if err != nil {
+				t.Fatalf("Failed to create target: %v", err)
+			}
+			err = target.Validate()
+			if tt.expectError != (err != nil) {
+				t.Errorf("Expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package target
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jsonnetTokenKind classifies the tokens of a Jsonnet file needed to locate string fields
+type jsonnetTokenKind int
+
+const (
+	jsonnetString     jsonnetTokenKind = iota // Quoted or verbatim string literal
+	jsonnetIdentifier                         // Identifier or keyword
+	jsonnetSymbol                             // Bracket, separator, ":", "::", ":::" or "="
+	jsonnetOther                              // Numbers, operators and text blocks
+)
+
+// jsonnetToken is a token of a Jsonnet file. For string literals, start and end enclose
+// the content between the quotes.
+type jsonnetToken struct {
+	kind     jsonnetTokenKind
+	text     string
+	start    int
+	end      int
+	quote    byte
+	verbatim bool
+}
+
+// textBlockEnd matches the line closing a ||| text block
+var textBlockEnd = regexp.MustCompile(`\n[ \t]*\|\|\|`)
+
+// tokenizeJsonnet splits Jsonnet source into tokens, skipping whitespace and comments
+func tokenizeJsonnet(src string) ([]jsonnetToken, error) {
+	var tokens []jsonnetToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		rest := src[i:]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(rest, "//"):
+			if end := strings.IndexByte(rest, '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 4
+		case strings.HasPrefix(rest, "|||"):
+			end := textBlockEnd.FindStringIndex(rest)
+			if end == nil {
+				return nil, fmt.Errorf("unterminated text block at offset %d", i)
+			}
+			tokens = append(tokens, jsonnetToken{kind: jsonnetOther, text: "|||", start: i, end: i + end[1]})
+			i += end[1]
+		case c == '"' || c == '\'':
+			end, err := scanJsonnetString(src, i+1, c, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, jsonnetToken{kind: jsonnetString, text: src[i+1 : end], start: i + 1, end: end, quote: c})
+			i = end + 1
+		case c == '@' && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\''):
+			end, err := scanJsonnetString(src, i+2, src[i+1], true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, jsonnetToken{kind: jsonnetString, text: src[i+2 : end], start: i + 2, end: end, quote: src[i+1], verbatim: true})
+			i = end + 1
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i + 1
+			for end < len(src) && (src[end] == '_' || src[end] >= 'a' && src[end] <= 'z' || src[end] >= 'A' && src[end] <= 'Z' || src[end] >= '0' && src[end] <= '9') {
+				end++
+			}
+			tokens = append(tokens, jsonnetToken{kind: jsonnetIdentifier, text: src[i:end], start: i, end: end})
+			i = end
+		case strings.ContainsRune("{}[](),;", rune(c)):
+			tokens = append(tokens, jsonnetToken{kind: jsonnetSymbol, text: string(c), start: i, end: i + 1})
+			i++
+		case c == ':':
+			end := i + 1
+			for end < len(src) && end-i < 3 && src[end] == ':' {
+				end++
+			}
+			tokens = append(tokens, jsonnetToken{kind: jsonnetSymbol, text: src[i:end], start: i, end: end})
+			i = end
+		case strings.HasPrefix(rest, "==") || strings.HasPrefix(rest, "!=") || strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, ">="):
+			tokens = append(tokens, jsonnetToken{kind: jsonnetOther, text: rest[:2], start: i, end: i + 2})
+			i += 2
+		case c == '=':
+			tokens = append(tokens, jsonnetToken{kind: jsonnetSymbol, text: "=", start: i, end: i + 1})
+			i++
+		default:
+			tokens = append(tokens, jsonnetToken{kind: jsonnetOther, text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// scanJsonnetString returns the offset of the quote closing a string literal whose content
+// starts at the given offset. Verbatim strings escape quotes by doubling them.
+func scanJsonnetString(src string, start int, quote byte, verbatim bool) (int, error) {
+	for i := start; i < len(src); i++ {
+		switch {
+		case verbatim && src[i] == quote && i+1 < len(src) && src[i+1] == quote:
+			i++
+		case !verbatim && src[i] == '\\':
+			i++
+		case src[i] == quote:
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", start-1)
+}
+
+// value returns the unescaped content of a string literal
+func (t jsonnetToken) value() string {
+	if t.verbatim {
+		return strings.ReplaceAll(t.text, string([]byte{t.quote, t.quote}), string(t.quote))
+	}
+	if !strings.Contains(t.text, `\`) {
+		return t.text
+	}
+	var value strings.Builder
+	for i := 0; i < len(t.text); i++ {
+		if t.text[i] != '\\' || i+1 == len(t.text) {
+			value.WriteByte(t.text[i])
+			continue
+		}
+		i++
+		switch t.text[i] {
+		case 'n':
+			value.WriteByte('\n')
+		case 't':
+			value.WriteByte('\t')
+		case 'r':
+			value.WriteByte('\r')
+		default:
+			value.WriteByte(t.text[i])
+		}
+	}
+	return value.String()
+}
+
+// escape returns the content of a string literal of the same quoting style holding value
+func (t jsonnetToken) escape(value string) string {
+	quote := string(t.quote)
+	if t.verbatim {
+		return strings.ReplaceAll(value, quote, quote+quote)
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, quote, `\`+quote)
+}
+
+// jsonnetParser collects the string literals assigned to object fields and local variables
+type jsonnetParser struct {
+	tokens []jsonnetToken
+	pos    int
+	fields map[string][]jsonnetToken
+}
+
+// parseJsonnetFields returns the string literals of a Jsonnet file by dotted path. Object
+// fields are addressed by their keys, array elements by their index and local variables by
+// their name, prefixed with the path of the object they are declared in. Values built from
+// expressions, function calls or text blocks are not addressable.
+func parseJsonnetFields(tokens []jsonnetToken) map[string][]jsonnetToken {
+	p := &jsonnetParser{tokens: tokens, fields: make(map[string][]jsonnetToken)}
+	for p.pos < len(p.tokens) {
+		p.parseExpression([]string{})
+		// Skip a stray terminator at top level
+		if p.pos < len(p.tokens) {
+			p.pos++
+		}
+	}
+	return p.fields
+}
+
+// peek returns the token at the current position, an empty token at the end
+func (p *jsonnetParser) peek() jsonnetToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return jsonnetToken{kind: jsonnetOther}
+}
+
+// isSymbol reports whether the current token is one of the given symbols
+func (p *jsonnetParser) isSymbol(symbols ...string) bool {
+	token := p.peek()
+	if token.kind != jsonnetSymbol {
+		return false
+	}
+	for _, symbol := range symbols {
+		if token.text == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// isTerminator reports whether the current token ends a value
+func (p *jsonnetParser) isTerminator() bool {
+	return p.pos >= len(p.tokens) || p.isSymbol(",", ";", "}", "]", ")")
+}
+
+// childPath returns the path of a key below path, nil for keys below unaddressable values
+func childPath(path []string, key string) []string {
+	if path == nil {
+		return nil
+	}
+	return append(append([]string{}, path...), key)
+}
+
+// parseValue records a string literal forming the whole value, or parses the expression
+func (p *jsonnetParser) parseValue(path []string) {
+	if token := p.peek(); token.kind == jsonnetString {
+		p.pos++
+		if p.isTerminator() {
+			if len(path) > 0 {
+				key := strings.Join(path, ".")
+				p.fields[key] = append(p.fields[key], token)
+			}
+			return
+		}
+		// The literal is part of an expression, e.g. a concatenation
+		path = nil
+	}
+	p.parseExpression(path)
+}
+
+// parseExpression skips an expression up to its terminator, descending into object and
+// array literals and local bindings it contains
+func (p *jsonnetParser) parseExpression(path []string) {
+	previous := jsonnetToken{kind: jsonnetOther}
+	for !p.isTerminator() {
+		token := p.peek()
+		p.pos++
+		switch {
+		case token.kind == jsonnetSymbol && token.text == "{":
+			p.parseObject(path)
+		case token.kind == jsonnetSymbol && token.text == "[":
+			// An index such as versions['app'] follows a value, an array literal does not
+			if previous.kind == jsonnetString || previous.kind == jsonnetIdentifier && !isJsonnetKeyword(previous.text) ||
+				previous.kind == jsonnetSymbol && (previous.text == "]" || previous.text == ")" || previous.text == "}") {
+				p.skipUntil("]")
+			} else {
+				p.parseArray(path)
+			}
+		case token.kind == jsonnetSymbol && token.text == "(":
+			p.skipUntil(")")
+		case token.kind == jsonnetIdentifier && token.text == "local":
+			p.parseLocals(path)
+		}
+		previous = p.tokens[p.pos-1]
+	}
+}
+
+// isJsonnetKeyword reports whether an identifier is a keyword an array literal may follow
+func isJsonnetKeyword(identifier string) bool {
+	switch identifier {
+	case "if", "then", "else", "in", "return", "error", "assert":
+		return true
+	default:
+		return false
+	}
+}
+
+// skipUntil skips tokens up to and including the given closing bracket
+func (p *jsonnetParser) skipUntil(closing string) {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		token := p.tokens[p.pos]
+		p.pos++
+		if token.kind != jsonnetSymbol {
+			continue
+		}
+		switch token.text {
+		case "{", "[", "(":
+			depth++
+		case "}", "]", ")":
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// parseObject parses the fields of an object literal after its opening brace
+func (p *jsonnetParser) parseObject(path []string) {
+	for p.pos < len(p.tokens) {
+		token := p.peek()
+		switch {
+		case p.isSymbol("}"):
+			p.pos++
+			return
+		case p.isSymbol(",", ";", ")", "]"):
+			p.pos++
+			continue
+		case token.kind == jsonnetIdentifier && token.text == "local":
+			p.pos++
+			p.parseBinding(path)
+			continue
+		case token.kind == jsonnetIdentifier && (token.text == "assert" || token.text == "for" || token.text == "if"):
+			p.parseExpression(nil)
+			continue
+		}
+
+		// The key is an identifier, a string or a computed [expression]
+		fieldPath := path
+		switch {
+		case token.kind == jsonnetIdentifier:
+			fieldPath = childPath(path, token.text)
+			p.pos++
+		case token.kind == jsonnetString:
+			fieldPath = childPath(path, token.value())
+			p.pos++
+		case p.isSymbol("["):
+			fieldPath = nil
+			p.pos++
+			p.skipUntil("]")
+		default:
+			p.pos++
+			continue
+		}
+
+		// Methods are functions, their bodies are not addressable
+		if p.isSymbol("(") {
+			fieldPath = nil
+			p.pos++
+			p.skipUntil(")")
+		}
+		if next := p.peek(); next.kind == jsonnetOther && next.text == "+" {
+			p.pos++
+		}
+		if !p.isSymbol(":", "::", ":::") {
+			continue
+		}
+		p.pos++
+		p.parseValue(fieldPath)
+	}
+}
+
+// parseArray parses the elements of an array literal after its opening bracket
+func (p *jsonnetParser) parseArray(path []string) {
+	for index := 0; p.pos < len(p.tokens); index++ {
+		if p.isSymbol("]") {
+			p.pos++
+			return
+		}
+		p.parseValue(childPath(path, fmt.Sprint(index)))
+		if !p.isSymbol(",") {
+			// Comprehensions and unbalanced brackets end the array
+			p.skipUntil("]")
+			return
+		}
+		p.pos++
+	}
+}
+
+// parseLocals parses the bindings of a local expression up to its semicolon
+func (p *jsonnetParser) parseLocals(path []string) {
+	for p.pos < len(p.tokens) {
+		p.parseBinding(path)
+		if !p.isSymbol(",") {
+			break
+		}
+		p.pos++
+	}
+	if p.isSymbol(";") {
+		p.pos++
+	}
+}
+
+// parseBinding parses a single "name = value" binding of a local
+func (p *jsonnetParser) parseBinding(path []string) {
+	token := p.peek()
+	if token.kind != jsonnetIdentifier {
+		return
+	}
+	p.pos++
+	bindingPath := childPath(path, token.text)
+	// Local functions are not addressable
+	if p.isSymbol("(") {
+		bindingPath = nil
+		p.pos++
+		p.skipUntil(")")
+	}
+	if !p.isSymbol("=") {
+		return
+	}
+	p.pos++
+	p.parseValue(bindingPath)
+}
@@ -0,0 +1,188 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// MakeVariableTarget implements the TargetClient interface for variable assignments in
+// Makefiles and shell scripts
+type MakeVariableTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+	pattern      *regexp.Regexp
+}
+
+// makeAssignmentPrefix matches the start of an assignment line up to the variable name
+const makeAssignmentPrefix = `(?m)^[ \t]*(?:(?:export|override|readonly|local|declare(?:[ \t]+-[a-zA-Z]+)*)[ \t]+)?`
+
+// shellDefaultPattern matches a shell default value expansion such as ${FOO_VERSION:-1.2.3}
+var shellDefaultPattern = regexp.MustCompile(`^\$\{\w+:?[-=]([^}]*)\}$`)
+
+// NewMakeVariableTargetForUpdateItem creates a new make-variable target for a specific update item
+func NewMakeVariableTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*MakeVariableTarget, error) {
+	if updateItem.VariableName == "" {
+		return nil, fmt.Errorf("variableName is required for make-variable target")
+	}
+
+	target := &MakeVariableTarget{
+		config:     config,
+		updateItem: updateItem,
+		pattern:    makeVariablePattern(updateItem.VariableName),
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// makeVariablePattern matches the assignments of a variable with their value in group 1
+// (double-quoted), 2 (single-quoted) or 3 (unquoted). It covers Makefile assignments
+// (FOO = 1.0, FOO := 1.0, FOO ?= 1.0, export FOO ?= 1.0) and shell assignments
+// (FOO=1.0, FOO="1.0", export FOO='1.0', readonly FOO=1.0, local FOO=1.0).
+func makeVariablePattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(
+		`%s%s[ \t]*(?:\?|:{1,3})?=[ \t]*(?:"([^"\n]*)"|'([^'\n]*)'|([^\s#;"']+))`,
+		makeAssignmentPrefix, regexp.QuoteMeta(name),
+	))
+}
+
+// readFile reads the target file into memory
+func (t *MakeVariableTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	t.fileContents = string(content)
+	return nil
+}
+
+// findValues returns the offsets of the values of all assignments of the variable. The
+// default of a shell expansion such as "${FOO_VERSION:-1.2.3}" is the value.
+func (t *MakeVariableTarget) findValues() ([][2]int, error) {
+	var values [][2]int
+	for _, match := range t.pattern.FindAllStringSubmatchIndex(t.fileContents, -1) {
+		for group := 1; group <= 3; group++ {
+			start, end := match[2*group], match[2*group+1]
+			if start < 0 {
+				continue
+			}
+			if defaultValue := shellDefaultPattern.FindStringSubmatchIndex(t.fileContents[start:end]); defaultValue != nil {
+				start, end = start+defaultValue[2], start+defaultValue[3]
+			}
+			values = append(values, [2]int{start, end})
+		}
+	}
+	if len(values) == 0 {
+		return nil, &VariableNotFoundError{Variable: t.updateItem.VariableName, File: t.config.File}
+	}
+	return values, nil
+}
+
+// ReadCurrentVersion reads the value of the first assignment of the variable
+func (t *MakeVariableTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("variable", t.updateItem.VariableName).
+		Msg("Reading current version from variable assignment")
+
+	values, err := t.findValues()
+	if err != nil {
+		return "", err
+	}
+	version := t.fileContents[values[0][0]:values[0][1]]
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("variable", t.updateItem.VariableName).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new version to all assignments of the variable, keeping their
+// quoting and operators
+func (t *MakeVariableTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("variable", t.updateItem.VariableName).
+		Str("version", version).
+		Msg("Writing new version to variable assignment")
+
+	values, err := t.findValues()
+	if err != nil {
+		return err
+	}
+
+	var newContents strings.Builder
+	previous := 0
+	for _, value := range values {
+		newContents.WriteString(t.fileContents[previous:value[0]])
+		newContents.WriteString(version)
+		previous = value[1]
+	}
+	newContents.WriteString(t.fileContents[previous:])
+
+	if err := os.WriteFile(t.config.File, []byte(newContents.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	t.fileContents = newContents.String()
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("variable", t.updateItem.VariableName).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *MakeVariableTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("variable", t.updateItem.VariableName).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *MakeVariableTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	// Makefiles and scripts have no common extension, so only the variable is checked
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("variable", t.updateItem.VariableName).
+		Msg("Make variable target validation successful")
+
+	return nil
+}
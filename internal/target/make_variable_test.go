@@ -0,0 +1,202 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testMakefile = `GOLANGCI_LINT_VERSION ?= 1.55.2 # linter
+export HELM_VERSION := 3.13.2
+override KIND_VERSION::=0.20.0
+GOLANGCI_LINT_VERSION_SUFFIX = -rc
+YQ_VERSION=4.40.5
+LDFLAGS += -X main.version=$(VERSION)
+
+ifdef CI
+	CHART_VERSION = 1.0.0
+else
+	CHART_VERSION = 1.0.0
+endif
+
+lint:
+	go run github.com/golangci/golangci-lint/cmd/golangci-lint@v$(GOLANGCI_LINT_VERSION) run
+`
+
+const testShellScript = `#!/usr/bin/env bash
+set -euo pipefail
+
+KUBECTL_VERSION="1.28.4"
+readonly JQ_VERSION='1.7'
+export TERRAFORM_VERSION="${TERRAFORM_VERSION:-1.6.5}" # default
+install() {
+	local HELM_VERSION=3.13.2
+	curl -sSL "https://dl.k8s.io/release/v${KUBECTL_VERSION}/bin/linux/amd64/kubectl"
+}
+`
+
+func newMakeVariableTarget(t *testing.T, fileName string, contents string, variable string) (*MakeVariableTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(tmpFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeMakeVariable,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{VariableName: variable, Source: "test-source"}},
+	}
+	target, err := NewMakeVariableTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestMakeVariableTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		fileName      string
+		contents      string
+		variable      string
+		expectedVer   string
+		newVersion    string
+		expectedLines []string
+	}{
+		{
+			name:          "make conditional assignment",
+			fileName:      "Makefile",
+			contents:      testMakefile,
+			variable:      "GOLANGCI_LINT_VERSION",
+			expectedVer:   "1.55.2",
+			newVersion:    "1.56.0",
+			expectedLines: []string{"GOLANGCI_LINT_VERSION ?= 1.56.0 # linter"},
+		},
+		{
+			name:          "make exported simple assignment",
+			fileName:      "Makefile",
+			contents:      testMakefile,
+			variable:      "HELM_VERSION",
+			expectedVer:   "3.13.2",
+			newVersion:    "3.14.0",
+			expectedLines: []string{"export HELM_VERSION := 3.14.0"},
+		},
+		{
+			name:          "make override assignment",
+			fileName:      "Makefile",
+			contents:      testMakefile,
+			variable:      "KIND_VERSION",
+			expectedVer:   "0.20.0",
+			newVersion:    "0.21.0",
+			expectedLines: []string{"override KIND_VERSION::=0.21.0"},
+		},
+		{
+			name:          "make assignments in conditional branches",
+			fileName:      "Makefile",
+			contents:      testMakefile,
+			variable:      "CHART_VERSION",
+			expectedVer:   "1.0.0",
+			newVersion:    "1.1.0",
+			expectedLines: []string{"\tCHART_VERSION = 1.1.0", "\tCHART_VERSION = 1.1.0"},
+		},
+		{
+			name:          "shell double-quoted",
+			fileName:      "install.sh",
+			contents:      testShellScript,
+			variable:      "KUBECTL_VERSION",
+			expectedVer:   "1.28.4",
+			newVersion:    "1.29.0",
+			expectedLines: []string{`KUBECTL_VERSION="1.29.0"`},
+		},
+		{
+			name:          "shell readonly single-quoted",
+			fileName:      "install.sh",
+			contents:      testShellScript,
+			variable:      "JQ_VERSION",
+			expectedVer:   "1.7",
+			newVersion:    "1.7.1",
+			expectedLines: []string{`readonly JQ_VERSION='1.7.1'`},
+		},
+		{
+			name:          "shell default value",
+			fileName:      "install.sh",
+			contents:      testShellScript,
+			variable:      "TERRAFORM_VERSION",
+			expectedVer:   "1.6.5",
+			newVersion:    "1.7.0",
+			expectedLines: []string{`export TERRAFORM_VERSION="${TERRAFORM_VERSION:-1.7.0}" # default`},
+		},
+		{
+			name:          "shell local unquoted",
+			fileName:      "install.sh",
+			contents:      testShellScript,
+			variable:      "HELM_VERSION",
+			expectedVer:   "3.13.2",
+			newVersion:    "3.14.0",
+			expectedLines: []string{"\tlocal HELM_VERSION=3.14.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newMakeVariableTarget(t, tt.fileName, tt.contents, tt.variable)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(tt.contents, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if strings.Join(changed, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLines)
+			}
+		})
+	}
+}
+
+func TestMakeVariableTarget_NotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		variable string
+	}{
+		{name: "missing variable", contents: testMakefile, variable: "KUBECTL_VERSION"},
+		{name: "appended variable", contents: testMakefile, variable: "LDFLAGS"},
+		{name: "prefix of another variable", contents: testMakefile, variable: "GOLANGCI_LINT"},
+		{name: "reference only", contents: testShellScript, variable: "VERSION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newMakeVariableTarget(t, "Makefile", tt.contents, tt.variable)
+			_, err := target.ReadCurrentVersion()
+			var notFound *VariableNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("Expected VariableNotFoundError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package target
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// MavenPomTarget implements the TargetClient interface for versions in Maven pom.xml files
+type MavenPomTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+	pom          *mavenPom
+}
+
+// mavenPom holds the locations of the versions in a pom.xml
+type mavenPom struct {
+	properties map[string]mavenText
+	artifacts  []mavenArtifact
+}
+
+// mavenText is the location of the text of an element in the file
+type mavenText struct {
+	value string
+	start int
+	end   int
+}
+
+// mavenArtifact is a dependency, plugin or extension declared with a version
+type mavenArtifact struct {
+	groupID    string
+	artifactID string
+	version    mavenText
+}
+
+// mavenDefaultPluginGroup is the groupId of plugins declared without one
+const mavenDefaultPluginGroup = "org.apache.maven.plugins"
+
+// NewMavenPomTargetForUpdateItem creates a new maven-pom target for a specific update item
+func NewMavenPomTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*MavenPomTarget, error) {
+	if (updateItem.Artifact == "") == (updateItem.Property == "") {
+		return nil, fmt.Errorf("either artifact or property is required for maven-pom target")
+	}
+
+	target := &MavenPomTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads and parses the pom.xml
+func (t *MavenPomTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	return t.parse(string(content))
+}
+
+// parse locates the versions in the given file contents
+func (t *MavenPomTarget) parse(contents string) error {
+	pom, err := parseMavenPom(contents)
+	if err != nil {
+		return fmt.Errorf("failed to parse pom.xml %s: %w", t.config.File, err)
+	}
+	t.fileContents = contents
+	t.pom = pom
+	return nil
+}
+
+// findVersions returns the texts holding the item's version. The versions of an artifact
+// referencing a property, e.g. ${jackson.version}, are read from and written to the property.
+func (t *MavenPomTarget) findVersions() ([]mavenText, error) {
+	if t.updateItem.Property != "" {
+		property, found := t.pom.properties[t.updateItem.Property]
+		if !found {
+			return nil, &VariableNotFoundError{Variable: t.updateItem.Property, File: t.config.File}
+		}
+		return []mavenText{property}, nil
+	}
+
+	groupID, artifactID, _ := strings.Cut(t.updateItem.Artifact, ":")
+	var versions []mavenText
+	seen := make(map[int]bool)
+	for _, artifact := range t.pom.artifacts {
+		if artifact.groupID != groupID || artifact.artifactID != artifactID {
+			continue
+		}
+		version := artifact.version
+		if name, ok := mavenPropertyReference(version.value); ok {
+			property, found := t.pom.properties[name]
+			if !found {
+				return nil, fmt.Errorf("property '%s' of artifact '%s' is not defined in file %s", name, t.updateItem.Artifact, t.config.File)
+			}
+			version = property
+		}
+		if !seen[version.start] {
+			seen[version.start] = true
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, &DependencyNotFoundError{Dependency: t.updateItem.Artifact, File: t.config.File}
+	}
+	return versions, nil
+}
+
+// mavenPropertyReference returns the property name of a ${name} value
+func mavenPropertyReference(value string) (string, bool) {
+	name, ok := strings.CutPrefix(value, "${")
+	if !ok || !strings.HasSuffix(name, "}") {
+		return "", false
+	}
+	return strings.TrimSuffix(name, "}"), true
+}
+
+// itemName returns the artifact or property of the item for log messages
+func (t *MavenPomTarget) itemName() string {
+	if t.updateItem.Artifact != "" {
+		return t.updateItem.Artifact
+	}
+	return t.updateItem.Property
+}
+
+// ReadCurrentVersion reads the version of the artifact or the value of the property
+func (t *MavenPomTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("item", t.itemName()).
+		Msg("Reading current version from pom.xml")
+
+	versions, err := t.findVersions()
+	if err != nil {
+		return "", err
+	}
+	version := versions[0].value
+	if strings.Contains(version, "${") {
+		return "", fmt.Errorf("version '%s' of '%s' in file %s is an expression", version, t.itemName(), t.config.File)
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("item", t.itemName()).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new version to all declarations of the artifact, or the property
+func (t *MavenPomTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("item", t.itemName()).
+		Str("version", version).
+		Msg("Writing new version to pom.xml")
+
+	versions, err := t.findVersions()
+	if err != nil {
+		return err
+	}
+
+	var escaped strings.Builder
+	if err := xml.EscapeText(&escaped, []byte(version)); err != nil {
+		return err
+	}
+
+	// Replace from the end, so earlier offsets stay valid
+	sort.Slice(versions, func(i, j int) bool { return versions[i].start > versions[j].start })
+	newContents := t.fileContents
+	for _, location := range versions {
+		newContents = newContents[:location.start] + escaped.String() + newContents[location.end:]
+	}
+
+	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	if err := t.parse(newContents); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("item", t.itemName()).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *MavenPomTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("item", t.itemName()).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *MavenPomTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(filepath.Base(t.config.File), ".xml") {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must have .xml extension",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("item", t.itemName()).
+		Msg("Maven pom target validation successful")
+
+	return nil
+}
+
+// parseMavenPom locates the properties of the project and the versions of its
+// dependencies, plugins and extensions, including those of dependencyManagement,
+// pluginManagement, profiles and plugin dependencies
+func parseMavenPom(contents string) (*mavenPom, error) {
+	pom := &mavenPom{properties: make(map[string]mavenText)}
+	decoder := xml.NewDecoder(strings.NewReader(contents))
+
+	type openArtifact struct {
+		artifact *mavenArtifact
+		depth    int
+	}
+	var stack []string
+	var artifacts []openArtifact // Dependencies, plugins and extensions being parsed
+	var text *mavenText          // Text of the leaf element being parsed
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			name := element.Name.Local
+			stack = append(stack, name)
+			offset := int(decoder.InputOffset())
+			text = &mavenText{start: offset, end: offset}
+			if name == "dependency" || name == "plugin" || name == "extension" {
+				artifact := &mavenArtifact{}
+				if name == "plugin" {
+					artifact.groupID = mavenDefaultPluginGroup
+				}
+				artifacts = append(artifacts, openArtifact{artifact: artifact, depth: len(stack)})
+			}
+		case xml.CharData:
+			if text != nil {
+				text.end = int(decoder.InputOffset())
+			}
+		case xml.EndElement:
+			depth := len(stack)
+			name := stack[depth-1]
+			if text != nil {
+				// Surrounding whitespace is kept when the text is replaced
+				raw := contents[text.start:text.end]
+				text.start += len(raw) - len(strings.TrimLeft(raw, " \t\r\n"))
+				text.end -= len(raw) - len(strings.TrimRight(raw, " \t\r\n"))
+				text.end = max(text.end, text.start)
+				text.value = strings.TrimSpace(html.UnescapeString(raw))
+
+				open := len(artifacts) - 1
+				switch {
+				case depth == 3 && stack[0] == "project" && stack[1] == "properties":
+					pom.properties[name] = *text
+				case open >= 0 && depth == artifacts[open].depth+1:
+					switch name {
+					case "groupId":
+						artifacts[open].artifact.groupID = text.value
+					case "artifactId":
+						artifacts[open].artifact.artifactID = text.value
+					case "version":
+						artifacts[open].artifact.version = *text
+					}
+				}
+			}
+			if open := len(artifacts) - 1; open >= 0 && depth == artifacts[open].depth {
+				if artifact := artifacts[open].artifact; artifact.version.end > artifact.version.start {
+					pom.artifacts = append(pom.artifacts, *artifact)
+				}
+				artifacts = artifacts[:open]
+			}
+			stack = stack[:depth-1]
+			text = nil
+		default:
+			// Comments and other markup inside an element are not part of a plain version
+			text = nil
+		}
+	}
+	return pom, nil
+}
@@ -0,0 +1,220 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testPom = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0-SNAPSHOT</version>
+
+  <properties>
+    <java.version>21</java.version>
+    <jackson.version>2.15.3</jackson.version> <!-- shared -->
+  </properties>
+
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson.core</groupId>
+        <artifactId>jackson-databind</artifactId>
+        <version>${jackson.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-annotations</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version> 2.0.9 </version>
+      <exclusions>
+        <exclusion>
+          <groupId>org.slf4j</groupId>
+          <artifactId>slf4j-simple</artifactId>
+        </exclusion>
+      </exclusions>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-compiler-plugin</artifactId>
+        <version>3.11.0</version>
+        <dependencies>
+          <dependency>
+            <groupId>org.ow2.asm</groupId>
+            <artifactId>asm</artifactId>
+            <version>9.6</version>
+          </dependency>
+        </dependencies>
+      </plugin>
+    </plugins>
+  </build>
+
+  <profiles>
+    <profile>
+      <id>legacy</id>
+      <dependencies>
+        <dependency>
+          <groupId>org.slf4j</groupId>
+          <artifactId>slf4j-api</artifactId>
+          <version>2.0.9</version>
+        </dependency>
+      </dependencies>
+    </profile>
+  </profiles>
+</project>
+`
+
+func newMavenPomTarget(t *testing.T, artifact string, property string) (*MavenPomTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "pom.xml")
+	if err := os.WriteFile(tmpFile, []byte(testPom), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeMavenPom,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{Artifact: artifact, Property: property, Source: "test-source"}},
+	}
+	target, err := NewMavenPomTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestMavenPomTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		artifact      string
+		property      string
+		expectedVer   string
+		newVersion    string
+		expectedLines []string
+	}{
+		{
+			name:          "property",
+			property:      "java.version",
+			expectedVer:   "21",
+			newVersion:    "25",
+			expectedLines: []string{"    <java.version>25</java.version>"},
+		},
+		{
+			name:          "dependency referencing a property",
+			artifact:      "com.fasterxml.jackson.core:jackson-databind",
+			expectedVer:   "2.15.3",
+			newVersion:    "2.16.0",
+			expectedLines: []string{"    <jackson.version>2.16.0</jackson.version> <!-- shared -->"},
+		},
+		{
+			name:        "dependency declared twice",
+			artifact:    "org.slf4j:slf4j-api",
+			expectedVer: "2.0.9",
+			newVersion:  "2.0.10",
+			expectedLines: []string{
+				"      <version> 2.0.10 </version>",
+				"          <version>2.0.10</version>",
+			},
+		},
+		{
+			name:          "plugin with default group",
+			artifact:      "org.apache.maven.plugins:maven-compiler-plugin",
+			expectedVer:   "3.11.0",
+			newVersion:    "3.12.1",
+			expectedLines: []string{"        <version>3.12.1</version>"},
+		},
+		{
+			name:          "plugin dependency",
+			artifact:      "org.ow2.asm:asm",
+			expectedVer:   "9.6",
+			newVersion:    "9.7",
+			expectedLines: []string{"            <version>9.7</version>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newMavenPomTarget(t, tt.artifact, tt.property)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+			if version, err := target.ReadCurrentVersion(); err != nil || version != tt.newVersion {
+				t.Errorf("Expected version '%s' after write, got '%s' (%v)", tt.newVersion, version, err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(testPom, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if strings.Join(changed, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLines)
+			}
+		})
+	}
+}
+
+func TestMavenPomTarget_NotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact string
+		property string
+	}{
+		{name: "missing dependency", artifact: "org.example:missing"},
+		{name: "managed version", artifact: "org.junit.jupiter:junit-jupiter"},
+		{name: "exclusion", artifact: "org.slf4j:slf4j-simple"},
+		{name: "project version", artifact: "com.example:app"},
+		{name: "missing property", property: "kotlin.version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newMavenPomTarget(t, tt.artifact, tt.property)
+			_, err := target.ReadCurrentVersion()
+			var dependencyNotFound *DependencyNotFoundError
+			var variableNotFound *VariableNotFoundError
+			if !errors.As(err, &dependencyNotFound) && !errors.As(err, &variableNotFound) {
+				t.Errorf("Expected not found error, got %v", err)
+			}
+		})
+	}
+}
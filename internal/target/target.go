@@ -19,6 +19,12 @@ type TargetClient interface {
 	Validate() error
 }
 
+// DigestWriter is implemented by targets pinning image digests. The digest is written
+// together with the human-readable tag kept next to it.
+type DigestWriter interface {
+	WriteDigest(tag string, digest string) error
+}
+
 // TargetInfo contains metadata about a target
 type TargetInfo struct {
 	Name         string
@@ -59,6 +65,24 @@ func (f *TargetFactory) CreateTargetForUpdateItem(target *configuration.Target,
 		return NewSubchartTargetForUpdateItem(target, updateItem)
 	case configuration.TargetTypeYamlField:
 		return NewYamlFieldTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeJsonnetField:
+		return NewJsonnetFieldTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeHelmfile:
+		return NewHelmfileTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeCargoToml:
+		return NewCargoTomlTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeMavenPom:
+		return NewMavenPomTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeGradleCatalog:
+		return NewGradleCatalogTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeMakeVariable:
+		return NewMakeVariableTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeToolVersions:
+		return NewToolVersionsTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeDevcontainer:
+		return NewDevcontainerTargetForUpdateItem(target, updateItem)
+	case configuration.TargetTypeCIImage:
+		return NewCIImageTargetForUpdateItem(target, updateItem)
 	default:
 		return nil, &UnsupportedTargetTypeError{Type: target.Type}
 	}
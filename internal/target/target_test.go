@@ -120,6 +120,39 @@ func TestTargetFactory_CreateTarget_YamlField(t *testing.T) {
 	}
 }
 
+func TestTargetFactory_CreateTarget_JsonnetField(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "main.jsonnet")
+	if err := os.WriteFile(tmpFile, []byte("{ grafana: { version: '10.2.0' } }\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	targetConfig := &configuration.Target{
+		Name: "test-jsonnet-target",
+		Type: configuration.TargetTypeJsonnetField,
+		File: tmpFile,
+		Items: []configuration.TargetItem{
+			{
+				JsonnetPath: "grafana.version",
+				Source:      "test-source",
+			},
+		},
+	}
+
+	factory := NewTargetFactory(&configuration.Config{})
+	target, err := factory.CreateTargetForUpdateItem(targetConfig, &targetConfig.Items[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	version, err := target.ReadCurrentVersion()
+	if err != nil {
+		t.Fatalf("Failed to read version: %v", err)
+	}
+	if version != "10.2.0" {
+		t.Errorf("Expected version '10.2.0', got '%s'", version)
+	}
+}
+
 func TestTargetFactory_CreateAllTargets(t *testing.T) {
 	// Create temp directory and files
 	tmpDir := t.TempDir()
@@ -0,0 +1,150 @@
+package target
+
+import "strings"
+
+// The TOML helpers locate values in the lines of a file without decoding it, so targets
+// can replace them in place and keep the formatting of the rest of the file.
+
+// tomlTableHeader returns the key segments of a [table] header line
+func tomlTableHeader(line string) ([]string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+	segments, rest := parseTomlKey(trimmed[1:])
+	rest = strings.TrimSpace(rest)
+	if segments == nil || !strings.HasPrefix(rest, "]") {
+		return nil, false
+	}
+	return segments, true
+}
+
+// splitTomlLine splits a "key = value" line into the key segments, the value and the
+// offset of the value in the line. The key is nil for other lines.
+func splitTomlLine(line string) ([]string, string, int) {
+	segments, rest := parseTomlKey(line)
+	if segments == nil {
+		return nil, "", 0
+	}
+	trimmed := strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(trimmed, "=") {
+		return nil, "", 0
+	}
+	valueStart := len(line) - len(trimmed) + 1
+	return segments, line[valueStart:], valueStart
+}
+
+// parseTomlKey parses a dotted key of bare and quoted segments and returns the rest of the
+// input, nil if the input does not start with a key
+func parseTomlKey(input string) ([]string, string) {
+	var segments []string
+	rest := input
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		switch {
+		case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				return nil, input
+			}
+			segments = append(segments, rest[1:end+1])
+			rest = rest[end+2:]
+		default:
+			end := 0
+			for end < len(rest) && isTomlBareKeyChar(rest[end]) {
+				end++
+			}
+			if end == 0 {
+				return nil, input
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		}
+		trimmed := strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(trimmed, ".") {
+			return segments, rest
+		}
+		rest = trimmed[1:]
+	}
+}
+
+// isTomlBareKeyChar reports whether a character may appear in a bare TOML key
+func isTomlBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// tomlString returns the offsets of the content of a string value at the start of value
+func tomlString(value string) (int, int, bool) {
+	trimmed := strings.TrimLeft(value, " \t")
+	if trimmed == "" || trimmed[0] != '"' && trimmed[0] != '\'' {
+		return 0, 0, false
+	}
+	start := len(value) - len(trimmed) + 1
+	end := strings.IndexByte(value[start:], trimmed[0])
+	if end < 0 {
+		return 0, 0, false
+	}
+	return start, start + end, true
+}
+
+// inlineTableString returns the offsets of the content of the string at a key of an inline
+// table value, e.g. of "version" in { version = "1.0", features = ["full"] }
+func inlineTableString(value string, key ...string) (int, int, bool) {
+	trimmed := strings.TrimLeft(value, " \t")
+	if !strings.HasPrefix(trimmed, "{") {
+		return 0, 0, false
+	}
+	position := len(value) - len(trimmed) + 1
+	for position < len(value) {
+		segments, rest := parseTomlKey(value[position:])
+		if segments == nil {
+			return 0, 0, false
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, "=") {
+			return 0, 0, false
+		}
+		valueStart := len(value) - len(rest) + 1
+		if strings.Join(segments, ".") == strings.Join(key, ".") {
+			start, end, ok := tomlString(value[valueStart:])
+			return valueStart + start, valueStart + end, ok
+		}
+		// Skip the value up to the next key of the table
+		next := skipTomlValue(value, valueStart)
+		if next < 0 {
+			return 0, 0, false
+		}
+		position = next
+	}
+	return 0, 0, false
+}
+
+// skipTomlValue returns the offset after the comma following the value starting at the
+// given offset, -1 at the end of the inline table
+func skipTomlValue(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '"', '\'':
+			end := strings.IndexByte(value[i+1:], value[i])
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '[', '{':
+			depth++
+		case ']':
+			depth--
+		case '}':
+			if depth == 0 {
+				return -1
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,247 @@
+package target
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog/log"
+)
+
+// ToolVersionsTarget implements the TargetClient interface for tool versions in asdf
+// .tool-versions and mise.toml files
+type ToolVersionsTarget struct {
+	config       *configuration.Target
+	updateItem   *configuration.TargetItem
+	fileContents string
+}
+
+// NewToolVersionsTargetForUpdateItem creates a new tool-versions target for a specific update item
+func NewToolVersionsTargetForUpdateItem(config *configuration.Target, updateItem *configuration.TargetItem) (*ToolVersionsTarget, error) {
+	if updateItem.ToolName == "" {
+		return nil, fmt.Errorf("toolName is required for tool-versions target")
+	}
+
+	target := &ToolVersionsTarget{
+		config:     config,
+		updateItem: updateItem,
+	}
+
+	if err := target.readFile(); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// readFile reads the target file into memory
+func (t *ToolVersionsTarget) readFile() error {
+	content, err := os.ReadFile(t.config.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileNotFoundError{Path: t.config.File}
+		}
+		return fmt.Errorf("failed to read file %s: %w", t.config.File, err)
+	}
+	t.fileContents = string(content)
+	return nil
+}
+
+// isMiseFile reports whether the file is a mise.toml rather than a .tool-versions file
+func (t *ToolVersionsTarget) isMiseFile() bool {
+	return strings.HasSuffix(t.config.File, ".toml")
+}
+
+// findVersion returns the offsets of the tool's version. If several versions are installed,
+// the first is the one in use and is returned.
+func (t *ToolVersionsTarget) findVersion() (int, int, error) {
+	var start, end int
+	var found bool
+	if t.isMiseFile() {
+		start, end, found = findMiseToolVersion(t.fileContents, t.updateItem.ToolName)
+	} else {
+		start, end, found = findAsdfToolVersion(t.fileContents, t.updateItem.ToolName)
+	}
+	if !found {
+		return 0, 0, &ToolNotFoundError{Tool: t.updateItem.ToolName, File: t.config.File}
+	}
+
+	// Versions like "system", "ref:main" or "path:~/src/node" are not pinned releases
+	version := t.fileContents[start:end]
+	if version == "system" || strings.HasPrefix(version, "ref:") || strings.HasPrefix(version, "path:") {
+		return 0, 0, fmt.Errorf("tool '%s' in file %s is not pinned to a version: %s", t.updateItem.ToolName, t.config.File, version)
+	}
+	return start, end, nil
+}
+
+// ReadCurrentVersion reads the version of the tool
+func (t *ToolVersionsTarget) ReadCurrentVersion() (string, error) {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("tool", t.updateItem.ToolName).
+		Msg("Reading current version from tool versions file")
+
+	start, end, err := t.findVersion()
+	if err != nil {
+		return "", err
+	}
+	version := t.fileContents[start:end]
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("tool", t.updateItem.ToolName).
+		Str("version", version).
+		Msg("Found current version")
+
+	return version, nil
+}
+
+// WriteVersion writes a new version of the tool, keeping any further versions installed
+// next to it
+func (t *ToolVersionsTarget) WriteVersion(version string) error {
+	log.Debug().
+		Str("file", t.config.File).
+		Str("tool", t.updateItem.ToolName).
+		Str("version", version).
+		Msg("Writing new version to tool versions file")
+
+	start, end, err := t.findVersion()
+	if err != nil {
+		return err
+	}
+
+	newContents := t.fileContents[:start] + version + t.fileContents[end:]
+	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
+	}
+	t.fileContents = newContents
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("tool", t.updateItem.ToolName).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// GetTargetInfo returns metadata about this target
+func (t *ToolVersionsTarget) GetTargetInfo() *TargetInfo {
+	currentVersion, err := t.ReadCurrentVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("file", t.config.File).Str("tool", t.updateItem.ToolName).Msg("Failed to read current version for target info")
+	}
+	targetName := t.updateItem.Name
+	if targetName == "" {
+		targetName = t.config.Name
+	}
+	return &TargetInfo{
+		Name:         targetName,
+		Type:         t.config.Type,
+		File:         t.config.File,
+		Source:       t.updateItem.Source,
+		CurrentValue: currentVersion,
+	}
+}
+
+// Validate checks if the target is valid and accessible
+func (t *ToolVersionsTarget) Validate() error {
+	if err := t.readFile(); err != nil {
+		return err
+	}
+
+	if filepath.Base(t.config.File) != ".tool-versions" && !t.isMiseFile() {
+		return &InvalidFileFormatError{
+			File:   t.config.File,
+			Reason: "file must be a .tool-versions file or have .toml extension",
+		}
+	}
+
+	if _, err := t.ReadCurrentVersion(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("tool", t.updateItem.ToolName).
+		Msg("Tool versions target validation successful")
+
+	return nil
+}
+
+// findAsdfToolVersion returns the offsets of the first version of a "<tool> <version>..."
+// line of a .tool-versions file
+func findAsdfToolVersion(contents string, tool string) (int, int, bool) {
+	offset := 0
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != tool {
+			continue
+		}
+		nameEnd := strings.Index(line, tool) + len(tool)
+		start := nameEnd + strings.Index(line[nameEnd:], fields[1])
+		return lineStart + start, lineStart + start + len(fields[1]), true
+	}
+	return 0, 0, false
+}
+
+// findMiseToolVersion returns the offsets of the tool's version in the [tools] table of a
+// mise.toml: node = "20", python = ["3.11", "3.10"], go = { version = "1.22" } or a
+// [tools.<tool>] table with a version key
+func findMiseToolVersion(contents string, tool string) (int, int, bool) {
+	var table []string
+	offset := 0
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if strings.HasPrefix(strings.TrimSpace(line), "[[") {
+			// Tools are not declared in arrays of tables
+			table = []string{""}
+			continue
+		}
+		if header, ok := tomlTableHeader(line); ok {
+			table = header
+			continue
+		}
+		key, value, valueStart := splitTomlLine(line)
+		if key == nil {
+			continue
+		}
+		key = append(append([]string{}, table...), key...)
+
+		switch {
+		case len(key) == 2 && key[0] == "tools" && key[1] == tool:
+			if start, end, ok := miseToolValue(value); ok {
+				return lineStart + valueStart + start, lineStart + valueStart + end, true
+			}
+		case len(key) == 3 && key[0] == "tools" && key[1] == tool && key[2] == "version":
+			if start, end, ok := tomlString(value); ok {
+				return lineStart + valueStart + start, lineStart + valueStart + end, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// miseToolValue returns the offsets of the version of a tool value: a string, the first
+// string of an array or the version of an inline table
+func miseToolValue(value string) (int, int, bool) {
+	if start, end, ok := tomlString(value); ok {
+		return start, end, true
+	}
+	if trimmed := strings.TrimLeft(value, " \t"); strings.HasPrefix(trimmed, "[") {
+		arrayStart := len(value) - len(trimmed) + 1
+		start, end, ok := tomlString(value[arrayStart:])
+		return arrayStart + start, arrayStart + end, ok
+	}
+	return inlineTableString(value, "version")
+}
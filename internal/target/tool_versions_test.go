@@ -0,0 +1,191 @@
+package target
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+const testToolVersions = `# toolchain
+nodejs 20.10.0 18.19.0
+golang   1.21.5 # go
+terraform-docs 0.17.0
+python system
+ruby ref:master
+`
+
+const testMiseToml = `[env]
+node = "not a tool"
+
+[tools]
+node = "20.10.0"
+python = ["3.12.1", "3.11.7"]
+"cargo:ripgrep" = { version = "14.0.3", os = ["linux"] }
+terraform = "1.6.5" # infra
+
+[tools.go]
+version = "1.21.5"
+
+[[tasks]]
+node = "18"
+`
+
+func newToolVersionsTarget(t *testing.T, fileName string, contents string, tool string) (*ToolVersionsTarget, string) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(tmpFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeToolVersions,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{ToolName: tool, Source: "test-source"}},
+	}
+	target, err := NewToolVersionsTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target, tmpFile
+}
+
+func TestToolVersionsTarget_ReadAndWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileName     string
+		contents     string
+		tool         string
+		expectedVer  string
+		newVersion   string
+		expectedLine string
+	}{
+		{
+			name:         "tool-versions with fallback version",
+			fileName:     ".tool-versions",
+			contents:     testToolVersions,
+			tool:         "nodejs",
+			expectedVer:  "20.10.0",
+			newVersion:   "20.11.0",
+			expectedLine: "nodejs 20.11.0 18.19.0",
+		},
+		{
+			name:         "tool-versions with comment",
+			fileName:     ".tool-versions",
+			contents:     testToolVersions,
+			tool:         "golang",
+			expectedVer:  "1.21.5",
+			newVersion:   "1.22.0",
+			expectedLine: "golang   1.22.0 # go",
+		},
+		{
+			name:         "mise string",
+			fileName:     "mise.toml",
+			contents:     testMiseToml,
+			tool:         "node",
+			expectedVer:  "20.10.0",
+			newVersion:   "20.11.0",
+			expectedLine: `node = "20.11.0"`,
+		},
+		{
+			name:         "mise array",
+			fileName:     "mise.toml",
+			contents:     testMiseToml,
+			tool:         "python",
+			expectedVer:  "3.12.1",
+			newVersion:   "3.12.2",
+			expectedLine: `python = ["3.12.2", "3.11.7"]`,
+		},
+		{
+			name:         "mise inline table with quoted key",
+			fileName:     "mise.toml",
+			contents:     testMiseToml,
+			tool:         "cargo:ripgrep",
+			expectedVer:  "14.0.3",
+			newVersion:   "14.1.0",
+			expectedLine: `"cargo:ripgrep" = { version = "14.1.0", os = ["linux"] }`,
+		},
+		{
+			name:         "mise tool table",
+			fileName:     "mise.toml",
+			contents:     testMiseToml,
+			tool:         "go",
+			expectedVer:  "1.21.5",
+			newVersion:   "1.22.0",
+			expectedLine: `version = "1.22.0"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, tmpFile := newToolVersionsTarget(t, tt.fileName, tt.contents, tt.tool)
+
+			version, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != tt.expectedVer {
+				t.Errorf("Expected version '%s', got '%s'", tt.expectedVer, version)
+			}
+
+			if err := target.WriteVersion(tt.newVersion); err != nil {
+				t.Fatalf("Failed to write version: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(string(content), "\n")
+			original := strings.Split(tt.contents, "\n")
+			var changed []string
+			for i := range lines {
+				if lines[i] != original[i] {
+					changed = append(changed, lines[i])
+				}
+			}
+			if len(changed) != 1 || changed[0] != tt.expectedLine {
+				t.Errorf("Unexpected changed lines %q, expected %q", changed, tt.expectedLine)
+			}
+		})
+	}
+}
+
+func TestToolVersionsTarget_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		fileName      string
+		contents      string
+		tool          string
+		errorContains string
+		notFound      bool
+	}{
+		{name: "missing tool", fileName: ".tool-versions", contents: testToolVersions, tool: "java", notFound: true},
+		{name: "prefix of another tool", fileName: ".tool-versions", contents: testToolVersions, tool: "terraform", notFound: true},
+		{name: "commented tool", fileName: ".tool-versions", contents: testToolVersions, tool: "toolchain", notFound: true},
+		{name: "system version", fileName: ".tool-versions", contents: testToolVersions, tool: "python", errorContains: "is not pinned to a version"},
+		{name: "ref version", fileName: ".tool-versions", contents: testToolVersions, tool: "ruby", errorContains: "is not pinned to a version"},
+		{name: "mise missing tool", fileName: "mise.toml", contents: testMiseToml, tool: "java", notFound: true},
+		{name: "mise key outside tools", fileName: "mise.toml", contents: strings.Replace(testMiseToml, `node = "20.10.0"`, "", 1), tool: "node", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := newToolVersionsTarget(t, tt.fileName, tt.contents, tt.tool)
+			_, err := target.ReadCurrentVersion()
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			var notFound *ToolNotFoundError
+			if tt.notFound != errors.As(err, &notFound) {
+				t.Errorf("Expected ToolNotFoundError=%v, got %v", tt.notFound, err)
+			}
+			if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package target
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// tagCommentPattern matches the "# tag: <tag>" comment kept next to a pinned digest
+var tagCommentPattern = regexp.MustCompile(`#\s*tag:\s*(\S+)`)
+
+// readPinnedTag returns the tag kept next to the pinned digest at the item's yamlPath
+func (t *YamlFieldTarget) readPinnedTag(segments []string, digestNode *yaml.Node) (string, error) {
+	if field := t.updateItem.DigestPin.TagField; field != "" {
+		tagNode, err := t.findTagField(segments, field)
+		if err != nil {
+			return "", err
+		}
+		return tagNode.Value, nil
+	}
+
+	match := tagCommentPattern.FindStringSubmatch(t.line(digestNode))
+	if match == nil {
+		return "", fmt.Errorf("no '# tag:' comment next to digest '%s' in file %s", t.updateItem.YamlPath, t.config.File)
+	}
+	return match[1], nil
+}
+
+// findTagField returns the scalar tag field next to the digest at the given path
+func (t *YamlFieldTarget) findTagField(segments []string, field string) (*yaml.Node, error) {
+	tagPath := append(append([]string{}, segments[:len(segments)-1]...), field)
+	node, err := t.findNodeInDocuments(tagPath)
+	if err != nil || node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("tag field '%s' next to digest '%s' not found in file %s", field, t.updateItem.YamlPath, t.config.File)
+	}
+	return node, nil
+}
+
+// WriteDigest pins a new digest at the item's yamlPath and updates the tag kept next to it.
+// The digest replaces the whole value, or only the part after "@" of an image reference.
+func (t *YamlFieldTarget) WriteDigest(tag string, digest string) error {
+	if t.updateItem.DigestPin == nil {
+		return fmt.Errorf("yaml path '%s' in file %s is not digest-pinned", t.updateItem.YamlPath, t.config.File)
+	}
+	log.Debug().
+		Str("file", t.config.File).
+		Str("yamlPath", t.updateItem.YamlPath).
+		Str("tag", tag).
+		Str("digest", digest).
+		Msg("Pinning new digest in YAML file")
+
+	segments := parsePath(t.updateItem.YamlPath)
+	node, err := t.findNodeInDocuments(segments)
+	if err != nil {
+		return &YamlFieldNotFoundError{Path: t.updateItem.YamlPath, File: t.config.File}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("yaml path '%s' in file %s points to a non-scalar node", t.updateItem.YamlPath, t.config.File)
+	}
+
+	newValue := digest
+	if at := strings.LastIndex(node.Value, "@"); at >= 0 {
+		newValue = node.Value[:at+1] + digest
+	}
+	if err := t.replaceScalar(node, newValue); err != nil {
+		return err
+	}
+
+	field := t.updateItem.DigestPin.TagField
+	if field == "" {
+		t.setLine(node, setTagComment(t.line(node), tag))
+		return t.save()
+	}
+
+	// The tag field may share the line of the digest, so it is looked up after the write
+	if err := t.save(); err != nil {
+		return err
+	}
+	tagNode, err := t.findTagField(segments, field)
+	if err != nil {
+		return err
+	}
+	if err := t.replaceScalar(tagNode, tag); err != nil {
+		return err
+	}
+	return t.save()
+}
+
+// setTagComment updates the "# tag:" comment of a line, or appends one
+func setTagComment(line string, tag string) string {
+	if match := tagCommentPattern.FindStringSubmatchIndex(line); match != nil {
+		return line[:match[2]] + tag + line[match[3]:]
+	}
+	return line + " # tag: " + tag
+}
+
+// line returns the line of the file contents a node starts on
+func (t *YamlFieldTarget) line(node *yaml.Node) string {
+	lines := strings.Split(t.fileContents, "\n")
+	if node.Line < 1 || node.Line > len(lines) {
+		return ""
+	}
+	return lines[node.Line-1]
+}
+
+// setLine replaces the line of the file contents a node starts on
+func (t *YamlFieldTarget) setLine(node *yaml.Node, line string) {
+	lines := strings.Split(t.fileContents, "\n")
+	if node.Line < 1 || node.Line > len(lines) {
+		return
+	}
+	lines[node.Line-1] = line
+	t.fileContents = strings.Join(lines, "\n")
+}
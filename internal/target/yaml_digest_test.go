@@ -0,0 +1,150 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mxcd/updater/internal/configuration"
+)
+
+func TestYamlFieldTarget_DigestPin(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		yamlPath    string
+		digestPin   *configuration.DigestPin
+		expectedTag string
+		expected    string
+	}{
+		{
+			name: "tag comment",
+			fileContent: `image:
+  digest: "sha256:aaa" # tag: 1.25.0
+`,
+			yamlPath:    "image.digest",
+			digestPin:   &configuration.DigestPin{},
+			expectedTag: "1.25.0",
+			expected: `image:
+  digest: "sha256:bbb" # tag: 1.26.0
+`,
+		},
+		{
+			name: "image reference with tag comment",
+			fileContent: `containers:
+  - name: app
+    image: nginx@sha256:aaa  #tag: 1.25.0
+`,
+			yamlPath:    "containers.0.image",
+			digestPin:   &configuration.DigestPin{},
+			expectedTag: "1.25.0",
+			expected: `containers:
+  - name: app
+    image: nginx@sha256:bbb  #tag: 1.26.0
+`,
+		},
+		{
+			name: "sibling tag field",
+			fileContent: `image:
+  digest: sha256:aaa
+  tag: '1.25.0'
+`,
+			yamlPath:    "image.digest",
+			digestPin:   &configuration.DigestPin{TagField: "tag"},
+			expectedTag: "1.25.0",
+			expected: `image:
+  digest: sha256:bbb
+  tag: '1.26.0'
+`,
+		},
+		{
+			name: "flow mapping with sibling tag field",
+			fileContent: `image: {digest: sha256:aaa, tag: 1.25.0}
+`,
+			yamlPath:    "image.digest",
+			digestPin:   &configuration.DigestPin{TagField: "tag"},
+			expectedTag: "1.25.0",
+			expected: `image: {digest: sha256:bbb, tag: 1.26.0}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "values.yaml")
+			if err := os.WriteFile(tmpFile, []byte(tt.fileContent), 0644); err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+			config := &configuration.Target{
+				Name:  "test-target",
+				Type:  configuration.TargetTypeYamlField,
+				File:  tmpFile,
+				Items: []configuration.TargetItem{{YamlPath: tt.yamlPath, Source: "test-source", DigestPin: tt.digestPin}},
+			}
+			target, err := NewYamlFieldTargetForUpdateItem(config, &config.Items[0])
+			if err != nil {
+				t.Fatalf("Failed to create target: %v", err)
+			}
+
+			tag, err := target.ReadCurrentVersion()
+			if err != nil {
+				t.Fatalf("Failed to read pinned tag: %v", err)
+			}
+			if tag != tt.expectedTag {
+				t.Errorf("Expected tag '%s', got '%s'", tt.expectedTag, tag)
+			}
+
+			if err := target.WriteVersion("1.26.0"); err == nil {
+				t.Errorf("Expected writing a version without digest to fail")
+			}
+			if err := target.WriteDigest("1.26.0", "sha256:bbb"); err != nil {
+				t.Fatalf("Failed to write digest: %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Unexpected file content:\n%s\nexpected:\n%s", content, tt.expected)
+			}
+			if tag, err := target.ReadCurrentVersion(); err != nil || tag != "1.26.0" {
+				t.Errorf("Expected tag '1.26.0' after write, got '%s' (%v)", tag, err)
+			}
+		})
+	}
+}
+
+func TestYamlFieldTarget_DigestPin_TagComment(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(tmpFile, []byte("digest: sha256:aaa\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	config := &configuration.Target{
+		Name:  "test-target",
+		Type:  configuration.TargetTypeYamlField,
+		File:  tmpFile,
+		Items: []configuration.TargetItem{{YamlPath: "digest", Source: "test-source", DigestPin: &configuration.DigestPin{}}},
+	}
+	target, err := NewYamlFieldTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+
+	// Without a tag the current version is unknown
+	if _, err := target.ReadCurrentVersion(); err == nil {
+		t.Errorf("Expected error for digest without tag comment")
+	}
+
+	// Writing the digest adds the missing comment
+	if err := target.WriteDigest("1.26.0", "sha256:bbb"); err != nil {
+		t.Fatalf("Failed to write digest: %v", err)
+	}
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "digest: sha256:bbb # tag: 1.26.0\n" {
+		t.Errorf("Unexpected file content: %q", content)
+	}
+}
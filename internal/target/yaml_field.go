@@ -70,8 +70,15 @@ func (t *YamlFieldTarget) readFile() error {
 	return nil
 }
 
-// findNodeInDocuments searches all documents for the given path
+// findNodeInDocuments searches the item's document, or all documents, for the given path
 func (t *YamlFieldTarget) findNodeInDocuments(segments []string) (*yaml.Node, error) {
+	if document := t.updateItem.Document; document != nil {
+		if *document < 0 || *document >= len(t.rootNodes) {
+			return nil, fmt.Errorf("document %d out of range (%d documents)", *document, len(t.rootNodes))
+		}
+		return findNode(t.rootNodes[*document], segments)
+	}
+
 	var lastErr error
 	for _, root := range t.rootNodes {
 		node, err := findNode(root, segments)
@@ -200,6 +207,11 @@ func (t *YamlFieldTarget) ReadCurrentVersion() (string, error) {
 		return "", fmt.Errorf("yaml path '%s' in file %s points to a non-scalar node", t.updateItem.YamlPath, t.config.File)
 	}
 
+	// A pinned digest is compared by the tag kept next to it
+	if t.updateItem.DigestPin != nil {
+		return t.readPinnedTag(segments, node)
+	}
+
 	value := node.Value
 	// If the value is a Docker image reference (e.g., "nginx:1.25.0"),
 	// extract just the tag portion for version comparison
@@ -224,6 +236,10 @@ func (t *YamlFieldTarget) WriteVersion(version string) error {
 		Str("version", version).
 		Msg("Writing new version to YAML file")
 
+	if t.updateItem.DigestPin != nil {
+		return fmt.Errorf("yaml path '%s' in file %s is digest-pinned, the digest of version %s is required", t.updateItem.YamlPath, t.config.File, version)
+	}
+
 	segments := parsePath(t.updateItem.YamlPath)
 	node, err := t.findNodeInDocuments(segments)
 	if err != nil {
@@ -247,6 +263,27 @@ func (t *YamlFieldTarget) WriteVersion(version string) error {
 		newValue = version
 	}
 
+	if err := t.replaceScalar(node, newValue); err != nil {
+		return err
+	}
+	if err := t.save(); err != nil {
+		return err
+	}
+
+	log.Debug().
+		Str("file", t.config.File).
+		Str("yamlPath", t.updateItem.YamlPath).
+		Str("version", version).
+		Msg("Successfully wrote new version")
+
+	return nil
+}
+
+// replaceScalar replaces the value of a scalar node in the file contents, keeping its
+// quoting style and the rest of the line
+func (t *YamlFieldTarget) replaceScalar(node *yaml.Node, newValue string) error {
+	oldValue := node.Value
+
 	// Split file into lines for surgical replacement
 	lines := strings.Split(t.fileContents, "\n")
 	// yaml.Node uses 1-based line numbers
@@ -298,27 +335,19 @@ func (t *YamlFieldTarget) WriteVersion(version string) error {
 	}
 
 	lines[lineIdx] = newLine
-	newContents := strings.Join(lines, "\n")
+	t.fileContents = strings.Join(lines, "\n")
+	return nil
+}
 
-	// Write the file
-	if err := os.WriteFile(t.config.File, []byte(newContents), 0644); err != nil {
+// save writes the file contents and re-parses them, so nodes found afterwards point to
+// the updated positions
+func (t *YamlFieldTarget) save() error {
+	if err := os.WriteFile(t.config.File, []byte(t.fileContents), 0644); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", t.config.File, err)
 	}
-
-	// Update internal state
-	t.fileContents = newContents
-
-	// Re-parse the YAML to update the node trees
 	if err := t.reparseNodes(); err != nil {
 		return fmt.Errorf("failed to re-parse YAML file %s after write: %w", t.config.File, err)
 	}
-
-	log.Debug().
-		Str("file", t.config.File).
-		Str("yamlPath", t.updateItem.YamlPath).
-		Str("version", version).
-		Msg("Successfully wrote new version")
-
 	return nil
 }
 
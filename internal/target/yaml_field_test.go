@@ -1,12 +1,14 @@
 package target
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/mxcd/updater/internal/configuration"
+	"github.com/rs/zerolog"
 )
 
 func TestYamlFieldTarget_ReadCurrentVersion(t *testing.T) {
@@ -1200,3 +1202,130 @@ spec:
 		t.Errorf("ReadCurrentVersion = %q, want %q", version, "16.1")
 	}
 }
+
+func TestYamlFieldTarget_MultiDocumentYAML_Document(t *testing.T) {
+	// Both documents contain the path, the item selects the second one
+	fileContent := `kind: Application
+spec:
+  image: nginx:1.25.0
+---
+kind: Application
+spec:
+  image: nginx:1.26.0
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "apps.yaml")
+	if err := os.WriteFile(tmpFile, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	second, outOfRange := 1, 2
+	config := &configuration.Target{
+		Name: "test",
+		Type: configuration.TargetTypeYamlField,
+		File: tmpFile,
+		Items: []configuration.TargetItem{
+			{YamlPath: "spec.image", Source: "test-source", Document: &second},
+			{YamlPath: "spec.image", Source: "test-source", Document: &outOfRange},
+		},
+	}
+
+	target, err := NewYamlFieldTargetForUpdateItem(config, &config.Items[0])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	if version, err := target.ReadCurrentVersion(); err != nil || version != "1.26.0" {
+		t.Fatalf("ReadCurrentVersion = %q, %v, want %q", version, err, "1.26.0")
+	}
+	if err := target.WriteVersion("1.27.0"); err != nil {
+		t.Fatalf("WriteVersion failed: %v", err)
+	}
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	expected := strings.Replace(fileContent, "nginx:1.26.0", "nginx:1.27.0", 1)
+	if string(content) != expected {
+		t.Errorf("Unexpected file content:\n%s", content)
+	}
+
+	invalid, err := NewYamlFieldTargetForUpdateItem(config, &config.Items[1])
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	if _, err := invalid.ReadCurrentVersion(); err == nil {
+		t.Errorf("Expected an error for a document out of range")
+	}
+}
+
+// benchmarkManifest generates a multi-document manifest of n deployments, like a rendered
+// Helm chart of a larger application
+func benchmarkManifest(n int) string {
+	var builder strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&builder, `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: service-%d
+  labels:
+    app.kubernetes.io/name: service-%d
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: service
+          image: registry.example.com/service-%d:1.%d.0
+          ports:
+            - containerPort: 8080
+          resources:
+            limits:
+              memory: 256Mi
+`, i, i, i, i)
+	}
+	return builder.String()
+}
+
+func BenchmarkYamlFieldTarget(b *testing.B) {
+	// Logging per file would dominate the measurements
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	const documents = 500
+	path := filepath.Join(b.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(path, []byte(benchmarkManifest(documents)), 0644); err != nil {
+		b.Fatal(err)
+	}
+	// The last document is the worst case for finding the field
+	last := documents - 1
+	config := &configuration.Target{Name: "manifest", File: path}
+	item := &configuration.TargetItem{YamlPath: "spec.template.spec.containers.0.image", Source: "service", Document: &last}
+
+	b.Run("read", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			target, err := NewYamlFieldTargetForUpdateItem(config, item)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := target.ReadCurrentVersion(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("write", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			target, err := NewYamlFieldTargetForUpdateItem(config, item)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := target.WriteVersion(fmt.Sprintf("2.%d.0", i%2)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
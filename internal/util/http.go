@@ -0,0 +1,165 @@
+package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// rateLimitHeaders are response headers reporting API and registry rate limits
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"Retry-After",
+}
+
+// sensitiveQueryParams are query parameters whose values are redacted in traces
+var sensitiveQueryParams = []string{"token", "password", "secret", "key", "signature", "sig", "code"}
+
+// transport is the dedicated transport of updater's outbound requests. It wraps a clone of the
+// default transport, leaving the process-global http.DefaultTransport untouched.
+var transport = newTracingTransport("dev", false)
+
+// ConfigureHTTPTransport configures the updater transport so that all outbound requests carry
+// the updater User-Agent and, with tracing enabled, are logged with their status and timing.
+// It must be called before any client is created.
+func ConfigureHTTPTransport(version string, trace bool) {
+	transport = newTracingTransport(version, trace)
+}
+
+// NewHTTPClient returns an HTTP client with the given timeout that sends its requests through
+// the updater transport
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// newTracingTransport wraps a clone of the default transport with the updater User-Agent and
+// optional request tracing
+func newTracingTransport(version string, trace bool) *tracingTransport {
+	return &tracingTransport{
+		base:      http.DefaultTransport.(*http.Transport).Clone(),
+		userAgent: "updater/" + version,
+		trace:     trace,
+	}
+}
+
+type tracingTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	trace     bool
+}
+
+func (t *tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	if request.Header.Get("User-Agent") == "" {
+		request.Header.Set("User-Agent", t.userAgent)
+	}
+
+	start := time.Now()
+	response, err := t.base.RoundTrip(request)
+	if !t.trace {
+		return response, err
+	}
+
+	event := log.Info().
+		Str("method", request.Method).
+		Str("url", RedactURL(request.URL)).
+		Dur("duration", time.Since(start))
+	if err != nil {
+		event.Err(err).Msg("HTTP request failed")
+		return response, err
+	}
+
+	event.Int("status", response.StatusCode)
+	addRateLimitHeaders(event, response.Header)
+	event.Msg("HTTP request")
+
+	return response, err
+}
+
+// ClientCertificateTransport returns a copy of a transport, the updater transport if nil, that
+// presents the TLS client certificate in certFile and keyFile, for servers requiring mutual TLS
+func ClientCertificateTransport(base http.RoundTripper, certFile string, keyFile string) (http.RoundTripper, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+	}
+	if base == nil {
+		base = transport
+	}
+	return withClientCertificate(base, certificate), nil
+}
+
+// withClientCertificate clones a transport, keeping the User-Agent and tracing wrapper, and
+// adds the client certificate to its TLS configuration
+func withClientCertificate(transport http.RoundTripper, certificate tls.Certificate) http.RoundTripper {
+	switch t := transport.(type) {
+	case *tracingTransport:
+		clone := *t
+		clone.base = withClientCertificate(t.base, certificate)
+		return &clone
+	case *snapshotTransport:
+		if t.replay {
+			return t
+		}
+		clone := *t
+		clone.base = withClientCertificate(t.base, certificate)
+		return &clone
+	case *http.Transport:
+		clone := t.Clone()
+		if clone.TLSClientConfig == nil {
+			clone.TLSClientConfig = &tls.Config{}
+		}
+		clone.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+		return clone
+	default:
+		return &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{certificate}},
+		}
+	}
+}
+
+// addRateLimitHeaders adds the rate-limit headers present in a response to a log event
+func addRateLimitHeaders(event *zerolog.Event, header http.Header) {
+	for _, name := range rateLimitHeaders {
+		if value := header.Get(name); value != "" {
+			event.Str(strings.ToLower(name), value)
+		}
+	}
+}
+
+// RedactURL returns the URL with user credentials and sensitive query parameters masked
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("REDACTED")
+	}
+
+	query := redacted.Query()
+	changed := false
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, sensitive := range sensitiveQueryParams {
+			if strings.Contains(lower, sensitive) {
+				query.Set(name, "REDACTED")
+				changed = true
+				break
+			}
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.String()
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"io"
 	"os"
 	"time"
 
@@ -9,17 +10,35 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
+// SetCliLoggerDefaults writes log output to stderr so that stdout only carries command results
 func SetCliLoggerDefaults() {
 	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.000Z"
-	log.Logger = log.Logger.Output(zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		NoColor:    false,
+	log.Logger = log.Logger.Output(consoleWriter(os.Stderr, !ColorEnabled())).With().Logger()
+}
+
+// AddLogOutput writes the log output to w as well, without colors, e.g. to show the log of a
+// run in the web UI
+func AddLogOutput(w io.Writer) {
+	log.Logger = log.Logger.Output(zerolog.MultiLevelWriter(
+		consoleWriter(os.Stderr, !ColorEnabled()),
+		consoleWriter(w, true),
+	)).With().Logger()
+}
+
+// consoleWriter returns a human-readable log writer masking secrets. Every log line ends in
+// a newline, so the redacting writer never holds anything back and needs no closing.
+func consoleWriter(w io.Writer, noColor bool) zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{
+		Out:        NewRedactingWriter(w),
+		NoColor:    noColor,
 		TimeFormat: time.RFC3339,
-	}).With().Logger()
+	}
 }
 
 func SetCliLogLevel(c *cli.Command) {
-	if c.Bool("very-verbose") {
+	if c.Bool("quiet") {
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	} else if c.Bool("very-verbose") {
 		zerolog.SetGlobalLevel(zerolog.TraceLevel)
 	} else if c.Bool("verbose") {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
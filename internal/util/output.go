@@ -0,0 +1,137 @@
+package util
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// quiet suppresses all human-oriented output; set once from the --quiet flag
+var quiet bool
+
+// SetQuiet enables or disables quiet mode
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// IsQuiet reports whether quiet mode is enabled
+func IsQuiet() bool {
+	return quiet
+}
+
+// ResultOutput returns the writer for the human-readable result of a command, such as a
+// table. It writes to stdout and discards everything in quiet mode. Machine-readable
+// formats (JSON, YAML, SARIF) are always written to stdout directly.
+func ResultOutput() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return humanWriter(os.Stdout)
+}
+
+// StatusOutput returns the writer for progress, summaries and other decoration around the
+// result. It writes to stderr so that stdout can be piped, and discards everything in
+// quiet mode.
+func StatusOutput() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return humanWriter(os.Stderr)
+}
+
+// humanWriter applies the emoji setting to a writer for human-oriented output
+func humanWriter(out io.Writer) io.Writer {
+	if noEmoji {
+		return &plainWriter{out: out}
+	}
+	return out
+}
+
+// noColor and noEmoji are set once from the --no-color and --no-emoji flags
+var (
+	noColor bool
+	noEmoji bool
+)
+
+// SetNoColor disables colored output
+func SetNoColor(enabled bool) {
+	noColor = enabled
+}
+
+// ColorEnabled reports whether output may be colored. Color is disabled by --no-color and
+// by a non-empty NO_COLOR environment variable (https://no-color.org).
+func ColorEnabled() bool {
+	return !noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// SetNoEmoji replaces emoji and Unicode box drawing with plain ASCII in human-oriented output
+func SetNoEmoji(enabled bool) {
+	noEmoji = enabled
+}
+
+// EmojiEnabled reports whether human-oriented output may contain emoji
+func EmojiEnabled() bool {
+	return !noEmoji
+}
+
+// asciiReplacements maps the non-emoji Unicode symbols used in output to ASCII
+var asciiReplacements = map[rune]string{
+	'→': "->",
+	'•': "*",
+	'━': "-",
+}
+
+// Plain returns text with emoji removed, together with the spaces following them, and
+// other Unicode symbols replaced by ASCII
+func Plain(text string) string {
+	var builder strings.Builder
+	skipSpaces := false
+	for _, r := range text {
+		if replacement, ok := asciiReplacements[r]; ok {
+			builder.WriteString(replacement)
+			skipSpaces = false
+			continue
+		}
+		if isEmoji(r) {
+			skipSpaces = true
+			continue
+		}
+		if skipSpaces && r == ' ' {
+			continue
+		}
+		skipSpaces = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// isEmoji reports whether r is an emoji, a pictographic symbol or an emoji modifier
+func isEmoji(r rune) bool {
+	switch {
+	case r == 0x2139 || r == 0x200D || r == 0xFE0F: // ℹ, zero width joiner, variation selector
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // Arrows
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Miscellaneous Technical
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous Symbols, Dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Miscellaneous Symbols and Arrows
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF: // Emoji and pictographs
+		return true
+	}
+	return false
+}
+
+// plainWriter passes all writes through Plain
+type plainWriter struct {
+	out io.Writer
+}
+
+func (w *plainWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, Plain(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,45 @@
+package util
+
+import "testing"
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "emoji and following spaces are removed",
+			input:    "✅ All targets are up to date",
+			expected: "All targets are up to date",
+		},
+		{
+			name:     "variation selector is removed",
+			input:    "⚠️  Total: 2 target(s) with errors",
+			expected: "Total: 2 target(s) with errors",
+		},
+		{
+			name:     "indentation is kept",
+			input:    "  ❌ nginx (provider: dockerhub): timeout",
+			expected: "  nginx (provider: dockerhub): timeout",
+		},
+		{
+			name:     "symbols are replaced by ASCII",
+			input:    "1.0.0 → 1.1.0 • patch",
+			expected: "1.0.0 -> 1.1.0 * patch",
+		},
+		{
+			name:     "plain text is unchanged",
+			input:    "Total findings: 3",
+			expected: "Total findings: 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Plain(tt.input); got != tt.expected {
+				t.Errorf("Plain() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package util
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces secret values in logs and serialized output
+const RedactedValue = "REDACTED"
+
+// minSecretLength avoids masking short values that would garble unrelated output.
+// Credential fields of the configuration are masked structurally regardless of length.
+const minSecretLength = 4
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// RegisterSecret marks a value as secret so it is masked in all log and command output
+func RegisterSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	addSecret(value)
+	// JSON encoders escape &, <, > and quotes, so the escaped form must be masked as well
+	if escaped := jsonEscape(value); escaped != value {
+		addSecret(escaped)
+	}
+	// Replace longer secrets first so a secret containing another is masked completely
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+}
+
+// addSecret appends a secret unless it is already registered
+func addSecret(value string) {
+	for _, existing := range secrets {
+		if existing == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+}
+
+// jsonEscape returns a value as it appears inside a JSON string
+func jsonEscape(value string) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(data[1 : len(data)-1])
+}
+
+// Redact masks all registered secrets in a string
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, RedactedValue)
+	}
+	return s
+}
+
+// partialSecretLength returns the length of the longest suffix of s that is the start,
+// but not the whole, of a registered secret
+func partialSecretLength(s string) int {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	longest := 0
+	for _, secret := range secrets {
+		for length := min(len(secret)-1, len(s)); length > longest; length-- {
+			if strings.HasSuffix(s, secret[:length]) {
+				longest = length
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// redactingWriter masks registered secrets in everything written through it. Encoders
+// may split a secret across writes, so a trailing fragment that could start a secret is
+// held back until the next write or Close. Secrets never start with whitespace, so a write
+// ending in a newline is always passed through completely.
+type redactingWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	pending string
+}
+
+// NewRedactingWriter wraps a writer so that registered secrets never reach it. Close the
+// returned writer after the last write to pass on a held back fragment; closing does not
+// close the wrapped writer.
+func NewRedactingWriter(out io.Writer) io.WriteCloser {
+	return &redactingWriter{out: out}
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	redacted := Redact(w.pending + string(p))
+	held := partialSecretLength(redacted)
+	w.pending = redacted[len(redacted)-held:]
+	if _, err := io.WriteString(w.out, redacted[:len(redacted)-held]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the held back fragment, which did not turn into a secret
+func (w *redactingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pending := w.pending
+	w.pending = ""
+	_, err := io.WriteString(w.out, pending)
+	return err
+}
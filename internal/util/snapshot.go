@@ -0,0 +1,200 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// snapshotTokenFields are fields of JSON responses holding credentials, e.g. the bearer tokens
+// of registry token exchanges, which are redacted before a response is recorded
+var snapshotTokenFields = []string{"token", "access_token", "refresh_token", "id_token"}
+
+// recordedResponse is a response as stored in a snapshot directory
+type recordedResponse struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`       // Body if it is valid UTF-8
+	BodyBase64 string      `json:"bodyBase64,omitempty"` // Body otherwise
+}
+
+// snapshotTransport records the responses of a transport in a directory, or replays them from
+// it without any network access
+type snapshotTransport struct {
+	base   http.RoundTripper // Transport of recorded requests, unused when replaying
+	dir    string
+	replay bool
+}
+
+// NewRecordingTransport returns a transport that sends requests through base and stores every
+// response in dir, one file per request, to be replayed with NewReplayTransport
+func NewRecordingTransport(base http.RoundTripper, dir string) http.RoundTripper {
+	return &snapshotTransport{base: base, dir: dir}
+}
+
+// NewReplayTransport returns a transport that answers requests with the responses recorded in
+// dir. Requests without a recorded response fail.
+func NewReplayTransport(dir string) http.RoundTripper {
+	return &snapshotTransport{dir: dir, replay: true}
+}
+
+func (t *snapshotTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(request.Body); err != nil {
+			return nil, err
+		}
+		request.Body.Close()
+		request = request.Clone(request.Context())
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	path := t.snapshotPath(request, requestBody)
+
+	if t.replay {
+		return t.replayResponse(request, path)
+	}
+
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.record(request, response, body, path); err != nil {
+		return nil, fmt.Errorf("failed to record response of %s %s: %w", request.Method, RedactURL(request.URL), err)
+	}
+	return response, nil
+}
+
+// snapshotPath returns the file of the response to a request. Requests are told apart by
+// method, URL and body, and by whether they carry credentials, since registries answer the
+// same URL with 401 before and with the result after the token exchange. Credential values are
+// not part of the key, so a snapshot replays with any or no credentials.
+func (t *snapshotTransport) snapshotPath(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%t\n", request.Method, RedactURL(request.URL), request.Header.Get("Authorization") != "")
+	hash.Write(body)
+
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(request.URL.Host)
+	return filepath.Join(t.dir, host, hex.EncodeToString(hash.Sum(nil))[:16]+".json")
+}
+
+// record stores a response with credentials redacted
+func (t *snapshotTransport) record(request *http.Request, response *http.Response, body []byte, path string) error {
+	header := response.Header.Clone()
+	header.Del("Set-Cookie")
+
+	recorded := &recordedResponse{
+		Method:     request.Method,
+		URL:        RedactURL(request.URL),
+		StatusCode: response.StatusCode,
+		Header:     header,
+	}
+	body = redactSnapshotTokens(body)
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+	} else {
+		recorded.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Concurrent scrapes may record the same request, so the file is replaced atomically
+	file, err := os.CreateTemp(filepath.Dir(path), ".response-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// replayResponse returns the recorded response of a request
+func (t *snapshotTransport) replayResponse(request *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", request.Method, RedactURL(request.URL), t.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded recordedResponse
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("invalid recorded response %s: %w", path, err)
+	}
+	body := []byte(recorded.Body)
+	if recorded.BodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(recorded.BodyBase64); err != nil {
+			return nil, fmt.Errorf("invalid recorded response %s: %w", path, err)
+		}
+	}
+	if recorded.Header == nil {
+		recorded.Header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+// redactSnapshotTokens masks the credential fields of a JSON object body, other bodies are
+// returned unchanged
+func redactSnapshotTokens(body []byte) []byte {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return body
+	}
+	changed := false
+	for _, field := range snapshotTokenFields {
+		if _, ok := object[field]; ok {
+			object[field] = json.RawMessage(`"REDACTED"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	redacted, err := json.Marshal(object)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
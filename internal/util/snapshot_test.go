@@ -0,0 +1,110 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotTransport_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"token":"secret-token","expires_in":300}`)
+		case r.Header.Get("Authorization") == "":
+			w.Header().Set("Www-Authenticate", `Bearer realm="/token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Header().Set("Link", `</v2/app/tags/list?n=2&last=b>; rel="next"`)
+			io.WriteString(w, `{"tags":["a","b"]}`)
+		}
+	}))
+
+	dir := t.TempDir()
+	recording := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, dir)}
+	unauthorized := snapshotGet(t, recording, server.URL+"/v2/app/tags/list", "")
+	token := snapshotGet(t, recording, server.URL+"/token", "")
+	tags := snapshotGet(t, recording, server.URL+"/v2/app/tags/list", "Bearer secret-token")
+	server.Close()
+
+	if unauthorized != "" {
+		t.Errorf("expected empty body of the 401, got %s", unauthorized)
+	}
+	if token != `{"token":"secret-token","expires_in":300}` {
+		t.Errorf("recording must return the original response, got %s", token)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("expected 3 recorded responses, got %d", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "secret-token") {
+			t.Errorf("token not redacted in %s: %s", file, data)
+		}
+	}
+
+	replay := &http.Client{Transport: NewReplayTransport(dir)}
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/v2/app/tags/list", nil)
+	response, err := replay.Do(request)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized || response.Header.Get("Www-Authenticate") == "" {
+		t.Errorf("expected the recorded 401 with challenge, got %d %v", response.StatusCode, response.Header)
+	}
+	if got := snapshotGet(t, replay, server.URL+"/token", ""); !strings.Contains(got, `"token":"REDACTED"`) {
+		t.Errorf("expected redacted token, got %s", got)
+	}
+	// Any credentials match the recorded authorized request
+	if got := snapshotGet(t, replay, server.URL+"/v2/app/tags/list", "Bearer REDACTED"); got != tags {
+		t.Errorf("expected %s, got %s", tags, got)
+	}
+	_, err = replay.Get(server.URL + "/v2/other/tags/list")
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for GET") {
+		t.Errorf("expected missing response error, got %v", err)
+	}
+}
+
+func TestSnapshotTransport_BinaryBody(t *testing.T) {
+	body := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	dir := t.TempDir()
+	snapshotGet(t, &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, dir)}, server.URL+"/chart.tgz", "")
+	server.Close()
+
+	if got := snapshotGet(t, &http.Client{Transport: NewReplayTransport(dir)}, server.URL+"/chart.tgz", ""); got != string(body) {
+		t.Errorf("expected %v, got %v", body, []byte(got))
+	}
+}
+
+// snapshotGet returns the body of a GET request, failing the test on errors
+func snapshotGet(t *testing.T, client *http.Client, url string, authorization string) string {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
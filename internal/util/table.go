@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// NewTable returns a table writer that renders to the result output. Tables use rounded
+// Unicode borders, or plain ASCII borders and emoji-free cells when emoji are disabled.
+func NewTable() table.Writer {
+	t := table.NewWriter()
+	t.SetOutputMirror(ResultOutput())
+	if EmojiEnabled() {
+		t.SetStyle(table.StyleRounded)
+		return t
+	}
+	t.SetStyle(table.StyleDefault)
+	return &plainTable{Writer: t}
+}
+
+// plainTable removes emoji from titles and rows before they are measured, so that column
+// widths stay aligned
+type plainTable struct {
+	table.Writer
+}
+
+func (t *plainTable) SetTitle(format string, a ...interface{}) {
+	t.Writer.SetTitle("%s", Plain(fmt.Sprintf(format, a...)))
+}
+
+func (t *plainTable) AppendHeader(row table.Row, configs ...table.RowConfig) {
+	t.Writer.AppendHeader(plainRow(row), configs...)
+}
+
+func (t *plainTable) AppendRow(row table.Row, configs ...table.RowConfig) {
+	t.Writer.AppendRow(plainRow(row), configs...)
+}
+
+func (t *plainTable) AppendRows(rows []table.Row, configs ...table.RowConfig) {
+	for _, row := range rows {
+		t.AppendRow(row, configs...)
+	}
+}
+
+func (t *plainTable) AppendFooter(row table.Row, configs ...table.RowConfig) {
+	t.Writer.AppendFooter(plainRow(row), configs...)
+}
+
+func plainRow(row table.Row) table.Row {
+	plain := make(table.Row, len(row))
+	for i, cell := range row {
+		if text, ok := cell.(string); ok {
+			plain[i] = Plain(text)
+		} else {
+			plain[i] = cell
+		}
+	}
+	return plain
+}